@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"scanner/internal/api/handler/v1handler"
 	"scanner/internal/config"
 	"scanner/pkg/logger"
 	"time"
@@ -19,24 +20,31 @@ func JWTCommand(cfg *config.Config) *cobra.Command {
 		Use:   "jwt",
 		Short: "Generates JWT token for given user ID",
 		Run: func(cmd *cobra.Command, args []string) {
+			ctx := context.Background()
+			defer func() { _ = logger.Sync(ctx) }()
+
 			subject, _ := cmd.Flags().GetString("subject")
 			TTL, _ := cmd.Flags().GetDuration("ttl")
+			roles, _ := cmd.Flags().GetStringSlice("roles")
 
 			key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(cfg.JWT.PrivateKey))
 			if err != nil {
-				logger.Fatal(context.Background(), "could not parse RSA private key", zap.Error(err))
+				logger.Fatal(ctx, "could not parse RSA private key", zap.Error(err))
 			}
 
-			claims := jwt.RegisteredClaims{
-				Subject:   subject,
-				ExpiresAt: jwt.NewNumericDate(time.Now().Add(TTL)),
-				IssuedAt:  jwt.NewNumericDate(time.Now()),
-				NotBefore: jwt.NewNumericDate(time.Now()),
+			claims := v1handler.Claims{
+				RegisteredClaims: jwt.RegisteredClaims{
+					Subject:   subject,
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(TTL)),
+					IssuedAt:  jwt.NewNumericDate(time.Now()),
+					NotBefore: jwt.NewNumericDate(time.Now()),
+				},
+				Roles: roles,
 			}
 			token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
 			signed, err := token.SignedString(key)
 			if err != nil {
-				logger.Fatal(context.Background(), "could not sign JWT", zap.Error(err))
+				logger.Fatal(ctx, "could not sign JWT", zap.Error(err))
 			}
 
 			fmt.Println(signed) //nolint: forbidigo
@@ -45,6 +53,7 @@ func JWTCommand(cfg *config.Config) *cobra.Command {
 
 	cmd.Flags().String("subject", "", "JWT subject (e.g., user ID)")
 	cmd.Flags().Duration("ttl", 24*time.Hour, "Token TTL (e.g., 30s, 15m, 1h)")
+	cmd.Flags().StringSlice("roles", nil, "Optional comma-separated user roles (e.g., admin)")
 	_ = cmd.MarkFlagRequired("subject")
 
 	return cmd
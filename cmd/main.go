@@ -10,11 +10,26 @@ import (
 	"scanner/internal/config"
 	"scanner/pkg/logger"
 	"scanner/pkg/storage/postgres"
+	"scanner/pkg/urlscanner/urlscanio"
 
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
 
+// version is the version of the binary, set at build time with
+// -ldflags "-X main.version=...".
+var version = "dev" //nolint: gochecknoglobals
+
+// userAgent returns the configured urlscan.io User-Agent, defaulting to
+// url-scanner/<version>.
+func userAgent(cfg *config.Config) string {
+	if cfg.Scanner.UserAgent != "" {
+		return cfg.Scanner.UserAgent
+	}
+
+	return urlscanio.UserAgent(version)
+}
+
 // getPostgres creates a PostgreSQL client using configuration values and returns it
 // along with a cleanup function to close the connection pool.
 func getPostgres(ctx context.Context, cfg *config.Config) (*postgres.PgSQL, func()) {
@@ -29,6 +44,8 @@ func getPostgres(ctx context.Context, cfg *config.Config) (*postgres.PgSQL, func
 		MaxOpenConnections: cfg.Database.MaxOpenConnections,
 		MaxIdleConnections: cfg.Database.MaxIdleConnections,
 		SslMode:            cfg.Database.SslMode,
+		ReplicaDSN:         cfg.Database.ReplicaDSN,
+		QueryTimeout:       cfg.Database.QueryTimeout,
 	})
 	if err != nil {
 		logger.Fatal(ctx, "could not create postgres storage", zap.Error(err))
@@ -64,13 +81,18 @@ func main() {
 	}
 
 	logger.Setup(cfg.Environment)
+	if cfg.LogLevel != "" {
+		if err := logger.SetLevel(cfg.LogLevel); err != nil {
+			log.Fatal("invalid log level", err)
+		}
+	}
 
 	ctx := context.Background()
 
 	defer func() {
 		if p := recover(); p != nil {
 			logger.Error(ctx, "captured panic, exiting...", zap.Any("panic", p))
-			_ = logger.Get(ctx).Sync()
+			_ = logger.Sync(ctx)
 
 			panic(p)
 		}
@@ -78,12 +100,12 @@ func main() {
 
 	rootCmd.AddCommand(
 		migrateCommand(cfg),
-		scanCommand(cfg),
+		scanCommand(cfg, *configPath),
 		JWTCommand(cfg),
 	)
 
 	err = rootCmd.Execute()
-	_ = logger.Get(ctx).Sync()
+	_ = logger.Sync(ctx)
 	if err != nil {
 		os.Exit(1) //nolint: gocritic
 	}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"scanner/internal/config"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserAgent(t *testing.T) {
+	cfg := &config.Config{}
+	require.Equal(t, "url-scanner/"+version, userAgent(cfg))
+
+	cfg.Scanner.UserAgent = "custom/1.0"
+	require.Equal(t, "custom/1.0", userAgent(cfg))
+}
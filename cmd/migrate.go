@@ -1,11 +1,16 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"database/sql"
+	"fmt"
+	"io"
 	root "scanner"
 	"scanner/internal/config"
 	"scanner/pkg/logger"
+	"strconv"
+	"strings"
 
 	"github.com/pressly/goose/v3"
 	"github.com/riverqueue/river/riverdriver/riverdatabasesql"
@@ -14,53 +19,224 @@ import (
 	"go.uber.org/zap"
 )
 
+// gooseMigrationsDir is the directory of the app migrations within root.Migrations.
+const gooseMigrationsDir = "migrations"
+
 // migrateCommand constructs the 'migrate' subcommand that applies database
-// migrations to the latest version using goose.
+// migrations to the latest version using goose, along with the 'down' and
+// 'to' subcommands used for rollbacks.
 func migrateCommand(cfg *config.Config) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "migrate",
 		Short: "Migrates database to the latest version",
 		Run: func(cmd *cobra.Command, args []string) {
 			ctx := context.Background()
+			defer func() { _ = logger.Sync(ctx) }()
 
 			strg, closeStrg := getPostgres(ctx, cfg)
 			defer closeStrg()
+			db := strg.DB.(*sql.DB)
+
+			// migrate riverqueue first, app migrations index its tables
+			migrator := newRiverMigrator(ctx, db)
+			migrations := migrator.AllVersions()
+			latestVersion := migrations[len(migrations)-1].Version
+			if latestVersion > riverVersion(ctx, migrator) {
+				_, err := migrator.Migrate(ctx, rivermigrate.DirectionUp, &rivermigrate.MigrateOpts{
+					TargetVersion: latestVersion,
+				})
+				if err != nil {
+					logger.Fatal(ctx, "could not river queue database", zap.Error(err))
+				}
+			}
 
 			// goose migrations (internal tables)
-			goose.SetBaseFS(root.Migrations)
+			setupGoose(ctx)
+			if err := goose.Up(db, gooseMigrationsDir); err != nil {
+				logger.Fatal(ctx, "could not migrate pgsql", zap.Error(err))
+			}
+		},
+	}
+
+	cmd.AddCommand(migrateDownCommand(cfg), migrateToCommand(cfg))
+
+	return cmd
+}
+
+// migrateDownCommand constructs the 'migrate down' subcommand that rolls back
+// the latest app migration, or the latest river queue migration with --river.
+func migrateDownCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "down",
+		Short: "Rolls back the latest migration",
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := context.Background()
+			defer func() { _ = logger.Sync(ctx) }()
 
-			if err := goose.SetDialect("postgres"); err != nil {
-				logger.Fatal(ctx, "could not set goose dialect to postgres", zap.Error(err))
+			river, _ := cmd.Flags().GetBool("river")
+			if !confirmed(cmd, "roll back the latest migration") {
+				logger.Info(ctx, "aborted")
+
+				return
 			}
-			if err := goose.Up(strg.DB.(*sql.DB), "migrations"); err != nil {
-				logger.Fatal(ctx, "could not migrate pgsql", zap.Error(err))
+
+			strg, closeStrg := getPostgres(ctx, cfg)
+			defer closeStrg()
+			db := strg.DB.(*sql.DB)
+
+			if river {
+				// river migrates a single step down by default
+				_, err := newRiverMigrator(ctx, db).Migrate(ctx, rivermigrate.DirectionDown, nil)
+				if err != nil {
+					logger.Fatal(ctx, "could not roll back river queue database", zap.Error(err))
+				}
+
+				return
 			}
 
-			// migrate riverqueue
-			migrator, err := rivermigrate.New(riverdatabasesql.New(strg.DB.(*sql.DB)), nil)
-			if err != nil {
-				logger.Fatal(ctx, "could not create river queue migrator", zap.Error(err))
+			setupGoose(ctx)
+			if err := goose.Down(db, gooseMigrationsDir); err != nil {
+				logger.Fatal(ctx, "could not roll back pgsql", zap.Error(err))
 			}
-			migrations := migrator.AllVersions()
-			latestVersion := migrations[len(migrations)-1].Version
-			currentVersion := 0
-			currentMigrations, err := migrator.ExistingVersions(ctx)
+		},
+	}
+
+	cmd.Flags().Bool("river", false, "Roll back river queue migrations instead of app migrations")
+	cmd.Flags().Bool("yes", false, "Skip the confirmation prompt")
+
+	return cmd
+}
+
+// migrateToCommand constructs the 'migrate to' subcommand that migrates up or
+// down to the given app migration version, or river queue migration version
+// with --river.
+func migrateToCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "to <version>",
+		Short: "Migrates database up or down to the given version",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := context.Background()
+			defer func() { _ = logger.Sync(ctx) }()
+
+			version, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil || version < 0 {
+				logger.Fatal(ctx, "version must be a non-negative integer", zap.String("version", args[0]))
+			}
+			river, _ := cmd.Flags().GetBool("river")
+			if !confirmed(cmd, fmt.Sprintf("migrate to version %d", version)) {
+				logger.Info(ctx, "aborted")
+
+				return
+			}
+
+			strg, closeStrg := getPostgres(ctx, cfg)
+			defer closeStrg()
+			db := strg.DB.(*sql.DB)
+
+			if river {
+				migrateRiverTo(ctx, newRiverMigrator(ctx, db), int(version))
+
+				return
+			}
+
+			setupGoose(ctx)
+			current, err := goose.GetDBVersion(db)
 			if err != nil {
-				logger.Fatal(ctx, "could not get existing river queue migrations", zap.Error(err))
+				logger.Fatal(ctx, "could not get pgsql version", zap.Error(err))
 			}
-			if len(currentMigrations) > 0 {
-				currentVersion = currentMigrations[len(currentMigrations)-1].Version
+			if version < current {
+				err = goose.DownTo(db, gooseMigrationsDir, version)
+			} else {
+				err = goose.UpTo(db, gooseMigrationsDir, version)
 			}
-			if latestVersion > currentVersion {
-				_, err = migrator.Migrate(ctx, rivermigrate.DirectionUp, &rivermigrate.MigrateOpts{
-					TargetVersion: latestVersion,
-				})
-				if err != nil {
-					logger.Fatal(ctx, "could not river queue database", zap.Error(err))
-				}
+			if err != nil {
+				logger.Fatal(ctx, "could not migrate pgsql", zap.Error(err), zap.Int64("version", version))
 			}
 		},
 	}
 
+	cmd.Flags().Bool("river", false, "Migrate river queue instead of app migrations")
+	cmd.Flags().Bool("yes", false, "Skip the confirmation prompt")
+
 	return cmd
 }
+
+// setupGoose points goose at the embedded migrations and the postgres dialect.
+func setupGoose(ctx context.Context) {
+	goose.SetBaseFS(root.Migrations)
+
+	if err := goose.SetDialect("postgres"); err != nil {
+		logger.Fatal(ctx, "could not set goose dialect to postgres", zap.Error(err))
+	}
+}
+
+// newRiverMigrator returns a river queue migrator for db.
+func newRiverMigrator(ctx context.Context, db *sql.DB) *rivermigrate.Migrator[*sql.Tx] {
+	migrator, err := rivermigrate.New(riverdatabasesql.New(db), nil)
+	if err != nil {
+		logger.Fatal(ctx, "could not create river queue migrator", zap.Error(err))
+	}
+
+	return migrator
+}
+
+// riverVersion returns the latest applied river queue migration version, or
+// zero when none is applied.
+func riverVersion(ctx context.Context, migrator *rivermigrate.Migrator[*sql.Tx]) int {
+	currentMigrations, err := migrator.ExistingVersions(ctx)
+	if err != nil {
+		logger.Fatal(ctx, "could not get existing river queue migrations", zap.Error(err))
+	}
+	if len(currentMigrations) == 0 {
+		return 0
+	}
+
+	return currentMigrations[len(currentMigrations)-1].Version
+}
+
+// migrateRiverTo migrates the river queue schema up or down to version. A
+// version of zero removes the river queue schema completely.
+func migrateRiverTo(ctx context.Context, migrator *rivermigrate.Migrator[*sql.Tx], version int) {
+	current := riverVersion(ctx, migrator)
+
+	var err error
+	switch {
+	case version > current:
+		_, err = migrator.Migrate(ctx, rivermigrate.DirectionUp, &rivermigrate.MigrateOpts{TargetVersion: version})
+	case version < current:
+		target := version
+		if target == 0 {
+			// -1 applies every down migration
+			target = -1
+		}
+		_, err = migrator.Migrate(ctx, rivermigrate.DirectionDown, &rivermigrate.MigrateOpts{TargetVersion: target})
+	}
+	if err != nil {
+		logger.Fatal(ctx, "could not migrate river queue database", zap.Error(err), zap.Int("version", version))
+	}
+}
+
+// confirmed reports whether the user confirmed the given destructive action,
+// either with the --yes flag or by answering "y" on the command's input.
+func confirmed(cmd *cobra.Command, action string) bool {
+	if yes, _ := cmd.Flags().GetBool("yes"); yes {
+		return true
+	}
+
+	return confirm(cmd.InOrStdin(), cmd.OutOrStdout(), action)
+}
+
+// confirm prompts for the given action on out and reports whether the answer
+// read from in is "y" or "yes".
+func confirm(in io.Reader, out io.Writer, action string) bool {
+	_, _ = fmt.Fprintf(out, "This will %s. Continue? [y/N] ", action)
+
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirm(t *testing.T) {
+	for answer, want := range map[string]bool{
+		"y\n":   true,
+		"YES\n": true,
+		"n\n":   false,
+		"\n":    false,
+		"":      false,
+	} {
+		var out bytes.Buffer
+		require.Equal(t, want, confirm(strings.NewReader(answer), &out, "roll back"), "answer %q", answer)
+		require.Equal(t, "This will roll back. Continue? [y/N] ", out.String())
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"scanner/internal/config"
+	"scanner/internal/scanner"
+	"scanner/pkg/logger"
+	"syscall"
+
+	"go.uber.org/zap"
+)
+
+// reloadOnSIGHUP reloads the config file at configPath whenever the process
+// receives SIGHUP, until ctx is done. See reloadConfig for what is applied.
+func reloadOnSIGHUP(ctx context.Context, configPath string, scannerOptions *scanner.OptionsHolder) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sighup:
+			logger.Info(ctx, "reloading config...")
+			if err := reloadConfig(configPath, scannerOptions); err != nil {
+				logger.Error(ctx, "could not reload config, keeping current settings", zap.Error(err))
+
+				continue
+			}
+			logger.Info(ctx, "config reloaded")
+		}
+	}
+}
+
+// reloadConfig loads and validates the config file at configPath and applies
+// the settings that are safe to change at runtime: the log level and the
+// scanner options. Connection settings such as the database pool and the HTTP
+// server require a restart. Nothing is applied when the config is invalid.
+func reloadConfig(configPath string, scannerOptions *scanner.OptionsHolder) error {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("could not load config: %w", err)
+	}
+	if err := cfg.ValidateForScan(); err != nil {
+		return fmt.Errorf("could not validate config: %w", err)
+	}
+
+	if cfg.LogLevel != "" {
+		if err := logger.SetLevel(cfg.LogLevel); err != nil {
+			return fmt.Errorf("could not set log level: %w", err)
+		}
+	}
+	scannerOptions.Reload(cfg)
+
+	return nil
+}
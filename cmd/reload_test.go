@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"scanner/internal/scanner"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestReloadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	holder := scanner.NewOptionsHolder(scanner.Options{MaxAttempts: 3})
+
+	writeConfig(t, path, `
+jwt:
+  publicKey: "PUBLIC KEY"
+scanner:
+  urlscanioApiKey: "API KEY"
+  maxAttempts: 7
+  deniedDomains: ["evil.example"]
+`)
+	require.NoError(t, reloadConfig(path, holder))
+	options := holder.Load()
+	require.Equal(t, 7, options.MaxAttempts)
+	require.Equal(t, scanner.DomainList{"evil.example"}, options.DeniedDomains)
+
+	// invalid configs are not applied
+	writeConfig(t, path, `
+scanner:
+  maxAttempts: 9
+`)
+	require.Error(t, reloadConfig(path, holder))
+	require.Equal(t, 7, holder.Load().MaxAttempts)
+
+	writeConfig(t, path, `
+logLevel: "loud"
+jwt:
+  publicKey: "PUBLIC KEY"
+scanner:
+  urlscanioApiKey: "API KEY"
+  maxAttempts: 9
+`)
+	require.ErrorContains(t, reloadConfig(path, holder), "log level")
+	require.Equal(t, 7, holder.Load().MaxAttempts)
+}
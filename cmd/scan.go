@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"os/signal"
 	"scanner/internal/api"
@@ -10,11 +11,19 @@ import (
 	"scanner/internal/config"
 	"scanner/internal/scanner"
 	"scanner/internal/worker"
+	"scanner/pkg/httpclient"
 	"scanner/pkg/logger"
+	"scanner/pkg/storage"
+	"scanner/pkg/storage/postgres"
+	"scanner/pkg/tracing"
+	"scanner/pkg/urlscanner"
 	"scanner/pkg/urlscanner/urlscanio"
 	"syscall"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 	"go.uber.org/zap"
 )
 
@@ -43,26 +52,75 @@ func setupServer(ctx context.Context, cfg *config.Config, deps api.Deps) func(ct
 	}
 }
 
+// newScanner creates the scanner used by both the API server and the worker,
+// backed by strg and a urlscan.io client configured from cfg. It reads its
+// options from holder.
+func newScanner(cfg *config.Config, strg storage.Storage, holder *scanner.OptionsHolder) (scanner.Scanner, error) {
+	httpClient, err := httpclient.New(httpclient.Options{
+		CAFile:   cfg.Scanner.TLSCAFile,
+		CertFile: cfg.Scanner.TLSCertFile,
+		KeyFile:  cfg.Scanner.TLSKeyFile,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create urlscan.io HTTP client: %w", err)
+	}
+	httpClient.Transport = urlscanio.NewTracingTransport(urlscanio.NewDebugTransport(httpClient.Transport))
+
+	return scanner.NewWithOptionsHolder(
+		strg,
+		urlscanio.New(
+			httpClient,
+			cfg.Scanner.UrlscanioAPIKey,
+			urlscanner.Visibility(cfg.Scanner.Visibility),
+			userAgent(cfg),
+		),
+		holder,
+	), nil
+}
+
 // scanCommand constructs the 'scan' subcommand that runs the API server and
-// background workers until interrupted.
-func scanCommand(cfg *config.Config) *cobra.Command {
+// background workers until interrupted. On SIGHUP it reloads the config file at
+// configPath and applies the settings that are safe to change at runtime.
+func scanCommand(cfg *config.Config, configPath string) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "scan",
 		Short: "Starts API server and background workers",
 		Run: func(cmd *cobra.Command, args []string) {
 			ctx, _ := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			// flush logs once everything else has shut down
+			defer func() { _ = logger.Sync(ctx) }()
+
+			if err := cfg.ValidateForScan(); err != nil {
+				logger.Fatal(ctx, "invalid config", zap.Error(err))
+			}
+
+			// propagate the trace context of API requests to urlscan.io, even when
+			// spans are not exported
+			otel.SetTextMapPropagator(propagation.TraceContext{})
+			if cfg.Tracing.Enabled {
+				tracerProvider := tracing.NewProvider(tracing.Options{SampleRatio: cfg.Tracing.SampleRatio})
+				otel.SetTracerProvider(tracerProvider)
+				// flush spans once everything else has shut down
+				defer func() { _ = tracerProvider.Shutdown(context.WithoutCancel(ctx)) }()
+			}
 
 			strg, closeStrg := getPostgres(ctx, cfg)
 			defer closeStrg()
+			if err := prometheus.Register(postgres.NewPoolCollector(strg.Pool)); err != nil {
+				logger.Warn(ctx, "could not register database pool metrics", zap.Error(err))
+			}
 
-			scannerSvc := scanner.New(
-				strg,
-				urlscanio.New(http.DefaultClient, cfg.Scanner.UrlscanioAPIKey),
-				scanner.NewOptions(cfg),
-			)
+			// the API server and the worker share one scanner, so both see reloaded options
+			scannerOptions := scanner.NewOptionsHolder(scanner.NewOptions(cfg))
+			scannerSvc, err := newScanner(cfg, strg, scannerOptions)
+			if err != nil {
+				logger.Fatal(ctx, "could not create scanner", zap.Error(err))
+			}
+			go reloadOnSIGHUP(ctx, configPath, scannerOptions)
 
 			// TODO: move workers to separate command
-			workerClient, err := worker.Start(ctx, strg.Pool, scannerSvc, worker.NewOptions(cfg))
+			workerOpts := worker.NewOptions(cfg)
+			workerClient, urlScannerWorker, err := worker.Start(ctx, strg.Pool, scannerSvc, strg, workerOpts)
 			if err != nil {
 				logger.Fatal(ctx, "could not start worker", zap.Error(err))
 			}
@@ -82,9 +140,10 @@ func scanCommand(cfg *config.Config) *cobra.Command {
 			stopWebserver(shutdownCtx)
 
 			logger.Info(ctx, "stopping worker...")
-			if err := workerClient.Stop(shutdownCtx); err != nil {
+			if err := worker.Stop(shutdownCtx, workerClient, urlScannerWorker, workerOpts); err != nil {
 				logger.Warn(ctx, "could not stop worker", zap.Error(err))
 			}
+			scannerSvc.Close()
 		},
 	}
 
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"scanner/internal/config"
+	"scanner/internal/scanner"
+	"scanner/pkg/domain"
+	"scanner/pkg/logger"
+	"scanner/pkg/serrors"
+	mockstorage "scanner/pkg/storage/mock"
+	"scanner/pkg/urlscanner"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNewScanner_UsesURLScanIOClient(t *testing.T) {
+	logger.Setup(logger.DevelopmentEnvironment)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	st := mockstorage.NewMockStorage(ctrl)
+
+	cfg := &config.Config{}
+	cfg.Scanner.UrlscanioAPIKey = "API KEY"
+	s, err := newScanner(cfg, st, scanner.NewOptionsHolder(scanner.Options{MaxAttempts: 3}))
+	require.NoError(t, err)
+
+	// the canceled submission shows the scan reached the urlscan.io client
+	// without sending a request
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	st.EXPECT().PendingScanCountByURL(gomock.Any(), "https://example.com", domain.UserID{}).Return(int64(1), nil)
+	st.EXPECT().RecordScanAttempts(gomock.Any(), "https://example.com", domain.UserID{}, gomock.Any()).Return(nil)
+	st.EXPECT().UpdatePendingScansByURL(gomock.Any(), "https://example.com", domain.UserID{}, gomock.Any()).
+		Return(int64(1), nil)
+	_, err = s.Scan(ctx, "https://example.com", domain.UserID{}, urlscanner.SubmitOptions{})
+	require.ErrorIs(t, err, context.Canceled)
+	require.NotErrorIs(t, err, serrors.ErrInternal)
+}
+
+func TestNewScanner_InvalidTLSFiles(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Scanner.TLSCAFile = "does-not-exist.pem"
+	_, err := newScanner(cfg, nil, scanner.NewOptionsHolder(scanner.Options{}))
+	require.ErrorContains(t, err, "could not create urlscan.io HTTP client")
+}
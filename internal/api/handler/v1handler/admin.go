@@ -0,0 +1,202 @@
+package v1handler
+
+import (
+	"context"
+	"scanner/internal/api/specs/v1specs"
+	"scanner/internal/scanner"
+	"scanner/pkg/domain"
+	"scanner/pkg/serrors"
+	"scanner/pkg/storage"
+
+	"github.com/google/uuid"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// requireAdmin returns a forbidden error unless the authenticated user has
+// the admin role.
+func requireAdmin(ctx context.Context) error {
+	if !GetRolesFromContext(ctx).Has(RoleAdmin) {
+		return serrors.With(serrors.ErrForbidden, "admin role required")
+	}
+
+	return nil
+}
+
+// ListAdminScans returns a paginated list of scans across all users. It is
+// only available to users with the admin role.
+func (h Handler) ListAdminScans(
+	ctx context.Context,
+	params v1specs.ListAdminScansParams) (v1specs.ListAdminScansRes, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	limit, err := h.pageLimit(params.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := scanStatus(params.Status)
+	if err != nil {
+		return nil, err
+	}
+	var URL string
+	if u, ok := params.URL.Get(); ok {
+		URL = u.String()
+	}
+
+	scans, nextCursor, err := h.deps.Scanner.AdminScans(ctx,
+		scanner.AdminScanFilter{
+			Status:        status,
+			URL:           URL,
+			CreatedAfter:  params.CreatedAfter.Value,
+			CreatedBefore: params.CreatedBefore.Value,
+		},
+		params.Cursor.Value,
+		limit)
+	if err != nil {
+		return nil, err //nolint: wrapcheck
+	}
+
+	return domainScansToV1Specs(scans, nextCursor, limit)
+}
+
+// ListAdminJobs returns a paginated list of queued jobs. It is only available
+// to users with the admin role.
+func (h Handler) ListAdminJobs(
+	ctx context.Context,
+	params v1specs.ListAdminJobsParams) (v1specs.ListAdminJobsRes, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	limit, err := h.pageLimit(params.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, nextCursor, err := h.deps.Scanner.AdminJobs(ctx,
+		rivertype.JobState(params.State.Value),
+		params.Cursor.Value,
+		limit)
+	if err != nil {
+		return nil, err //nolint: wrapcheck
+	}
+
+	items := make([]v1specs.Job, 0, len(jobs))
+	for i := range jobs {
+		items = append(items, StorageJobToV1Specs(&jobs[i]))
+	}
+
+	var cursorOpt v1specs.OptNilString
+	if nextCursor != "" {
+		cursorOpt = v1specs.NewOptNilString(nextCursor)
+	}
+
+	return &v1specs.JobList{
+		Items:      items,
+		NextCursor: cursorOpt,
+	}, nil
+}
+
+// ListAdminAuditLog returns a paginated list of audit log entries. It is
+// only available to users with the admin role.
+func (h Handler) ListAdminAuditLog(
+	ctx context.Context,
+	params v1specs.ListAdminAuditLogParams) (v1specs.ListAdminAuditLogRes, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	limit, err := h.pageLimit(params.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, nextCursor, err := h.deps.Scanner.AuditLog(ctx,
+		scanner.AuditFilter{
+			UserID: domain.UserID(params.UserId.Value),
+			ScanID: domain.ScanID(params.ScanId.Value),
+			Action: storage.AuditAction(params.Action.Value),
+		},
+		params.Cursor.Value,
+		limit)
+	if err != nil {
+		return nil, err //nolint: wrapcheck
+	}
+
+	items := make([]v1specs.AuditEntry, 0, len(entries))
+	for i := range entries {
+		items = append(items, StorageAuditEntryToV1Specs(&entries[i]))
+	}
+
+	var cursorOpt v1specs.OptNilString
+	if nextCursor != "" {
+		cursorOpt = v1specs.NewOptNilString(nextCursor)
+	}
+
+	return &v1specs.AuditEntryList{
+		Items:      items,
+		NextCursor: cursorOpt,
+	}, nil
+}
+
+// RequeueScan enqueues a new job for a stuck pending scan. It is only
+// available to users with the admin role.
+func (h Handler) RequeueScan(ctx context.Context, params v1specs.RequeueScanParams) (v1specs.RequeueScanRes, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	s, err := h.deps.Scanner.Requeue(ctx, domain.ScanID(params.ID))
+	if err != nil {
+		return nil, err //nolint: wrapcheck
+	}
+
+	return DomainScanToV1Specs(s)
+}
+
+// ForceFailScan marks a stuck pending scan as failed. It is only available to
+// users with the admin role.
+func (h Handler) ForceFailScan(ctx context.Context,
+	req *v1specs.ForceFailScanRequest,
+	params v1specs.ForceFailScanParams) (v1specs.ForceFailScanRes, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	s, err := h.deps.Scanner.ForceFail(ctx, domain.ScanID(params.ID), req.Reason)
+	if err != nil {
+		return nil, err //nolint: wrapcheck
+	}
+
+	return DomainScanToV1Specs(s)
+}
+
+func StorageJobToV1Specs(in *storage.Job) v1specs.Job {
+	var URL v1specs.OptString
+	if in.URL != "" {
+		URL.SetTo(in.URL)
+	}
+
+	return v1specs.Job{
+		ID:          in.ID,
+		Kind:        in.Kind,
+		URL:         URL,
+		State:       v1specs.JobState(in.State),
+		Queue:       in.Queue,
+		Attempt:     in.Attempt,
+		MaxAttempts: in.MaxAttempts,
+		CreatedAt:   in.CreatedAt,
+	}
+}
+
+func StorageAuditEntryToV1Specs(in *storage.AuditEntry) v1specs.AuditEntry {
+	return v1specs.AuditEntry{
+		ID:        in.ID,
+		UserId:    uuid.UUID(in.UserID),
+		Action:    v1specs.AuditAction(in.Action),
+		ScanId:    uuid.UUID(in.ScanID),
+		CreatedAt: in.CreatedAt,
+	}
+}
@@ -0,0 +1,180 @@
+package v1handler_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riverqueue/river/rivertype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"scanner/internal/api/handler/v1handler"
+	"scanner/internal/api/specs/v1specs"
+	"scanner/internal/scanner"
+	mockscanner "scanner/internal/scanner/mock"
+	"scanner/pkg/domain"
+	"scanner/pkg/serrors"
+	"scanner/pkg/storage"
+)
+
+func TestHandler_ListAdminScans(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockscanner.NewMockScanner(ctrl)
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{})
+
+	userID := domain.UserID(uuid.New())
+	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, userID)
+
+	u, _ := url.Parse("https://abc.xyz")
+	after := time.Now().Add(-time.Hour)
+	params := v1specs.ListAdminScansParams{
+		Cursor:       v1specs.NewOptNilString("c0"),
+		Status:       v1specs.NewOptScanStatus(v1specs.ScanStatus(domain.ScanStatusCompleted)),
+		URL:          v1specs.NewOptURI(*u),
+		CreatedAfter: v1specs.NewOptDateTime(after),
+	}
+
+	// regular users are forbidden
+	_, err := h.ListAdminScans(ctx, params)
+	require.ErrorIs(t, err, serrors.ErrForbidden)
+
+	// admins list scans of all users
+	ctx = context.WithValue(ctx, v1handler.RolesKey, v1handler.Roles{v1handler.RoleAdmin})
+	scans := []domain.Scan{sampleScan(domain.UserID(uuid.New()), "https://abc.xyz")}
+	m.EXPECT().AdminScans(ctx,
+		scanner.AdminScanFilter{Status: domain.ScanStatusCompleted, URL: "https://abc.xyz", CreatedAfter: after},
+		"c0",
+		uint(v1handler.DefaultLimit),
+	).Return(scans, "next", nil)
+
+	res, err := h.ListAdminScans(ctx, params)
+	require.NoError(t, err)
+	lst := res.(*v1specs.ScanList)
+	require.Len(t, lst.Items, 1)
+	require.Equal(t, "next", lst.NextCursor.Value)
+}
+
+func TestHandler_ListAdminJobs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockscanner.NewMockScanner(ctrl)
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{})
+
+	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, domain.UserID(uuid.New()))
+	params := v1specs.ListAdminJobsParams{
+		Limit: v1specs.NewOptInt(5),
+		State: v1specs.NewOptJobState(v1specs.JobStateAvailable),
+	}
+
+	// regular users are forbidden
+	_, err := h.ListAdminJobs(ctx, params)
+	require.ErrorIs(t, err, serrors.ErrForbidden)
+
+	// admins list queued jobs
+	ctx = context.WithValue(ctx, v1handler.RolesKey, v1handler.Roles{v1handler.RoleAdmin})
+	jobs := []storage.Job{{
+		ID:          42,
+		Kind:        scanner.JobKind,
+		URL:         "https://abc.xyz/",
+		State:       rivertype.JobStateAvailable,
+		Queue:       "default",
+		Attempt:     1,
+		MaxAttempts: 3,
+		CreatedAt:   time.Now(),
+	}}
+	m.EXPECT().AdminJobs(ctx, rivertype.JobStateAvailable, "", uint(5)).Return(jobs, "42", nil)
+
+	res, err := h.ListAdminJobs(ctx, params)
+	require.NoError(t, err)
+	lst := res.(*v1specs.JobList)
+	require.Len(t, lst.Items, 1)
+	require.Equal(t, int64(42), lst.Items[0].ID)
+	require.Equal(t, "https://abc.xyz/", lst.Items[0].URL.Value)
+	require.Equal(t, v1specs.JobStateAvailable, lst.Items[0].State)
+	require.Equal(t, "42", lst.NextCursor.Value)
+}
+
+func TestHandler_ListAdminAuditLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockscanner.NewMockScanner(ctrl)
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{})
+
+	userID := domain.UserID(uuid.New())
+	scanID := domain.ScanID(uuid.New())
+	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, domain.UserID(uuid.New()))
+	params := v1specs.ListAdminAuditLogParams{
+		Limit:  v1specs.NewOptInt(5),
+		UserId: v1specs.NewOptUUID(uuid.UUID(userID)),
+		Action: v1specs.NewOptAuditAction(v1specs.AuditActionDELETE),
+	}
+
+	// regular users are forbidden
+	_, err := h.ListAdminAuditLog(ctx, params)
+	require.ErrorIs(t, err, serrors.ErrForbidden)
+
+	// admins list audit entries
+	ctx = context.WithValue(ctx, v1handler.RolesKey, v1handler.Roles{v1handler.RoleAdmin})
+	entries := []storage.AuditEntry{{
+		ID:        42,
+		UserID:    userID,
+		Action:    storage.AuditActionDelete,
+		ScanID:    scanID,
+		CreatedAt: time.Now(),
+	}}
+	m.EXPECT().AuditLog(ctx,
+		scanner.AuditFilter{UserID: userID, Action: storage.AuditActionDelete},
+		"",
+		uint(5)).Return(entries, "42", nil)
+
+	res, err := h.ListAdminAuditLog(ctx, params)
+	require.NoError(t, err)
+	lst := res.(*v1specs.AuditEntryList)
+	require.Len(t, lst.Items, 1)
+	require.Equal(t, int64(42), lst.Items[0].ID)
+	require.Equal(t, uuid.UUID(userID), lst.Items[0].UserId)
+	require.Equal(t, uuid.UUID(scanID), lst.Items[0].ScanId)
+	require.Equal(t, v1specs.AuditActionDELETE, lst.Items[0].Action)
+	require.Equal(t, "42", lst.NextCursor.Value)
+}
+
+func TestHandler_RequeueAndForceFailScan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockscanner.NewMockScanner(ctrl)
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{})
+
+	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, domain.UserID(uuid.New()))
+	scan := sampleScan(domain.UserID(uuid.New()), "https://abc.xyz")
+	id := uuid.UUID(scan.ID)
+	req := &v1specs.ForceFailScanRequest{Reason: "job lost"}
+
+	// regular users are forbidden
+	_, err := h.RequeueScan(ctx, v1specs.RequeueScanParams{ID: id})
+	require.ErrorIs(t, err, serrors.ErrForbidden)
+	_, err = h.ForceFailScan(ctx, req, v1specs.ForceFailScanParams{ID: id})
+	require.ErrorIs(t, err, serrors.ErrForbidden)
+
+	ctx = context.WithValue(ctx, v1handler.RolesKey, v1handler.Roles{v1handler.RoleAdmin})
+
+	m.EXPECT().Requeue(ctx, scan.ID).Return(&scan, nil)
+	res, err := h.RequeueScan(ctx, v1specs.RequeueScanParams{ID: id})
+	require.NoError(t, err)
+	require.Equal(t, id, res.(*v1specs.Scan).ID)
+
+	failed := scan
+	failed.Status = domain.ScanStatusFailed
+	m.EXPECT().ForceFail(ctx, scan.ID, "job lost").Return(&failed, nil)
+	res2, err := h.ForceFailScan(ctx, req, v1specs.ForceFailScanParams{ID: id})
+	require.NoError(t, err)
+	require.Equal(t, v1specs.ScanStatusFAILED, res2.(*v1specs.Scan).Status)
+
+	// precondition errors are propagated for NewError to map
+	m.EXPECT().Requeue(ctx, scan.ID).Return(nil, serrors.With(serrors.ErrConflict, "scan is completed, not pending"))
+	_, err = h.RequeueScan(ctx, v1specs.RequeueScanParams{ID: id})
+	require.ErrorIs(t, err, serrors.ErrConflict)
+}
@@ -0,0 +1,38 @@
+package v1handler
+
+import (
+	"fmt"
+	"scanner/pkg/domain"
+	"strings"
+)
+
+// ScanETag returns a weak entity tag for the scan's API representation. Every
+// change to a scan refreshes its UpdatedAt, so the tag is derived from it (or
+// CreatedAt for never-updated scans) together with the status. NextRetryAt
+// comes from the job queue rather than the scan, so it is part of the tag too.
+func ScanETag(s *domain.Scan) string {
+	changedAt := s.UpdatedAt
+	if changedAt.IsZero() {
+		changedAt = s.CreatedAt
+	}
+
+	etag := fmt.Sprintf("%x-%d-%s", s.ID, changedAt.UnixNano(), strings.ToLower(string(s.Status)))
+	if !s.NextRetryAt.IsZero() {
+		etag += fmt.Sprintf("-%d", s.NextRetryAt.UnixNano())
+	}
+
+	return `W/"` + etag + `"`
+}
+
+// ETagMatches reports whether an If-None-Match header value matches etag using
+// weak comparison. The header may contain a comma-separated list of tags or "*".
+func ETagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,19 @@
+package v1handler_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"scanner/internal/api/handler/v1handler"
+)
+
+func TestETagMatches(t *testing.T) {
+	etag := `W/"abc"`
+	require.True(t, v1handler.ETagMatches(`W/"abc"`, etag))
+	require.True(t, v1handler.ETagMatches(`"abc"`, etag))
+	require.True(t, v1handler.ETagMatches(`W/"x", W/"abc"`, etag))
+	require.True(t, v1handler.ETagMatches("*", etag))
+	require.False(t, v1handler.ETagMatches(`W/"abd"`, etag))
+	require.False(t, v1handler.ETagMatches("", etag))
+}
@@ -0,0 +1,174 @@
+package v1handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"scanner/internal/api/specs/v1specs"
+	"scanner/internal/scanner"
+	"scanner/pkg/domain"
+	"scanner/pkg/storage"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// contentDisposition returns the Content-Disposition of an export suggesting
+// the given file name to clients saving it.
+func contentDisposition(filename string) v1specs.OptString {
+	return v1specs.NewOptString(fmt.Sprintf("attachment; filename=%q", filename))
+}
+
+// csvHeader lists the columns of CSV exports.
+var csvHeader = []string{ //nolint: gochecknoglobals
+	"id", "url", "status", "malicious", "score", "created_at", "updated_at",
+}
+
+// ExportScans streams all scans of the user as newline-delimited JSON, or as
+// CSV when requested. The first page is fetched before responding so that its
+// errors are returned as API errors; the following pages are fetched while the
+// response is written, so the export is never held in memory as a whole.
+func (h Handler) ExportScans(ctx context.Context, params v1specs.ExportScansParams) (v1specs.ExportScansRes, error) {
+	e := &scanExporter{
+		ctx:     ctx,
+		scanner: h.deps.Scanner,
+		userID:  GetUserIDFromContext(ctx),
+		limit:   uint(h.options.maxLimit()), //nolint: gosec
+	}
+
+	format := params.Format.Or(v1specs.ExportScansFormatNdjson)
+	if format == v1specs.ExportScansFormatCsv {
+		e.encode = newCSVEncoder(&e.buf)
+	} else {
+		e.encode = newNDJSONEncoder(&e.buf)
+	}
+	if err := e.nextPage(); err != nil {
+		return nil, err
+	}
+
+	if format == v1specs.ExportScansFormatCsv {
+		return &v1specs.ExportScansOKTextCsvHeaders{
+			ContentDisposition: contentDisposition("scans.csv"),
+			Response:           v1specs.ExportScansOKTextCsv{Data: e},
+		}, nil
+	}
+
+	return &v1specs.ExportScansOKApplicationXNdjsonHeaders{
+		ContentDisposition: contentDisposition("scans.ndjson"),
+		Response:           v1specs.ExportScansOKApplicationXNdjson{Data: e},
+	}, nil
+}
+
+// scanEncoder appends a page of scans to an export.
+type scanEncoder func(scans []domain.Scan) error
+
+// newNDJSONEncoder returns a scanEncoder writing each scan to buf as a JSON line.
+func newNDJSONEncoder(buf *bytes.Buffer) scanEncoder {
+	return func(scans []domain.Scan) error {
+		for i := range scans {
+			v1s, err := DomainScanToV1Specs(&scans[i])
+			if err != nil {
+				return err
+			}
+			line, err := v1s.MarshalJSON()
+			if err != nil {
+				return fmt.Errorf("could not marshal scan: %w", err)
+			}
+			buf.Write(line)
+			buf.WriteByte('\n')
+		}
+
+		return nil
+	}
+}
+
+// newCSVEncoder returns a scanEncoder writing each scan to buf as a CSV row,
+// preceded by csvHeader. Verdict fields of scans without one and the update
+// time of never updated scans are left empty.
+func newCSVEncoder(buf *bytes.Buffer) scanEncoder {
+	w := csv.NewWriter(buf)
+	headerWritten := false
+
+	return func(scans []domain.Scan) error {
+		if !headerWritten {
+			if err := w.Write(csvHeader); err != nil {
+				return fmt.Errorf("could not write csv header: %w", err)
+			}
+			headerWritten = true
+		}
+
+		for _, s := range scans {
+			var malicious, score, updatedAt string
+			if s.Result.Verdict != nil {
+				malicious = strconv.FormatBool(s.Result.Verdict.Malicious)
+				score = strconv.Itoa(s.Result.Verdict.Score)
+			}
+			if !s.UpdatedAt.IsZero() {
+				updatedAt = s.UpdatedAt.Format(time.RFC3339)
+			}
+
+			if err := w.Write([]string{
+				uuid.UUID(s.ID).String(),
+				s.URL,
+				string(s.Status),
+				malicious,
+				score,
+				s.CreatedAt.Format(time.RFC3339),
+				updatedAt,
+			}); err != nil {
+				return fmt.Errorf("could not write csv row: %w", err)
+			}
+		}
+		w.Flush()
+
+		return w.Error() //nolint: wrapcheck
+	}
+}
+
+// scanExporter is an io.Reader paging through the scans of a user with
+// Scanner.UserScans and encoding them into buf.
+type scanExporter struct {
+	ctx     context.Context //nolint: containedctx
+	scanner scanner.Scanner
+	userID  domain.UserID
+	limit   uint
+	encode  scanEncoder
+
+	buf    bytes.Buffer
+	cursor string
+	done   bool
+}
+
+// Read implements io.Reader, fetching the next page whenever the buffered
+// output is consumed.
+func (e *scanExporter) Read(p []byte) (int, error) {
+	for e.buf.Len() == 0 {
+		if e.done {
+			return 0, io.EOF
+		}
+		if err := e.nextPage(); err != nil {
+			return 0, err
+		}
+	}
+
+	return e.buf.Read(p) //nolint: wrapcheck
+}
+
+// nextPage fetches the page at the current cursor and encodes its scans.
+func (e *scanExporter) nextPage() error {
+	scans, nextCursor, _, err := e.scanner.UserScans(e.ctx, e.userID, "", false, e.cursor, storage.PageNext, e.limit)
+	if err != nil {
+		return err //nolint: wrapcheck
+	}
+	if err := e.encode(scans); err != nil {
+		return err
+	}
+
+	e.cursor = nextCursor
+	e.done = nextCursor == ""
+
+	return nil
+}
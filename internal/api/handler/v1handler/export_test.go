@@ -0,0 +1,140 @@
+package v1handler_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"scanner/internal/api/handler/v1handler"
+	"scanner/internal/api/specs/v1specs"
+	mockscanner "scanner/internal/scanner/mock"
+	"scanner/pkg/domain"
+	"scanner/pkg/storage"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandler_ExportScans(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockscanner.NewMockScanner(ctrl)
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{MaxLimit: 2})
+
+	userID := domain.UserID(uuid.New())
+	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, userID)
+
+	pages := [][]domain.Scan{
+		{sampleScan(userID, "https://a"), sampleScan(userID, "https://b")},
+		{sampleScan(userID, "https://c"), sampleScan(userID, "https://d")},
+		{sampleScan(userID, "https://e")},
+	}
+	gomock.InOrder(
+		m.EXPECT().UserScans(ctx, userID, domain.ScanStatus(""), false, "", storage.PageNext, uint(2)).Return(pages[0], "c1", "", nil),
+		m.EXPECT().UserScans(ctx, userID, domain.ScanStatus(""), false, "c1", storage.PageNext, uint(2)).Return(pages[1], "c2", "", nil),
+		m.EXPECT().UserScans(ctx, userID, domain.ScanStatus(""), false, "c2", storage.PageNext, uint(2)).Return(pages[2], "", "", nil),
+	)
+
+	res, err := h.ExportScans(ctx, v1specs.ExportScansParams{})
+	require.NoError(t, err)
+	export := res.(*v1specs.ExportScansOKApplicationXNdjsonHeaders)
+	require.Equal(t, `attachment; filename="scans.ndjson"`, export.ContentDisposition.Value)
+
+	var ids []uuid.UUID
+	lines := bufio.NewScanner(export.Response)
+	for lines.Scan() {
+		var s v1specs.Scan
+		require.NoError(t, s.UnmarshalJSON(lines.Bytes()))
+		ids = append(ids, s.ID)
+	}
+	require.NoError(t, lines.Err())
+
+	var want []uuid.UUID
+	for _, page := range pages {
+		for _, s := range page {
+			want = append(want, uuid.UUID(s.ID))
+		}
+	}
+	require.Equal(t, want, ids)
+}
+
+func TestHandler_ExportScans_CSV(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockscanner.NewMockScanner(ctrl)
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{MaxLimit: 1})
+
+	userID := domain.UserID(uuid.New())
+	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, userID)
+
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	completed := sampleScan(userID, "https://a")
+	completed.CreatedAt = createdAt
+	completed.UpdatedAt = createdAt.Add(time.Minute)
+	completed.Result.Verdict = &struct {
+		Malicious  bool     `json:"malicious"`
+		Score      int      `json:"score"`
+		Categories []string `json:"categories,omitempty"`
+		Brands     []string `json:"brands,omitempty"`
+	}{
+		Malicious: true,
+		Score:     42,
+	}
+	pending := sampleScan(userID, "https://b")
+	pending.Status = domain.ScanStatusPending
+	pending.CreatedAt = createdAt
+	pending.UpdatedAt = time.Time{}
+
+	gomock.InOrder(
+		m.EXPECT().UserScans(ctx, userID, domain.ScanStatus(""), false, "", storage.PageNext, uint(1)).
+			Return([]domain.Scan{completed}, "c1", "", nil),
+		m.EXPECT().UserScans(ctx, userID, domain.ScanStatus(""), false, "c1", storage.PageNext, uint(1)).
+			Return([]domain.Scan{pending}, "", "", nil),
+	)
+
+	res, err := h.ExportScans(ctx, v1specs.ExportScansParams{Format: v1specs.NewOptExportScansFormat(v1specs.ExportScansFormatCsv)})
+	require.NoError(t, err)
+	export := res.(*v1specs.ExportScansOKTextCsvHeaders)
+	require.Equal(t, `attachment; filename="scans.csv"`, export.ContentDisposition.Value)
+
+	rows, err := csv.NewReader(export.Response).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"id", "url", "status", "malicious", "score", "created_at", "updated_at"},
+		{
+			uuid.UUID(completed.ID).String(), "https://a", "COMPLETED", "true", "42",
+			"2025-01-02T03:04:05Z", "2025-01-02T03:05:05Z",
+		},
+		// missing result fields are left empty
+		{uuid.UUID(pending.ID).String(), "https://b", "PENDING", "", "", "2025-01-02T03:04:05Z", ""},
+	}, rows)
+}
+
+func TestHandler_ExportScans_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockscanner.NewMockScanner(ctrl)
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{MaxLimit: 1})
+
+	userID := domain.UserID(uuid.New())
+	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, userID)
+	boom := errors.New("boom")
+
+	// errors of the first page are returned before responding
+	m.EXPECT().UserScans(ctx, userID, domain.ScanStatus(""), false, "", storage.PageNext, uint(1)).Return(nil, "", "", boom)
+	_, err := h.ExportScans(ctx, v1specs.ExportScansParams{})
+	require.ErrorIs(t, err, boom)
+
+	// errors of later pages abort the stream
+	m.EXPECT().UserScans(ctx, userID, domain.ScanStatus(""), false, "", storage.PageNext, uint(1)).
+		Return([]domain.Scan{sampleScan(userID, "https://a")}, "c1", "", nil)
+	m.EXPECT().UserScans(ctx, userID, domain.ScanStatus(""), false, "c1", storage.PageNext, uint(1)).Return(nil, "", "", boom)
+	res, err := h.ExportScans(ctx, v1specs.ExportScansParams{})
+	require.NoError(t, err)
+	_, err = io.ReadAll(res.(*v1specs.ExportScansOKApplicationXNdjsonHeaders).Response)
+	require.ErrorIs(t, err, boom)
+}
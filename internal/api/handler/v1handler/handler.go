@@ -8,9 +8,13 @@ import (
 	"errors"
 	"net/http"
 	"scanner/internal/api/specs/v1specs"
+	"scanner/internal/config"
 	"scanner/internal/scanner"
+	"scanner/pkg/clock"
+	"scanner/pkg/controller"
 	"scanner/pkg/logger"
 	"scanner/pkg/serrors"
+	"time"
 
 	"github.com/ogen-go/ogen/ogenerrors"
 	"go.uber.org/zap"
@@ -21,26 +25,124 @@ type Deps struct {
 	Scanner scanner.Scanner
 }
 
+// Options holds runtime settings of the Handler.
+type Options struct {
+	// MaxLimit is the maximum page size of list endpoints; larger requested
+	// limits are clamped to it. Values <= 0 use DefaultMaxLimit.
+	MaxLimit int
+	// MaxSyncScanTimeout is the longest CreateScanSync waits for a scan;
+	// larger requested timeouts are clamped to it. Values <= 0 use
+	// DefaultMaxSyncScanTimeout.
+	MaxSyncScanTimeout time.Duration
+	// SyncScanPollInterval is how often CreateScanSync checks the scan status.
+	// Values <= 0 use DefaultSyncScanPollInterval.
+	SyncScanPollInterval time.Duration
+	// Clock is used to wait between status checks. Nil uses the real clock.
+	Clock clock.Clock
+}
+
+// NewOptions constructs Options from application configuration.
+func NewOptions(cfg *config.Config) Options {
+	return Options{
+		MaxLimit:           cfg.HTTP.MaxPageLimit,
+		MaxSyncScanTimeout: cfg.HTTP.MaxSyncScanTimeout,
+	}
+}
+
+// maxLimit returns the configured MaxLimit, falling back to DefaultMaxLimit.
+func (o Options) maxLimit() int {
+	if o.MaxLimit > 0 {
+		return o.MaxLimit
+	}
+
+	return DefaultMaxLimit
+}
+
+// maxSyncScanTimeout returns the configured MaxSyncScanTimeout, falling back to
+// DefaultMaxSyncScanTimeout.
+func (o Options) maxSyncScanTimeout() time.Duration {
+	if o.MaxSyncScanTimeout > 0 {
+		return o.MaxSyncScanTimeout
+	}
+
+	return DefaultMaxSyncScanTimeout
+}
+
+// syncScanPollInterval returns the configured SyncScanPollInterval, falling
+// back to DefaultSyncScanPollInterval.
+func (o Options) syncScanPollInterval() time.Duration {
+	if o.SyncScanPollInterval > 0 {
+		return o.SyncScanPollInterval
+	}
+
+	return DefaultSyncScanPollInterval
+}
+
 // Handler implements v1specs.Handler and provides endpoint methods for the v1 API.
 type Handler struct {
-	deps Deps
+	deps    Deps
+	options Options
 }
 
 // Ensure Handler implements v1specs.Handler.
 var _ v1specs.Handler = (*Handler)(nil)
 
 // New constructs and returns a new Handler instance.
-func New(deps Deps) *Handler {
+func New(deps Deps, options Options) *Handler {
 	return &Handler{
-		deps: deps,
+		deps:    deps,
+		options: options,
 	}
 }
 
+// errorMapping describes how a semantic error kind is exposed by the API.
+type errorMapping struct {
+	// status is the HTTP status code of the response.
+	status int
+	// code is the machine-readable API error code.
+	code v1specs.ErrorCode
+	// msg is the default message used when the error carries none.
+	msg string
+}
+
+// errorMappings maps every serrors kind to its API representation. Kinds not
+// listed here are treated as internal errors.
+var errorMappings = map[serrors.Kind]errorMapping{ //nolint: gochecknoglobals
+	serrors.ErrBadRequest:   {http.StatusBadRequest, v1specs.ErrorCodeBADREQUEST, "bad request"},
+	serrors.ErrUnauthorized: {http.StatusUnauthorized, v1specs.ErrorCodeUNAUTHORIZED, "unauthorized"},
+	serrors.ErrForbidden:    {http.StatusForbidden, v1specs.ErrorCodeFORBIDDEN, "forbidden"},
+	serrors.ErrNotFound:     {http.StatusNotFound, v1specs.ErrorCodeNOTFOUND, "resource not found"},
+	serrors.ErrConflict:     {http.StatusConflict, v1specs.ErrorCodeCONFLICT, "conflict"},
+	serrors.ErrRateLimited:  {http.StatusTooManyRequests, v1specs.ErrorCodeRATELIMITED, "too many requests"},
+	serrors.ErrUnavailable:  {http.StatusServiceUnavailable, v1specs.ErrorCodeUNAVAILABLE, "service unavailable"},
+	serrors.ErrTimeout:      {http.StatusGatewayTimeout, v1specs.ErrorCodeTIMEOUT, "request timed out"},
+}
+
+// internalErrorMapping is used for ErrInternal and errors without a known kind.
+var internalErrorMapping = errorMapping{ //nolint: gochecknoglobals
+	http.StatusInternalServerError, v1specs.ErrorCodeINTERNAL, "internal error",
+}
+
+// payloadTooLargeMapping is used when reading the request body exceeded the
+// limit set by controller.WithMaxBodyBytes.
+var payloadTooLargeMapping = errorMapping{ //nolint: gochecknoglobals
+	http.StatusRequestEntityTooLarge, v1specs.ErrorCodePAYLOADTOOLARGE, "request body too large",
+}
+
 // NewError maps internal errors into an API-friendly error response with an HTTP status code.
 // It inspects wrapped semantic errors (serrors.Error) and well-known kinds
-// to select status code and message. Internal/unknown errors are logged and
-// converted to a generic 500 response.
+// to select status code, error code and message (see errorMappings).
+// Request bodies cut off by http.MaxBytesReader are reported as 413.
+// Internal/unknown errors are logged and converted to a generic 500 response.
+// Retry hints of semantic errors are passed on to WithRetryAfter, and the
+// request ID set by controller.WithLogger is included in the response.
 func (h Handler) NewError(ctx context.Context, err error) *v1specs.ServerErrorStatusCode {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return newErrorResponse(ctx,
+			payloadTooLargeMapping.status, payloadTooLargeMapping.code, payloadTooLargeMapping.msg)
+	}
+
 	var kind serrors.Kind
 	var sem *serrors.Error
 	// try to extract semantic kind or error wrapper
@@ -55,47 +157,40 @@ func (h Handler) NewError(ctx context.Context, err error) *v1specs.ServerErrorSt
 		kind = serrors.ErrUnauthorized
 	}
 
-	status := http.StatusInternalServerError
-	code := serrors.ErrInternal.Error()
-	msg := "internal error"
-
-	// map known kinds to HTTP status codes and messages.
-	if kind != nil {
-		switch kind {
-		case serrors.ErrNotFound:
-			status = http.StatusNotFound
-			code = serrors.ErrNotFound.Error()
-			msg = "resource not found"
-		case serrors.ErrUnauthorized:
-			status = http.StatusUnauthorized
-			code = serrors.ErrUnauthorized.Error()
-			msg = "unauthorized"
-		case serrors.ErrBadRequest:
-			status = http.StatusBadRequest
-			code = serrors.ErrBadRequest.Error()
-			msg = "bad request"
-		case serrors.ErrInternal:
-			// keep defaults
-		}
-	}
-
+	mapping, ok := errorMappings[kind]
 	// for internal or non-sentinel errors, log full error and respond with generic internal error
-	if kind == nil || errors.Is(kind, serrors.ErrInternal) {
+	if !ok {
 		logger.Error(ctx, "error in handling requests", zap.Error(err))
 
-		return &v1specs.ServerErrorStatusCode{
-			StatusCode: http.StatusInternalServerError,
-			Response:   v1specs.Error{Code: code, Message: msg},
-		}
+		return newErrorResponse(ctx, internalErrorMapping.status, internalErrorMapping.code, internalErrorMapping.msg)
 	}
 
 	// for known non-internal kinds, include message if provided
+	msg := mapping.msg
 	if sem != nil && sem.Message() != "" {
 		msg = sem.Message()
 	}
 
-	return &v1specs.ServerErrorStatusCode{
+	// let WithRetryAfter set the Retry-After header
+	if sem != nil && sem.RetryAfter() > 0 {
+		setRetryAfter(ctx, sem.RetryAfter())
+	}
+
+	return newErrorResponse(ctx, mapping.status, mapping.code, msg)
+}
+
+// newErrorResponse builds an error response carrying the request ID of ctx, if any.
+func newErrorResponse(ctx context.Context,
+	status int,
+	code v1specs.ErrorCode,
+	msg string) *v1specs.ServerErrorStatusCode {
+	res := &v1specs.ServerErrorStatusCode{
 		StatusCode: status,
 		Response:   v1specs.Error{Code: code, Message: msg},
 	}
+	if requestID := controller.GetRequestID(ctx); requestID != "" {
+		res.Response.RequestId = v1specs.NewOptString(requestID)
+	}
+
+	return res
 }
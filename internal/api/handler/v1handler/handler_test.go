@@ -3,12 +3,17 @@ package v1handler_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
 	"scanner/internal/api/handler/v1handler"
+	"scanner/internal/api/specs/v1specs"
 	"testing"
 
+	"scanner/pkg/controller"
 	"scanner/pkg/logger"
 	"scanner/pkg/serrors"
 
+	"github.com/ogen-go/ogen/ogenerrors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -19,57 +24,170 @@ func TestMain(m *testing.M) {
 }
 
 func TestNewError_InternalOnPlainError(t *testing.T) {
-	h := v1handler.New(v1handler.Deps{})
+	h := v1handler.New(v1handler.Deps{}, v1handler.Options{})
 	ctx := context.Background()
 
 	res := h.NewError(ctx, errors.New("boom"))
 	require.NotNil(t, res)
 	require.Equal(t, 500, res.StatusCode)
-	require.Equal(t, serrors.ErrInternal.Error(), res.Response.Code)
+	require.Equal(t, v1specs.ErrorCodeINTERNAL, res.Response.Code)
 	require.Equal(t, "internal error", res.Response.Message)
 }
 
 func TestNewError_KindSentinelDirect_NotFound(t *testing.T) {
-	h := v1handler.New(v1handler.Deps{})
+	h := v1handler.New(v1handler.Deps{}, v1handler.Options{})
 	ctx := context.Background()
 
 	// Pass the Kind sentinel directly
 	res := h.NewError(ctx, serrors.ErrNotFound)
 	require.Equal(t, 404, res.StatusCode)
-	require.Equal(t, serrors.ErrNotFound.Error(), res.Response.Code)
+	require.Equal(t, v1specs.ErrorCodeNOTFOUND, res.Response.Code)
 	require.Equal(t, "resource not found", res.Response.Message)
 }
 
 func TestNewError_SemanticWithMessage_BadRequest(t *testing.T) {
-	h := v1handler.New(v1handler.Deps{})
+	h := v1handler.New(v1handler.Deps{}, v1handler.Options{})
 	ctx := context.Background()
 
 	err := serrors.With(serrors.ErrBadRequest, "invalid payload: missing url")
 	res := h.NewError(ctx, err)
 	require.Equal(t, 400, res.StatusCode)
-	require.Equal(t, serrors.ErrBadRequest.Error(), res.Response.Code)
+	require.Equal(t, v1specs.ErrorCodeBADREQUEST, res.Response.Code)
 	require.Equal(t, "invalid payload: missing url", res.Response.Message)
 }
 
 func TestNewError_SemanticWrap_Unauthorized(t *testing.T) {
-	h := v1handler.New(v1handler.Deps{})
+	h := v1handler.New(v1handler.Deps{}, v1handler.Options{})
 	ctx := context.Background()
 
 	cause := errors.New("bad token")
 	err := serrors.Wrap(serrors.ErrUnauthorized, cause, "unauthorized")
 	res := h.NewError(ctx, err)
 	require.Equal(t, 401, res.StatusCode)
-	require.Equal(t, serrors.ErrUnauthorized.Error(), res.Response.Code)
+	require.Equal(t, v1specs.ErrorCodeUNAUTHORIZED, res.Response.Code)
 	// Should include provided message, not the cause
 	require.Equal(t, "unauthorized", res.Response.Message)
 }
 
 func TestNewError_InternalKind_GeneratesInternal(t *testing.T) {
-	h := v1handler.New(v1handler.Deps{})
+	h := v1handler.New(v1handler.Deps{}, v1handler.Options{})
 	ctx := context.Background()
 
 	res := h.NewError(ctx, serrors.KindOnly(serrors.ErrInternal))
 	require.Equal(t, 500, res.StatusCode)
-	require.Equal(t, serrors.ErrInternal.Error(), res.Response.Code)
+	require.Equal(t, v1specs.ErrorCodeINTERNAL, res.Response.Code)
 	require.Equal(t, "internal error", res.Response.Message)
 }
+
+func TestNewError_SemanticWithMessage_Forbidden(t *testing.T) {
+	h := v1handler.New(v1handler.Deps{}, v1handler.Options{})
+	ctx := context.Background()
+
+	err := serrors.With(serrors.ErrForbidden, "scanning this domain is not allowed")
+	res := h.NewError(ctx, err)
+	require.Equal(t, 403, res.StatusCode)
+	require.Equal(t, v1specs.ErrorCodeFORBIDDEN, res.Response.Code)
+	require.Equal(t, "scanning this domain is not allowed", res.Response.Message)
+}
+
+func TestNewError_KindMapping(t *testing.T) {
+	h := v1handler.New(v1handler.Deps{}, v1handler.Options{})
+	ctx := context.Background()
+
+	tests := []struct {
+		kind   serrors.Kind
+		status int
+		code   v1specs.ErrorCode
+	}{
+		{serrors.ErrBadRequest, http.StatusBadRequest, v1specs.ErrorCodeBADREQUEST},
+		{serrors.ErrUnauthorized, http.StatusUnauthorized, v1specs.ErrorCodeUNAUTHORIZED},
+		{serrors.ErrForbidden, http.StatusForbidden, v1specs.ErrorCodeFORBIDDEN},
+		{serrors.ErrNotFound, http.StatusNotFound, v1specs.ErrorCodeNOTFOUND},
+		{serrors.ErrConflict, http.StatusConflict, v1specs.ErrorCodeCONFLICT},
+		{serrors.ErrRateLimited, http.StatusTooManyRequests, v1specs.ErrorCodeRATELIMITED},
+		{serrors.ErrUnavailable, http.StatusServiceUnavailable, v1specs.ErrorCodeUNAVAILABLE},
+		{serrors.ErrTimeout, http.StatusGatewayTimeout, v1specs.ErrorCodeTIMEOUT},
+		{serrors.ErrInternal, http.StatusInternalServerError, v1specs.ErrorCodeINTERNAL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind.Error(), func(t *testing.T) {
+			// sentinel, kind-only and wrapped errors map identically
+			for _, err := range []error{
+				tt.kind,
+				serrors.KindOnly(tt.kind),
+				fmt.Errorf("context: %w", serrors.Wrap(tt.kind, errors.New("cause"), "")),
+			} {
+				res := h.NewError(ctx, err)
+				require.Equal(t, tt.status, res.StatusCode)
+				require.Equal(t, tt.code, res.Response.Code)
+				require.NotEmpty(t, res.Response.Message)
+			}
+		})
+	}
+}
+
+func TestNewError_MaxBytesError_PayloadTooLarge(t *testing.T) {
+	h := v1handler.New(v1handler.Deps{}, v1handler.Options{})
+	ctx := context.Background()
+
+	// ogen wraps body read failures in a DecodeRequestError
+	err := &ogenerrors.DecodeRequestError{
+		OperationContext: ogenerrors.OperationContext{Name: "CreateScan"},
+		Err:              fmt.Errorf("read: %w", &http.MaxBytesError{Limit: 16}),
+	}
+	res := h.NewError(ctx, err)
+	require.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+	require.Equal(t, v1specs.ErrorCodePAYLOADTOOLARGE, res.Response.Code)
+	require.Equal(t, "request body too large", res.Response.Message)
+}
+
+func TestNewError_PreservesMessageForNonInternalKinds(t *testing.T) {
+	h := v1handler.New(v1handler.Deps{}, v1handler.Options{})
+	ctx := context.Background()
+
+	kinds := []serrors.Kind{
+		serrors.ErrBadRequest,
+		serrors.ErrUnauthorized,
+		serrors.ErrForbidden,
+		serrors.ErrNotFound,
+		serrors.ErrConflict,
+		serrors.ErrRateLimited,
+		serrors.ErrUnavailable,
+		serrors.ErrTimeout,
+	}
+	for _, kind := range kinds {
+		t.Run(kind.Error(), func(t *testing.T) {
+			res := h.NewError(ctx, serrors.With(kind, "custom message"))
+			require.NotEqual(t, http.StatusInternalServerError, res.StatusCode)
+			require.Equal(t, "custom message", res.Response.Message)
+		})
+	}
+
+	// internal errors never leak their message
+	res := h.NewError(ctx, serrors.With(serrors.ErrInternal, "db password is wrong"))
+	require.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	require.Equal(t, "internal error", res.Response.Message)
+
+	// unknown kinds are treated as internal
+	res = h.NewError(ctx, serrors.With(serrors.NewKind("CUSTOM"), "custom message"))
+	require.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	require.Equal(t, v1specs.ErrorCodeINTERNAL, res.Response.Code)
+	require.Equal(t, "internal error", res.Response.Message)
+}
+
+func TestNewError_IncludesRequestID(t *testing.T) {
+	h := v1handler.New(v1handler.Deps{}, v1handler.Options{})
+
+	ctx := context.WithValue(context.Background(), controller.RequestIDKey, "req-123")
+	res := h.NewError(ctx, serrors.With(serrors.ErrNotFound, "scan not found"))
+	require.Equal(t, "req-123", res.Response.RequestId.Or(""))
+
+	// internal errors carry it as well
+	res = h.NewError(ctx, errors.New("boom"))
+	require.Equal(t, "req-123", res.Response.RequestId.Or(""))
+
+	// omitted without a request ID
+	res = h.NewError(context.Background(), serrors.KindOnly(serrors.ErrNotFound))
+	require.False(t, res.Response.RequestId.IsSet())
+}
@@ -0,0 +1,70 @@
+package v1handler
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// retryAfterKey is the context key under which WithRetryAfter stores the
+// per-request retryAfter holder.
+type retryAfterKey struct{}
+
+// retryAfter carries the Retry-After hint from NewError to WithRetryAfter.
+// It is stored as a pointer in the request context, so NewError can set it
+// while the response is being produced.
+type retryAfter struct {
+	delay time.Duration
+}
+
+// setRetryAfter records the Retry-After hint of the current request. It is a
+// no-op when the request is not served through WithRetryAfter.
+func setRetryAfter(ctx context.Context, delay time.Duration) {
+	if holder, _ := ctx.Value(retryAfterKey{}).(*retryAfter); holder != nil {
+		holder.delay = delay
+	}
+}
+
+// retryAfterWriter sets the Retry-After header right before 429 and 503
+// status codes are written.
+type retryAfterWriter struct {
+	http.ResponseWriter
+
+	holder       *retryAfter
+	defaultDelay time.Duration
+}
+
+// WriteHeader sets Retry-After for 429 and 503 responses and forwards the call.
+func (w *retryAfterWriter) WriteHeader(code int) {
+	if code == http.StatusTooManyRequests || code == http.StatusServiceUnavailable {
+		delay := w.holder.delay
+		if delay <= 0 {
+			delay = w.defaultDelay
+		}
+		if delay > 0 && w.Header().Get("Retry-After") == "" {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// WithRetryAfter returns a middleware that adds a Retry-After header (in
+// seconds) to 429 Too Many Requests and 503 Service Unavailable responses.
+// The delay comes from the serrors.Error handled by NewError when it carries a
+// retry hint, or defaultDelay otherwise. A zero defaultDelay only sets the
+// header for errors with a hint.
+func WithRetryAfter(next http.Handler, defaultDelay time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		holder := &retryAfter{}
+		ctx := context.WithValue(r.Context(), retryAfterKey{}, holder)
+
+		next.ServeHTTP(&retryAfterWriter{
+			ResponseWriter: w,
+			holder:         holder,
+			defaultDelay:   defaultDelay,
+		}, r.WithContext(ctx))
+	})
+}
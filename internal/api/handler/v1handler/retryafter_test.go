@@ -0,0 +1,61 @@
+package v1handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"scanner/internal/api/handler/v1handler"
+	"scanner/pkg/serrors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// errorServer responds the way the generated server does for handler errors:
+// it converts err with NewError and writes the resulting status code.
+func errorServer(err error) http.Handler {
+	h := v1handler.New(v1handler.Deps{}, v1handler.Options{})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res := h.NewError(r.Context(), err)
+		w.WriteHeader(res.StatusCode)
+	})
+}
+
+func serveError(t *testing.T, err error, defaultDelay time.Duration) *http.Response {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	v1handler.WithRetryAfter(errorServer(err), defaultDelay).
+		ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/scans", nil))
+
+	return rec.Result()
+}
+
+func TestWithRetryAfter_UsesErrorHint(t *testing.T) {
+	err := serrors.With(serrors.ErrRateLimited, "slow down").WithRetryAfter(1500 * time.Millisecond)
+
+	res := serveError(t, err, 5*time.Second)
+	require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	require.Equal(t, "2", res.Header.Get("Retry-After"))
+}
+
+func TestWithRetryAfter_FallsBackToDefault(t *testing.T) {
+	res := serveError(t, serrors.KindOnly(serrors.ErrRateLimited), 5*time.Second)
+	require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	require.Equal(t, "5", res.Header.Get("Retry-After"))
+
+	res = serveError(t, serrors.KindOnly(serrors.ErrUnavailable), 5*time.Second)
+	require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	require.Equal(t, "5", res.Header.Get("Retry-After"))
+
+	// no hint and no default
+	res = serveError(t, serrors.KindOnly(serrors.ErrRateLimited), 0)
+	require.Empty(t, res.Header.Get("Retry-After"))
+}
+
+func TestWithRetryAfter_OtherStatuses(t *testing.T) {
+	res := serveError(t, serrors.With(serrors.ErrNotFound, "missing").WithRetryAfter(time.Minute), 5*time.Second)
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+	require.Empty(t, res.Header.Get("Retry-After"))
+}
@@ -5,12 +5,31 @@ import (
 	"fmt"
 	"net/url"
 	"scanner/internal/api/specs/v1specs"
+	"scanner/pkg/clock"
 	"scanner/pkg/domain"
+	"scanner/pkg/serrors"
+	"scanner/pkg/storage"
+	"scanner/pkg/urlscanner"
+	"time"
 
 	"github.com/google/uuid"
 )
 
-const DefaultLimit = 20
+const (
+	// DefaultLimit is the page size used when a list request sets no limit.
+	DefaultLimit = 20
+	// DefaultMaxLimit is the maximum page size used when Options.MaxLimit is unset.
+	DefaultMaxLimit = 100
+	// DefaultSyncScanTimeout is how long CreateScanSync waits when a request
+	// sets no timeout.
+	DefaultSyncScanTimeout = 10 * time.Second
+	// DefaultMaxSyncScanTimeout is the longest CreateScanSync waits when
+	// Options.MaxSyncScanTimeout is unset.
+	DefaultMaxSyncScanTimeout = 30 * time.Second
+	// DefaultSyncScanPollInterval is how often CreateScanSync checks the scan
+	// status when Options.SyncScanPollInterval is unset.
+	DefaultSyncScanPollInterval = 500 * time.Millisecond
+)
 
 func DomainScanResultToV1Specs(in *domain.ScanResult) *v1specs.ScanResult {
 	var out v1specs.ScanResult
@@ -49,6 +68,8 @@ func DomainScanResultToV1Specs(in *domain.ScanResult) *v1specs.ScanResult {
 		if v.Score != 0 {
 			ver.Score = v1specs.NewOptInt(v.Score)
 		}
+		ver.Categories = v.Categories
+		ver.Brands = v.Brands
 		out.Verdicts = ver
 	}
 	// Stats
@@ -75,6 +96,11 @@ func DomainScanToV1Specs(in *domain.Scan) (*v1specs.Scan, error) {
 		updateAt.SetTo(in.UpdatedAt)
 	}
 
+	nextRetryAt := v1specs.OptDateTime{}
+	if !in.NextRetryAt.IsZero() {
+		nextRetryAt.SetTo(in.NextRetryAt)
+	}
+
 	return &v1specs.Scan{
 		ID:        uuid.UUID(in.ID),
 		URL:       *URL,
@@ -83,12 +109,22 @@ func DomainScanToV1Specs(in *domain.Scan) (*v1specs.Scan, error) {
 		Attempts:  int(in.Attempts), //nolint: gosec
 		CreatedAt: in.CreatedAt,
 		UpdatedAt: updateAt,
+
+		NextRetryAt: nextRetryAt,
 	}, nil
 }
 
 // CreateScan schedules a new scan based on the provided request payload.
-func (h Handler) CreateScan(ctx context.Context, req *v1specs.CreateScanRequest) (v1specs.CreateScanRes, error) {
-	s, err := h.deps.Scanner.Enqueue(ctx, GetUserIDFromContext(ctx), req.URL.String())
+// Requests repeated with the same Idempotency-Key header return the original scan.
+func (h Handler) CreateScan(ctx context.Context,
+	req *v1specs.CreateScanRequest,
+	params v1specs.CreateScanParams) (v1specs.CreateScanRes, error) {
+	s, err := h.deps.Scanner.Enqueue(ctx,
+		GetUserIDFromContext(ctx),
+		req.URL.String(),
+		params.IdempotencyKey.Or(""),
+		req.Tags,
+		urlscanner.Visibility(req.Visibility.Value))
 	if err != nil {
 		return nil, err //nolint: wrapcheck
 	}
@@ -96,6 +132,79 @@ func (h Handler) CreateScan(ctx context.Context, req *v1specs.CreateScanRequest)
 	return DomainScanToV1Specs(s)
 }
 
+// CreateScanSync schedules a new scan like CreateScan and waits for it to
+// reach a terminal state. The wait is bounded by the requested timeout, capped
+// at Options.MaxSyncScanTimeout; a scan still pending afterwards is returned
+// with 202 Accepted.
+func (h Handler) CreateScanSync(ctx context.Context,
+	req *v1specs.CreateScanRequest,
+	params v1specs.CreateScanSyncParams) (v1specs.CreateScanSyncRes, error) {
+	userID := GetUserIDFromContext(ctx)
+	s, err := h.deps.Scanner.Enqueue(ctx,
+		userID,
+		req.URL.String(),
+		params.IdempotencyKey.Or(""),
+		req.Tags,
+		urlscanner.Visibility(req.Visibility.Value))
+	if err != nil {
+		return nil, err //nolint: wrapcheck
+	}
+
+	maxTimeout := h.options.maxSyncScanTimeout()
+	timeout := min(DefaultSyncScanTimeout, maxTimeout)
+	if seconds, ok := params.Timeout.Get(); ok {
+		// clamped before the conversion, which overflows for huge values
+		timeout = maxTimeout
+		if int64(seconds) <= int64(maxTimeout/time.Second) {
+			timeout = time.Duration(seconds) * time.Second
+		}
+	}
+	s, err = h.waitForScan(ctx, userID, s, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := DomainScanToV1Specs(s)
+	if err != nil {
+		return nil, err
+	}
+	if !s.IsTerminal() {
+		return (*v1specs.CreateScanSyncAccepted)(res), nil
+	}
+
+	return (*v1specs.CreateScanSyncOK)(res), nil
+}
+
+// waitForScan polls the status of s until it is terminal, timeout
+// elapsed or ctx is done, and returns its last seen state.
+func (h Handler) waitForScan(ctx context.Context,
+	userID domain.UserID,
+	s *domain.Scan,
+	timeout time.Duration) (*domain.Scan, error) {
+	clk := clock.OrReal(h.options.Clock)
+	deadline := clk.Now().Add(timeout)
+	for !s.IsTerminal() {
+		wait := min(h.options.syncScanPollInterval(), deadline.Sub(clk.Now()))
+		if wait <= 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return s, nil
+		case <-clk.After(wait):
+		}
+
+		next, err := h.deps.Scanner.Result(ctx, userID, s.ID)
+		if err != nil {
+			return nil, err //nolint: wrapcheck
+		}
+		s = next
+	}
+
+	return s, nil
+}
+
 // DeleteScan deletes a scan by ID.
 func (h Handler) DeleteScan(ctx context.Context, params v1specs.DeleteScanParams) (v1specs.DeleteScanRes, error) {
 	err := h.deps.Scanner.Delete(ctx, GetUserIDFromContext(ctx), domain.ScanID(params.ID))
@@ -107,26 +216,216 @@ func (h Handler) DeleteScan(ctx context.Context, params v1specs.DeleteScanParams
 }
 
 // GetScan returns details of a scan by ID.
+// A weak ETag is returned with the scan, and 304 Not Modified is returned when
+// If-None-Match still matches it.
 func (h Handler) GetScan(ctx context.Context, params v1specs.GetScanParams) (v1specs.GetScanRes, error) {
 	s, err := h.deps.Scanner.Result(ctx, GetUserIDFromContext(ctx), domain.ScanID(params.ID))
 	if err != nil {
 		return nil, err //nolint: wrapcheck
 	}
 
+	etag := ScanETag(s)
+	if ifNoneMatch, ok := params.IfNoneMatch.Get(); ok && ETagMatches(ifNoneMatch, etag) {
+		return &v1specs.GetScanNotModified{ETag: v1specs.NewOptString(etag)}, nil
+	}
+
+	res, err := DomainScanToV1Specs(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1specs.ScanHeaders{ETag: v1specs.NewOptString(etag), Response: *res}, nil
+}
+
+// ListScanAttempts returns the timeline of processing attempts of a single
+// scan of the user.
+func (h Handler) ListScanAttempts(
+	ctx context.Context,
+	params v1specs.ListScanAttemptsParams) (v1specs.ListScanAttemptsRes, error) {
+	attempts, err := h.deps.Scanner.Attempts(ctx, GetUserIDFromContext(ctx), domain.ScanID(params.ID))
+	if err != nil {
+		return nil, err //nolint: wrapcheck
+	}
+
+	items := make([]v1specs.ScanAttempt, 0, len(attempts))
+	for i := range attempts {
+		items = append(items, StorageScanAttemptToV1Specs(&attempts[i]))
+	}
+
+	return &v1specs.ScanAttemptList{Items: items}, nil
+}
+
+// StorageScanAttemptToV1Specs converts a storage.ScanAttempt into its v1
+// representation, omitting the error of successful attempts and an unknown
+// rate-limit reset time.
+func StorageScanAttemptToV1Specs(in *storage.ScanAttempt) v1specs.ScanAttempt {
+	out := v1specs.ScanAttempt{
+		ID: in.ID,
+		RateLimit: v1specs.RateLimitStatus{
+			Limit:     in.RateLimit.Limit,
+			Remaining: in.RateLimit.Remaining,
+		},
+		CreatedAt: in.CreatedAt,
+	}
+	if in.Error != "" {
+		out.Error = v1specs.NewOptString(in.Error)
+	}
+	if !in.RateLimit.ResetAt.IsZero() {
+		out.RateLimit.ResetAt = v1specs.NewOptDateTime(in.RateLimit.ResetAt)
+	}
+
+	return out
+}
+
+// BatchGetScans returns multiple scans by ID, listing the IDs without a
+// matching scan in NotFound.
+func (h Handler) BatchGetScans(
+	ctx context.Context,
+	req *v1specs.BatchGetScansRequest) (v1specs.BatchGetScansRes, error) {
+	ids := make([]domain.ScanID, 0, len(req.Ids))
+	for _, id := range req.Ids {
+		ids = append(ids, domain.ScanID(id))
+	}
+
+	scans, err := h.deps.Scanner.Results(ctx, GetUserIDFromContext(ctx), ids)
+	if err != nil {
+		return nil, err //nolint: wrapcheck
+	}
+
+	found := make(map[uuid.UUID]struct{}, len(scans))
+	items := make([]v1specs.Scan, 0, len(scans))
+	for i := range scans {
+		v1s, err := DomainScanToV1Specs(&scans[i])
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *v1s)
+		found[v1s.ID] = struct{}{}
+	}
+
+	notFound := make([]uuid.UUID, 0)
+	for _, id := range req.Ids {
+		if _, ok := found[id]; !ok {
+			found[id] = struct{}{} // report duplicates once
+			notFound = append(notFound, id)
+		}
+	}
+
+	return &v1specs.BatchGetScansResponse{
+		Items:    items,
+		NotFound: notFound,
+	}, nil
+}
+
+// GetLatestScan returns the latest scan of a URL.
+func (h Handler) GetLatestScan(
+	ctx context.Context,
+	params v1specs.GetLatestScanParams) (v1specs.GetLatestScanRes, error) {
+	s, err := h.deps.Scanner.LatestByURL(ctx, GetUserIDFromContext(ctx), params.URL.String())
+	if err != nil {
+		return nil, err //nolint: wrapcheck
+	}
+
 	return DomainScanToV1Specs(s)
 }
 
 // ListScans returns a paginated list of scans.
 func (h Handler) ListScans(ctx context.Context, params v1specs.ListScansParams) (v1specs.ListScansRes, error) {
-	scans, nextCursor, err := h.deps.Scanner.UserScans(ctx,
+	limit, err := h.pageLimit(params.Limit)
+	if err != nil {
+		return nil, err
+	}
+	status, err := scanStatus(params.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	scans, nextCursor, prevCursor, err := h.deps.Scanner.UserScans(ctx,
+		GetUserIDFromContext(ctx),
+		status,
+		params.Malicious.Or(false),
+		params.Cursor.Value,
+		storage.PageDirection(params.Direction.Or(v1specs.ListScansDirectionNext)),
+		limit)
+	if err != nil {
+		return nil, err //nolint: wrapcheck
+	}
+
+	res, err := domainScansToV1Specs(scans, nextCursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	if prevCursor != "" {
+		res.PrevCursor = v1specs.NewOptNilString(prevCursor)
+	}
+
+	return res, nil
+}
+
+// SearchScans returns a paginated list of scans matching a result page field.
+func (h Handler) SearchScans(ctx context.Context, params v1specs.SearchScansParams) (v1specs.SearchScansRes, error) {
+	limit, err := h.pageLimit(params.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	scans, nextCursor, err := h.deps.Scanner.SearchScans(ctx,
 		GetUserIDFromContext(ctx),
-		domain.ScanStatus(params.Status.Value),
+		domain.ScanSearchKey(params.Key),
+		params.Value,
 		params.Cursor.Value,
-		uint(params.Limit.Or(DefaultLimit))) //nolint: gosec
+		limit)
+	if err != nil {
+		return nil, err //nolint: wrapcheck
+	}
+
+	return domainScansToV1Specs(scans, nextCursor, limit)
+}
+
+// GetScanSummary returns the number of scans of the user for every status.
+func (h Handler) GetScanSummary(ctx context.Context) (v1specs.GetScanSummaryRes, error) {
+	counts, err := h.deps.Scanner.StatusCounts(ctx, GetUserIDFromContext(ctx))
 	if err != nil {
 		return nil, err //nolint: wrapcheck
 	}
 
+	res := make(v1specs.ScanSummaryCounts, len(counts))
+	for status, count := range counts {
+		res[string(status)] = count
+	}
+
+	return &v1specs.ScanSummary{Counts: res}, nil
+}
+
+// pageLimit validates the requested page size, applying DefaultLimit when it
+// is unset and clamping it to the configured maximum.
+func (h Handler) pageLimit(opt v1specs.OptInt) (uint, error) {
+	limit := opt.Or(DefaultLimit)
+	if limit <= 0 {
+		return 0, serrors.With(serrors.ErrBadRequest, "limit must be positive")
+	}
+
+	return uint(min(limit, h.options.maxLimit())), nil //nolint: gosec
+}
+
+// scanStatus parses the optional status filter of a request. An unset filter
+// yields the empty status, which matches scans of any status.
+func scanStatus(opt v1specs.OptScanStatus) (domain.ScanStatus, error) {
+	raw, ok := opt.Get()
+	if !ok {
+		return "", nil
+	}
+	status, err := domain.ParseScanStatus(string(raw))
+	if err != nil {
+		return "", serrors.Wrap(serrors.ErrBadRequest, err, "invalid status %q", raw)
+	}
+
+	return status, nil
+}
+
+// domainScansToV1Specs converts a page of scans requested with the given
+// limit into a v1specs.ScanList.
+func domainScansToV1Specs(scans []domain.Scan, nextCursor string, limit uint) (*v1specs.ScanList, error) {
 	items := make([]v1specs.Scan, 0, len(scans))
 	for i := range scans {
 		v1s, err := DomainScanToV1Specs(&scans[i])
@@ -144,5 +443,7 @@ func (h Handler) ListScans(ctx context.Context, params v1specs.ListScansParams)
 	return &v1specs.ScanList{
 		Items:      items,
 		NextCursor: cursorOpt,
+		HasMore:    nextCursor != "",
+		PageSize:   int(limit), //nolint: gosec
 	}, nil
 }
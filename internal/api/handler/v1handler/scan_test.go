@@ -2,7 +2,10 @@ package v1handler_test
 
 import (
 	"context"
+	"math"
+	"net/http"
 	"net/url"
+	"strconv"
 	"testing"
 	"time"
 
@@ -13,7 +16,11 @@ import (
 	"scanner/internal/api/handler/v1handler"
 	"scanner/internal/api/specs/v1specs"
 	mockscanner "scanner/internal/scanner/mock"
+	"scanner/pkg/clock"
 	"scanner/pkg/domain"
+	"scanner/pkg/serrors"
+	"scanner/pkg/storage"
+	"scanner/pkg/urlscanner"
 )
 
 func Test_toV1Result_Mapping(t *testing.T) {
@@ -34,11 +41,15 @@ func Test_toV1Result_Mapping(t *testing.T) {
 			Server:  "nginx",
 		},
 		Verdict: &struct {
-			Malicious bool `json:"malicious"`
-			Score     int  `json:"score"`
+			Malicious  bool     `json:"malicious"`
+			Score      int      `json:"score"`
+			Categories []string `json:"categories,omitempty"`
+			Brands     []string `json:"brands,omitempty"`
 		}{
-			Malicious: true,
-			Score:     42,
+			Malicious:  true,
+			Score:      42,
+			Categories: []string{"phishing"},
+			Brands:     []string{"PayPal"},
 		},
 		Stats: &struct {
 			Malicious int `json:"malicious"`
@@ -59,6 +70,8 @@ func Test_toV1Result_Mapping(t *testing.T) {
 
 	require.True(t, out.Verdicts.Malicious.Value, "malicious expected true")
 	require.Equal(t, 42, out.Verdicts.Score.Value)
+	require.Equal(t, []string{"phishing"}, out.Verdicts.Categories)
+	require.Equal(t, []string{"PayPal"}, out.Verdicts.Brands)
 
 	require.Equal(t, 3, out.Stats.Malicious.Value)
 }
@@ -70,6 +83,8 @@ func Test_toV1Result_OptionalFieldsUnset_WhenEmpty(t *testing.T) {
 	// page is zero; Page fields should be zero-values
 	require.Equal(t, v1specs.ScanResultPage{}, out.Page, "expected empty page struct")
 	require.False(t, out.Verdicts.Malicious.IsSet(), "malicious should not be set by default")
+	require.Nil(t, out.Verdicts.Categories, "categories should not be set by default")
+	require.Nil(t, out.Verdicts.Brands, "brands should not be set by default")
 	require.False(t, out.Stats.Malicious.IsSet(), "stats.malicious should not be set by default")
 }
 
@@ -94,6 +109,33 @@ func Test_toV1Specs_Success(t *testing.T) {
 	require.True(t, out.UpdatedAt.IsSet(), "updatedAt should be set")
 }
 
+func Test_toV1Specs_NextRetryAt(t *testing.T) {
+	out, err := v1handler.DomainScanToV1Specs(&domain.Scan{URL: "https://example.org"})
+	require.NoError(t, err)
+	require.False(t, out.NextRetryAt.IsSet())
+
+	retryAt := time.Now().Add(time.Minute)
+	out, err = v1handler.DomainScanToV1Specs(&domain.Scan{URL: "https://example.org", NextRetryAt: retryAt})
+	require.NoError(t, err)
+	require.True(t, out.NextRetryAt.IsSet())
+	require.True(t, retryAt.Equal(out.NextRetryAt.Value))
+}
+
+func Test_toV1Specs_StatusMapping(t *testing.T) {
+	statuses := map[domain.ScanStatus]v1specs.ScanStatus{
+		domain.ScanStatusPending:   v1specs.ScanStatusPENDING,
+		domain.ScanStatusCompleted: v1specs.ScanStatusCOMPLETED,
+		domain.ScanStatusFailed:    v1specs.ScanStatusFAILED,
+		domain.ScanStatusCanceled:  v1specs.ScanStatusCANCELED,
+	}
+	for status, want := range statuses {
+		out, err := v1handler.DomainScanToV1Specs(&domain.Scan{URL: "https://example.org", Status: status})
+		require.NoError(t, err)
+		require.Equal(t, want, out.Status)
+		require.NoError(t, out.Status.Validate())
+	}
+}
+
 func Test_toV1Specs_InvalidURL_Error(t *testing.T) {
 	in := &domain.Scan{URL: "://bad url"}
 	_, err := v1handler.DomainScanToV1Specs(in)
@@ -104,7 +146,7 @@ func TestHandler_CreateScan(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	m := mockscanner.NewMockScanner(ctrl)
-	h := v1handler.New(v1handler.Deps{Scanner: m})
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{})
 
 	userID := domain.UserID(uuid.New())
 	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, userID)
@@ -115,20 +157,158 @@ func TestHandler_CreateScan(t *testing.T) {
 
 	// expect
 	scan := sampleScan(userID, "https://e.com")
-	m.EXPECT().Enqueue(ctx, userID, "https://e.com").Return(&scan, nil)
+	m.EXPECT().Enqueue(ctx, userID, "https://e.com", "", nil, urlscanner.Visibility("")).Return(&scan, nil)
 
-	res, err := h.CreateScan(ctx, req)
+	res, err := h.CreateScan(ctx, req, v1specs.CreateScanParams{})
 	require.NoError(t, err)
 	require.NotNil(t, res)
 	got := res.(*v1specs.Scan)
 	require.Equal(t, "https://e.com", got.URL.String())
+
+	// idempotency key is forwarded to the scanner
+	m.EXPECT().Enqueue(ctx, userID, "https://e.com", "key-1", nil, urlscanner.Visibility("")).Return(&scan, nil)
+	_, err = h.CreateScan(ctx, req, v1specs.CreateScanParams{IdempotencyKey: v1specs.NewOptString("key-1")})
+	require.NoError(t, err)
+
+	// tags are forwarded to the scanner
+	tagged := &v1specs.CreateScanRequest{URL: *u, Tags: []string{"phishing"}}
+	m.EXPECT().Enqueue(ctx, userID, "https://e.com", "", []string{"phishing"}, urlscanner.Visibility("")).Return(&scan, nil)
+	_, err = h.CreateScan(ctx, tagged, v1specs.CreateScanParams{})
+	require.NoError(t, err)
+
+	// and so is the requested visibility
+	private := &v1specs.CreateScanRequest{
+		URL:        *u,
+		Visibility: v1specs.NewOptCreateScanRequestVisibility(v1specs.CreateScanRequestVisibilityPrivate),
+	}
+	m.EXPECT().Enqueue(ctx, userID, "https://e.com", "", nil, urlscanner.VisibilityPrivate).Return(&scan, nil)
+	_, err = h.CreateScan(ctx, private, v1specs.CreateScanParams{})
+	require.NoError(t, err)
+}
+
+// createScanSyncWithFakeClock runs h.CreateScanSync and, for each of waits,
+// advances clk by it once the handler is waiting on the clock.
+func createScanSyncWithFakeClock(
+	ctx context.Context,
+	h *v1handler.Handler,
+	clk *clock.Fake,
+	req *v1specs.CreateScanRequest,
+	params v1specs.CreateScanSyncParams,
+	waits ...time.Duration,
+) (v1specs.CreateScanSyncRes, error) {
+	type syncRes struct {
+		res v1specs.CreateScanSyncRes
+		err error
+	}
+	ch := make(chan syncRes, 1)
+	go func() {
+		res, err := h.CreateScanSync(ctx, req, params)
+		ch <- syncRes{res: res, err: err}
+	}()
+
+	for _, d := range waits {
+		clk.BlockUntil(1)
+		clk.Advance(d)
+	}
+	res := <-ch
+
+	return res.res, res.err
+}
+
+func TestHandler_CreateScanSync_CompletesWithinTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockscanner.NewMockScanner(ctrl)
+	clk := clock.NewFake(time.Now())
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{
+		SyncScanPollInterval: time.Second,
+		Clock:                clk,
+	})
+
+	userID := domain.UserID(uuid.New())
+	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, userID)
+	u, _ := url.Parse("https://e.com")
+
+	pending := sampleScan(userID, "https://e.com")
+	pending.Status = domain.ScanStatusPending
+	completed := pending
+	completed.Status = domain.ScanStatusCompleted
+	gomock.InOrder(
+		m.EXPECT().Enqueue(ctx, userID, "https://e.com", "key-1", nil, urlscanner.Visibility("")).Return(&pending, nil),
+		m.EXPECT().Result(ctx, userID, pending.ID).Return(&pending, nil),
+		m.EXPECT().Result(ctx, userID, pending.ID).Return(&completed, nil),
+	)
+
+	res, err := createScanSyncWithFakeClock(ctx, h, clk,
+		&v1specs.CreateScanRequest{URL: *u},
+		v1specs.CreateScanSyncParams{
+			IdempotencyKey: v1specs.NewOptString("key-1"),
+			Timeout:        v1specs.NewOptInt(5),
+		},
+		time.Second, time.Second)
+	require.NoError(t, err)
+	got, ok := res.(*v1specs.CreateScanSyncOK)
+	require.True(t, ok)
+	require.Equal(t, v1specs.ScanStatusCOMPLETED, got.Status)
+}
+
+func TestHandler_CreateScanSync_PendingAfterTimeout(t *testing.T) {
+	// timeouts above the maximum are clamped, even when they overflow a time.Duration
+	for _, seconds := range []int{60, math.MaxInt} {
+		t.Run(strconv.Itoa(seconds), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			m := mockscanner.NewMockScanner(ctrl)
+			clk := clock.NewFake(time.Now())
+			h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{
+				MaxSyncScanTimeout:   3 * time.Second,
+				SyncScanPollInterval: 2 * time.Second,
+				Clock:                clk,
+			})
+
+			userID := domain.UserID(uuid.New())
+			ctx := context.WithValue(context.Background(), v1handler.UserIDKey, userID)
+			u, _ := url.Parse("https://e.com")
+
+			pending := sampleScan(userID, "https://e.com")
+			pending.Status = domain.ScanStatusPending
+			m.EXPECT().Enqueue(ctx, userID, "https://e.com", "", nil, urlscanner.Visibility("")).Return(&pending, nil)
+			// the requested timeout is clamped to 3s: polls at 2s and at the deadline
+			m.EXPECT().Result(ctx, userID, pending.ID).Return(&pending, nil).Times(2)
+
+			res, err := createScanSyncWithFakeClock(ctx, h, clk,
+				&v1specs.CreateScanRequest{URL: *u},
+				v1specs.CreateScanSyncParams{Timeout: v1specs.NewOptInt(seconds)},
+				2*time.Second, time.Second)
+			require.NoError(t, err)
+			got, ok := res.(*v1specs.CreateScanSyncAccepted)
+			require.True(t, ok)
+			require.Equal(t, v1specs.ScanStatusPENDING, got.Status)
+		})
+	}
+}
+
+func TestHandler_CreateScanSync_EnqueueError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockscanner.NewMockScanner(ctrl)
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{})
+
+	userID := domain.UserID(uuid.New())
+	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, userID)
+	u, _ := url.Parse("https://e.com")
+
+	m.EXPECT().Enqueue(ctx, userID, "https://e.com", "", nil, urlscanner.Visibility("")).Return(nil, serrors.ErrRateLimited)
+
+	_, err := h.CreateScanSync(ctx, &v1specs.CreateScanRequest{URL: *u}, v1specs.CreateScanSyncParams{})
+	require.ErrorIs(t, err, serrors.ErrRateLimited)
 }
 
 func TestHandler_DeleteScan(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	m := mockscanner.NewMockScanner(ctrl)
-	h := v1handler.New(v1handler.Deps{Scanner: m})
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{})
 
 	userID := domain.UserID(uuid.New())
 	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, userID)
@@ -145,7 +325,7 @@ func TestHandler_GetScan(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	m := mockscanner.NewMockScanner(ctrl)
-	h := v1handler.New(v1handler.Deps{Scanner: m})
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{})
 
 	userID := domain.UserID(uuid.New())
 	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, userID)
@@ -155,15 +335,102 @@ func TestHandler_GetScan(t *testing.T) {
 
 	res, err := h.GetScan(ctx, v1specs.GetScanParams{ID: uuid.UUID(scan.ID)})
 	require.NoError(t, err)
-	got := res.(*v1specs.Scan)
-	require.Equal(t, "https://abc.xyz", got.URL.String())
+	got := res.(*v1specs.ScanHeaders)
+	require.Equal(t, "https://abc.xyz", got.Response.URL.String())
+	require.Equal(t, v1handler.ScanETag(&scan), got.ETag.Or(""))
+}
+
+func TestHandler_GetScan_ETag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockscanner.NewMockScanner(ctrl)
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{})
+
+	userID := domain.UserID(uuid.New())
+	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, userID)
+
+	scan := sampleScan(userID, "https://abc.xyz")
+	etag := v1handler.ScanETag(&scan)
+	params := v1specs.GetScanParams{ID: uuid.UUID(scan.ID), IfNoneMatch: v1specs.NewOptString(etag)}
+
+	// unchanged scan yields 304
+	m.EXPECT().Result(ctx, userID, scan.ID).Return(&scan, nil)
+	res, err := h.GetScan(ctx, params)
+	require.NoError(t, err)
+	notModified, ok := res.(*v1specs.GetScanNotModified)
+	require.True(t, ok, "expected 304 response, got %T", res)
+	require.Equal(t, etag, notModified.ETag.Or(""))
+
+	// changed scan yields 200 with a new ETag
+	changed := scan
+	changed.Status = domain.ScanStatusFailed
+	changed.UpdatedAt = scan.CreatedAt.Add(time.Minute)
+	m.EXPECT().Result(ctx, userID, scan.ID).Return(&changed, nil)
+	res, err = h.GetScan(ctx, params)
+	require.NoError(t, err)
+	ok200, ok := res.(*v1specs.ScanHeaders)
+	require.True(t, ok, "expected 200 response, got %T", res)
+	require.NotEqual(t, etag, ok200.ETag.Or(""))
+	require.Equal(t, v1handler.ScanETag(&changed), ok200.ETag.Or(""))
+}
+
+func TestHandler_ListScanAttempts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockscanner.NewMockScanner(ctrl)
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{})
+
+	userID := domain.UserID(uuid.New())
+	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, userID)
+
+	scanID := domain.ScanID(uuid.New())
+	now := time.Now().UTC().Truncate(time.Second)
+	m.EXPECT().Attempts(ctx, userID, scanID).Return([]storage.ScanAttempt{
+		{ID: 1, ScanID: scanID, Error: "provider down", CreatedAt: now},
+		{
+			ID:        2,
+			ScanID:    scanID,
+			RateLimit: urlscanner.RateLimitStatus{Limit: 60, Remaining: 59, ResetAt: now.Add(time.Minute)},
+			CreatedAt: now.Add(time.Second),
+		},
+	}, nil)
+
+	res, err := h.ListScanAttempts(ctx, v1specs.ListScanAttemptsParams{ID: uuid.UUID(scanID)})
+	require.NoError(t, err)
+	list := res.(*v1specs.ScanAttemptList)
+	require.Len(t, list.Items, 2)
+
+	require.Equal(t, int64(1), list.Items[0].ID)
+	require.Equal(t, "provider down", list.Items[0].Error.Or(""))
+	require.False(t, list.Items[0].RateLimit.ResetAt.IsSet())
+
+	require.False(t, list.Items[1].Error.IsSet())
+	require.Equal(t, 60, list.Items[1].RateLimit.Limit)
+	require.Equal(t, 59, list.Items[1].RateLimit.Remaining)
+	require.Equal(t, now.Add(time.Minute), list.Items[1].RateLimit.ResetAt.Or(time.Time{}))
+}
+
+func TestHandler_ListScanAttempts_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockscanner.NewMockScanner(ctrl)
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{})
+
+	userID := domain.UserID(uuid.New())
+	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, userID)
+
+	scanID := domain.ScanID(uuid.New())
+	m.EXPECT().Attempts(ctx, userID, scanID).Return(nil, serrors.With(serrors.ErrNotFound, "scan not found"))
+
+	_, err := h.ListScanAttempts(ctx, v1specs.ListScanAttemptsParams{ID: uuid.UUID(scanID)})
+	require.ErrorIs(t, err, serrors.ErrNotFound)
 }
 
 func TestHandler_ListScans_DefaultLimitAndCursor(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	m := mockscanner.NewMockScanner(ctrl)
-	h := v1handler.New(v1handler.Deps{Scanner: m})
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{})
 
 	userID := domain.UserID(uuid.New())
 	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, userID)
@@ -178,9 +445,11 @@ func TestHandler_ListScans_DefaultLimitAndCursor(t *testing.T) {
 	m.EXPECT().UserScans(ctx,
 		userID,
 		domain.ScanStatus(params.Status.Value),
+		false,
 		params.Cursor.Value,
+		storage.PageNext,
 		uint(v1handler.DefaultLimit),
-	).Return(scans, next, nil)
+	).Return(scans, next, "", nil)
 
 	res, err := h.ListScans(ctx, params)
 	require.NoError(t, err)
@@ -188,13 +457,15 @@ func TestHandler_ListScans_DefaultLimitAndCursor(t *testing.T) {
 	require.Len(t, lst.Items, 2)
 	require.True(t, lst.NextCursor.IsSet())
 	require.Equal(t, next, lst.NextCursor.Value)
+	require.True(t, lst.HasMore)
+	require.Equal(t, v1handler.DefaultLimit, lst.PageSize)
 }
 
 func TestHandler_ListScans_CustomLimit_NoNextCursor(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	m := mockscanner.NewMockScanner(ctrl)
-	h := v1handler.New(v1handler.Deps{Scanner: m})
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{})
 
 	userID := domain.UserID(uuid.New())
 	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, userID)
@@ -205,13 +476,149 @@ func TestHandler_ListScans_CustomLimit_NoNextCursor(t *testing.T) {
 		Cursor: v1specs.NewOptNilString("c0"),
 		Status: v1specs.NewOptScanStatus(v1specs.ScanStatus(domain.ScanStatusPending)),
 	}
-	m.EXPECT().UserScans(ctx, userID, domain.ScanStatusPending, "c0", uint(5)).Return(scans, "", nil)
+	m.EXPECT().UserScans(ctx, userID, domain.ScanStatusPending, false, "c0", storage.PageNext, uint(5)).Return(scans, "", "", nil)
 
 	res, err := h.ListScans(ctx, params)
 	require.NoError(t, err)
 	lst := res.(*v1specs.ScanList)
 	require.Empty(t, lst.Items)
 	require.False(t, lst.NextCursor.IsSet(), "next cursor should be unset when empty")
+	require.False(t, lst.HasMore)
+	require.Equal(t, 5, lst.PageSize)
+}
+
+func TestHandler_ListScans_PrevDirection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockscanner.NewMockScanner(ctrl)
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{})
+
+	userID := domain.UserID(uuid.New())
+	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, userID)
+
+	m.EXPECT().UserScans(ctx, userID, domain.ScanStatus(""), false, "c2", storage.PagePrev, uint(v1handler.DefaultLimit)).
+		Return([]domain.Scan{sampleScan(userID, "https://a")}, "c3", "c1", nil)
+	res, err := h.ListScans(ctx, v1specs.ListScansParams{
+		Cursor:    v1specs.NewOptNilString("c2"),
+		Direction: v1specs.NewOptListScansDirection(v1specs.ListScansDirectionPrev),
+	})
+	require.NoError(t, err)
+	lst := res.(*v1specs.ScanList)
+	require.Len(t, lst.Items, 1)
+	require.Equal(t, "c3", lst.NextCursor.Value)
+	require.Equal(t, "c1", lst.PrevCursor.Value)
+
+	// the first page has no previous cursor
+	m.EXPECT().UserScans(ctx, userID, domain.ScanStatus(""), false, "", storage.PageNext, uint(v1handler.DefaultLimit)).
+		Return(nil, "", "", nil)
+	res, err = h.ListScans(ctx, v1specs.ListScansParams{})
+	require.NoError(t, err)
+	require.False(t, res.(*v1specs.ScanList).PrevCursor.IsSet())
+}
+
+func TestHandler_ListScans_PageMetadata(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockscanner.NewMockScanner(ctrl)
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{MaxLimit: 2})
+
+	userID := domain.UserID(uuid.New())
+	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, userID)
+
+	// a full page reports more scans and the clamped page size
+	m.EXPECT().UserScans(ctx, userID, domain.ScanStatus(""), false, "", storage.PageNext, uint(2)).
+		Return([]domain.Scan{sampleScan(userID, "https://a"), sampleScan(userID, "https://b")}, "c1", "", nil)
+	res, err := h.ListScans(ctx, v1specs.ListScansParams{Limit: v1specs.NewOptInt(10)})
+	require.NoError(t, err)
+	lst := res.(*v1specs.ScanList)
+	require.Len(t, lst.Items, 2)
+	require.True(t, lst.HasMore)
+	require.Equal(t, 2, lst.PageSize)
+
+	// the final page has no more scans
+	m.EXPECT().UserScans(ctx, userID, domain.ScanStatus(""), false, "c1", storage.PageNext, uint(2)).
+		Return([]domain.Scan{sampleScan(userID, "https://c")}, "", "", nil)
+	res, err = h.ListScans(ctx, v1specs.ListScansParams{
+		Limit:  v1specs.NewOptInt(10),
+		Cursor: v1specs.NewOptNilString("c1"),
+	})
+	require.NoError(t, err)
+	lst = res.(*v1specs.ScanList)
+	require.Len(t, lst.Items, 1)
+	require.False(t, lst.HasMore)
+	require.False(t, lst.NextCursor.IsSet())
+	require.Equal(t, 2, lst.PageSize)
+}
+
+func TestHandler_GetScanSummary(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockscanner.NewMockScanner(ctrl)
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{})
+
+	userID := domain.UserID(uuid.New())
+	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, userID)
+
+	m.EXPECT().StatusCounts(ctx, userID).Return(map[domain.ScanStatus]int64{
+		domain.ScanStatusPending:   2,
+		domain.ScanStatusCompleted: 5,
+		domain.ScanStatusFailed:    0,
+		domain.ScanStatusCanceled:  1,
+	}, nil)
+
+	res, err := h.GetScanSummary(ctx)
+	require.NoError(t, err)
+	require.Equal(t, v1specs.ScanSummaryCounts{
+		"PENDING":   2,
+		"COMPLETED": 5,
+		"FAILED":    0,
+		"CANCELED":  1,
+	}, res.(*v1specs.ScanSummary).Counts)
+
+	m.EXPECT().StatusCounts(ctx, userID).Return(nil, serrors.With(serrors.ErrUnavailable, "down"))
+	_, err = h.GetScanSummary(ctx)
+	require.ErrorIs(t, err, serrors.ErrUnavailable)
+}
+
+func TestHandler_ListScans_Malicious(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockscanner.NewMockScanner(ctrl)
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{})
+
+	userID := domain.UserID(uuid.New())
+	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, userID)
+
+	m.EXPECT().UserScans(ctx, userID, domain.ScanStatus(""), true, "", storage.PageNext, uint(v1handler.DefaultLimit)).
+		Return([]domain.Scan{sampleScan(userID, "https://a")}, "", "", nil)
+
+	res, err := h.ListScans(ctx, v1specs.ListScansParams{Malicious: v1specs.NewOptBool(true)})
+	require.NoError(t, err)
+	require.Len(t, res.(*v1specs.ScanList).Items, 1)
+}
+
+func TestHandler_SearchScans(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockscanner.NewMockScanner(ctrl)
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{})
+
+	userID := domain.UserID(uuid.New())
+	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, userID)
+
+	m.EXPECT().SearchScans(ctx, userID, domain.ScanSearchKeyASN, "AS15133", "c0", uint(5)).
+		Return([]domain.Scan{sampleScan(userID, "https://a")}, "c1", nil)
+
+	res, err := h.SearchScans(ctx, v1specs.SearchScansParams{
+		Key:    v1specs.SearchScansKeyAsn,
+		Value:  "AS15133",
+		Cursor: v1specs.NewOptNilString("c0"),
+		Limit:  v1specs.NewOptInt(5),
+	})
+	require.NoError(t, err)
+	lst := res.(*v1specs.ScanList)
+	require.Len(t, lst.Items, 1)
+	require.Equal(t, "c1", lst.NextCursor.Value)
 }
 
 // sampleScan constructs a minimal domain.Scan for tests.
@@ -228,3 +635,112 @@ func sampleScan(userID domain.UserID, rawurl string) domain.Scan {
 		UpdatedAt: time.Now(),
 	}
 }
+
+func TestHandler_GetLatestScan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockscanner.NewMockScanner(ctrl)
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{})
+
+	userID := domain.UserID(uuid.New())
+	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, userID)
+
+	u, _ := url.Parse("https://abc.xyz")
+	scan := sampleScan(userID, "https://abc.xyz")
+	m.EXPECT().LatestByURL(ctx, userID, "https://abc.xyz").Return(&scan, nil)
+
+	res, err := h.GetLatestScan(ctx, v1specs.GetLatestScanParams{URL: *u})
+	require.NoError(t, err)
+	got := res.(*v1specs.Scan)
+	require.Equal(t, uuid.UUID(scan.ID), got.ID)
+
+	// errors are propagated for NewError to map
+	m.EXPECT().LatestByURL(ctx, userID, "https://abc.xyz").Return(nil, serrors.With(serrors.ErrNotFound, "scan not found"))
+	_, err = h.GetLatestScan(ctx, v1specs.GetLatestScanParams{URL: *u})
+	require.ErrorIs(t, err, serrors.ErrNotFound)
+}
+
+func TestHandler_ListScans_ClampsLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockscanner.NewMockScanner(ctrl)
+
+	userID := domain.UserID(uuid.New())
+	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, userID)
+	params := v1specs.ListScansParams{Limit: v1specs.NewOptInt(1000000)}
+
+	// configured maximum
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{MaxLimit: 50})
+	m.EXPECT().UserScans(ctx, userID, domain.ScanStatus(""), false, "", storage.PageNext, uint(50)).Return(nil, "", "", nil)
+	_, err := h.ListScans(ctx, params)
+	require.NoError(t, err)
+
+	// default maximum
+	h = v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{})
+	m.EXPECT().UserScans(ctx, userID, domain.ScanStatus(""), false, "", storage.PageNext, uint(v1handler.DefaultMaxLimit)).Return(nil, "", "", nil)
+	_, err = h.ListScans(ctx, params)
+	require.NoError(t, err)
+}
+
+func TestHandler_ListScans_RejectsNonPositiveLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockscanner.NewMockScanner(ctrl)
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{})
+
+	userID := domain.UserID(uuid.New())
+	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, userID)
+
+	for _, limit := range []int{0, -1} {
+		_, err := h.ListScans(ctx, v1specs.ListScansParams{Limit: v1specs.NewOptInt(limit)})
+		require.ErrorIs(t, err, serrors.ErrBadRequest)
+	}
+}
+
+func TestHandler_ListScans_RejectsUnknownStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockscanner.NewMockScanner(ctrl)
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{})
+
+	userID := domain.UserID(uuid.New())
+	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, userID)
+
+	// ?status=BOGUS is rejected before the scanner is called
+	_, err := h.ListScans(ctx, v1specs.ListScansParams{Status: v1specs.NewOptScanStatus("BOGUS")})
+	require.ErrorIs(t, err, serrors.ErrBadRequest)
+	res := h.NewError(ctx, err)
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	require.Equal(t, `invalid status "BOGUS"`, res.Response.Message)
+}
+
+func TestHandler_BatchGetScans(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockscanner.NewMockScanner(ctrl)
+	h := v1handler.New(v1handler.Deps{Scanner: m}, v1handler.Options{})
+
+	userID := domain.UserID(uuid.New())
+	ctx := context.WithValue(context.Background(), v1handler.UserIDKey, userID)
+
+	s1 := sampleScan(userID, "https://a")
+	s2 := sampleScan(userID, "https://b")
+	missing := uuid.New()
+	req := &v1specs.BatchGetScansRequest{Ids: []uuid.UUID{uuid.UUID(s2.ID), missing, uuid.UUID(s1.ID), missing}}
+
+	m.EXPECT().Results(ctx, userID, []domain.ScanID{s2.ID, domain.ScanID(missing), s1.ID, domain.ScanID(missing)}).
+		Return([]domain.Scan{s2, s1}, nil)
+
+	res, err := h.BatchGetScans(ctx, req)
+	require.NoError(t, err)
+	out := res.(*v1specs.BatchGetScansResponse)
+	require.Len(t, out.Items, 2)
+	require.Equal(t, uuid.UUID(s2.ID), out.Items[0].ID)
+	require.Equal(t, uuid.UUID(s1.ID), out.Items[1].ID)
+	require.Equal(t, []uuid.UUID{missing}, out.NotFound)
+
+	// errors are propagated for NewError to map
+	m.EXPECT().Results(ctx, userID, gomock.Any()).Return(nil, serrors.With(serrors.ErrInternal, "boom"))
+	_, err = h.BatchGetScans(ctx, req)
+	require.ErrorIs(t, err, serrors.ErrInternal)
+}
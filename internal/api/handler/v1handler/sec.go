@@ -9,9 +9,10 @@ import (
 	"scanner/pkg/controller"
 	"scanner/pkg/domain"
 	"scanner/pkg/serrors"
+	"slices"
+	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/google/uuid"
 )
 
 // SecHandlerOptions holds configuration for security handling, such as JWT keys.
@@ -33,6 +34,53 @@ func NewSecHandlerOptions(cfg *config.Config) *SecHandlerOptions {
 // UserIDKey is the context key under which authenticated user's UUID is stored.
 const UserIDKey controller.CtxKey = "userID"
 
+// RolesKey is the context key under which authenticated user's Roles are stored.
+const RolesKey controller.CtxKey = "roles"
+
+// Role is a role granted to an authenticated user through the JWT claims.
+type Role string
+
+// RoleAdmin grants access to operator endpoints such as listing all scans.
+const RoleAdmin Role = "admin"
+
+// knownRoles lists the roles understood by the API. Other roles found in a
+// token are ignored.
+var knownRoles = map[Role]struct{}{ //nolint: gochecknoglobals
+	RoleAdmin: {},
+}
+
+// Roles is the set of known roles granted to an authenticated user.
+type Roles []Role
+
+// Has reports whether role is part of the set.
+func (r Roles) Has(role Role) bool {
+	return slices.Contains(r, role)
+}
+
+// Claims are the JWT claims accepted by HandleBearerAuth. Roles can be given
+// either as a "roles" array or as a space-delimited "scope" string.
+type Claims struct {
+	jwt.RegisteredClaims
+	// Roles lists the roles of the user.
+	Roles []string `json:"roles,omitempty"`
+	// Scope lists the roles of the user separated by spaces (RFC 8693 style).
+	Scope string `json:"scope,omitempty"`
+}
+
+// KnownRoles returns the known roles found in the roles and scope claims,
+// without duplicates. Unknown and empty roles are dropped.
+func (c Claims) KnownRoles() Roles {
+	var roles Roles
+	for _, r := range append(slices.Clone(c.Roles), strings.Fields(c.Scope)...) {
+		role := Role(strings.TrimSpace(r))
+		if _, ok := knownRoles[role]; ok && !roles.Has(role) {
+			roles = append(roles, role)
+		}
+	}
+
+	return roles
+}
+
 // GetUserIDFromContext extracts the authenticated user's UUID from context.
 // It panics if the value is missing or of unexpected type, which should not
 // happen when the JWT middleware is correctly configured.
@@ -46,6 +94,14 @@ func GetUserIDFromContext(ctx context.Context) domain.UserID {
 	return userID
 }
 
+// GetRolesFromContext extracts the authenticated user's Roles from context.
+// It returns no roles for regular users.
+func GetRolesFromContext(ctx context.Context) Roles {
+	roles, _ := ctx.Value(RolesKey).(Roles)
+
+	return roles
+}
+
 // SecHandler verifies Bearer (JWT) tokens and enriches context with user identity.
 type SecHandler struct {
 	publicKey *rsa.PublicKey
@@ -68,12 +124,13 @@ var _ v1specs.SecurityHandler = (*SecHandler)(nil)
 
 // HandleBearerAuth validates the provided Bearer token (JWT), ensuring it is signed
 // with RS256 using the configured public key, not expired, and contains a valid
-// UUID subject. On success, it stores the user ID in the context.
+// UUID subject. On success, it stores the user ID and the known roles of the
+// roles/scope claims in the context; malformed role claims are rejected.
 func (s SecHandler) HandleBearerAuth(
 	ctx context.Context,
 	_ v1specs.OperationName,
 	t v1specs.BearerAuth) (context.Context, error) {
-	token, err := jwt.ParseWithClaims(t.Token, &jwt.RegisteredClaims{}, func(token *jwt.Token) (any, error) {
+	token, err := jwt.ParseWithClaims(t.Token, &Claims{}, func(token *jwt.Token) (any, error) {
 		return s.publicKey, nil
 	},
 		jwt.WithExpirationRequired(),
@@ -92,10 +149,17 @@ func (s SecHandler) HandleBearerAuth(
 		return ctx, serrors.With(serrors.ErrUnauthorized, "invalid subject")
 	}
 
-	userID, err := uuid.Parse(subject)
+	userID, err := domain.ParseUserID(subject)
 	if err != nil {
 		return ctx, serrors.With(serrors.ErrUnauthorized, "invalid subject")
 	}
 
-	return context.WithValue(ctx, UserIDKey, domain.UserID(userID)), nil
+	claims, ok := token.Claims.(*Claims)
+	if !ok {
+		return ctx, serrors.With(serrors.ErrUnauthorized, "invalid claims")
+	}
+
+	ctx = context.WithValue(ctx, UserIDKey, userID)
+
+	return context.WithValue(ctx, RolesKey, claims.KnownRoles()), nil
 }
@@ -74,6 +74,93 @@ func TestHandleBearerAuth_ValidToken(t *testing.T) {
 	require.Equal(t, domain.UserID(uid), got)
 }
 
+func signJWTWithClaims(tb testing.TB, priv *rsa.PrivateKey, roles []string, scope string) string {
+	tb.Helper()
+	now := time.Now()
+	claims := v1handler.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   uuid.NewString(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+		Roles: roles,
+		Scope: scope,
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(priv)
+	require.NoError(tb, err, "failed to sign token")
+
+	return signed
+}
+
+func TestHandleBearerAuth_Roles(t *testing.T) {
+	priv, pubPEM := genRSAKeys(t)
+	sh := newSecHandlerForTest(t, pubPEM)
+
+	tests := []struct {
+		name  string
+		token string
+		want  v1handler.Roles
+	}{
+		{
+			name:  "no claim",
+			token: signJWTRS256(t, priv, uuid.NewString(), time.Now(), time.Now().Add(time.Hour)),
+		},
+		{
+			name:  "roles claim",
+			token: signJWTWithClaims(t, priv, []string{"admin"}, ""),
+			want:  v1handler.Roles{v1handler.RoleAdmin},
+		},
+		{
+			name:  "scope claim",
+			token: signJWTWithClaims(t, priv, nil, "read admin"),
+			want:  v1handler.Roles{v1handler.RoleAdmin},
+		},
+		{
+			name:  "duplicates across claims",
+			token: signJWTWithClaims(t, priv, []string{"admin"}, "admin"),
+			want:  v1handler.Roles{v1handler.RoleAdmin},
+		},
+		{
+			name:  "unknown roles are ignored",
+			token: signJWTWithClaims(t, priv, []string{"superuser", ""}, "write"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := sh.HandleBearerAuth(context.Background(), "", v1specs.BearerAuth{Token: tt.token})
+			require.NoError(t, err)
+			require.Equal(t, tt.want, v1handler.GetRolesFromContext(ctx))
+		})
+	}
+}
+
+func TestHandleBearerAuth_MalformedRoles(t *testing.T) {
+	priv, pubPEM := genRSAKeys(t)
+	sh := newSecHandlerForTest(t, pubPEM)
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub":   uuid.NewString(),
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"roles": "admin", // must be an array
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(priv)
+	require.NoError(t, err)
+
+	_, err = sh.HandleBearerAuth(context.Background(), "", v1specs.BearerAuth{Token: signed})
+	require.ErrorIs(t, err, serrors.ErrUnauthorized)
+}
+
+func TestGetRolesFromContext(t *testing.T) {
+	require.Empty(t, v1handler.GetRolesFromContext(context.Background()))
+
+	ctx := context.WithValue(context.Background(), v1handler.RolesKey, v1handler.Roles{v1handler.RoleAdmin})
+	roles := v1handler.GetRolesFromContext(ctx)
+	require.True(t, roles.Has(v1handler.RoleAdmin))
+	require.False(t, v1handler.Roles{}.Has(v1handler.RoleAdmin))
+}
+
 func TestHandleBearerAuth_InvalidSignature(t *testing.T) {
 	// handler uses pub from key A, but token signed with key B
 	_, pubPEM := genRSAKeys(t)
@@ -31,6 +31,13 @@ import (
 //go:embed specs/v1.yaml
 var v1Spec []byte
 
+// streamingPaths are served without the request timeout, since
+// http.TimeoutHandler buffers the whole response in memory. They are still
+// bounded by the server's WriteTimeout.
+var streamingPaths = map[string]struct{}{ //nolint: gochecknoglobals
+	"/v1/scans:export": {},
+}
+
 // Options holds configuration for the HTTP server and its dependencies.
 // It is typically created from a config.Config via NewOptions.
 // All durations are used to configure server timeouts, and zero values
@@ -38,6 +45,8 @@ var v1Spec []byte
 type Options struct {
 	// SecHandlerOptions configures the security handler (authn/authz) for v1 endpoints.
 	SecHandlerOptions *v1handler.SecHandlerOptions
+	// HandlerOptions configures the v1 endpoint handlers.
+	HandlerOptions v1handler.Options
 
 	// Addr is the TCP address the server listens on, e.g. ":8080".
 	Addr string
@@ -54,8 +63,24 @@ type Options struct {
 	// MaxHeaderBytes controls the maximum number of bytes the server
 	// will read parsing the request header's keys and values, including the request line.
 	MaxHeaderBytes int
+	// MaxBodyBytes is the maximum size of a request body; larger requests are
+	// rejected with 413 Request Entity Too Large. Zero disables the limit.
+	MaxBodyBytes int64
+	// DefaultRetryAfter is the Retry-After delay of 429 and 503 v1 API
+	// responses whose error carries no retry hint. Zero omits the header.
+	DefaultRetryAfter time.Duration
 	// MetricsPath is the HTTP path at which Prometheus metrics are served.
 	MetricsPath string
+	// MetricsAuth configures the credentials required to scrape MetricsPath.
+	// The zero value serves metrics unauthenticated.
+	MetricsAuth controller.AuthConfig
+	// CORS is the CORS policy applied to every route.
+	CORS controller.CORSConfig
+	// AccessLog configures access log sampling and slow request detection.
+	AccessLog controller.AccessLogConfig
+	// LogLevelEndpoint exposes /debug/loglevel to read (GET) and change (PUT)
+	// the log level at runtime.
+	LogLevelEndpoint bool
 }
 
 // NewOptions constructs an Options value from the provided application configuration.
@@ -63,6 +88,7 @@ type Options struct {
 func NewOptions(cfg *config.Config) Options {
 	return Options{
 		SecHandlerOptions: v1handler.NewSecHandlerOptions(cfg),
+		HandlerOptions:    v1handler.NewOptions(cfg),
 
 		Addr:              cfg.HTTP.Addr,
 		ReadTimeout:       cfg.HTTP.ReadTimeout,
@@ -71,7 +97,25 @@ func NewOptions(cfg *config.Config) Options {
 		IdleTimeout:       cfg.HTTP.IdleTimeout,
 		RequestTimeout:    cfg.HTTP.RequestTimeout,
 		MaxHeaderBytes:    cfg.HTTP.MaxHeaderBytes,
+		MaxBodyBytes:      cfg.HTTP.MaxBodyBytes,
+		DefaultRetryAfter: cfg.HTTP.DefaultRetryAfter,
 		MetricsPath:       cfg.HTTP.MetricsPath,
+		MetricsAuth: controller.AuthConfig{
+			BearerToken: cfg.HTTP.MetricsBearerToken,
+			Username:    cfg.HTTP.MetricsUsername,
+			Password:    cfg.HTTP.MetricsPassword,
+		},
+		CORS: controller.CORSConfig{
+			AllowedOrigins:   cfg.HTTP.CORSAllowedOrigins,
+			AllowedMethods:   cfg.HTTP.CORSAllowedMethods,
+			AllowedHeaders:   cfg.HTTP.CORSAllowedHeaders,
+			AllowCredentials: cfg.HTTP.CORSAllowCredentials,
+		},
+		AccessLog: controller.AccessLogConfig{
+			SampleRate:           cfg.HTTP.AccessLogSampleRate,
+			SlowRequestThreshold: cfg.HTTP.SlowRequestThreshold,
+		},
+		LogLevelEndpoint: cfg.HTTP.LogLevelEndpoint,
 	}
 }
 
@@ -83,18 +127,20 @@ type Deps struct {
 
 // NewServer wires up and returns a configured *http.Server using the provided Options.
 // It sets up:
-// - Prometheus metrics endpoint (MetricsPath)
+// - Prometheus metrics endpoint (MetricsPath), optionally requiring MetricsAuth credentials
 // - OpenTelemetry metrics exporter (Prometheus)
 // - Embedded OpenAPI v1 spec and Swagger UI
 // - v1 API routes backed by generated server and handlers
 // - pprof endpoints for profiling
+// - Runtime log level endpoint (when LogLevelEndpoint is enabled)
 // - RiverQueue UI
-// It also wraps the mux with CORS and logging middlewares and applies a request timeout.
+// It also wraps the mux with body size limit, CORS and logging middlewares, applies a request timeout
+// and recovers panics of all of them.
 func NewServer(ctx context.Context, deps Deps, opts Options) (*http.Server, error) {
 	mux := http.NewServeMux()
 
 	// prometheus metrics server
-	mux.Handle(opts.MetricsPath, promhttp.Handler())
+	mux.Handle(opts.MetricsPath, controller.WithAuth(promhttp.Handler(), opts.MetricsAuth))
 
 	// otel
 	exp, err := otelprom.New(otelprom.WithRegisterer(prometheus.DefaultRegisterer))
@@ -119,14 +165,14 @@ func NewServer(ctx context.Context, deps Deps, opts Options) (*http.Server, erro
 	if err != nil {
 		return nil, fmt.Errorf("could not create sec handler: %w", err)
 	}
-	v1Srv, err := v1specs.NewServer(v1handler.New(deps.Deps),
+	v1Srv, err := v1specs.NewServer(v1handler.New(deps.Deps, opts.HandlerOptions),
 		secHandler,
 		v1specs.WithMeterProvider(mp),
 		v1specs.WithPathPrefix("/v1"))
 	if err != nil {
 		return nil, fmt.Errorf("could not create v1 api server: %w", err)
 	}
-	mux.Handle("/v1/", v1Srv)
+	mux.Handle("/v1/", v1handler.WithRetryAfter(v1Srv, opts.DefaultRetryAfter))
 
 	// river queue ui
 	riverEndpoints := riverui.NewEndpoints(deps.WorkerClient, nil)
@@ -147,15 +193,36 @@ func NewServer(ctx context.Context, deps Deps, opts Options) (*http.Server, erro
 	// pprof
 	mux.Handle("/debug/pprof/", controller.PprofMux())
 
+	// runtime log level
+	if opts.LogLevelEndpoint {
+		mux.Handle("/debug/loglevel", logger.LevelHandler())
+	}
+
+	// request body limit
+	handler := controller.WithMaxBodyBytes(mux, opts.MaxBodyBytes)
+
 	// cors
-	handler := controller.WithCORS(mux)
+	handler = controller.WithCORS(handler, opts.CORS)
 
 	// logger
-	handler = controller.WithLogger(handler)
+	handler = controller.WithLogger(handler, opts.AccessLog)
+
+	// request timeout, except for streaming responses
+	timeoutHandler := http.TimeoutHandler(handler, opts.ReadTimeout, `{"error":"request timed out"}`)
+
+	// panic recovery, outermost so panics of every other middleware are recovered as well
+	rootHandler := controller.WithRecover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := streamingPaths[r.URL.Path]; ok {
+			handler.ServeHTTP(w, r)
+
+			return
+		}
+		timeoutHandler.ServeHTTP(w, r)
+	}))
 
 	return &http.Server{
 		Addr:              opts.Addr,
-		Handler:           http.TimeoutHandler(handler, opts.ReadTimeout, `{"error":"request timed out"}`),
+		Handler:           rootHandler,
 		ReadTimeout:       opts.ReadTimeout,
 		ReadHeaderTimeout: opts.ReadHeaderTimeout,
 		WriteTimeout:      opts.WriteTimeout,
@@ -1,18 +1,66 @@
 // Code generated by ogen, DO NOT EDIT.
 package v1specs
 
+type BatchGetScansRes interface {
+	batchGetScansRes()
+}
+
 type CreateScanRes interface {
 	createScanRes()
 }
 
+type CreateScanSyncRes interface {
+	createScanSyncRes()
+}
+
 type DeleteScanRes interface {
 	deleteScanRes()
 }
 
+type ExportScansRes interface {
+	exportScansRes()
+}
+
+type ForceFailScanRes interface {
+	forceFailScanRes()
+}
+
+type GetLatestScanRes interface {
+	getLatestScanRes()
+}
+
 type GetScanRes interface {
 	getScanRes()
 }
 
+type GetScanSummaryRes interface {
+	getScanSummaryRes()
+}
+
+type ListAdminAuditLogRes interface {
+	listAdminAuditLogRes()
+}
+
+type ListAdminJobsRes interface {
+	listAdminJobsRes()
+}
+
+type ListAdminScansRes interface {
+	listAdminScansRes()
+}
+
+type ListScanAttemptsRes interface {
+	listScanAttemptsRes()
+}
+
 type ListScansRes interface {
 	listScansRes()
 }
+
+type RequeueScanRes interface {
+	requeueScanRes()
+}
+
+type SearchScansRes interface {
+	searchScansRes()
+}
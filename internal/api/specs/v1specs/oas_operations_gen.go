@@ -6,8 +6,20 @@ package v1specs
 type OperationName = string
 
 const (
-	CreateScanOperation OperationName = "CreateScan"
-	DeleteScanOperation OperationName = "DeleteScan"
-	GetScanOperation    OperationName = "GetScan"
-	ListScansOperation  OperationName = "ListScans"
+	BatchGetScansOperation     OperationName = "BatchGetScans"
+	CreateScanOperation        OperationName = "CreateScan"
+	CreateScanSyncOperation    OperationName = "CreateScanSync"
+	DeleteScanOperation        OperationName = "DeleteScan"
+	ExportScansOperation       OperationName = "ExportScans"
+	ForceFailScanOperation     OperationName = "ForceFailScan"
+	GetLatestScanOperation     OperationName = "GetLatestScan"
+	GetScanOperation           OperationName = "GetScan"
+	GetScanSummaryOperation    OperationName = "GetScanSummary"
+	ListAdminAuditLogOperation OperationName = "ListAdminAuditLog"
+	ListAdminJobsOperation     OperationName = "ListAdminJobs"
+	ListAdminScansOperation    OperationName = "ListAdminScans"
+	ListScanAttemptsOperation  OperationName = "ListScanAttempts"
+	ListScansOperation         OperationName = "ListScans"
+	RequeueScanOperation       OperationName = "RequeueScan"
+	SearchScansOperation       OperationName = "SearchScans"
 )
@@ -49,61 +49,402 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			break
 		}
 		switch elem[0] {
-		case '/': // Prefix: "/scans"
+		case '/': // Prefix: "/"
 
-			if l := len("/scans"); len(elem) >= l && elem[0:l] == "/scans" {
+			if l := len("/"); len(elem) >= l && elem[0:l] == "/" {
 				elem = elem[l:]
 			} else {
 				break
 			}
 
 			if len(elem) == 0 {
-				switch r.Method {
-				case "GET":
-					s.handleListScansRequest([0]string{}, elemIsEscaped, w, r)
-				case "POST":
-					s.handleCreateScanRequest([0]string{}, elemIsEscaped, w, r)
-				default:
-					s.notAllowed(w, r, "GET,POST")
-				}
-
-				return
+				break
 			}
 			switch elem[0] {
-			case '/': // Prefix: "/"
+			case 'a': // Prefix: "admin/"
 
-				if l := len("/"); len(elem) >= l && elem[0:l] == "/" {
+				if l := len("admin/"); len(elem) >= l && elem[0:l] == "admin/" {
 					elem = elem[l:]
 				} else {
 					break
 				}
 
-				// Param: "id"
-				// Leaf parameter, slashes are prohibited
-				idx := strings.IndexByte(elem, '/')
-				if idx >= 0 {
+				if len(elem) == 0 {
+					break
+				}
+				switch elem[0] {
+				case 'a': // Prefix: "audit"
+
+					if l := len("audit"); len(elem) >= l && elem[0:l] == "audit" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					if len(elem) == 0 {
+						// Leaf node.
+						switch r.Method {
+						case "GET":
+							s.handleListAdminAuditLogRequest([0]string{}, elemIsEscaped, w, r)
+						default:
+							s.notAllowed(w, r, "GET")
+						}
+
+						return
+					}
+
+				case 'j': // Prefix: "jobs"
+
+					if l := len("jobs"); len(elem) >= l && elem[0:l] == "jobs" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					if len(elem) == 0 {
+						// Leaf node.
+						switch r.Method {
+						case "GET":
+							s.handleListAdminJobsRequest([0]string{}, elemIsEscaped, w, r)
+						default:
+							s.notAllowed(w, r, "GET")
+						}
+
+						return
+					}
+
+				case 's': // Prefix: "scans"
+
+					if l := len("scans"); len(elem) >= l && elem[0:l] == "scans" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					if len(elem) == 0 {
+						switch r.Method {
+						case "GET":
+							s.handleListAdminScansRequest([0]string{}, elemIsEscaped, w, r)
+						default:
+							s.notAllowed(w, r, "GET")
+						}
+
+						return
+					}
+					switch elem[0] {
+					case '/': // Prefix: "/"
+
+						if l := len("/"); len(elem) >= l && elem[0:l] == "/" {
+							elem = elem[l:]
+						} else {
+							break
+						}
+
+						// Param: "id"
+						// Match until "/"
+						idx := strings.IndexByte(elem, '/')
+						if idx < 0 {
+							idx = len(elem)
+						}
+						args[0] = elem[:idx]
+						elem = elem[idx:]
+
+						if len(elem) == 0 {
+							break
+						}
+						switch elem[0] {
+						case '/': // Prefix: "/"
+
+							if l := len("/"); len(elem) >= l && elem[0:l] == "/" {
+								elem = elem[l:]
+							} else {
+								break
+							}
+
+							if len(elem) == 0 {
+								break
+							}
+							switch elem[0] {
+							case 'f': // Prefix: "fail"
+
+								if l := len("fail"); len(elem) >= l && elem[0:l] == "fail" {
+									elem = elem[l:]
+								} else {
+									break
+								}
+
+								if len(elem) == 0 {
+									// Leaf node.
+									switch r.Method {
+									case "POST":
+										s.handleForceFailScanRequest([1]string{
+											args[0],
+										}, elemIsEscaped, w, r)
+									default:
+										s.notAllowed(w, r, "POST")
+									}
+
+									return
+								}
+
+							case 'r': // Prefix: "requeue"
+
+								if l := len("requeue"); len(elem) >= l && elem[0:l] == "requeue" {
+									elem = elem[l:]
+								} else {
+									break
+								}
+
+								if len(elem) == 0 {
+									// Leaf node.
+									switch r.Method {
+									case "POST":
+										s.handleRequeueScanRequest([1]string{
+											args[0],
+										}, elemIsEscaped, w, r)
+									default:
+										s.notAllowed(w, r, "POST")
+									}
+
+									return
+								}
+
+							}
+
+						}
+
+					}
+
+				}
+
+			case 's': // Prefix: "scans"
+
+				if l := len("scans"); len(elem) >= l && elem[0:l] == "scans" {
+					elem = elem[l:]
+				} else {
 					break
 				}
-				args[0] = elem
-				elem = ""
 
 				if len(elem) == 0 {
-					// Leaf node.
 					switch r.Method {
-					case "DELETE":
-						s.handleDeleteScanRequest([1]string{
-							args[0],
-						}, elemIsEscaped, w, r)
 					case "GET":
-						s.handleGetScanRequest([1]string{
-							args[0],
-						}, elemIsEscaped, w, r)
+						s.handleListScansRequest([0]string{}, elemIsEscaped, w, r)
+					case "POST":
+						s.handleCreateScanRequest([0]string{}, elemIsEscaped, w, r)
 					default:
-						s.notAllowed(w, r, "DELETE,GET")
+						s.notAllowed(w, r, "GET,POST")
 					}
 
 					return
 				}
+				switch elem[0] {
+				case '/': // Prefix: "/"
+
+					if l := len("/"); len(elem) >= l && elem[0:l] == "/" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					// Param: "id"
+					// Match until "/"
+					idx := strings.IndexByte(elem, '/')
+					if idx < 0 {
+						idx = len(elem)
+					}
+					args[0] = elem[:idx]
+					elem = elem[idx:]
+
+					if len(elem) == 0 {
+						switch r.Method {
+						case "DELETE":
+							s.handleDeleteScanRequest([1]string{
+								args[0],
+							}, elemIsEscaped, w, r)
+						case "GET":
+							s.handleGetScanRequest([1]string{
+								args[0],
+							}, elemIsEscaped, w, r)
+						default:
+							s.notAllowed(w, r, "DELETE,GET")
+						}
+
+						return
+					}
+					switch elem[0] {
+					case '/': // Prefix: "/attempts"
+
+						if l := len("/attempts"); len(elem) >= l && elem[0:l] == "/attempts" {
+							elem = elem[l:]
+						} else {
+							break
+						}
+
+						if len(elem) == 0 {
+							// Leaf node.
+							switch r.Method {
+							case "GET":
+								s.handleListScanAttemptsRequest([1]string{
+									args[0],
+								}, elemIsEscaped, w, r)
+							default:
+								s.notAllowed(w, r, "GET")
+							}
+
+							return
+						}
+
+					}
+
+				case ':': // Prefix: ":"
+
+					if l := len(":"); len(elem) >= l && elem[0:l] == ":" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					if len(elem) == 0 {
+						break
+					}
+					switch elem[0] {
+					case 'b': // Prefix: "batchGet"
+
+						if l := len("batchGet"); len(elem) >= l && elem[0:l] == "batchGet" {
+							elem = elem[l:]
+						} else {
+							break
+						}
+
+						if len(elem) == 0 {
+							// Leaf node.
+							switch r.Method {
+							case "POST":
+								s.handleBatchGetScansRequest([0]string{}, elemIsEscaped, w, r)
+							default:
+								s.notAllowed(w, r, "POST")
+							}
+
+							return
+						}
+
+					case 'e': // Prefix: "export"
+
+						if l := len("export"); len(elem) >= l && elem[0:l] == "export" {
+							elem = elem[l:]
+						} else {
+							break
+						}
+
+						if len(elem) == 0 {
+							// Leaf node.
+							switch r.Method {
+							case "GET":
+								s.handleExportScansRequest([0]string{}, elemIsEscaped, w, r)
+							default:
+								s.notAllowed(w, r, "GET")
+							}
+
+							return
+						}
+
+					case 'l': // Prefix: "latest"
+
+						if l := len("latest"); len(elem) >= l && elem[0:l] == "latest" {
+							elem = elem[l:]
+						} else {
+							break
+						}
+
+						if len(elem) == 0 {
+							// Leaf node.
+							switch r.Method {
+							case "GET":
+								s.handleGetLatestScanRequest([0]string{}, elemIsEscaped, w, r)
+							default:
+								s.notAllowed(w, r, "GET")
+							}
+
+							return
+						}
+
+					case 's': // Prefix: "s"
+
+						if l := len("s"); len(elem) >= l && elem[0:l] == "s" {
+							elem = elem[l:]
+						} else {
+							break
+						}
+
+						if len(elem) == 0 {
+							break
+						}
+						switch elem[0] {
+						case 'e': // Prefix: "earch"
+
+							if l := len("earch"); len(elem) >= l && elem[0:l] == "earch" {
+								elem = elem[l:]
+							} else {
+								break
+							}
+
+							if len(elem) == 0 {
+								// Leaf node.
+								switch r.Method {
+								case "GET":
+									s.handleSearchScansRequest([0]string{}, elemIsEscaped, w, r)
+								default:
+									s.notAllowed(w, r, "GET")
+								}
+
+								return
+							}
+
+						case 'u': // Prefix: "ummary"
+
+							if l := len("ummary"); len(elem) >= l && elem[0:l] == "ummary" {
+								elem = elem[l:]
+							} else {
+								break
+							}
+
+							if len(elem) == 0 {
+								// Leaf node.
+								switch r.Method {
+								case "GET":
+									s.handleGetScanSummaryRequest([0]string{}, elemIsEscaped, w, r)
+								default:
+									s.notAllowed(w, r, "GET")
+								}
+
+								return
+							}
+
+						case 'y': // Prefix: "ync"
+
+							if l := len("ync"); len(elem) >= l && elem[0:l] == "ync" {
+								elem = elem[l:]
+							} else {
+								break
+							}
+
+							if len(elem) == 0 {
+								// Leaf node.
+								switch r.Method {
+								case "POST":
+									s.handleCreateScanSyncRequest([0]string{}, elemIsEscaped, w, r)
+								default:
+									s.notAllowed(w, r, "POST")
+								}
+
+								return
+							}
+
+						}
+
+					}
+
+				}
 
 			}
 
@@ -187,77 +528,460 @@ func (s *Server) FindPath(method string, u *url.URL) (r Route, _ bool) {
 			break
 		}
 		switch elem[0] {
-		case '/': // Prefix: "/scans"
+		case '/': // Prefix: "/"
 
-			if l := len("/scans"); len(elem) >= l && elem[0:l] == "/scans" {
+			if l := len("/"); len(elem) >= l && elem[0:l] == "/" {
 				elem = elem[l:]
 			} else {
 				break
 			}
 
 			if len(elem) == 0 {
-				switch method {
-				case "GET":
-					r.name = ListScansOperation
-					r.summary = "List scans for the authenticated user (cursor pagination)"
-					r.operationID = "listScans"
-					r.pathPattern = "/scans"
-					r.args = args
-					r.count = 0
-					return r, true
-				case "POST":
-					r.name = CreateScanOperation
-					r.summary = "Submit a URL for scanning"
-					r.operationID = "createScan"
-					r.pathPattern = "/scans"
-					r.args = args
-					r.count = 0
-					return r, true
-				default:
-					return
-				}
+				break
 			}
 			switch elem[0] {
-			case '/': // Prefix: "/"
+			case 'a': // Prefix: "admin/"
 
-				if l := len("/"); len(elem) >= l && elem[0:l] == "/" {
+				if l := len("admin/"); len(elem) >= l && elem[0:l] == "admin/" {
 					elem = elem[l:]
 				} else {
 					break
 				}
 
-				// Param: "id"
-				// Leaf parameter, slashes are prohibited
-				idx := strings.IndexByte(elem, '/')
-				if idx >= 0 {
+				if len(elem) == 0 {
+					break
+				}
+				switch elem[0] {
+				case 'a': // Prefix: "audit"
+
+					if l := len("audit"); len(elem) >= l && elem[0:l] == "audit" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					if len(elem) == 0 {
+						// Leaf node.
+						switch method {
+						case "GET":
+							r.name = ListAdminAuditLogOperation
+							r.summary = "List audit log entries (admin only)"
+							r.operationID = "listAdminAuditLog"
+							r.pathPattern = "/admin/audit"
+							r.args = args
+							r.count = 0
+							return r, true
+						default:
+							return
+						}
+					}
+
+				case 'j': // Prefix: "jobs"
+
+					if l := len("jobs"); len(elem) >= l && elem[0:l] == "jobs" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					if len(elem) == 0 {
+						// Leaf node.
+						switch method {
+						case "GET":
+							r.name = ListAdminJobsOperation
+							r.summary = "List queued scan jobs (admin only)"
+							r.operationID = "listAdminJobs"
+							r.pathPattern = "/admin/jobs"
+							r.args = args
+							r.count = 0
+							return r, true
+						default:
+							return
+						}
+					}
+
+				case 's': // Prefix: "scans"
+
+					if l := len("scans"); len(elem) >= l && elem[0:l] == "scans" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					if len(elem) == 0 {
+						switch method {
+						case "GET":
+							r.name = ListAdminScansOperation
+							r.summary = "List scans of all users (admin only)"
+							r.operationID = "listAdminScans"
+							r.pathPattern = "/admin/scans"
+							r.args = args
+							r.count = 0
+							return r, true
+						default:
+							return
+						}
+					}
+					switch elem[0] {
+					case '/': // Prefix: "/"
+
+						if l := len("/"); len(elem) >= l && elem[0:l] == "/" {
+							elem = elem[l:]
+						} else {
+							break
+						}
+
+						// Param: "id"
+						// Match until "/"
+						idx := strings.IndexByte(elem, '/')
+						if idx < 0 {
+							idx = len(elem)
+						}
+						args[0] = elem[:idx]
+						elem = elem[idx:]
+
+						if len(elem) == 0 {
+							break
+						}
+						switch elem[0] {
+						case '/': // Prefix: "/"
+
+							if l := len("/"); len(elem) >= l && elem[0:l] == "/" {
+								elem = elem[l:]
+							} else {
+								break
+							}
+
+							if len(elem) == 0 {
+								break
+							}
+							switch elem[0] {
+							case 'f': // Prefix: "fail"
+
+								if l := len("fail"); len(elem) >= l && elem[0:l] == "fail" {
+									elem = elem[l:]
+								} else {
+									break
+								}
+
+								if len(elem) == 0 {
+									// Leaf node.
+									switch method {
+									case "POST":
+										r.name = ForceFailScanOperation
+										r.summary = "Force a stuck pending scan to fail (admin only)"
+										r.operationID = "forceFailScan"
+										r.pathPattern = "/admin/scans/{id}/fail"
+										r.args = args
+										r.count = 1
+										return r, true
+									default:
+										return
+									}
+								}
+
+							case 'r': // Prefix: "requeue"
+
+								if l := len("requeue"); len(elem) >= l && elem[0:l] == "requeue" {
+									elem = elem[l:]
+								} else {
+									break
+								}
+
+								if len(elem) == 0 {
+									// Leaf node.
+									switch method {
+									case "POST":
+										r.name = RequeueScanOperation
+										r.summary = "Requeue a stuck pending scan (admin only)"
+										r.operationID = "requeueScan"
+										r.pathPattern = "/admin/scans/{id}/requeue"
+										r.args = args
+										r.count = 1
+										return r, true
+									default:
+										return
+									}
+								}
+
+							}
+
+						}
+
+					}
+
+				}
+
+			case 's': // Prefix: "scans"
+
+				if l := len("scans"); len(elem) >= l && elem[0:l] == "scans" {
+					elem = elem[l:]
+				} else {
 					break
 				}
-				args[0] = elem
-				elem = ""
 
 				if len(elem) == 0 {
-					// Leaf node.
 					switch method {
-					case "DELETE":
-						r.name = DeleteScanOperation
-						r.summary = "Delete a scan"
-						r.operationID = "deleteScan"
-						r.pathPattern = "/scans/{id}"
+					case "GET":
+						r.name = ListScansOperation
+						r.summary = "List scans for the authenticated user (cursor pagination)"
+						r.operationID = "listScans"
+						r.pathPattern = "/scans"
 						r.args = args
-						r.count = 1
+						r.count = 0
 						return r, true
-					case "GET":
-						r.name = GetScanOperation
-						r.summary = "Get a single scan"
-						r.operationID = "getScan"
-						r.pathPattern = "/scans/{id}"
+					case "POST":
+						r.name = CreateScanOperation
+						r.summary = "Submit a URL for scanning"
+						r.operationID = "createScan"
+						r.pathPattern = "/scans"
 						r.args = args
-						r.count = 1
+						r.count = 0
 						return r, true
 					default:
 						return
 					}
 				}
+				switch elem[0] {
+				case '/': // Prefix: "/"
+
+					if l := len("/"); len(elem) >= l && elem[0:l] == "/" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					// Param: "id"
+					// Match until "/"
+					idx := strings.IndexByte(elem, '/')
+					if idx < 0 {
+						idx = len(elem)
+					}
+					args[0] = elem[:idx]
+					elem = elem[idx:]
+
+					if len(elem) == 0 {
+						switch method {
+						case "DELETE":
+							r.name = DeleteScanOperation
+							r.summary = "Delete a scan"
+							r.operationID = "deleteScan"
+							r.pathPattern = "/scans/{id}"
+							r.args = args
+							r.count = 1
+							return r, true
+						case "GET":
+							r.name = GetScanOperation
+							r.summary = "Get a single scan"
+							r.operationID = "getScan"
+							r.pathPattern = "/scans/{id}"
+							r.args = args
+							r.count = 1
+							return r, true
+						default:
+							return
+						}
+					}
+					switch elem[0] {
+					case '/': // Prefix: "/attempts"
+
+						if l := len("/attempts"); len(elem) >= l && elem[0:l] == "/attempts" {
+							elem = elem[l:]
+						} else {
+							break
+						}
+
+						if len(elem) == 0 {
+							// Leaf node.
+							switch method {
+							case "GET":
+								r.name = ListScanAttemptsOperation
+								r.summary = "List the processing attempts of a scan"
+								r.operationID = "listScanAttempts"
+								r.pathPattern = "/scans/{id}/attempts"
+								r.args = args
+								r.count = 1
+								return r, true
+							default:
+								return
+							}
+						}
+
+					}
+
+				case ':': // Prefix: ":"
+
+					if l := len(":"); len(elem) >= l && elem[0:l] == ":" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					if len(elem) == 0 {
+						break
+					}
+					switch elem[0] {
+					case 'b': // Prefix: "batchGet"
+
+						if l := len("batchGet"); len(elem) >= l && elem[0:l] == "batchGet" {
+							elem = elem[l:]
+						} else {
+							break
+						}
+
+						if len(elem) == 0 {
+							// Leaf node.
+							switch method {
+							case "POST":
+								r.name = BatchGetScansOperation
+								r.summary = "Get multiple scans by ID"
+								r.operationID = "batchGetScans"
+								r.pathPattern = "/scans:batchGet"
+								r.args = args
+								r.count = 0
+								return r, true
+							default:
+								return
+							}
+						}
+
+					case 'e': // Prefix: "export"
+
+						if l := len("export"); len(elem) >= l && elem[0:l] == "export" {
+							elem = elem[l:]
+						} else {
+							break
+						}
+
+						if len(elem) == 0 {
+							// Leaf node.
+							switch method {
+							case "GET":
+								r.name = ExportScansOperation
+								r.summary = "Export all scans of the authenticated user"
+								r.operationID = "exportScans"
+								r.pathPattern = "/scans:export"
+								r.args = args
+								r.count = 0
+								return r, true
+							default:
+								return
+							}
+						}
+
+					case 'l': // Prefix: "latest"
+
+						if l := len("latest"); len(elem) >= l && elem[0:l] == "latest" {
+							elem = elem[l:]
+						} else {
+							break
+						}
+
+						if len(elem) == 0 {
+							// Leaf node.
+							switch method {
+							case "GET":
+								r.name = GetLatestScanOperation
+								r.summary = "Get the latest scan of a URL"
+								r.operationID = "getLatestScan"
+								r.pathPattern = "/scans:latest"
+								r.args = args
+								r.count = 0
+								return r, true
+							default:
+								return
+							}
+						}
+
+					case 's': // Prefix: "s"
+
+						if l := len("s"); len(elem) >= l && elem[0:l] == "s" {
+							elem = elem[l:]
+						} else {
+							break
+						}
+
+						if len(elem) == 0 {
+							break
+						}
+						switch elem[0] {
+						case 'e': // Prefix: "earch"
+
+							if l := len("earch"); len(elem) >= l && elem[0:l] == "earch" {
+								elem = elem[l:]
+							} else {
+								break
+							}
+
+							if len(elem) == 0 {
+								// Leaf node.
+								switch method {
+								case "GET":
+									r.name = SearchScansOperation
+									r.summary = "Search scans of the authenticated user by result field"
+									r.operationID = "searchScans"
+									r.pathPattern = "/scans:search"
+									r.args = args
+									r.count = 0
+									return r, true
+								default:
+									return
+								}
+							}
+
+						case 'u': // Prefix: "ummary"
+
+							if l := len("ummary"); len(elem) >= l && elem[0:l] == "ummary" {
+								elem = elem[l:]
+							} else {
+								break
+							}
+
+							if len(elem) == 0 {
+								// Leaf node.
+								switch method {
+								case "GET":
+									r.name = GetScanSummaryOperation
+									r.summary = "Count scans of the authenticated user by status"
+									r.operationID = "getScanSummary"
+									r.pathPattern = "/scans:summary"
+									r.args = args
+									r.count = 0
+									return r, true
+								default:
+									return
+								}
+							}
+
+						case 'y': // Prefix: "ync"
+
+							if l := len("ync"); len(elem) >= l && elem[0:l] == "ync" {
+								elem = elem[l:]
+							} else {
+								break
+							}
+
+							if len(elem) == 0 {
+								// Leaf node.
+								switch method {
+								case "POST":
+									r.name = CreateScanSyncOperation
+									r.summary = "Submit a URL for scanning and wait for the result"
+									r.operationID = "createScanSync"
+									r.pathPattern = "/scans:sync"
+									r.args = args
+									r.count = 0
+									return r, true
+								default:
+									return
+								}
+							}
+
+						}
+
+					}
+
+				}
 
 			}
 
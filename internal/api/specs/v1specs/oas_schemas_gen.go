@@ -4,6 +4,7 @@ package v1specs
 
 import (
 	"fmt"
+	"io"
 	"net/url"
 	"time"
 
@@ -16,6 +17,186 @@ func (s *ServerErrorStatusCode) Error() string {
 	return fmt.Sprintf("code %d: %+v", s.StatusCode, s.Response)
 }
 
+// Ref: #/components/schemas/AuditAction
+type AuditAction string
+
+const (
+	AuditActionCREATE AuditAction = "CREATE"
+	AuditActionDELETE AuditAction = "DELETE"
+)
+
+// AllValues returns all AuditAction values.
+func (AuditAction) AllValues() []AuditAction {
+	return []AuditAction{
+		AuditActionCREATE,
+		AuditActionDELETE,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s AuditAction) MarshalText() ([]byte, error) {
+	switch s {
+	case AuditActionCREATE:
+		return []byte(s), nil
+	case AuditActionDELETE:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *AuditAction) UnmarshalText(data []byte) error {
+	switch AuditAction(data) {
+	case AuditActionCREATE:
+		*s = AuditActionCREATE
+		return nil
+	case AuditActionDELETE:
+		*s = AuditActionDELETE
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
+// Ref: #/components/schemas/AuditEntry
+type AuditEntry struct {
+	ID        int64       `json:"id"`
+	UserId    uuid.UUID   `json:"userId"`
+	Action    AuditAction `json:"action"`
+	ScanId    uuid.UUID   `json:"scanId"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+// GetID returns the value of ID.
+func (s *AuditEntry) GetID() int64 {
+	return s.ID
+}
+
+// GetUserId returns the value of UserId.
+func (s *AuditEntry) GetUserId() uuid.UUID {
+	return s.UserId
+}
+
+// GetAction returns the value of Action.
+func (s *AuditEntry) GetAction() AuditAction {
+	return s.Action
+}
+
+// GetScanId returns the value of ScanId.
+func (s *AuditEntry) GetScanId() uuid.UUID {
+	return s.ScanId
+}
+
+// GetCreatedAt returns the value of CreatedAt.
+func (s *AuditEntry) GetCreatedAt() time.Time {
+	return s.CreatedAt
+}
+
+// SetID sets the value of ID.
+func (s *AuditEntry) SetID(val int64) {
+	s.ID = val
+}
+
+// SetUserId sets the value of UserId.
+func (s *AuditEntry) SetUserId(val uuid.UUID) {
+	s.UserId = val
+}
+
+// SetAction sets the value of Action.
+func (s *AuditEntry) SetAction(val AuditAction) {
+	s.Action = val
+}
+
+// SetScanId sets the value of ScanId.
+func (s *AuditEntry) SetScanId(val uuid.UUID) {
+	s.ScanId = val
+}
+
+// SetCreatedAt sets the value of CreatedAt.
+func (s *AuditEntry) SetCreatedAt(val time.Time) {
+	s.CreatedAt = val
+}
+
+// Ref: #/components/schemas/AuditEntryList
+type AuditEntryList struct {
+	Items      []AuditEntry `json:"items"`
+	NextCursor OptNilString `json:"next_cursor"`
+}
+
+// GetItems returns the value of Items.
+func (s *AuditEntryList) GetItems() []AuditEntry {
+	return s.Items
+}
+
+// GetNextCursor returns the value of NextCursor.
+func (s *AuditEntryList) GetNextCursor() OptNilString {
+	return s.NextCursor
+}
+
+// SetItems sets the value of Items.
+func (s *AuditEntryList) SetItems(val []AuditEntry) {
+	s.Items = val
+}
+
+// SetNextCursor sets the value of NextCursor.
+func (s *AuditEntryList) SetNextCursor(val OptNilString) {
+	s.NextCursor = val
+}
+
+func (*AuditEntryList) listAdminAuditLogRes() {}
+
+type BatchGetScansBadRequest Error
+
+func (*BatchGetScansBadRequest) batchGetScansRes() {}
+
+// Ref: #/components/schemas/BatchGetScansRequest
+type BatchGetScansRequest struct {
+	Ids []uuid.UUID `json:"ids"`
+}
+
+// GetIds returns the value of Ids.
+func (s *BatchGetScansRequest) GetIds() []uuid.UUID {
+	return s.Ids
+}
+
+// SetIds sets the value of Ids.
+func (s *BatchGetScansRequest) SetIds(val []uuid.UUID) {
+	s.Ids = val
+}
+
+// Ref: #/components/schemas/BatchGetScansResponse
+type BatchGetScansResponse struct {
+	Items    []Scan      `json:"items"`
+	NotFound []uuid.UUID `json:"notFound"`
+}
+
+// GetItems returns the value of Items.
+func (s *BatchGetScansResponse) GetItems() []Scan {
+	return s.Items
+}
+
+// GetNotFound returns the value of NotFound.
+func (s *BatchGetScansResponse) GetNotFound() []uuid.UUID {
+	return s.NotFound
+}
+
+// SetItems sets the value of Items.
+func (s *BatchGetScansResponse) SetItems(val []Scan) {
+	s.Items = val
+}
+
+// SetNotFound sets the value of NotFound.
+func (s *BatchGetScansResponse) SetNotFound(val []uuid.UUID) {
+	s.NotFound = val
+}
+
+func (*BatchGetScansResponse) batchGetScansRes() {}
+
+type BatchGetScansUnauthorized Error
+
+func (*BatchGetScansUnauthorized) batchGetScansRes() {}
+
 type BearerAuth struct {
 	Token string
 	Roles []string
@@ -45,9 +226,18 @@ type CreateScanBadRequest Error
 
 func (*CreateScanBadRequest) createScanRes() {}
 
+type CreateScanForbidden Error
+
+func (*CreateScanForbidden) createScanRes() {}
+
 // Ref: #/components/schemas/CreateScanRequest
 type CreateScanRequest struct {
 	URL url.URL `json:"url"`
+	// Tags attached to the scan submitted to urlscan.io for later filtering.
+	Tags []string `json:"tags"`
+	// Visibility of the scan submitted to urlscan.io, overriding the server's configured default (public
+	// unless configured otherwise). Only results of public scans are reused for other users.
+	Visibility OptCreateScanRequestVisibility `json:"visibility"`
 }
 
 // GetURL returns the value of URL.
@@ -55,11 +245,109 @@ func (s *CreateScanRequest) GetURL() url.URL {
 	return s.URL
 }
 
+// GetTags returns the value of Tags.
+func (s *CreateScanRequest) GetTags() []string {
+	return s.Tags
+}
+
+// GetVisibility returns the value of Visibility.
+func (s *CreateScanRequest) GetVisibility() OptCreateScanRequestVisibility {
+	return s.Visibility
+}
+
 // SetURL sets the value of URL.
 func (s *CreateScanRequest) SetURL(val url.URL) {
 	s.URL = val
 }
 
+// SetTags sets the value of Tags.
+func (s *CreateScanRequest) SetTags(val []string) {
+	s.Tags = val
+}
+
+// SetVisibility sets the value of Visibility.
+func (s *CreateScanRequest) SetVisibility(val OptCreateScanRequestVisibility) {
+	s.Visibility = val
+}
+
+// Visibility of the scan submitted to urlscan.io, overriding the server's configured default (public
+// unless configured otherwise). Only results of public scans are reused for other users.
+type CreateScanRequestVisibility string
+
+const (
+	CreateScanRequestVisibilityPublic   CreateScanRequestVisibility = "public"
+	CreateScanRequestVisibilityUnlisted CreateScanRequestVisibility = "unlisted"
+	CreateScanRequestVisibilityPrivate  CreateScanRequestVisibility = "private"
+)
+
+// AllValues returns all CreateScanRequestVisibility values.
+func (CreateScanRequestVisibility) AllValues() []CreateScanRequestVisibility {
+	return []CreateScanRequestVisibility{
+		CreateScanRequestVisibilityPublic,
+		CreateScanRequestVisibilityUnlisted,
+		CreateScanRequestVisibilityPrivate,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s CreateScanRequestVisibility) MarshalText() ([]byte, error) {
+	switch s {
+	case CreateScanRequestVisibilityPublic:
+		return []byte(s), nil
+	case CreateScanRequestVisibilityUnlisted:
+		return []byte(s), nil
+	case CreateScanRequestVisibilityPrivate:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *CreateScanRequestVisibility) UnmarshalText(data []byte) error {
+	switch CreateScanRequestVisibility(data) {
+	case CreateScanRequestVisibilityPublic:
+		*s = CreateScanRequestVisibilityPublic
+		return nil
+	case CreateScanRequestVisibilityUnlisted:
+		*s = CreateScanRequestVisibilityUnlisted
+		return nil
+	case CreateScanRequestVisibilityPrivate:
+		*s = CreateScanRequestVisibilityPrivate
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
+type CreateScanSyncAccepted Scan
+
+func (*CreateScanSyncAccepted) createScanSyncRes() {}
+
+type CreateScanSyncBadRequest Error
+
+func (*CreateScanSyncBadRequest) createScanSyncRes() {}
+
+type CreateScanSyncForbidden Error
+
+func (*CreateScanSyncForbidden) createScanSyncRes() {}
+
+type CreateScanSyncOK Scan
+
+func (*CreateScanSyncOK) createScanSyncRes() {}
+
+type CreateScanSyncTooManyRequests Error
+
+func (*CreateScanSyncTooManyRequests) createScanSyncRes() {}
+
+type CreateScanSyncUnauthorized Error
+
+func (*CreateScanSyncUnauthorized) createScanSyncRes() {}
+
+type CreateScanTooManyRequests Error
+
+func (*CreateScanTooManyRequests) createScanRes() {}
+
 type CreateScanUnauthorized Error
 
 func (*CreateScanUnauthorized) createScanRes() {}
@@ -79,13 +367,15 @@ func (*DeleteScanUnauthorized) deleteScanRes() {}
 
 // Ref: #/components/schemas/Error
 type Error struct {
-	Code    string          `json:"code"`
-	Message string          `json:"message"`
-	Details OptErrorDetails `json:"details"`
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+	// ID of the request, also sent in the `X-Request-Id` response header.
+	RequestId OptString       `json:"requestId"`
+	Details   OptErrorDetails `json:"details"`
 }
 
 // GetCode returns the value of Code.
-func (s *Error) GetCode() string {
+func (s *Error) GetCode() ErrorCode {
 	return s.Code
 }
 
@@ -94,13 +384,18 @@ func (s *Error) GetMessage() string {
 	return s.Message
 }
 
+// GetRequestId returns the value of RequestId.
+func (s *Error) GetRequestId() OptString {
+	return s.RequestId
+}
+
 // GetDetails returns the value of Details.
 func (s *Error) GetDetails() OptErrorDetails {
 	return s.Details
 }
 
 // SetCode sets the value of Code.
-func (s *Error) SetCode(val string) {
+func (s *Error) SetCode(val ErrorCode) {
 	s.Code = val
 }
 
@@ -109,12 +404,119 @@ func (s *Error) SetMessage(val string) {
 	s.Message = val
 }
 
+// SetRequestId sets the value of RequestId.
+func (s *Error) SetRequestId(val OptString) {
+	s.RequestId = val
+}
+
 // SetDetails sets the value of Details.
 func (s *Error) SetDetails(val OptErrorDetails) {
 	s.Details = val
 }
 
-func (*Error) listScansRes() {}
+func (*Error) getScanSummaryRes() {}
+func (*Error) listScansRes()      {}
+
+// Stable, machine-readable error code. `BAD_REQUEST` (400), `UNAUTHORIZED` (401), `FORBIDDEN` (403),
+// `NOT_FOUND` (404), `CONFLICT` (409), `PAYLOAD_TOO_LARGE` (413), `RATE_LIMITED` (429), `INTERNAL`
+// (500), `UNAVAILABLE` (503), `TIMEOUT` (504).
+// Ref: #/components/schemas/ErrorCode
+type ErrorCode string
+
+const (
+	ErrorCodeBADREQUEST      ErrorCode = "BAD_REQUEST"
+	ErrorCodeUNAUTHORIZED    ErrorCode = "UNAUTHORIZED"
+	ErrorCodeFORBIDDEN       ErrorCode = "FORBIDDEN"
+	ErrorCodeNOTFOUND        ErrorCode = "NOT_FOUND"
+	ErrorCodeCONFLICT        ErrorCode = "CONFLICT"
+	ErrorCodePAYLOADTOOLARGE ErrorCode = "PAYLOAD_TOO_LARGE"
+	ErrorCodeRATELIMITED     ErrorCode = "RATE_LIMITED"
+	ErrorCodeINTERNAL        ErrorCode = "INTERNAL"
+	ErrorCodeUNAVAILABLE     ErrorCode = "UNAVAILABLE"
+	ErrorCodeTIMEOUT         ErrorCode = "TIMEOUT"
+)
+
+// AllValues returns all ErrorCode values.
+func (ErrorCode) AllValues() []ErrorCode {
+	return []ErrorCode{
+		ErrorCodeBADREQUEST,
+		ErrorCodeUNAUTHORIZED,
+		ErrorCodeFORBIDDEN,
+		ErrorCodeNOTFOUND,
+		ErrorCodeCONFLICT,
+		ErrorCodePAYLOADTOOLARGE,
+		ErrorCodeRATELIMITED,
+		ErrorCodeINTERNAL,
+		ErrorCodeUNAVAILABLE,
+		ErrorCodeTIMEOUT,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s ErrorCode) MarshalText() ([]byte, error) {
+	switch s {
+	case ErrorCodeBADREQUEST:
+		return []byte(s), nil
+	case ErrorCodeUNAUTHORIZED:
+		return []byte(s), nil
+	case ErrorCodeFORBIDDEN:
+		return []byte(s), nil
+	case ErrorCodeNOTFOUND:
+		return []byte(s), nil
+	case ErrorCodeCONFLICT:
+		return []byte(s), nil
+	case ErrorCodePAYLOADTOOLARGE:
+		return []byte(s), nil
+	case ErrorCodeRATELIMITED:
+		return []byte(s), nil
+	case ErrorCodeINTERNAL:
+		return []byte(s), nil
+	case ErrorCodeUNAVAILABLE:
+		return []byte(s), nil
+	case ErrorCodeTIMEOUT:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *ErrorCode) UnmarshalText(data []byte) error {
+	switch ErrorCode(data) {
+	case ErrorCodeBADREQUEST:
+		*s = ErrorCodeBADREQUEST
+		return nil
+	case ErrorCodeUNAUTHORIZED:
+		*s = ErrorCodeUNAUTHORIZED
+		return nil
+	case ErrorCodeFORBIDDEN:
+		*s = ErrorCodeFORBIDDEN
+		return nil
+	case ErrorCodeNOTFOUND:
+		*s = ErrorCodeNOTFOUND
+		return nil
+	case ErrorCodeCONFLICT:
+		*s = ErrorCodeCONFLICT
+		return nil
+	case ErrorCodePAYLOADTOOLARGE:
+		*s = ErrorCodePAYLOADTOOLARGE
+		return nil
+	case ErrorCodeRATELIMITED:
+		*s = ErrorCodeRATELIMITED
+		return nil
+	case ErrorCodeINTERNAL:
+		*s = ErrorCodeINTERNAL
+		return nil
+	case ErrorCodeUNAVAILABLE:
+		*s = ErrorCodeUNAVAILABLE
+		return nil
+	case ErrorCodeTIMEOUT:
+		*s = ErrorCodeTIMEOUT
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
 
 type ErrorDetails map[string]jx.Raw
 
@@ -127,46 +529,762 @@ func (s *ErrorDetails) init() ErrorDetails {
 	return m
 }
 
-type GetScanNotFound Error
+type ExportScansBadRequest Error
 
-func (*GetScanNotFound) getScanRes() {}
+func (*ExportScansBadRequest) exportScansRes() {}
 
-type GetScanUnauthorized Error
+type ExportScansFormat string
+
+const (
+	ExportScansFormatNdjson ExportScansFormat = "ndjson"
+	ExportScansFormatCsv    ExportScansFormat = "csv"
+)
+
+// AllValues returns all ExportScansFormat values.
+func (ExportScansFormat) AllValues() []ExportScansFormat {
+	return []ExportScansFormat{
+		ExportScansFormatNdjson,
+		ExportScansFormatCsv,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s ExportScansFormat) MarshalText() ([]byte, error) {
+	switch s {
+	case ExportScansFormatNdjson:
+		return []byte(s), nil
+	case ExportScansFormatCsv:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *ExportScansFormat) UnmarshalText(data []byte) error {
+	switch ExportScansFormat(data) {
+	case ExportScansFormatNdjson:
+		*s = ExportScansFormatNdjson
+		return nil
+	case ExportScansFormatCsv:
+		*s = ExportScansFormatCsv
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
+type ExportScansOKApplicationXNdjson struct {
+	Data io.Reader
+}
+
+// Read reads data from the Data reader.
+//
+// Kept to satisfy the io.Reader interface.
+func (s ExportScansOKApplicationXNdjson) Read(p []byte) (n int, err error) {
+	if s.Data == nil {
+		return 0, io.EOF
+	}
+	return s.Data.Read(p)
+}
+
+// ExportScansOKApplicationXNdjsonHeaders wraps ExportScansOKApplicationXNdjson with response headers.
+type ExportScansOKApplicationXNdjsonHeaders struct {
+	ContentDisposition OptString
+	Response           ExportScansOKApplicationXNdjson
+}
+
+// GetContentDisposition returns the value of ContentDisposition.
+func (s *ExportScansOKApplicationXNdjsonHeaders) GetContentDisposition() OptString {
+	return s.ContentDisposition
+}
+
+// GetResponse returns the value of Response.
+func (s *ExportScansOKApplicationXNdjsonHeaders) GetResponse() ExportScansOKApplicationXNdjson {
+	return s.Response
+}
+
+// SetContentDisposition sets the value of ContentDisposition.
+func (s *ExportScansOKApplicationXNdjsonHeaders) SetContentDisposition(val OptString) {
+	s.ContentDisposition = val
+}
+
+// SetResponse sets the value of Response.
+func (s *ExportScansOKApplicationXNdjsonHeaders) SetResponse(val ExportScansOKApplicationXNdjson) {
+	s.Response = val
+}
+
+func (*ExportScansOKApplicationXNdjsonHeaders) exportScansRes() {}
+
+type ExportScansOKTextCsv struct {
+	Data io.Reader
+}
+
+// Read reads data from the Data reader.
+//
+// Kept to satisfy the io.Reader interface.
+func (s ExportScansOKTextCsv) Read(p []byte) (n int, err error) {
+	if s.Data == nil {
+		return 0, io.EOF
+	}
+	return s.Data.Read(p)
+}
+
+// ExportScansOKTextCsvHeaders wraps ExportScansOKTextCsv with response headers.
+type ExportScansOKTextCsvHeaders struct {
+	ContentDisposition OptString
+	Response           ExportScansOKTextCsv
+}
+
+// GetContentDisposition returns the value of ContentDisposition.
+func (s *ExportScansOKTextCsvHeaders) GetContentDisposition() OptString {
+	return s.ContentDisposition
+}
+
+// GetResponse returns the value of Response.
+func (s *ExportScansOKTextCsvHeaders) GetResponse() ExportScansOKTextCsv {
+	return s.Response
+}
+
+// SetContentDisposition sets the value of ContentDisposition.
+func (s *ExportScansOKTextCsvHeaders) SetContentDisposition(val OptString) {
+	s.ContentDisposition = val
+}
+
+// SetResponse sets the value of Response.
+func (s *ExportScansOKTextCsvHeaders) SetResponse(val ExportScansOKTextCsv) {
+	s.Response = val
+}
+
+func (*ExportScansOKTextCsvHeaders) exportScansRes() {}
+
+type ExportScansUnauthorized Error
+
+func (*ExportScansUnauthorized) exportScansRes() {}
+
+type ForceFailScanBadRequest Error
+
+func (*ForceFailScanBadRequest) forceFailScanRes() {}
+
+type ForceFailScanConflict Error
+
+func (*ForceFailScanConflict) forceFailScanRes() {}
+
+type ForceFailScanForbidden Error
+
+func (*ForceFailScanForbidden) forceFailScanRes() {}
+
+type ForceFailScanNotFound Error
+
+func (*ForceFailScanNotFound) forceFailScanRes() {}
+
+// Ref: #/components/schemas/ForceFailScanRequest
+type ForceFailScanRequest struct {
+	Reason string `json:"reason"`
+}
+
+// GetReason returns the value of Reason.
+func (s *ForceFailScanRequest) GetReason() string {
+	return s.Reason
+}
+
+// SetReason sets the value of Reason.
+func (s *ForceFailScanRequest) SetReason(val string) {
+	s.Reason = val
+}
+
+type ForceFailScanUnauthorized Error
+
+func (*ForceFailScanUnauthorized) forceFailScanRes() {}
+
+type GetLatestScanBadRequest Error
+
+func (*GetLatestScanBadRequest) getLatestScanRes() {}
+
+type GetLatestScanNotFound Error
+
+func (*GetLatestScanNotFound) getLatestScanRes() {}
+
+type GetLatestScanUnauthorized Error
+
+func (*GetLatestScanUnauthorized) getLatestScanRes() {}
+
+type GetScanNotFound Error
+
+func (*GetScanNotFound) getScanRes() {}
+
+// GetScanNotModified is response for GetScan operation.
+type GetScanNotModified struct {
+	ETag OptString
+}
+
+// GetETag returns the value of ETag.
+func (s *GetScanNotModified) GetETag() OptString {
+	return s.ETag
+}
+
+// SetETag sets the value of ETag.
+func (s *GetScanNotModified) SetETag(val OptString) {
+	s.ETag = val
+}
+
+func (*GetScanNotModified) getScanRes() {}
+
+type GetScanUnauthorized Error
+
+func (*GetScanUnauthorized) getScanRes() {}
+
+// Ref: #/components/schemas/Job
+type Job struct {
+	ID          int64     `json:"id"`
+	Kind        string    `json:"kind"`
+	URL         OptString `json:"url"`
+	State       JobState  `json:"state"`
+	Queue       string    `json:"queue"`
+	Attempt     int       `json:"attempt"`
+	MaxAttempts int       `json:"maxAttempts"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// GetID returns the value of ID.
+func (s *Job) GetID() int64 {
+	return s.ID
+}
+
+// GetKind returns the value of Kind.
+func (s *Job) GetKind() string {
+	return s.Kind
+}
+
+// GetURL returns the value of URL.
+func (s *Job) GetURL() OptString {
+	return s.URL
+}
+
+// GetState returns the value of State.
+func (s *Job) GetState() JobState {
+	return s.State
+}
+
+// GetQueue returns the value of Queue.
+func (s *Job) GetQueue() string {
+	return s.Queue
+}
+
+// GetAttempt returns the value of Attempt.
+func (s *Job) GetAttempt() int {
+	return s.Attempt
+}
+
+// GetMaxAttempts returns the value of MaxAttempts.
+func (s *Job) GetMaxAttempts() int {
+	return s.MaxAttempts
+}
+
+// GetCreatedAt returns the value of CreatedAt.
+func (s *Job) GetCreatedAt() time.Time {
+	return s.CreatedAt
+}
+
+// SetID sets the value of ID.
+func (s *Job) SetID(val int64) {
+	s.ID = val
+}
+
+// SetKind sets the value of Kind.
+func (s *Job) SetKind(val string) {
+	s.Kind = val
+}
+
+// SetURL sets the value of URL.
+func (s *Job) SetURL(val OptString) {
+	s.URL = val
+}
+
+// SetState sets the value of State.
+func (s *Job) SetState(val JobState) {
+	s.State = val
+}
+
+// SetQueue sets the value of Queue.
+func (s *Job) SetQueue(val string) {
+	s.Queue = val
+}
+
+// SetAttempt sets the value of Attempt.
+func (s *Job) SetAttempt(val int) {
+	s.Attempt = val
+}
+
+// SetMaxAttempts sets the value of MaxAttempts.
+func (s *Job) SetMaxAttempts(val int) {
+	s.MaxAttempts = val
+}
+
+// SetCreatedAt sets the value of CreatedAt.
+func (s *Job) SetCreatedAt(val time.Time) {
+	s.CreatedAt = val
+}
+
+// Ref: #/components/schemas/JobList
+type JobList struct {
+	Items      []Job        `json:"items"`
+	NextCursor OptNilString `json:"next_cursor"`
+}
+
+// GetItems returns the value of Items.
+func (s *JobList) GetItems() []Job {
+	return s.Items
+}
+
+// GetNextCursor returns the value of NextCursor.
+func (s *JobList) GetNextCursor() OptNilString {
+	return s.NextCursor
+}
+
+// SetItems sets the value of Items.
+func (s *JobList) SetItems(val []Job) {
+	s.Items = val
+}
+
+// SetNextCursor sets the value of NextCursor.
+func (s *JobList) SetNextCursor(val OptNilString) {
+	s.NextCursor = val
+}
+
+func (*JobList) listAdminJobsRes() {}
+
+// Ref: #/components/schemas/JobState
+type JobState string
+
+const (
+	JobStateAvailable JobState = "available"
+	JobStateCancelled JobState = "cancelled"
+	JobStateCompleted JobState = "completed"
+	JobStateDiscarded JobState = "discarded"
+	JobStatePending   JobState = "pending"
+	JobStateRetryable JobState = "retryable"
+	JobStateRunning   JobState = "running"
+	JobStateScheduled JobState = "scheduled"
+)
+
+// AllValues returns all JobState values.
+func (JobState) AllValues() []JobState {
+	return []JobState{
+		JobStateAvailable,
+		JobStateCancelled,
+		JobStateCompleted,
+		JobStateDiscarded,
+		JobStatePending,
+		JobStateRetryable,
+		JobStateRunning,
+		JobStateScheduled,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s JobState) MarshalText() ([]byte, error) {
+	switch s {
+	case JobStateAvailable:
+		return []byte(s), nil
+	case JobStateCancelled:
+		return []byte(s), nil
+	case JobStateCompleted:
+		return []byte(s), nil
+	case JobStateDiscarded:
+		return []byte(s), nil
+	case JobStatePending:
+		return []byte(s), nil
+	case JobStateRetryable:
+		return []byte(s), nil
+	case JobStateRunning:
+		return []byte(s), nil
+	case JobStateScheduled:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *JobState) UnmarshalText(data []byte) error {
+	switch JobState(data) {
+	case JobStateAvailable:
+		*s = JobStateAvailable
+		return nil
+	case JobStateCancelled:
+		*s = JobStateCancelled
+		return nil
+	case JobStateCompleted:
+		*s = JobStateCompleted
+		return nil
+	case JobStateDiscarded:
+		*s = JobStateDiscarded
+		return nil
+	case JobStatePending:
+		*s = JobStatePending
+		return nil
+	case JobStateRetryable:
+		*s = JobStateRetryable
+		return nil
+	case JobStateRunning:
+		*s = JobStateRunning
+		return nil
+	case JobStateScheduled:
+		*s = JobStateScheduled
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
+type ListAdminAuditLogBadRequest Error
+
+func (*ListAdminAuditLogBadRequest) listAdminAuditLogRes() {}
+
+type ListAdminAuditLogForbidden Error
+
+func (*ListAdminAuditLogForbidden) listAdminAuditLogRes() {}
+
+type ListAdminAuditLogUnauthorized Error
+
+func (*ListAdminAuditLogUnauthorized) listAdminAuditLogRes() {}
+
+type ListAdminJobsBadRequest Error
+
+func (*ListAdminJobsBadRequest) listAdminJobsRes() {}
+
+type ListAdminJobsForbidden Error
+
+func (*ListAdminJobsForbidden) listAdminJobsRes() {}
+
+type ListAdminJobsUnauthorized Error
+
+func (*ListAdminJobsUnauthorized) listAdminJobsRes() {}
+
+type ListAdminScansBadRequest Error
+
+func (*ListAdminScansBadRequest) listAdminScansRes() {}
+
+type ListAdminScansForbidden Error
+
+func (*ListAdminScansForbidden) listAdminScansRes() {}
+
+type ListAdminScansUnauthorized Error
+
+func (*ListAdminScansUnauthorized) listAdminScansRes() {}
+
+type ListScanAttemptsNotFound Error
+
+func (*ListScanAttemptsNotFound) listScanAttemptsRes() {}
+
+type ListScanAttemptsUnauthorized Error
+
+func (*ListScanAttemptsUnauthorized) listScanAttemptsRes() {}
+
+type ListScansDirection string
+
+const (
+	ListScansDirectionNext ListScansDirection = "next"
+	ListScansDirectionPrev ListScansDirection = "prev"
+)
+
+// AllValues returns all ListScansDirection values.
+func (ListScansDirection) AllValues() []ListScansDirection {
+	return []ListScansDirection{
+		ListScansDirectionNext,
+		ListScansDirectionPrev,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s ListScansDirection) MarshalText() ([]byte, error) {
+	switch s {
+	case ListScansDirectionNext:
+		return []byte(s), nil
+	case ListScansDirectionPrev:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *ListScansDirection) UnmarshalText(data []byte) error {
+	switch ListScansDirection(data) {
+	case ListScansDirectionNext:
+		*s = ListScansDirectionNext
+		return nil
+	case ListScansDirectionPrev:
+		*s = ListScansDirectionPrev
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
+// NewOptAuditAction returns new OptAuditAction with value set to v.
+func NewOptAuditAction(v AuditAction) OptAuditAction {
+	return OptAuditAction{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptAuditAction is optional AuditAction.
+type OptAuditAction struct {
+	Value AuditAction
+	Set   bool
+}
+
+// IsSet returns true if OptAuditAction was set.
+func (o OptAuditAction) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptAuditAction) Reset() {
+	var v AuditAction
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptAuditAction) SetTo(v AuditAction) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptAuditAction) Get() (v AuditAction, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptAuditAction) Or(d AuditAction) AuditAction {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptBool returns new OptBool with value set to v.
+func NewOptBool(v bool) OptBool {
+	return OptBool{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptBool is optional bool.
+type OptBool struct {
+	Value bool
+	Set   bool
+}
+
+// IsSet returns true if OptBool was set.
+func (o OptBool) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptBool) Reset() {
+	var v bool
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptBool) SetTo(v bool) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptBool) Get() (v bool, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptBool) Or(d bool) bool {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptCreateScanRequestVisibility returns new OptCreateScanRequestVisibility with value set to v.
+func NewOptCreateScanRequestVisibility(v CreateScanRequestVisibility) OptCreateScanRequestVisibility {
+	return OptCreateScanRequestVisibility{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptCreateScanRequestVisibility is optional CreateScanRequestVisibility.
+type OptCreateScanRequestVisibility struct {
+	Value CreateScanRequestVisibility
+	Set   bool
+}
+
+// IsSet returns true if OptCreateScanRequestVisibility was set.
+func (o OptCreateScanRequestVisibility) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptCreateScanRequestVisibility) Reset() {
+	var v CreateScanRequestVisibility
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptCreateScanRequestVisibility) SetTo(v CreateScanRequestVisibility) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptCreateScanRequestVisibility) Get() (v CreateScanRequestVisibility, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptCreateScanRequestVisibility) Or(d CreateScanRequestVisibility) CreateScanRequestVisibility {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptDateTime returns new OptDateTime with value set to v.
+func NewOptDateTime(v time.Time) OptDateTime {
+	return OptDateTime{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptDateTime is optional time.Time.
+type OptDateTime struct {
+	Value time.Time
+	Set   bool
+}
+
+// IsSet returns true if OptDateTime was set.
+func (o OptDateTime) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptDateTime) Reset() {
+	var v time.Time
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptDateTime) SetTo(v time.Time) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptDateTime) Get() (v time.Time, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptDateTime) Or(d time.Time) time.Time {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptErrorDetails returns new OptErrorDetails with value set to v.
+func NewOptErrorDetails(v ErrorDetails) OptErrorDetails {
+	return OptErrorDetails{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptErrorDetails is optional ErrorDetails.
+type OptErrorDetails struct {
+	Value ErrorDetails
+	Set   bool
+}
+
+// IsSet returns true if OptErrorDetails was set.
+func (o OptErrorDetails) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptErrorDetails) Reset() {
+	var v ErrorDetails
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptErrorDetails) SetTo(v ErrorDetails) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptErrorDetails) Get() (v ErrorDetails, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
 
-func (*GetScanUnauthorized) getScanRes() {}
+// Or returns value if set, or given parameter if does not.
+func (o OptErrorDetails) Or(d ErrorDetails) ErrorDetails {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
 
-// NewOptBool returns new OptBool with value set to v.
-func NewOptBool(v bool) OptBool {
-	return OptBool{
+// NewOptExportScansFormat returns new OptExportScansFormat with value set to v.
+func NewOptExportScansFormat(v ExportScansFormat) OptExportScansFormat {
+	return OptExportScansFormat{
 		Value: v,
 		Set:   true,
 	}
 }
 
-// OptBool is optional bool.
-type OptBool struct {
-	Value bool
+// OptExportScansFormat is optional ExportScansFormat.
+type OptExportScansFormat struct {
+	Value ExportScansFormat
 	Set   bool
 }
 
-// IsSet returns true if OptBool was set.
-func (o OptBool) IsSet() bool { return o.Set }
+// IsSet returns true if OptExportScansFormat was set.
+func (o OptExportScansFormat) IsSet() bool { return o.Set }
 
 // Reset unsets value.
-func (o *OptBool) Reset() {
-	var v bool
+func (o *OptExportScansFormat) Reset() {
+	var v ExportScansFormat
 	o.Value = v
 	o.Set = false
 }
 
 // SetTo sets value to v.
-func (o *OptBool) SetTo(v bool) {
+func (o *OptExportScansFormat) SetTo(v ExportScansFormat) {
 	o.Set = true
 	o.Value = v
 }
 
 // Get returns value and boolean that denotes whether value was set.
-func (o OptBool) Get() (v bool, ok bool) {
+func (o OptExportScansFormat) Get() (v ExportScansFormat, ok bool) {
 	if !o.Set {
 		return v, false
 	}
@@ -174,45 +1292,45 @@ func (o OptBool) Get() (v bool, ok bool) {
 }
 
 // Or returns value if set, or given parameter if does not.
-func (o OptBool) Or(d bool) bool {
+func (o OptExportScansFormat) Or(d ExportScansFormat) ExportScansFormat {
 	if v, ok := o.Get(); ok {
 		return v
 	}
 	return d
 }
 
-// NewOptDateTime returns new OptDateTime with value set to v.
-func NewOptDateTime(v time.Time) OptDateTime {
-	return OptDateTime{
+// NewOptInt returns new OptInt with value set to v.
+func NewOptInt(v int) OptInt {
+	return OptInt{
 		Value: v,
 		Set:   true,
 	}
 }
 
-// OptDateTime is optional time.Time.
-type OptDateTime struct {
-	Value time.Time
+// OptInt is optional int.
+type OptInt struct {
+	Value int
 	Set   bool
 }
 
-// IsSet returns true if OptDateTime was set.
-func (o OptDateTime) IsSet() bool { return o.Set }
+// IsSet returns true if OptInt was set.
+func (o OptInt) IsSet() bool { return o.Set }
 
 // Reset unsets value.
-func (o *OptDateTime) Reset() {
-	var v time.Time
+func (o *OptInt) Reset() {
+	var v int
 	o.Value = v
 	o.Set = false
 }
 
 // SetTo sets value to v.
-func (o *OptDateTime) SetTo(v time.Time) {
+func (o *OptInt) SetTo(v int) {
 	o.Set = true
 	o.Value = v
 }
 
 // Get returns value and boolean that denotes whether value was set.
-func (o OptDateTime) Get() (v time.Time, ok bool) {
+func (o OptInt) Get() (v int, ok bool) {
 	if !o.Set {
 		return v, false
 	}
@@ -220,45 +1338,45 @@ func (o OptDateTime) Get() (v time.Time, ok bool) {
 }
 
 // Or returns value if set, or given parameter if does not.
-func (o OptDateTime) Or(d time.Time) time.Time {
+func (o OptInt) Or(d int) int {
 	if v, ok := o.Get(); ok {
 		return v
 	}
 	return d
 }
 
-// NewOptErrorDetails returns new OptErrorDetails with value set to v.
-func NewOptErrorDetails(v ErrorDetails) OptErrorDetails {
-	return OptErrorDetails{
+// NewOptJobState returns new OptJobState with value set to v.
+func NewOptJobState(v JobState) OptJobState {
+	return OptJobState{
 		Value: v,
 		Set:   true,
 	}
 }
 
-// OptErrorDetails is optional ErrorDetails.
-type OptErrorDetails struct {
-	Value ErrorDetails
+// OptJobState is optional JobState.
+type OptJobState struct {
+	Value JobState
 	Set   bool
 }
 
-// IsSet returns true if OptErrorDetails was set.
-func (o OptErrorDetails) IsSet() bool { return o.Set }
+// IsSet returns true if OptJobState was set.
+func (o OptJobState) IsSet() bool { return o.Set }
 
 // Reset unsets value.
-func (o *OptErrorDetails) Reset() {
-	var v ErrorDetails
+func (o *OptJobState) Reset() {
+	var v JobState
 	o.Value = v
 	o.Set = false
 }
 
 // SetTo sets value to v.
-func (o *OptErrorDetails) SetTo(v ErrorDetails) {
+func (o *OptJobState) SetTo(v JobState) {
 	o.Set = true
 	o.Value = v
 }
 
 // Get returns value and boolean that denotes whether value was set.
-func (o OptErrorDetails) Get() (v ErrorDetails, ok bool) {
+func (o OptJobState) Get() (v JobState, ok bool) {
 	if !o.Set {
 		return v, false
 	}
@@ -266,45 +1384,45 @@ func (o OptErrorDetails) Get() (v ErrorDetails, ok bool) {
 }
 
 // Or returns value if set, or given parameter if does not.
-func (o OptErrorDetails) Or(d ErrorDetails) ErrorDetails {
+func (o OptJobState) Or(d JobState) JobState {
 	if v, ok := o.Get(); ok {
 		return v
 	}
 	return d
 }
 
-// NewOptInt returns new OptInt with value set to v.
-func NewOptInt(v int) OptInt {
-	return OptInt{
+// NewOptListScansDirection returns new OptListScansDirection with value set to v.
+func NewOptListScansDirection(v ListScansDirection) OptListScansDirection {
+	return OptListScansDirection{
 		Value: v,
 		Set:   true,
 	}
 }
 
-// OptInt is optional int.
-type OptInt struct {
-	Value int
+// OptListScansDirection is optional ListScansDirection.
+type OptListScansDirection struct {
+	Value ListScansDirection
 	Set   bool
 }
 
-// IsSet returns true if OptInt was set.
-func (o OptInt) IsSet() bool { return o.Set }
+// IsSet returns true if OptListScansDirection was set.
+func (o OptListScansDirection) IsSet() bool { return o.Set }
 
 // Reset unsets value.
-func (o *OptInt) Reset() {
-	var v int
+func (o *OptListScansDirection) Reset() {
+	var v ListScansDirection
 	o.Value = v
 	o.Set = false
 }
 
 // SetTo sets value to v.
-func (o *OptInt) SetTo(v int) {
+func (o *OptListScansDirection) SetTo(v ListScansDirection) {
 	o.Set = true
 	o.Value = v
 }
 
 // Get returns value and boolean that denotes whether value was set.
-func (o OptInt) Get() (v int, ok bool) {
+func (o OptListScansDirection) Get() (v ListScansDirection, ok bool) {
 	if !o.Set {
 		return v, false
 	}
@@ -312,7 +1430,7 @@ func (o OptInt) Get() (v int, ok bool) {
 }
 
 // Or returns value if set, or given parameter if does not.
-func (o OptInt) Or(d int) int {
+func (o OptListScansDirection) Or(d ListScansDirection) ListScansDirection {
 	if v, ok := o.Get(); ok {
 		return v
 	}
@@ -520,6 +1638,105 @@ func (o OptURI) Or(d url.URL) url.URL {
 	return d
 }
 
+// NewOptUUID returns new OptUUID with value set to v.
+func NewOptUUID(v uuid.UUID) OptUUID {
+	return OptUUID{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptUUID is optional uuid.UUID.
+type OptUUID struct {
+	Value uuid.UUID
+	Set   bool
+}
+
+// IsSet returns true if OptUUID was set.
+func (o OptUUID) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptUUID) Reset() {
+	var v uuid.UUID
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptUUID) SetTo(v uuid.UUID) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptUUID) Get() (v uuid.UUID, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptUUID) Or(d uuid.UUID) uuid.UUID {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// Ref: #/components/schemas/RateLimitStatus
+type RateLimitStatus struct {
+	Limit     int         `json:"limit"`
+	Remaining int         `json:"remaining"`
+	ResetAt   OptDateTime `json:"resetAt"`
+}
+
+// GetLimit returns the value of Limit.
+func (s *RateLimitStatus) GetLimit() int {
+	return s.Limit
+}
+
+// GetRemaining returns the value of Remaining.
+func (s *RateLimitStatus) GetRemaining() int {
+	return s.Remaining
+}
+
+// GetResetAt returns the value of ResetAt.
+func (s *RateLimitStatus) GetResetAt() OptDateTime {
+	return s.ResetAt
+}
+
+// SetLimit sets the value of Limit.
+func (s *RateLimitStatus) SetLimit(val int) {
+	s.Limit = val
+}
+
+// SetRemaining sets the value of Remaining.
+func (s *RateLimitStatus) SetRemaining(val int) {
+	s.Remaining = val
+}
+
+// SetResetAt sets the value of ResetAt.
+func (s *RateLimitStatus) SetResetAt(val OptDateTime) {
+	s.ResetAt = val
+}
+
+type RequeueScanConflict Error
+
+func (*RequeueScanConflict) requeueScanRes() {}
+
+type RequeueScanForbidden Error
+
+func (*RequeueScanForbidden) requeueScanRes() {}
+
+type RequeueScanNotFound Error
+
+func (*RequeueScanNotFound) requeueScanRes() {}
+
+type RequeueScanUnauthorized Error
+
+func (*RequeueScanUnauthorized) requeueScanRes() {}
+
 // Ref: #/components/schemas/Scan
 type Scan struct {
 	ID        uuid.UUID   `json:"id"`
@@ -529,6 +1746,9 @@ type Scan struct {
 	Attempts  int         `json:"attempts"`
 	CreatedAt time.Time   `json:"createdAt"`
 	UpdatedAt OptDateTime `json:"updatedAt"`
+	// When the scan job runs next. Only set by `getScan` for pending scans whose job waits for its next
+	// run.
+	NextRetryAt OptDateTime `json:"nextRetryAt"`
 }
 
 // GetID returns the value of ID.
@@ -566,6 +1786,11 @@ func (s *Scan) GetUpdatedAt() OptDateTime {
 	return s.UpdatedAt
 }
 
+// GetNextRetryAt returns the value of NextRetryAt.
+func (s *Scan) GetNextRetryAt() OptDateTime {
+	return s.NextRetryAt
+}
+
 // SetID sets the value of ID.
 func (s *Scan) SetID(val uuid.UUID) {
 	s.ID = val
@@ -601,13 +1826,120 @@ func (s *Scan) SetUpdatedAt(val OptDateTime) {
 	s.UpdatedAt = val
 }
 
-func (*Scan) createScanRes() {}
-func (*Scan) getScanRes()    {}
+// SetNextRetryAt sets the value of NextRetryAt.
+func (s *Scan) SetNextRetryAt(val OptDateTime) {
+	s.NextRetryAt = val
+}
+
+func (*Scan) createScanRes()    {}
+func (*Scan) forceFailScanRes() {}
+func (*Scan) getLatestScanRes() {}
+func (*Scan) requeueScanRes()   {}
+
+// Ref: #/components/schemas/ScanAttempt
+type ScanAttempt struct {
+	ID int64 `json:"id"`
+	// Error the attempt failed with; absent when it succeeded.
+	Error     OptString       `json:"error"`
+	RateLimit RateLimitStatus `json:"rateLimit"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// GetID returns the value of ID.
+func (s *ScanAttempt) GetID() int64 {
+	return s.ID
+}
+
+// GetError returns the value of Error.
+func (s *ScanAttempt) GetError() OptString {
+	return s.Error
+}
+
+// GetRateLimit returns the value of RateLimit.
+func (s *ScanAttempt) GetRateLimit() RateLimitStatus {
+	return s.RateLimit
+}
+
+// GetCreatedAt returns the value of CreatedAt.
+func (s *ScanAttempt) GetCreatedAt() time.Time {
+	return s.CreatedAt
+}
+
+// SetID sets the value of ID.
+func (s *ScanAttempt) SetID(val int64) {
+	s.ID = val
+}
+
+// SetError sets the value of Error.
+func (s *ScanAttempt) SetError(val OptString) {
+	s.Error = val
+}
+
+// SetRateLimit sets the value of RateLimit.
+func (s *ScanAttempt) SetRateLimit(val RateLimitStatus) {
+	s.RateLimit = val
+}
+
+// SetCreatedAt sets the value of CreatedAt.
+func (s *ScanAttempt) SetCreatedAt(val time.Time) {
+	s.CreatedAt = val
+}
+
+// Ref: #/components/schemas/ScanAttemptList
+type ScanAttemptList struct {
+	Items []ScanAttempt `json:"items"`
+}
+
+// GetItems returns the value of Items.
+func (s *ScanAttemptList) GetItems() []ScanAttempt {
+	return s.Items
+}
+
+// SetItems sets the value of Items.
+func (s *ScanAttemptList) SetItems(val []ScanAttempt) {
+	s.Items = val
+}
+
+func (*ScanAttemptList) listScanAttemptsRes() {}
+
+// ScanHeaders wraps Scan with response headers.
+type ScanHeaders struct {
+	ETag     OptString
+	Response Scan
+}
+
+// GetETag returns the value of ETag.
+func (s *ScanHeaders) GetETag() OptString {
+	return s.ETag
+}
+
+// GetResponse returns the value of Response.
+func (s *ScanHeaders) GetResponse() Scan {
+	return s.Response
+}
+
+// SetETag sets the value of ETag.
+func (s *ScanHeaders) SetETag(val OptString) {
+	s.ETag = val
+}
+
+// SetResponse sets the value of Response.
+func (s *ScanHeaders) SetResponse(val Scan) {
+	s.Response = val
+}
+
+func (*ScanHeaders) getScanRes() {}
 
 // Ref: #/components/schemas/ScanList
 type ScanList struct {
 	Items      []Scan       `json:"items"`
 	NextCursor OptNilString `json:"next_cursor"`
+	// Cursor of the preceding page, to be requested with `direction=prev`. Only set by `listScans`.
+	PrevCursor OptNilString `json:"prev_cursor"`
+	// Whether another page can be requested with next_cursor.
+	HasMore bool `json:"has_more"`
+	// Page size used for this page, i.e. the requested limit clamped to the maximum.
+	PageSize int `json:"page_size"`
 }
 
 // GetItems returns the value of Items.
@@ -620,6 +1952,21 @@ func (s *ScanList) GetNextCursor() OptNilString {
 	return s.NextCursor
 }
 
+// GetPrevCursor returns the value of PrevCursor.
+func (s *ScanList) GetPrevCursor() OptNilString {
+	return s.PrevCursor
+}
+
+// GetHasMore returns the value of HasMore.
+func (s *ScanList) GetHasMore() bool {
+	return s.HasMore
+}
+
+// GetPageSize returns the value of PageSize.
+func (s *ScanList) GetPageSize() int {
+	return s.PageSize
+}
+
 // SetItems sets the value of Items.
 func (s *ScanList) SetItems(val []Scan) {
 	s.Items = val
@@ -630,7 +1977,24 @@ func (s *ScanList) SetNextCursor(val OptNilString) {
 	s.NextCursor = val
 }
 
-func (*ScanList) listScansRes() {}
+// SetPrevCursor sets the value of PrevCursor.
+func (s *ScanList) SetPrevCursor(val OptNilString) {
+	s.PrevCursor = val
+}
+
+// SetHasMore sets the value of HasMore.
+func (s *ScanList) SetHasMore(val bool) {
+	s.HasMore = val
+}
+
+// SetPageSize sets the value of PageSize.
+func (s *ScanList) SetPageSize(val int) {
+	s.PageSize = val
+}
+
+func (*ScanList) listAdminScansRes() {}
+func (*ScanList) listScansRes()      {}
+func (*ScanList) searchScansRes()    {}
 
 // Ref: #/components/schemas/ScanResult
 type ScanResult struct {
@@ -755,6 +2119,10 @@ func (s *ScanResultStats) SetMalicious(val OptInt) {
 type ScanResultVerdicts struct {
 	Malicious OptBool `json:"malicious"`
 	Score     OptInt  `json:"score"`
+	// Verdict categories reported by the provider, e.g. phishing or malware.
+	Categories []string `json:"categories"`
+	// Names of the brands targeted by the page, e.g. by a phishing attempt.
+	Brands []string `json:"brands"`
 }
 
 // GetMalicious returns the value of Malicious.
@@ -767,6 +2135,16 @@ func (s *ScanResultVerdicts) GetScore() OptInt {
 	return s.Score
 }
 
+// GetCategories returns the value of Categories.
+func (s *ScanResultVerdicts) GetCategories() []string {
+	return s.Categories
+}
+
+// GetBrands returns the value of Brands.
+func (s *ScanResultVerdicts) GetBrands() []string {
+	return s.Brands
+}
+
 // SetMalicious sets the value of Malicious.
 func (s *ScanResultVerdicts) SetMalicious(val OptBool) {
 	s.Malicious = val
@@ -777,6 +2155,17 @@ func (s *ScanResultVerdicts) SetScore(val OptInt) {
 	s.Score = val
 }
 
+// SetCategories sets the value of Categories.
+func (s *ScanResultVerdicts) SetCategories(val []string) {
+	s.Categories = val
+}
+
+// SetBrands sets the value of Brands.
+func (s *ScanResultVerdicts) SetBrands(val []string) {
+	s.Brands = val
+}
+
+// `CANCELED` is recorded for scans deleted by their user while pending.
 // Ref: #/components/schemas/ScanStatus
 type ScanStatus string
 
@@ -784,6 +2173,7 @@ const (
 	ScanStatusPENDING   ScanStatus = "PENDING"
 	ScanStatusCOMPLETED ScanStatus = "COMPLETED"
 	ScanStatusFAILED    ScanStatus = "FAILED"
+	ScanStatusCANCELED  ScanStatus = "CANCELED"
 )
 
 // AllValues returns all ScanStatus values.
@@ -792,6 +2182,7 @@ func (ScanStatus) AllValues() []ScanStatus {
 		ScanStatusPENDING,
 		ScanStatusCOMPLETED,
 		ScanStatusFAILED,
+		ScanStatusCANCELED,
 	}
 }
 
@@ -804,6 +2195,8 @@ func (s ScanStatus) MarshalText() ([]byte, error) {
 		return []byte(s), nil
 	case ScanStatusFAILED:
 		return []byte(s), nil
+	case ScanStatusCANCELED:
+		return []byte(s), nil
 	default:
 		return nil, errors.Errorf("invalid value: %q", s)
 	}
@@ -821,11 +2214,114 @@ func (s *ScanStatus) UnmarshalText(data []byte) error {
 	case ScanStatusFAILED:
 		*s = ScanStatusFAILED
 		return nil
+	case ScanStatusCANCELED:
+		*s = ScanStatusCANCELED
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
+// Ref: #/components/schemas/ScanSummary
+type ScanSummary struct {
+	// Number of scans keyed by scan status; every status is listed.
+	Counts ScanSummaryCounts `json:"counts"`
+}
+
+// GetCounts returns the value of Counts.
+func (s *ScanSummary) GetCounts() ScanSummaryCounts {
+	return s.Counts
+}
+
+// SetCounts sets the value of Counts.
+func (s *ScanSummary) SetCounts(val ScanSummaryCounts) {
+	s.Counts = val
+}
+
+func (*ScanSummary) getScanSummaryRes() {}
+
+// Number of scans keyed by scan status; every status is listed.
+type ScanSummaryCounts map[string]int64
+
+func (s *ScanSummaryCounts) init() ScanSummaryCounts {
+	m := *s
+	if m == nil {
+		m = map[string]int64{}
+		*s = m
+	}
+	return m
+}
+
+type SearchScansBadRequest Error
+
+func (*SearchScansBadRequest) searchScansRes() {}
+
+type SearchScansKey string
+
+const (
+	SearchScansKeyIP      SearchScansKey = "ip"
+	SearchScansKeyAsn     SearchScansKey = "asn"
+	SearchScansKeyServer  SearchScansKey = "server"
+	SearchScansKeyDomain  SearchScansKey = "domain"
+	SearchScansKeyCountry SearchScansKey = "country"
+)
+
+// AllValues returns all SearchScansKey values.
+func (SearchScansKey) AllValues() []SearchScansKey {
+	return []SearchScansKey{
+		SearchScansKeyIP,
+		SearchScansKeyAsn,
+		SearchScansKeyServer,
+		SearchScansKeyDomain,
+		SearchScansKeyCountry,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s SearchScansKey) MarshalText() ([]byte, error) {
+	switch s {
+	case SearchScansKeyIP:
+		return []byte(s), nil
+	case SearchScansKeyAsn:
+		return []byte(s), nil
+	case SearchScansKeyServer:
+		return []byte(s), nil
+	case SearchScansKeyDomain:
+		return []byte(s), nil
+	case SearchScansKeyCountry:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *SearchScansKey) UnmarshalText(data []byte) error {
+	switch SearchScansKey(data) {
+	case SearchScansKeyIP:
+		*s = SearchScansKeyIP
+		return nil
+	case SearchScansKeyAsn:
+		*s = SearchScansKeyAsn
+		return nil
+	case SearchScansKeyServer:
+		*s = SearchScansKeyServer
+		return nil
+	case SearchScansKeyDomain:
+		*s = SearchScansKeyDomain
+		return nil
+	case SearchScansKeyCountry:
+		*s = SearchScansKeyCountry
+		return nil
 	default:
 		return errors.Errorf("invalid value: %q", data)
 	}
 }
 
+type SearchScansUnauthorized Error
+
+func (*SearchScansUnauthorized) searchScansRes() {}
+
 // ServerErrorStatusCode wraps Error with StatusCode.
 type ServerErrorStatusCode struct {
 	StatusCode int
@@ -852,7 +2348,19 @@ func (s *ServerErrorStatusCode) SetResponse(val Error) {
 	s.Response = val
 }
 
-func (*ServerErrorStatusCode) createScanRes() {}
-func (*ServerErrorStatusCode) deleteScanRes() {}
-func (*ServerErrorStatusCode) getScanRes()    {}
-func (*ServerErrorStatusCode) listScansRes()  {}
+func (*ServerErrorStatusCode) batchGetScansRes()     {}
+func (*ServerErrorStatusCode) createScanRes()        {}
+func (*ServerErrorStatusCode) createScanSyncRes()    {}
+func (*ServerErrorStatusCode) deleteScanRes()        {}
+func (*ServerErrorStatusCode) exportScansRes()       {}
+func (*ServerErrorStatusCode) forceFailScanRes()     {}
+func (*ServerErrorStatusCode) getLatestScanRes()     {}
+func (*ServerErrorStatusCode) getScanRes()           {}
+func (*ServerErrorStatusCode) getScanSummaryRes()    {}
+func (*ServerErrorStatusCode) listAdminAuditLogRes() {}
+func (*ServerErrorStatusCode) listAdminJobsRes()     {}
+func (*ServerErrorStatusCode) listAdminScansRes()    {}
+func (*ServerErrorStatusCode) listScanAttemptsRes()  {}
+func (*ServerErrorStatusCode) listScansRes()         {}
+func (*ServerErrorStatusCode) requeueScanRes()       {}
+func (*ServerErrorStatusCode) searchScansRes()       {}
@@ -34,10 +34,22 @@ func findAuthorization(h http.Header, prefix string) (string, bool) {
 }
 
 var operationRolesBearerAuth = map[string][]string{
-	CreateScanOperation: []string{},
-	DeleteScanOperation: []string{},
-	GetScanOperation:    []string{},
-	ListScansOperation:  []string{},
+	BatchGetScansOperation:     []string{},
+	CreateScanOperation:        []string{},
+	CreateScanSyncOperation:    []string{},
+	DeleteScanOperation:        []string{},
+	ExportScansOperation:       []string{},
+	ForceFailScanOperation:     []string{},
+	GetLatestScanOperation:     []string{},
+	GetScanOperation:           []string{},
+	GetScanSummaryOperation:    []string{},
+	ListAdminAuditLogOperation: []string{},
+	ListAdminJobsOperation:     []string{},
+	ListAdminScansOperation:    []string{},
+	ListScanAttemptsOperation:  []string{},
+	ListScansOperation:         []string{},
+	RequeueScanOperation:       []string{},
+	SearchScansOperation:       []string{},
 }
 
 func (s *Server) securityBearerAuth(ctx context.Context, operationName OperationName, req *http.Request) (context.Context, bool, error) {
@@ -8,31 +8,116 @@ import (
 
 // Handler handles operations described by OpenAPI v3 specification.
 type Handler interface {
+	// BatchGetScans implements batchGetScans operation.
+	//
+	// Returns the caller's scans with the given IDs in the requested order. IDs that do not match a scan
+	// of the caller are listed in `notFound`.
+	//
+	// POST /scans:batchGet
+	BatchGetScans(ctx context.Context, req *BatchGetScansRequest) (BatchGetScansRes, error)
 	// CreateScan implements createScan operation.
 	//
 	// Starts an asynchronous scan for the given page URL. Returns a scan resource with status `PENDING`.
 	//
 	// POST /scans
-	CreateScan(ctx context.Context, req *CreateScanRequest) (CreateScanRes, error)
+	CreateScan(ctx context.Context, req *CreateScanRequest, params CreateScanParams) (CreateScanRes, error)
+	// CreateScanSync implements createScanSync operation.
+	//
+	// Starts a scan like `POST /scans` and waits for it to reach a terminal state. Returns `200` with
+	// the finished scan, or `202` with the pending scan if it does not finish within `timeout`.
+	//
+	// POST /scans:sync
+	CreateScanSync(ctx context.Context, req *CreateScanRequest, params CreateScanSyncParams) (CreateScanSyncRes, error)
 	// DeleteScan implements deleteScan operation.
 	//
 	// Delete a scan.
 	//
 	// DELETE /scans/{id}
 	DeleteScan(ctx context.Context, params DeleteScanParams) (DeleteScanRes, error)
+	// ExportScans implements exportScans operation.
+	//
+	// Streams every scan owned by the caller, newest first, either as newline-delimited JSON with one
+	// `Scan` object per line, or as CSV with the columns id, url, status, malicious, score, created_at
+	// and updated_at. Result fields a scan does not have are left empty.
+	//
+	// GET /scans:export
+	ExportScans(ctx context.Context, params ExportScansParams) (ExportScansRes, error)
+	// ForceFailScan implements forceFailScan operation.
+	//
+	// Marks a pending scan of any user as `FAILED` with the given reason. Returns `409` when the scan is
+	// no longer pending. Requires a token with the `admin` role.
+	//
+	// POST /admin/scans/{id}/fail
+	ForceFailScan(ctx context.Context, req *ForceFailScanRequest, params ForceFailScanParams) (ForceFailScanRes, error)
+	// GetLatestScan implements getLatestScan operation.
+	//
+	// Returns the most recent scan of the given URL owned by the caller. The URL is normalized before
+	// lookup.
+	//
+	// GET /scans:latest
+	GetLatestScan(ctx context.Context, params GetLatestScanParams) (GetLatestScanRes, error)
 	// GetScan implements getScan operation.
 	//
 	// Get a single scan.
 	//
 	// GET /scans/{id}
 	GetScan(ctx context.Context, params GetScanParams) (GetScanRes, error)
+	// GetScanSummary implements getScanSummary operation.
+	//
+	// Returns the number of scans owned by the caller for every scan status, excluding deleted scans.
+	//
+	// GET /scans:summary
+	GetScanSummary(ctx context.Context) (GetScanSummaryRes, error)
+	// ListAdminAuditLog implements listAdminAuditLog operation.
+	//
+	// Returns the recorded scan creations and deletions of all users, newest first. Requires a token
+	// with the `admin` role. Use `cursor` and `limit` for pagination.
+	//
+	// GET /admin/audit
+	ListAdminAuditLog(ctx context.Context, params ListAdminAuditLogParams) (ListAdminAuditLogRes, error)
+	// ListAdminJobs implements listAdminJobs operation.
+	//
+	// Returns background jobs from the queue, newest first. Requires a token with the `admin` role. Use
+	// `cursor` and `limit` for pagination.
+	//
+	// GET /admin/jobs
+	ListAdminJobs(ctx context.Context, params ListAdminJobsParams) (ListAdminJobsRes, error)
+	// ListAdminScans implements listAdminScans operation.
+	//
+	// Returns scans across all users, newest first. Requires a token with the `admin` role claim.
+	// Supports the same cursor pagination as `listScans`.
+	//
+	// GET /admin/scans
+	ListAdminScans(ctx context.Context, params ListAdminScansParams) (ListAdminScansRes, error)
+	// ListScanAttempts implements listScanAttempts operation.
+	//
+	// Returns the timeline of attempts made to process the scan, oldest first, with the error of every
+	// failed attempt and the upstream rate-limit status it reported.
+	//
+	// GET /scans/{id}/attempts
+	ListScanAttempts(ctx context.Context, params ListScanAttemptsParams) (ListScanAttemptsRes, error)
 	// ListScans implements listScans operation.
 	//
-	// Returns scans owned by the caller. Use `cursor` and `limit` for pagination. The response includes
-	// `next_cursor` when more pages exist.
+	// Returns scans owned by the caller, newest first. Use `cursor` and `limit` for pagination. The
+	// response includes `next_cursor` when more pages exist, and `prev_cursor` when newer pages exist;
+	// pass the latter with `direction=prev` to page back.
 	//
 	// GET /scans
 	ListScans(ctx context.Context, params ListScansParams) (ListScansRes, error)
+	// RequeueScan implements requeueScan operation.
+	//
+	// Enqueues a new job for a pending scan of any user whose job was lost. Returns `409` when the scan
+	// is no longer pending or a job for its URL is still queued. Requires a token with the `admin` role.
+	//
+	// POST /admin/scans/{id}/requeue
+	RequeueScan(ctx context.Context, params RequeueScanParams) (RequeueScanRes, error)
+	// SearchScans implements searchScans operation.
+	//
+	// Returns scans owned by the caller whose scanned page has the given value for `key`, e.g.
+	// `key=ip&value=1.2.3.4` or `key=asn&value=AS15133`. Pagination works the same as in listScans.
+	//
+	// GET /scans:search
+	SearchScans(ctx context.Context, params SearchScansParams) (SearchScansRes, error)
 	// NewError creates *ServerErrorStatusCode from error returned by handler.
 	//
 	// Used for common default response.
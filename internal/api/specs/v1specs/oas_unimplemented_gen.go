@@ -13,12 +13,32 @@ type UnimplementedHandler struct{}
 
 var _ Handler = UnimplementedHandler{}
 
+// BatchGetScans implements batchGetScans operation.
+//
+// Returns the caller's scans with the given IDs in the requested order. IDs that do not match a scan
+// of the caller are listed in `notFound`.
+//
+// POST /scans:batchGet
+func (UnimplementedHandler) BatchGetScans(ctx context.Context, req *BatchGetScansRequest) (r BatchGetScansRes, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
 // CreateScan implements createScan operation.
 //
 // Starts an asynchronous scan for the given page URL. Returns a scan resource with status `PENDING`.
 //
 // POST /scans
-func (UnimplementedHandler) CreateScan(ctx context.Context, req *CreateScanRequest) (r CreateScanRes, _ error) {
+func (UnimplementedHandler) CreateScan(ctx context.Context, req *CreateScanRequest, params CreateScanParams) (r CreateScanRes, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// CreateScanSync implements createScanSync operation.
+//
+// Starts a scan like `POST /scans` and waits for it to reach a terminal state. Returns `200` with
+// the finished scan, or `202` with the pending scan if it does not finish within `timeout`.
+//
+// POST /scans:sync
+func (UnimplementedHandler) CreateScanSync(ctx context.Context, req *CreateScanRequest, params CreateScanSyncParams) (r CreateScanSyncRes, _ error) {
 	return r, ht.ErrNotImplemented
 }
 
@@ -31,6 +51,37 @@ func (UnimplementedHandler) DeleteScan(ctx context.Context, params DeleteScanPar
 	return r, ht.ErrNotImplemented
 }
 
+// ExportScans implements exportScans operation.
+//
+// Streams every scan owned by the caller, newest first, either as newline-delimited JSON with one
+// `Scan` object per line, or as CSV with the columns id, url, status, malicious, score, created_at
+// and updated_at. Result fields a scan does not have are left empty.
+//
+// GET /scans:export
+func (UnimplementedHandler) ExportScans(ctx context.Context, params ExportScansParams) (r ExportScansRes, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// ForceFailScan implements forceFailScan operation.
+//
+// Marks a pending scan of any user as `FAILED` with the given reason. Returns `409` when the scan is
+// no longer pending. Requires a token with the `admin` role.
+//
+// POST /admin/scans/{id}/fail
+func (UnimplementedHandler) ForceFailScan(ctx context.Context, req *ForceFailScanRequest, params ForceFailScanParams) (r ForceFailScanRes, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// GetLatestScan implements getLatestScan operation.
+//
+// Returns the most recent scan of the given URL owned by the caller. The URL is normalized before
+// lookup.
+//
+// GET /scans:latest
+func (UnimplementedHandler) GetLatestScan(ctx context.Context, params GetLatestScanParams) (r GetLatestScanRes, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
 // GetScan implements getScan operation.
 //
 // Get a single scan.
@@ -40,16 +91,86 @@ func (UnimplementedHandler) GetScan(ctx context.Context, params GetScanParams) (
 	return r, ht.ErrNotImplemented
 }
 
+// GetScanSummary implements getScanSummary operation.
+//
+// Returns the number of scans owned by the caller for every scan status, excluding deleted scans.
+//
+// GET /scans:summary
+func (UnimplementedHandler) GetScanSummary(ctx context.Context) (r GetScanSummaryRes, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// ListAdminAuditLog implements listAdminAuditLog operation.
+//
+// Returns the recorded scan creations and deletions of all users, newest first. Requires a token
+// with the `admin` role. Use `cursor` and `limit` for pagination.
+//
+// GET /admin/audit
+func (UnimplementedHandler) ListAdminAuditLog(ctx context.Context, params ListAdminAuditLogParams) (r ListAdminAuditLogRes, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// ListAdminJobs implements listAdminJobs operation.
+//
+// Returns background jobs from the queue, newest first. Requires a token with the `admin` role. Use
+// `cursor` and `limit` for pagination.
+//
+// GET /admin/jobs
+func (UnimplementedHandler) ListAdminJobs(ctx context.Context, params ListAdminJobsParams) (r ListAdminJobsRes, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// ListAdminScans implements listAdminScans operation.
+//
+// Returns scans across all users, newest first. Requires a token with the `admin` role claim.
+// Supports the same cursor pagination as `listScans`.
+//
+// GET /admin/scans
+func (UnimplementedHandler) ListAdminScans(ctx context.Context, params ListAdminScansParams) (r ListAdminScansRes, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// ListScanAttempts implements listScanAttempts operation.
+//
+// Returns the timeline of attempts made to process the scan, oldest first, with the error of every
+// failed attempt and the upstream rate-limit status it reported.
+//
+// GET /scans/{id}/attempts
+func (UnimplementedHandler) ListScanAttempts(ctx context.Context, params ListScanAttemptsParams) (r ListScanAttemptsRes, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
 // ListScans implements listScans operation.
 //
-// Returns scans owned by the caller. Use `cursor` and `limit` for pagination. The response includes
-// `next_cursor` when more pages exist.
+// Returns scans owned by the caller, newest first. Use `cursor` and `limit` for pagination. The
+// response includes `next_cursor` when more pages exist, and `prev_cursor` when newer pages exist;
+// pass the latter with `direction=prev` to page back.
 //
 // GET /scans
 func (UnimplementedHandler) ListScans(ctx context.Context, params ListScansParams) (r ListScansRes, _ error) {
 	return r, ht.ErrNotImplemented
 }
 
+// RequeueScan implements requeueScan operation.
+//
+// Enqueues a new job for a pending scan of any user whose job was lost. Returns `409` when the scan
+// is no longer pending or a job for its URL is still queued. Requires a token with the `admin` role.
+//
+// POST /admin/scans/{id}/requeue
+func (UnimplementedHandler) RequeueScan(ctx context.Context, params RequeueScanParams) (r RequeueScanRes, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
+// SearchScans implements searchScans operation.
+//
+// Returns scans owned by the caller whose scanned page has the given value for `key`, e.g.
+// `key=ip&value=1.2.3.4` or `key=asn&value=AS15133`. Pagination works the same as in listScans.
+//
+// GET /scans:search
+func (UnimplementedHandler) SearchScans(ctx context.Context, params SearchScansParams) (r SearchScansRes, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
 // NewError creates *ServerErrorStatusCode from error returned by handler.
 //
 // Used for common default response.
@@ -1,7 +1,11 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"scanner/pkg/urlscanner"
+	"strings"
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
@@ -13,6 +17,8 @@ import (
 type Config struct {
 	// Environment specifies the current running environment (development, production, etc.)
 	Environment string `env:"ENVIRONMENT" env-default:"development" yaml:"environment"`
+	// LogLevel overrides the environment's default log level, e.g. "debug" or "warn"
+	LogLevel string `env:"LOG_LEVEL" yaml:"logLevel"`
 
 	// HTTP contains all HTTP server related configurations
 	HTTP struct {
@@ -30,8 +36,36 @@ type Config struct {
 		RequestTimeout time.Duration `env:"HTTP_REQUEST_TIMEOUT" env-default:"10s" yaml:"requestTimeout"`
 		// MaxHeaderBytes controls the maximum number of bytes the server will read parsing the request header
 		MaxHeaderBytes int `env:"HTTP_MAX_HEADER_BYTES" env-default:"0" yaml:"maxHeaderBytes"`
+		// MaxBodyBytes is the maximum size of a request body; larger requests are rejected with 413 (0 disables the limit)
+		MaxBodyBytes int64 `env:"HTTP_MAX_BODY_BYTES" env-default:"1048576" yaml:"maxBodyBytes"`
+		// DefaultRetryAfter is the Retry-After sent with 429 and 503 responses that carry no retry hint (0 omits it)
+		DefaultRetryAfter time.Duration `env:"HTTP_DEFAULT_RETRY_AFTER" env-default:"5s" yaml:"defaultRetryAfter"`
+		// MaxPageLimit is the maximum page size of list endpoints; larger requested limits are clamped
+		MaxPageLimit int `env:"HTTP_MAX_PAGE_LIMIT" env-default:"100" yaml:"maxPageLimit"`
+		// MaxSyncScanTimeout is the longest POST /v1/scans:sync waits for a scan; larger requested timeouts are clamped
+		MaxSyncScanTimeout time.Duration `env:"HTTP_MAX_SYNC_SCAN_TIMEOUT" env-default:"30s" yaml:"maxSyncScanTimeout"`
 		// MetricsPath defines the URL path where metrics are exposed
 		MetricsPath string `env:"HTTP_METRICS_PATH" env-default:"/metrics" yaml:"metricsPath"`
+		// MetricsBearerToken requires this bearer token to scrape metrics (empty disables it)
+		MetricsBearerToken string `env:"HTTP_METRICS_BEARER_TOKEN" yaml:"metricsBearerToken"`
+		// MetricsUsername requires basic auth with this user name to scrape metrics (empty disables it)
+		MetricsUsername string `env:"HTTP_METRICS_USERNAME" yaml:"metricsUsername"`
+		// MetricsPassword is the basic auth password required along with MetricsUsername
+		MetricsPassword string `env:"HTTP_METRICS_PASSWORD" yaml:"metricsPassword"`
+		// AccessLogSampleRate logs one in every N successful fast requests (<= 1 logs all of them)
+		AccessLogSampleRate int `env:"HTTP_ACCESS_LOG_SAMPLE_RATE" env-default:"1" yaml:"accessLogSampleRate"`
+		// SlowRequestThreshold is the latency from which requests are always logged at warn level (0 disables it)
+		SlowRequestThreshold time.Duration `env:"HTTP_SLOW_REQUEST_THRESHOLD" env-default:"1s" yaml:"slowRequestThreshold"`
+		// LogLevelEndpoint exposes /debug/loglevel to read and change the log level at runtime
+		LogLevelEndpoint bool `env:"HTTP_LOG_LEVEL_ENDPOINT" env-default:"false" yaml:"logLevelEndpoint"`
+		// CORSAllowedOrigins lists origins allowed to make cross-origin requests; "*" allows any origin
+		CORSAllowedOrigins []string `env:"HTTP_CORS_ALLOWED_ORIGINS" env-default:"*" yaml:"corsAllowedOrigins"`
+		// CORSAllowedMethods lists methods allowed for cross-origin requests
+		CORSAllowedMethods []string `env:"HTTP_CORS_ALLOWED_METHODS" env-default:"GET,POST,PUT,PATCH,DELETE,OPTIONS" yaml:"corsAllowedMethods"`
+		// CORSAllowedHeaders lists request headers allowed for cross-origin requests
+		CORSAllowedHeaders []string `env:"HTTP_CORS_ALLOWED_HEADERS" env-default:"Accept,Authorization,Cache-Control,Content-Type,Idempotency-Key,If-None-Match" yaml:"corsAllowedHeaders"`
+		// CORSAllowCredentials allows cross-origin requests to include credentials
+		CORSAllowCredentials bool `env:"HTTP_CORS_ALLOW_CREDENTIALS" env-default:"false" yaml:"corsAllowCredentials"`
 	} `yaml:"http"`
 
 	// Database contains all database connection related configurations
@@ -56,6 +90,10 @@ type Config struct {
 		ConnMaxLifetime time.Duration `env:"DATABASE_CONNECTION_MAX_LIFETIME" env-default:"3m" yaml:"connMaxLifetime"`
 		// ConnMaxIdleTime is the maximum amount of time a connection may be idle
 		ConnMaxIdleTime time.Duration `env:"DATABASE_CONNECTION_MAX_IDLE_TIME" env-default:"3m" yaml:"connMaxIdleTime"`
+		// ReplicaDSN is the connection string of an optional read replica used by read-heavy queries
+		ReplicaDSN string `env:"DATABASE_REPLICA_DSN" yaml:"replicaDsn"`
+		// QueryTimeout is the maximum duration of a single storage query, zero disables it
+		QueryTimeout time.Duration `env:"DATABASE_QUERY_TIMEOUT" env-default:"10s" yaml:"queryTimeout"`
 	} `yaml:"database"`
 
 	// JWT contains keys used for signing and verifying JSON Web Tokens
@@ -72,18 +110,94 @@ type Config struct {
 		MaxAttempts int `env:"SCANNER_MAX_ATTEMPTS" env-default:"5" yaml:"maxAttempts"`
 		// ResultCacheTTL is the duration for which scan results are cached and reused
 		ResultCacheTTL time.Duration `env:"SCANNER_RESULT_CACHE_TTL" env-default:"1h" yaml:"resultCacheTtl"`
+		// ResultBatchInterval polls results of all in-flight scans with one batched request per interval when positive; 0 polls each scan separately
+		ResultBatchInterval time.Duration `env:"SCANNER_RESULT_BATCH_INTERVAL" env-default:"0s" yaml:"resultBatchInterval"`
+		// ResultCacheScope selects whose cached results are reused: global (any user's shareable results) or user (own results only)
+		ResultCacheScope string `env:"SCANNER_RESULT_CACHE_SCOPE" env-default:"global" yaml:"resultCacheScope"`
+		// FailedResultTTL fails new scans of a URL with the error of a scan of it that failed within this duration instead of scanning it again; 0 disables it
+		FailedResultTTL time.Duration `env:"SCANNER_FAILED_RESULT_TTL" env-default:"0s" yaml:"failedResultTtl"`
+		// MaxURLLength is the maximum length of a normalized URL accepted for scanning; 0 disables the check
+		MaxURLLength int `env:"SCANNER_MAX_URL_LENGTH" env-default:"2048" yaml:"maxUrlLength"`
+		// MaxPendingScansPerUser is the maximum number of pending scans a user may have; further submissions are rejected as rate limited. 0 disables the cap
+		MaxPendingScansPerUser int `env:"SCANNER_MAX_PENDING_SCANS_PER_USER" env-default:"0" yaml:"maxPendingScansPerUser"`
+		// PendingScansRetryAfter is the Retry-After sent when a submission exceeds MaxPendingScansPerUser; 0 falls back to the HTTP default
+		PendingScansRetryAfter time.Duration `env:"SCANNER_PENDING_SCANS_RETRY_AFTER" env-default:"30s" yaml:"pendingScansRetryAfter"`
+		// BlockPrivateHosts rejects URLs whose host is or resolves to a loopback, link-local, private or metadata address
+		BlockPrivateHosts bool `env:"SCANNER_BLOCK_PRIVATE_HOSTS" env-default:"true" yaml:"blockPrivateHosts"`
+		// AllowedDomains restricts scanning to these domains when non-empty; "*.example.com" matches subdomains
+		AllowedDomains []string `env:"SCANNER_ALLOWED_DOMAINS" yaml:"allowedDomains"`
+		// DeniedDomains lists domains that can never be scanned; takes precedence over AllowedDomains
+		DeniedDomains []string `env:"SCANNER_DENIED_DOMAINS" yaml:"deniedDomains"`
 		// UrlscanioAPIKey is the API key used to authenticate with urlscan.io
 		UrlscanioAPIKey string `env:"SCANNER_URLSCAN_IO_API_KEY" yaml:"urlscanioApiKey"`
+		// TLSCAFile is the path of a PEM CA bundle trusted for urlscan.io in addition to the system roots
+		TLSCAFile string `env:"SCANNER_TLS_CA_FILE" yaml:"tlsCaFile"`
+		// TLSCertFile is the path of a PEM client certificate presented to urlscan.io for mutual TLS
+		TLSCertFile string `env:"SCANNER_TLS_CERT_FILE" yaml:"tlsCertFile"`
+		// TLSKeyFile is the path of the PEM key of TLSCertFile
+		TLSKeyFile string `env:"SCANNER_TLS_KEY_FILE" yaml:"tlsKeyFile"`
+		// UserAgent is the User-Agent sent to urlscan.io; empty uses url-scanner/<version>
+		UserAgent string `env:"SCANNER_USER_AGENT" yaml:"userAgent"`
+		// Default visibility of scans submitted to urlscan.io: public, unlisted or private; requests may override it and only public results are shared across users
+		Visibility string `env:"SCANNER_VISIBILITY" env-default:"public" yaml:"visibility"`
+		// Country is the ISO 3166-1 alpha-2 code of the country urlscan.io scans from; empty lets urlscan.io choose
+		Country string `env:"SCANNER_COUNTRY" yaml:"country"`
+		// Queue is the worker queue scan jobs are inserted into
+		Queue string `env:"SCANNER_QUEUE" env-default:"default" yaml:"queue"`
+		// Priority is the job priority of scan jobs, from 1 (highest) to 4 (lowest)
+		Priority int `env:"SCANNER_PRIORITY" env-default:"2" yaml:"priority"`
+		// PriorityQueue is the worker queue scan jobs of priority users are inserted into
+		PriorityQueue string `env:"SCANNER_PRIORITY_QUEUE" env-default:"priority" yaml:"priorityQueue"`
+		// PriorityJobPriority is the job priority of scan jobs of priority users
+		PriorityJobPriority int `env:"SCANNER_PRIORITY_JOB_PRIORITY" env-default:"1" yaml:"priorityJobPriority"`
+		// PriorityUserIDs lists the IDs of users (e.g. paid users) whose scans are routed to PriorityQueue
+		PriorityUserIDs []string `env:"SCANNER_PRIORITY_USER_IDS" yaml:"priorityUserIds"`
+		// SlowDomains lists domains that take longer to scan and run with SlowJobTimeout; "*.example.com" matches subdomains
+		SlowDomains []string `env:"SCANNER_SLOW_DOMAINS" yaml:"slowDomains"`
+		// SlowJobTimeout is the job timeout of scans of SlowDomains, overriding the worker's JobTimeout
+		SlowJobTimeout time.Duration `env:"SCANNER_SLOW_JOB_TIMEOUT" env-default:"5m" yaml:"slowJobTimeout"`
+		// ForbidCrossUserAccess returns 403 instead of 404 when a user accesses another user's scan
+		ForbidCrossUserAccess bool `env:"SCANNER_FORBID_CROSS_USER_ACCESS" env-default:"false" yaml:"forbidCrossUserAccess"`
 	} `yaml:"scanner"`
 
 	// Worker contains configuration for background job processing
 	Worker struct {
 		// JobTimeout is the maximum duration allowed for a single job execution
 		JobTimeout time.Duration `env:"WORKER_JOB_TIMEOUT" env-default:"1m" yaml:"jobTimeout"`
-		// JobConcurrency is the number of jobs that can be processed concurrently
+		// JobConcurrency is the number of jobs that can be processed concurrently in the default queue
 		JobConcurrency int `env:"WORKER_JOB_CONCURRENCY" env-default:"10" yaml:"jobConcurrency"`
+		// Queues maps additional queue names to the number of jobs that can be processed concurrently in them
+		Queues map[string]int `env:"WORKER_QUEUES" env-default:"priority:5" yaml:"queues"`
+		// DrainTimeout is how long shutdown waits for running jobs to finish before canceling them
+		DrainTimeout time.Duration `env:"WORKER_DRAIN_TIMEOUT" env-default:"5s" yaml:"drainTimeout"`
+		// FetchCooldown is the minimum time between fetches of new jobs
+		FetchCooldown time.Duration `env:"WORKER_FETCH_COOLDOWN" env-default:"100ms" yaml:"fetchCooldown"`
+		// FetchPollInterval is the time between periodic fetches when no new jobs are notified
+		FetchPollInterval time.Duration `env:"WORKER_FETCH_POLL_INTERVAL" env-default:"1s" yaml:"fetchPollInterval"`
+		// RescueStuckJobsAfter is how long a job may run before it is considered stuck and retried (0 uses River's default)
+		RescueStuckJobsAfter time.Duration `env:"WORKER_RESCUE_STUCK_JOBS_AFTER" env-default:"0" yaml:"rescueStuckJobsAfter"`
+		// MaxSnooze caps how long a rate-limited job waits for the upstream rate limit to reset (0 disables the cap)
+		MaxSnooze time.Duration `env:"WORKER_MAX_SNOOZE" env-default:"15m" yaml:"maxSnooze"`
+		// SnoozeJitter is the upper bound of a random delay added to snoozes of rate-limited jobs (0 disables it)
+		SnoozeJitter time.Duration `env:"WORKER_SNOOZE_JITTER" env-default:"5s" yaml:"snoozeJitter"`
+		// MaxPerHost is the maximum number of scans of URLs on the same host that run at once (0 disables the limit)
+		MaxPerHost int `env:"WORKER_MAX_PER_HOST" env-default:"0" yaml:"maxPerHost"`
+		// UrgentBudget is the part of the rate-limit budget reserved for urgent scans, e.g. requeued by an admin (0 disables it)
+		UrgentBudget int `env:"WORKER_URGENT_BUDGET" env-default:"0" yaml:"urgentBudget"`
+		// ReconcileInterval is how often pending scans whose job was lost are requeued or failed (0 disables it)
+		ReconcileInterval time.Duration `env:"WORKER_RECONCILE_INTERVAL" env-default:"5m" yaml:"reconcileInterval"`
+		// StaleScanAfter is how long a pending scan may go without updates before its job is considered lost
+		StaleScanAfter time.Duration `env:"WORKER_STALE_SCAN_AFTER" env-default:"1h" yaml:"staleScanAfter"`
 	} `yaml:"worker"`
 
+	// Tracing contains configuration for OpenTelemetry tracing
+	Tracing struct {
+		// Enabled creates spans around the scan lifecycle, exported to the debug log
+		Enabled bool `env:"TRACING_ENABLED" env-default:"false" yaml:"enabled"`
+		// SampleRatio is the fraction of traces sampled, from 0 to 1
+		SampleRatio float64 `env:"TRACING_SAMPLE_RATIO" env-default:"1" yaml:"sampleRatio"`
+	} `yaml:"tracing"`
+
 	// GracefulShutdownTimeout is the maximum duration to wait for ongoing requests to complete during shutdown
 	GracefulShutdownTimeout time.Duration `env:"GRACEFUL_SHUTDOWN_TIMEOUT" env-default:"10s" yaml:"gracefulShutdownTimeout"` //nolint: lll
 }
@@ -96,5 +210,120 @@ func Load(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("could not read config: %w", err)
 	}
 
+	if err := cfg.resolveSecretFiles(); err != nil {
+		return nil, err
+	}
+
 	return &cfg, nil
 }
+
+// secretFileSuffix is appended to the environment variable of a sensitive
+// field to name a variable holding the path of a file with its value, as with
+// Docker and Kubernetes secrets.
+const secretFileSuffix = "_FILE"
+
+// secretFields maps the environment variables of sensitive fields to the
+// fields themselves.
+func (c *Config) secretFields() map[string]*string {
+	return map[string]*string{
+		"DATABASE_PASSWORD":          &c.Database.Password,
+		"DATABASE_REPLICA_DSN":       &c.Database.ReplicaDSN,
+		"JWT_PUBLIC_KEY":             &c.JWT.PublicKey,
+		"JWT_PRIVATE_KEY":            &c.JWT.PrivateKey,
+		"SCANNER_URLSCAN_IO_API_KEY": &c.Scanner.UrlscanioAPIKey,
+		"HTTP_METRICS_BEARER_TOKEN":  &c.HTTP.MetricsBearerToken,
+		"HTTP_METRICS_PASSWORD":      &c.HTTP.MetricsPassword,
+	}
+}
+
+// resolveSecretFiles reads sensitive fields from files named by their *_FILE
+// environment variables, e.g. JWT_PRIVATE_KEY_FILE. A value read from a file
+// takes precedence over env and yaml; trailing newlines are trimmed.
+func (c *Config) resolveSecretFiles() error {
+	for env, field := range c.secretFields() {
+		path := os.Getenv(env + secretFileSuffix)
+		if path == "" {
+			continue
+		}
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("could not read %s%s: %w", env, secretFileSuffix, err)
+		}
+		*field = strings.TrimRight(string(b), "\r\n")
+	}
+
+	return nil
+}
+
+// ValidateForScan checks that the fields required by the scan command are set
+// and sane. All problems are reported at once as a joined error, each naming
+// the offending yaml key and environment variable.
+func (c *Config) ValidateForScan() error {
+	var errs []error
+	if c.JWT.PublicKey == "" {
+		errs = append(errs, errors.New("jwt.publicKey (JWT_PUBLIC_KEY) is required"))
+	}
+	if c.Scanner.UrlscanioAPIKey == "" {
+		errs = append(errs, errors.New("scanner.urlscanioApiKey (SCANNER_URLSCAN_IO_API_KEY) is required"))
+	}
+	if c.Scanner.MaxAttempts < 1 {
+		errs = append(errs, errors.New("scanner.maxAttempts (SCANNER_MAX_ATTEMPTS) must be at least 1"))
+	}
+	switch c.Scanner.ResultCacheScope {
+	case "global", "user":
+	default:
+		errs = append(errs, errors.New("scanner.resultCacheScope (SCANNER_RESULT_CACHE_SCOPE) must be global or user"))
+	}
+	switch c.Scanner.Visibility {
+	case "public", "unlisted", "private":
+	default:
+		errs = append(errs, errors.New("scanner.visibility (SCANNER_VISIBILITY) must be public, unlisted or private"))
+	}
+	if (c.Scanner.TLSCertFile == "") != (c.Scanner.TLSKeyFile == "") {
+		errs = append(errs, errors.New(
+			"scanner.tlsCertFile (SCANNER_TLS_CERT_FILE) and scanner.tlsKeyFile (SCANNER_TLS_KEY_FILE) must be set together"))
+	}
+	if c.Scanner.Country != "" && !urlscanner.ValidCountry(c.Scanner.Country) {
+		errs = append(errs, errors.New("scanner.country (SCANNER_COUNTRY) must be an ISO 3166-1 alpha-2 country code"))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, errors.New("tracing.sampleRatio (TRACING_SAMPLE_RATIO) must be between 0 and 1"))
+	}
+	if c.Worker.JobConcurrency < 1 {
+		errs = append(errs, errors.New("worker.jobConcurrency (WORKER_JOB_CONCURRENCY) must be at least 1"))
+	}
+	if c.Worker.FetchPollInterval < c.Worker.FetchCooldown {
+		errs = append(errs, errors.New(
+			"worker.fetchPollInterval (WORKER_FETCH_POLL_INTERVAL) must not be shorter than worker.fetchCooldown"))
+	}
+	// rescuing a job that is still running scans its URL twice
+	if c.Worker.RescueStuckJobsAfter > 0 && c.Worker.RescueStuckJobsAfter < c.maxJobTimeout() {
+		errs = append(errs, errors.New("worker.rescueStuckJobsAfter (WORKER_RESCUE_STUCK_JOBS_AFTER) must not be "+
+			"shorter than worker.jobTimeout, nor than scanner.slowJobTimeout when scanner.slowDomains are set"))
+	}
+	if c.Worker.ReconcileInterval > 0 && c.Worker.StaleScanAfter < c.Worker.JobTimeout {
+		errs = append(errs, errors.New(
+			"worker.staleScanAfter (WORKER_STALE_SCAN_AFTER) must not be shorter than worker.jobTimeout"))
+	}
+	if c.HTTP.MetricsPassword != "" && c.HTTP.MetricsUsername == "" {
+		errs = append(errs, errors.New("http.metricsUsername (HTTP_METRICS_USERNAME) is required with http.metricsPassword"))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// maxJobTimeout returns the longest time a scan job may run: the worker's
+// JobTimeout, or the scanner's SlowJobTimeout when it is longer and applies
+// to any domain.
+func (c *Config) maxJobTimeout() time.Duration {
+	if len(c.Scanner.SlowDomains) > 0 {
+		return max(c.Worker.JobTimeout, c.Scanner.SlowJobTimeout)
+	}
+
+	return c.Worker.JobTimeout
+}
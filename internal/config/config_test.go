@@ -0,0 +1,204 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"scanner/internal/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// loadConfig writes content to a temporary yaml file and loads it.
+func loadConfig(t *testing.T, content string) *config.Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+
+	return cfg
+}
+
+func TestConfig_ValidateForScan_Valid(t *testing.T) {
+	cfg := loadConfig(t, `
+jwt:
+  publicKey: "PUBLIC KEY"
+scanner:
+  urlscanioApiKey: "API KEY"
+`)
+
+	require.NoError(t, cfg.ValidateForScan())
+}
+
+func TestConfig_ValidateForScan_MissingKeys(t *testing.T) {
+	cfg := loadConfig(t, `
+scanner:
+  maxAttempts: -1
+`)
+
+	err := cfg.ValidateForScan()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "invalid config")
+	require.ErrorContains(t, err, "jwt.publicKey (JWT_PUBLIC_KEY) is required")
+	require.ErrorContains(t, err, "scanner.urlscanioApiKey (SCANNER_URLSCAN_IO_API_KEY) is required")
+	require.ErrorContains(t, err, "scanner.maxAttempts (SCANNER_MAX_ATTEMPTS) must be at least 1")
+}
+
+func TestConfig_ValidateForScan_MetricsPasswordWithoutUsername(t *testing.T) {
+	cfg := loadConfig(t, `
+http:
+  metricsPassword: "secret"
+jwt:
+  publicKey: "PUBLIC KEY"
+scanner:
+  urlscanioApiKey: "API KEY"
+`)
+
+	require.ErrorContains(t, cfg.ValidateForScan(), "http.metricsUsername (HTTP_METRICS_USERNAME)")
+}
+
+func TestConfig_ValidateForScan_InvalidVisibility(t *testing.T) {
+	cfg := loadConfig(t, `
+jwt:
+  publicKey: "PUBLIC KEY"
+scanner:
+  urlscanioApiKey: "API KEY"
+  visibility: secret
+`)
+
+	require.ErrorContains(t, cfg.ValidateForScan(), "scanner.visibility (SCANNER_VISIBILITY)")
+}
+
+func TestConfig_ValidateForScan_TLSCertWithoutKey(t *testing.T) {
+	cfg := loadConfig(t, `
+jwt:
+  publicKey: "PUBLIC KEY"
+scanner:
+  urlscanioApiKey: "API KEY"
+  tlsCertFile: /etc/scanner/client.pem
+`)
+
+	require.ErrorContains(t, cfg.ValidateForScan(), "scanner.tlsCertFile (SCANNER_TLS_CERT_FILE)")
+}
+
+func TestConfig_ValidateForScan_InvalidCountry(t *testing.T) {
+	cfg := loadConfig(t, `
+jwt:
+  publicKey: "PUBLIC KEY"
+scanner:
+  urlscanioApiKey: "API KEY"
+  country: USA
+`)
+
+	require.ErrorContains(t, cfg.ValidateForScan(), "scanner.country (SCANNER_COUNTRY)")
+}
+
+func TestConfig_ValidateForScan_InvalidResultCacheScope(t *testing.T) {
+	cfg := loadConfig(t, `
+jwt:
+  publicKey: "PUBLIC KEY"
+scanner:
+  urlscanioApiKey: "API KEY"
+  resultCacheScope: team
+`)
+
+	require.ErrorContains(t, cfg.ValidateForScan(), "scanner.resultCacheScope (SCANNER_RESULT_CACHE_SCOPE)")
+}
+
+func TestConfig_ValidateForScan_InvalidWorkerFetchAndRescue(t *testing.T) {
+	cfg := loadConfig(t, `
+jwt:
+  publicKey: "PUBLIC KEY"
+scanner:
+  urlscanioApiKey: "API KEY"
+worker:
+  jobTimeout: 1m
+  fetchCooldown: 2s
+  fetchPollInterval: 1s
+  rescueStuckJobsAfter: 30s
+`)
+
+	err := cfg.ValidateForScan()
+	require.ErrorContains(t, err, "worker.fetchPollInterval (WORKER_FETCH_POLL_INTERVAL)")
+	require.ErrorContains(t, err, "worker.rescueStuckJobsAfter (WORKER_RESCUE_STUCK_JOBS_AFTER)")
+}
+
+func TestConfig_ValidateForScan_RescueShorterThanSlowJobTimeout(t *testing.T) {
+	cfg := loadConfig(t, `
+jwt:
+  publicKey: "PUBLIC KEY"
+scanner:
+  urlscanioApiKey: "API KEY"
+  slowDomains: ["slow.example.com"]
+  slowJobTimeout: 5m
+worker:
+  jobTimeout: 1m
+  rescueStuckJobsAfter: 2m
+`)
+
+	// slow-domain jobs still running after 2m would be rescued and run twice
+	require.ErrorContains(t, cfg.ValidateForScan(), "worker.rescueStuckJobsAfter (WORKER_RESCUE_STUCK_JOBS_AFTER)")
+
+	cfg.Worker.RescueStuckJobsAfter = 5 * time.Minute
+	require.NoError(t, cfg.ValidateForScan())
+
+	// without slow domains only the worker's job timeout applies
+	cfg.Scanner.SlowDomains = nil
+	cfg.Worker.RescueStuckJobsAfter = 2 * time.Minute
+	require.NoError(t, cfg.ValidateForScan())
+}
+
+func TestLoad_FailedResultReuseOffByDefault(t *testing.T) {
+	cfg := loadConfig(t, "environment: test\n")
+	require.Zero(t, cfg.Scanner.FailedResultTTL)
+}
+
+func TestLoad_SecretFiles(t *testing.T) {
+	cases := []struct {
+		env   string
+		field func(cfg *config.Config) string
+	}{
+		{env: "DATABASE_PASSWORD", field: func(cfg *config.Config) string { return cfg.Database.Password }},
+		{env: "DATABASE_REPLICA_DSN", field: func(cfg *config.Config) string { return cfg.Database.ReplicaDSN }},
+		{env: "JWT_PUBLIC_KEY", field: func(cfg *config.Config) string { return cfg.JWT.PublicKey }},
+		{env: "JWT_PRIVATE_KEY", field: func(cfg *config.Config) string { return cfg.JWT.PrivateKey }},
+		{env: "SCANNER_URLSCAN_IO_API_KEY", field: func(cfg *config.Config) string { return cfg.Scanner.UrlscanioAPIKey }},
+		{env: "HTTP_METRICS_BEARER_TOKEN", field: func(cfg *config.Config) string { return cfg.HTTP.MetricsBearerToken }},
+		{env: "HTTP_METRICS_PASSWORD", field: func(cfg *config.Config) string { return cfg.HTTP.MetricsPassword }},
+	}
+	for _, tc := range cases {
+		t.Run(tc.env, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "secret")
+			require.NoError(t, os.WriteFile(path, []byte("from-file\n"), 0o600))
+			t.Setenv(tc.env+"_FILE", path)
+
+			cfg := loadConfig(t, "environment: test\n")
+			require.Equal(t, "from-file", tc.field(cfg))
+		})
+	}
+}
+
+func TestLoad_SecretFileOverridesYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(path, []byte("from-file"), 0o600))
+	t.Setenv("SCANNER_URLSCAN_IO_API_KEY_FILE", path)
+
+	cfg := loadConfig(t, `
+scanner:
+  urlscanioApiKey: "from-yaml"
+`)
+	require.Equal(t, "from-file", cfg.Scanner.UrlscanioAPIKey)
+}
+
+func TestLoad_SecretFileMissing(t *testing.T) {
+	t.Setenv("JWT_PRIVATE_KEY_FILE", filepath.Join(t.TempDir(), "missing"))
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(path, []byte("environment: test\n"), 0o600))
+
+	_, err := config.Load(path)
+	require.ErrorContains(t, err, "JWT_PRIVATE_KEY_FILE")
+}
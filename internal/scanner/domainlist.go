@@ -0,0 +1,53 @@
+package scanner
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DomainList is a set of domain patterns. A pattern is either an exact domain
+// ("example.com"), which matches only that host, or a wildcard suffix
+// ("*.example.com"), which matches any subdomain of example.com but not
+// example.com itself. Matching is case-insensitive.
+type DomainList []string
+
+// Match reports whether host matches any pattern in the list.
+func (l DomainList) Match(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, pattern := range l {
+		pattern = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(pattern)), ".")
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+
+			continue
+		}
+
+		if host == pattern {
+			return true
+		}
+	}
+
+	return false
+}
+
+// domainAllowed reports whether the host of the given normalized URL may be
+// scanned. The denylist takes precedence; when the allowlist is non-empty the
+// host must also match it.
+func domainAllowed(rawURL string, allowlist, denylist DomainList) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	host := u.Hostname()
+	if denylist.Match(host) {
+		return false
+	}
+	if len(allowlist) > 0 {
+		return allowlist.Match(host)
+	}
+
+	return true
+}
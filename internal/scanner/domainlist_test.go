@@ -0,0 +1,112 @@
+package scanner_test
+
+import (
+	"context"
+	"scanner/internal/scanner"
+	"scanner/pkg/domain"
+	"scanner/pkg/serrors"
+	"scanner/pkg/storage"
+	mockstorage "scanner/pkg/storage/mock"
+	mockurlscanner "scanner/pkg/urlscanner/mock"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDomainList_Match(t *testing.T) {
+	list := scanner.DomainList{"example.com", "*.evil.org", " *.Mixed.Case "}
+
+	cases := map[string]bool{
+		"example.com":        true,
+		"EXAMPLE.com.":       true,
+		"www.example.com":    false,
+		"notexample.com":     false,
+		"evil.org":           false,
+		"a.evil.org":         true,
+		"a.b.evil.org":       true,
+		"notevil.org":        false,
+		"sub.mixed.case":     true,
+		"example.com.evil":   false,
+		"www.evil.org.other": false,
+	}
+	for host, want := range cases {
+		require.Equal(t, want, list.Match(host), host)
+	}
+
+	require.False(t, scanner.DomainList(nil).Match("example.com"))
+}
+
+func TestScanner_Enqueue_DomainLists(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	st := mockstorage.NewMockStorage(ctrl)
+	s := scanner.New(st, mockurlscanner.NewMockClient(ctrl), scanner.Options{
+		MaxAttempts:    3,
+		ResultCacheTTL: time.Hour,
+		AllowedDomains: scanner.DomainList{"*.example.com", "example.org"},
+		// denylist wins over the allowlist wildcard
+		DeniedDomains: scanner.DomainList{"blocked.example.com"},
+	})
+
+	cases := []struct {
+		URL     string
+		allowed bool
+	}{
+		{URL: "https://www.example.com/", allowed: true},
+		{URL: "https://example.org/path", allowed: true},
+		{URL: "https://blocked.example.com/", allowed: false},
+		{URL: "https://example.com/", allowed: false},
+		{URL: "https://other.net/", allowed: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.URL, func(t *testing.T) {
+			if tc.allowed {
+				expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+					tx.EXPECT().StoreScans(gomock.Any(), gomock.Any()).DoAndReturn(
+						func(_ context.Context, scans ...domain.Scan) ([]domain.Scan, error) { return scans, nil },
+					)
+					tx.EXPECT().RecordAudit(gomock.Any(), gomock.Any(), storage.AuditActionCreate, gomock.Any()).Return(nil)
+					tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).Return(true, nil)
+				})
+			}
+
+			_, err := s.Enqueue(context.Background(), domain.UserID(uuid.New()), tc.URL, "", nil, "")
+			if tc.allowed {
+				require.NoError(t, err)
+
+				return
+			}
+			require.Error(t, err)
+			require.ErrorIs(t, err, serrors.ErrForbidden)
+		})
+	}
+}
+
+func TestScanner_Enqueue_DenylistOnly(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	st := mockstorage.NewMockStorage(ctrl)
+	s := scanner.New(st, mockurlscanner.NewMockClient(ctrl), scanner.Options{
+		MaxAttempts:   3,
+		DeniedDomains: scanner.DomainList{"*.evil.org"},
+	})
+
+	_, err := s.Enqueue(context.Background(), domain.UserID(uuid.New()), "https://a.evil.org/", "", nil, "")
+	require.ErrorIs(t, err, serrors.ErrForbidden)
+
+	// with an empty allowlist anything not denied is allowed
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().StoreScans(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, scans ...domain.Scan) ([]domain.Scan, error) { return scans, nil },
+		)
+		tx.EXPECT().RecordAudit(gomock.Any(), gomock.Any(), storage.AuditActionCreate, gomock.Any()).Return(nil)
+		tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).Return(true, nil)
+	})
+	_, err = s.Enqueue(context.Background(), domain.UserID(uuid.New()), "https://good.org/", "", nil, "")
+	require.NoError(t, err)
+}
@@ -0,0 +1,74 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Resolver resolves host names to IP addresses. *net.Resolver satisfies it.
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598) which, like
+// private ranges, is not reachable from the public internet.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)} //nolint: gochecknoglobals
+
+// isBlockedIP reports whether ip is a loopback, link-local (including cloud
+// metadata endpoints such as 169.254.169.254), private, shared, unspecified or
+// multicast address.
+func isBlockedIP(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsPrivate() ||
+		ip.IsUnspecified() ||
+		sharedAddressSpace.Contains(ip)
+}
+
+// checkHost makes sure the host of the given normalized URL does not point to
+// a private or internal address. Literal IPs are inspected directly, localhost
+// names are rejected, and other host names are resolved so that every address
+// they point to can be inspected.
+func checkHost(ctx context.Context, resolver Resolver, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("could not parse URL: %w", err)
+	}
+
+	host := u.Hostname()
+	if host == "" {
+		return errors.New("URL has no host")
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		if isBlockedIP(ip) {
+			return fmt.Errorf("host %s is a private or internal address", host)
+		}
+
+		return nil
+	}
+
+	name := strings.TrimSuffix(host, ".")
+	if name == "localhost" || strings.HasSuffix(name, ".localhost") {
+		return fmt.Errorf("host %s is a private or internal address", host)
+	}
+
+	addrs, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("could not resolve host %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if isBlockedIP(addr.IP) {
+			return fmt.Errorf("host %s resolves to a private or internal address", host)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package scanner_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"scanner/internal/scanner"
+	"scanner/pkg/domain"
+	"scanner/pkg/serrors"
+	"scanner/pkg/storage"
+	mockstorage "scanner/pkg/storage/mock"
+	mockurlscanner "scanner/pkg/urlscanner/mock"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// fakeResolver resolves host names from a static table.
+type fakeResolver map[string][]string
+
+func (f fakeResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	ips, ok := f[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+
+	out := make([]net.IPAddr, 0, len(ips))
+	for _, ip := range ips {
+		out = append(out, net.IPAddr{IP: net.ParseIP(ip)})
+	}
+
+	return out, nil
+}
+
+func newHostGuardScanner(t *testing.T) (*gomock.Controller, *mockstorage.MockStorage, scanner.Scanner) {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	st := mockstorage.NewMockStorage(ctrl)
+	s := scanner.New(st, mockurlscanner.NewMockClient(ctrl), scanner.Options{
+		MaxAttempts:       3,
+		ResultCacheTTL:    time.Hour,
+		BlockPrivateHosts: true,
+		Resolver: fakeResolver{
+			"example.com":   {"93.184.216.34", "2606:2800:220:1:248:1893:25c8:1946"},
+			"internal.corp": {"93.184.216.34", "10.1.2.3"},
+			"metadata.test": {"169.254.169.254"},
+		},
+	})
+
+	return ctrl, st, s
+}
+
+func TestScanner_Enqueue_BlocksPrivateHosts(t *testing.T) {
+	ctrl, st, s := newHostGuardScanner(t)
+	defer ctrl.Finish()
+
+	// storage must never be reached for blocked hosts
+	st.EXPECT().WithTx(gomock.Any(), gomock.Any()).Times(0)
+
+	blocked := map[string]string{
+		"loopback v4":         "http://127.0.0.1/",
+		"loopback v6":         "http://[::1]/",
+		"localhost":           "http://localhost:8080/",
+		"localhost subdomain": "http://api.localhost/",
+		"link-local":          "http://169.254.10.10/",
+		"metadata":            "http://169.254.169.254/latest/meta-data",
+		"link-local v6":       "http://[fe80::1]/",
+		"private 10/8":        "http://10.0.0.1/",
+		"private 172.16/12":   "http://172.16.5.4/",
+		"private 192.168/16":  "http://192.168.1.1/",
+		"private v6":          "http://[fd00::1]/",
+		"shared 100.64/10":    "http://100.64.0.1/",
+		"unspecified":         "http://0.0.0.0/",
+		"mapped v6 loopback":  "http://[::ffff:127.0.0.1]/",
+		"resolves to private": "https://internal.corp/",
+		"resolves to meta":    "https://metadata.test/",
+		"unresolvable":        "https://unknown.invalid/",
+	}
+	for name, raw := range blocked {
+		t.Run(name, func(t *testing.T) {
+			_, err := s.Enqueue(context.Background(), domain.UserID(uuid.New()), raw, "", nil, "")
+			require.Error(t, err)
+			require.ErrorIs(t, err, serrors.ErrBadRequest)
+		})
+	}
+}
+
+func TestScanner_Enqueue_AllowsPublicHosts(t *testing.T) {
+	ctrl, st, s := newHostGuardScanner(t)
+	defer ctrl.Finish()
+
+	allowed := map[string]string{
+		"public literal v4": "http://93.184.216.34/",
+		"public literal v6": "http://[2606:2800:220:1:248:1893:25c8:1946]/",
+		"public host name":  "https://example.com/",
+	}
+	for name, raw := range allowed {
+		t.Run(name, func(t *testing.T) {
+			expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+				tx.EXPECT().StoreScans(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, scans ...domain.Scan) ([]domain.Scan, error) { return scans, nil },
+				)
+				tx.EXPECT().RecordAudit(gomock.Any(), gomock.Any(), storage.AuditActionCreate, gomock.Any()).Return(nil)
+				tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).Return(true, nil)
+			})
+
+			_, err := s.Enqueue(context.Background(), domain.UserID(uuid.New()), raw, "", nil, "")
+			require.NoError(t, err)
+		})
+	}
+}
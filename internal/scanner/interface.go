@@ -4,9 +4,37 @@ package scanner
 import (
 	"context"
 	"scanner/pkg/domain"
+	"scanner/pkg/storage"
 	"scanner/pkg/urlscanner"
+	"time"
+
+	"github.com/riverqueue/river/rivertype"
 )
 
+// AdminScanFilter narrows the scans returned by Scanner.AdminScans. Zero-valued
+// fields are ignored.
+type AdminScanFilter struct {
+	// Status filters results to scans with the given status.
+	Status domain.ScanStatus
+	// URL filters results to scans of the given URL; it is normalized first.
+	URL string
+	// CreatedAfter filters results to scans created at or after this time.
+	CreatedAfter time.Time
+	// CreatedBefore filters results to scans created before this time.
+	CreatedBefore time.Time
+}
+
+// AuditFilter narrows the entries returned by Scanner.AuditLog. Zero-valued
+// fields are ignored.
+type AuditFilter struct {
+	// UserID filters results to entries of the given user.
+	UserID domain.UserID
+	// ScanID filters results to entries of the given scan.
+	ScanID domain.ScanID
+	// Action filters results to entries of the given action.
+	Action storage.AuditAction
+}
+
 // Scanner is the main interface for scheduling URL scans and querying their results.
 // Implementations are expected to enqueue scan jobs, paginate user scans,
 // fetch individual scan results, and delete scans when requested.
@@ -15,26 +43,130 @@ import (
 type Scanner interface {
 	// Enqueue submits a new scan request for the given URL and user.
 	// It returns the created scan record, which may already be completed if a
-	// recent cached result exists for the same URL.
-	Enqueue(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error)
+	// recent cached result exists for the same URL. A non-empty idempotencyKey
+	// makes repeated calls by the same user return the originally created scan.
+	// Tags are attached to the scan submitted to the provider. A non-empty
+	// visibility overrides the configured default visibility of the scan.
+	// Created scans are recorded in the audit log.
+	Enqueue(ctx context.Context,
+		userID domain.UserID,
+		URL, idempotencyKey string,
+		tags []string,
+		visibility urlscanner.Visibility) (*domain.Scan, error)
+
+	// EnsureScan returns the user's latest completed scan of the URL if it is
+	// still within the result cache TTL, or otherwise enqueues and returns a
+	// new scan like Enqueue, in a single transaction.
+	EnsureScan(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error)
 
-	// UserScans returns a page of scans for the given user filtered by status.
+	// UserScans returns a page of scans for the given user filtered by status,
+	// and to malicious verdicts when maliciousOnly is set.
 	// Cursor is an RFC3339 timestamp string; when empty, it starts from "now".
-	// The returned string is the next cursor to request the following page.
+	// Direction selects the page before (storage.PageNext, the default when
+	// empty) or after (storage.PagePrev) the cursor. The returned strings are
+	// the next and previous cursors to request the following and preceding
+	// pages.
 	UserScans(ctx context.Context,
 		userID domain.UserID,
 		status domain.ScanStatus,
+		maliciousOnly bool,
+		cursor string,
+		direction storage.PageDirection,
+		limit uint) ([]domain.Scan, string, string, error)
+
+	// SearchScans returns a page of scans of the given user whose result page
+	// field named by key equals value. Cursor semantics match UserScans.
+	SearchScans(ctx context.Context,
+		userID domain.UserID,
+		key domain.ScanSearchKey,
+		value string,
 		cursor string,
 		limit uint) ([]domain.Scan, string, error)
 
+	// StatusCounts returns the number of non-deleted scans of the given user for
+	// every known scan status, including the ones without scans.
+	StatusCounts(ctx context.Context, userID domain.UserID) (map[domain.ScanStatus]int64, error)
+
+	// AdminScans returns a page of scans across all users matching the filter.
+	// It must only be exposed to operators. Cursor semantics match UserScans.
+	AdminScans(ctx context.Context,
+		filter AdminScanFilter,
+		cursor string,
+		limit uint) ([]domain.Scan, string, error)
+
+	// AdminJobs returns a page of queued jobs, optionally filtered by state. It
+	// must only be exposed to operators. Cursor is a job ID; when empty, it
+	// starts from the newest job. The returned string is the next cursor.
+	AdminJobs(ctx context.Context,
+		state rivertype.JobState,
+		cursor string,
+		limit uint) ([]storage.Job, string, error)
+
+	// AuditLog returns a page of audit log entries matching the filter, newest
+	// first. It must only be exposed to operators. Cursor is an entry ID; when
+	// empty, it starts from the newest entry. The returned string is the next
+	// cursor.
+	AuditLog(ctx context.Context,
+		filter AuditFilter,
+		cursor string,
+		limit uint) ([]storage.AuditEntry, string, error)
+
+	// Requeue enqueues a new urgent job for a pending scan of any user whose job was
+	// lost. A conflict error is returned when the scan is no longer pending or
+	// a job for its URL is still active. It must only be exposed to operators.
+	Requeue(ctx context.Context, scanID domain.ScanID) (*domain.Scan, error)
+
+	// ForceFail marks a pending scan of any user as failed with the given
+	// reason. A conflict error is returned when the scan is no longer pending.
+	// It must only be exposed to operators.
+	ForceFail(ctx context.Context, scanID domain.ScanID, reason string) (*domain.Scan, error)
+
+	// ReconcileStaleScans recovers pending scans of any user that were last
+	// updated before olderThan and whose URL has no active job, e.g. because the
+	// worker processing it died. Scans that used up their attempts are marked
+	// failed and the others are requeued. It returns the number of requeued and
+	// failed scans.
+	ReconcileStaleScans(ctx context.Context, olderThan time.Time) (requeued, failed int, err error)
+
 	// Result fetches a single scan by ID for the given user, or a not-found error
-	// when the scan does not exist.
+	// when the scan does not exist. NextRetryAt is populated when the scan is
+	// still pending and the job completing it waits for its next run.
 	Result(ctx context.Context, userID domain.UserID, scanID domain.ScanID) (*domain.Scan, error)
 
-	// Delete removes a scan belonging to the given user. If the scan does not
-	// exist, a not-found error is returned.
+	// Attempts returns the timeline of processing attempts of a single scan of
+	// the given user, oldest first, each with its error and the upstream
+	// rate-limit status. Missing scans are reported like Result.
+	Attempts(ctx context.Context, userID domain.UserID, scanID domain.ScanID) ([]storage.ScanAttempt, error)
+
+	// Results fetches the user's scans with the given IDs in the requested
+	// order. Duplicate IDs are returned once, and IDs without a matching scan
+	// are skipped.
+	Results(ctx context.Context, userID domain.UserID, scanIDs []domain.ScanID) ([]domain.Scan, error)
+
+	// LatestByURL returns the most recent scan of the given URL owned by the
+	// user. The URL is normalized before lookup. A not-found error is returned
+	// when the user has no scan for the URL.
+	LatestByURL(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error)
+
+	// Delete removes a scan belonging to the given user and cancels it if it
+	// is still pending. If the scan does not exist, a not-found error is returned.
+	// The deletion is recorded in the audit log.
 	Delete(ctx context.Context, userID domain.UserID, scanID domain.ScanID) error
 
-	// Scan scans the given URL, waits for results, and store results in the database.
-	Scan(ctx context.Context, URL string) (urlscanner.RateLimitStatus, error)
+	// Scan scans the given URL with the given submit options, waits for
+	// results, and store results in the database. Only the pending scans the
+	// job of owner scans are updated: the owner's non-shareable scans, or the
+	// shareable scans of all users for the zero owner; see JobArgs.Owner. An
+	// internal error is returned if the Scanner was created without a
+	// urlscanner client.
+	Scan(
+		ctx context.Context,
+		URL string,
+		owner domain.UserID,
+		opts urlscanner.SubmitOptions,
+	) (urlscanner.RateLimitStatus, error)
+
+	// Close stops polling the results of submitted scans in the background.
+	// Scans still waiting for their result fail afterward.
+	Close()
 }
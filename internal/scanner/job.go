@@ -1,47 +1,145 @@
 package scanner
 
 import (
+	"context"
+	"scanner/pkg/domain"
+	"scanner/pkg/urlscanner"
 	"time"
 
 	"github.com/riverqueue/river"
 	"github.com/riverqueue/river/rivertype"
+	"go.opentelemetry.io/otel/propagation"
 )
 
+// JobKind is the River job kind used to register and dispatch the scan worker.
+const JobKind = "ScanURLJob"
+
+// JobOptions control how a scan job is inserted into River. They are not part
+// of the job payload and therefore do not affect job uniqueness.
+type JobOptions struct {
+	// MaxAttempts configures the maximum number of times River should retry the job.
+	MaxAttempts int
+	// UniqueJobPeriod defines the lookback window during which a job with the
+	// same arguments is considered a duplicate across the unique states.
+	UniqueJobPeriod time.Duration
+	// Queue is the name of the River queue the job is inserted into. An empty
+	// value uses River's default queue.
+	Queue string
+	// Priority is the River job priority, from 1 (highest) to 4 (lowest). Zero
+	// uses River's default priority.
+	Priority int
+	// Timeout overrides the worker's global job timeout for this job. Zero
+	// uses the global timeout.
+	Timeout time.Duration
+	// NotUnique inserts the job without uniqueness constraints, e.g. to scan a
+	// URL again whose unique job finished without a result that may be reused.
+	NotUnique bool
+}
+
 // JobArgs contains the arguments for a scan job submitted to River.
 // The struct is used as the unique key for jobs to prevent duplicate work per URL.
 type JobArgs struct {
 	// URL is the address to scan. It is marked as unique so River can enforce
 	// one job per URL according to InsertOpts.UniqueOpts.
 	URL string `json:"url" river:"unique"`
+	// Timeout is the maximum duration a single attempt of the job may run,
+	// overriding the worker's global job timeout when positive. It is part of
+	// the payload because River reads per-job timeouts from the worker rather
+	// than from InsertOpts, but it is not a unique field.
+	Timeout time.Duration `json:"timeout,omitempty"`
+	// Tags are attached to the scan at the provider. They are not a unique
+	// field, so the tags of a request deduplicated into an existing job for the
+	// same URL are not submitted.
+	Tags []string `json:"tags,omitempty"`
+	// Visibility overrides the visibility the URL is submitted with; empty uses
+	// the urlscan.io client's default. It is a unique field, so that requests
+	// for different visibilities of a URL are not deduplicated into one
+	// submission.
+	Visibility urlscanner.Visibility `json:"visibility,omitempty" river:"unique"`
+	// Owner is the ID of the user whose scans a private or unlisted job scans;
+	// it is empty for public jobs, which scan the shareable scans of all users.
+	// It is a unique field, so that the non-shareable scans of different users
+	// are never completed with each other's results.
+	Owner string `json:"owner,omitempty" river:"unique"`
+	// Urgent marks a priority scan, e.g. requeued by an admin, that may use the
+	// worker's rate-limit budget reserved for such scans. It is not a unique
+	// field.
+	Urgent bool `json:"urgent,omitempty"`
+	// TraceContext carries the W3C trace context, e.g. the traceparent, of the
+	// request that enqueued the job, so that its scan continues the request's
+	// trace. It is not a unique field.
+	TraceContext map[string]string `json:"traceContext,omitempty"`
+
+	// options controls how the job is inserted; see InsertOpts.
+	options JobOptions
+}
+
+// NewJobArgs constructs JobArgs for scanning the given URL with the given
+// provider tags, inserted according to the provided options.
+func NewJobArgs(URL string, tags []string, options JobOptions) JobArgs {
+	return JobArgs{
+		URL:     URL,
+		Timeout: options.Timeout,
+		Tags:    tags,
+		options: options,
+	}
+}
+
+// Context returns ctx carrying the trace context the job was enqueued with, if
+// any, so that spans started from it join the enqueuing request's trace.
+func (args JobArgs) Context(ctx context.Context) context.Context {
+	if len(args.TraceContext) == 0 {
+		return ctx
+	}
 
-	// maxAttempts configures the maximum number of times River should retry the job.
-	maxAttempts int
-	// uniqueJobPeriod defines the lookback window during which a job with the
-	// same arguments is considered a duplicate across the specified states.
-	uniqueJobPeriod time.Duration
+	return propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier(args.TraceContext))
+}
+
+// OwnerID returns the user whose non-shareable scans the job scans, or the
+// zero UserID for jobs scanning the shareable scans of all users.
+func (args JobArgs) OwnerID() (domain.UserID, error) {
+	if args.Owner == "" {
+		return domain.UserID{}, nil
+	}
+
+	return domain.ParseUserID(args.Owner) //nolint: wrapcheck
+}
+
+// UniqueStates lists the job states in which an existing job for the same URL
+// makes a new insert a duplicate.
+func UniqueStates() []rivertype.JobState {
+	return []rivertype.JobState{
+		rivertype.JobStateAvailable,
+		rivertype.JobStateCompleted,
+		rivertype.JobStatePending,
+		rivertype.JobStateRunning,
+		rivertype.JobStateRetryable,
+		rivertype.JobStateScheduled,
+	}
 }
 
 // Kind returns the River job kind used to register and dispatch the scan worker.
-func (args JobArgs) Kind() string { return "ScanURLJob" }
+func (args JobArgs) Kind() string { return JobKind }
 
 // InsertOpts returns the River options that control how the job is enqueued,
-// including the maximum retry attempts and uniqueness constraints to prevent
-// duplicate jobs for the same URL across multiple job states.
+// including the queue, priority, maximum retry attempts and uniqueness
+// constraints to prevent duplicate jobs for the same URL across multiple job
+// states, unless JobOptions.NotUnique is set. Uniqueness is not scoped by queue,
+// so a URL is only scanned once regardless of which queue its job landed in.
 func (args JobArgs) InsertOpts() river.InsertOpts {
-	return river.InsertOpts{
-		MaxAttempts: args.maxAttempts,
+	opts := river.InsertOpts{
+		MaxAttempts: args.options.MaxAttempts,
+		Queue:       args.options.Queue,
+		Priority:    args.options.Priority,
+	}
+	if !args.options.NotUnique {
 		// make sure we only have one job per URL in any state
-		UniqueOpts: river.UniqueOpts{
+		opts.UniqueOpts = river.UniqueOpts{
 			ByArgs:   true,
-			ByPeriod: args.uniqueJobPeriod,
-			ByState: []rivertype.JobState{
-				rivertype.JobStateAvailable,
-				rivertype.JobStateCompleted,
-				rivertype.JobStatePending,
-				rivertype.JobStateRunning,
-				rivertype.JobStateRetryable,
-				rivertype.JobStateScheduled,
-			},
-		},
+			ByPeriod: args.options.UniqueJobPeriod,
+			ByState:  UniqueStates(),
+		}
 	}
+
+	return opts
 }
@@ -0,0 +1,47 @@
+package scanner_test
+
+import (
+	"scanner/internal/scanner"
+	"testing"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobArgs_KindAndInsertOpts(t *testing.T) {
+	args := scanner.NewJobArgs(url, nil, scanner.JobOptions{
+		MaxAttempts:     3,
+		UniqueJobPeriod: time.Hour,
+		Queue:           "priority",
+		Priority:        1,
+		Timeout:         5 * time.Minute,
+	})
+
+	require.Equal(t, scanner.JobKind, args.Kind())
+	require.Equal(t, url, args.URL)
+	require.Equal(t, 5*time.Minute, args.Timeout)
+
+	opts := args.InsertOpts()
+	require.Equal(t, 3, opts.MaxAttempts)
+	require.Equal(t, "priority", opts.Queue)
+	require.Equal(t, 1, opts.Priority)
+	require.True(t, opts.UniqueOpts.ByArgs)
+	require.False(t, opts.UniqueOpts.ByQueue, "uniqueness must span queues")
+	require.Equal(t, time.Hour, opts.UniqueOpts.ByPeriod)
+	require.ElementsMatch(t, scanner.UniqueStates(), opts.UniqueOpts.ByState)
+}
+
+func TestJobArgs_InsertOpts_NotUnique(t *testing.T) {
+	args := scanner.NewJobArgs(url, nil, scanner.JobOptions{
+		MaxAttempts:     3,
+		UniqueJobPeriod: time.Hour,
+		Queue:           "priority",
+		NotUnique:       true,
+	})
+
+	opts := args.InsertOpts()
+	require.Equal(t, 3, opts.MaxAttempts)
+	require.Equal(t, "priority", opts.Queue)
+	require.Equal(t, river.UniqueOpts{}, opts.UniqueOpts)
+}
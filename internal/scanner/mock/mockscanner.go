@@ -12,9 +12,13 @@ package mockscanner
 import (
 	context "context"
 	reflect "reflect"
+	scanner "scanner/internal/scanner"
 	domain "scanner/pkg/domain"
+	storage "scanner/pkg/storage"
 	urlscanner "scanner/pkg/urlscanner"
+	time "time"
 
+	rivertype "github.com/riverqueue/river/rivertype"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -42,6 +46,81 @@ func (m *MockScanner) EXPECT() *MockScannerMockRecorder {
 	return m.recorder
 }
 
+// AdminJobs mocks base method.
+func (m *MockScanner) AdminJobs(ctx context.Context, state rivertype.JobState, cursor string, limit uint) ([]storage.Job, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdminJobs", ctx, state, cursor, limit)
+	ret0, _ := ret[0].([]storage.Job)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AdminJobs indicates an expected call of AdminJobs.
+func (mr *MockScannerMockRecorder) AdminJobs(ctx, state, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminJobs", reflect.TypeOf((*MockScanner)(nil).AdminJobs), ctx, state, cursor, limit)
+}
+
+// AdminScans mocks base method.
+func (m *MockScanner) AdminScans(ctx context.Context, filter scanner.AdminScanFilter, cursor string, limit uint) ([]domain.Scan, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdminScans", ctx, filter, cursor, limit)
+	ret0, _ := ret[0].([]domain.Scan)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AdminScans indicates an expected call of AdminScans.
+func (mr *MockScannerMockRecorder) AdminScans(ctx, filter, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminScans", reflect.TypeOf((*MockScanner)(nil).AdminScans), ctx, filter, cursor, limit)
+}
+
+// Attempts mocks base method.
+func (m *MockScanner) Attempts(ctx context.Context, userID domain.UserID, scanID domain.ScanID) ([]storage.ScanAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Attempts", ctx, userID, scanID)
+	ret0, _ := ret[0].([]storage.ScanAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Attempts indicates an expected call of Attempts.
+func (mr *MockScannerMockRecorder) Attempts(ctx, userID, scanID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Attempts", reflect.TypeOf((*MockScanner)(nil).Attempts), ctx, userID, scanID)
+}
+
+// AuditLog mocks base method.
+func (m *MockScanner) AuditLog(ctx context.Context, filter scanner.AuditFilter, cursor string, limit uint) ([]storage.AuditEntry, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuditLog", ctx, filter, cursor, limit)
+	ret0, _ := ret[0].([]storage.AuditEntry)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AuditLog indicates an expected call of AuditLog.
+func (mr *MockScannerMockRecorder) AuditLog(ctx, filter, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditLog", reflect.TypeOf((*MockScanner)(nil).AuditLog), ctx, filter, cursor, limit)
+}
+
+// Close mocks base method.
+func (m *MockScanner) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockScannerMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockScanner)(nil).Close))
+}
+
 // Delete mocks base method.
 func (m *MockScanner) Delete(ctx context.Context, userID domain.UserID, scanID domain.ScanID) error {
 	m.ctrl.T.Helper()
@@ -57,18 +136,94 @@ func (mr *MockScannerMockRecorder) Delete(ctx, userID, scanID any) *gomock.Call
 }
 
 // Enqueue mocks base method.
-func (m *MockScanner) Enqueue(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error) {
+func (m *MockScanner) Enqueue(ctx context.Context, userID domain.UserID, URL, idempotencyKey string, tags []string, visibility urlscanner.Visibility) (*domain.Scan, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Enqueue", ctx, userID, URL)
+	ret := m.ctrl.Call(m, "Enqueue", ctx, userID, URL, idempotencyKey, tags, visibility)
 	ret0, _ := ret[0].(*domain.Scan)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Enqueue indicates an expected call of Enqueue.
-func (mr *MockScannerMockRecorder) Enqueue(ctx, userID, URL any) *gomock.Call {
+func (mr *MockScannerMockRecorder) Enqueue(ctx, userID, URL, idempotencyKey, tags, visibility any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockScanner)(nil).Enqueue), ctx, userID, URL, idempotencyKey, tags, visibility)
+}
+
+// EnsureScan mocks base method.
+func (m *MockScanner) EnsureScan(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureScan", ctx, userID, URL)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnsureScan indicates an expected call of EnsureScan.
+func (mr *MockScannerMockRecorder) EnsureScan(ctx, userID, URL any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockScanner)(nil).Enqueue), ctx, userID, URL)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureScan", reflect.TypeOf((*MockScanner)(nil).EnsureScan), ctx, userID, URL)
+}
+
+// ForceFail mocks base method.
+func (m *MockScanner) ForceFail(ctx context.Context, scanID domain.ScanID, reason string) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForceFail", ctx, scanID, reason)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ForceFail indicates an expected call of ForceFail.
+func (mr *MockScannerMockRecorder) ForceFail(ctx, scanID, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForceFail", reflect.TypeOf((*MockScanner)(nil).ForceFail), ctx, scanID, reason)
+}
+
+// LatestByURL mocks base method.
+func (m *MockScanner) LatestByURL(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LatestByURL", ctx, userID, URL)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LatestByURL indicates an expected call of LatestByURL.
+func (mr *MockScannerMockRecorder) LatestByURL(ctx, userID, URL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LatestByURL", reflect.TypeOf((*MockScanner)(nil).LatestByURL), ctx, userID, URL)
+}
+
+// ReconcileStaleScans mocks base method.
+func (m *MockScanner) ReconcileStaleScans(ctx context.Context, olderThan time.Time) (int, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReconcileStaleScans", ctx, olderThan)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReconcileStaleScans indicates an expected call of ReconcileStaleScans.
+func (mr *MockScannerMockRecorder) ReconcileStaleScans(ctx, olderThan any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconcileStaleScans", reflect.TypeOf((*MockScanner)(nil).ReconcileStaleScans), ctx, olderThan)
+}
+
+// Requeue mocks base method.
+func (m *MockScanner) Requeue(ctx context.Context, scanID domain.ScanID) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Requeue", ctx, scanID)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Requeue indicates an expected call of Requeue.
+func (mr *MockScannerMockRecorder) Requeue(ctx, scanID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Requeue", reflect.TypeOf((*MockScanner)(nil).Requeue), ctx, scanID)
 }
 
 // Result mocks base method.
@@ -86,33 +241,80 @@ func (mr *MockScannerMockRecorder) Result(ctx, userID, scanID any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Result", reflect.TypeOf((*MockScanner)(nil).Result), ctx, userID, scanID)
 }
 
+// Results mocks base method.
+func (m *MockScanner) Results(ctx context.Context, userID domain.UserID, scanIDs []domain.ScanID) ([]domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Results", ctx, userID, scanIDs)
+	ret0, _ := ret[0].([]domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Results indicates an expected call of Results.
+func (mr *MockScannerMockRecorder) Results(ctx, userID, scanIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Results", reflect.TypeOf((*MockScanner)(nil).Results), ctx, userID, scanIDs)
+}
+
 // Scan mocks base method.
-func (m *MockScanner) Scan(ctx context.Context, URL string) (urlscanner.RateLimitStatus, error) {
+func (m *MockScanner) Scan(ctx context.Context, URL string, owner domain.UserID, opts urlscanner.SubmitOptions) (urlscanner.RateLimitStatus, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Scan", ctx, URL)
+	ret := m.ctrl.Call(m, "Scan", ctx, URL, owner, opts)
 	ret0, _ := ret[0].(urlscanner.RateLimitStatus)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Scan indicates an expected call of Scan.
-func (mr *MockScannerMockRecorder) Scan(ctx, URL any) *gomock.Call {
+func (mr *MockScannerMockRecorder) Scan(ctx, URL, owner, opts any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scan", reflect.TypeOf((*MockScanner)(nil).Scan), ctx, URL)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scan", reflect.TypeOf((*MockScanner)(nil).Scan), ctx, URL, owner, opts)
 }
 
-// UserScans mocks base method.
-func (m *MockScanner) UserScans(ctx context.Context, userID domain.UserID, status domain.ScanStatus, cursor string, limit uint) ([]domain.Scan, string, error) {
+// SearchScans mocks base method.
+func (m *MockScanner) SearchScans(ctx context.Context, userID domain.UserID, key domain.ScanSearchKey, value, cursor string, limit uint) ([]domain.Scan, string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UserScans", ctx, userID, status, cursor, limit)
+	ret := m.ctrl.Call(m, "SearchScans", ctx, userID, key, value, cursor, limit)
 	ret0, _ := ret[0].([]domain.Scan)
 	ret1, _ := ret[1].(string)
 	ret2, _ := ret[2].(error)
 	return ret0, ret1, ret2
 }
 
+// SearchScans indicates an expected call of SearchScans.
+func (mr *MockScannerMockRecorder) SearchScans(ctx, userID, key, value, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchScans", reflect.TypeOf((*MockScanner)(nil).SearchScans), ctx, userID, key, value, cursor, limit)
+}
+
+// StatusCounts mocks base method.
+func (m *MockScanner) StatusCounts(ctx context.Context, userID domain.UserID) (map[domain.ScanStatus]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StatusCounts", ctx, userID)
+	ret0, _ := ret[0].(map[domain.ScanStatus]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StatusCounts indicates an expected call of StatusCounts.
+func (mr *MockScannerMockRecorder) StatusCounts(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatusCounts", reflect.TypeOf((*MockScanner)(nil).StatusCounts), ctx, userID)
+}
+
+// UserScans mocks base method.
+func (m *MockScanner) UserScans(ctx context.Context, userID domain.UserID, status domain.ScanStatus, maliciousOnly bool, cursor string, direction storage.PageDirection, limit uint) ([]domain.Scan, string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserScans", ctx, userID, status, maliciousOnly, cursor, direction, limit)
+	ret0, _ := ret[0].([]domain.Scan)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(string)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
 // UserScans indicates an expected call of UserScans.
-func (mr *MockScannerMockRecorder) UserScans(ctx, userID, status, cursor, limit any) *gomock.Call {
+func (mr *MockScannerMockRecorder) UserScans(ctx, userID, status, maliciousOnly, cursor, direction, limit any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserScans", reflect.TypeOf((*MockScanner)(nil).UserScans), ctx, userID, status, cursor, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserScans", reflect.TypeOf((*MockScanner)(nil).UserScans), ctx, userID, status, maliciousOnly, cursor, direction, limit)
 }
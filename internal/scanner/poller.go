@@ -0,0 +1,150 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"scanner/pkg/domain"
+	"scanner/pkg/logger"
+	"scanner/pkg/urlscanner"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// resultPoller polls the results of submitted scans in batches. Jobs waiting
+// for a result register its scan ID and a single loop fetches the results of
+// all waiting scans with one urlscanner.Client.Results call per interval,
+// instead of every job polling its own scan.
+type resultPoller struct {
+	client  urlscanner.Client
+	options func() Options
+	// ctx is canceled by close to stop the polling loop and its waiters.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	waiters map[string]chan *domain.ScanResult
+	running bool
+}
+
+// newResultPoller returns a resultPoller fetching results with client, reading
+// its polling interval and clock from options on every iteration so they can
+// be reloaded at runtime.
+func newResultPoller(client urlscanner.Client, options func() Options) *resultPoller {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &resultPoller{
+		client:  client,
+		options: options,
+		ctx:     ctx,
+		cancel:  cancel,
+		waiters: make(map[string]chan *domain.ScanResult),
+	}
+}
+
+// close stops the polling loop. Current and later waiters fail without a
+// result.
+func (p *resultPoller) close() {
+	p.cancel()
+}
+
+// wait blocks until the result of the scan is fetched, ctx is done or the
+// poller is closed. The polling loop is started by the first waiter and stops
+// when none is left.
+func (p *resultPoller) wait(ctx context.Context, scanID string) (*domain.ScanResult, error) {
+	ch := make(chan *domain.ScanResult, 1)
+
+	p.mu.Lock()
+	p.waiters[scanID] = ch
+	if !p.running && p.ctx.Err() == nil {
+		p.running = true
+		go p.run()
+	}
+	p.mu.Unlock()
+
+	select {
+	case res := <-ch:
+		return res, nil
+	case <-ctx.Done():
+		p.mu.Lock()
+		delete(p.waiters, scanID)
+		p.mu.Unlock()
+
+		return nil, fmt.Errorf("timeout waiting for results: %w", ctx.Err())
+	case <-p.ctx.Done():
+		p.mu.Lock()
+		delete(p.waiters, scanID)
+		p.mu.Unlock()
+
+		return nil, errors.New("result poller closed")
+	}
+}
+
+// run polls the results of waiting scans until no scan is waiting anymore or
+// the poller is closed. Errors are logged and the failed scans are retried on
+// the next iteration; waiters give up on their own once their context is done.
+func (p *resultPoller) run() {
+	for {
+		options := p.options()
+		interval := options.ResultBatchInterval
+		if interval <= 0 {
+			interval = scanResultPollIntervalBase
+		}
+		select {
+		case <-options.clock().After(interval):
+		case <-p.ctx.Done():
+			return
+		}
+
+		scanIDs := p.waiting()
+		if len(scanIDs) == 0 {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(p.ctx, scanResultPollTimeout)
+		logger.Debug(ctx, "reading batch results from urlscanner", zap.Int("count", len(scanIDs)))
+		results, errs := p.client.Results(ctx, scanIDs)
+		for scanID, err := range errs {
+			logger.Debug(ctx, "error reading batch result from urlscanner, will retry...",
+				zap.String("scanID", scanID), zap.Error(err))
+		}
+		cancel()
+
+		p.deliver(results)
+	}
+}
+
+// waiting returns the IDs of the waiting scans. When there are none, the loop
+// is marked as stopped in the same critical section so that a new waiter
+// starts a new loop.
+func (p *resultPoller) waiting() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.waiters) == 0 {
+		p.running = false
+
+		return nil
+	}
+
+	scanIDs := make([]string, 0, len(p.waiters))
+	for scanID := range p.waiters {
+		scanIDs = append(scanIDs, scanID)
+	}
+
+	return scanIDs
+}
+
+// deliver hands the fetched results to their waiters.
+func (p *resultPoller) deliver(results map[string]*domain.ScanResult) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for scanID, res := range results {
+		if ch, ok := p.waiters[scanID]; ok {
+			ch <- res
+			delete(p.waiters, scanID)
+		}
+	}
+}
@@ -4,14 +4,27 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/url"
 	"scanner/internal/config"
+	"scanner/pkg/clock"
 	"scanner/pkg/domain"
 	"scanner/pkg/logger"
 	"scanner/pkg/serrors"
 	"scanner/pkg/storage"
+	"scanner/pkg/tracing"
 	"scanner/pkg/urlscanner"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/riverqueue/river/rivertype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -20,6 +33,23 @@ const (
 	scanResultPollInitialDelay = time.Second
 	scanResultPollIntervalBase = 2 * time.Second
 	scanResultPollIntervalMax  = 10 * time.Minute
+
+	// staleScansPageSize is the number of stale pending scans reconciled per
+	// storage query.
+	staleScansPageSize = 100
+
+	// tracerName is the instrumentation name of the spans of the scanner.
+	tracerName = "scanner/internal/scanner"
+)
+
+// ResultCacheScope selects whose completed results may be reused for new scans.
+type ResultCacheScope string
+
+const (
+	// ResultCacheScopeGlobal reuses shareable results of any user.
+	ResultCacheScopeGlobal ResultCacheScope = "global"
+	// ResultCacheScopeUser only reuses results of the requesting user.
+	ResultCacheScopeUser ResultCacheScope = "user"
 )
 
 // Options configure how scan jobs are enqueued and how results are cached.
@@ -32,126 +62,1130 @@ type Options struct {
 	// scan requests for the same URL reuse that result instead of enqueueing
 	// a duplicate job.
 	ResultCacheTTL time.Duration
+	// MaxURLLength is the maximum length of a normalized URL accepted by
+	// Enqueue. A value <= 0 disables the check.
+	MaxURLLength int
+	// MaxPendingScansPerUser is the maximum number of pending scans a user may
+	// have. Enqueue rejects further scans with a rate-limited error. A value
+	// <= 0 disables the cap.
+	MaxPendingScansPerUser int
+	// PendingScansRetryAfter is the retry hint of errors returned by Enqueue
+	// when MaxPendingScansPerUser is exceeded. Zero sets no hint.
+	PendingScansRetryAfter time.Duration
+	// BlockPrivateHosts makes Enqueue reject URLs whose host is, or resolves
+	// to, a loopback, link-local, private or metadata address.
+	BlockPrivateHosts bool
+	// Resolver resolves host names when BlockPrivateHosts is enabled. When nil,
+	// net.DefaultResolver is used.
+	Resolver Resolver
+	// Clock provides the time scan results are polled with. When nil, the real
+	// clock is used.
+	Clock clock.Clock
+	// TracerProvider creates the spans of enqueued and processed scans. When
+	// nil, the global provider of otel is used.
+	TracerProvider trace.TracerProvider
+	// AllowedDomains restricts Enqueue to matching domains when non-empty.
+	AllowedDomains DomainList
+	// DeniedDomains lists domains Enqueue always rejects. It takes precedence
+	// over AllowedDomains.
+	DeniedDomains DomainList
+	// Queue is the worker queue scan jobs are inserted into. Empty uses the
+	// default queue.
+	Queue string
+	// Priority is the job priority of scan jobs, from 1 (highest) to 4 (lowest).
+	// Zero uses the default priority.
+	Priority int
+	// PriorityQueue is the worker queue scan jobs of PriorityUserIDs are
+	// inserted into.
+	PriorityQueue string
+	// PriorityJobPriority is the job priority of scan jobs of PriorityUserIDs.
+	PriorityJobPriority int
+	// PriorityUserIDs is the set of users (e.g. paid users) whose scans are
+	// routed to PriorityQueue with PriorityJobPriority.
+	PriorityUserIDs map[domain.UserID]struct{}
+	// SlowDomains lists domains that legitimately take longer to scan. Their
+	// scan jobs run with SlowJobTimeout instead of the worker's global timeout.
+	SlowDomains DomainList
+	// SlowJobTimeout is the job timeout of scans of SlowDomains. Zero keeps the
+	// worker's global timeout.
+	SlowJobTimeout time.Duration
+	// ResultBatchInterval enables batched result polling when positive: scans
+	// waiting for their result are polled together with one
+	// urlscanner.Client.Results call per interval instead of each job polling
+	// its own scan.
+	ResultBatchInterval time.Duration
+	// ResultCacheScope selects whose completed results Enqueue may reuse. Empty
+	// means ResultCacheScopeGlobal.
+	ResultCacheScope ResultCacheScope
+	// FailedResultTTL makes Enqueue fail new scans right away with the error of
+	// a scan of the same URL that failed within this duration, instead of
+	// scanning a URL that keeps failing again. Whose failures are reused follows
+	// ResultCacheScope. A value <= 0 disables it.
+	FailedResultTTL time.Duration
+	// Visibility is the default visibility URLs are submitted to urlscan.io
+	// with, unless a scan requests another one. Only results of public scans are
+	// reused for other users; empty means public.
+	Visibility urlscanner.Visibility
+	// Country is the ISO 3166-1 alpha-2 code of the country URLs are scanned
+	// from. Empty lets urlscan.io choose.
+	Country string
+	// ForbidCrossUserAccess makes Result and Delete return a forbidden error
+	// instead of not-found when the scan exists but belongs to another user.
+	ForbidCrossUserAccess bool
 }
 
 // NewOptions constructs an Options value from the provided application config.
+// Entries of cfg.Scanner.PriorityUserIDs that are not valid UUIDs are ignored.
 func NewOptions(cfg *config.Config) Options {
+	priorityUserIDs := make(map[domain.UserID]struct{}, len(cfg.Scanner.PriorityUserIDs))
+	for _, id := range cfg.Scanner.PriorityUserIDs {
+		if userID, err := domain.ParseUserID(id); err == nil {
+			priorityUserIDs[userID] = struct{}{}
+		}
+	}
+
 	return Options{
-		MaxAttempts:    cfg.Scanner.MaxAttempts,
-		ResultCacheTTL: cfg.Scanner.ResultCacheTTL,
+		MaxAttempts:         cfg.Scanner.MaxAttempts,
+		ResultCacheTTL:      cfg.Scanner.ResultCacheTTL,
+		MaxURLLength:        cfg.Scanner.MaxURLLength,
+		BlockPrivateHosts:   cfg.Scanner.BlockPrivateHosts,
+		AllowedDomains:      cfg.Scanner.AllowedDomains,
+		DeniedDomains:       cfg.Scanner.DeniedDomains,
+		Queue:               cfg.Scanner.Queue,
+		Priority:            cfg.Scanner.Priority,
+		PriorityQueue:       cfg.Scanner.PriorityQueue,
+		PriorityJobPriority: cfg.Scanner.PriorityJobPriority,
+		PriorityUserIDs:     priorityUserIDs,
+		SlowDomains:         cfg.Scanner.SlowDomains,
+		SlowJobTimeout:      cfg.Scanner.SlowJobTimeout,
+		ResultBatchInterval: cfg.Scanner.ResultBatchInterval,
+		ResultCacheScope:    ResultCacheScope(cfg.Scanner.ResultCacheScope),
+		FailedResultTTL:     cfg.Scanner.FailedResultTTL,
+		Visibility:          urlscanner.Visibility(cfg.Scanner.Visibility),
+		Country:             cfg.Scanner.Country,
+
+		MaxPendingScansPerUser: cfg.Scanner.MaxPendingScansPerUser,
+		PendingScansRetryAfter: cfg.Scanner.PendingScansRetryAfter,
+		ForbidCrossUserAccess:  cfg.Scanner.ForbidCrossUserAccess,
+	}
+}
+
+// resolver returns the configured Resolver, falling back to net.DefaultResolver.
+func (o Options) resolver() Resolver {
+	if o.Resolver != nil {
+		return o.Resolver
+	}
+
+	return net.DefaultResolver
+}
+
+// clock returns the configured Clock, falling back to the real clock.
+func (o Options) clock() clock.Clock {
+	return clock.OrReal(o.Clock)
+}
+
+// tracer returns the tracer of the configured TracerProvider, falling back to
+// the global provider.
+func (o Options) tracer() trace.Tracer {
+	provider := o.TracerProvider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+
+	return provider.Tracer(tracerName)
+}
+
+// visibility returns the visibility a scan requesting the given one is
+// submitted with: the requested visibility if set, otherwise Visibility and
+// finally public.
+func (o Options) visibility(requested urlscanner.Visibility) urlscanner.Visibility {
+	switch {
+	case requested != "":
+		return requested
+	case o.Visibility != "":
+		return o.Visibility
+	default:
+		return urlscanner.VisibilityPublic
+	}
+}
+
+// shareable reports whether results of scans submitted with the given
+// visibility may be reused for other users.
+func shareable(visibility urlscanner.Visibility) bool {
+	return visibility == urlscanner.VisibilityPublic
+}
+
+// jobOwner returns the owner of the job scanning scan, see JobArgs.Owner: the
+// zero UserID for shareable scans, the user of the scan otherwise.
+func jobOwner(scan *domain.Scan) domain.UserID {
+	if scan.Shareable {
+		return domain.UserID{}
+	}
+
+	return scan.UserID
+}
+
+// jobQueue returns the queue and job priority used for scan jobs requested by
+// the given user.
+func (o Options) jobQueue(userID domain.UserID) (string, int) {
+	if _, ok := o.PriorityUserIDs[userID]; ok {
+		return o.PriorityQueue, o.PriorityJobPriority
+	}
+
+	return o.Queue, o.Priority
+}
+
+// jobTimeout returns the job timeout of scans of the given normalized URL.
+// Zero means the worker's global timeout applies.
+func (o Options) jobTimeout(rawURL string) time.Duration {
+	u, err := url.Parse(rawURL)
+	if err != nil || !o.SlowDomains.Match(u.Hostname()) {
+		return 0
 	}
+
+	return o.SlowJobTimeout
+}
+
+// OptionsHolder holds scanner Options that can be replaced at runtime, e.g.
+// when the configuration is reloaded. It is safe for concurrent use.
+type OptionsHolder struct {
+	options atomic.Pointer[Options]
+}
+
+// NewOptionsHolder returns an OptionsHolder initially holding options.
+func NewOptionsHolder(options Options) *OptionsHolder {
+	h := &OptionsHolder{}
+	h.Store(options)
+
+	return h
+}
+
+// Load returns the current options.
+func (h *OptionsHolder) Load() Options {
+	return *h.options.Load()
+}
+
+// Store atomically replaces the current options. Operations already in
+// progress keep the options they loaded.
+func (h *OptionsHolder) Store(options Options) {
+	h.options.Store(&options)
+}
+
+// Reload replaces the current options with the ones derived from cfg. The
+// Resolver, Clock and TracerProvider of the current options are kept since
+// they are not configurable, and so is Visibility since the urlscan.io client
+// it must match is not reloaded.
+func (h *OptionsHolder) Reload(cfg *config.Config) {
+	current := h.Load()
+	options := NewOptions(cfg)
+	options.Resolver = current.Resolver
+	options.Clock = current.Clock
+	options.TracerProvider = current.TracerProvider
+	options.Visibility = current.Visibility
+	h.Store(options)
 }
 
 // scanner is the concrete implementation of the Scanner interface.
 // It coordinates persistence with the storage layer and job enqueueing.
 type scanner struct {
 	// options holds runtime configuration that affects enqueueing and caching.
-	options Options
+	// It may be replaced at runtime, so each operation should load it once.
+	options *OptionsHolder
 	// storage is the persistence layer used to store scans and manage jobs.
 	storage storage.Storage
 	// urlScanner is the client used to submit scan requests to urlscan.io.
 	urlScanner urlscanner.Client
+	// poller polls results of submitted scans in batches when ResultBatchInterval is positive.
+	poller *resultPoller
+	// int64N returns a random number in [0, n) and is used to add jitter to poll delays.
+	int64N func(n int64) int64
+}
+
+// Jitter randomizes the given backoff delay using "equal jitter": half of the
+// delay is kept and a random amount in [0, delay/2] is added, so the result
+// always falls within [delay/2, delay]. This keeps concurrent jobs that poll on
+// the same schedule from hitting the upstream API in synchronized bursts.
+// int64N must return a random number in [0, n).
+func Jitter(delay time.Duration, int64N func(n int64) int64) time.Duration {
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+
+	return half + time.Duration(int64N(int64(delay-half)+1))
 }
 
 // Enqueue stores a new scan request for the given URL and user, and attempts
-// to enqueue a background job to process it. If a recent completed result exists
-// for the same URL (within ResultCacheTTL), the new scan is immediately marked
-// as completed with that result.
-func (s scanner) Enqueue(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error) {
+// to enqueue a background job to process it. The job is placed in the queue
+// and with the priority configured for the user's tier. If a recent completed
+// result exists for the same URL (within ResultCacheTTL), the new scan is
+// immediately marked as completed with that result. URLs longer than
+// MaxURLLength after normalization, or pointing to private or internal hosts
+// when BlockPrivateHosts is enabled, are rejected with a bad-request error.
+// Domains rejected by DeniedDomains or AllowedDomains yield a forbidden error,
+// and the zero user ID an unauthorized one.
+// When idempotencyKey is non-empty and the user already has a scan stored with
+// the same key, that scan is returned and nothing new is stored or enqueued.
+// Tags exceeding urlscanner.MaxTags or urlscanner.MaxTagLength are rejected
+// with a bad-request error, and so are unknown visibilities. The scan is
+// submitted with the requested visibility, falling back to Options.Visibility
+// and then to public.
+func (s scanner) Enqueue(ctx context.Context,
+	userID domain.UserID,
+	URL, idempotencyKey string,
+	tags []string,
+	visibility urlscanner.Visibility) (_ *domain.Scan, err error) {
+	ctx, span := s.options.Load().tracer().Start(ctx, "scanner.Enqueue")
+	defer tracing.End(span, &err)
+
+	scan, err := s.enqueue(ctx, userID, URL, idempotencyKey, tags, visibility)
+	if scan != nil {
+		span.SetAttributes(attribute.String("scan.status", string(scan.Status)))
+	}
+
+	return scan, err
+}
+
+// enqueue implements Enqueue within its span.
+func (s scanner) enqueue(ctx context.Context,
+	userID domain.UserID,
+	URL, idempotencyKey string,
+	tags []string,
+	visibility urlscanner.Visibility) (*domain.Scan, error) {
+	URL, err := s.checkEnqueue(ctx, userID, URL, urlscanner.SubmitOptions{Tags: tags, Visibility: visibility})
+	if err != nil {
+		return nil, err
+	}
+
 	var scan *domain.Scan
-	URL, err := NormalizeURL(URL)
+	if err := s.storage.WithTx(ctx, func(tx storage.AllStorage) error {
+		scan, err = s.enqueueTx(ctx, tx, userID, URL, idempotencyKey, tags, visibility)
+
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("could not enqueue URL: %w", err)
+	}
+
+	return scan, nil
+}
+
+// EnsureScan returns the user's latest completed scan of the URL if it
+// completed within ResultCacheTTL. Otherwise it enqueues a new scan like
+// Enqueue, without idempotency key and tags, and returns it. The lookup and the
+// enqueue run in a single transaction. URLs are validated like in Enqueue.
+func (s scanner) EnsureScan(ctx context.Context, userID domain.UserID, URL string) (_ *domain.Scan, err error) {
+	options := s.options.Load()
+	ctx, span := options.tracer().Start(ctx, "scanner.EnsureScan")
+	defer tracing.End(span, &err)
+
+	URL, err = s.checkEnqueue(ctx, userID, URL, urlscanner.SubmitOptions{})
 	if err != nil {
-		return nil, serrors.Wrap(serrors.ErrBadRequest, err, "invalid URL")
+		return nil, err
 	}
 
+	var scan *domain.Scan
 	if err := s.storage.WithTx(ctx, func(tx storage.AllStorage) error {
-		res, err := tx.StoreScans(ctx, domain.Scan{
-			UserID: userID,
-			URL:    URL,
-			Status: domain.ScanStatusPending,
-		})
+		last, err := tx.LastCompletedScanByURLForUser(ctx, userID, URL)
 		if err != nil {
-			return fmt.Errorf("could not store scan: %w", err)
+			return fmt.Errorf("could not get last completed scan: %w", err)
+		}
+		if last != nil && options.clock().Now().Sub(last.UpdatedAt) < options.ResultCacheTTL {
+			scan = last
+
+			return nil
+		}
+
+		scan, err = s.enqueueTx(ctx, tx, userID, URL, "", nil, "")
+
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("could not ensure scan: %w", err)
+	}
+	span.SetAttributes(attribute.String("scan.status", string(scan.Status)))
+
+	return scan, nil
+}
+
+// checkEnqueue validates an enqueue request of userID with the given submit
+// options and returns the normalized URL. See Enqueue for the rejected
+// requests.
+func (s scanner) checkEnqueue(
+	ctx context.Context,
+	userID domain.UserID,
+	URL string,
+	opts urlscanner.SubmitOptions,
+) (string, error) {
+	if userID.IsZero() {
+		return "", serrors.With(serrors.ErrUnauthorized, "missing user")
+	}
+	if err := opts.Validate(); err != nil {
+		return "", serrors.Wrap(serrors.ErrBadRequest, err, "invalid submit options")
+	}
+
+	URL, err := NormalizeURL(URL)
+	if err != nil {
+		return "", serrors.Wrap(serrors.ErrBadRequest, err, "invalid URL")
+	}
+	trace.SpanFromContext(ctx).SetAttributes(tracing.URLHash(URL))
+	options := s.options.Load()
+	if options.MaxURLLength > 0 && len(URL) > options.MaxURLLength {
+		return "", serrors.With(serrors.ErrBadRequest, "URL exceeds maximum length of %d", options.MaxURLLength)
+	}
+	if !domainAllowed(URL, options.AllowedDomains, options.DeniedDomains) {
+		return "", serrors.With(serrors.ErrForbidden, "scanning this domain is not allowed")
+	}
+	if options.BlockPrivateHosts {
+		if err := checkHost(ctx, options.resolver(), URL); err != nil {
+			return "", serrors.Wrap(serrors.ErrBadRequest, err, "URL host is not allowed")
+		}
+	}
+
+	return URL, nil
+}
+
+// enqueueTx stores a scan of the already validated URL and adds its job
+// within tx, as described by Enqueue.
+func (s scanner) enqueueTx(ctx context.Context,
+	tx storage.AllStorage,
+	userID domain.UserID,
+	URL, idempotencyKey string,
+	tags []string,
+	visibility urlscanner.Visibility) (*domain.Scan, error) {
+	options := s.options.Load()
+	visibility = options.visibility(visibility)
+	var scan *domain.Scan
+	newScan := domain.Scan{
+		UserID:         userID,
+		URL:            URL,
+		Status:         domain.ScanStatusPending,
+		IdempotencyKey: idempotencyKey,
+		Shareable:      shareable(visibility),
+	}
+	if idempotencyKey != "" {
+		// a retried request with the same idempotency key returns the original scan,
+		// concurrent retries are deduplicated by the database
+		stored, created, err := tx.UpsertScan(ctx, newScan)
+		if err != nil {
+			return nil, fmt.Errorf("could not store scan: %w", err)
+		}
+		if !created {
+			return stored, nil
+		}
+		scan = stored
+	} else {
+		res, err := tx.StoreScans(ctx, newScan)
+		if err != nil {
+			return nil, fmt.Errorf("could not store scan: %w", err)
 		}
 		scan = &res[0]
+	}
+	if options.MaxPendingScansPerUser > 0 {
+		// counted after storing the scan so that concurrent enqueues of the user,
+		// serialized by the count, see each other's scans
+		pending, err := tx.PendingScanCountByUser(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("could not count pending scans: %w", err)
+		}
+		if pending > int64(options.MaxPendingScansPerUser) {
+			return nil, serrors.With(serrors.ErrRateLimited,
+				"too many pending scans, at most %d are allowed", options.MaxPendingScansPerUser).
+				WithRetryAfter(options.PendingScansRetryAfter)
+		}
+	}
+	if err := tx.RecordAudit(ctx, userID, storage.AuditActionCreate, scan.ID); err != nil {
+		return nil, fmt.Errorf("could not record audit entry: %w", err)
+	}
 
-		jobAdded, err := tx.AddJob(ctx, JobArgs{
-			URL:             URL,
-			maxAttempts:     s.options.MaxAttempts,
-			uniqueJobPeriod: s.options.ResultCacheTTL,
-		}, nil)
+	lastFailure, err := s.recentFailure(ctx, tx, userID, URL)
+	if err != nil {
+		return nil, err
+	}
+	if lastFailure != nil {
+		// the URL failed recently, fail the scan with the same error instead of
+		// scanning it again
+		updated, err := tx.UpdateScanByIDForUser(ctx, userID, scan.ID, storage.ScanUpdates{
+			Status:    domain.ScanStatusFailed,
+			LastError: &lastFailure.LastError,
+		})
 		if err != nil {
-			return fmt.Errorf("could not add job: %w", err)
+			return nil, fmt.Errorf("could not update scan: %w", err)
 		}
 
-		// if a job was not added, it means that another job already exists for this URL.
-		// river unique jobs prevent having duplicate jobs for the same URL.
-		if !jobAdded {
-			// if existing jobs is already completed, we should get its result from db and
-			// update the new scan
-			lastResult, err := tx.LastCompletedScanByURL(ctx, URL)
+		return updated, nil
+	}
+
+	jobAdded, err := tx.AddJob(ctx, s.newJobArgs(ctx, userID, URL, tags, visibility), nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not add job: %w", err)
+	}
+
+	// if a job was not added, it means that another job already exists for this URL.
+	// river unique jobs prevent having duplicate jobs for the same URL. Concurrent
+	// enqueues of a URL in separate transactions are serialized by river's unique
+	// index: the later insert waits for the earlier transaction to commit and is
+	// then skipped as a duplicate, so exactly one job is added either way.
+	if !jobAdded {
+		// if existing jobs is already completed, we should get its result from db and
+		// update the new scan
+		var lastResult *domain.Scan
+		if options.ResultCacheScope == ResultCacheScopeUser {
+			lastResult, err = tx.LastCompletedScanByURLForUser(ctx, userID, URL)
+		} else {
+			lastResult, err = tx.LastCompletedScanByURL(ctx, userID, URL)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not get last completed scan: %w", err)
+		}
+
+		if lastResult != nil {
+			updated, err := tx.UpdateScanByIDForUser(ctx, userID, scan.ID, storage.ScanUpdates{
+				Status: domain.ScanStatusCompleted,
+				Result: &lastResult.Result,
+			})
 			if err != nil {
-				return fmt.Errorf("could not get last completed scan: %w", err)
+				return nil, fmt.Errorf("could not update scan: %w", err)
 			}
 
-			if lastResult != nil {
-				updated, err := tx.UpdateScanByID(ctx, scan.ID, storage.ScanUpdates{
-					Status: domain.ScanStatusCompleted,
-					Result: &lastResult.Result,
-				})
-				if err != nil {
-					return fmt.Errorf("could not update scan: %w", err)
-				}
-				scan = updated
-			} // else: the job is in the queue and will be processed soon.
-			// Job will automatically update all pending jobs by URL upon completion.
+			return updated, nil
 		}
 
-		return nil
-	}); err != nil {
-		return nil, fmt.Errorf("could not enqueue URL: %w", err)
+		// the job is in the queue or still running, e.g. it was just added by a
+		// concurrent enqueue, and the scan is completed together with all other
+		// pending scans of the URL once it finishes. Otherwise the job finished
+		// without a result the user may reuse, e.g. of another user or deleted,
+		// and the URL is scanned again. The lookup serializes concurrent enqueues
+		// of the URL, so only the first of them adds that job.
+		job, err := tx.ActiveJobByURL(ctx, JobKind, URL, jobOwner(scan))
+		if err != nil {
+			return nil, fmt.Errorf("could not get active job: %w", err)
+		}
+		if job == nil {
+			if _, err := tx.AddJob(ctx, notUnique(s.newJobArgs(ctx, userID, URL, tags, visibility)), nil); err != nil {
+				return nil, fmt.Errorf("could not add job: %w", err)
+			}
+		}
 	}
 
 	return scan, nil
 }
 
+// recentFailure returns the latest scan of the URL the user may reuse if it
+// failed within FailedResultTTL, or nil otherwise. Only processed scans are
+// looked up, so the TTL runs from the attempt that actually failed rather than
+// from the scans that reused it. With ResultCacheScopeUser, scans of other
+// users are ignored, so that they neither are reused nor hide the user's own
+// failure.
+func (s scanner) recentFailure(
+	ctx context.Context,
+	tx storage.AllStorage,
+	userID domain.UserID,
+	URL string,
+) (*domain.Scan, error) {
+	options := s.options.Load()
+	if options.FailedResultTTL <= 0 {
+		return nil, nil
+	}
+
+	var last *domain.Scan
+	var err error
+	if options.ResultCacheScope == ResultCacheScopeUser {
+		last, err = tx.LastScanByURLForUser(ctx, userID, URL)
+	} else {
+		last, err = tx.LastScanByURL(ctx, userID, URL)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get last scan: %w", err)
+	}
+	if last == nil || last.Status != domain.ScanStatusFailed ||
+		options.clock().Now().Sub(last.UpdatedAt) >= options.FailedResultTTL {
+		return nil, nil
+	}
+
+	return last, nil
+}
+
+// scanStatuses lists the statuses reported by StatusCounts.
+var scanStatuses = []domain.ScanStatus{ //nolint: gochecknoglobals
+	domain.ScanStatusPending,
+	domain.ScanStatusCompleted,
+	domain.ScanStatusFailed,
+	domain.ScanStatusCanceled,
+}
+
+// StatusCounts returns the number of scans of the given user per status.
+// Statuses without scans are reported with a zero count.
+func (s scanner) StatusCounts(ctx context.Context, userID domain.UserID) (map[domain.ScanStatus]int64, error) {
+	counts, err := s.storage.ScanStatusCounts(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not count scans by status: %w", err)
+	}
+
+	res := make(map[domain.ScanStatus]int64, len(scanStatuses))
+	for _, status := range scanStatuses {
+		res[status] = counts[status]
+	}
+
+	return res, nil
+}
+
+// parseTimeCursor parses an RFC3339 cursor into a time in UTC, the zone
+// created_at is stored in, so that cursors echoed with another offset mark
+// the same boundary. An empty cursor yields the zero time.
+func parseTimeCursor(cursor string) (time.Time, error) {
+	if cursor == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, cursor)
+	if err != nil {
+		return time.Time{}, serrors.Wrap(serrors.ErrBadRequest, err, "invalid cursor")
+	}
+
+	return t.UTC(), nil
+}
+
 // UserScans returns a page of scans for the given user filtered by status.
-// It supports cursor-based pagination using an RFC3339 timestamp string and
-// returns the next cursor when more results are available.
+// Unknown statuses are rejected with a bad request error. It supports
+// cursor-based pagination using an RFC3339 timestamp string and returns the
+// next cursor when more scans are available. With storage.PagePrev it pages
+// back to newer scans instead, and returns the previous cursor when even newer
+// ones exist.
 func (s scanner) UserScans(ctx context.Context,
 	userID domain.UserID,
 	status domain.ScanStatus,
+	maliciousOnly bool,
+	cursor string,
+	direction storage.PageDirection,
+	limit uint) ([]domain.Scan, string, string, error) {
+	if status != "" && !status.Valid() {
+		return nil, "", "", serrors.With(serrors.ErrBadRequest, "invalid status %q", status)
+	}
+	if direction == "" {
+		direction = storage.PageNext
+	}
+	if !direction.Valid() {
+		return nil, "", "", serrors.With(serrors.ErrBadRequest, "invalid page direction %q", direction)
+	}
+
+	cursorTime, err := parseTimeCursor(cursor)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	page, err := s.storage.UserScans(ctx, userID, status, maliciousOnly, cursorTime, direction, limit)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("could not get user scans: %w", err)
+	}
+
+	// cursors keep fractional seconds, so that paging back and forth lands on
+	// the same scans
+	var next, prev string
+	if page.NextCursor != nil {
+		next = page.NextCursor.Format(time.RFC3339Nano)
+	}
+	if page.PrevCursor != nil {
+		prev = page.PrevCursor.Format(time.RFC3339Nano)
+	}
+
+	return page.Scans, next, prev, nil
+}
+
+// SearchScans returns a page of scans of the given user whose result page has
+// value for key, e.g. the IP address or ASN. Unknown keys and empty values are
+// rejected with a bad request error; cursors work the same as in UserScans.
+func (s scanner) SearchScans(ctx context.Context,
+	userID domain.UserID,
+	key domain.ScanSearchKey,
+	value string,
 	cursor string,
 	limit uint) ([]domain.Scan, string, error) {
-	var cursorTime time.Time
-	if cursor != "" {
-		t, err := time.Parse(time.RFC3339, cursor)
+	if !key.Valid() {
+		return nil, "", serrors.With(serrors.ErrBadRequest, "invalid search key %q", key)
+	}
+	if value == "" {
+		return nil, "", serrors.With(serrors.ErrBadRequest, "search value must not be empty")
+	}
+
+	cursorTime, err := parseTimeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	page, err := s.storage.SearchScans(ctx, userID, key, value, cursorTime, limit)
+	if err != nil {
+		return nil, "", fmt.Errorf("could not search scans: %w", err)
+	}
+
+	var next string
+	if page.NextCursor != nil {
+		next = page.NextCursor.Format(time.RFC3339Nano)
+	}
+
+	return page.Scans, next, nil
+}
+
+// AdminScans returns a page of scans across all users matching the filter.
+// The filter URL is normalized and cursors work the same as in UserScans.
+func (s scanner) AdminScans(ctx context.Context,
+	filter AdminScanFilter,
+	cursor string,
+	limit uint) ([]domain.Scan, string, error) {
+	if filter.Status != "" && !filter.Status.Valid() {
+		return nil, "", serrors.With(serrors.ErrBadRequest, "invalid status %q", filter.Status)
+	}
+
+	storageFilter := storage.AdminScanFilter{
+		Status:        filter.Status,
+		CreatedAfter:  filter.CreatedAfter,
+		CreatedBefore: filter.CreatedBefore,
+		Limit:         limit,
+	}
+	if filter.URL != "" {
+		URL, err := NormalizeURL(filter.URL)
 		if err != nil {
-			return nil, "", serrors.Wrap(serrors.ErrBadRequest, err, "invalid cursor")
+			return nil, "", serrors.Wrap(serrors.ErrBadRequest, err, "invalid URL")
 		}
-		cursorTime = t
+		storageFilter.URL = URL
+	}
+	cursorTime, err := parseTimeCursor(cursor)
+	if err != nil {
+		return nil, "", err
 	}
+	storageFilter.Cursor = cursorTime
 
-	page, err := s.storage.UserScans(ctx, userID, status, cursorTime, limit)
+	page, err := s.storage.AdminListScans(ctx, storageFilter)
 	if err != nil {
-		return nil, "", fmt.Errorf("could not get user scans: %w", err)
+		return nil, "", fmt.Errorf("could not list scans: %w", err)
 	}
 
 	var next string
 	if page.NextCursor != nil {
-		next = page.NextCursor.Format(time.RFC3339)
+		next = page.NextCursor.Format(time.RFC3339Nano)
 	}
 
 	return page.Scans, next, nil
 }
 
+// AdminJobs returns a page of queued jobs filtered by state. It supports
+// cursor-based pagination using a job ID string and returns the next cursor
+// when more jobs are available.
+func (s scanner) AdminJobs(ctx context.Context,
+	state rivertype.JobState,
+	cursor string,
+	limit uint) ([]storage.Job, string, error) {
+	var cursorID int64
+	if cursor != "" {
+		id, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, "", serrors.With(serrors.ErrBadRequest, "invalid cursor")
+		}
+		cursorID = id
+	}
+
+	page, err := s.storage.ListJobs(ctx, state, limit, cursorID)
+	if err != nil {
+		return nil, "", fmt.Errorf("could not list jobs: %w", err)
+	}
+
+	var next string
+	if page.NextCursor != nil {
+		next = strconv.FormatInt(*page.NextCursor, 10)
+	}
+
+	return page.Jobs, next, nil
+}
+
+// AuditLog returns a page of audit log entries matching the filter. Cursors
+// are entry IDs and work the same as in AdminJobs.
+func (s scanner) AuditLog(ctx context.Context,
+	filter AuditFilter,
+	cursor string,
+	limit uint) ([]storage.AuditEntry, string, error) {
+	if filter.Action != "" && !filter.Action.Valid() {
+		return nil, "", serrors.With(serrors.ErrBadRequest, "invalid action %q", filter.Action)
+	}
+
+	storageFilter := storage.AuditFilter{
+		UserID: filter.UserID,
+		ScanID: filter.ScanID,
+		Action: filter.Action,
+		Limit:  limit,
+	}
+	if cursor != "" {
+		id, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, "", serrors.With(serrors.ErrBadRequest, "invalid cursor")
+		}
+		storageFilter.Cursor = id
+	}
+
+	page, err := s.storage.AuditLog(ctx, storageFilter)
+	if err != nil {
+		return nil, "", fmt.Errorf("could not list audit log: %w", err)
+	}
+
+	var next string
+	if page.NextCursor != nil {
+		next = strconv.FormatInt(*page.NextCursor, 10)
+	}
+
+	return page.Entries, next, nil
+}
+
+// newJobArgs returns the arguments of a scan job for the given URL and tags,
+// routed to the queue of the requesting user and with the job timeout of its
+// domain. The trace context of ctx is passed on to the job.
+func (s scanner) newJobArgs(
+	ctx context.Context,
+	userID domain.UserID,
+	URL string,
+	tags []string,
+	visibility urlscanner.Visibility,
+) JobArgs {
+	options := s.options.Load()
+	queue, priority := options.jobQueue(userID)
+
+	args := NewJobArgs(URL, tags, JobOptions{
+		MaxAttempts:     options.MaxAttempts,
+		UniqueJobPeriod: options.ResultCacheTTL,
+		Queue:           queue,
+		Priority:        priority,
+		Timeout:         options.jobTimeout(URL),
+	})
+	// jobs of the default visibility are submitted with the client's default and
+	// keep the unique key of jobs enqueued before visibilities could be requested
+	if visibility != options.visibility("") {
+		args.Visibility = visibility
+	}
+	// results of private and unlisted jobs only complete the scans of their owner
+	if !shareable(visibility) {
+		args.Owner = userID.String()
+	}
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	if len(carrier) > 0 {
+		args.TraceContext = carrier
+	}
+
+	return args
+}
+
+// notUnique returns args inserted without uniqueness constraints, for scanning
+// a URL again whose unique job already finished within ResultCacheTTL.
+func notUnique(args JobArgs) JobArgs {
+	args.options.NotUnique = true
+
+	return args
+}
+
+// pendingScan fetches a scan of any user and ensures it is still pending.
+func pendingScan(ctx context.Context, st storage.AllStorage, scanID domain.ScanID) (*domain.Scan, error) {
+	scan, err := st.AdminScanByID(ctx, scanID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get scan: %w", err)
+	}
+	if scan == nil {
+		return nil, serrors.With(serrors.ErrNotFound, "scan not found")
+	}
+	if scan.IsTerminal() {
+		return nil, serrors.With(serrors.ErrConflict, "scan is %s, not pending", strings.ToLower(string(scan.Status)))
+	}
+
+	return scan, nil
+}
+
+// Requeue enqueues a new urgent job for a pending scan whose job was lost. If River
+// skips the job as a duplicate and a job for the URL is still active, a conflict
+// error is returned; the scan will be updated once that job finishes. Otherwise
+// the duplicate is a finished job which did not complete the scan, and the job
+// is added without uniqueness constraints.
+func (s scanner) Requeue(ctx context.Context, scanID domain.ScanID) (*domain.Scan, error) {
+	var scan *domain.Scan
+	if err := s.storage.WithTx(ctx, func(tx storage.AllStorage) error {
+		var err error
+		scan, err = pendingScan(ctx, tx, scanID)
+		if err != nil {
+			return err
+		}
+
+		// tags and visibility are not stored with the scan, so the requeued job is
+		// submitted without tags and privately unless its result is shareable
+		visibility := urlscanner.VisibilityPrivate
+		if scan.Shareable {
+			visibility = urlscanner.VisibilityPublic
+		}
+		args := s.newJobArgs(ctx, scan.UserID, scan.URL, nil, visibility)
+		// requeued scans were already delayed, let them skip ahead of other jobs
+		args.Urgent = true
+		jobAdded, err := tx.AddJob(ctx, args, nil)
+		if err != nil {
+			return fmt.Errorf("could not add job: %w", err)
+		}
+		if jobAdded {
+			return nil
+		}
+
+		job, err := tx.ActiveJobByURL(ctx, JobKind, scan.URL, jobOwner(scan))
+		if err != nil {
+			return fmt.Errorf("could not get active job: %w", err)
+		}
+		if job != nil {
+			return serrors.With(serrors.ErrConflict, "a job for the scan URL is still active")
+		}
+		if _, err := tx.AddJob(ctx, notUnique(args), nil); err != nil {
+			return fmt.Errorf("could not add job: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("could not requeue scan: %w", err)
+	}
+
+	return scan, nil
+}
+
+// ForceFail marks a pending scan as failed with the given reason, e.g. when
+// its job is stuck and should not be retried.
+func (s scanner) ForceFail(ctx context.Context, scanID domain.ScanID, reason string) (*domain.Scan, error) {
+	var scan *domain.Scan
+	if err := s.storage.WithTx(ctx, func(tx storage.AllStorage) error {
+		pending, err := pendingScan(ctx, tx, scanID)
+		if err != nil {
+			return err
+		}
+
+		// fail with a conflict if the worker updated the scan in the meantime
+		scan, err = tx.UpdateScanByID(ctx, scanID, storage.ScanUpdates{
+			Status:          domain.ScanStatusFailed,
+			LastError:       &reason,
+			ExpectedVersion: pending.Version,
+		})
+		if err != nil {
+			return fmt.Errorf("could not update scan: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("could not fail scan: %w", err)
+	}
+
+	return scan, nil
+}
+
+// ReconcileStaleScans recovers stale pending scans that have no active job.
+// Scans that used up MaxAttempts are failed, guarded by their version so that
+// concurrent updates of the worker win, while the others get a new job. All
+// pending scans of a URL with the same job owner share its job, so each of
+// them is requeued at most once.
+func (s scanner) ReconcileStaleScans(ctx context.Context, olderThan time.Time) (int, int, error) {
+	options := s.options.Load()
+	var stale, requeued, failed int
+	type jobKey struct {
+		URL   string
+		owner domain.UserID
+	}
+	// hasJob caches whether a URL has an active job of an owner, including the
+	// ones added here
+	hasJob := make(map[jobKey]bool)
+	// the scans are paged so that a large backlog is not loaded at once; the
+	// pages are keyed by the last scan, so failed and requeued scans do not
+	// shift them
+	var after *domain.Scan
+	for {
+		scans, err := s.storage.StalePendingScans(ctx, olderThan, after, staleScansPageSize)
+		if err != nil {
+			return requeued, failed, fmt.Errorf("could not get stale pending scans: %w", err)
+		}
+
+		for _, scan := range scans {
+			key := jobKey{URL: scan.URL, owner: jobOwner(&scan)}
+			active, ok := hasJob[key]
+			if !ok {
+				job, err := s.storage.ActiveJobByURL(ctx, JobKind, key.URL, key.owner)
+				if err != nil {
+					return requeued, failed, fmt.Errorf("could not get active job of scan: %w", err)
+				}
+				active = job != nil
+				hasJob[key] = active
+			}
+			if active {
+				continue
+			}
+
+			if options.MaxAttempts > 0 && scan.Attempts >= uint(options.MaxAttempts) {
+				reason := "scan job was lost after its last attempt"
+				if _, err := s.storage.UpdateScanByID(ctx, scan.ID, storage.ScanUpdates{
+					Status:          domain.ScanStatusFailed,
+					LastError:       &reason,
+					ExpectedVersion: scan.Version,
+				}); err != nil {
+					if errors.Is(err, storage.ErrVersionMismatch) {
+						continue
+					}
+
+					return requeued, failed, fmt.Errorf("could not fail stale scan: %w", err)
+				}
+				failed++
+
+				continue
+			}
+
+			// tags and visibility are not stored with the scan, see Requeue
+			visibility := urlscanner.VisibilityPrivate
+			if scan.Shareable {
+				visibility = urlscanner.VisibilityPublic
+			}
+			args := s.newJobArgs(ctx, scan.UserID, scan.URL, nil, visibility)
+			jobAdded, err := s.storage.AddJob(ctx, args, nil)
+			if err != nil {
+				return requeued, failed, fmt.Errorf("could not add job: %w", err)
+			}
+			if !jobAdded {
+				// the scan has no active job, so River skipped the job as a duplicate
+				// of a finished one which did not complete the scan
+				if _, err := s.storage.AddJob(ctx, notUnique(args), nil); err != nil {
+					return requeued, failed, fmt.Errorf("could not add job: %w", err)
+				}
+			}
+			hasJob[key] = true
+			requeued++
+		}
+
+		stale += len(scans)
+		if len(scans) < staleScansPageSize {
+			break
+		}
+		after = &scans[len(scans)-1]
+	}
+
+	if requeued > 0 || failed > 0 {
+		logger.Info(ctx, "reconciled stale pending scans",
+			zap.Int("stale", stale), zap.Int("requeued", requeued), zap.Int("failed", failed))
+	}
+
+	return requeued, failed, nil
+}
+
 // Result fetches a single scan by ID for the given user. It returns a
-// not-found error when no matching scan exists.
+// not-found error when no matching scan exists, or a forbidden error when
+// ForbidCrossUserAccess is enabled and the scan belongs to another user.
 func (s scanner) Result(ctx context.Context, userID domain.UserID, scanID domain.ScanID) (*domain.Scan, error) {
 	res, err := s.storage.ScanByID(ctx, userID, scanID)
 	if err != nil {
 		return nil, fmt.Errorf("could not get scan results: %w", err)
 	}
+	if res == nil {
+		return nil, s.scanNotFound(ctx, scanID)
+	}
+
+	if err := s.setNextRetryAt(ctx, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Attempts returns the recorded processing attempts of a single scan of the
+// given user, oldest first. It returns the same errors as Result when the scan
+// does not exist or belongs to another user.
+func (s scanner) Attempts(ctx context.Context, userID domain.UserID, scanID domain.ScanID) ([]storage.ScanAttempt, error) {
+	res, err := s.storage.ScanByID(ctx, userID, scanID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get scan: %w", err)
+	}
+	if res == nil {
+		return nil, s.scanNotFound(ctx, scanID)
+	}
+
+	attempts, err := s.storage.ScanAttemptsByID(ctx, scanID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get scan attempts: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// setNextRetryAt populates NextRetryAt of a pending scan from the active job of
+// its URL and job owner, i.e. the job whose result completes it. Finalized
+// scans are never retried, so no job is looked up for them, and running jobs
+// have no next run time yet.
+func (s scanner) setNextRetryAt(ctx context.Context, scan *domain.Scan) error {
+	if scan.Status != domain.ScanStatusPending {
+		return nil
+	}
+
+	job, err := s.storage.ActiveJobByURL(ctx, JobKind, scan.URL, jobOwner(scan))
+	if err != nil {
+		return fmt.Errorf("could not get active job: %w", err)
+	}
+	if job != nil && job.State != rivertype.JobStateRunning {
+		scan.NextRetryAt = job.ScheduledAt
+	}
+
+	return nil
+}
+
+// Results fetches multiple scans of the user with a single storage query and
+// returns them in the order of scanIDs, without duplicates.
+func (s scanner) Results(ctx context.Context, userID domain.UserID, scanIDs []domain.ScanID) ([]domain.Scan, error) {
+	ids := make([]domain.ScanID, 0, len(scanIDs))
+	seen := make(map[domain.ScanID]struct{}, len(scanIDs))
+	for _, id := range scanIDs {
+		if _, ok := seen[id]; !ok {
+			seen[id] = struct{}{}
+			ids = append(ids, id)
+		}
+	}
+
+	found, err := s.storage.ScansByIDs(ctx, userID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("could not get scans: %w", err)
+	}
+
+	byID := make(map[domain.ScanID]domain.Scan, len(found))
+	for _, scan := range found {
+		byID[scan.ID] = scan
+	}
+
+	scans := make([]domain.Scan, 0, len(found))
+	for _, id := range ids {
+		if scan, ok := byID[id]; ok {
+			scans = append(scans, scan)
+		}
+	}
+
+	return scans, nil
+}
+
+// scanNotFound returns the error reported when a scan is not found for the
+// requesting user. With ForbidCrossUserAccess, scans owned by other users
+// yield a forbidden error instead of a not-found one.
+func (s scanner) scanNotFound(ctx context.Context, scanID domain.ScanID) error {
+	if s.options.Load().ForbidCrossUserAccess {
+		exists, err := s.storage.ScanExists(ctx, scanID)
+		if err != nil {
+			return fmt.Errorf("could not check scan existence: %w", err)
+		}
+		if exists {
+			return serrors.With(serrors.ErrForbidden, "scan belongs to another user")
+		}
+	}
+
+	return serrors.With(serrors.ErrNotFound, "scan not found")
+}
+
+// LatestByURL normalizes the URL and returns the user's most recent scan of
+// it. It returns a not-found error when the user has no scan for the URL.
+func (s scanner) LatestByURL(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error) {
+	URL, err := NormalizeURL(URL)
+	if err != nil {
+		return nil, serrors.Wrap(serrors.ErrBadRequest, err, "invalid URL")
+	}
+
+	res, err := s.storage.LatestScanByURLForUser(ctx, userID, URL)
+	if err != nil {
+		return nil, fmt.Errorf("could not get latest scan: %w", err)
+	}
 	if res == nil {
 		return nil, serrors.With(serrors.ErrNotFound, "scan not found")
 	}
@@ -159,16 +1193,32 @@ func (s scanner) Result(ctx context.Context, userID domain.UserID, scanID domain
 	return res, nil
 }
 
-// Delete removes a scan belonging to the given user. If the scan does not
-// exist, a not-found error is returned. Jobs are not cancelled here because
+// Delete removes a scan belonging to the given user; pending scans are
+// recorded as canceled. If the scan does not exist, a not-found error is
+// returned (or a forbidden one, see Result). The deletion is recorded in the
+// audit log within the same transaction. Jobs are not cancelled here because
 // other pending scans may still depend on the same URL job.
 func (s scanner) Delete(ctx context.Context, userID domain.UserID, scanID domain.ScanID) error {
-	res, err := s.storage.DeleteScan(ctx, userID, scanID)
-	if err != nil {
+	var deleted bool
+	if err := s.storage.WithTx(ctx, func(tx storage.AllStorage) error {
+		res, err := tx.DeleteScan(ctx, userID, scanID)
+		if err != nil {
+			return fmt.Errorf("could not delete scan: %w", err)
+		}
+		if res == nil {
+			return nil
+		}
+		deleted = true
+		if err := tx.RecordAudit(ctx, userID, storage.AuditActionDelete, scanID); err != nil {
+			return fmt.Errorf("could not record audit entry: %w", err)
+		}
+
+		return nil
+	}); err != nil {
 		return fmt.Errorf("could not delete scan: %w", err)
 	}
-	if res == nil {
-		return serrors.With(serrors.ErrNotFound, "scan not found")
+	if !deleted {
+		return s.scanNotFound(ctx, scanID)
 	}
 
 	// we don't delete jobs from the queue here because there might be other scans depending on the job.
@@ -194,10 +1244,39 @@ func (s scanner) Delete(ctx context.Context, userID domain.UserID, scanID domain
 //
 // This method is designed to be invoked by a background worker and to be
 // idempotent with respect to concurrently deleted scan requests.
-func (s scanner) Scan(ctx context.Context, URL string) (urlscanner.RateLimitStatus, error) {
+func (s scanner) Scan(
+	ctx context.Context,
+	URL string,
+	owner domain.UserID,
+	opts urlscanner.SubmitOptions,
+) (_ urlscanner.RateLimitStatus, err error) {
+	ctx, span := s.options.Load().tracer().Start(ctx, "scanner.Scan", trace.WithAttributes(tracing.URLHash(URL)))
+	defer tracing.End(span, &err)
+
+	return s.scan(ctx, URL, owner, opts)
+}
+
+// Close stops the result poller; see resultPoller.close.
+func (s scanner) Close() {
+	s.poller.close()
+}
+
+// scan implements Scan within its span.
+func (s scanner) scan(
+	ctx context.Context,
+	URL string,
+	owner domain.UserID,
+	opts urlscanner.SubmitOptions,
+) (urlscanner.RateLimitStatus, error) {
+	// scanners built without a client can only enqueue and read scans
+	if s.urlScanner == nil {
+		return urlscanner.RateLimitStatus{}, serrors.With(serrors.ErrInternal,
+			"scanner has no urlscanner client configured, can not scan URLs")
+	}
+
 	// makes sure there are still pending scans for the URL before processing,
 	// this is required because during scan deletion we do not cancel jobs
-	pendingCount, err := s.storage.PendingScanCountByURL(ctx, URL)
+	pendingCount, err := s.storage.PendingScanCountByURL(ctx, URL, owner)
 	if err != nil {
 		return urlscanner.RateLimitStatus{}, fmt.Errorf("could not get pending scan count: %w", err)
 	}
@@ -207,14 +1286,22 @@ func (s scanner) Scan(ctx context.Context, URL string) (urlscanner.RateLimitStat
 		return urlscanner.RateLimitStatus{}, serrors.With(serrors.ErrConflict, "no pending scans for URL")
 	}
 
-	res, RLStatus, err := s.submitURLAndPoll(ctx, URL)
+	res, RLStatus, err := s.submitURLAndPoll(ctx, URL, opts)
 	if err != nil {
+		// rate-limited attempts are recorded too, but the job is snoozed
+		// instead of failing the scans
+		lastErr := err.Error()
+		if err := s.storage.RecordScanAttempts(ctx, URL, owner, storage.ScanAttempt{
+			Error:     lastErr,
+			RateLimit: RLStatus,
+		}); err != nil {
+			logger.Error(ctx, "error recording scan attempt", zap.Error(err))
+		}
 		if !errors.Is(err, serrors.ErrRateLimited) {
-			lastErr := err.Error()
-			if err := s.storage.UpdatePendingScansByURL(ctx, URL, storage.ScanUpdates{
+			if _, err := s.storage.UpdatePendingScansByURL(ctx, URL, owner, storage.ScanUpdates{
 				Status:      domain.ScanStatusFailed,
 				LastError:   &lastErr,
-				MaxAttempts: s.options.MaxAttempts,
+				MaxAttempts: s.options.Load().MaxAttempts,
 			}); err != nil {
 				// just log the error and continue
 				logger.Error(ctx, "error updating scan", zap.Error(err))
@@ -224,18 +1311,62 @@ func (s scanner) Scan(ctx context.Context, URL string) (urlscanner.RateLimitStat
 		return RLStatus, err
 	}
 
-	if err := s.storage.UpdatePendingScansByURL(ctx, URL, storage.ScanUpdates{
-		Status: domain.ScanStatusCompleted,
-		Result: res,
-	}); err != nil {
-		return RLStatus, fmt.Errorf("could not update scan: %w", err)
+	if err := s.storeResult(ctx, URL, owner, res, RLStatus); err != nil {
+		return RLStatus, err
 	}
 
 	return RLStatus, nil
 }
 
+// storeResult completes the pending scans of the URL that the job of owner
+// scans with the given result and records the successful attempt, reporting
+// RLStatus, for each of them. The pending count is re-checked and the update
+// is done in one transaction.
+// Completing no scan, e.g. because all of them were deleted while the URL was
+// being scanned, is a benign no-op: the result is dropped and only logged.
+func (s scanner) storeResult(
+	ctx context.Context,
+	URL string,
+	owner domain.UserID,
+	res *domain.ScanResult,
+	RLStatus urlscanner.RateLimitStatus,
+) error {
+	if err := s.storage.WithTx(ctx, func(tx storage.AllStorage) error {
+		pendingCount, err := tx.PendingScanCountByURL(ctx, URL, owner)
+		if err != nil {
+			return fmt.Errorf("could not get pending scan count: %w", err)
+		}
+		if pendingCount <= 0 {
+			logger.Info(ctx, "no pending scans left for URL, dropping result")
+
+			return nil
+		}
+
+		if err := tx.RecordScanAttempts(ctx, URL, owner, storage.ScanAttempt{RateLimit: RLStatus}); err != nil {
+			return fmt.Errorf("could not record scan attempt: %w", err)
+		}
+		updated, err := tx.UpdatePendingScansByURL(ctx, URL, owner, storage.ScanUpdates{
+			Status: domain.ScanStatusCompleted,
+			Result: res,
+		})
+		if err != nil {
+			return fmt.Errorf("could not update pending scans: %w", err)
+		}
+		if updated == 0 {
+			logger.Info(ctx, "no pending scans updated for URL, dropping result")
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("could not update scan: %w", err)
+	}
+
+	return nil
+}
+
 // submitURLAndPoll submits the URL to the urlscanner provider and polls for
-// the final result using exponential backoff until success or timeout.
+// the final result using exponential backoff until success or timeout. Each
+// poll checks the result Status first and only fetches the Result once ready.
 //
 // On success, it returns the scan result along with the provider's
 // urlscanner.RateLimitStatus. On failure (submission error, polling error that
@@ -248,37 +1379,62 @@ func (s scanner) Scan(ctx context.Context, URL string) (urlscanner.RateLimitStat
 //   - scanResultPollIntervalBase: starting backoff interval
 //   - scanResultPollIntervalMax: maximum backoff interval cap
 //   - scanResultPollTimeout: overall timeout for the polling operation
+//
+// Each backoff interval is randomized with Jitter before sleeping. With a
+// positive ResultBatchInterval, the result is polled by the shared
+// resultPoller instead, together with the results of other jobs.
 func (s scanner) submitURLAndPoll(
 	ctx context.Context,
 	URL string,
-) (*domain.ScanResult, urlscanner.RateLimitStatus, error) {
+	opts urlscanner.SubmitOptions,
+) (result *domain.ScanResult, RLStatus urlscanner.RateLimitStatus, err error) {
+	options := s.options.Load()
+	tracer := options.tracer()
+	ctx, span := tracer.Start(ctx, "scanner.submitURLAndPoll")
+	defer tracing.End(span, &err)
+
+	clk := options.clock()
+	if opts.Country == "" {
+		opts.Country = options.Country
+	}
+
 	logger.Info(ctx, "submitting URL to urlscanner")
-	scanRes, RLStatus, err := s.urlScanner.SubmitURL(ctx, URL)
+	submitCtx, submitSpan := tracer.Start(ctx, "scanner.submit")
+	scanRes, RLStatus, err := s.urlScanner.SubmitURL(submitCtx, URL, opts)
+	submitSpan.SetAttributes(attribute.Int("ratelimit.remaining", RLStatus.Remaining))
+	tracing.End(submitSpan, &err)
 	if err != nil {
 		return nil, RLStatus, fmt.Errorf("could not submit URL: %w", err)
 	}
+	span.SetAttributes(attribute.String("scan.provider_id", scanRes.ID))
 
 	// initial delay
-	time.Sleep(scanResultPollInitialDelay)
+	clk.Sleep(scanResultPollInitialDelay)
 	// poll for results until timeout
 	ctx, cancel := context.WithTimeout(ctx, scanResultPollTimeout)
 	defer cancel()
+
+	if options.ResultBatchInterval > 0 {
+		waitCtx, waitSpan := tracer.Start(ctx, "scanner.waitBatchedResult")
+		result, err = s.poller.wait(waitCtx, scanRes.ID)
+		tracing.End(waitSpan, &err)
+
+		return result, RLStatus, err
+	}
+
 	// start delay with the base interval
 	delay := scanResultPollIntervalBase
 
-	for {
-		logger.Debug(ctx, "reading results from urlscanner")
-		result, err := s.urlScanner.Result(ctx, scanRes.ID)
-		if err == nil {
-			logger.Debug(ctx, "received results from urlscanner")
-
+	for attempt := 1; ; attempt++ {
+		result, err = s.pollResult(ctx, tracer, scanRes.ID, attempt)
+		if err != nil {
+			logger.Debug(ctx, "error reading results from urlscanner, will retry...", zap.Error(err))
+		} else if result != nil {
 			return result, RLStatus, nil
 		}
 
-		logger.Debug(ctx, "error reading results from urlscanner, will retry...", zap.Error(err))
-
 		select {
-		case <-time.After(delay):
+		case <-clk.After(Jitter(delay, s.int64N)):
 			// double delay each time with a cap
 			delay = min(delay*2, scanResultPollIntervalMax)
 		case <-ctx.Done():
@@ -287,12 +1443,53 @@ func (s scanner) submitURLAndPoll(
 	}
 }
 
+// pollResult checks the status of the scan once, in its own span, and fetches
+// its result when ready. A nil result without error means the scan is not
+// finished yet.
+func (s scanner) pollResult(
+	ctx context.Context,
+	tracer trace.Tracer,
+	scanID string,
+	attempt int,
+) (result *domain.ScanResult, err error) {
+	ctx, span := tracer.Start(ctx, "scanner.poll", trace.WithAttributes(attribute.Int("poll.attempt", attempt)))
+	defer tracing.End(span, &err)
+
+	logger.Debug(ctx, "checking result status on urlscanner")
+	ready, err := s.urlScanner.Status(ctx, scanID)
+	if err != nil {
+		return nil, err //nolint: wrapcheck
+	}
+	span.SetAttributes(attribute.Bool("scan.ready", ready))
+	if !ready {
+		return nil, nil
+	}
+
+	logger.Debug(ctx, "reading results from urlscanner")
+	result, err = s.urlScanner.Result(ctx, scanID)
+	if err != nil {
+		return nil, err //nolint: wrapcheck
+	}
+	logger.Debug(ctx, "received results from urlscanner")
+
+	return result, nil
+}
+
 // New creates a new Scanner instance backed by the provided storage and
-// configured with the given options.
+// configured with the given options. URLScanner may be nil for a Scanner that
+// only enqueues and reads scans; its Scan then returns an internal error.
 func New(storage storage.Storage, URLScanner urlscanner.Client, options Options) Scanner {
+	return NewWithOptionsHolder(storage, URLScanner, NewOptionsHolder(options))
+}
+
+// NewWithOptionsHolder creates a new Scanner instance like New, reading its
+// options from holder on every operation so they can be reloaded at runtime.
+func NewWithOptionsHolder(storage storage.Storage, URLScanner urlscanner.Client, holder *OptionsHolder) Scanner {
 	return &scanner{
-		options:    options,
+		options:    holder,
 		storage:    storage,
 		urlScanner: URLScanner,
+		poller:     newResultPoller(URLScanner, holder.Load),
+		int64N:     rand.Int64N, //nolint: gosec
 	}
 }
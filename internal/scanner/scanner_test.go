@@ -3,14 +3,22 @@ package scanner_test
 import (
 	"context"
 	"errors"
+	"scanner/internal/config"
 	"scanner/internal/scanner"
+	"scanner/pkg/clock"
 	"scanner/pkg/logger"
 	mockurlscanner "scanner/pkg/urlscanner/mock"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	mockstorage "scanner/pkg/storage/mock"
 
+	"github.com/google/uuid"
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
 	"go.uber.org/mock/gomock"
 
 	"scanner/pkg/domain"
@@ -32,14 +40,57 @@ func newTestScanner(t *testing.T) (
 	scanner.Scanner) {
 	t.Helper()
 
+	ctrl, st, urlClient, s, _ := newTestScannerWithClock(t)
+
+	return ctrl, st, urlClient, s
+}
+
+// newTestScannerWithClock is like newTestScanner, also returning the fake clock
+// the scanner polls results with.
+func newTestScannerWithClock(t *testing.T) (
+	*gomock.Controller,
+	*mockstorage.MockStorage,
+	*mockurlscanner.MockClient,
+	scanner.Scanner,
+	*clock.Fake) {
+	t.Helper()
+
 	ctrl := gomock.NewController(t)
 	st := mockstorage.NewMockStorage(ctrl)
 	urlClient := mockurlscanner.NewMockClient(ctrl)
-	s := scanner.New(st, urlClient, scanner.Options{MaxAttempts: 3, ResultCacheTTL: time.Hour})
+	clk := clock.NewFake(time.Now())
+	s := scanner.New(st, urlClient, scanner.Options{MaxAttempts: 3, ResultCacheTTL: time.Hour, Clock: clk})
 
 	logger.Setup("debug")
 
-	return ctrl, st, urlClient, s
+	return ctrl, st, urlClient, s, clk
+}
+
+// scanWithFakeClock runs s.Scan on url and, for each of waits, advances clk by
+// it once the scan is waiting on the clock, so that polling for the result
+// completes without real delays.
+func scanWithFakeClock(
+	s scanner.Scanner,
+	clk *clock.Fake,
+	waits ...time.Duration,
+) (urlscanner.RateLimitStatus, error) {
+	type scanRes struct {
+		rl  urlscanner.RateLimitStatus
+		err error
+	}
+	ch := make(chan scanRes, 1)
+	go func() {
+		rl, err := s.Scan(context.Background(), url, domain.UserID{}, urlscanner.SubmitOptions{})
+		ch <- scanRes{rl: rl, err: err}
+	}()
+
+	for _, d := range waits {
+		clk.BlockUntil(1)
+		clk.Advance(d)
+	}
+	res := <-ch
+
+	return res.rl, res.err
 }
 
 // helper to wire Storage.WithTx to execute callback with a MockAllStorage.
@@ -67,7 +118,7 @@ func TestScanner_Enqueue_JobAdded(t *testing.T) {
 	ctrl, st, _, s := newTestScanner(t)
 	defer ctrl.Finish()
 
-	userID := domain.UserID{}
+	userID := domain.UserID(uuid.New())
 
 	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
 		// Expect storing the scan
@@ -81,22 +132,89 @@ func TestScanner_Enqueue_JobAdded(t *testing.T) {
 				return ret, nil
 			},
 		)
+		// Expect recording the creation in the audit log
+		tx.EXPECT().RecordAudit(gomock.Any(), userID, storage.AuditActionCreate, domain.ScanID{}).Return(nil)
 		// Expect adding a job and report it was added
 		tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).Return(true, nil)
 	})
 
-	scan, err := s.Enqueue(context.Background(), userID, url)
+	scan, err := s.Enqueue(context.Background(), userID, url, "", nil, "")
 	require.NoError(t, err)
 	require.NotNil(t, scan)
 	require.Equal(t, url, scan.URL)
 	require.Equal(t, domain.ScanStatusPending, scan.Status)
 }
 
+func TestScanner_EnsureScan_CacheHit(t *testing.T) {
+	ctrl, st, _, s, clk := newTestScannerWithClock(t)
+	defer ctrl.Finish()
+
+	userID := domain.UserID(uuid.New())
+	last := &domain.Scan{
+		ID:        domain.ScanID(uuid.New()),
+		UserID:    userID,
+		URL:       url,
+		Status:    domain.ScanStatusCompleted,
+		UpdatedAt: clk.Now().Add(-30 * time.Minute),
+	}
+
+	// nothing is stored or enqueued
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().LastCompletedScanByURLForUser(gomock.Any(), userID, url).Return(last, nil)
+	})
+
+	scan, err := s.EnsureScan(context.Background(), userID, "HTTPS://Example.com")
+	require.NoError(t, err)
+	require.Equal(t, last, scan)
+}
+
+func TestScanner_EnsureScan_CacheMiss(t *testing.T) {
+	ctrl, st, _, s, clk := newTestScannerWithClock(t)
+	defer ctrl.Finish()
+
+	userID := domain.UserID(uuid.New())
+	storeScan := func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().StoreScans(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, scans ...domain.Scan) ([]domain.Scan, error) { return scans, nil },
+		)
+		tx.EXPECT().RecordAudit(gomock.Any(), userID, storage.AuditActionCreate, gomock.Any()).Return(nil)
+		tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).Return(true, nil)
+	}
+
+	// without a completed scan, a new one is enqueued
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().LastCompletedScanByURLForUser(gomock.Any(), userID, url).Return(nil, nil)
+		storeScan(tx)
+	})
+	scan, err := s.EnsureScan(context.Background(), userID, url)
+	require.NoError(t, err)
+	require.Equal(t, url, scan.URL)
+	require.Equal(t, domain.ScanStatusPending, scan.Status)
+
+	// so is it when the completed scan is older than the TTL
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().LastCompletedScanByURLForUser(gomock.Any(), userID, url).Return(&domain.Scan{
+			UserID:    userID,
+			URL:       url,
+			Status:    domain.ScanStatusCompleted,
+			UpdatedAt: clk.Now().Add(-2 * time.Hour),
+		}, nil)
+		storeScan(tx)
+	})
+	scan, err = s.EnsureScan(context.Background(), userID, url)
+	require.NoError(t, err)
+	require.Equal(t, domain.ScanStatusPending, scan.Status)
+
+	// invalid URLs are rejected before the transaction
+	_, err = s.EnsureScan(context.Background(), userID, "http://[::1")
+	require.ErrorIs(t, err, serrors.ErrBadRequest)
+}
+
 func TestScanner_Enqueue_UsesLastCompletedResult(t *testing.T) {
 	ctrl, st, _, s := newTestScanner(t)
 	defer ctrl.Finish()
 
-	userID := domain.UserID{}
+	userID := domain.UserID(uuid.New())
 	completed := domain.Scan{Result: domain.ScanResult{}}
 
 	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
@@ -108,13 +226,14 @@ func TestScanner_Enqueue_UsesLastCompletedResult(t *testing.T) {
 				return ret, nil
 			},
 		)
+		tx.EXPECT().RecordAudit(gomock.Any(), gomock.Any(), storage.AuditActionCreate, gomock.Any()).Return(nil)
 		// Job not added (already exists)
 		tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).Return(false, nil)
 		// There is a last completed scan for URL
-		tx.EXPECT().LastCompletedScanByURL(gomock.Any(), url).Return(&completed, nil)
-		// Update the newly created scan to completed with that result
-		tx.EXPECT().UpdateScanByID(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
-			func(_ context.Context, _ domain.ScanID, updates storage.ScanUpdates) (*domain.Scan, error) {
+		tx.EXPECT().LastCompletedScanByURL(gomock.Any(), userID, url).Return(&completed, nil)
+		// Update the newly created scan, scoped to its user, to completed with that result
+		tx.EXPECT().UpdateScanByIDForUser(gomock.Any(), userID, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ domain.UserID, _ domain.ScanID, updates storage.ScanUpdates) (*domain.Scan, error) {
 				require.Equal(t, domain.ScanStatusCompleted, updates.Status)
 				require.NotNil(t, updates.Result, "expected completed update with result")
 				res := domain.Scan{Status: domain.ScanStatusCompleted, Result: *updates.Result}
@@ -124,15 +243,233 @@ func TestScanner_Enqueue_UsesLastCompletedResult(t *testing.T) {
 		)
 	})
 
-	scan, err := s.Enqueue(context.Background(), userID, url)
+	scan, err := s.Enqueue(context.Background(), userID, url, "", nil, "")
 	require.NoError(t, err)
 	require.Equal(t, domain.ScanStatusCompleted, scan.Status)
 }
 
+func TestScanner_Enqueue_ResultCacheScope(t *testing.T) {
+	cases := []struct {
+		scope   scanner.ResultCacheScope
+		perUser bool
+	}{
+		{scope: "", perUser: false},
+		{scope: scanner.ResultCacheScopeGlobal, perUser: false},
+		{scope: scanner.ResultCacheScopeUser, perUser: true},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.scope), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			st := mockstorage.NewMockStorage(ctrl)
+			s := scanner.New(st, mockurlscanner.NewMockClient(ctrl), scanner.Options{
+				MaxAttempts:      3,
+				ResultCacheTTL:   time.Hour,
+				ResultCacheScope: tc.scope,
+			})
+			userID := domain.UserID(uuid.New())
+
+			expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+				tx.EXPECT().StoreScans(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, scans ...domain.Scan) ([]domain.Scan, error) { return scans, nil },
+				)
+				tx.EXPECT().RecordAudit(gomock.Any(), gomock.Any(), storage.AuditActionCreate, gomock.Any()).Return(nil)
+				// the unique job is skipped as a duplicate of a finished job
+				first := tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).Return(false, nil)
+				if tc.perUser {
+					tx.EXPECT().LastCompletedScanByURLForUser(gomock.Any(), userID, url).Return(nil, nil)
+				} else {
+					tx.EXPECT().LastCompletedScanByURL(gomock.Any(), userID, url).Return(nil, nil)
+				}
+				// without a reusable result or an active job, the URL is scanned again
+				tx.EXPECT().ActiveJobByURL(gomock.Any(), scanner.JobKind, url, domain.UserID{}).Return(nil, nil)
+				tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).After(first).DoAndReturn(
+					func(_ context.Context, args river.JobArgs, _ *river.InsertOpts) (bool, error) {
+						jobArgs, ok := args.(scanner.JobArgs)
+						require.True(t, ok)
+						require.Equal(t, url, jobArgs.URL)
+						require.Equal(t, river.UniqueOpts{}, jobArgs.InsertOpts().UniqueOpts)
+
+						return true, nil
+					},
+				)
+			})
+
+			scan, err := s.Enqueue(context.Background(), userID, url, "", nil, "")
+			require.NoError(t, err)
+			require.Equal(t, domain.ScanStatusPending, scan.Status)
+		})
+	}
+}
+
+func TestScanner_Enqueue_ReusesRecentFailure(t *testing.T) {
+	userID := domain.UserID(uuid.New())
+	otherUserID := domain.UserID(uuid.New())
+	clk := clock.NewFake(time.Now())
+	failure := func(owner domain.UserID, age time.Duration) *domain.Scan {
+		return &domain.Scan{
+			UserID:    owner,
+			URL:       url,
+			Status:    domain.ScanStatusFailed,
+			LastError: "could not resolve host",
+			UpdatedAt: clk.Now().Add(-age),
+		}
+	}
+	cases := []struct {
+		name   string
+		scope  scanner.ResultCacheScope
+		last   *domain.Scan
+		reused bool
+	}{
+		{name: "recent failure", last: failure(otherUserID, time.Minute), reused: true},
+		{name: "expired failure", last: failure(userID, 10*time.Minute), reused: false},
+		{name: "completed", last: &domain.Scan{Status: domain.ScanStatusCompleted, UpdatedAt: clk.Now()}, reused: false},
+		{name: "no scan", last: nil, reused: false},
+		{name: "own failure per user", scope: scanner.ResultCacheScopeUser, last: failure(userID, time.Minute), reused: true},
+		{
+			// the scans of other users, e.g. a newer shareable completed one, are
+			// not looked up and do not hide the user's own failure
+			name:   "own failure behind scans of other users per user",
+			scope:  scanner.ResultCacheScopeUser,
+			last:   failure(userID, 2*time.Minute),
+			reused: true,
+		},
+		{
+			name:   "expired own failure per user",
+			scope:  scanner.ResultCacheScopeUser,
+			last:   failure(userID, 10*time.Minute),
+			reused: false,
+		},
+		{name: "no own scan per user", scope: scanner.ResultCacheScopeUser, last: nil, reused: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			st := mockstorage.NewMockStorage(ctrl)
+			s := scanner.New(st, mockurlscanner.NewMockClient(ctrl), scanner.Options{
+				MaxAttempts:      3,
+				ResultCacheTTL:   time.Hour,
+				ResultCacheScope: tc.scope,
+				FailedResultTTL:  5 * time.Minute,
+				Clock:            clk,
+			})
+
+			expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+				tx.EXPECT().StoreScans(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, scans ...domain.Scan) ([]domain.Scan, error) { return scans, nil },
+				)
+				tx.EXPECT().RecordAudit(gomock.Any(), gomock.Any(), storage.AuditActionCreate, gomock.Any()).Return(nil)
+				if tc.scope == scanner.ResultCacheScopeUser {
+					tx.EXPECT().LastScanByURL(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+					tx.EXPECT().LastScanByURLForUser(gomock.Any(), userID, url).Return(tc.last, nil)
+				} else {
+					tx.EXPECT().LastScanByURL(gomock.Any(), userID, url).Return(tc.last, nil)
+				}
+				if tc.reused {
+					// the scan fails with the error of the last scan without a new job
+					tx.EXPECT().UpdateScanByIDForUser(gomock.Any(), userID, gomock.Any(), storage.ScanUpdates{
+						Status:    domain.ScanStatusFailed,
+						LastError: &tc.last.LastError,
+					}).Return(&domain.Scan{Status: domain.ScanStatusFailed, LastError: tc.last.LastError}, nil)
+					tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				} else {
+					tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).Return(true, nil)
+				}
+			})
+
+			scan, err := s.Enqueue(context.Background(), userID, url, "", nil, "")
+			require.NoError(t, err)
+			if tc.reused {
+				require.Equal(t, domain.ScanStatusFailed, scan.Status)
+				require.Equal(t, tc.last.LastError, scan.LastError)
+			} else {
+				require.Equal(t, domain.ScanStatusPending, scan.Status)
+			}
+		})
+	}
+}
+
+func TestScanner_Enqueue_RecentFailureExpiresDespiteRequests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userID := domain.UserID(uuid.New())
+	clk := clock.NewFake(time.Now())
+	st := mockstorage.NewMockStorage(ctrl)
+	s := scanner.New(st, mockurlscanner.NewMockClient(ctrl), scanner.Options{
+		MaxAttempts:     3,
+		ResultCacheTTL:  time.Hour,
+		FailedResultTTL: 5 * time.Minute,
+		Clock:           clk,
+	})
+
+	// the last processed scan stays the original failure: scans failed by
+	// reusing it have no attempts and are not returned by storage, so their
+	// later updated_at does not extend the window
+	original := &domain.Scan{
+		UserID:    userID,
+		URL:       url,
+		Status:    domain.ScanStatusFailed,
+		LastError: "could not resolve host",
+		UpdatedAt: clk.Now(),
+	}
+	enqueue := func(reused bool) *domain.Scan {
+		expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+			tx.EXPECT().StoreScans(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, scans ...domain.Scan) ([]domain.Scan, error) { return scans, nil },
+			)
+			tx.EXPECT().RecordAudit(gomock.Any(), gomock.Any(), storage.AuditActionCreate, gomock.Any()).Return(nil)
+			tx.EXPECT().LastScanByURL(gomock.Any(), userID, url).Return(original, nil)
+			if reused {
+				tx.EXPECT().UpdateScanByIDForUser(gomock.Any(), userID, gomock.Any(), gomock.Any()).
+					Return(&domain.Scan{Status: domain.ScanStatusFailed, UpdatedAt: clk.Now()}, nil)
+				tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			} else {
+				tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).Return(true, nil)
+			}
+		})
+
+		scan, err := s.Enqueue(context.Background(), userID, url, "", nil, "")
+		require.NoError(t, err)
+
+		return scan
+	}
+
+	// a request within the TTL reuses the failure
+	clk.Advance(4 * time.Minute)
+	require.Equal(t, domain.ScanStatusFailed, enqueue(true).Status)
+
+	// once the TTL since the original failure passed, the URL is scanned again
+	// even though the reused failure was updated only a minute ago
+	clk.Advance(time.Minute)
+	require.Equal(t, domain.ScanStatusPending, enqueue(false).Status)
+}
+
+func TestScanner_Enqueue_FailedResultReuseOffByDefault(t *testing.T) {
+	ctrl, st, _, s := newTestScanner(t)
+	defer ctrl.Finish()
+
+	// without FailedResultTTL the last scan is not looked up and a job is added
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().StoreScans(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, scans ...domain.Scan) ([]domain.Scan, error) { return scans, nil },
+		)
+		tx.EXPECT().RecordAudit(gomock.Any(), gomock.Any(), storage.AuditActionCreate, gomock.Any()).Return(nil)
+		tx.EXPECT().LastScanByURL(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).Return(true, nil)
+	})
+
+	scan, err := s.Enqueue(context.Background(), domain.UserID(uuid.New()), url, "", nil, "")
+	require.NoError(t, err)
+	require.Equal(t, domain.ScanStatusPending, scan.Status)
+}
+
 func TestScanner_Enqueue_PendingWhenJobExistsWithoutResult(t *testing.T) {
 	ctrl, st, _, s := newTestScanner(t)
 	defer ctrl.Finish()
-	userID := domain.UserID{}
+	userID := domain.UserID(uuid.New())
 
 	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
 		tx.EXPECT().StoreScans(gomock.Any(), gomock.Any()).DoAndReturn(
@@ -143,11 +480,15 @@ func TestScanner_Enqueue_PendingWhenJobExistsWithoutResult(t *testing.T) {
 				return ret, nil
 			},
 		)
+		tx.EXPECT().RecordAudit(gomock.Any(), gomock.Any(), storage.AuditActionCreate, gomock.Any()).Return(nil)
 		tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).Return(false, nil)
-		tx.EXPECT().LastCompletedScanByURL(gomock.Any(), url).Return(nil, nil)
+		tx.EXPECT().LastCompletedScanByURL(gomock.Any(), userID, url).Return(nil, nil)
+		// the active job completes the scan once it finishes, no job is added
+		tx.EXPECT().ActiveJobByURL(gomock.Any(), scanner.JobKind, url, domain.UserID{}).
+			Return(&storage.Job{URL: url}, nil)
 	})
 
-	scan, err := s.Enqueue(context.Background(), userID, url)
+	scan, err := s.Enqueue(context.Background(), userID, url, "", nil, "")
 	require.NoError(t, err)
 	require.Equal(t, domain.ScanStatusPending, scan.Status)
 }
@@ -156,7 +497,7 @@ func TestScanner_Enqueue_InvalidURL(t *testing.T) {
 	ctrl, st, _, s := newTestScanner(t)
 	defer ctrl.Finish()
 
-	_, err := s.Enqueue(context.Background(), domain.UserID{}, "http://[::1")
+	_, err := s.Enqueue(context.Background(), domain.UserID(uuid.New()), "http://[::1", "", nil, "")
 	require.Error(t, err)
 	require.ErrorIs(t, err, serrors.ErrBadRequest)
 	// ensure no calls were made on storage
@@ -166,15 +507,25 @@ func TestScanner_Enqueue_InvalidURL(t *testing.T) {
 func TestScanner_Enqueue_PropagatesErrors(t *testing.T) {
 	ctrl, st, _, s := newTestScanner(t)
 	defer ctrl.Finish()
-	userID := domain.UserID{}
+	userID := domain.UserID(uuid.New())
 
 	// error from StoreScans
 	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
 		tx.EXPECT().StoreScans(gomock.Any(), gomock.Any()).Return(nil, errors.New("store err"))
 	})
-	_, err := s.Enqueue(context.Background(), userID, url)
+	_, err := s.Enqueue(context.Background(), userID, url, "", nil, "")
 	require.Error(t, err, "expected error from StoreScans")
 
+	// error from RecordAudit
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().StoreScans(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, scans ...domain.Scan) ([]domain.Scan, error) { return scans, nil },
+		)
+		tx.EXPECT().RecordAudit(gomock.Any(), userID, storage.AuditActionCreate, gomock.Any()).Return(errors.New("audit err"))
+	})
+	_, err = s.Enqueue(context.Background(), userID, url, "", nil, "")
+	require.Error(t, err, "expected error from RecordAudit")
+
 	// error from AddJob
 	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
 		tx.EXPECT().StoreScans(gomock.Any(), gomock.Any()).DoAndReturn(
@@ -182,9 +533,10 @@ func TestScanner_Enqueue_PropagatesErrors(t *testing.T) {
 				return scans, nil
 			},
 		)
+		tx.EXPECT().RecordAudit(gomock.Any(), gomock.Any(), storage.AuditActionCreate, gomock.Any()).Return(nil)
 		tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).Return(false, errors.New("add err"))
 	})
-	_, err = s.Enqueue(context.Background(), userID, url)
+	_, err = s.Enqueue(context.Background(), userID, url, "", nil, "")
 	require.Error(t, err, "expected error from AddJob")
 
 	// error from LastCompletedScanByURL
@@ -192,10 +544,11 @@ func TestScanner_Enqueue_PropagatesErrors(t *testing.T) {
 		tx.EXPECT().StoreScans(gomock.Any(), gomock.Any()).DoAndReturn(
 			func(_ context.Context, scans ...domain.Scan) ([]domain.Scan, error) { return scans, nil },
 		)
+		tx.EXPECT().RecordAudit(gomock.Any(), gomock.Any(), storage.AuditActionCreate, gomock.Any()).Return(nil)
 		tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).Return(false, nil)
-		tx.EXPECT().LastCompletedScanByURL(gomock.Any(), url).Return(nil, errors.New("last err"))
+		tx.EXPECT().LastCompletedScanByURL(gomock.Any(), userID, url).Return(nil, errors.New("last err"))
 	})
-	_, err = s.Enqueue(context.Background(), userID, url)
+	_, err = s.Enqueue(context.Background(), userID, url, "", nil, "")
 	require.Error(t, err, "expected error from LastCompletedScanByURL")
 
 	// error from UpdateScanByID
@@ -203,18 +556,19 @@ func TestScanner_Enqueue_PropagatesErrors(t *testing.T) {
 		tx.EXPECT().StoreScans(gomock.Any(), gomock.Any()).DoAndReturn(
 			func(_ context.Context, scans ...domain.Scan) ([]domain.Scan, error) { return scans, nil },
 		)
+		tx.EXPECT().RecordAudit(gomock.Any(), gomock.Any(), storage.AuditActionCreate, gomock.Any()).Return(nil)
 		tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).Return(false, nil)
-		tx.EXPECT().LastCompletedScanByURL(gomock.Any(), url).Return(&domain.Scan{Result: domain.ScanResult{}}, nil)
-		tx.EXPECT().UpdateScanByID(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("update err"))
+		tx.EXPECT().LastCompletedScanByURL(gomock.Any(), userID, url).Return(&domain.Scan{Result: domain.ScanResult{}}, nil)
+		tx.EXPECT().UpdateScanByIDForUser(gomock.Any(), userID, gomock.Any(), gomock.Any()).Return(nil, errors.New("update err"))
 	})
-	_, err = s.Enqueue(context.Background(), userID, url)
+	_, err = s.Enqueue(context.Background(), userID, url, "", nil, "")
 	require.Error(t, err, "expected error from UpdateScanByID")
 }
 
 func TestScanner_UserScans_SuccessAndPagination(t *testing.T) {
 	ctrl, st, _, s := newTestScanner(t)
 	defer ctrl.Finish()
-	userID := domain.UserID{}
+	userID := domain.UserID(uuid.New())
 	status := domain.ScanStatusPending
 	cursorTime := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
 	cursor := cursorTime.Format(time.RFC3339)
@@ -228,27 +582,155 @@ func TestScanner_UserScans_SuccessAndPagination(t *testing.T) {
 		}(),
 	}
 
-	st.EXPECT().UserScans(gomock.Any(), userID, status, cursorTime, uint(10)).Return(page, nil)
+	st.EXPECT().UserScans(gomock.Any(), userID, status, false, cursorTime, storage.PageNext, uint(10)).Return(page, nil)
 
-	scans, next, err := s.UserScans(context.Background(), userID, status, cursor, 10)
+	scans, next, prev, err := s.UserScans(context.Background(), userID, status, false, cursor, "", 10)
 	require.NoError(t, err)
 	require.Len(t, scans, 1)
 	require.Equal(t, "https://a", scans[0].URL)
 	require.NotEmpty(t, next, "expected next cursor")
+	require.Empty(t, prev)
+}
+
+func TestScanner_UserScans_PrevDirection(t *testing.T) {
+	ctrl, st, _, s := newTestScanner(t)
+	defer ctrl.Finish()
+	userID := domain.UserID(uuid.New())
+	cursorTime := time.Date(2025, 1, 2, 3, 4, 5, 123456000, time.UTC)
+	next := cursorTime.Add(time.Minute)
+	prev := cursorTime.Add(time.Hour)
+
+	// cursors keep fractional seconds in both directions
+	st.EXPECT().UserScans(gomock.Any(), userID, domain.ScanStatus(""), false, cursorTime, storage.PagePrev, uint(10)).
+		Return(storage.UserScans{Scans: []domain.Scan{{URL: "https://a"}}, NextCursor: &next, PrevCursor: &prev}, nil)
+	scans, nextCursor, prevCursor, err := s.UserScans(context.Background(), userID, "", false,
+		cursorTime.Format(time.RFC3339Nano), storage.PagePrev, 10)
+	require.NoError(t, err)
+	require.Len(t, scans, 1)
+	require.Equal(t, "2025-01-02T03:05:05.123456Z", nextCursor)
+	require.Equal(t, "2025-01-02T04:04:05.123456Z", prevCursor)
+
+	// unknown directions are rejected before reaching storage
+	_, _, _, err = s.UserScans(context.Background(), userID, "", false, "", "sideways", 10)
+	require.ErrorIs(t, err, serrors.ErrBadRequest)
+}
+
+func TestScanner_StatusCounts(t *testing.T) {
+	ctrl, st, _, s := newTestScanner(t)
+	defer ctrl.Finish()
+	userID := domain.UserID(uuid.New())
+
+	st.EXPECT().ScanStatusCounts(gomock.Any(), userID).Return(map[domain.ScanStatus]int64{
+		domain.ScanStatusCompleted: 3,
+		domain.ScanStatusPending:   1,
+	}, nil)
+
+	// statuses without scans are reported as zero
+	counts, err := s.StatusCounts(context.Background(), userID)
+	require.NoError(t, err)
+	require.Equal(t, map[domain.ScanStatus]int64{
+		domain.ScanStatusPending:   1,
+		domain.ScanStatusCompleted: 3,
+		domain.ScanStatusFailed:    0,
+		domain.ScanStatusCanceled:  0,
+	}, counts)
+
+	st.EXPECT().ScanStatusCounts(gomock.Any(), userID).Return(nil, errors.New("boom"))
+	_, err = s.StatusCounts(context.Background(), userID)
+	require.Error(t, err)
+}
+
+func TestScanner_UserScans_MaliciousOnly(t *testing.T) {
+	ctrl, st, _, s := newTestScanner(t)
+	defer ctrl.Finish()
+	userID := domain.UserID(uuid.New())
+
+	st.EXPECT().UserScans(gomock.Any(), userID, domain.ScanStatus(""), true, time.Time{}, storage.PageNext, uint(10)).
+		Return(storage.UserScans{}, nil)
+
+	_, _, _, err := s.UserScans(context.Background(), userID, "", true, "", "", 10)
+	require.NoError(t, err)
+}
+
+func TestScanner_SearchScans(t *testing.T) {
+	ctrl, st, _, s := newTestScanner(t)
+	defer ctrl.Finish()
+	userID := domain.UserID(uuid.New())
+	next := time.Now().UTC().Truncate(time.Second)
+
+	st.EXPECT().SearchScans(gomock.Any(), userID, domain.ScanSearchKeyIP, "1.2.3.4", time.Time{}, uint(10)).
+		Return(storage.UserScans{Scans: []domain.Scan{{URL: "https://a"}}, NextCursor: &next}, nil)
+	scans, cursor, err := s.SearchScans(context.Background(), userID, domain.ScanSearchKeyIP, "1.2.3.4", "", 10)
+	require.NoError(t, err)
+	require.Len(t, scans, 1)
+	require.Equal(t, next.Format(time.RFC3339Nano), cursor)
+
+	// invalid input is rejected before reaching storage
+	_, _, err = s.SearchScans(context.Background(), userID, "url", "https://a", "", 10)
+	require.ErrorIs(t, err, serrors.ErrBadRequest)
+	_, _, err = s.SearchScans(context.Background(), userID, domain.ScanSearchKeyASN, "", "", 10)
+	require.ErrorIs(t, err, serrors.ErrBadRequest)
+	_, _, err = s.SearchScans(context.Background(), userID, domain.ScanSearchKeyASN, "AS1", "bad", 10)
+	require.ErrorIs(t, err, serrors.ErrBadRequest)
+}
+
+func TestScanner_SearchScans_SubSecondCursor(t *testing.T) {
+	ctrl, st, _, s := newTestScanner(t)
+	defer ctrl.Finish()
+	userID := domain.UserID(uuid.New())
+	first := time.Date(2025, 1, 2, 3, 4, 5, 900000000, time.UTC)
+	second := first.Add(-500 * time.Millisecond)
+
+	// the scans of both pages were created within the same second, so the
+	// cursor must keep fractional seconds for the second page to include the
+	// older one
+	st.EXPECT().SearchScans(gomock.Any(), userID, domain.ScanSearchKeyIP, "1.2.3.4", time.Time{}, uint(1)).
+		Return(storage.UserScans{Scans: []domain.Scan{{URL: "https://a", CreatedAt: first}}, NextCursor: &first}, nil)
+	st.EXPECT().SearchScans(gomock.Any(), userID, domain.ScanSearchKeyIP, "1.2.3.4", first, uint(1)).
+		Return(storage.UserScans{Scans: []domain.Scan{{URL: "https://b", CreatedAt: second}}}, nil)
+
+	scans, cursor, err := s.SearchScans(context.Background(), userID, domain.ScanSearchKeyIP, "1.2.3.4", "", 1)
+	require.NoError(t, err)
+	require.Len(t, scans, 1)
+	require.Equal(t, "2025-01-02T03:04:05.9Z", cursor)
+
+	scans, cursor, err = s.SearchScans(context.Background(), userID, domain.ScanSearchKeyIP, "1.2.3.4", cursor, 1)
+	require.NoError(t, err)
+	require.Len(t, scans, 1)
+	require.Equal(t, "https://b", scans[0].URL)
+	require.Empty(t, cursor)
 }
 
 func TestScanner_UserScans_InvalidCursor(t *testing.T) {
 	ctrl, _, _, s := newTestScanner(t)
 	defer ctrl.Finish()
-	_, _, err := s.UserScans(context.Background(), domain.UserID{}, "", "not-a-time", 5)
+	_, _, _, err := s.UserScans(context.Background(), domain.UserID{}, "", false, "not-a-time", "", 5)
 	require.Error(t, err)
 	require.ErrorIs(t, err, serrors.ErrBadRequest)
 }
 
+func TestScanner_UserScans_OffsetCursorNormalizedToUTC(t *testing.T) {
+	ctrl, st, _, s := newTestScanner(t)
+	defer ctrl.Finish()
+	userID := domain.UserID(uuid.New())
+	cursorTime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	next := cursorTime.Add(-time.Minute)
+
+	// a cursor echoed with a +02:00 offset reaches storage as the same instant
+	// in UTC, and the next cursor is handed out in UTC again
+	st.EXPECT().UserScans(gomock.Any(), userID, domain.ScanStatus(""), false, cursorTime, storage.PageNext, uint(10)).
+		Return(storage.UserScans{Scans: []domain.Scan{{URL: "https://a"}}, NextCursor: &next}, nil)
+	scans, nextCursor, _, err := s.UserScans(context.Background(), userID, "", false,
+		"2025-01-02T05:04:05+02:00", storage.PageNext, 10)
+	require.NoError(t, err)
+	require.Len(t, scans, 1)
+	require.Equal(t, "2025-01-02T03:03:05Z", nextCursor)
+}
+
 func TestScanner_Result(t *testing.T) {
 	ctrl, st, _, s := newTestScanner(t)
 	defer ctrl.Finish()
-	userID := domain.UserID{}
+	userID := domain.UserID(uuid.New())
 	id := domain.ScanID{}
 
 	// found
@@ -270,126 +752,1381 @@ func TestScanner_Result(t *testing.T) {
 	require.Error(t, err)
 }
 
-func TestScanner_Delete(t *testing.T) {
+func TestScanner_Attempts(t *testing.T) {
 	ctrl, st, _, s := newTestScanner(t)
 	defer ctrl.Finish()
-	userID := domain.UserID{}
-	id := domain.ScanID{}
+	userID := domain.UserID(uuid.New())
+	id := domain.ScanID(uuid.New())
 
-	// success
-	st.EXPECT().DeleteScan(gomock.Any(), userID, id).Return(&domain.Scan{}, nil)
-	require.NoError(t, s.Delete(context.Background(), userID, id))
-	// not found
-	st.EXPECT().DeleteScan(gomock.Any(), userID, id).Return(nil, nil)
-	err := s.Delete(context.Background(), userID, id)
-	require.Error(t, err)
+	// attempts of the user's scan
+	attempts := []storage.ScanAttempt{
+		{ID: 1, ScanID: id, Error: "provider down"},
+		{ID: 2, ScanID: id, RateLimit: urlscanner.RateLimitStatus{Limit: 100, Remaining: 99}},
+	}
+	st.EXPECT().ScanByID(gomock.Any(), userID, id).Return(&domain.Scan{ID: id, URL: url}, nil)
+	st.EXPECT().ScanAttemptsByID(gomock.Any(), id).Return(attempts, nil)
+	got, err := s.Attempts(context.Background(), userID, id)
+	require.NoError(t, err)
+	require.Equal(t, attempts, got)
+
+	// attempts of scans of other users are not looked up
+	st.EXPECT().ScanByID(gomock.Any(), userID, id).Return(nil, nil)
+	st.EXPECT().ScanAttemptsByID(gomock.Any(), gomock.Any()).Times(0)
+	_, err = s.Attempts(context.Background(), userID, id)
 	require.ErrorIs(t, err, serrors.ErrNotFound)
-	// storage error
-	st.EXPECT().DeleteScan(gomock.Any(), userID, id).Return(nil, errors.New("boom"))
-	require.Error(t, s.Delete(context.Background(), userID, id))
 }
 
-func TestScanner_Scan_NoPendingConflict(t *testing.T) {
-	ctrl, st, urlClient, s := newTestScanner(t)
+func TestScanner_Result_NextRetryAt(t *testing.T) {
+	ctrl, st, _, s := newTestScanner(t)
 	defer ctrl.Finish()
+	userID := domain.UserID(uuid.New())
+	id := domain.ScanID(uuid.New())
+	retryAt := time.Now().Add(time.Minute)
+
+	// pending scan with a job waiting for a retry
+	st.EXPECT().ScanByID(gomock.Any(), userID, id).Return(&domain.Scan{URL: url, Status: domain.ScanStatusPending}, nil)
+	st.EXPECT().ActiveJobByURL(gomock.Any(), scanner.JobKind, url, domain.UserID{}).
+		Return(&storage.Job{State: rivertype.JobStateRetryable, ScheduledAt: retryAt}, nil)
+	scan, err := s.Result(context.Background(), userID, id)
+	require.NoError(t, err)
+	require.Equal(t, retryAt, scan.NextRetryAt)
 
-	// no pending scans
-	st.EXPECT().PendingScanCountByURL(gomock.Any(), url).Return(int64(0), nil)
-	// ensure urlscanner is not called
-	urlClient.EXPECT().SubmitURL(gomock.Any(), gomock.Any()).Times(0)
+	// running jobs have no next run time
+	st.EXPECT().ScanByID(gomock.Any(), userID, id).Return(&domain.Scan{URL: url, Status: domain.ScanStatusPending}, nil)
+	st.EXPECT().ActiveJobByURL(gomock.Any(), scanner.JobKind, url, domain.UserID{}).
+		Return(&storage.Job{State: rivertype.JobStateRunning, ScheduledAt: retryAt}, nil)
+	scan, err = s.Result(context.Background(), userID, id)
+	require.NoError(t, err)
+	require.True(t, scan.NextRetryAt.IsZero())
+
+	// no active job
+	st.EXPECT().ScanByID(gomock.Any(), userID, id).Return(&domain.Scan{URL: url, Status: domain.ScanStatusPending}, nil)
+	st.EXPECT().ActiveJobByURL(gomock.Any(), scanner.JobKind, url, domain.UserID{}).Return(nil, nil)
+	scan, err = s.Result(context.Background(), userID, id)
+	require.NoError(t, err)
+	require.True(t, scan.NextRetryAt.IsZero())
+
+	// the job of private scans is owned by their user
+	st.EXPECT().ScanByID(gomock.Any(), userID, id).
+		Return(&domain.Scan{URL: url, UserID: userID, Status: domain.ScanStatusPending}, nil)
+	st.EXPECT().ActiveJobByURL(gomock.Any(), scanner.JobKind, url, userID).
+		Return(&storage.Job{State: rivertype.JobStateScheduled, ScheduledAt: retryAt}, nil)
+	scan, err = s.Result(context.Background(), userID, id)
+	require.NoError(t, err)
+	require.Equal(t, retryAt, scan.NextRetryAt)
+
+	// finalized scans are never retried, so no job is looked up, even when a
+	// newer job of the URL is active
+	for _, status := range []domain.ScanStatus{domain.ScanStatusCompleted, domain.ScanStatusFailed} {
+		st.EXPECT().ScanByID(gomock.Any(), userID, id).Return(&domain.Scan{URL: url, Status: status}, nil)
+		st.EXPECT().ActiveJobByURL(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		scan, err = s.Result(context.Background(), userID, id)
+		require.NoError(t, err)
+		require.True(t, scan.NextRetryAt.IsZero())
+	}
 
-	_, err := s.Scan(context.Background(), url)
+	// job lookup error
+	st.EXPECT().ScanByID(gomock.Any(), userID, id).Return(&domain.Scan{URL: url, Status: domain.ScanStatusPending}, nil)
+	st.EXPECT().ActiveJobByURL(gomock.Any(), scanner.JobKind, url, domain.UserID{}).Return(nil, errors.New("boom"))
+	_, err = s.Result(context.Background(), userID, id)
 	require.Error(t, err)
-	require.ErrorIs(t, err, serrors.ErrConflict)
 }
 
-func TestScanner_Scan_PendingCountError(t *testing.T) {
+func TestScanner_Results(t *testing.T) {
 	ctrl, st, _, s := newTestScanner(t)
 	defer ctrl.Finish()
+	userID := domain.UserID(uuid.New())
+	id1, id2, missing := domain.ScanID(uuid.New()), domain.ScanID(uuid.New()), domain.ScanID(uuid.New())
+
+	// duplicates are queried once; results follow the requested order
+	st.EXPECT().ScansByIDs(gomock.Any(), userID, []domain.ScanID{id2, missing, id1}).
+		Return([]domain.Scan{{ID: id1}, {ID: id2}}, nil)
+	scans, err := s.Results(context.Background(), userID, []domain.ScanID{id2, missing, id1, id2})
+	require.NoError(t, err)
+	require.Equal(t, []domain.Scan{{ID: id2}, {ID: id1}}, scans)
 
-	st.EXPECT().PendingScanCountByURL(gomock.Any(), url).Return(int64(0), errors.New("count boom"))
-	_, err := s.Scan(context.Background(), url)
+	// storage error
+	st.EXPECT().ScansByIDs(gomock.Any(), userID, gomock.Any()).Return(nil, errors.New("boom"))
+	_, err = s.Results(context.Background(), userID, []domain.ScanID{id1})
 	require.Error(t, err)
 }
 
-func TestScanner_Scan_Success(t *testing.T) {
-	ctrl, st, urlClient, s := newTestScanner(t)
+func TestScanner_UserScans_InvalidStatus(t *testing.T) {
+	ctrl, st, _, s := newTestScanner(t)
 	defer ctrl.Finish()
+	userID := domain.UserID(uuid.New())
 
-	st.EXPECT().PendingScanCountByURL(gomock.Any(), url).Return(int64(2), nil)
-	// urlscanner returns ID and RL
-	rl := urlscanner.RateLimitStatus{Limit: 100, Remaining: 50, ResetAt: time.Now()}
-	urlClient.EXPECT().SubmitURL(gomock.Any(), url).Return(urlscanner.SubmitRes{ID: "scan123"}, rl, nil)
-	// first poll returns result right away
-	urlClient.EXPECT().Result(gomock.Any(), "scan123").Return(&domain.ScanResult{}, nil)
-	// expect storage updated to completed with result
-	st.EXPECT().UpdatePendingScansByURL(gomock.Any(), url, gomock.Any()).DoAndReturn(
-		func(_ context.Context, _ string, updates storage.ScanUpdates) error {
-			require.Equal(t, domain.ScanStatusCompleted, updates.Status)
-			require.NotNil(t, updates.Result)
+	st.EXPECT().UserScans(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	_, _, _, err := s.UserScans(context.Background(), userID, "BOGUS", false, "", "", 10)
+	require.ErrorIs(t, err, serrors.ErrBadRequest)
 
-			return nil
-		},
-	)
+	_, _, err = s.AdminScans(context.Background(), scanner.AdminScanFilter{Status: "BOGUS"}, "", 10)
+	require.ErrorIs(t, err, serrors.ErrBadRequest)
 
-	rlOut, err := s.Scan(context.Background(), url)
-	require.NoError(t, err)
-	require.Equal(t, rl, rlOut)
+	// known statuses and no status are accepted
+	for _, status := range []domain.ScanStatus{"", domain.ScanStatusPending, domain.ScanStatusCompleted, domain.ScanStatusFailed} {
+		st.EXPECT().UserScans(gomock.Any(), userID, status, false, time.Time{}, storage.PageNext, uint(10)).Return(storage.UserScans{}, nil)
+		_, _, _, err = s.UserScans(context.Background(), userID, status, false, "", "", 10)
+		require.NoError(t, err)
+	}
 }
 
-func TestScanner_Scan_SubmitErrorUpdatesFailed(t *testing.T) {
-	ctrl, st, urlClient, s := newTestScanner(t)
+func TestScanner_Delete(t *testing.T) {
+	ctrl, st, _, s := newTestScanner(t)
 	defer ctrl.Finish()
+	userID := domain.UserID(uuid.New())
+	id := domain.ScanID{}
 
-	st.EXPECT().PendingScanCountByURL(gomock.Any(), url).Return(int64(1), nil)
-	// submit fails
-	rl := urlscanner.RateLimitStatus{Limit: 100, Remaining: 0, ResetAt: time.Now()}
-	urlClient.EXPECT().SubmitURL(gomock.Any(), url).Return(urlscanner.SubmitRes{}, rl, errors.New("provider down"))
-	// expect failed update with last error and max attempts
-	st.EXPECT().UpdatePendingScansByURL(gomock.Any(), url, gomock.Any()).DoAndReturn(
-		func(_ context.Context, _ string, updates storage.ScanUpdates) error {
-			require.Equal(t, domain.ScanStatusFailed, updates.Status)
-			require.NotNil(t, updates.LastError)
-			require.Equal(t, 3, updates.MaxAttempts)
+	// success, recorded in the audit log within the same transaction
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		gomock.InOrder(
+			tx.EXPECT().DeleteScan(gomock.Any(), userID, id).Return(&domain.Scan{}, nil),
+			tx.EXPECT().RecordAudit(gomock.Any(), userID, storage.AuditActionDelete, id).Return(nil),
+		)
+	})
+	require.NoError(t, s.Delete(context.Background(), userID, id))
+	// not found, nothing is audited
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().DeleteScan(gomock.Any(), userID, id).Return(nil, nil)
+	})
+	err := s.Delete(context.Background(), userID, id)
+	require.Error(t, err)
+	require.ErrorIs(t, err, serrors.ErrNotFound)
+	// storage error
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().DeleteScan(gomock.Any(), userID, id).Return(nil, errors.New("boom"))
+	})
+	require.Error(t, s.Delete(context.Background(), userID, id))
+	// audit error
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().DeleteScan(gomock.Any(), userID, id).Return(&domain.Scan{}, nil)
+		tx.EXPECT().RecordAudit(gomock.Any(), userID, storage.AuditActionDelete, id).Return(errors.New("audit err"))
+	})
+	err = s.Delete(context.Background(), userID, id)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, serrors.ErrNotFound)
+}
 
-			return nil
-		},
-	)
+func TestScanner_AdminScans(t *testing.T) {
+	ctrl, st, _, s := newTestScanner(t)
+	defer ctrl.Finish()
+
+	cursor := time.Now().UTC().Truncate(time.Second)
+	next := cursor.Add(-time.Minute)
+	after := cursor.Add(-time.Hour)
+
+	st.EXPECT().AdminListScans(gomock.Any(), storage.AdminScanFilter{
+		Status:       domain.ScanStatusPending,
+		URL:          "https://example.com/",
+		CreatedAfter: after,
+		Cursor:       cursor,
+		Limit:        10,
+	}).Return(storage.UserScans{Scans: []domain.Scan{{URL: "https://example.com/"}}, NextCursor: &next}, nil)
+
+	scans, nextCursor, err := s.AdminScans(context.Background(),
+		scanner.AdminScanFilter{Status: domain.ScanStatusPending, URL: "HTTPS://Example.com", CreatedAfter: after},
+		cursor.Format(time.RFC3339),
+		10)
+	require.NoError(t, err)
+	require.Len(t, scans, 1)
+	require.Equal(t, next.Format(time.RFC3339Nano), nextCursor)
+
+	// invalid cursor
+	_, _, err = s.AdminScans(context.Background(), scanner.AdminScanFilter{}, "nope", 10)
+	require.ErrorIs(t, err, serrors.ErrBadRequest)
 
-	_, err := s.Scan(context.Background(), url)
+	// storage error
+	st.EXPECT().AdminListScans(gomock.Any(), gomock.Any()).Return(storage.UserScans{}, errors.New("boom"))
+	_, _, err = s.AdminScans(context.Background(), scanner.AdminScanFilter{}, "", 10)
 	require.Error(t, err)
 }
 
-func TestScanner_Scan_RateLimitedNoFailedUpdate(t *testing.T) {
-	ctrl, st, urlClient, s := newTestScanner(t)
+func TestScanner_AdminScans_SubSecondCursor(t *testing.T) {
+	ctrl, st, _, s := newTestScanner(t)
 	defer ctrl.Finish()
+	first := time.Date(2025, 1, 2, 3, 4, 5, 750000000, time.UTC)
+	second := first.Add(-250 * time.Millisecond)
 
-	st.EXPECT().PendingScanCountByURL(gomock.Any(), url).Return(int64(1), nil)
-	// simulate rate-limited error on submit; submit can return wrapped rate-limit error
-	rl := urlscanner.RateLimitStatus{Limit: 100, Remaining: 0, ResetAt: time.Now()}
-	rateErr := serrors.With(serrors.ErrRateLimited, "rate limited")
-	urlClient.EXPECT().SubmitURL(gomock.Any(), url).Return(urlscanner.SubmitRes{}, rl, rateErr)
-	// ensure we do NOT mark failed when rate-limited
-	st.EXPECT().UpdatePendingScansByURL(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	// both scans were created within the same second, so the cursor of the
+	// first page must keep fractional seconds not to skip the second one
+	st.EXPECT().AdminListScans(gomock.Any(), storage.AdminScanFilter{Limit: 1}).
+		Return(storage.UserScans{Scans: []domain.Scan{{URL: "https://a", CreatedAt: first}}, NextCursor: &first}, nil)
+	st.EXPECT().AdminListScans(gomock.Any(), storage.AdminScanFilter{Cursor: first, Limit: 1}).
+		Return(storage.UserScans{Scans: []domain.Scan{{URL: "https://b", CreatedAt: second}}}, nil)
+
+	scans, cursor, err := s.AdminScans(context.Background(), scanner.AdminScanFilter{}, "", 1)
+	require.NoError(t, err)
+	require.Len(t, scans, 1)
+	require.Equal(t, "2025-01-02T03:04:05.75Z", cursor)
+
+	scans, cursor, err = s.AdminScans(context.Background(), scanner.AdminScanFilter{}, cursor, 1)
+	require.NoError(t, err)
+	require.Len(t, scans, 1)
+	require.Equal(t, "https://b", scans[0].URL)
+	require.Empty(t, cursor)
+}
+
+func TestScanner_AdminJobs(t *testing.T) {
+	ctrl, st, _, s := newTestScanner(t)
+	defer ctrl.Finish()
 
-	rlOut, err := s.Scan(context.Background(), url)
+	next := int64(7)
+	st.EXPECT().ListJobs(gomock.Any(), rivertype.JobStateAvailable, uint(10), int64(20)).
+		Return(storage.Jobs{Jobs: []storage.Job{{ID: 19, URL: url}}, NextCursor: &next}, nil)
+
+	jobs, nextCursor, err := s.AdminJobs(context.Background(), rivertype.JobStateAvailable, "20", 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	require.Equal(t, "7", nextCursor)
+
+	// invalid cursors
+	for _, cursor := range []string{"nope", "0", "-1"} {
+		_, _, err = s.AdminJobs(context.Background(), "", cursor, 10)
+		require.ErrorIs(t, err, serrors.ErrBadRequest)
+	}
+
+	// storage error
+	st.EXPECT().ListJobs(gomock.Any(), rivertype.JobState(""), uint(10), int64(0)).
+		Return(storage.Jobs{}, errors.New("boom"))
+	_, _, err = s.AdminJobs(context.Background(), "", "", 10)
 	require.Error(t, err)
-	require.ErrorIs(t, err, serrors.ErrRateLimited)
-	require.Equal(t, rl, rlOut)
 }
 
-func TestScanner_Scan_UpdateOnSuccessError(t *testing.T) {
-	ctrl, st, urlClient, s := newTestScanner(t)
+func TestScanner_AuditLog(t *testing.T) {
+	ctrl, st, _, s := newTestScanner(t)
 	defer ctrl.Finish()
 
-	st.EXPECT().PendingScanCountByURL(gomock.Any(), url).Return(int64(1), nil)
-	// submit ok
-	rl := urlscanner.RateLimitStatus{Limit: 100, Remaining: 50, ResetAt: time.Now()}
-	urlClient.EXPECT().SubmitURL(gomock.Any(), url).Return(urlscanner.SubmitRes{ID: "x"}, rl, nil)
-	urlClient.EXPECT().Result(gomock.Any(), "x").Return(&domain.ScanResult{}, nil)
-	// storage update fails
-	st.EXPECT().UpdatePendingScansByURL(gomock.Any(), url, gomock.Any()).Return(errors.New("update fail"))
+	userID := domain.UserID(uuid.New())
+	scanID := domain.ScanID(uuid.New())
+	filter := scanner.AuditFilter{UserID: userID, ScanID: scanID, Action: storage.AuditActionDelete}
+	next := int64(7)
+	st.EXPECT().AuditLog(gomock.Any(), storage.AuditFilter{
+		UserID: userID,
+		ScanID: scanID,
+		Action: storage.AuditActionDelete,
+		Cursor: 20,
+		Limit:  10,
+	}).Return(storage.AuditEntries{Entries: []storage.AuditEntry{{ID: 19, ScanID: scanID}}, NextCursor: &next}, nil)
+
+	entries, nextCursor, err := s.AuditLog(context.Background(), filter, "20", 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "7", nextCursor)
 
-	_, err := s.Scan(context.Background(), url)
+	// invalid cursors and actions
+	for _, cursor := range []string{"nope", "0", "-1"} {
+		_, _, err = s.AuditLog(context.Background(), scanner.AuditFilter{}, cursor, 10)
+		require.ErrorIs(t, err, serrors.ErrBadRequest)
+	}
+	_, _, err = s.AuditLog(context.Background(), scanner.AuditFilter{Action: "UPDATE"}, "", 10)
+	require.ErrorIs(t, err, serrors.ErrBadRequest)
+
+	// storage error
+	st.EXPECT().AuditLog(gomock.Any(), storage.AuditFilter{Limit: 10}).Return(storage.AuditEntries{}, errors.New("boom"))
+	_, _, err = s.AuditLog(context.Background(), scanner.AuditFilter{}, "", 10)
+	require.Error(t, err)
+}
+
+func TestScanner_Requeue(t *testing.T) {
+	ctrl, st, _, s := newTestScanner(t)
+	defer ctrl.Finish()
+	id := domain.ScanID(uuid.New())
+	userID := domain.UserID(uuid.New())
+	pending := domain.Scan{ID: id, UserID: userID, URL: url, Status: domain.ScanStatusPending}
+
+	// pending scan gets a new job
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().AdminScanByID(gomock.Any(), id).Return(&pending, nil)
+		args := scanner.NewJobArgs(url, nil, scanner.JobOptions{
+			MaxAttempts:     3,
+			UniqueJobPeriod: time.Hour,
+		})
+		args.Urgent = true
+		// the scan is not shareable, so it may have been requested privately
+		args.Visibility = urlscanner.VisibilityPrivate
+		args.Owner = userID.String()
+		tx.EXPECT().AddJob(gomock.Any(), args, gomock.Nil()).Return(true, nil)
+	})
+	scan, err := s.Requeue(context.Background(), id)
+	require.NoError(t, err)
+	require.Equal(t, id, scan.ID)
+
+	// a job for the URL is still queued
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().AdminScanByID(gomock.Any(), id).Return(&pending, nil)
+		tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).Return(false, nil)
+		tx.EXPECT().ActiveJobByURL(gomock.Any(), scanner.JobKind, url, userID).Return(&storage.Job{URL: url}, nil)
+	})
+	_, err = s.Requeue(context.Background(), id)
+	require.ErrorIs(t, err, serrors.ErrConflict)
+
+	// the duplicate job already finished without completing the scan
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().AdminScanByID(gomock.Any(), id).Return(&pending, nil)
+		first := tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).Return(false, nil)
+		tx.EXPECT().ActiveJobByURL(gomock.Any(), scanner.JobKind, url, userID).Return(nil, nil)
+		tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).After(first).DoAndReturn(
+			func(_ context.Context, args river.JobArgs, _ *river.InsertOpts) (bool, error) {
+				jobArgs, ok := args.(scanner.JobArgs)
+				require.True(t, ok)
+				require.True(t, jobArgs.Urgent)
+				require.Equal(t, river.UniqueOpts{}, jobArgs.InsertOpts().UniqueOpts)
+
+				return true, nil
+			},
+		)
+	})
+	scan, err = s.Requeue(context.Background(), id)
+	require.NoError(t, err)
+	require.Equal(t, id, scan.ID)
+
+	// terminal scans cannot be requeued
+	for _, status := range []domain.ScanStatus{domain.ScanStatusCompleted, domain.ScanStatusFailed} {
+		expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+			tx.EXPECT().AdminScanByID(gomock.Any(), id).Return(&domain.Scan{ID: id, URL: url, Status: status}, nil)
+			tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		})
+		_, err = s.Requeue(context.Background(), id)
+		require.ErrorIs(t, err, serrors.ErrConflict)
+	}
+
+	// not found
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().AdminScanByID(gomock.Any(), id).Return(nil, nil)
+	})
+	_, err = s.Requeue(context.Background(), id)
+	require.ErrorIs(t, err, serrors.ErrNotFound)
+}
+
+func TestScanner_ForceFail(t *testing.T) {
+	ctrl, st, _, s := newTestScanner(t)
+	defer ctrl.Finish()
+	id := domain.ScanID(uuid.New())
+	reason := "job lost"
+
+	// pending scan is marked failed
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().AdminScanByID(gomock.Any(), id).
+			Return(&domain.Scan{ID: id, Status: domain.ScanStatusPending, Version: 2}, nil)
+		tx.EXPECT().UpdateScanByID(gomock.Any(), id, storage.ScanUpdates{
+			Status:          domain.ScanStatusFailed,
+			LastError:       &reason,
+			ExpectedVersion: 2,
+		}).Return(&domain.Scan{ID: id, Status: domain.ScanStatusFailed, LastError: reason, Version: 3}, nil)
+	})
+	scan, err := s.ForceFail(context.Background(), id, reason)
+	require.NoError(t, err)
+	require.Equal(t, domain.ScanStatusFailed, scan.Status)
+	require.Equal(t, reason, scan.LastError)
+
+	// concurrent update by the worker
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().AdminScanByID(gomock.Any(), id).
+			Return(&domain.Scan{ID: id, Status: domain.ScanStatusPending, Version: 2}, nil)
+		tx.EXPECT().UpdateScanByID(gomock.Any(), id, gomock.Any()).
+			Return(nil, serrors.Wrap(serrors.ErrConflict, storage.ErrVersionMismatch, "scan was modified concurrently"))
+	})
+	_, err = s.ForceFail(context.Background(), id, reason)
+	require.ErrorIs(t, err, serrors.ErrConflict)
+
+	// terminal scans are left untouched
+	for _, status := range []domain.ScanStatus{domain.ScanStatusCompleted, domain.ScanStatusFailed} {
+		expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+			tx.EXPECT().AdminScanByID(gomock.Any(), id).Return(&domain.Scan{ID: id, Status: status}, nil)
+			tx.EXPECT().UpdateScanByID(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		})
+		_, err = s.ForceFail(context.Background(), id, reason)
+		require.ErrorIs(t, err, serrors.ErrConflict)
+	}
+
+	// not found
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().AdminScanByID(gomock.Any(), id).Return(nil, nil)
+	})
+	_, err = s.ForceFail(context.Background(), id, reason)
+	require.ErrorIs(t, err, serrors.ErrNotFound)
+}
+
+func TestScanner_ReconcileStaleScans(t *testing.T) {
+	ctrl, st, _, s := newTestScanner(t)
+	defer ctrl.Finish()
+	olderThan := time.Now().Add(-time.Hour)
+	const (
+		lostURL   = "https://lost.example.com/"
+		activeURL = "https://active.example.com/"
+	)
+	userA := domain.UserID(uuid.New())
+	userB := domain.UserID(uuid.New())
+	pending := func(userID domain.UserID, URL string, attempts uint, shareable bool) domain.Scan {
+		return domain.Scan{
+			ID:        domain.ScanID(uuid.New()),
+			UserID:    userID,
+			URL:       URL,
+			Status:    domain.ScanStatusPending,
+			Attempts:  attempts,
+			Shareable: shareable,
+		}
+	}
+	retry := pending(userA, lostURL, 1, false)
+	sibling := pending(userA, lostURL, 0, false)
+	shared := pending(userB, lostURL, 0, true)
+	exhausted := pending(userA, url, 3, false)
+	exhausted.Version = 4
+	running := pending(userA, activeURL, 3, false)
+
+	st.EXPECT().StalePendingScans(gomock.Any(), olderThan, gomock.Nil(), uint(100)).
+		Return([]domain.Scan{retry, sibling, shared, exhausted, running}, nil)
+	st.EXPECT().ActiveJobByURL(gomock.Any(), scanner.JobKind, lostURL, userA).Return(nil, nil)
+	st.EXPECT().ActiveJobByURL(gomock.Any(), scanner.JobKind, lostURL, domain.UserID{}).Return(nil, nil)
+	st.EXPECT().ActiveJobByURL(gomock.Any(), scanner.JobKind, url, userA).Return(nil, nil)
+	st.EXPECT().ActiveJobByURL(gomock.Any(), scanner.JobKind, activeURL, userA).Return(&storage.Job{URL: activeURL}, nil)
+	// the private scans of a user share one job, which is added once, while the
+	// shareable scans of the URL get a job of their own
+	private := scanner.NewJobArgs(lostURL, nil, scanner.JobOptions{MaxAttempts: 3, UniqueJobPeriod: time.Hour})
+	private.Visibility = urlscanner.VisibilityPrivate
+	private.Owner = userA.String()
+	st.EXPECT().AddJob(gomock.Any(), private, gomock.Nil()).Return(true, nil)
+	// the public job is skipped as a duplicate of a finished job which did not
+	// complete the shareable scan, so a job without uniqueness is added instead
+	public := scanner.NewJobArgs(lostURL, nil, scanner.JobOptions{MaxAttempts: 3, UniqueJobPeriod: time.Hour})
+	skipped := st.EXPECT().AddJob(gomock.Any(), public, gomock.Nil()).Return(false, nil)
+	st.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).After(skipped).DoAndReturn(
+		func(_ context.Context, args river.JobArgs, _ *river.InsertOpts) (bool, error) {
+			jobArgs, ok := args.(scanner.JobArgs)
+			require.True(t, ok)
+			require.Equal(t, lostURL, jobArgs.URL)
+			require.Empty(t, jobArgs.Owner)
+			require.Equal(t, river.UniqueOpts{}, jobArgs.InsertOpts().UniqueOpts)
+
+			return true, nil
+		})
+	st.EXPECT().UpdateScanByID(gomock.Any(), exhausted.ID, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ domain.ScanID, updates storage.ScanUpdates) (*domain.Scan, error) {
+			require.Equal(t, domain.ScanStatusFailed, updates.Status)
+			require.NotEmpty(t, *updates.LastError)
+			require.EqualValues(t, 4, updates.ExpectedVersion)
+
+			return &exhausted, nil
+		})
+
+	requeued, failed, err := s.ReconcileStaleScans(context.Background(), olderThan)
+	require.NoError(t, err)
+	require.Equal(t, 2, requeued)
+	require.Equal(t, 1, failed)
+
+	// scans updated by the worker in the meantime are skipped
+	st.EXPECT().StalePendingScans(gomock.Any(), olderThan, gomock.Nil(), uint(100)).Return([]domain.Scan{exhausted}, nil)
+	st.EXPECT().ActiveJobByURL(gomock.Any(), scanner.JobKind, url, userA).Return(nil, nil)
+	st.EXPECT().UpdateScanByID(gomock.Any(), exhausted.ID, gomock.Any()).
+		Return(nil, serrors.Wrap(serrors.ErrConflict, storage.ErrVersionMismatch, "scan was modified concurrently"))
+	requeued, failed, err = s.ReconcileStaleScans(context.Background(), olderThan)
+	require.NoError(t, err)
+	require.Zero(t, requeued)
+	require.Zero(t, failed)
+
+	// storage errors abort the reconciliation
+	st.EXPECT().StalePendingScans(gomock.Any(), olderThan, gomock.Nil(), uint(100)).Return(nil, errors.New("db down"))
+	_, _, err = s.ReconcileStaleScans(context.Background(), olderThan)
+	require.ErrorContains(t, err, "db down")
+
+	// full pages are followed by the page after their last scan
+	page := make([]domain.Scan, 100)
+	for i := range page {
+		page[i] = pending(userA, activeURL, 0, false)
+	}
+	lost := pending(userA, url, 3, false)
+	first := st.EXPECT().StalePendingScans(gomock.Any(), olderThan, gomock.Nil(), uint(100)).Return(page, nil)
+	st.EXPECT().StalePendingScans(gomock.Any(), olderThan, &page[99], uint(100)).After(first).
+		Return([]domain.Scan{lost}, nil)
+	st.EXPECT().ActiveJobByURL(gomock.Any(), scanner.JobKind, activeURL, userA).Return(&storage.Job{URL: activeURL}, nil)
+	st.EXPECT().ActiveJobByURL(gomock.Any(), scanner.JobKind, url, userA).Return(nil, nil)
+	st.EXPECT().UpdateScanByID(gomock.Any(), lost.ID, gomock.Any()).Return(&lost, nil)
+	requeued, failed, err = s.ReconcileStaleScans(context.Background(), olderThan)
+	require.NoError(t, err)
+	require.Zero(t, requeued)
+	require.Equal(t, 1, failed)
+}
+func TestScanner_ForbidCrossUserAccess(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	st := mockstorage.NewMockStorage(ctrl)
+	s := scanner.New(st, mockurlscanner.NewMockClient(ctrl), scanner.Options{ForbidCrossUserAccess: true})
+	userID := domain.UserID(uuid.New())
+	id := domain.ScanID{}
+
+	// scan owned by another user
+	st.EXPECT().ScanByID(gomock.Any(), userID, id).Return(nil, nil)
+	st.EXPECT().ScanExists(gomock.Any(), id).Return(true, nil)
+	_, err := s.Result(context.Background(), userID, id)
+	require.ErrorIs(t, err, serrors.ErrForbidden)
+
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().DeleteScan(gomock.Any(), userID, id).Return(nil, nil)
+	})
+	st.EXPECT().ScanExists(gomock.Any(), id).Return(true, nil)
+	require.ErrorIs(t, s.Delete(context.Background(), userID, id), serrors.ErrForbidden)
+
+	// scan does not exist at all
+	st.EXPECT().ScanByID(gomock.Any(), userID, id).Return(nil, nil)
+	st.EXPECT().ScanExists(gomock.Any(), id).Return(false, nil)
+	_, err = s.Result(context.Background(), userID, id)
+	require.ErrorIs(t, err, serrors.ErrNotFound)
+
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().DeleteScan(gomock.Any(), userID, id).Return(nil, nil)
+	})
+	st.EXPECT().ScanExists(gomock.Any(), id).Return(false, nil)
+	require.ErrorIs(t, s.Delete(context.Background(), userID, id), serrors.ErrNotFound)
+
+	// existence check error
+	st.EXPECT().ScanByID(gomock.Any(), userID, id).Return(nil, nil)
+	st.EXPECT().ScanExists(gomock.Any(), id).Return(false, errors.New("boom"))
+	_, err = s.Result(context.Background(), userID, id)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, serrors.ErrNotFound)
+}
+
+func TestScanner_Scan_NoPendingConflict(t *testing.T) {
+	ctrl, st, urlClient, s := newTestScanner(t)
+	defer ctrl.Finish()
+
+	// no pending scans
+	st.EXPECT().PendingScanCountByURL(gomock.Any(), url, domain.UserID{}).Return(int64(0), nil)
+	// ensure urlscanner is not called
+	urlClient.EXPECT().SubmitURL(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := s.Scan(context.Background(), url, domain.UserID{}, urlscanner.SubmitOptions{})
+	require.Error(t, err)
+	require.ErrorIs(t, err, serrors.ErrConflict)
+}
+
+func TestScanner_Scan_NoClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	st := mockstorage.NewMockStorage(ctrl)
+	s := scanner.New(st, nil, scanner.Options{MaxAttempts: 3})
+
+	// neither storage nor the missing client is touched
+	_, err := s.Scan(context.Background(), url, domain.UserID{}, urlscanner.SubmitOptions{})
+	require.ErrorIs(t, err, serrors.ErrInternal)
+	require.ErrorContains(t, err, "no urlscanner client configured")
+}
+
+func TestScanner_Scan_PendingCountError(t *testing.T) {
+	ctrl, st, _, s := newTestScanner(t)
+	defer ctrl.Finish()
+
+	st.EXPECT().PendingScanCountByURL(gomock.Any(), url, domain.UserID{}).Return(int64(0), errors.New("count boom"))
+	_, err := s.Scan(context.Background(), url, domain.UserID{}, urlscanner.SubmitOptions{})
+	require.Error(t, err)
+}
+
+func TestScanner_Scan_Success(t *testing.T) {
+	ctrl, st, urlClient, s, clk := newTestScannerWithClock(t)
+	defer ctrl.Finish()
+
+	st.EXPECT().PendingScanCountByURL(gomock.Any(), url, domain.UserID{}).Return(int64(2), nil)
+	// urlscanner returns ID and RL
+	rl := urlscanner.RateLimitStatus{Limit: 100, Remaining: 50, ResetAt: time.Now()}
+	urlClient.EXPECT().SubmitURL(gomock.Any(), url, gomock.Any()).Return(urlscanner.SubmitRes{ID: "scan123"}, rl, nil)
+	// first poll finds the result ready and fetches it
+	urlClient.EXPECT().Status(gomock.Any(), "scan123").Return(true, nil)
+	urlClient.EXPECT().Result(gomock.Any(), "scan123").Return(&domain.ScanResult{}, nil)
+	// expect pending scans re-checked and updated to completed with result in a tx
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().PendingScanCountByURL(gomock.Any(), url, domain.UserID{}).Return(int64(2), nil)
+		// the successful attempt is recorded with the rate-limit status
+		tx.EXPECT().RecordScanAttempts(gomock.Any(), url, domain.UserID{}, storage.ScanAttempt{RateLimit: rl}).Return(nil)
+		tx.EXPECT().UpdatePendingScansByURL(gomock.Any(), url, domain.UserID{}, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ string, _ domain.UserID, updates storage.ScanUpdates) (int64, error) {
+				require.Equal(t, domain.ScanStatusCompleted, updates.Status)
+				require.NotNil(t, updates.Result)
+
+				return 2, nil
+			},
+		)
+	})
+
+	// the result is polled after the initial delay
+	rlOut, err := scanWithFakeClock(s, clk, time.Second)
+	require.NoError(t, err)
+	require.Equal(t, rl, rlOut)
+}
+
+func TestScanner_Scan_WaitsUntilResultReady(t *testing.T) {
+	ctrl, st, urlClient, s, clk := newTestScannerWithClock(t)
+	defer ctrl.Finish()
+
+	st.EXPECT().PendingScanCountByURL(gomock.Any(), url, domain.UserID{}).Return(int64(1), nil)
+	urlClient.EXPECT().SubmitURL(gomock.Any(), url, gomock.Any()).Return(urlscanner.SubmitRes{ID: "scan123"}, urlscanner.RateLimitStatus{}, nil)
+	// the result is only fetched once its status is ready
+	gomock.InOrder(
+		urlClient.EXPECT().Status(gomock.Any(), "scan123").Return(false, nil),
+		urlClient.EXPECT().Status(gomock.Any(), "scan123").Return(true, nil),
+		urlClient.EXPECT().Result(gomock.Any(), "scan123").Return(&domain.ScanResult{}, nil),
+	)
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().PendingScanCountByURL(gomock.Any(), url, domain.UserID{}).Return(int64(1), nil)
+		tx.EXPECT().RecordScanAttempts(gomock.Any(), url, domain.UserID{}, gomock.Any()).Return(nil)
+		tx.EXPECT().UpdatePendingScansByURL(gomock.Any(), url, domain.UserID{}, gomock.Any()).Return(int64(1), nil)
+	})
+
+	// the initial delay, then the jittered base interval of at most 2s
+	_, err := scanWithFakeClock(s, clk, time.Second, 2*time.Second)
+	require.NoError(t, err)
+}
+
+func TestScanner_Scan_DefaultCountry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	st := mockstorage.NewMockStorage(ctrl)
+	urlClient := mockurlscanner.NewMockClient(ctrl)
+	s := scanner.New(st, urlClient, scanner.Options{MaxAttempts: 3, Country: "de"})
+
+	st.EXPECT().PendingScanCountByURL(gomock.Any(), url, domain.UserID{}).Return(int64(1), nil).Times(2)
+	submitErr := errors.New("provider down")
+	st.EXPECT().RecordScanAttempts(gomock.Any(), url, domain.UserID{}, gomock.Any()).Return(nil).Times(2)
+	st.EXPECT().UpdatePendingScansByURL(gomock.Any(), url, domain.UserID{}, gomock.Any()).Return(int64(1), nil).Times(2)
+
+	// the configured country is used unless the job sets its own
+	urlClient.EXPECT().SubmitURL(gomock.Any(), url, urlscanner.SubmitOptions{Country: "de"}).
+		Return(urlscanner.SubmitRes{}, urlscanner.RateLimitStatus{}, submitErr)
+	_, err := s.Scan(context.Background(), url, domain.UserID{}, urlscanner.SubmitOptions{})
+	require.ErrorIs(t, err, submitErr)
+
+	urlClient.EXPECT().SubmitURL(gomock.Any(), url, urlscanner.SubmitOptions{Country: "fr"}).
+		Return(urlscanner.SubmitRes{}, urlscanner.RateLimitStatus{}, submitErr)
+	_, err = s.Scan(context.Background(), url, domain.UserID{}, urlscanner.SubmitOptions{Country: "fr"})
+	require.ErrorIs(t, err, submitErr)
+}
+
+func TestScanner_Scan_SubmitErrorUpdatesFailed(t *testing.T) {
+	ctrl, st, urlClient, s := newTestScanner(t)
+	defer ctrl.Finish()
+
+	st.EXPECT().PendingScanCountByURL(gomock.Any(), url, domain.UserID{}).Return(int64(1), nil)
+	// submit fails
+	rl := urlscanner.RateLimitStatus{Limit: 100, Remaining: 0, ResetAt: time.Now()}
+	urlClient.EXPECT().SubmitURL(gomock.Any(), url, gomock.Any()).Return(urlscanner.SubmitRes{}, rl, errors.New("provider down"))
+	// expect the failed attempt to be recorded, then failed update with last error and max attempts
+	st.EXPECT().RecordScanAttempts(gomock.Any(), url, domain.UserID{}, storage.ScanAttempt{
+		Error:     "could not submit URL: provider down",
+		RateLimit: rl,
+	}).Return(nil)
+	st.EXPECT().UpdatePendingScansByURL(gomock.Any(), url, domain.UserID{}, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, _ domain.UserID, updates storage.ScanUpdates) (int64, error) {
+			require.Equal(t, domain.ScanStatusFailed, updates.Status)
+			require.NotNil(t, updates.LastError)
+			require.Equal(t, 3, updates.MaxAttempts)
+
+			return 1, nil
+		},
+	)
+
+	_, err := s.Scan(context.Background(), url, domain.UserID{}, urlscanner.SubmitOptions{})
+	require.Error(t, err)
+}
+
+func TestScanner_Scan_RateLimitedNoFailedUpdate(t *testing.T) {
+	ctrl, st, urlClient, s := newTestScanner(t)
+	defer ctrl.Finish()
+
+	st.EXPECT().PendingScanCountByURL(gomock.Any(), url, domain.UserID{}).Return(int64(1), nil)
+	// simulate rate-limited error on submit; submit can return wrapped rate-limit error
+	rl := urlscanner.RateLimitStatus{Limit: 100, Remaining: 0, ResetAt: time.Now()}
+	rateErr := serrors.With(serrors.ErrRateLimited, "rate limited")
+	urlClient.EXPECT().SubmitURL(gomock.Any(), url, gomock.Any()).Return(urlscanner.SubmitRes{}, rl, rateErr)
+	// the attempt is recorded with the rate-limit status, but the scans are NOT marked failed
+	st.EXPECT().RecordScanAttempts(gomock.Any(), url, domain.UserID{}, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, _ domain.UserID, attempt storage.ScanAttempt) error {
+			require.Contains(t, attempt.Error, "rate limited")
+			require.Equal(t, rl, attempt.RateLimit)
+
+			return nil
+		},
+	)
+	st.EXPECT().UpdatePendingScansByURL(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	rlOut, err := s.Scan(context.Background(), url, domain.UserID{}, urlscanner.SubmitOptions{})
+	require.Error(t, err)
+	require.ErrorIs(t, err, serrors.ErrRateLimited)
+	require.Equal(t, rl, rlOut)
+}
+
+func TestScanner_Scan_UpdateOnSuccessError(t *testing.T) {
+	ctrl, st, urlClient, s, clk := newTestScannerWithClock(t)
+	defer ctrl.Finish()
+
+	st.EXPECT().PendingScanCountByURL(gomock.Any(), url, domain.UserID{}).Return(int64(1), nil)
+	// submit ok
+	rl := urlscanner.RateLimitStatus{Limit: 100, Remaining: 50, ResetAt: time.Now()}
+	urlClient.EXPECT().SubmitURL(gomock.Any(), url, gomock.Any()).Return(urlscanner.SubmitRes{ID: "x"}, rl, nil)
+	urlClient.EXPECT().Status(gomock.Any(), "x").Return(true, nil)
+	urlClient.EXPECT().Result(gomock.Any(), "x").Return(&domain.ScanResult{}, nil)
+	// storage update fails
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().PendingScanCountByURL(gomock.Any(), url, domain.UserID{}).Return(int64(1), nil)
+		tx.EXPECT().RecordScanAttempts(gomock.Any(), url, domain.UserID{}, gomock.Any()).Return(nil)
+		tx.EXPECT().UpdatePendingScansByURL(gomock.Any(), url, domain.UserID{}, gomock.Any()).
+			Return(int64(0), errors.New("update fail"))
+	})
+
+	_, err := scanWithFakeClock(s, clk, time.Second)
+	require.Error(t, err)
+}
+
+func TestScanner_Scan_BatchedResults(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	st := mockstorage.NewMockStorage(ctrl)
+	urlClient := mockurlscanner.NewMockClient(ctrl)
+	clk := clock.NewFake(time.Now())
+	s := scanner.New(st, urlClient, scanner.Options{
+		MaxAttempts:         3,
+		ResultBatchInterval: 10 * time.Millisecond,
+		Clock:               clk,
+	})
+
+	urls := map[string]string{"https://a.example/": "scan-a", "https://b.example/": "scan-b"}
+	for u, id := range urls {
+		st.EXPECT().PendingScanCountByURL(gomock.Any(), u, domain.UserID{}).Return(int64(1), nil)
+		urlClient.EXPECT().SubmitURL(gomock.Any(), u, gomock.Any()).Return(urlscanner.SubmitRes{ID: id}, urlscanner.RateLimitStatus{}, nil)
+	}
+	// results are only returned once both scans are polled in the same batch;
+	// Result is never called for a single scan
+	urlClient.EXPECT().Results(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, ids []string) (map[string]*domain.ScanResult, map[string]error) {
+			if len(ids) < len(urls) {
+				return nil, nil
+			}
+			require.ElementsMatch(t, []string{"scan-a", "scan-b"}, ids)
+
+			return map[string]*domain.ScanResult{"scan-a": {}, "scan-b": {}}, nil
+		},
+	).MinTimes(1)
+	for range urls {
+		expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+			tx.EXPECT().PendingScanCountByURL(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(1), nil)
+			tx.EXPECT().RecordScanAttempts(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			tx.EXPECT().UpdatePendingScansByURL(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ string, _ domain.UserID, updates storage.ScanUpdates) (int64, error) {
+					require.Equal(t, domain.ScanStatusCompleted, updates.Status)
+					require.NotNil(t, updates.Result)
+
+					return 1, nil
+				},
+			)
+		})
+	}
+
+	var wg sync.WaitGroup
+	for u := range urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.Scan(context.Background(), u, domain.UserID{}, urlscanner.SubmitOptions{})
+			require.NoError(t, err)
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	// advance the clock past the initial delay and the batch interval until
+	// both scans completed; the poller may start with a single waiting scan
+	for {
+		select {
+		case <-done:
+			return
+		case <-time.After(time.Millisecond):
+			clk.Advance(time.Second)
+		}
+	}
+}
+
+func TestScanner_Scan_BatchedResultsClosed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	st := mockstorage.NewMockStorage(ctrl)
+	urlClient := mockurlscanner.NewMockClient(ctrl)
+	clk := clock.NewFake(time.Now())
+	s := scanner.New(st, urlClient, scanner.Options{
+		MaxAttempts:         3,
+		ResultBatchInterval: 10 * time.Millisecond,
+		Clock:               clk,
+	})
+
+	st.EXPECT().PendingScanCountByURL(gomock.Any(), url, domain.UserID{}).Return(int64(1), nil)
+	urlClient.EXPECT().SubmitURL(gomock.Any(), url, gomock.Any()).Return(urlscanner.SubmitRes{ID: "scan-a"}, urlscanner.RateLimitStatus{}, nil)
+	// the result of the scan can not be fetched, so it keeps waiting
+	polled := make(chan struct{})
+	var once sync.Once
+	urlClient.EXPECT().Results(gomock.Any(), []string{"scan-a"}).DoAndReturn(
+		func(context.Context, []string) (map[string]*domain.ScanResult, map[string]error) {
+			once.Do(func() { close(polled) })
+
+			return nil, map[string]error{"scan-a": errors.New("bad upstream")}
+		},
+	).MinTimes(1)
+	st.EXPECT().RecordScanAttempts(gomock.Any(), url, domain.UserID{}, gomock.Any()).Return(nil)
+	st.EXPECT().UpdatePendingScansByURL(gomock.Any(), url, domain.UserID{}, gomock.Any()).Return(int64(1), nil)
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := s.Scan(context.Background(), url, domain.UserID{}, urlscanner.SubmitOptions{})
+		errCh <- err
+	}()
+
+	// advance the clock until the scan is polled at least once
+	for waiting := true; waiting; {
+		select {
+		case <-polled:
+			waiting = false
+		case <-time.After(time.Millisecond):
+			clk.Advance(time.Second)
+		}
+	}
+
+	// closing the scanner stops the poller and fails the waiting scan
+	s.Close()
+	select {
+	case err := <-errCh:
+		require.ErrorContains(t, err, "result poller closed")
+	case <-time.After(5 * time.Second):
+		t.Fatal("scan still waiting after the scanner was closed")
+	}
+}
+
+func TestScanner_Scan_ScansDeletedBeforeResultWrite(t *testing.T) {
+	rl := urlscanner.RateLimitStatus{Limit: 100, Remaining: 50, ResetAt: time.Now()}
+	tests := []struct {
+		name   string
+		expect func(tx *mockstorage.MockAllStorage)
+	}{
+		{
+			name: "deleted before the count",
+			expect: func(tx *mockstorage.MockAllStorage) {
+				tx.EXPECT().PendingScanCountByURL(gomock.Any(), url, domain.UserID{}).Return(int64(0), nil)
+				tx.EXPECT().UpdatePendingScansByURL(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "deleted between the count and the update",
+			expect: func(tx *mockstorage.MockAllStorage) {
+				tx.EXPECT().PendingScanCountByURL(gomock.Any(), url, domain.UserID{}).Return(int64(1), nil)
+				tx.EXPECT().RecordScanAttempts(gomock.Any(), url, domain.UserID{}, gomock.Any()).Return(nil)
+				tx.EXPECT().UpdatePendingScansByURL(gomock.Any(), url, domain.UserID{}, gomock.Any()).Return(int64(0), nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl, st, urlClient, s, clk := newTestScannerWithClock(t)
+			defer ctrl.Finish()
+
+			st.EXPECT().PendingScanCountByURL(gomock.Any(), url, domain.UserID{}).Return(int64(1), nil)
+			urlClient.EXPECT().SubmitURL(gomock.Any(), url, gomock.Any()).Return(urlscanner.SubmitRes{ID: "x"}, rl, nil)
+			urlClient.EXPECT().Status(gomock.Any(), "x").Return(true, nil)
+			urlClient.EXPECT().Result(gomock.Any(), "x").Return(&domain.ScanResult{}, nil)
+			expectWithTx(t, ctrl, st, tt.expect)
+
+			// the result is dropped without failing the job
+			rlOut, err := scanWithFakeClock(s, clk, time.Second)
+			require.NoError(t, err)
+			require.Equal(t, rl, rlOut)
+		})
+	}
+}
+
+func TestJitter_StaysWithinBounds(t *testing.T) {
+	base := 2 * time.Second
+	maxDelay := 10 * time.Minute
+
+	sources := map[string]func(n int64) int64{
+		"min":    func(int64) int64 { return 0 },
+		"max":    func(n int64) int64 { return n - 1 },
+		"middle": func(n int64) int64 { return n / 2 },
+	}
+	for name, src := range sources {
+		t.Run(name, func(t *testing.T) {
+			delay := base
+			for range 20 {
+				got := scanner.Jitter(delay, src)
+				require.GreaterOrEqual(t, got, base/2)
+				require.GreaterOrEqual(t, got, delay/2)
+				require.LessOrEqual(t, got, delay)
+				require.LessOrEqual(t, got, maxDelay)
+
+				delay = min(delay*2, maxDelay)
+			}
+		})
+	}
+}
+
+func TestJitter_ZeroDelay(t *testing.T) {
+	require.Equal(t, time.Duration(0), scanner.Jitter(0, func(int64) int64 { return 0 }))
+}
+
+func TestScanner_Enqueue_RoutesJobQueueByUserTier(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	priorityUser := domain.UserID(uuid.New())
+	regularUser := domain.UserID(uuid.New())
+
+	st := mockstorage.NewMockStorage(ctrl)
+	s := scanner.New(st, mockurlscanner.NewMockClient(ctrl), scanner.Options{
+		MaxAttempts:         3,
+		ResultCacheTTL:      time.Hour,
+		Queue:               "default",
+		Priority:            2,
+		PriorityQueue:       "priority",
+		PriorityJobPriority: 1,
+		PriorityUserIDs:     map[domain.UserID]struct{}{priorityUser: {}},
+	})
+
+	cases := []struct {
+		name     string
+		userID   domain.UserID
+		queue    string
+		priority int
+	}{
+		{name: "priority user", userID: priorityUser, queue: "priority", priority: 1},
+		{name: "regular user", userID: regularUser, queue: "default", priority: 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+				tx.EXPECT().StoreScans(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, scans ...domain.Scan) ([]domain.Scan, error) { return scans, nil },
+				)
+				tx.EXPECT().RecordAudit(gomock.Any(), gomock.Any(), storage.AuditActionCreate, gomock.Any()).Return(nil)
+				tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).DoAndReturn(
+					func(_ context.Context, args river.JobArgs, _ *river.InsertOpts) (bool, error) {
+						jobArgs, ok := args.(scanner.JobArgs)
+						require.True(t, ok)
+						opts := jobArgs.InsertOpts()
+						require.Equal(t, tc.queue, opts.Queue)
+						require.Equal(t, tc.priority, opts.Priority)
+
+						return true, nil
+					},
+				)
+			})
+
+			_, err := s.Enqueue(context.Background(), tc.userID, url, "", nil, "")
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestScanner_Enqueue_SlowDomainJobTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	st := mockstorage.NewMockStorage(ctrl)
+	s := scanner.New(st, mockurlscanner.NewMockClient(ctrl), scanner.Options{
+		MaxAttempts:    3,
+		ResultCacheTTL: time.Hour,
+		SlowDomains:    scanner.DomainList{"*.slow.example"},
+		SlowJobTimeout: 5 * time.Minute,
+	})
+
+	cases := []struct {
+		name    string
+		url     string
+		timeout time.Duration
+	}{
+		{name: "slow domain", url: "https://www.slow.example/", timeout: 5 * time.Minute},
+		{name: "regular domain", url: url, timeout: 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+				tx.EXPECT().StoreScans(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, scans ...domain.Scan) ([]domain.Scan, error) { return scans, nil },
+				)
+				tx.EXPECT().RecordAudit(gomock.Any(), gomock.Any(), storage.AuditActionCreate, gomock.Any()).Return(nil)
+				tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).DoAndReturn(
+					func(_ context.Context, args river.JobArgs, _ *river.InsertOpts) (bool, error) {
+						jobArgs, ok := args.(scanner.JobArgs)
+						require.True(t, ok)
+						require.Equal(t, tc.timeout, jobArgs.Timeout)
+
+						return true, nil
+					},
+				)
+			})
+
+			_, err := s.Enqueue(context.Background(), domain.UserID(uuid.New()), tc.url, "", nil, "")
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestScanner_Enqueue_Shareable(t *testing.T) {
+	cases := []struct {
+		visibility urlscanner.Visibility
+		shareable  bool
+	}{
+		{visibility: "", shareable: true},
+		{visibility: urlscanner.VisibilityPublic, shareable: true},
+		{visibility: urlscanner.VisibilityUnlisted, shareable: false},
+		{visibility: urlscanner.VisibilityPrivate, shareable: false},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.visibility), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			st := mockstorage.NewMockStorage(ctrl)
+			s := scanner.New(st, mockurlscanner.NewMockClient(ctrl), scanner.Options{
+				MaxAttempts:    3,
+				ResultCacheTTL: time.Hour,
+				Visibility:     tc.visibility,
+			})
+
+			expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+				tx.EXPECT().StoreScans(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, scans ...domain.Scan) ([]domain.Scan, error) {
+						require.Equal(t, tc.shareable, scans[0].Shareable)
+
+						return scans, nil
+					},
+				)
+				tx.EXPECT().RecordAudit(gomock.Any(), gomock.Any(), storage.AuditActionCreate, gomock.Any()).Return(nil)
+				tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).Return(true, nil)
+			})
+
+			_, err := s.Enqueue(context.Background(), domain.UserID(uuid.New()), url, "", nil, "")
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestScanner_Enqueue_VisibilityPrecedence(t *testing.T) {
+	// the requested visibility takes precedence over the configured one, which
+	// falls back to public. Jobs only carry visibilities differing from the
+	// configured one, and jobs of non-shareable scans carry their owner.
+	cases := []struct {
+		configured urlscanner.Visibility
+		requested  urlscanner.Visibility
+		shareable  bool
+		job        urlscanner.Visibility
+	}{
+		{configured: "", requested: "", shareable: true, job: ""},
+		{configured: "", requested: urlscanner.VisibilityPublic, shareable: true, job: ""},
+		{configured: "", requested: urlscanner.VisibilityPrivate, shareable: false, job: urlscanner.VisibilityPrivate},
+		{configured: urlscanner.VisibilityUnlisted, requested: "", shareable: false, job: ""},
+		{
+			configured: urlscanner.VisibilityUnlisted,
+			requested:  urlscanner.VisibilityPublic,
+			shareable:  true,
+			job:        urlscanner.VisibilityPublic,
+		},
+		{
+			configured: urlscanner.VisibilityPublic,
+			requested:  urlscanner.VisibilityUnlisted,
+			shareable:  false,
+			job:        urlscanner.VisibilityUnlisted,
+		},
+		{configured: urlscanner.VisibilityPrivate, requested: urlscanner.VisibilityPrivate, shareable: false, job: ""},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.configured)+"/"+string(tc.requested), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			st := mockstorage.NewMockStorage(ctrl)
+			s := scanner.New(st, mockurlscanner.NewMockClient(ctrl), scanner.Options{
+				MaxAttempts:    3,
+				ResultCacheTTL: time.Hour,
+				Visibility:     tc.configured,
+			})
+
+			userID := domain.UserID(uuid.New())
+			expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+				tx.EXPECT().StoreScans(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, scans ...domain.Scan) ([]domain.Scan, error) {
+						require.Equal(t, tc.shareable, scans[0].Shareable)
+
+						return scans, nil
+					},
+				)
+				tx.EXPECT().RecordAudit(gomock.Any(), gomock.Any(), storage.AuditActionCreate, gomock.Any()).Return(nil)
+				tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).DoAndReturn(
+					func(_ context.Context, args river.JobArgs, _ *river.InsertOpts) (bool, error) {
+						require.Equal(t, tc.job, args.(scanner.JobArgs).Visibility)
+						if tc.shareable {
+							require.Empty(t, args.(scanner.JobArgs).Owner)
+						} else {
+							require.Equal(t, userID.String(), args.(scanner.JobArgs).Owner)
+						}
+
+						return true, nil
+					},
+				)
+			})
+
+			_, err := s.Enqueue(context.Background(), userID, url, "", nil, tc.requested)
+			require.NoError(t, err)
+		})
+	}
+
+	// unknown visibilities are rejected before touching storage
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	s := scanner.New(mockstorage.NewMockStorage(ctrl), mockurlscanner.NewMockClient(ctrl), scanner.Options{
+		Visibility: urlscanner.VisibilityPrivate,
+	})
+	_, err := s.Enqueue(context.Background(), domain.UserID(uuid.New()), url, "", nil, "secret")
+	require.ErrorIs(t, err, serrors.ErrBadRequest)
+	require.ErrorContains(t, err, "visibility")
+}
+
+func TestScanner_OptionsHolder_Reload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	st := mockstorage.NewMockStorage(ctrl)
+	holder := scanner.NewOptionsHolder(scanner.Options{MaxAttempts: 3, ResultCacheTTL: time.Hour})
+	s := scanner.NewWithOptionsHolder(st, mockurlscanner.NewMockClient(ctrl), holder)
+
+	// reloaded options apply to subsequent operations
+	cfg := &config.Config{}
+	cfg.Scanner.MaxAttempts = 3
+	cfg.Scanner.DeniedDomains = []string{"example.com"}
+	holder.Reload(cfg)
+
+	st.EXPECT().WithTx(gomock.Any(), gomock.Any()).Times(0)
+	_, err := s.Enqueue(context.Background(), domain.UserID(uuid.New()), url, "", nil, "")
+	require.ErrorIs(t, err, serrors.ErrForbidden)
+
+	// visibility is kept since the urlscan.io client is not reloaded
+	holder.Store(scanner.Options{Visibility: urlscanner.VisibilityPrivate})
+	cfg.Scanner.Visibility = string(urlscanner.VisibilityPublic)
+	holder.Reload(cfg)
+	require.Equal(t, urlscanner.VisibilityPrivate, holder.Load().Visibility)
+
+	// concurrent loads always observe a complete set of options
+	holder.Store(scanner.Options{})
+	var wg sync.WaitGroup
+	var torn atomic.Bool
+	for i := range 10 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			holder.Store(scanner.Options{MaxAttempts: i, MaxURLLength: i})
+		}()
+		go func() {
+			defer wg.Done()
+			options := holder.Load()
+			if options.MaxAttempts != options.MaxURLLength {
+				torn.Store(true)
+			}
+		}()
+	}
+	wg.Wait()
+	require.False(t, torn.Load())
+}
+
+func TestScanner_Enqueue_MaxURLLength(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const maxLen = 64
+	st := mockstorage.NewMockStorage(ctrl)
+	s := scanner.New(st, mockurlscanner.NewMockClient(ctrl), scanner.Options{
+		MaxAttempts:    3,
+		ResultCacheTTL: time.Hour,
+		MaxURLLength:   maxLen,
+	})
+
+	prefix := "https://example.com/"
+	atLimit := prefix + strings.Repeat("a", maxLen-len(prefix))
+	overLimit := atLimit + "a"
+
+	// just over the limit is rejected before touching storage
+	st.EXPECT().WithTx(gomock.Any(), gomock.Any()).Times(0)
+	_, err := s.Enqueue(context.Background(), domain.UserID(uuid.New()), overLimit, "", nil, "")
+	require.Error(t, err)
+	require.ErrorIs(t, err, serrors.ErrBadRequest)
+
+	// exactly at the limit is accepted
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().StoreScans(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, scans ...domain.Scan) ([]domain.Scan, error) { return scans, nil },
+		)
+		tx.EXPECT().RecordAudit(gomock.Any(), gomock.Any(), storage.AuditActionCreate, gomock.Any()).Return(nil)
+		tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).Return(true, nil)
+	})
+	scan, err := s.Enqueue(context.Background(), domain.UserID(uuid.New()), atLimit, "", nil, "")
+	require.NoError(t, err)
+	require.Equal(t, atLimit, scan.URL)
+}
+
+func TestScanner_Enqueue_Tags(t *testing.T) {
+	ctrl, st, _, s := newTestScanner(t)
+	defer ctrl.Finish()
+
+	// invalid tags are rejected before touching storage
+	st.EXPECT().WithTx(gomock.Any(), gomock.Any()).Times(0)
+	tooMany := make([]string, urlscanner.MaxTags+1)
+	for i := range tooMany {
+		tooMany[i] = "tag"
+	}
+	for _, tags := range [][]string{tooMany, {""}, {strings.Repeat("a", urlscanner.MaxTagLength+1)}} {
+		_, err := s.Enqueue(context.Background(), domain.UserID(uuid.New()), url, "", tags, "")
+		require.ErrorIs(t, err, serrors.ErrBadRequest)
+	}
+
+	// valid tags are carried by the job
+	tags := []string{"phishing", strings.Repeat("a", urlscanner.MaxTagLength)}
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().StoreScans(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, scans ...domain.Scan) ([]domain.Scan, error) { return scans, nil },
+		)
+		tx.EXPECT().RecordAudit(gomock.Any(), gomock.Any(), storage.AuditActionCreate, gomock.Any()).Return(nil)
+		tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).DoAndReturn(
+			func(_ context.Context, args scanner.JobArgs, _ any) (bool, error) {
+				require.Equal(t, tags, args.Tags)
+
+				return true, nil
+			},
+		)
+	})
+	_, err := s.Enqueue(context.Background(), domain.UserID(uuid.New()), url, "", tags, "")
+	require.NoError(t, err)
+}
+
+func TestScanner_LatestByURL(t *testing.T) {
+	ctrl, st, _, s := newTestScanner(t)
+	defer ctrl.Finish()
+	userID := domain.UserID(uuid.New())
+
+	// found; URL is normalized before lookup
+	st.EXPECT().LatestScanByURLForUser(gomock.Any(), userID, url).Return(&domain.Scan{URL: url}, nil)
+	scan, err := s.LatestByURL(context.Background(), userID, "HTTPS://Example.com:443#frag")
+	require.NoError(t, err)
+	require.Equal(t, url, scan.URL)
+
+	// not found
+	st.EXPECT().LatestScanByURLForUser(gomock.Any(), userID, url).Return(nil, nil)
+	_, err = s.LatestByURL(context.Background(), userID, url)
+	require.ErrorIs(t, err, serrors.ErrNotFound)
+
+	// storage error
+	st.EXPECT().LatestScanByURLForUser(gomock.Any(), userID, url).Return(nil, errors.New("boom"))
+	_, err = s.LatestByURL(context.Background(), userID, url)
+	require.Error(t, err)
+
+	// invalid URL
+	_, err = s.LatestByURL(context.Background(), userID, "http://[::1")
+	require.ErrorIs(t, err, serrors.ErrBadRequest)
+}
+
+func TestScanner_Enqueue_RejectsZeroUserID(t *testing.T) {
+	ctrl, _, _, s := newTestScanner(t)
+	defer ctrl.Finish()
+
+	// nothing is stored or enqueued for the zero user
+	_, err := s.Enqueue(context.Background(), domain.UserID{}, url, "", nil, "")
+	require.ErrorIs(t, err, serrors.ErrUnauthorized)
+}
+
+func TestScanner_Enqueue_MaxPendingScansPerUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	st := mockstorage.NewMockStorage(ctrl)
+	s := scanner.New(st, mockurlscanner.NewMockClient(ctrl), scanner.Options{
+		MaxPendingScansPerUser: 2,
+		PendingScansRetryAfter: 30 * time.Second,
+	})
+	userID := domain.UserID(uuid.New())
+	storeScan := func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().StoreScans(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, scans ...domain.Scan) ([]domain.Scan, error) { return scans, nil },
+		)
+	}
+
+	// the count includes the new scan, so reaching the cap is allowed
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		storeScan(tx)
+		tx.EXPECT().PendingScanCountByUser(gomock.Any(), userID).Return(int64(2), nil)
+		tx.EXPECT().RecordAudit(gomock.Any(), userID, storage.AuditActionCreate, gomock.Any()).Return(nil)
+		tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).Return(true, nil)
+	})
+	_, err := s.Enqueue(context.Background(), userID, url, "", nil, "")
+	require.NoError(t, err)
+
+	// exceeding it rolls the scan back without enqueueing a job
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		storeScan(tx)
+		tx.EXPECT().PendingScanCountByUser(gomock.Any(), userID).Return(int64(3), nil)
+	})
+	_, err = s.Enqueue(context.Background(), userID, url, "", nil, "")
+	require.ErrorIs(t, err, serrors.ErrRateLimited)
+	var sem *serrors.Error
+	require.ErrorAs(t, err, &sem)
+	require.Equal(t, 30*time.Second, sem.RetryAfter())
+
+	// retries of an existing scan are not counted
+	original := domain.Scan{ID: domain.ScanID(uuid.New()), UserID: userID, URL: url, IdempotencyKey: "key-1"}
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().UpsertScan(gomock.Any(), gomock.Any()).Return(&original, false, nil)
+	})
+	scan, err := s.Enqueue(context.Background(), userID, url, "key-1", nil, "")
+	require.NoError(t, err)
+	require.Equal(t, original.ID, scan.ID)
+
+	// count errors abort the enqueue
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		storeScan(tx)
+		tx.EXPECT().PendingScanCountByUser(gomock.Any(), userID).Return(int64(0), errors.New("boom"))
+	})
+	_, err = s.Enqueue(context.Background(), userID, url, "", nil, "")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, serrors.ErrRateLimited)
+}
+
+func TestScanner_Enqueue_IdempotencyKey(t *testing.T) {
+	ctrl, st, _, s := newTestScanner(t)
+	defer ctrl.Finish()
+	userID := domain.UserID(uuid.New())
+	original := domain.Scan{ID: domain.ScanID(uuid.New()), UserID: userID, URL: url, IdempotencyKey: "key-1"}
+
+	// same key returns the original scan without storing or enqueueing again
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().UpsertScan(gomock.Any(), gomock.Any()).Return(&original, false, nil)
+	})
+	scan, err := s.Enqueue(context.Background(), userID, url, "key-1", nil, "")
+	require.NoError(t, err)
+	require.Equal(t, original.ID, scan.ID)
+
+	// a new key stores a new scan carrying the key
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().UpsertScan(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, scan domain.Scan) (*domain.Scan, bool, error) {
+				require.Equal(t, "key-2", scan.IdempotencyKey)
+				scan.ID = domain.ScanID(uuid.New())
+
+				return &scan, true, nil
+			},
+		)
+		tx.EXPECT().RecordAudit(gomock.Any(), userID, storage.AuditActionCreate, gomock.Any()).Return(nil)
+		tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).Return(true, nil)
+	})
+	scan, err = s.Enqueue(context.Background(), userID, url, "key-2", nil, "")
+	require.NoError(t, err)
+	require.NotEqual(t, original.ID, scan.ID)
+
+	// storage errors abort the enqueue
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().UpsertScan(gomock.Any(), gomock.Any()).Return(nil, false, errors.New("boom"))
+	})
+	_, err = s.Enqueue(context.Background(), userID, url, "key-3", nil, "")
 	require.Error(t, err)
 }
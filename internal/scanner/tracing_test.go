@@ -0,0 +1,216 @@
+package scanner_test
+
+import (
+	"context"
+	"errors"
+	"scanner/internal/scanner"
+	"scanner/pkg/clock"
+	"scanner/pkg/domain"
+	"scanner/pkg/logger"
+	"scanner/pkg/storage"
+	mockstorage "scanner/pkg/storage/mock"
+	"scanner/pkg/tracing"
+	"scanner/pkg/urlscanner"
+	mockurlscanner "scanner/pkg/urlscanner/mock"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riverqueue/river"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/mock/gomock"
+)
+
+// newTracedScanner returns a scanner recording its spans in the returned
+// in-memory exporter.
+func newTracedScanner(t *testing.T) (
+	*gomock.Controller,
+	*mockstorage.MockStorage,
+	*mockurlscanner.MockClient,
+	scanner.Scanner,
+	*clock.Fake,
+	*tracetest.InMemoryExporter) {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	st := mockstorage.NewMockStorage(ctrl)
+	urlClient := mockurlscanner.NewMockClient(ctrl)
+	clk := clock.NewFake(time.Now())
+	exporter := tracetest.NewInMemoryExporter()
+	s := scanner.New(st, urlClient, scanner.Options{
+		MaxAttempts:    3,
+		Clock:          clk,
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)),
+	})
+
+	logger.Setup("debug")
+
+	return ctrl, st, urlClient, s, clk, exporter
+}
+
+// spanByName returns the single recorded span with the given name.
+func spanByName(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	t.Helper()
+
+	var found []tracetest.SpanStub
+	for _, span := range spans {
+		if span.Name == name {
+			found = append(found, span)
+		}
+	}
+	require.Len(t, found, 1, name)
+
+	return found[0]
+}
+
+func TestScanner_Scan_Spans(t *testing.T) {
+	ctrl, st, urlClient, s, clk, exporter := newTracedScanner(t)
+	defer ctrl.Finish()
+
+	st.EXPECT().PendingScanCountByURL(gomock.Any(), url, domain.UserID{}).Return(int64(1), nil)
+	urlClient.EXPECT().SubmitURL(gomock.Any(), url, gomock.Any()).
+		Return(urlscanner.SubmitRes{ID: "scan123"}, urlscanner.RateLimitStatus{Remaining: 7}, nil)
+	gomock.InOrder(
+		urlClient.EXPECT().Status(gomock.Any(), "scan123").Return(false, errors.New("flaky")),
+		urlClient.EXPECT().Status(gomock.Any(), "scan123").Return(true, nil),
+		urlClient.EXPECT().Result(gomock.Any(), "scan123").Return(&domain.ScanResult{}, nil),
+	)
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().PendingScanCountByURL(gomock.Any(), url, domain.UserID{}).Return(int64(1), nil)
+		tx.EXPECT().RecordScanAttempts(gomock.Any(), url, domain.UserID{}, gomock.Any()).Return(nil)
+		tx.EXPECT().UpdatePendingScansByURL(gomock.Any(), url, domain.UserID{}, gomock.Any()).Return(int64(1), nil)
+	})
+
+	_, err := scanWithFakeClock(s, clk, time.Second, 2*time.Second)
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 5)
+
+	scan := spanByName(t, spans, "scanner.Scan")
+	require.Contains(t, scan.Attributes, tracing.URLHash(url))
+	require.Equal(t, codes.Unset, scan.Status.Code)
+
+	poll := spanByName(t, spans, "scanner.submitURLAndPoll")
+	require.Equal(t, scan.SpanContext.SpanID(), poll.Parent.SpanID())
+	require.Contains(t, poll.Attributes, attribute.String("scan.provider_id", "scan123"))
+
+	submit := spanByName(t, spans, "scanner.submit")
+	require.Equal(t, poll.SpanContext.SpanID(), submit.Parent.SpanID())
+	require.Contains(t, submit.Attributes, attribute.Int("ratelimit.remaining", 7))
+
+	// one span per poll; the failed one records its error
+	var polls tracetest.SpanStubs
+	for _, span := range spans {
+		if span.Name == "scanner.poll" {
+			require.Equal(t, poll.SpanContext.SpanID(), span.Parent.SpanID())
+			polls = append(polls, span)
+		}
+	}
+	require.Len(t, polls, 2)
+	require.Contains(t, polls[0].Attributes, attribute.Int("poll.attempt", 1))
+	require.Equal(t, codes.Error, polls[0].Status.Code)
+	require.Len(t, polls[0].Events, 1)
+	require.Contains(t, polls[1].Attributes, attribute.Int("poll.attempt", 2))
+	require.Contains(t, polls[1].Attributes, attribute.Bool("scan.ready", true))
+	require.Equal(t, codes.Unset, polls[1].Status.Code)
+}
+
+func TestScanner_Scan_SpanRecordsError(t *testing.T) {
+	ctrl, st, urlClient, s, _, exporter := newTracedScanner(t)
+	defer ctrl.Finish()
+
+	st.EXPECT().PendingScanCountByURL(gomock.Any(), url, domain.UserID{}).Return(int64(1), nil)
+	urlClient.EXPECT().SubmitURL(gomock.Any(), url, gomock.Any()).
+		Return(urlscanner.SubmitRes{}, urlscanner.RateLimitStatus{}, errors.New("provider down"))
+	st.EXPECT().RecordScanAttempts(gomock.Any(), url, domain.UserID{}, gomock.Any()).Return(nil)
+	st.EXPECT().UpdatePendingScansByURL(gomock.Any(), url, domain.UserID{}, gomock.Any()).Return(int64(1), nil)
+
+	_, err := s.Scan(context.Background(), url, domain.UserID{}, urlscanner.SubmitOptions{})
+	require.Error(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+	for _, name := range []string{"scanner.Scan", "scanner.submitURLAndPoll", "scanner.submit"} {
+		span := spanByName(t, spans, name)
+		require.Equal(t, codes.Error, span.Status.Code, name)
+		require.Len(t, span.Events, 1, name)
+	}
+}
+
+func TestScanner_Enqueue_Span(t *testing.T) {
+	ctrl, st, _, s, _, exporter := newTracedScanner(t)
+	defer ctrl.Finish()
+
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().StoreScans(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, scans ...domain.Scan) ([]domain.Scan, error) { return scans, nil },
+		)
+		tx.EXPECT().RecordAudit(gomock.Any(), gomock.Any(), storage.AuditActionCreate, gomock.Any()).Return(nil)
+		tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).Return(true, nil)
+	})
+
+	// the normalized URL is recorded
+	_, err := s.Enqueue(context.Background(), domain.UserID(uuid.New()), "HTTPS://Example.com:443", "", nil, "")
+	require.NoError(t, err)
+
+	span := spanByName(t, exporter.GetSpans(), "scanner.Enqueue")
+	require.Contains(t, span.Attributes, tracing.URLHash(url))
+	require.Contains(t, span.Attributes, attribute.String("scan.status", string(domain.ScanStatusPending)))
+}
+
+func TestScanner_TraceContextPropagatesThroughJob(t *testing.T) {
+	ctrl, st, urlClient, s, _, exporter := newTracedScanner(t)
+	defer ctrl.Finish()
+
+	traceID := trace.TraceID{1, 2, 3}
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     trace.SpanID{4, 5, 6},
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	// the job enqueued for the request carries the request's trace context
+	var args scanner.JobArgs
+	expectWithTx(t, ctrl, st, func(tx *mockstorage.MockAllStorage) {
+		tx.EXPECT().StoreScans(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, scans ...domain.Scan) ([]domain.Scan, error) { return scans, nil },
+		)
+		tx.EXPECT().RecordAudit(gomock.Any(), gomock.Any(), storage.AuditActionCreate, gomock.Any()).Return(nil)
+		tx.EXPECT().AddJob(gomock.Any(), gomock.Any(), gomock.Nil()).DoAndReturn(
+			func(_ context.Context, jobArgs river.JobArgs, _ *river.InsertOpts) (bool, error) {
+				args = jobArgs.(scanner.JobArgs) //nolint: forcetypeassert
+
+				return true, nil
+			},
+		)
+	})
+	_, err := s.Enqueue(ctx, domain.UserID(uuid.New()), url, "", nil, "")
+	require.NoError(t, err)
+	require.Contains(t, args.TraceContext["traceparent"], traceID.String())
+
+	// the job's scan continues the trace up to the urlscan.io client, whose
+	// transport injects it into the requests
+	st.EXPECT().PendingScanCountByURL(gomock.Any(), url, domain.UserID{}).Return(int64(1), nil)
+	urlClient.EXPECT().SubmitURL(gomock.Any(), url, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _ string, _ urlscanner.SubmitOptions) (urlscanner.SubmitRes, urlscanner.RateLimitStatus, error) {
+			require.Equal(t, traceID, trace.SpanContextFromContext(ctx).TraceID())
+
+			return urlscanner.SubmitRes{}, urlscanner.RateLimitStatus{}, errors.New("provider down")
+		},
+	)
+	st.EXPECT().RecordScanAttempts(gomock.Any(), url, domain.UserID{}, gomock.Any()).Return(nil)
+	st.EXPECT().UpdatePendingScansByURL(gomock.Any(), url, domain.UserID{}, gomock.Any()).Return(int64(1), nil)
+	_, err = s.Scan(args.Context(context.Background()), url, domain.UserID{}, urlscanner.SubmitOptions{})
+	require.Error(t, err)
+
+	scan := spanByName(t, exporter.GetSpans(), "scanner.Scan")
+	require.Equal(t, traceID, scan.SpanContext.TraceID())
+	enqueue := spanByName(t, exporter.GetSpans(), "scanner.Enqueue")
+	require.Equal(t, enqueue.SpanContext.SpanID(), scan.Parent.SpanID())
+}
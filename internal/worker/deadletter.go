@@ -0,0 +1,95 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"scanner/pkg/serrors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// DeadLetterFunc is invoked with a job that failed its last attempt and is
+// therefore discarded by River, along with the error of that attempt.
+type DeadLetterFunc func(ctx context.Context, job *rivertype.JobRow, err error)
+
+// DeadLetterHandler is a river.ErrorHandler that reports jobs exhausting their
+// MaxAttempts. River discards a job whose failed attempt was its last one, so
+// such failures are counted and passed on to an optional DeadLetterFunc.
+// Snoozed and explicitly canceled jobs never reach it. It never changes how
+// River handles the job.
+//
+// Metrics: The handler is a prometheus.Collector exposing the number of
+// discarded jobs as a counter labeled by the serrors kind of their last error,
+// INTERNAL for errors without a kind.
+type DeadLetterHandler struct {
+	// onDeadLetter is called for every discarded job. It may be nil.
+	onDeadLetter DeadLetterFunc
+	// discardedCounter counts discarded jobs by error kind.
+	discardedCounter *prometheus.CounterVec
+}
+
+// Ensure DeadLetterHandler implements river.ErrorHandler.
+var _ river.ErrorHandler = (*DeadLetterHandler)(nil)
+
+// NewDeadLetterHandler constructs a DeadLetterHandler calling onDeadLetter, if
+// not nil, for every discarded job.
+func NewDeadLetterHandler(onDeadLetter DeadLetterFunc) *DeadLetterHandler {
+	return &DeadLetterHandler{
+		onDeadLetter: onDeadLetter,
+		discardedCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "worker_dead_letter_jobs_total",
+			Help: "Number of jobs discarded after exhausting their attempts, by error kind.",
+		}, []string{"kind"}),
+	}
+}
+
+// HandleError implements river.ErrorHandler.
+func (h *DeadLetterHandler) HandleError(
+	ctx context.Context,
+	job *rivertype.JobRow,
+	err error,
+) *river.ErrorHandlerResult {
+	h.report(ctx, job, err)
+
+	return nil
+}
+
+// HandlePanic implements river.ErrorHandler.
+func (h *DeadLetterHandler) HandlePanic(
+	ctx context.Context,
+	job *rivertype.JobRow,
+	panicVal any,
+	_ string,
+) *river.ErrorHandlerResult {
+	h.report(ctx, job, fmt.Errorf("job panicked: %v", panicVal))
+
+	return nil
+}
+
+// report counts job and calls onDeadLetter if the failed attempt was its last.
+func (h *DeadLetterHandler) report(ctx context.Context, job *rivertype.JobRow, err error) {
+	if job.Attempt < job.MaxAttempts {
+		return
+	}
+
+	kind := serrors.KindOf(err)
+	if kind == nil {
+		kind = serrors.ErrInternal
+	}
+	h.discardedCounter.WithLabelValues(kind.Error()).Inc()
+	if h.onDeadLetter != nil {
+		h.onDeadLetter(ctx, job, err)
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (h *DeadLetterHandler) Describe(ch chan<- *prometheus.Desc) {
+	h.discardedCounter.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (h *DeadLetterHandler) Collect(ch chan<- prometheus.Metric) {
+	h.discardedCounter.Collect(ch)
+}
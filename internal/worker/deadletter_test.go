@@ -0,0 +1,76 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/riverqueue/river/rivertype"
+	"github.com/stretchr/testify/require"
+
+	"scanner/internal/worker"
+	"scanner/pkg/serrors"
+)
+
+// discardedCounts gathers the handler's metrics and returns the number of
+// discarded jobs by kind.
+func discardedCounts(t *testing.T, h *worker.DeadLetterHandler) map[string]float64 {
+	t.Helper()
+	reg := prometheus.NewPedanticRegistry()
+	require.NoError(t, reg.Register(h))
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	counts := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			counts[metric.GetLabel()[0].GetValue()] = metric.GetCounter().GetValue()
+		}
+	}
+
+	return counts
+}
+
+func TestDeadLetterHandler_DiscardedAfterMaxAttempts(t *testing.T) {
+	var got []*rivertype.JobRow
+	var gotErrs []error
+	h := worker.NewDeadLetterHandler(func(_ context.Context, job *rivertype.JobRow, err error) {
+		got = append(got, job)
+		gotErrs = append(gotErrs, err)
+	})
+	ctx := context.Background()
+	boom := errors.New("boom")
+
+	// attempts left: the job is retried, not reported
+	retried := &rivertype.JobRow{ID: 1, Attempt: 2, MaxAttempts: 3}
+	require.Nil(t, h.HandleError(ctx, retried, boom))
+	require.Empty(t, got)
+	require.Empty(t, discardedCounts(t, h))
+
+	// last attempt failed: the job is discarded
+	discarded := &rivertype.JobRow{ID: 1, Attempt: 3, MaxAttempts: 3}
+	require.Nil(t, h.HandleError(ctx, discarded, boom))
+	require.Equal(t, []*rivertype.JobRow{discarded}, got)
+	require.ErrorIs(t, gotErrs[0], boom)
+	require.Equal(t, map[string]float64{"INTERNAL": 1}, discardedCounts(t, h))
+
+	// a panic in the last attempt is reported as an error
+	panicked := &rivertype.JobRow{ID: 2, Attempt: 1, MaxAttempts: 1}
+	require.Nil(t, h.HandlePanic(ctx, panicked, "oops", "trace"))
+	require.Equal(t, []*rivertype.JobRow{discarded, panicked}, got)
+	require.ErrorContains(t, gotErrs[1], "oops")
+	require.Equal(t, map[string]float64{"INTERNAL": 2}, discardedCounts(t, h))
+
+	// jobs are counted by the kind of their last error
+	unavailable := serrors.Wrap(serrors.ErrUnavailable, boom, "could not scan URL")
+	require.Nil(t, h.HandleError(ctx, &rivertype.JobRow{ID: 3, Attempt: 3, MaxAttempts: 3}, unavailable))
+	require.Equal(t, map[string]float64{"INTERNAL": 2, "UNAVAILABLE": 1}, discardedCounts(t, h))
+}
+
+func TestDeadLetterHandler_NilCallback(t *testing.T) {
+	h := worker.NewDeadLetterHandler(nil)
+
+	require.Nil(t, h.HandleError(context.Background(), &rivertype.JobRow{Attempt: 1, MaxAttempts: 1}, errors.New("boom")))
+	require.Equal(t, map[string]float64{"INTERNAL": 1}, discardedCounts(t, h))
+}
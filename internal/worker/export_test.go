@@ -0,0 +1,17 @@
+package worker
+
+import (
+	"log/slog"
+
+	"github.com/riverqueue/river"
+)
+
+// RiverConfig exposes the river client configuration built by Start to tests.
+func RiverConfig(
+	o Options,
+	workers *river.Workers,
+	errorHandler river.ErrorHandler,
+	logger *slog.Logger,
+) *river.Config {
+	return o.riverConfig(workers, errorHandler, logger)
+}
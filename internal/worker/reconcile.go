@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"scanner/internal/scanner"
+	"scanner/pkg/clock"
+	"time"
+
+	"github.com/riverqueue/river"
+)
+
+// ReconcileJobKind is the River job kind of the periodic stale scan reconciliation.
+const ReconcileJobKind = "ReconcileStaleScansJob"
+
+// ReconcileArgs are the (empty) arguments of the periodic job reconciling
+// stale pending scans.
+type ReconcileArgs struct{}
+
+// Kind returns the River job kind used to register and dispatch the
+// reconciliation worker.
+func (ReconcileArgs) Kind() string { return ReconcileJobKind }
+
+// InsertOpts makes the job run once per period; a failed run is not retried
+// since the next period reconciles the same scans.
+func (ReconcileArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{MaxAttempts: 1}
+}
+
+// ReconcileWorker is a River worker recovering pending scans that were stuck,
+// e.g. because the worker processing them died mid-scan. Scans not updated
+// for staleAfter and without an active job are requeued, or failed when they
+// used up their attempts; see scanner.Scanner.ReconcileStaleScans.
+type ReconcileWorker struct {
+	river.WorkerDefaults[ReconcileArgs]
+
+	// scanner reconciles the stale scans.
+	scanner scanner.Scanner
+	// clock provides the time the staleness of scans is measured against.
+	clock clock.Clock
+	// staleAfter is how long a pending scan may go without updates before it
+	// is considered stale.
+	staleAfter time.Duration
+}
+
+// NewReconcileWorker constructs a ReconcileWorker reconciling the scans of
+// scanner that were not updated for options.StaleScanAfter; a nil clock uses
+// the real time.
+func NewReconcileWorker(scanner scanner.Scanner, clk clock.Clock, options Options) *ReconcileWorker {
+	return &ReconcileWorker{
+		scanner:    scanner,
+		clock:      clock.OrReal(clk),
+		staleAfter: options.StaleScanAfter,
+	}
+}
+
+// Work reconciles the pending scans that went stale.
+func (r *ReconcileWorker) Work(ctx context.Context, _ *river.Job[ReconcileArgs]) error {
+	if _, _, err := r.scanner.ReconcileStaleScans(ctx, r.clock.Now().Add(-r.staleAfter)); err != nil {
+		return fmt.Errorf("could not reconcile stale scans: %w", err)
+	}
+
+	return nil
+}
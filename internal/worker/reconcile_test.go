@@ -0,0 +1,34 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	mockscanner "scanner/internal/scanner/mock"
+	"scanner/internal/worker"
+	"scanner/pkg/clock"
+)
+
+func TestReconcileWorker_Work(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := mockscanner.NewMockScanner(ctrl)
+	clk := clock.NewFake(time.Now())
+	w := worker.NewReconcileWorker(mock, clk, worker.Options{StaleScanAfter: time.Hour})
+	job := &river.Job[worker.ReconcileArgs]{JobRow: &rivertype.JobRow{ID: 1}}
+
+	// scans not updated for StaleScanAfter are reconciled
+	mock.EXPECT().ReconcileStaleScans(gomock.Any(), clk.Now().Add(-time.Hour)).Return(2, 1, nil)
+	require.NoError(t, w.Work(context.Background(), job))
+
+	mock.EXPECT().ReconcileStaleScans(gomock.Any(), gomock.Any()).Return(0, 0, errors.New("db down"))
+	require.ErrorContains(t, w.Work(context.Background(), job), "db down")
+}
@@ -4,13 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand/v2"
+	"net/url"
 	"scanner/internal/scanner"
+	"scanner/pkg/clock"
 	"scanner/pkg/logger"
 	"scanner/pkg/serrors"
+	"scanner/pkg/storage"
 	"scanner/pkg/urlscanner"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/riverqueue/river"
 	"go.uber.org/zap"
 )
@@ -35,75 +41,248 @@ import (
 // multiple concurrent requests as long as they do not exceed the Remaining budget.
 // When there is no budget left, reserveRL waits until either:
 //   - the ResetAt time is reached (budget replenishes to Limit), or
-//   - another in-flight request finishes and signals requestFinishedChan.
+//   - another in-flight request finishes and closes requestFinishedChan.
 //
 // After a request completes, requestFinished is called with the server-provided
 // urlscanner.RateLimitStatus gathered from the response. It decrements the
-// inFlightRequests counter, notifies any goroutines waiting in reserveRL by sending a
-// message on requestFinishedChan (non-blocking), and updates lastRLStatus. The
+// inFlightRequests counter, notifies all goroutines waiting in reserveRL by closing
+// requestFinishedChan and replacing it with a new one, and updates lastRLStatus. The
 // update strategy prefers the freshest ResetAt and the lowest Remaining to avoid
 // optimistic races when multiple concurrent requests report slightly different views
 // of the budget. If ResetAt changes, it is always adopted. Otherwise, Remaining is
 // only replaced when it decreases, which is conservative and prevents overuse.
 //
+// # Per-host limit
+//
+// With Options.MaxPerHost set, reserveRL also waits until fewer than MaxPerHost
+// scans of the job URL's host are in flight (hostInFlight), so that many URLs
+// of one target are not scanned at once. Such waits end when a request
+// finishes, as the rate-limit budget does not affect them.
+//
+// # Priority scans
+//
+// With Options.UrgentBudget set, that many units of each window's budget are
+// reserved for jobs whose JobArgs.Urgent is set. Other jobs only start while
+// more than the unused reserved units remain, so urgent jobs skip the queue of
+// jobs waiting for budget. Urgent jobs draw from the shared budget first and
+// from the reserved units (reservedInFlight) only when it is used up. The
+// reservation is capped at Limit-1, so urgent jobs can never starve other jobs.
+//
+// Persistence: Every adopted rate-limit status is saved through rlStorage, and
+// LoadRLStatus restores it when the worker starts. This lets a restarted worker
+// resume with the last known budget instead of probing the upstream API again.
+//
 // Bootstrap behavior: At startup, before any API call has returned a rate-limit
-// status, lastRLStatus is initialized to a synthetic status with Limit=1,
-// Remaining=1, and a far-future ResetAt. This permits exactly one request to go
-// through so we can obtain real rate-limit headers from the upstream API. Subsequent
-// requests use actual data.
+// status and when no persisted status exists, lastRLStatus is initialized to a
+// synthetic status with Limit=1, Remaining=1, and a far-future ResetAt. This
+// permits exactly one request to go through so we can obtain real rate-limit
+// headers from the upstream API. Subsequent requests use actual data.
 //
 // Concurrency safety: All rate-limit mutable state is guarded by mu. The
 // requestFinishedChan is used as a wake-up signal for waiters without accumulating
-// backpressure; send is non-blocking and dropped if no one is waiting.
+// backpressure. Waiters read it while holding mu, so a request finishing after
+// they evaluated the budget always wakes them.
+//
+// Metrics: The worker is a prometheus.Collector exposing the last known
+// rate-limit budget and the number of in-flight scans as gauges. They are
+// updated while holding mu, whenever the corresponding state changes.
 //
 // Error handling: If the scan returns a conflict, the job is canceled. If the scan
 // indicates upstream rate limiting, the job is snoozed until ResetAt (deferring
-// retry). Other errors are logged and returned.
+// retry) plus a random jitter, capped at Options.MaxSnooze. Other errors are
+// logged and returned as a serrors.Error of the same kind, or of ErrInternal if
+// they have none.
 type URLScannerWorker struct {
 	river.WorkerDefaults[scanner.JobArgs]
 
 	// scanner performs the actual URL scan and returns rate-limit status from the
 	// upstream API alongside any error.
 	scanner scanner.Scanner
-	// mu protects all fields below it: inFlightRequests and lastRLStatus.
+	// rlStorage persists the last known rate-limit status across restarts. It may
+	// be nil, in which case the status is only kept in memory.
+	rlStorage storage.RateLimitStorage
+	// clock provides the time the rate-limit windows are compared against.
+	clock clock.Clock
+	// maxSnooze caps how long a rate-limited job is snoozed; <= 0 disables the cap.
+	maxSnooze time.Duration
+	// snoozeJitter is the upper bound of the random delay added to snoozes.
+	snoozeJitter time.Duration
+	// int64N returns a random number in [0, n) and is used to add jitter to snoozes.
+	int64N func(n int64) int64
+	// maxPerHost is the maximum number of in-flight scans per URL host; <= 0
+	// disables the limit.
+	maxPerHost int
+	// urgentBudget is the part of the budget reserved for urgent jobs; <= 0
+	// disables it.
+	urgentBudget int
+	// persistMu serializes the writes of lastRLStatus to rlStorage, so that a
+	// slow write of an older status cannot land after a newer one.
+	persistMu sync.Mutex
+	// mu protects all fields below it: inFlightRequests, reservedInFlight,
+	// hostInFlight, lastRLStatus and requestFinishedChan.
 	mu sync.Mutex
 	// inFlightRequests counts how many scans are currently running. It is used in
 	// conjunction with lastRLStatus.Remaining to decide if another request may start.
 	inFlightRequests int
+	// reservedInFlight counts the running urgent scans that use the reserved
+	// budget. They are included in inFlightRequests.
+	reservedInFlight int
+	// hostInFlight counts the running scans per URL host when maxPerHost is set.
+	hostInFlight map[string]int
 	// lastRLStatus stores the most recent view of the upstream rate-limit headers.
 	// It is updated after each request, preferring newer ResetAt and lower Remaining
 	// to avoid optimistic races between concurrent requests.
 	lastRLStatus *urlscanner.RateLimitStatus
-	// requestFinishedChan is a non-buffered notification channel used to wake up
-	// goroutines waiting in reserveRL when any in-flight request completes.
+	// requestFinishedChan is closed and replaced to wake up goroutines waiting
+	// in reserveRL when any in-flight request completes.
 	requestFinishedChan chan struct{}
+	// rlRemainingGauge reports lastRLStatus.Remaining.
+	rlRemainingGauge prometheus.Gauge
+	// rlLimitGauge reports lastRLStatus.Limit.
+	rlLimitGauge prometheus.Gauge
+	// inFlightGauge reports inFlightRequests.
+	inFlightGauge prometheus.Gauge
 }
 
-// NewURLScannerWorker constructs a URLScannerWorker using the provided scanner.
-// The returned worker enforces cooperative rate limiting across
-// its concurrent jobs.
-func NewURLScannerWorker(scanner scanner.Scanner) *URLScannerWorker {
+// rlStorageKey is the key under which the upstream rate-limit status is persisted.
+const rlStorageKey = "urlscanner.rateLimitStatus"
+
+// NewURLScannerWorker constructs a URLScannerWorker using the provided scanner,
+// rate-limit storage and clock; a nil clock uses the real time. Snoozes of
+// rate-limited jobs are bounded by options.MaxSnooze and options.SnoozeJitter,
+// and options.UrgentBudget is reserved for urgent jobs. The returned
+// worker enforces cooperative rate limiting across its concurrent jobs. Call
+// LoadRLStatus before processing jobs to resume from a previously persisted
+// rate-limit status.
+func NewURLScannerWorker(
+	scanner scanner.Scanner,
+	rlStorage storage.RateLimitStorage,
+	clk clock.Clock,
+	options Options,
+) *URLScannerWorker {
 	return &URLScannerWorker{
 		scanner:             scanner,
+		rlStorage:           rlStorage,
+		clock:               clock.OrReal(clk),
+		maxSnooze:           options.MaxSnooze,
+		snoozeJitter:        options.SnoozeJitter,
+		int64N:              rand.Int64N, //nolint: gosec
+		maxPerHost:          options.MaxPerHost,
+		urgentBudget:        options.UrgentBudget,
+		hostInFlight:        make(map[string]int),
 		requestFinishedChan: make(chan struct{}),
+		rlRemainingGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "urlscanner_rate_limit_remaining",
+			Help: "Remaining urlscan.io request budget in the current rate-limit window.",
+		}),
+		rlLimitGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "urlscanner_rate_limit_limit",
+			Help: "urlscan.io request budget of a rate-limit window.",
+		}),
+		inFlightGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "urlscanner_in_flight_requests",
+			Help: "Number of urlscan.io scans currently in flight.",
+		}),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (u *URLScannerWorker) Describe(ch chan<- *prometheus.Desc) {
+	u.rlRemainingGauge.Describe(ch)
+	u.rlLimitGauge.Describe(ch)
+	u.inFlightGauge.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (u *URLScannerWorker) Collect(ch chan<- prometheus.Metric) {
+	u.rlRemainingGauge.Collect(ch)
+	u.rlLimitGauge.Collect(ch)
+	u.inFlightGauge.Collect(ch)
+}
+
+// updateMetrics sets the gauges from the current rate-limit state. Callers
+// must hold mu.
+func (u *URLScannerWorker) updateMetrics() {
+	u.inFlightGauge.Set(float64(u.inFlightRequests))
+	if u.lastRLStatus != nil {
+		u.rlRemainingGauge.Set(float64(u.lastRLStatus.Remaining))
+		u.rlLimitGauge.Set(float64(u.lastRLStatus.Limit))
 	}
 }
 
+// LoadRLStatus restores the last persisted rate-limit status from rlStorage.
+// When nothing has been persisted yet, the worker keeps its synthetic bootstrap
+// behavior and probes the upstream API with a single request.
+func (u *URLScannerWorker) LoadRLStatus(ctx context.Context) error {
+	if u.rlStorage == nil {
+		return nil
+	}
+
+	status, err := u.rlStorage.RateLimitStatus(ctx, rlStorageKey)
+	if err != nil {
+		return fmt.Errorf("could not load rate limit status: %w", err)
+	}
+	if status == nil {
+		logger.Info(ctx, "no persisted rate limit status found, will probe upstream API")
+
+		return nil
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.lastRLStatus = status
+	u.updateMetrics()
+	logger.Info(ctx, "loaded persisted rate limit status",
+		zap.Int("limit", status.Limit),
+		zap.Int("remaining", status.Remaining),
+		zap.Time("resetAt", status.ResetAt))
+
+	return nil
+}
+
+// InFlight returns the number of scans that reserved rate-limit budget and have
+// not finished yet. It is used to report and drain running scans on shutdown.
+func (u *URLScannerWorker) InFlight() int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.inFlightRequests
+}
+
+// Timeout returns the job-specific timeout carried by the job arguments. Zero
+// makes River fall back to the client's global JobTimeout.
+func (u *URLScannerWorker) Timeout(job *river.Job[scanner.JobArgs]) time.Duration {
+	return job.Args.Timeout
+}
+
 // Work executes a single scan job while respecting rate limits
 // It reserves rate-limit budget, runs the scan, updates the
 // internal rate-limit state, and maps errors to appropriate River actions.
 func (u *URLScannerWorker) Work(ctx context.Context, job *river.Job[scanner.JobArgs]) error {
+	// continue the trace of the request that enqueued the job, so that the
+	// urlscan.io calls carry its traceparent
+	ctx = job.Args.Context(ctx)
 	ctx = logger.WithFields(ctx, zap.Int64("jobID", job.ID), zap.String("URL", job.Args.URL))
 
+	owner, err := job.Args.OwnerID()
+	if err != nil {
+		return river.JobCancel(serrors.Wrap(serrors.ErrBadRequest, err, "invalid job owner")) //nolint: wrapcheck
+	}
+
 	// try to reserve a rate limit slot
-	if err := u.reserveRL(ctx); err != nil {
+	res, err := u.reserveRL(ctx, urlHost(job.Args.URL), job.Args.Urgent)
+	if err != nil {
 		logger.Error(ctx, "error reserving rate limit", zap.Error(err))
 
 		return fmt.Errorf("could not reserve rate limit: %w", err)
 	}
 
-	RLStatus, err := u.scanner.Scan(ctx, job.Args.URL)
-	u.requestFinished(ctx, RLStatus)
+	RLStatus, err := u.scanner.Scan(ctx, job.Args.URL, owner, urlscanner.SubmitOptions{
+		Tags:       job.Args.Tags,
+		Visibility: job.Args.Visibility,
+	})
+	u.requestFinished(ctx, res, RLStatus)
 	if err != nil {
 		if errors.Is(err, serrors.ErrConflict) {
 			return river.JobCancel(err) //nolint: wrapcheck
@@ -112,15 +291,17 @@ func (u *URLScannerWorker) Work(ctx context.Context, job *river.Job[scanner.JobA
 		logger.Error(ctx, "error in scanning URL", zap.Error(err))
 
 		if errors.Is(err, serrors.ErrRateLimited) {
-			dur := time.Until(RLStatus.ResetAt)
-			if dur < 0 {
-				dur = 0
-			}
+			return river.JobSnooze(u.snoozeDuration(RLStatus.ResetAt)) //nolint: wrapcheck
+		}
 
-			return river.JobSnooze(dur) //nolint: wrapcheck
+		// keep the semantic kind of the failure, so that the error handler can
+		// group failed jobs by it
+		kind := serrors.KindOf(err)
+		if kind == nil {
+			kind = serrors.ErrInternal
 		}
 
-		return fmt.Errorf("could not scan URL: %w", err)
+		return serrors.Wrap(kind, err, "could not scan URL")
 	}
 
 	logger.Info(ctx, "URL scanned successfully")
@@ -128,13 +309,89 @@ func (u *URLScannerWorker) Work(ctx context.Context, job *river.Job[scanner.JobA
 	return nil
 }
 
-// requestFinished is called after every scan attempt. It decrements the in-flight
-// counter, notifies any goroutines waiting to reserve rate limit, and updates the
-// last known rate-limit status using a conservative merge strategy to avoid races
-// between concurrent requests.
-func (u *URLScannerWorker) requestFinished(ctx context.Context, newRLStatus urlscanner.RateLimitStatus) {
+// snoozeDuration returns how long a rate-limited job is snoozed: until resetAt
+// plus a random jitter in [0, snoozeJitter), so that snoozed jobs do not all
+// wake up at once. Durations above maxSnooze are replaced by a random one in
+// [maxSnooze/2, maxSnooze], since a far reset would otherwise hold the job back
+// for the whole window.
+func (u *URLScannerWorker) snoozeDuration(resetAt time.Time) time.Duration {
+	dur := max(resetAt.Sub(u.clock.Now()), 0)
+	if u.snoozeJitter > 0 {
+		dur += time.Duration(u.int64N(int64(u.snoozeJitter)))
+	}
+	if u.maxSnooze > 0 && dur > u.maxSnooze {
+		dur = scanner.Jitter(u.maxSnooze, u.int64N)
+	}
+
+	return dur
+}
+
+// urlHost returns the host of rawURL that per-host limits are keyed by, or
+// rawURL itself if it cannot be parsed.
+func urlHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	return strings.ToLower(u.Hostname())
+}
+
+// reservation is what reserveRL reserved for a scan, released by
+// requestFinished.
+type reservation struct {
+	// host is the URL host whose slot was reserved.
+	host string
+	// reserved reports whether the scan uses the budget reserved for urgent jobs.
+	reserved bool
+}
+
+// requestFinished is called after every scan attempt holding res. It
+// decrements the in-flight counters, notifies any goroutines waiting to reserve
+// rate limit, and updates the last known rate-limit status using a conservative
+// merge strategy to avoid races between concurrent requests. Adopted statuses
+// are persisted to rlStorage.
+func (u *URLScannerWorker) requestFinished(
+	ctx context.Context,
+	res reservation,
+	newRLStatus urlscanner.RateLimitStatus,
+) {
+	if !u.updateRLStatus(ctx, res, newRLStatus) || u.rlStorage == nil {
+		return
+	}
+
+	u.persistRLStatus(ctx)
+}
+
+// persistRLStatus writes lastRLStatus to rlStorage while holding persistMu.
+// The status is read once the lock is held rather than passed in, so every
+// write stores the newest status adopted so far and the last write wins with
+// the newest one even when concurrent requests adopted theirs out of order.
+func (u *URLScannerWorker) persistRLStatus(ctx context.Context) {
+	u.persistMu.Lock()
+	defer u.persistMu.Unlock()
+
+	u.mu.Lock()
+	status := *u.lastRLStatus
+	u.mu.Unlock()
+
+	// persistence is best effort, the in-memory status is already up to date.
+	if err := u.rlStorage.StoreRateLimitStatus(ctx, rlStorageKey, status); err != nil {
+		logger.Warn(ctx, "could not persist rate limit status", zap.Error(err))
+	}
+}
+
+// updateRLStatus applies the bookkeeping of requestFinished while holding mu and
+// reports whether newRLStatus was adopted as the last known status.
+func (u *URLScannerWorker) updateRLStatus(
+	ctx context.Context,
+	res reservation,
+	newRLStatus urlscanner.RateLimitStatus,
+) bool {
 	u.mu.Lock()
 	defer u.mu.Unlock()
+	// runs before the unlock above
+	defer u.updateMetrics()
 
 	if u.inFlightRequests > 0 {
 		u.inFlightRequests--
@@ -142,21 +399,24 @@ func (u *URLScannerWorker) requestFinished(ctx context.Context, newRLStatus urls
 		// Defensive clamp: avoid negative values in case of unexpected sequencing.
 		u.inFlightRequests = 0
 	}
-
-	// If other goroutines are blocked in reserveRL, try to wake as many
-	// as possible. If no one is waiting, the signal is dropped.
-l:
-	for {
-		select {
-		case u.requestFinishedChan <- struct{}{}:
-		default:
-			break l
+	if res.reserved && u.reservedInFlight > 0 {
+		u.reservedInFlight--
+	}
+	if u.maxPerHost > 0 {
+		if u.hostInFlight[res.host] > 1 {
+			u.hostInFlight[res.host]--
+		} else {
+			delete(u.hostInFlight, res.host)
 		}
 	}
 
+	// Wake all goroutines blocked in reserveRL; later waiters use the new channel.
+	close(u.requestFinishedChan)
+	u.requestFinishedChan = make(chan struct{})
+
 	// If the call didn't return any RL info, don't change our view.
 	if newRLStatus.ResetAt.IsZero() {
-		return
+		return false
 	}
 
 	log := func() {
@@ -172,7 +432,7 @@ l:
 		u.lastRLStatus = &newRLStatus
 		log()
 
-		return
+		return true
 	}
 
 	// If ResetAt changed, always adopt the new window.
@@ -180,29 +440,37 @@ l:
 		u.lastRLStatus = &newRLStatus
 		log()
 
-		return
+		return true
 	}
 
 	// Otherwise prefer the lower Remaining to stay conservative under concurrency.
 	if newRLStatus.Remaining < u.lastRLStatus.Remaining {
 		u.lastRLStatus = &newRLStatus
 		log()
+
+		return true
 	}
+
+	return false
 }
 
-// reserveRL reserves one unit from the rate-limit budget or blocks until a unit
-// becomes available. It implements the cooperative rate limiting described in the
-// type-level comment:
+// reserveRL reserves one unit from the rate-limit budget, and a slot of host if
+// maxPerHost is set, or blocks until both become available. Urgent scans may
+// also use the budget reserved for them. It implements the cooperative rate
+// limiting described in the type-level comment:
 //  1. On first use, initialize a synthetic RL state to allow a single probe
 //     request to gather real headers.
 //  2. Compute effective remaining budget; if we've passed ResetAt, Remaining is
 //     treated as Limit.
-//  3. If remaining - inFlightRequests > 0, increment inFlightRequests and return.
-//  4. Otherwise, wait until either ResetAt elapses or any in-flight request
-//     completes (signaled via requestFinishedChan), then retry.
+//  3. If remaining - inFlightRequests, less the unused reserved budget, is > 0,
+//     or the scan is urgent and reserved budget is left, and host has a free
+//     slot, increment inFlightRequests and the host's count and return.
+//  4. Otherwise, wait until any in-flight request completes (signaled via
+//     requestFinishedChan) or, if the budget is used up, ResetAt elapses, then
+//     retry.
 //
 // If ctx is canceled while waiting, an error is returned.
-func (u *URLScannerWorker) reserveRL(ctx context.Context) error {
+func (u *URLScannerWorker) reserveRL(ctx context.Context, host string, urgent bool) (reservation, error) {
 	for {
 		u.mu.Lock()
 
@@ -213,48 +481,69 @@ func (u *URLScannerWorker) reserveRL(ctx context.Context) error {
 				Remaining: 1,
 				// Far-future reset so the first reservation doesn't
 				// unblock due to a timer; we'll replace this with real headers soon.
-				ResetAt: time.Now().Add(365 * 24 * time.Hour),
+				ResetAt: u.clock.Now().Add(365 * 24 * time.Hour),
 			}
 		}
 
 		remaining := u.lastRLStatus.Remaining
-		// If the reset time has passed, treat the full limit as remaining.
-		if time.Now().UTC().After(u.lastRLStatus.ResetAt) {
+		// If the reset time has been reached, treat the full limit as remaining.
+		if !u.clock.Now().Before(u.lastRLStatus.ResetAt) {
 			remaining = u.lastRLStatus.Limit
 		}
 
+		// Keep at least one unit of the window for other jobs.
+		reserve := max(min(u.urgentBudget, u.lastRLStatus.Limit-1), 0)
+		free := remaining - u.inFlightRequests
+		sharedBudget := free-max(reserve-u.reservedInFlight, 0) > 0
+		reservedBudget := urgent && free > 0 && u.reservedInFlight < reserve
+		hasBudget := sharedBudget || reservedBudget
+		hostFull := u.maxPerHost > 0 && u.hostInFlight[host] >= u.maxPerHost
+
 		// If budget remains once we account for in-flight requests, reserve and go.
-		if remaining-u.inFlightRequests > 0 {
+		if hasBudget && !hostFull {
 			logger.Debug(ctx, "reserved rate limit slot",
 				zap.Int("remaining", remaining),
 				zap.Int("limit", u.lastRLStatus.Limit),
 				zap.Time("resetAt", u.lastRLStatus.ResetAt),
-				zap.Int("inFlight", u.inFlightRequests))
+				zap.Int("inFlight", u.inFlightRequests),
+				zap.Bool("urgent", urgent))
+			res := reservation{host: host, reserved: !sharedBudget}
 			u.inFlightRequests++
+			if res.reserved {
+				u.reservedInFlight++
+			}
+			if u.maxPerHost > 0 {
+				u.hostInFlight[host]++
+			}
+			u.updateMetrics()
 			u.mu.Unlock()
 
-			return nil
+			return res, nil
 		}
 
-		// Otherwise, wait for either the reset time (if in the future) or for any
-		// request to finish, then retry.
-		waitTime := time.Until(u.lastRLStatus.ResetAt)
-		u.mu.Unlock()
-		var waitCH <-chan time.Time
-		if waitTime > 0 {
-			waitCH = time.After(waitTime)
+		// Otherwise, wait for any request to finish or, without budget, for the
+		// reset time (if in the future), then retry.
+		var waitTime time.Duration
+		if !hasBudget {
+			waitTime = u.lastRLStatus.ResetAt.Sub(u.clock.Now())
 		}
-
 		logger.Debug(ctx, "waiting for rate limit slot or other requests to finish",
 			zap.Int("remaining", remaining),
 			zap.Int("limit", u.lastRLStatus.Limit),
 			zap.Time("resetAt", u.lastRLStatus.ResetAt),
-			zap.Int("inFlight", u.inFlightRequests))
+			zap.Int("inFlight", u.inFlightRequests),
+			zap.Bool("hostFull", hostFull))
+		requestFinished := u.requestFinishedChan
+		u.mu.Unlock()
+		var waitCH <-chan time.Time
+		if waitTime > 0 {
+			waitCH = u.clock.After(waitTime)
+		}
 
 		select {
 		case <-ctx.Done():
-			return fmt.Errorf("timeout waiting for rate limit: %w", ctx.Err())
-		case <-u.requestFinishedChan:
+			return reservation{}, fmt.Errorf("timeout waiting for rate limit: %w", ctx.Err())
+		case <-requestFinished:
 			// loop to re-evaluate
 			continue
 		case <-waitCH:
@@ -263,5 +552,3 @@ func (u *URLScannerWorker) reserveRL(ctx context.Context) error {
 		}
 	}
 }
-
-// TODO: add prometheus metrics for workers
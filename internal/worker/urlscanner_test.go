@@ -3,19 +3,27 @@ package worker_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/riverqueue/river"
 	"github.com/riverqueue/river/rivertype"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/mock/gomock"
 
 	"scanner/internal/scanner"
 	mockscanner "scanner/internal/scanner/mock"
 	"scanner/internal/worker"
+	"scanner/pkg/clock"
+	"scanner/pkg/domain"
 	"scanner/pkg/logger"
 	"scanner/pkg/serrors"
+	mockstorage "scanner/pkg/storage/mock"
 	"scanner/pkg/urlscanner"
 )
 
@@ -31,29 +39,103 @@ func makeJob(id int64, url string) *river.Job[scanner.JobArgs] {
 	}
 }
 
+func TestURLScannerWorker_Timeout(t *testing.T) {
+	w := worker.NewURLScannerWorker(nil, nil, nil, worker.Options{})
+
+	job := makeJob(1, "https://ok")
+	require.Zero(t, w.Timeout(job), "zero falls back to the global job timeout")
+
+	job.Args.Timeout = 5 * time.Minute
+	require.Equal(t, 5*time.Minute, w.Timeout(job))
+}
+
 func TestURLScannerWorker_Work_Success(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mock := mockscanner.NewMockScanner(ctrl)
-	w := worker.NewURLScannerWorker(mock)
+	w := worker.NewURLScannerWorker(mock, nil, nil, worker.Options{})
 
 	// Return some RL status that should be adopted on first success
 	rl := urlscanner.RateLimitStatus{Limit: 100, Remaining: 99, ResetAt: time.Now().Add(time.Minute)}
-	mock.EXPECT().Scan(gomock.Any(), "https://ok").Return(rl, nil)
+	mock.EXPECT().Scan(gomock.Any(), "https://ok", domain.UserID{}, gomock.Any()).Return(rl, nil)
 
 	require.NoError(t, w.Work(context.Background(), makeJob(1, "https://ok")))
 }
 
+func TestURLScannerWorker_Work_SubmitsTags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := mockscanner.NewMockScanner(ctrl)
+	w := worker.NewURLScannerWorker(mock, nil, nil, worker.Options{})
+
+	rl := urlscanner.RateLimitStatus{Limit: 100, Remaining: 99, ResetAt: time.Now().Add(time.Minute)}
+	mock.EXPECT().Scan(gomock.Any(), "https://ok", domain.UserID{}, urlscanner.SubmitOptions{Tags: []string{"a", "b"}}).
+		Return(rl, nil)
+
+	job := makeJob(1, "https://ok")
+	job.Args.Tags = []string{"a", "b"}
+	require.NoError(t, w.Work(context.Background(), job))
+}
+
+func TestURLScannerWorker_Work_ContinuesEnqueuedTrace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := mockscanner.NewMockScanner(ctrl)
+	w := worker.NewURLScannerWorker(mock, nil, nil, worker.Options{})
+
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	mock.EXPECT().Scan(gomock.Any(), "https://ok", domain.UserID{}, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _ string, _ domain.UserID, _ urlscanner.SubmitOptions) (urlscanner.RateLimitStatus, error) {
+			require.Equal(t, traceID, trace.SpanContextFromContext(ctx).TraceID().String())
+
+			return urlscanner.RateLimitStatus{}, nil
+		},
+	)
+
+	job := makeJob(1, "https://ok")
+	job.Args.TraceContext = map[string]string{"traceparent": "00-" + traceID + "-00f067aa0ba902b7-01"}
+	require.NoError(t, w.Work(context.Background(), job))
+}
+
+func TestURLScannerWorker_Work_ScansOwnerScans(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := mockscanner.NewMockScanner(ctrl)
+	w := worker.NewURLScannerWorker(mock, nil, nil, worker.Options{})
+
+	owner := domain.UserID(uuid.New())
+	rl := urlscanner.RateLimitStatus{Limit: 100, Remaining: 99, ResetAt: time.Now().Add(time.Minute)}
+	opts := urlscanner.SubmitOptions{Visibility: urlscanner.VisibilityPrivate}
+	mock.EXPECT().Scan(gomock.Any(), "https://ok", owner, opts).Return(rl, nil)
+
+	job := makeJob(1, "https://ok")
+	job.Args.Visibility = urlscanner.VisibilityPrivate
+	job.Args.Owner = owner.String()
+	require.NoError(t, w.Work(context.Background(), job))
+
+	// jobs with an invalid owner are canceled without scanning
+	job.Args.Owner = "not-a-user"
+	err := w.Work(context.Background(), job)
+	var cancelErr *river.JobCancelError
+	require.ErrorAs(t, err, &cancelErr)
+	require.ErrorIs(t, err, serrors.ErrBadRequest)
+	require.Zero(t, w.InFlight())
+}
+
 func TestURLScannerWorker_Work_ConflictCancels(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mock := mockscanner.NewMockScanner(ctrl)
-	w := worker.NewURLScannerWorker(mock)
+	w := worker.NewURLScannerWorker(mock, nil, nil, worker.Options{})
 
 	rl := urlscanner.RateLimitStatus{Limit: 100, Remaining: 100, ResetAt: time.Now().Add(time.Minute)}
-	mock.EXPECT().Scan(gomock.Any(), "https://conflict").Return(rl, serrors.With(serrors.ErrConflict, "dupe"))
+	mock.EXPECT().Scan(gomock.Any(), "https://conflict", domain.UserID{}, gomock.Any()).
+		Return(rl, serrors.With(serrors.ErrConflict, "dupe"))
 
 	err := w.Work(context.Background(), makeJob(2, "https://conflict"))
 	require.Error(t, err)
@@ -66,19 +148,77 @@ func TestURLScannerWorker_Work_RateLimitedSnoozes(t *testing.T) {
 	defer ctrl.Finish()
 
 	mock := mockscanner.NewMockScanner(ctrl)
-	w := worker.NewURLScannerWorker(mock)
+	clk := clock.NewFake(time.Now())
+	w := worker.NewURLScannerWorker(mock, nil, clk, worker.Options{})
 
-	resetAt := time.Now().Add(1500 * time.Millisecond)
+	resetAt := clk.Now().Add(1500 * time.Millisecond)
 	rl := urlscanner.RateLimitStatus{Limit: 100, Remaining: 0, ResetAt: resetAt}
-	mock.EXPECT().Scan(gomock.Any(), "https://rl").Return(rl, serrors.With(serrors.ErrRateLimited, "provider rl"))
+	mock.EXPECT().Scan(gomock.Any(), "https://rl", domain.UserID{}, gomock.Any()).
+		Return(rl, serrors.With(serrors.ErrRateLimited, "provider rl"))
 
 	err := w.Work(context.Background(), makeJob(3, "https://rl"))
 	require.Error(t, err)
 	var snoozeErr *river.JobSnoozeError
 	require.ErrorAs(t, err, &snoozeErr)
-	// Duration should be around time.Until(resetAt)
-	require.GreaterOrEqual(t, snoozeErr.Duration, 1200*time.Millisecond)
-	require.LessOrEqual(t, snoozeErr.Duration, 2*time.Second)
+	// the job is snoozed until the rate limit resets
+	require.Equal(t, 1500*time.Millisecond, snoozeErr.Duration)
+}
+
+func TestURLScannerWorker_Work_RateLimitedSnoozeCapped(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := mockscanner.NewMockScanner(ctrl)
+	clk := clock.NewFake(time.Now())
+	options := worker.Options{
+		MaxSnooze:    10 * time.Minute,
+		SnoozeJitter: 5 * time.Second,
+	}
+
+	// the provider reports a reset far in the future
+	rl := urlscanner.RateLimitStatus{Limit: 100, Remaining: 0, ResetAt: clk.Now().Add(24 * time.Hour)}
+	mock.EXPECT().Scan(gomock.Any(), "https://rl", domain.UserID{}, gomock.Any()).
+		Return(rl, serrors.With(serrors.ErrRateLimited, "provider rl")).Times(20)
+
+	for range 20 {
+		// a fresh worker each time, since a rate-limited worker waits for the reset
+		w := worker.NewURLScannerWorker(mock, nil, clk, options)
+		err := w.Work(context.Background(), makeJob(3, "https://rl"))
+		var snoozeErr *river.JobSnoozeError
+		require.ErrorAs(t, err, &snoozeErr)
+		require.LessOrEqual(t, snoozeErr.Duration, 10*time.Minute)
+		require.GreaterOrEqual(t, snoozeErr.Duration, 5*time.Minute)
+	}
+}
+
+func TestURLScannerWorker_Work_RateLimitedSnoozeJitter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := mockscanner.NewMockScanner(ctrl)
+	clk := clock.NewFake(time.Now())
+	options := worker.Options{
+		MaxSnooze:    10 * time.Minute,
+		SnoozeJitter: 5 * time.Second,
+	}
+
+	rl := urlscanner.RateLimitStatus{Limit: 100, Remaining: 0, ResetAt: clk.Now().Add(time.Minute)}
+	mock.EXPECT().Scan(gomock.Any(), "https://rl", domain.UserID{}, gomock.Any()).
+		Return(rl, serrors.With(serrors.ErrRateLimited, "provider rl")).Times(20)
+
+	durations := make(map[time.Duration]struct{})
+	for range 20 {
+		// a fresh worker each time, since a rate-limited worker waits for the reset
+		w := worker.NewURLScannerWorker(mock, nil, clk, options)
+		err := w.Work(context.Background(), makeJob(3, "https://rl"))
+		var snoozeErr *river.JobSnoozeError
+		require.ErrorAs(t, err, &snoozeErr)
+		// snoozed until the reset, plus at most the jitter
+		require.GreaterOrEqual(t, snoozeErr.Duration, time.Minute)
+		require.Less(t, snoozeErr.Duration, time.Minute+5*time.Second)
+		durations[snoozeErr.Duration] = struct{}{}
+	}
+	require.Greater(t, len(durations), 1, "expected jittered snoozes")
 }
 
 func TestURLScannerWorker_Work_GenericErrorWrapped(t *testing.T) {
@@ -86,11 +226,11 @@ func TestURLScannerWorker_Work_GenericErrorWrapped(t *testing.T) {
 	defer ctrl.Finish()
 
 	mock := mockscanner.NewMockScanner(ctrl)
-	w := worker.NewURLScannerWorker(mock)
+	w := worker.NewURLScannerWorker(mock, nil, nil, worker.Options{})
 
 	rl := urlscanner.RateLimitStatus{Limit: 100, Remaining: 100, ResetAt: time.Now().Add(time.Minute)}
 	scanErr := errors.New("boom")
-	mock.EXPECT().Scan(gomock.Any(), "https://err").Return(rl, scanErr)
+	mock.EXPECT().Scan(gomock.Any(), "https://err", domain.UserID{}, gomock.Any()).Return(rl, scanErr)
 
 	err := w.Work(context.Background(), makeJob(4, "https://err"))
 	require.Error(t, err)
@@ -98,6 +238,29 @@ func TestURLScannerWorker_Work_GenericErrorWrapped(t *testing.T) {
 	require.NotErrorAs(t, err, &cancelErr, "did not expect JobCancelError")
 	var snoozeErr *river.JobSnoozeError
 	require.NotErrorAs(t, err, &snoozeErr, "did not expect JobSnoozeError")
+	// errors without a kind are reported as internal
+	require.ErrorIs(t, err, scanErr)
+	require.Equal(t, serrors.ErrInternal, serrors.KindOf(err))
+}
+
+func TestURLScannerWorker_Work_ErrorKeepsKind(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := mockscanner.NewMockScanner(ctrl)
+	w := worker.NewURLScannerWorker(mock, nil, nil, worker.Options{})
+
+	rl := urlscanner.RateLimitStatus{Limit: 100, Remaining: 100, ResetAt: time.Now().Add(time.Minute)}
+	cause := errors.New("connection refused")
+	scanErr := fmt.Errorf("could not submit scan: %w", serrors.Wrap(serrors.ErrUnavailable, cause, "upstream down"))
+	mock.EXPECT().Scan(gomock.Any(), "https://err", domain.UserID{}, gomock.Any()).Return(rl, scanErr)
+
+	err := w.Work(context.Background(), makeJob(4, "https://err"))
+	require.ErrorIs(t, err, serrors.ErrUnavailable)
+	require.ErrorIs(t, err, cause)
+	var sem *serrors.Error
+	require.ErrorAs(t, err, &sem)
+	require.Equal(t, serrors.ErrUnavailable, sem.Kind())
 }
 
 func TestURLScannerWorker_CooperativeRateLimit_BlocksSecondUntilFirstFinishes(t *testing.T) {
@@ -105,23 +268,27 @@ func TestURLScannerWorker_CooperativeRateLimit_BlocksSecondUntilFirstFinishes(t
 	defer ctrl.Finish()
 
 	mock := mockscanner.NewMockScanner(ctrl)
-	w := worker.NewURLScannerWorker(mock)
+	w := worker.NewURLScannerWorker(mock, nil, nil, worker.Options{})
 
 	firstScanStart := make(chan struct{})
 	allowFirstToFinish := make(chan struct{})
 	secondScanStarted := make(chan struct{})
 
 	// First Scan blocks until we allow it to finish.
-	mock.EXPECT().Scan(gomock.Any(), "https://a").
-		DoAndReturn(func(ctx context.Context, _ string) (urlscanner.RateLimitStatus, error) {
+	mock.EXPECT().Scan(gomock.Any(), "https://a", domain.UserID{}, gomock.Any()).
+		DoAndReturn(func(
+			ctx context.Context, _ string, _ domain.UserID, _ urlscanner.SubmitOptions,
+		) (urlscanner.RateLimitStatus, error) {
 			close(firstScanStart)
 			<-allowFirstToFinish
 
 			return urlscanner.RateLimitStatus{Limit: 1, Remaining: 1, ResetAt: time.Now().Add(time.Minute)}, nil
 		})
 	// Second Scan should not be called until the first finishes and requestFinished wakes it.
-	mock.EXPECT().Scan(gomock.Any(), "https://b").
-		DoAndReturn(func(ctx context.Context, _ string) (urlscanner.RateLimitStatus, error) {
+	mock.EXPECT().Scan(gomock.Any(), "https://b", domain.UserID{}, gomock.Any()).
+		DoAndReturn(func(
+			ctx context.Context, _ string, _ domain.UserID, _ urlscanner.SubmitOptions,
+		) (urlscanner.RateLimitStatus, error) {
 			close(secondScanStarted)
 
 			return urlscanner.RateLimitStatus{Limit: 1, Remaining: 1, ResetAt: time.Now().Add(time.Minute)}, nil
@@ -157,16 +324,168 @@ func TestURLScannerWorker_CooperativeRateLimit_BlocksSecondUntilFirstFinishes(t
 	}
 }
 
+func TestURLScannerWorker_MaxPerHost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := mockscanner.NewMockScanner(ctrl)
+	w := worker.NewURLScannerWorker(mock, nil, nil, worker.Options{MaxPerHost: 1})
+
+	// Prime the worker with enough budget, so that only the host limit applies.
+	rl := urlscanner.RateLimitStatus{Limit: 10, Remaining: 10, ResetAt: time.Now().Add(time.Minute)}
+	mock.EXPECT().Scan(gomock.Any(), "https://prime.example/", domain.UserID{}, gomock.Any()).Return(rl, nil)
+	require.NoError(t, w.Work(context.Background(), makeJob(30, "https://prime.example/")))
+
+	started := make(map[string]chan struct{})
+	finish := make(map[string]chan struct{})
+	for _, u := range []string{"https://a.example/1", "https://a.example/2", "https://b.example/1"} {
+		started[u] = make(chan struct{})
+		finish[u] = make(chan struct{})
+		mock.EXPECT().Scan(gomock.Any(), u, domain.UserID{}, gomock.Any()).
+			DoAndReturn(func(
+				context.Context, string, domain.UserID, urlscanner.SubmitOptions,
+			) (urlscanner.RateLimitStatus, error) {
+				close(started[u])
+				<-finish[u]
+
+				return rl, nil
+			})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	done := make(chan error, 3)
+	work := func(id int64, u string) {
+		go func() { done <- w.Work(ctx, makeJob(id, u)) }()
+	}
+
+	work(31, "https://a.example/1")
+	<-started["https://a.example/1"]
+
+	// a URL on another host runs in parallel
+	work(32, "https://b.example/1")
+	select {
+	case <-started["https://b.example/1"]:
+	case <-time.After(2 * time.Second):
+		t.Fatal("scan of another host did not start while the first host was busy")
+	}
+
+	// a second URL on the same host waits for the first one
+	work(33, "https://a.example/2")
+	select {
+	case <-started["https://a.example/2"]:
+		t.Fatal("second scan of the same host started before the first finished")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// finishing the other host's scan does not free a slot of the first host
+	close(finish["https://b.example/1"])
+	require.NoError(t, <-done)
+	select {
+	case <-started["https://a.example/2"]:
+		t.Fatal("second scan of the same host started before the first finished")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(finish["https://a.example/1"])
+	select {
+	case <-started["https://a.example/2"]:
+	case <-time.After(2 * time.Second):
+		t.Fatal("second scan of the same host did not start after the first finished")
+	}
+	close(finish["https://a.example/2"])
+	require.NoError(t, <-done)
+	require.NoError(t, <-done)
+	require.Equal(t, 0, w.InFlight())
+}
+
+func TestURLScannerWorker_UrgentBudget(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := mockscanner.NewMockScanner(ctrl)
+	// The reservation is capped at Limit-1, so a single unit is reserved.
+	w := worker.NewURLScannerWorker(mock, nil, nil, worker.Options{UrgentBudget: 2})
+
+	rl := urlscanner.RateLimitStatus{Limit: 2, Remaining: 2, ResetAt: time.Now().Add(time.Minute)}
+	mock.EXPECT().Scan(gomock.Any(), "https://prime", domain.UserID{}, gomock.Any()).Return(rl, nil)
+	require.NoError(t, w.Work(context.Background(), makeJob(40, "https://prime")))
+
+	started := make(map[string]chan struct{})
+	finish := make(map[string]chan struct{})
+	for _, u := range []string{"https://normal/1", "https://normal/2", "https://urgent"} {
+		started[u] = make(chan struct{})
+		finish[u] = make(chan struct{})
+		mock.EXPECT().Scan(gomock.Any(), u, domain.UserID{}, gomock.Any()).
+			DoAndReturn(func(
+				context.Context, string, domain.UserID, urlscanner.SubmitOptions,
+			) (urlscanner.RateLimitStatus, error) {
+				close(started[u])
+				<-finish[u]
+
+				return rl, nil
+			})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	done := make(chan error, 3)
+	work := func(job *river.Job[scanner.JobArgs]) {
+		go func() { done <- w.Work(ctx, job) }()
+	}
+
+	// the unreserved unit is left to normal jobs
+	work(makeJob(41, "https://normal/1"))
+	select {
+	case <-started["https://normal/1"]:
+	case <-time.After(2 * time.Second):
+		t.Fatal("normal scan did not start with unreserved budget left")
+	}
+
+	// the reserved unit is not available to normal jobs
+	work(makeJob(42, "https://normal/2"))
+	select {
+	case <-started["https://normal/2"]:
+		t.Fatal("normal scan started using the reserved budget")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// an urgent job skips the blocked normal one
+	urgent := makeJob(43, "https://urgent")
+	urgent.Args.Urgent = true
+	work(urgent)
+	select {
+	case <-started["https://urgent"]:
+	case <-time.After(2 * time.Second):
+		t.Fatal("urgent scan did not start using the reserved budget")
+	}
+	require.Equal(t, 2, w.InFlight())
+
+	// the blocked normal job proceeds once the shared budget is released
+	close(finish["https://normal/1"])
+	select {
+	case <-started["https://normal/2"]:
+	case <-time.After(2 * time.Second):
+		t.Fatal("normal scan did not start after budget was released")
+	}
+	close(finish["https://urgent"])
+	close(finish["https://normal/2"])
+	for range 3 {
+		require.NoError(t, <-done)
+	}
+	require.Equal(t, 0, w.InFlight())
+}
+
 func TestURLScannerWorker_RL_AllowsUpToRemainingConcurrent_ThenBlocksExtra(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mock := mockscanner.NewMockScanner(ctrl)
-	w := worker.NewURLScannerWorker(mock)
+	w := worker.NewURLScannerWorker(mock, nil, nil, worker.Options{})
 
 	// Prime the worker with RL Remaining=2 so two in-flight can start immediately.
 	rlPrime := urlscanner.RateLimitStatus{Limit: 2, Remaining: 2, ResetAt: time.Now().Add(time.Minute)}
-	mock.EXPECT().Scan(gomock.Any(), "https://prime").Return(rlPrime, nil)
+	mock.EXPECT().Scan(gomock.Any(), "https://prime", domain.UserID{}, gomock.Any()).Return(rlPrime, nil)
 
 	require.NoError(t, w.Work(context.Background(), makeJob(20, "https://prime")))
 
@@ -177,24 +496,30 @@ func TestURLScannerWorker_RL_AllowsUpToRemainingConcurrent_ThenBlocksExtra(t *te
 	finishC := make(chan struct{})
 
 	// B and C should both be able to start concurrently under Remaining=2.
-	mock.EXPECT().Scan(gomock.Any(), "https://b").
-		DoAndReturn(func(ctx context.Context, _ string) (urlscanner.RateLimitStatus, error) {
+	mock.EXPECT().Scan(gomock.Any(), "https://b", domain.UserID{}, gomock.Any()).
+		DoAndReturn(func(
+			ctx context.Context, _ string, _ domain.UserID, _ urlscanner.SubmitOptions,
+		) (urlscanner.RateLimitStatus, error) {
 			close(bStarted)
 			<-finishB
 
 			// Return Remaining=2 so after B finishes, remaining - inFlight (1) > 0 allowing D to start.
 			return urlscanner.RateLimitStatus{Limit: 2, Remaining: 2, ResetAt: time.Now().Add(time.Minute)}, nil
 		})
-	mock.EXPECT().Scan(gomock.Any(), "https://c").
-		DoAndReturn(func(ctx context.Context, _ string) (urlscanner.RateLimitStatus, error) {
+	mock.EXPECT().Scan(gomock.Any(), "https://c", domain.UserID{}, gomock.Any()).
+		DoAndReturn(func(
+			ctx context.Context, _ string, _ domain.UserID, _ urlscanner.SubmitOptions,
+		) (urlscanner.RateLimitStatus, error) {
 			close(cStarted)
 			<-finishC
 
 			return urlscanner.RateLimitStatus{Limit: 2, Remaining: 0, ResetAt: time.Now().Add(time.Minute)}, nil
 		})
 	// D should be blocked until either B or C finishes and wakes a waiter.
-	mock.EXPECT().Scan(gomock.Any(), "https://d").
-		DoAndReturn(func(ctx context.Context, _ string) (urlscanner.RateLimitStatus, error) {
+	mock.EXPECT().Scan(gomock.Any(), "https://d", domain.UserID{}, gomock.Any()).
+		DoAndReturn(func(
+			ctx context.Context, _ string, _ domain.UserID, _ urlscanner.SubmitOptions,
+		) (urlscanner.RateLimitStatus, error) {
 			close(dStarted)
 
 			return urlscanner.RateLimitStatus{Limit: 2, Remaining: 1, ResetAt: time.Now().Add(time.Minute)}, nil
@@ -247,34 +572,39 @@ func TestURLScannerWorker_RL_WaitsForReset_WhenRemainingZero(t *testing.T) {
 	defer ctrl.Finish()
 
 	mock := mockscanner.NewMockScanner(ctrl)
-	w := worker.NewURLScannerWorker(mock)
+	clk := clock.NewFake(time.Now())
+	w := worker.NewURLScannerWorker(mock, nil, clk, worker.Options{})
 
-	// First call returns Remaining=0 with a short ResetAt in the future.
-	resetDelay := 300 * time.Millisecond
-	resetAt := time.Now().Add(resetDelay)
-	rlZero := urlscanner.RateLimitStatus{Limit: 5, Remaining: 0, ResetAt: resetAt}
-	mock.EXPECT().Scan(gomock.Any(), "https://a").Return(rlZero, nil)
+	// First call returns Remaining=0 with a ResetAt in the future.
+	resetDelay := 30 * time.Second
+	rlZero := urlscanner.RateLimitStatus{Limit: 5, Remaining: 0, ResetAt: clk.Now().Add(resetDelay)}
+	mock.EXPECT().Scan(gomock.Any(), "https://a", domain.UserID{}, gomock.Any()).Return(rlZero, nil)
 	require.NoError(t, w.Work(context.Background(), makeJob(30, "https://a")))
 
 	started := make(chan struct{})
-	start := time.Now()
-	mock.EXPECT().Scan(gomock.Any(), "https://b").
-		DoAndReturn(func(ctx context.Context, _ string) (urlscanner.RateLimitStatus, error) {
+	mock.EXPECT().Scan(gomock.Any(), "https://b", domain.UserID{}, gomock.Any()).
+		DoAndReturn(func(
+			ctx context.Context, _ string, _ domain.UserID, _ urlscanner.SubmitOptions,
+		) (urlscanner.RateLimitStatus, error) {
 			close(started)
 			// Return any RL status; here we simulate a reset having happened.
-			return urlscanner.RateLimitStatus{Limit: 5, Remaining: 4, ResetAt: time.Now().Add(time.Minute)}, nil
+			return urlscanner.RateLimitStatus{Limit: 5, Remaining: 4, ResetAt: clk.Now().Add(time.Minute)}, nil
 		})
 
-	// Start B; it should not invoke Scan until roughly after resetDelay.
+	// Start B; it should not invoke Scan before the reset window elapsed.
 	go func() { _ = w.Work(context.Background(), makeJob(31, "https://b")) }()
 
+	clk.BlockUntil(1)
+	clk.Advance(resetDelay - time.Second)
+	select {
+	case <-started:
+		t.Fatal("Scan started too early before reset window elapsed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	clk.Advance(time.Second)
 	select {
 	case <-started:
-		elapsed := time.Since(start)
-		require.GreaterOrEqual(t,
-			elapsed,
-			resetDelay-75*time.Millisecond,
-			"Scan started too early before reset window elapsed")
 	case <-time.After(2 * time.Second):
 		t.Fatal("b did not start after reset window elapsed")
 	}
@@ -285,22 +615,26 @@ func TestURLScannerWorker_RL_UnblocksOnFailure(t *testing.T) {
 	defer ctrl.Finish()
 
 	mock := mockscanner.NewMockScanner(ctrl)
-	w := worker.NewURLScannerWorker(mock)
+	w := worker.NewURLScannerWorker(mock, nil, nil, worker.Options{})
 
 	firstStarted := make(chan struct{})
 	allowFirstToFinish := make(chan struct{})
 	secondStarted := make(chan struct{})
 
 	// First returns a generic error after we allow it to finish.
-	mock.EXPECT().Scan(gomock.Any(), "https://fail").
-		DoAndReturn(func(ctx context.Context, _ string) (urlscanner.RateLimitStatus, error) {
+	mock.EXPECT().Scan(gomock.Any(), "https://fail", domain.UserID{}, gomock.Any()).
+		DoAndReturn(func(
+			ctx context.Context, _ string, _ domain.UserID, _ urlscanner.SubmitOptions,
+		) (urlscanner.RateLimitStatus, error) {
 			close(firstStarted)
 			<-allowFirstToFinish
 
 			return urlscanner.RateLimitStatus{Limit: 1, Remaining: 1, ResetAt: time.Now().Add(time.Minute)}, errors.New("boom")
 		})
-	mock.EXPECT().Scan(gomock.Any(), "https://next").
-		DoAndReturn(func(ctx context.Context, _ string) (urlscanner.RateLimitStatus, error) {
+	mock.EXPECT().Scan(gomock.Any(), "https://next", domain.UserID{}, gomock.Any()).
+		DoAndReturn(func(
+			ctx context.Context, _ string, _ domain.UserID, _ urlscanner.SubmitOptions,
+		) (urlscanner.RateLimitStatus, error) {
 			close(secondStarted)
 
 			return urlscanner.RateLimitStatus{Limit: 1, Remaining: 1, ResetAt: time.Now().Add(time.Minute)}, nil
@@ -330,3 +664,302 @@ func TestURLScannerWorker_RL_UnblocksOnFailure(t *testing.T) {
 		t.Fatal("second did not start after first finished with error")
 	}
 }
+
+func TestURLScannerWorker_LoadRLStatus_UsesPersistedStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := mockscanner.NewMockScanner(ctrl)
+	st := mockstorage.NewMockStorage(ctrl)
+	w := worker.NewURLScannerWorker(mock, st, nil, worker.Options{})
+
+	// Persisted budget allows two concurrent requests, unlike the synthetic bootstrap status.
+	persisted := urlscanner.RateLimitStatus{Limit: 2, Remaining: 2, ResetAt: time.Now().Add(time.Minute)}
+	st.EXPECT().RateLimitStatus(gomock.Any(), gomock.Any()).Return(&persisted, nil)
+	require.NoError(t, w.LoadRLStatus(context.Background()))
+
+	aStarted := make(chan struct{})
+	bStarted := make(chan struct{})
+	finish := make(chan struct{})
+	mock.EXPECT().Scan(gomock.Any(), "https://a", domain.UserID{}, gomock.Any()).
+		DoAndReturn(func(
+			ctx context.Context, _ string, _ domain.UserID, _ urlscanner.SubmitOptions,
+		) (urlscanner.RateLimitStatus, error) {
+			close(aStarted)
+			<-finish
+
+			return urlscanner.RateLimitStatus{}, nil
+		})
+	mock.EXPECT().Scan(gomock.Any(), "https://b", domain.UserID{}, gomock.Any()).
+		DoAndReturn(func(
+			ctx context.Context, _ string, _ domain.UserID, _ urlscanner.SubmitOptions,
+		) (urlscanner.RateLimitStatus, error) {
+			close(bStarted)
+			<-finish
+
+			return urlscanner.RateLimitStatus{}, nil
+		})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	go func() { _ = w.Work(ctx, makeJob(50, "https://a")) }()
+	go func() { _ = w.Work(ctx, makeJob(51, "https://b")) }()
+
+	// Both should start without waiting for each other.
+	for _, started := range []chan struct{}{aStarted, bStarted} {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("scan did not start concurrently with persisted budget")
+		}
+	}
+
+	close(finish)
+}
+
+func TestURLScannerWorker_LoadRLStatus_FallsBackToBootstrap(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := mockscanner.NewMockScanner(ctrl)
+	st := mockstorage.NewMockStorage(ctrl)
+	w := worker.NewURLScannerWorker(mock, st, nil, worker.Options{})
+
+	// No persisted row: the worker should keep the single-probe bootstrap behavior.
+	st.EXPECT().RateLimitStatus(gomock.Any(), gomock.Any()).Return(nil, nil)
+	require.NoError(t, w.LoadRLStatus(context.Background()))
+
+	firstStarted := make(chan struct{})
+	allowFirstToFinish := make(chan struct{})
+	secondStarted := make(chan struct{})
+	mock.EXPECT().Scan(gomock.Any(), "https://a", domain.UserID{}, gomock.Any()).
+		DoAndReturn(func(
+			ctx context.Context, _ string, _ domain.UserID, _ urlscanner.SubmitOptions,
+		) (urlscanner.RateLimitStatus, error) {
+			close(firstStarted)
+			<-allowFirstToFinish
+
+			return urlscanner.RateLimitStatus{}, nil
+		})
+	mock.EXPECT().Scan(gomock.Any(), "https://b", domain.UserID{}, gomock.Any()).
+		DoAndReturn(func(
+			ctx context.Context, _ string, _ domain.UserID, _ urlscanner.SubmitOptions,
+		) (urlscanner.RateLimitStatus, error) {
+			close(secondStarted)
+
+			return urlscanner.RateLimitStatus{}, nil
+		})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	go func() { _ = w.Work(ctx, makeJob(60, "https://a")) }()
+	<-firstStarted
+	go func() { _ = w.Work(ctx, makeJob(61, "https://b")) }()
+
+	select {
+	case <-secondStarted:
+		t.Fatal("second scan started before the bootstrap probe finished")
+	case <-time.After(100 * time.Millisecond):
+		// expected
+	}
+
+	close(allowFirstToFinish)
+
+	select {
+	case <-secondStarted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("second scan did not start after the bootstrap probe finished")
+	}
+}
+
+func TestURLScannerWorker_LoadRLStatus_StorageError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	st := mockstorage.NewMockStorage(ctrl)
+	w := worker.NewURLScannerWorker(mockscanner.NewMockScanner(ctrl), st, nil, worker.Options{})
+
+	st.EXPECT().RateLimitStatus(gomock.Any(), gomock.Any()).Return(nil, errors.New("boom"))
+	require.Error(t, w.LoadRLStatus(context.Background()))
+}
+
+func TestURLScannerWorker_Work_PersistsAdoptedRLStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := mockscanner.NewMockScanner(ctrl)
+	st := mockstorage.NewMockStorage(ctrl)
+	w := worker.NewURLScannerWorker(mock, st, nil, worker.Options{})
+
+	rl := urlscanner.RateLimitStatus{Limit: 100, Remaining: 99, ResetAt: time.Now().Add(time.Minute)}
+	mock.EXPECT().Scan(gomock.Any(), "https://a", domain.UserID{}, gomock.Any()).Return(rl, nil)
+	st.EXPECT().StoreRateLimitStatus(gomock.Any(), gomock.Any(), rl).Return(nil)
+	require.NoError(t, w.Work(context.Background(), makeJob(70, "https://a")))
+
+	// A higher Remaining within the same window is not adopted and must not be persisted.
+	mock.EXPECT().Scan(gomock.Any(), "https://b", domain.UserID{}, gomock.Any()).
+		Return(urlscanner.RateLimitStatus{Limit: 100, Remaining: 100, ResetAt: rl.ResetAt}, nil)
+	require.NoError(t, w.Work(context.Background(), makeJob(71, "https://b")))
+
+	// Responses without rate-limit info are not persisted either.
+	mock.EXPECT().Scan(gomock.Any(), "https://c", domain.UserID{}, gomock.Any()).Return(urlscanner.RateLimitStatus{}, nil)
+	require.NoError(t, w.Work(context.Background(), makeJob(72, "https://c")))
+
+	// Persistence failures do not fail the job.
+	lower := urlscanner.RateLimitStatus{Limit: 100, Remaining: 98, ResetAt: rl.ResetAt}
+	mock.EXPECT().Scan(gomock.Any(), "https://d", domain.UserID{}, gomock.Any()).Return(lower, nil)
+	st.EXPECT().StoreRateLimitStatus(gomock.Any(), gomock.Any(), lower).Return(errors.New("boom"))
+	require.NoError(t, w.Work(context.Background(), makeJob(73, "https://d")))
+}
+
+func TestURLScannerWorker_Work_PersistsRLStatusInOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := mockscanner.NewMockScanner(ctrl)
+	st := mockstorage.NewMockStorage(ctrl)
+	w := worker.NewURLScannerWorker(mock, st, nil, worker.Options{})
+
+	older := urlscanner.RateLimitStatus{Limit: 100, Remaining: 99, ResetAt: time.Now().Add(time.Minute)}
+	newer := urlscanner.RateLimitStatus{Limit: 100, Remaining: 99, ResetAt: older.ResetAt.Add(time.Minute)}
+
+	var (
+		mu     sync.Mutex
+		stored []urlscanner.RateLimitStatus
+	)
+	record := func(status urlscanner.RateLimitStatus) {
+		mu.Lock()
+		defer mu.Unlock()
+		stored = append(stored, status)
+	}
+
+	// the write of the older status is slow
+	olderStoring := make(chan struct{})
+	releaseOlder := make(chan struct{})
+	mock.EXPECT().Scan(gomock.Any(), "https://a", domain.UserID{}, gomock.Any()).Return(older, nil)
+	st.EXPECT().StoreRateLimitStatus(gomock.Any(), gomock.Any(), older).
+		DoAndReturn(func(context.Context, string, urlscanner.RateLimitStatus) error {
+			close(olderStoring)
+			<-releaseOlder
+			record(older)
+
+			return nil
+		})
+	st.EXPECT().StoreRateLimitStatus(gomock.Any(), gomock.Any(), newer).
+		DoAndReturn(func(context.Context, string, urlscanner.RateLimitStatus) error {
+			record(newer)
+
+			return nil
+		})
+
+	doneA := make(chan error, 1)
+	go func() { doneA <- w.Work(context.Background(), makeJob(80, "https://a")) }()
+	<-olderStoring
+
+	// a newer status is adopted while the older one is still being written
+	newerScanned := make(chan struct{})
+	mock.EXPECT().Scan(gomock.Any(), "https://b", domain.UserID{}, gomock.Any()).
+		DoAndReturn(func(
+			context.Context, string, domain.UserID, urlscanner.SubmitOptions,
+		) (urlscanner.RateLimitStatus, error) {
+			close(newerScanned)
+
+			return newer, nil
+		})
+	doneB := make(chan error, 1)
+	go func() { doneB <- w.Work(context.Background(), makeJob(81, "https://b")) }()
+	<-newerScanned
+	require.Eventually(t, func() bool { return w.InFlight() == 0 }, time.Second, time.Millisecond)
+
+	close(releaseOlder)
+	require.NoError(t, <-doneA)
+	require.NoError(t, <-doneB)
+
+	// the newer status is written last and is what a restart loads
+	require.Equal(t, []urlscanner.RateLimitStatus{older, newer}, stored)
+}
+
+func TestURLScannerWorker_InFlight(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := mockscanner.NewMockScanner(ctrl)
+	w := worker.NewURLScannerWorker(mock, nil, nil, worker.Options{})
+	require.Equal(t, 0, w.InFlight())
+
+	scanStarted := make(chan struct{})
+	allowFinish := make(chan struct{})
+	mock.EXPECT().Scan(gomock.Any(), "https://a", domain.UserID{}, gomock.Any()).
+		DoAndReturn(func(
+			ctx context.Context, _ string, _ domain.UserID, _ urlscanner.SubmitOptions,
+		) (urlscanner.RateLimitStatus, error) {
+			close(scanStarted)
+			<-allowFinish
+
+			return urlscanner.RateLimitStatus{Limit: 10, Remaining: 9, ResetAt: time.Now().Add(time.Minute)}, nil
+		})
+
+	done := make(chan error)
+	go func() { done <- w.Work(context.Background(), makeJob(1, "https://a")) }()
+
+	// reserved but not finished
+	<-scanStarted
+	require.Equal(t, 1, w.InFlight())
+
+	close(allowFinish)
+	require.NoError(t, <-done)
+	require.Equal(t, 0, w.InFlight())
+}
+
+// gaugeValues gathers the worker's metrics and returns the gauge values by name.
+func gaugeValues(t *testing.T, w *worker.URLScannerWorker) map[string]float64 {
+	t.Helper()
+	reg := prometheus.NewPedanticRegistry()
+	require.NoError(t, reg.Register(w))
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	values := make(map[string]float64, len(families))
+	for _, family := range families {
+		values[family.GetName()] = family.GetMetric()[0].GetGauge().GetValue()
+	}
+
+	return values
+}
+
+func TestURLScannerWorker_Metrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := mockscanner.NewMockScanner(ctrl)
+	w := worker.NewURLScannerWorker(mock, nil, nil, worker.Options{})
+
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	rl := urlscanner.RateLimitStatus{Limit: 100, Remaining: 42, ResetAt: time.Now().Add(time.Minute)}
+	mock.EXPECT().Scan(gomock.Any(), "https://ok", domain.UserID{}, gomock.Any()).
+		DoAndReturn(func(
+			ctx context.Context, _ string, _ domain.UserID, _ urlscanner.SubmitOptions,
+		) (urlscanner.RateLimitStatus, error) {
+			close(started)
+			<-finish
+
+			return rl, nil
+		})
+
+	done := make(chan error)
+	go func() { done <- w.Work(context.Background(), makeJob(1, "https://ok")) }()
+
+	<-started
+	require.InDelta(t, 1, gaugeValues(t, w)["urlscanner_in_flight_requests"], 0)
+
+	close(finish)
+	require.NoError(t, <-done)
+
+	values := gaugeValues(t, w)
+	require.InDelta(t, 42, values["urlscanner_rate_limit_remaining"], 0)
+	require.InDelta(t, 100, values["urlscanner_rate_limit_limit"], 0)
+	require.InDelta(t, 0, values["urlscanner_in_flight_requests"], 0)
+}
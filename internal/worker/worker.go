@@ -10,12 +10,15 @@ import (
 	"scanner/internal/config"
 	"scanner/internal/scanner"
 	"scanner/pkg/logger"
+	"scanner/pkg/storage"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/riverqueue/river"
 	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"go.uber.org/zap"
 	"go.uber.org/zap/exp/zapslog"
 )
 
@@ -24,46 +27,186 @@ type Options struct {
 	// JobTimeout controls the maximum duration a single job is allowed to run
 	// before it is considered timed out by river.
 	JobTimeout time.Duration
-	// JobConcurrency specifies how many jobs can be processed in parallel.
+	// JobConcurrency specifies how many jobs can be processed in parallel in
+	// the default queue.
 	JobConcurrency int
+	// Queues maps additional queue names to how many jobs can be processed in
+	// parallel in each of them.
+	Queues map[string]int
+	// DrainTimeout bounds how long shutdown waits for running jobs to finish
+	// before canceling them.
+	DrainTimeout time.Duration
+	// FetchCooldown is the minimum time between fetches of new jobs. Zero
+	// uses River's default.
+	FetchCooldown time.Duration
+	// FetchPollInterval is the time between periodic fetches of new jobs when
+	// none are notified. Zero uses River's default.
+	FetchPollInterval time.Duration
+	// RescueStuckJobsAfter is how long a job may run before River considers
+	// it stuck and makes it available again. Zero uses River's default.
+	RescueStuckJobsAfter time.Duration
+	// MaxSnooze caps how long a rate-limited job is snoozed until the upstream
+	// rate limit resets. Values <= 0 disable the cap.
+	MaxSnooze time.Duration
+	// SnoozeJitter is the upper bound of a random delay added to snoozes of
+	// rate-limited jobs. Values <= 0 disable it.
+	SnoozeJitter time.Duration
+	// MaxPerHost is the maximum number of scans of URLs on the same host that
+	// run at once. Values <= 0 disable the limit.
+	MaxPerHost int
+	// UrgentBudget is the part of the rate-limit budget reserved for urgent
+	// jobs. It is capped below the limit, so that other jobs always keep some
+	// budget. Values <= 0 disable it.
+	UrgentBudget int
+	// ReconcileInterval is how often pending scans that went stale are
+	// reconciled. Values <= 0 disable the reconciliation.
+	ReconcileInterval time.Duration
+	// StaleScanAfter is how long a pending scan may go without updates before
+	// the reconciliation considers its job lost.
+	StaleScanAfter time.Duration
+	// OnDeadLetter is called for every job discarded after exhausting its
+	// attempts. It may be nil; discarded jobs are counted either way.
+	OnDeadLetter DeadLetterFunc
 }
 
 // NewOptions translates the application's config into worker Options.
 // It copies relevant values from cfg.Worker so callers can pass them to Start.
 func NewOptions(cfg *config.Config) Options {
 	return Options{
-		JobTimeout:     cfg.Worker.JobTimeout,
-		JobConcurrency: cfg.Worker.JobConcurrency,
+		JobTimeout:           cfg.Worker.JobTimeout,
+		JobConcurrency:       cfg.Worker.JobConcurrency,
+		Queues:               cfg.Worker.Queues,
+		DrainTimeout:         cfg.Worker.DrainTimeout,
+		FetchCooldown:        cfg.Worker.FetchCooldown,
+		FetchPollInterval:    cfg.Worker.FetchPollInterval,
+		RescueStuckJobsAfter: cfg.Worker.RescueStuckJobsAfter,
+		MaxSnooze:            cfg.Worker.MaxSnooze,
+		SnoozeJitter:         cfg.Worker.SnoozeJitter,
+		MaxPerHost:           cfg.Worker.MaxPerHost,
+		UrgentBudget:         cfg.Worker.UrgentBudget,
+		ReconcileInterval:    cfg.Worker.ReconcileInterval,
+		StaleScanAfter:       cfg.Worker.StaleScanAfter,
+	}
+}
+
+// queues builds the river queue configuration from options. The default queue
+// is always present and uses JobConcurrency.
+func (o Options) queues() map[string]river.QueueConfig {
+	queues := map[string]river.QueueConfig{
+		river.QueueDefault: {MaxWorkers: o.JobConcurrency},
+	}
+	for name, maxWorkers := range o.Queues {
+		queues[name] = river.QueueConfig{MaxWorkers: maxWorkers}
+	}
+
+	return queues
+}
+
+// periodicJobs returns the jobs River runs periodically: the reconciliation of
+// stale scans every ReconcileInterval, if enabled.
+func (o Options) periodicJobs() []*river.PeriodicJob {
+	if o.ReconcileInterval <= 0 {
+		return nil
+	}
+
+	return []*river.PeriodicJob{
+		river.NewPeriodicJob(
+			river.PeriodicInterval(o.ReconcileInterval),
+			func() (river.JobArgs, *river.InsertOpts) { return ReconcileArgs{}, nil },
+			nil,
+		),
+	}
+}
+
+// riverConfig builds the river client configuration from options, running
+// the given workers and periodic jobs, reporting their errors to errorHandler
+// and logging to logger.
+func (o Options) riverConfig(
+	workers *river.Workers,
+	errorHandler river.ErrorHandler,
+	logger *slog.Logger,
+) *river.Config {
+	return &river.Config{
+		ErrorHandler:         errorHandler,
+		Queues:               o.queues(),
+		JobTimeout:           o.JobTimeout,
+		FetchCooldown:        o.FetchCooldown,
+		FetchPollInterval:    o.FetchPollInterval,
+		RescueStuckJobsAfter: o.RescueStuckJobsAfter,
+		PeriodicJobs:         o.periodicJobs(),
+		Workers:              workers,
+		Logger:               logger,
 	}
 }
 
 // Start initializes the river client, registers workers, and starts processing
-// jobs. It returns the started river client which should be closed by the
-// caller when shutting down (by canceling ctx).
+// jobs. The URL scanner worker restores its rate-limit status from rlStorage
+// before any job runs, and stale pending scans are reconciled periodically
+// when options.ReconcileInterval is set. It returns the started river client,
+// which should be closed by the caller when shutting down (see Stop), along
+// with the URL scanner worker so callers can observe its in-flight scans.
 func Start(
 	ctx context.Context,
 	dbPool *pgxpool.Pool,
 	scanner scanner.Scanner,
+	rlStorage storage.RateLimitStorage,
 	options Options,
-) (*river.Client[pgx.Tx], error) {
+) (*river.Client[pgx.Tx], *URLScannerWorker, error) {
+	urlScannerWorker := NewURLScannerWorker(scanner, rlStorage, nil, options)
+	if err := urlScannerWorker.LoadRLStatus(ctx); err != nil {
+		// fall back to probing the upstream API
+		logger.Warn(ctx, "could not load rate limit status", zap.Error(err))
+	}
+
+	if err := prometheus.Register(urlScannerWorker); err != nil {
+		logger.Warn(ctx, "could not register worker metrics", zap.Error(err))
+	}
+
+	deadLetterHandler := NewDeadLetterHandler(options.OnDeadLetter)
+	if err := prometheus.Register(deadLetterHandler); err != nil {
+		logger.Warn(ctx, "could not register dead letter metrics", zap.Error(err))
+	}
+
 	workers := river.NewWorkers()
-	river.AddWorker(workers, NewURLScannerWorker(scanner))
-
-	riverClient, err := river.NewClient(riverpgxv5.New(dbPool), &river.Config{
-		Queues: map[string]river.QueueConfig{
-			river.QueueDefault: {MaxWorkers: options.JobConcurrency},
-		},
-		JobTimeout: options.JobTimeout,
-		Workers:    workers,
-		Logger:     slog.New(zapslog.NewHandler(logger.Get(ctx).Core())),
-	})
+	river.AddWorker(workers, urlScannerWorker)
+	river.AddWorker(workers, NewReconcileWorker(scanner, nil, options))
+
+	riverClient, err := river.NewClient(riverpgxv5.New(dbPool),
+		options.riverConfig(workers, deadLetterHandler, slog.New(zapslog.NewHandler(logger.Get(ctx).Core()))))
 	if err != nil {
-		return nil, fmt.Errorf("could not create river queue client: %w", err)
+		return nil, nil, fmt.Errorf("could not create river queue client: %w", err)
 	}
 
 	if err := riverClient.Start(ctx); err != nil {
-		return nil, fmt.Errorf("could not start river queue client: %w", err)
+		return nil, nil, fmt.Errorf("could not start river queue client: %w", err)
+	}
+
+	return riverClient, urlScannerWorker, nil
+}
+
+// Stop gracefully stops the river client. It stops fetching new jobs and waits
+// up to options.DrainTimeout for running jobs, including in-flight scans of
+// urlScannerWorker, to finish. Jobs still running afterward are canceled,
+// waiting for them until ctx is done.
+func Stop(
+	ctx context.Context,
+	riverClient *river.Client[pgx.Tx],
+	urlScannerWorker *URLScannerWorker,
+	options Options,
+) error {
+	logger.Info(ctx, "draining worker...", zap.Int("inFlight", urlScannerWorker.InFlight()))
+
+	drainCtx, cancel := context.WithTimeout(ctx, options.DrainTimeout)
+	defer cancel()
+	if err := riverClient.Stop(drainCtx); err == nil {
+		return nil
+	}
+
+	logger.Warn(ctx, "worker did not drain in time, canceling running jobs",
+		zap.Int("inFlight", urlScannerWorker.InFlight()))
+	if err := riverClient.StopAndCancel(ctx); err != nil {
+		return fmt.Errorf("could not stop river queue client: %w", err)
 	}
 
-	return riverClient, nil
+	return nil
 }
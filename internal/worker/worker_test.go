@@ -0,0 +1,46 @@
+package worker_test
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/stretchr/testify/require"
+
+	"scanner/internal/config"
+	"scanner/internal/worker"
+)
+
+func TestRiverConfig_PassesOptions(t *testing.T) {
+	var cfg config.Config
+	cfg.Worker.JobTimeout = time.Minute
+	cfg.Worker.JobConcurrency = 10
+	cfg.Worker.Queues = map[string]int{"priority": 5}
+	cfg.Worker.FetchCooldown = 50 * time.Millisecond
+	cfg.Worker.FetchPollInterval = 2 * time.Second
+	cfg.Worker.RescueStuckJobsAfter = 30 * time.Minute
+
+	workers := river.NewWorkers()
+	errorHandler := worker.NewDeadLetterHandler(nil)
+	logger := slog.Default()
+	riverCfg := worker.RiverConfig(worker.NewOptions(&cfg), workers, errorHandler, logger)
+
+	require.Equal(t, time.Minute, riverCfg.JobTimeout)
+	require.Equal(t, 50*time.Millisecond, riverCfg.FetchCooldown)
+	require.Equal(t, 2*time.Second, riverCfg.FetchPollInterval)
+	require.Equal(t, 30*time.Minute, riverCfg.RescueStuckJobsAfter)
+	require.Equal(t, map[string]river.QueueConfig{
+		river.QueueDefault: {MaxWorkers: 10},
+		"priority":         {MaxWorkers: 5},
+	}, riverCfg.Queues)
+	require.Same(t, workers, riverCfg.Workers)
+	require.Same(t, errorHandler, riverCfg.ErrorHandler)
+	require.Same(t, logger, riverCfg.Logger)
+	require.Empty(t, riverCfg.PeriodicJobs)
+}
+
+func TestRiverConfig_ReconcilesPeriodically(t *testing.T) {
+	riverCfg := worker.RiverConfig(worker.Options{ReconcileInterval: 5 * time.Minute}, river.NewWorkers(), nil, nil)
+	require.Len(t, riverCfg.PeriodicJobs, 1)
+}
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE scans ADD COLUMN IF NOT EXISTS idempotency_key TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS scans_user_id_idempotency_key_idx ON scans (user_id, idempotency_key)
+    WHERE idempotency_key IS NOT NULL AND deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS scans_user_id_idempotency_key_idx;
+ALTER TABLE scans DROP COLUMN IF EXISTS idempotency_key;
+-- +goose StatementEnd
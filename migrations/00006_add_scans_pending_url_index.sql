@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- PendingScanCountByURL runs on every job execution; a partial index keeps it
+-- small since only pending, non-deleted scans are ever counted.
+CREATE INDEX IF NOT EXISTS scans_pending_url_idx ON scans (url)
+    WHERE status = 'PENDING' AND deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS scans_pending_url_idx;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- SearchScans looks scans up by containment (result @> ...) on fields of the
+-- result page; jsonb_path_ops keeps the index smaller than the default opclass
+-- and supports exactly that operator.
+CREATE INDEX IF NOT EXISTS scans_result_gin_idx ON scans USING GIN (result jsonb_path_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS scans_result_gin_idx;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Scans submitted before the column existed were all public on urlscan.io.
+ALTER TABLE scans ADD COLUMN IF NOT EXISTS shareable BOOLEAN NOT NULL DEFAULT TRUE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE scans DROP COLUMN IF EXISTS shareable;
+-- +goose StatementEnd
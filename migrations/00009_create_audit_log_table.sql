@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Entries are append-only and outlive soft-deleted scans, so scan_id has no
+-- foreign key.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    scan_id UUID NOT NULL,
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS audit_log_user_id_idx ON audit_log (user_id, id);
+CREATE INDEX IF NOT EXISTS audit_log_scan_id_idx ON audit_log (scan_id, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS audit_log;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- One row per processing attempt of a scan, appended by the worker.
+CREATE TABLE IF NOT EXISTS scan_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    scan_id UUID NOT NULL REFERENCES scans (id) ON DELETE CASCADE,
+
+    error TEXT,
+    rate_limit_limit INTEGER NOT NULL DEFAULT 0,
+    rate_limit_remaining INTEGER NOT NULL DEFAULT 0,
+    rate_limit_reset_at TIMESTAMP,
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS scan_attempts_scan_id_idx ON scan_attempts (scan_id, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS scan_attempts;
+-- +goose StatementEnd
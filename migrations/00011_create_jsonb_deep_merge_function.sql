@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Partial scan result updates (ScanUpdates.MergeResult) merge the new result
+-- into the stored one recursively: keys of nested objects present in patch
+-- overwrite the stored ones, the others are preserved. Any other value, e.g.
+-- an array, replaces the stored one as a whole.
+CREATE OR REPLACE FUNCTION jsonb_deep_merge(target JSONB, patch JSONB) RETURNS JSONB
+LANGUAGE plpgsql IMMUTABLE AS $$
+BEGIN
+    IF jsonb_typeof(target) IS DISTINCT FROM 'object' OR jsonb_typeof(patch) IS DISTINCT FROM 'object' THEN
+        RETURN COALESCE(patch, target);
+    END IF;
+
+    RETURN (
+        SELECT COALESCE(jsonb_object_agg(
+            keys.key,
+            CASE
+                WHEN target ? keys.key AND patch ? keys.key
+                    THEN jsonb_deep_merge(target -> keys.key, patch -> keys.key)
+                ELSE COALESCE(patch -> keys.key, target -> keys.key)
+            END
+        ), '{}'::JSONB)
+        FROM (SELECT jsonb_object_keys(target) UNION SELECT jsonb_object_keys(patch)) AS keys (key)
+    );
+END
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP FUNCTION IF EXISTS jsonb_deep_merge(JSONB, JSONB);
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- ActiveJobByURL looks up the active job of a URL on every enqueue that does
+-- not add a job; a partial expression index keeps it from scanning river_job,
+-- since only jobs in an active state are ever looked up. The river_job table
+-- is created by the river queue migrations, which run before these.
+CREATE INDEX IF NOT EXISTS river_job_active_url_idx ON river_job (kind, (args->>'url'))
+    WHERE state IN ('available', 'pending', 'retryable', 'running', 'scheduled');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS river_job_active_url_idx;
+-- +goose StatementEnd
@@ -0,0 +1,124 @@
+// Package clock abstracts reading the current time and waiting for durations
+// so that time-dependent code, such as rate limiting and polling, can be
+// tested deterministically with a Fake clock instead of real waits.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock provides the current time and timers.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After returns a channel receiving the current time once d elapsed.
+	After(d time.Duration) <-chan time.Time
+	// Sleep blocks until d elapsed.
+	Sleep(d time.Duration)
+}
+
+// Real is the Clock backed by the time package.
+type Real struct{}
+
+// Now implements Clock.
+func (Real) Now() time.Time { return time.Now() }
+
+// After implements Clock.
+func (Real) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Sleep implements Clock.
+func (Real) Sleep(d time.Duration) { time.Sleep(d) }
+
+// OrReal returns c, or Real when c is nil.
+func OrReal(c Clock) Clock {
+	if c == nil {
+		return Real{}
+	}
+
+	return c
+}
+
+// waiter is a pending After or Sleep call of a Fake clock.
+type waiter struct {
+	until time.Time
+	ch    chan time.Time
+}
+
+// Fake is a Clock whose time only moves with Advance. Timers created with
+// After and Sleep fire once the fake time reaches their deadline. It is safe
+// for concurrent use.
+type Fake struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []waiter
+}
+
+// NewFake returns a Fake clock set to now.
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.cond = sync.NewCond(&f.mu)
+
+	return f
+}
+
+// Now implements Clock.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.now
+}
+
+// After implements Clock. Non-positive durations fire immediately.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- f.now
+
+		return ch
+	}
+	f.waiters = append(f.waiters, waiter{until: f.now.Add(d), ch: ch})
+	f.cond.Broadcast()
+
+	return ch
+}
+
+// Sleep implements Clock, blocking until the fake time advanced by d.
+func (f *Fake) Sleep(d time.Duration) {
+	<-f.After(d)
+}
+
+// Advance moves the fake time forward by d and fires the timers whose
+// deadline was reached.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = f.now.Add(d)
+	pending := f.waiters[:0]
+	for _, w := range f.waiters {
+		if w.until.After(f.now) {
+			pending = append(pending, w)
+
+			continue
+		}
+		w.ch <- f.now
+	}
+	f.waiters = pending
+}
+
+// BlockUntil blocks until at least n timers are waiting on the clock. Tests
+// use it to advance the time only once the code under test started waiting.
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for len(f.waiters) < n {
+		f.cond.Wait()
+	}
+}
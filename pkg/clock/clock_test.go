@@ -0,0 +1,56 @@
+package clock_test
+
+import (
+	"scanner/pkg/clock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFake_After(t *testing.T) {
+	start := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	c := clock.NewFake(start)
+
+	ch := c.After(time.Minute)
+	c.Advance(30 * time.Second)
+	select {
+	case <-ch:
+		t.Fatal("timer fired before its deadline")
+	default:
+	}
+
+	c.Advance(30 * time.Second)
+	require.Equal(t, start.Add(time.Minute), <-ch)
+	require.Equal(t, start.Add(time.Minute), c.Now())
+
+	// non-positive durations fire immediately
+	require.Equal(t, start.Add(time.Minute), <-c.After(0))
+}
+
+func TestFake_SleepAndBlockUntil(t *testing.T) {
+	c := clock.NewFake(time.Now())
+
+	done := make(chan struct{})
+	go func() {
+		c.Sleep(time.Second)
+		close(done)
+	}()
+
+	c.BlockUntil(1)
+	select {
+	case <-done:
+		t.Fatal("sleep returned before the clock advanced")
+	default:
+	}
+
+	c.Advance(time.Second)
+	<-done
+}
+
+func TestOrReal(t *testing.T) {
+	require.Equal(t, clock.Real{}, clock.OrReal(nil))
+
+	fake := clock.NewFake(time.Now())
+	require.Same(t, fake, clock.OrReal(fake))
+}
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"net/http"
+)
+
+// payloadTooLargeBody is the JSON error body returned for oversized requests.
+const payloadTooLargeBody = `{"code":"PAYLOAD_TOO_LARGE","message":"request body too large"}`
+
+// WithMaxBodyBytes returns a middleware that limits request bodies to limit
+// bytes. Requests declaring a larger Content-Length are rejected with 413
+// without invoking next; bodies of unknown length are wrapped with
+// http.MaxBytesReader so reading past the limit fails. A limit <= 0 disables
+// the check.
+func WithMaxBodyBytes(next http.Handler, limit int64) http.Handler {
+	if limit <= 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > limit {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Connection", "close")
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			_, _ = w.Write([]byte(payloadTooLargeBody))
+
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		next.ServeHTTP(w, r)
+	})
+}
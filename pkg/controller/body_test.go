@@ -0,0 +1,64 @@
+package controller_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"scanner/pkg/controller"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithMaxBodyBytes_TooLarge(t *testing.T) {
+	called := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/scans", strings.NewReader(strings.Repeat("a", 11)))
+	rec := httptest.NewRecorder()
+
+	controller.WithMaxBodyBytes(next, 10).ServeHTTP(rec, req)
+
+	require.False(t, called, "next handler should not be called for oversized bodies")
+	res := rec.Result()
+	require.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+	require.Equal(t, "application/json", res.Header.Get("Content-Type"))
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"code":"PAYLOAD_TOO_LARGE","message":"request body too large"}`, string(body))
+}
+
+func TestWithMaxBodyBytes_UnknownLength(t *testing.T) {
+	var readErr error
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, readErr = io.ReadAll(r.Body)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/scans", strings.NewReader(strings.Repeat("a", 11)))
+	req.ContentLength = -1
+	rec := httptest.NewRecorder()
+
+	controller.WithMaxBodyBytes(next, 10).ServeHTTP(rec, req)
+
+	var maxBytesErr *http.MaxBytesError
+	require.ErrorAs(t, readErr, &maxBytesErr)
+}
+
+func TestWithMaxBodyBytes_WithinLimit(t *testing.T) {
+	var body []byte
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/scans", strings.NewReader("0123456789"))
+	rec := httptest.NewRecorder()
+
+	controller.WithMaxBodyBytes(next, 10).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusCreated, rec.Result().StatusCode)
+	require.Equal(t, "0123456789", string(body))
+}
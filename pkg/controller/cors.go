@@ -1,16 +1,69 @@
 package controller
 
-import "net/http"
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// CORSConfig configures the CORS policy applied by WithCORS.
+type CORSConfig struct {
+	// AllowedOrigins lists origins allowed to make cross-origin requests. "*"
+	// allows any origin.
+	AllowedOrigins []string
+	// AllowedMethods lists methods advertised in preflight responses.
+	AllowedMethods []string
+	// AllowedHeaders lists request headers advertised in preflight responses.
+	AllowedHeaders []string
+	// AllowCredentials allows browsers to send credentials with cross-origin
+	// requests. Since browsers reject credentials with a wildcard origin, the
+	// request Origin is echoed instead of "*" when this is set.
+	AllowCredentials bool
+}
+
+// allowOrigin returns the Access-Control-Allow-Origin value for the given
+// request origin, or an empty string when the origin is not allowed.
+func (c CORSConfig) allowOrigin(origin string) string {
+	if origin == "" {
+		return ""
+	}
+	if slices.Contains(c.AllowedOrigins, "*") {
+		if c.AllowCredentials {
+			return origin
+		}
+
+		return "*"
+	}
+	if slices.ContainsFunc(c.AllowedOrigins, func(allowed string) bool {
+		return strings.EqualFold(allowed, origin)
+	}) {
+		return origin
+	}
+
+	return ""
+}
+
+// WithCORS returns a middleware that sets CORS headers according to cfg and
+// short-circuits OPTIONS preflight requests with 204 No Content. Requests from
+// origins that are not allowed get no CORS headers, so browsers block them.
+func WithCORS(next http.Handler, cfg CORSConfig) http.Handler {
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
 
-// WithCORS returns a middleware that sets permissive CORS headers on every
-// response and short-circuits OPTIONS preflight requests with 204 No Content.
-func WithCORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
-		w.Header().Set("Access-Control-Allow-Headers",
-			"Content-Type, Content-Length, Accept-Encoding, Authorization, accept, origin, Cache-Control")
-		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
+		w.Header().Add("Vary", "Origin")
+		if origin := cfg.allowOrigin(r.Header.Get("Origin")); origin != "" {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			if cfg.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+			if methods != "" {
+				w.Header().Set("Access-Control-Allow-Methods", methods)
+			}
+			if headers != "" {
+				w.Header().Set("Access-Control-Allow-Headers", headers)
+			}
+		}
 
 		// handle preflight requests quickly
 		if r.Method == http.MethodOptions {
@@ -9,6 +9,13 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+var corsConfig = controller.CORSConfig{
+	AllowedOrigins:   []string{"https://app.example.com"},
+	AllowedMethods:   []string{http.MethodGet, http.MethodPost},
+	AllowedHeaders:   []string{"Authorization", "Content-Type"},
+	AllowCredentials: true,
+}
+
 func TestWithCORS_Preflight(t *testing.T) {
 	called := false
 	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -16,22 +23,24 @@ func TestWithCORS_Preflight(t *testing.T) {
 	})
 
 	req := httptest.NewRequest(http.MethodOptions, "/anything", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
 	rec := httptest.NewRecorder()
 
-	controller.WithCORS(next).ServeHTTP(rec, req)
+	controller.WithCORS(next, corsConfig).ServeHTTP(rec, req)
 
 	require.False(t, called, "next handler should not be called for OPTIONS preflight")
 	res := rec.Result()
 	require.Equal(t, http.StatusNoContent, res.StatusCode)
 
 	// headers should be present
-	require.Equal(t, "*", res.Header.Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "https://app.example.com", res.Header.Get("Access-Control-Allow-Origin"))
 	require.Equal(t, "true", res.Header.Get("Access-Control-Allow-Credentials"))
-	require.NotEmpty(t, res.Header.Get("Access-Control-Allow-Headers"))
-	require.NotEmpty(t, res.Header.Get("Access-Control-Allow-Methods"))
+	require.Equal(t, "Authorization, Content-Type", res.Header.Get("Access-Control-Allow-Headers"))
+	require.Equal(t, "GET, POST", res.Header.Get("Access-Control-Allow-Methods"))
 }
 
-func TestWithCORS_NormalRequest(t *testing.T) {
+func TestWithCORS_AllowedOrigin(t *testing.T) {
 	called := false
 	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		called = true
@@ -39,17 +48,53 @@ func TestWithCORS_NormalRequest(t *testing.T) {
 	})
 
 	req := httptest.NewRequest(http.MethodGet, "/path", nil)
+	req.Header.Set("Origin", "https://app.example.com")
 	rec := httptest.NewRecorder()
 
-	controller.WithCORS(next).ServeHTTP(rec, req)
+	controller.WithCORS(next, corsConfig).ServeHTTP(rec, req)
 
 	require.True(t, called, "next handler should be called for non-OPTIONS request")
 	res := rec.Result()
 	require.Equal(t, http.StatusTeapot, res.StatusCode)
 
-	// headers should be present
-	require.Equal(t, "*", res.Header.Get("Access-Control-Allow-Origin"))
+	// origin is echoed
+	require.Equal(t, "https://app.example.com", res.Header.Get("Access-Control-Allow-Origin"))
 	require.Equal(t, "true", res.Header.Get("Access-Control-Allow-Credentials"))
-	require.NotEmpty(t, res.Header.Get("Access-Control-Allow-Headers"))
-	require.NotEmpty(t, res.Header.Get("Access-Control-Allow-Methods"))
+	require.Equal(t, "Origin", res.Header.Get("Vary"))
+}
+
+func TestWithCORS_DisallowedOrigin(t *testing.T) {
+	called := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/path", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	rec := httptest.NewRecorder()
+
+	controller.WithCORS(next, corsConfig).ServeHTTP(rec, req)
+
+	require.True(t, called, "request is still served; the browser enforces CORS")
+	res := rec.Result()
+	require.Empty(t, res.Header.Get("Access-Control-Allow-Origin"))
+	require.Empty(t, res.Header.Get("Access-Control-Allow-Credentials"))
+}
+
+func TestWithCORS_Wildcard(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	cfg := controller.CORSConfig{AllowedOrigins: []string{"*"}}
+
+	req := httptest.NewRequest(http.MethodGet, "/path", nil)
+	req.Header.Set("Origin", "https://any.example.com")
+	rec := httptest.NewRecorder()
+	controller.WithCORS(next, cfg).ServeHTTP(rec, req)
+	require.Equal(t, "*", rec.Result().Header.Get("Access-Control-Allow-Origin"))
+
+	// with credentials the origin is echoed instead of "*"
+	cfg.AllowCredentials = true
+	rec = httptest.NewRecorder()
+	controller.WithCORS(next, cfg).ServeHTTP(rec, req)
+	require.Equal(t, "https://any.example.com", rec.Result().Header.Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "true", rec.Result().Header.Get("Access-Control-Allow-Credentials"))
 }
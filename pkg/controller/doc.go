@@ -1,7 +1,10 @@
 // Package controller contains HTTP middlewares and helper handlers used by the API server.
 //
 // Provided middlewares:
-//   - WithCORS: Adds permissive CORS headers and handles OPTIONS preflight.
+//   - WithAuth: Requires a bearer token or basic auth credentials, responding 401 otherwise.
+//   - WithCORS: Adds CORS headers for allowed origins and handles OPTIONS preflight.
+//   - WithMaxBodyBytes: Rejects request bodies larger than a configured limit with 413.
+//   - WithRecover: Recovers handler panics, logs them and responds with a JSON 500.
 //   - WithLogger: Attaches a request-scoped logger and request ID to the context and logs access info.
 //
 // Provided helpers:
@@ -2,13 +2,17 @@ package controller
 
 import (
 	"context"
+	"crypto/rand"
 	"net"
 	"net/http"
 	"scanner/pkg/logger"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -59,12 +63,61 @@ type CtxKey string
 const (
 	// RequestIDKey is the context key under which the current request ID is stored.
 	RequestIDKey CtxKey = "RequestID"
+	// TraceIDKey is the logger field under which the current trace ID is logged.
+	TraceIDKey = "trace_id"
+	// TraceIDHeader is the response header carrying the current trace ID.
+	TraceIDHeader = "X-Trace-Id"
 )
 
-// WithLogger returns a middleware that injects a request-scoped logger and
-// request ID into the context, then logs a structured access log after the
-// handler finishes.
-func WithLogger(next http.Handler) http.Handler {
+// GetRequestID returns the request ID stored in ctx by WithLogger, or an empty
+// string when there is none.
+func GetRequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(RequestIDKey).(string)
+
+	return requestID
+}
+
+// withTraceContext extracts the W3C trace context of the request into ctx.
+// When the request carries no valid traceparent, a new trace is started by
+// attaching a random span context, so every request has a trace ID.
+func withTraceContext(ctx context.Context, r *http.Request) (context.Context, trace.TraceID) {
+	ctx = propagation.TraceContext{}.Extract(ctx, propagation.HeaderCarrier(r.Header))
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return ctx, sc.TraceID()
+	}
+
+	var traceID trace.TraceID
+	var spanID trace.SpanID
+	_, _ = rand.Read(traceID[:])
+	_, _ = rand.Read(spanID[:])
+	ctx = trace.ContextWithRemoteSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	return ctx, traceID
+}
+
+// AccessLogConfig configures which requests WithLogger writes access logs for.
+type AccessLogConfig struct {
+	// SampleRate logs one in every SampleRate fast 2xx requests. Values <= 1
+	// log every request. Non-2xx and slow requests are always logged.
+	SampleRate int
+	// SlowRequestThreshold is the latency from which requests are logged at
+	// Warn level regardless of sampling. Zero disables slow request detection.
+	SlowRequestThreshold time.Duration
+}
+
+// WithLogger returns a middleware that injects a request-scoped logger, the
+// request ID and the W3C trace context (see withTraceContext) into the
+// context, sets the request ID and trace ID response headers, then logs a structured access log after the
+// handler finishes. Fast 2xx requests are sampled according to cfg.SampleRate,
+// non-2xx requests are always logged and requests slower than
+// cfg.SlowRequestThreshold are logged at Warn level.
+func WithLogger(next http.Handler, cfg AccessLogConfig) http.Handler {
+	var requests atomic.Uint64
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
@@ -74,18 +127,38 @@ func WithLogger(next http.Handler) http.Handler {
 			requestID = uuid.New().String()
 		}
 		ctx = context.WithValue(ctx, RequestIDKey, requestID)
+		w.Header().Set("X-Request-Id", requestID)
+
+		// set trace context
+		ctx, traceID := withTraceContext(ctx, r)
+		w.Header().Set(TraceIDHeader, traceID.String())
 
 		// set logger
-		ctx = logger.WithFields(ctx, zap.String(string(RequestIDKey), requestID))
+		ctx = logger.WithFields(ctx,
+			zap.String(string(RequestIDKey), requestID),
+			zap.String(TraceIDKey, traceID.String()))
 
 		start := time.Now()
 		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
 
 		next.ServeHTTP(rec, r.WithContext(ctx))
 
-		logger.Info(ctx, "Access log",
+		latency := time.Since(start)
+		slow := cfg.SlowRequestThreshold > 0 && latency >= cfg.SlowRequestThreshold
+		success := rec.status >= 200 && rec.status < 300
+		if success && !slow && cfg.SampleRate > 1 && requests.Add(1)%uint64(cfg.SampleRate) != 1 { //nolint: gosec
+			return
+		}
+
+		log := logger.Info
+		msg := "Access log"
+		if slow {
+			log = logger.Warn
+			msg = "Slow request"
+		}
+		log(ctx, msg,
 			zap.Int("status_code", rec.status),
-			zap.Float64("latency", time.Since(start).Seconds()),
+			zap.Float64("latency", latency.Seconds()),
 			zap.String("client_ip", GetClientIP(r)),
 			zap.String("user_agent", r.UserAgent()),
 			zap.String("url", r.URL.String()),
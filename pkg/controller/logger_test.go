@@ -1,14 +1,20 @@
 package controller_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"scanner/pkg/controller"
 	"testing"
+	"time"
 
 	"scanner/pkg/logger"
 
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestGetClientIP_XForwardedFor(t *testing.T) {
@@ -51,16 +57,114 @@ func TestWithLogger_SetsRequestIDAndPassesStatus(t *testing.T) {
 	req1 := httptest.NewRequest(http.MethodGet, "/", nil)
 	req1.Header.Set("X-Request-Id", "abc-123")
 	rec1 := httptest.NewRecorder()
-	controller.WithLogger(next).ServeHTTP(rec1, req1)
+	controller.WithLogger(next, controller.AccessLogConfig{}).ServeHTTP(rec1, req1)
 	res1 := rec1.Result()
 	require.Equal(t, http.StatusCreated, res1.StatusCode)
 	require.Equal(t, "abc-123", res1.Header.Get("X-Echo-Request-Id"))
+	require.Equal(t, "abc-123", res1.Header.Get("X-Request-Id"))
 
 	// Case 2: request without header should still receive a generated ID
 	req2 := httptest.NewRequest(http.MethodGet, "/", nil)
 	rec2 := httptest.NewRecorder()
-	controller.WithLogger(next).ServeHTTP(rec2, req2)
+	controller.WithLogger(next, controller.AccessLogConfig{}).ServeHTTP(rec2, req2)
 	res2 := rec2.Result()
 	require.Equal(t, http.StatusCreated, res2.StatusCode)
 	require.NotEmpty(t, res2.Header.Get("X-Echo-Request-Id"))
+	require.Equal(t, res2.Header.Get("X-Echo-Request-Id"), res2.Header.Get("X-Request-Id"))
+}
+
+// serveLogged serves a request through WithLogger with an observed logger and
+// returns the recorded access logs.
+func serveLogged(t *testing.T, handler http.Handler, n int) []observer.LoggedEntry {
+	t.Helper()
+
+	core, logs := observer.New(zap.DebugLevel)
+	for range n {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req = req.WithContext(logger.WithLogger(req.Context(), zap.New(core)))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	return logs.All()
+}
+
+func TestWithLogger_SamplesFastSuccess(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := controller.WithLogger(next, controller.AccessLogConfig{SampleRate: 3, SlowRequestThreshold: time.Minute})
+
+	// 1 in 3 fast 200s are logged
+	entries := serveLogged(t, handler, 6)
+	require.Len(t, entries, 2)
+	for _, e := range entries {
+		require.Equal(t, zapcore.InfoLevel, e.Level)
+	}
+}
+
+func TestWithLogger_SlowRequestLoggedAtWarn(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+	})
+	handler := controller.WithLogger(next, controller.AccessLogConfig{SampleRate: 100, SlowRequestThreshold: 10 * time.Millisecond})
+
+	entries := serveLogged(t, handler, 3)
+	require.Len(t, entries, 3)
+	for _, e := range entries {
+		require.Equal(t, zapcore.WarnLevel, e.Level)
+		require.Equal(t, "Slow request", e.Message)
+	}
+}
+
+func TestWithLogger_ErrorsAlwaysLogged(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	handler := controller.WithLogger(next, controller.AccessLogConfig{SampleRate: 100})
+
+	entries := serveLogged(t, handler, 3)
+	require.Len(t, entries, 3)
+	require.EqualValues(t, http.StatusInternalServerError, entries[0].ContextMap()["status_code"])
+	require.NotEmpty(t, entries[0].ContextMap()[string(controller.RequestIDKey)])
+}
+
+func TestWithLogger_PropagatesTraceID(t *testing.T) {
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	var ctxTraceID string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctxTraceID = trace.SpanContextFromContext(r.Context()).TraceID().String()
+	})
+
+	core, logs := observer.New(zap.DebugLevel)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	req = req.WithContext(logger.WithLogger(req.Context(), zap.New(core)))
+	rec := httptest.NewRecorder()
+	controller.WithLogger(next, controller.AccessLogConfig{}).ServeHTTP(rec, req)
+
+	require.Equal(t, traceID, rec.Result().Header.Get(controller.TraceIDHeader))
+	require.Equal(t, traceID, ctxTraceID)
+	require.Equal(t, 1, logs.Len())
+	require.Equal(t, traceID, logs.All()[0].ContextMap()[controller.TraceIDKey])
+}
+
+func TestWithLogger_GeneratesTraceID(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	core, logs := observer.New(zap.DebugLevel)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("traceparent", "invalid")
+	req = req.WithContext(logger.WithLogger(req.Context(), zap.New(core)))
+	rec := httptest.NewRecorder()
+	controller.WithLogger(next, controller.AccessLogConfig{}).ServeHTTP(rec, req)
+
+	traceID := rec.Result().Header.Get(controller.TraceIDHeader)
+	require.Len(t, traceID, 32)
+	require.NotEqual(t, "00000000000000000000000000000000", traceID)
+	require.Equal(t, traceID, logs.All()[0].ContextMap()[controller.TraceIDKey])
+}
+
+func TestGetRequestID(t *testing.T) {
+	require.Empty(t, controller.GetRequestID(context.Background()))
+
+	ctx := context.WithValue(context.Background(), controller.RequestIDKey, "abc-123")
+	require.Equal(t, "abc-123", controller.GetRequestID(ctx))
 }
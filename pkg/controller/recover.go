@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"scanner/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// internalErrorBody is the JSON error body returned when a handler panics. It
+// follows the shape of the API's error schema.
+const internalErrorBody = `{"code":"INTERNAL","message":"internal error"}`
+
+// WithRecover returns a middleware that recovers panics raised by next, logs
+// them and responds with a JSON 500. It is meant to be the outermost
+// middleware, so the request ID is taken from the X-Request-Id response header
+// set by WithLogger, if any. Panics with http.ErrAbortHandler are re-raised so
+// net/http can abort the response as intended.
+func WithRecover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rvr := recover()
+			if rvr == nil {
+				return
+			}
+			if err, ok := rvr.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(rvr)
+			}
+
+			logger.Error(r.Context(), "recovered from panic in HTTP handler",
+				zap.String(string(RequestIDKey), w.Header().Get("X-Request-Id")),
+				zap.String("panic", fmt.Sprint(rvr)),
+				zap.StackSkip("stack", 2),
+			)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(internalErrorBody))
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
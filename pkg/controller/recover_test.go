@@ -0,0 +1,68 @@
+package controller_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"scanner/pkg/controller"
+	"scanner/pkg/logger"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestWithRecover_Panic(t *testing.T) {
+	core, logs := observer.New(zap.ErrorLevel)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/scans", nil)
+	req.Header.Set("X-Request-Id", "abc-123")
+	req = req.WithContext(logger.WithLogger(req.Context(), zap.New(core)))
+	rec := httptest.NewRecorder()
+
+	// recover wraps the logger, as in the API server
+	require.NotPanics(t, func() {
+		controller.WithRecover(controller.WithLogger(next, controller.AccessLogConfig{})).ServeHTTP(rec, req)
+	})
+
+	res := rec.Result()
+	require.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	require.Equal(t, "application/json", res.Header.Get("Content-Type"))
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"code":"INTERNAL","message":"internal error"}`, string(body))
+
+	// panic is logged with the request ID set by the logger
+	require.Equal(t, 1, logs.Len())
+	fields := logs.All()[0].ContextMap()
+	require.Equal(t, "boom", fields["panic"])
+	require.Equal(t, "abc-123", fields["RequestID"])
+}
+
+func TestWithRecover_NoPanic(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	controller.WithRecover(next).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusTeapot, rec.Result().StatusCode)
+}
+
+func TestWithRecover_AbortHandler(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		controller.WithRecover(next).ServeHTTP(rec, req)
+	})
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,7 +12,7 @@ import (
 type ScanID uuid.UUID
 
 // ScanStatus represents the lifecycle state of a scan.
-// It can be pending, completed, or failed.
+// It can be pending, completed, failed, or canceled.
 type ScanStatus string
 
 const (
@@ -21,8 +22,69 @@ const (
 	ScanStatusCompleted ScanStatus = "COMPLETED"
 	// ScanStatusFailed indicates the scan ended with an error; see LastError and Attempts for details.
 	ScanStatusFailed ScanStatus = "FAILED"
+	// ScanStatusCanceled indicates the scan was deleted by its user while still pending.
+	ScanStatusCanceled ScanStatus = "CANCELED"
 )
 
+// Valid reports whether s is one of the known scan statuses.
+func (s ScanStatus) Valid() bool {
+	switch s {
+	case ScanStatusPending, ScanStatusCompleted, ScanStatusFailed, ScanStatusCanceled:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsTerminal reports whether s is a final state that a scan does not leave
+// on its own, i.e. anything but pending.
+func (s ScanStatus) IsTerminal() bool {
+	switch s {
+	case ScanStatusCompleted, ScanStatusFailed, ScanStatusCanceled:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseScanStatus converts a string into a ScanStatus, returning an error when
+// it is not one of the known statuses. Matching is case-sensitive.
+func ParseScanStatus(s string) (ScanStatus, error) {
+	status := ScanStatus(s)
+	if !status.Valid() {
+		return "", fmt.Errorf("unknown scan status %q", s)
+	}
+
+	return status, nil
+}
+
+// ScanSearchKey names a field of the scanned page in a ScanResult that scans
+// can be searched by.
+type ScanSearchKey string
+
+const (
+	// ScanSearchKeyIP searches by the IP address the page was served from.
+	ScanSearchKeyIP ScanSearchKey = "ip"
+	// ScanSearchKeyASN searches by the autonomous system number, e.g. AS15133.
+	ScanSearchKeyASN ScanSearchKey = "asn"
+	// ScanSearchKeyServer searches by the server header of the page.
+	ScanSearchKeyServer ScanSearchKey = "server"
+	// ScanSearchKeyDomain searches by the domain of the page.
+	ScanSearchKeyDomain ScanSearchKey = "domain"
+	// ScanSearchKeyCountry searches by the country the page was served from.
+	ScanSearchKeyCountry ScanSearchKey = "country"
+)
+
+// Valid reports whether k is one of the known search keys.
+func (k ScanSearchKey) Valid() bool {
+	switch k {
+	case ScanSearchKeyIP, ScanSearchKeyASN, ScanSearchKeyServer, ScanSearchKeyDomain, ScanSearchKeyCountry:
+		return true
+	default:
+		return false
+	}
+}
+
 // ScanResult holds the normalized outcome of a URL scan, including
 // page metadata, a verdict, and aggregated stats.
 type ScanResult struct {
@@ -36,8 +98,10 @@ type ScanResult struct {
 	} `json:"page,omitempty"`
 
 	Verdict *struct {
-		Malicious bool `json:"malicious"`
-		Score     int  `json:"score"`
+		Malicious  bool     `json:"malicious"`
+		Score      int      `json:"score"`
+		Categories []string `json:"categories,omitempty"`
+		Brands     []string `json:"brands,omitempty"`
 	} `json:"verdicts,omitempty"`
 
 	Stats *struct {
@@ -64,6 +128,17 @@ type Scan struct {
 	Attempts uint `json:"attempts"`
 	// LastError stores the most recent error message, if any, encountered while processing the scan.
 	LastError string `json:"-"`
+	// NextRetryAt is when the scan job is scheduled to run next. It is only
+	// populated for single-scan lookups of pending scans with an active job.
+	NextRetryAt time.Time `json:"-"`
+	// Version is incremented on every update and is used for optimistic concurrency control.
+	Version int64 `json:"-"`
+	// IdempotencyKey is the client-provided key the scan was created with, if any.
+	// It is unique per user among non-deleted scans.
+	IdempotencyKey string `json:"-"`
+	// Shareable reports whether the result may be reused for scans of other
+	// users, i.e. the scan was submitted publicly.
+	Shareable bool `json:"-"`
 
 	// CreatedAt is the time when the scan request was created.
 	CreatedAt time.Time `json:"createdAt"`
@@ -72,3 +147,9 @@ type Scan struct {
 	// DeletedAt marks when the scan was soft-deleted; zero value means not deleted.
 	DeletedAt time.Time `json:"-"`
 }
+
+// IsTerminal reports whether the scan reached a terminal status; see
+// ScanStatus.IsTerminal.
+func (s *Scan) IsTerminal() bool {
+	return s.Status.IsTerminal()
+}
@@ -0,0 +1,63 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"scanner/pkg/domain"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScanStatus(t *testing.T) {
+	for _, status := range []domain.ScanStatus{
+		domain.ScanStatusPending,
+		domain.ScanStatusCompleted,
+		domain.ScanStatusFailed,
+		domain.ScanStatusCanceled,
+	} {
+		t.Run(string(status), func(t *testing.T) {
+			require.True(t, status.Valid())
+			got, err := domain.ParseScanStatus(string(status))
+			require.NoError(t, err)
+			require.Equal(t, status, got)
+		})
+	}
+
+	for _, raw := range []string{"BOGUS", "pending", ""} {
+		t.Run("invalid "+raw, func(t *testing.T) {
+			require.False(t, domain.ScanStatus(raw).Valid())
+			_, err := domain.ParseScanStatus(raw)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestScanStatus_IsTerminal(t *testing.T) {
+	for status, terminal := range map[domain.ScanStatus]bool{
+		domain.ScanStatusPending:   false,
+		domain.ScanStatusCompleted: true,
+		domain.ScanStatusFailed:    true,
+		domain.ScanStatusCanceled:  true,
+		domain.ScanStatus("BOGUS"): false,
+	} {
+		t.Run(string(status), func(t *testing.T) {
+			require.Equal(t, terminal, status.IsTerminal())
+			require.Equal(t, terminal, (&domain.Scan{Status: status}).IsTerminal())
+		})
+	}
+}
+
+func TestScanResult_JSONBackwardCompatible(t *testing.T) {
+	// results stored before categories and brands were added still decode
+	var res domain.ScanResult
+	require.NoError(t, json.Unmarshal([]byte(`{"verdicts":{"malicious":true,"score":42}}`), &res))
+	require.NotNil(t, res.Verdict)
+	require.True(t, res.Verdict.Malicious)
+	require.Nil(t, res.Verdict.Categories)
+	require.Nil(t, res.Verdict.Brands)
+
+	// empty categories and brands are not written
+	b, err := json.Marshal(res)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"verdicts":{"malicious":true,"score":42}}`, string(b))
+}
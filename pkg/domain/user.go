@@ -1,7 +1,32 @@
 package domain
 
-import "github.com/google/uuid"
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
 
 // UserID uniquely identifies a user within the system.
 // It is a thin wrapper around uuid.UUID to provide type safety at the domain layer.
 type UserID uuid.UUID
+
+// ParseUserID converts a string into a UserID, returning an error when it is
+// not a valid UUID.
+func ParseUserID(s string) (UserID, error) {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return UserID{}, fmt.Errorf("invalid user id %q: %w", s, err)
+	}
+
+	return UserID(id), nil
+}
+
+// String returns the canonical UUID representation of the user ID.
+func (id UserID) String() string {
+	return uuid.UUID(id).String()
+}
+
+// IsZero reports whether id is the zero (nil) UUID, i.e. no user.
+func (id UserID) IsZero() bool {
+	return id == UserID{}
+}
@@ -0,0 +1,37 @@
+package domain_test
+
+import (
+	"scanner/pkg/domain"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUserID(t *testing.T) {
+	raw := uuid.New()
+
+	id, err := domain.ParseUserID(raw.String())
+	require.NoError(t, err)
+	require.Equal(t, domain.UserID(raw), id)
+	require.Equal(t, raw.String(), id.String())
+	require.False(t, id.IsZero())
+
+	for _, invalid := range []string{"", "not-a-uuid", "1234"} {
+		t.Run("invalid "+invalid, func(t *testing.T) {
+			_, err := domain.ParseUserID(invalid)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestUserID_IsZero(t *testing.T) {
+	require.True(t, domain.UserID{}.IsZero())
+	require.True(t, domain.UserID(uuid.Nil).IsZero())
+	require.Equal(t, "00000000-0000-0000-0000-000000000000", domain.UserID{}.String())
+
+	// the nil UUID parses but is still zero
+	id, err := domain.ParseUserID(uuid.Nil.String())
+	require.NoError(t, err)
+	require.True(t, id.IsZero())
+}
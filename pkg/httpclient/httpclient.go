@@ -0,0 +1,150 @@
+// Package httpclient builds the *http.Client used to talk to external APIs,
+// with connection pooling and timeouts tuned in one place.
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Default values used for zero fields of Options.
+const (
+	DefaultTimeout               = 30 * time.Second
+	DefaultDialTimeout           = 10 * time.Second
+	DefaultTLSHandshakeTimeout   = 10 * time.Second
+	DefaultResponseHeaderTimeout = 30 * time.Second
+	DefaultIdleConnTimeout       = 90 * time.Second
+	DefaultMaxIdleConns          = 100
+	DefaultMaxIdleConnsPerHost   = 10
+	DefaultTLSMinVersion         = tls.VersionTLS12
+)
+
+// Options tunes the client built by New. Zero fields use the matching
+// Default value.
+type Options struct {
+	// Timeout limits the whole exchange, including reading the response body.
+	Timeout time.Duration
+	// DialTimeout limits establishing a TCP connection.
+	DialTimeout time.Duration
+	// TLSHandshakeTimeout limits the TLS handshake.
+	TLSHandshakeTimeout time.Duration
+	// ResponseHeaderTimeout limits waiting for the response headers once the
+	// request was written.
+	ResponseHeaderTimeout time.Duration
+	// IdleConnTimeout is how long an idle pooled connection is kept.
+	IdleConnTimeout time.Duration
+	// MaxIdleConns caps the idle pooled connections across all hosts.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost caps the idle pooled connections per host. The
+	// net/http default of 2 makes concurrent jobs against the same API
+	// reconnect constantly.
+	MaxIdleConnsPerHost int
+	// TLSMinVersion is the minimum accepted TLS version, e.g. tls.VersionTLS12.
+	TLSMinVersion uint16
+	// CAFile is the path of a PEM bundle of CA certificates trusted in
+	// addition to the system roots, e.g. for appliances behind a private CA.
+	CAFile string
+	// CertFile and KeyFile are the paths of a PEM client certificate and its
+	// key presented for mutual TLS. Both or neither must be set.
+	CertFile string
+	KeyFile  string
+}
+
+// withDefaults returns o with zero fields set to their Default value.
+func (o Options) withDefaults() Options {
+	if o.Timeout == 0 {
+		o.Timeout = DefaultTimeout
+	}
+	if o.DialTimeout == 0 {
+		o.DialTimeout = DefaultDialTimeout
+	}
+	if o.TLSHandshakeTimeout == 0 {
+		o.TLSHandshakeTimeout = DefaultTLSHandshakeTimeout
+	}
+	if o.ResponseHeaderTimeout == 0 {
+		o.ResponseHeaderTimeout = DefaultResponseHeaderTimeout
+	}
+	if o.IdleConnTimeout == 0 {
+		o.IdleConnTimeout = DefaultIdleConnTimeout
+	}
+	if o.MaxIdleConns == 0 {
+		o.MaxIdleConns = DefaultMaxIdleConns
+	}
+	if o.MaxIdleConnsPerHost == 0 {
+		o.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	}
+	if o.TLSMinVersion == 0 {
+		o.TLSMinVersion = DefaultTLSMinVersion
+	}
+
+	return o
+}
+
+// tlsConfig returns the TLS configuration of the client, loading the CA
+// bundle and client certificate of opts.
+func (o Options) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{MinVersion: o.TLSMinVersion} //nolint: gosec
+
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("CA file contains no PEM certificates")
+		}
+		config.RootCAs = pool
+	}
+
+	if (o.CertFile == "") != (o.KeyFile == "") {
+		return nil, errors.New("client certificate and key files must be set together")
+	}
+	if o.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// New returns an *http.Client whose transport is a clone of
+// http.DefaultTransport, keeping its proxy and HTTP/2 settings, tuned with
+// opts. The transport is an *http.Transport, so callers may wrap it. An error
+// is returned when the CA bundle or client certificate cannot be loaded.
+func New(opts Options) (*http.Client, error) {
+	opts = opts.withDefaults()
+	tlsConfig, err := opts.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone() //nolint: forcetypeassert
+	transport.DialContext = (&net.Dialer{
+		Timeout:   opts.DialTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	transport.TLSHandshakeTimeout = opts.TLSHandshakeTimeout
+	transport.ResponseHeaderTimeout = opts.ResponseHeaderTimeout
+	transport.IdleConnTimeout = opts.IdleConnTimeout
+	transport.MaxIdleConns = opts.MaxIdleConns
+	transport.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   opts.Timeout,
+	}, nil
+}
@@ -0,0 +1,174 @@
+package httpclient_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"scanner/pkg/httpclient"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_AppliesOptions(t *testing.T) {
+	c, err := httpclient.New(httpclient.Options{
+		Timeout:               5 * time.Second,
+		DialTimeout:           time.Second,
+		TLSHandshakeTimeout:   2 * time.Second,
+		ResponseHeaderTimeout: 3 * time.Second,
+		IdleConnTimeout:       4 * time.Second,
+		MaxIdleConns:          50,
+		MaxIdleConnsPerHost:   25,
+		TLSMinVersion:         tls.VersionTLS13,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, 5*time.Second, c.Timeout)
+	transport, ok := c.Transport.(*http.Transport)
+	require.True(t, ok)
+	require.NotNil(t, transport.DialContext)
+	require.Equal(t, 2*time.Second, transport.TLSHandshakeTimeout)
+	require.Equal(t, 3*time.Second, transport.ResponseHeaderTimeout)
+	require.Equal(t, 4*time.Second, transport.IdleConnTimeout)
+	require.Equal(t, 50, transport.MaxIdleConns)
+	require.Equal(t, 25, transport.MaxIdleConnsPerHost)
+	require.Equal(t, uint16(tls.VersionTLS13), transport.TLSClientConfig.MinVersion)
+	// settings of the default transport are kept
+	require.NotNil(t, transport.Proxy)
+	require.True(t, transport.ForceAttemptHTTP2)
+}
+
+func TestNew_Defaults(t *testing.T) {
+	c, err := httpclient.New(httpclient.Options{})
+	require.NoError(t, err)
+
+	require.Equal(t, httpclient.DefaultTimeout, c.Timeout)
+	transport, ok := c.Transport.(*http.Transport)
+	require.True(t, ok)
+	require.Equal(t, httpclient.DefaultMaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	require.Equal(t, httpclient.DefaultResponseHeaderTimeout, transport.ResponseHeaderTimeout)
+	require.Equal(t, uint16(httpclient.DefaultTLSMinVersion), transport.TLSClientConfig.MinVersion)
+
+	// the default transport itself is not modified
+	require.NotSame(t, http.DefaultTransport, c.Transport)
+	require.NotEqual(t, httpclient.DefaultMaxIdleConnsPerHost,
+		http.DefaultTransport.(*http.Transport).MaxIdleConnsPerHost) //nolint: forcetypeassert
+}
+
+// writePEM writes the PEM block of the given type and bytes to a file in a
+// temporary directory and returns its path.
+func writePEM(t *testing.T, name, blockType string, der []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+
+	return path
+}
+
+// newClientCert returns a self-signed client certificate and the paths of its
+// PEM certificate and key files.
+func newClientCert(t *testing.T) (*x509.Certificate, string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "scanner"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	return cert, writePEM(t, "client.pem", "CERTIFICATE", der), writePEM(t, "client-key.pem", "PRIVATE KEY", keyDER)
+}
+
+func TestNew_TrustsCAFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// the self-signed server certificate is not trusted by default
+	c, err := httpclient.New(httpclient.Options{})
+	require.NoError(t, err)
+	_, err = c.Get(server.URL) //nolint: noctx
+	require.Error(t, err)
+
+	// it is once its CA is configured
+	caFile := writePEM(t, "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+	c, err = httpclient.New(httpclient.Options{CAFile: caFile})
+	require.NoError(t, err)
+	resp, err := c.Get(server.URL) //nolint: noctx
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+func TestNew_PresentsClientCertificate(t *testing.T) {
+	clientCert, certFile, keyFile := newClientCert(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "scanner", r.TLS.PeerCertificates[0].Subject.CommonName)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs} //nolint: gosec
+	server.StartTLS()
+	defer server.Close()
+	caFile := writePEM(t, "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+
+	// the server rejects clients without a certificate
+	c, err := httpclient.New(httpclient.Options{CAFile: caFile})
+	require.NoError(t, err)
+	_, err = c.Get(server.URL) //nolint: noctx
+	require.Error(t, err)
+
+	c, err = httpclient.New(httpclient.Options{CAFile: caFile, CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+	resp, err := c.Get(server.URL) //nolint: noctx
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+func TestNew_InvalidTLSFiles(t *testing.T) {
+	_, certFile, keyFile := newClientCert(t)
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0o600))
+
+	cases := map[string]httpclient.Options{
+		"missing CA file":     {CAFile: filepath.Join(t.TempDir(), "missing.pem")},
+		"CA file without PEM": {CAFile: notPEM},
+		"cert without key":    {CertFile: certFile},
+		"key without cert":    {KeyFile: keyFile},
+		"mismatched key pair": {CertFile: certFile, KeyFile: certFile},
+	}
+	for name, opts := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := httpclient.New(opts)
+			require.Error(t, err)
+		})
+	}
+}
@@ -5,6 +5,9 @@ package logger
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -21,21 +24,48 @@ const (
 )
 
 // defaultLogger is the package-level logger instance used when no logger is found in context.
-var defaultLogger *zap.Logger //nolint: gochecknoglobals
+// It is stored atomically so Setup may run concurrently with logging.
+var defaultLogger atomic.Pointer[zap.Logger] //nolint: gochecknoglobals
+
+// level is the minimum enabled level of the default logger. It is shared by
+// every logger derived from it and can be changed at runtime via LevelHandler.
+var level = zap.NewAtomicLevel() //nolint: gochecknoglobals
 
 // Setup initializes the default logger based on the environment.
 // It configures the logger with appropriate settings for either development or production use.
+// The environment's default level becomes the current runtime level.
 //
 // Parameters:
 //   - environment: A string indicating the environment ("development" or "production").
 func Setup(environment string) {
+	cfg := zap.NewDevelopmentConfig()
 	if environment == ProductionEnvironment {
-		defaultLogger, _ = zap.NewProduction()
+		cfg = zap.NewProductionConfig()
+	}
+
+	level.SetLevel(cfg.Level.Level())
+	cfg.Level = level
+	l, _ := cfg.Build()
+	defaultLogger.Store(l)
+}
 
-		return
+// LevelHandler returns an HTTP handler reporting the current level of the
+// default logger on GET and changing it on PUT, e.g. with a body of
+// {"level":"debug"}. See zap.AtomicLevel.ServeHTTP for the exact protocol.
+func LevelHandler() http.Handler {
+	return level
+}
+
+// SetLevel changes the level of the default logger at runtime, e.g. to
+// "debug" or "warn". It returns an error for unknown levels.
+func SetLevel(l string) error {
+	lvl, err := zapcore.ParseLevel(l)
+	if err != nil {
+		return fmt.Errorf("could not parse log level: %w", err)
 	}
+	level.SetLevel(lvl)
 
-	defaultLogger, _ = zap.NewDevelopment()
+	return nil
 }
 
 // key is a custom type used as a context key for storing and retrieving logger instances.
@@ -48,7 +78,7 @@ func Get(ctx context.Context) *zap.Logger {
 		return logger
 	}
 
-	return defaultLogger
+	return defaultLogger.Load()
 }
 
 // WithLogger creates a new context with the provided logger attached.
@@ -63,6 +93,13 @@ func WithFields(ctx context.Context, fields ...zapcore.Field) context.Context {
 	return WithLogger(ctx, Get(ctx).With(fields...))
 }
 
+// Sync flushes any buffered log entries of the logger in the context. It
+// should be called before the process exits. The error of the underlying
+// writer is returned as is.
+func Sync(ctx context.Context) error {
+	return Get(ctx).Sync() //nolint: wrapcheck
+}
+
 // IsDebug checks if the logger in the context is configured at debug level.
 func IsDebug(ctx context.Context) bool {
 	return Get(ctx).Level() == zap.DebugLevel
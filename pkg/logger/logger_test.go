@@ -2,7 +2,12 @@ package logger_test
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"scanner/pkg/logger"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -128,3 +133,90 @@ func TestLoggingFunctions(t *testing.T) {
 		logger.Error(ctx, "error message", zap.String("key", "value"))
 	})
 }
+
+func TestLevelHandler(t *testing.T) {
+	logger.Setup(logger.ProductionEnvironment)
+	ctx := context.Background()
+	handler := logger.LevelHandler()
+
+	// GET returns the current level
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/loglevel", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"level":"info"}`, rec.Body.String())
+	require.False(t, logger.IsDebug(ctx))
+
+	// PUT changes the level of the default logger
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/debug/loglevel", strings.NewReader(`{"level":"debug"}`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"level":"debug"}`, rec.Body.String())
+	require.True(t, logger.IsDebug(ctx))
+
+	// loggers derived from the default logger follow the change
+	require.True(t, logger.IsDebug(logger.WithFields(ctx, zap.String("k", "v"))))
+
+	// Setup resets the level to the environment's default
+	logger.Setup(logger.ProductionEnvironment)
+	require.False(t, logger.IsDebug(ctx))
+}
+
+func TestSetLevel(t *testing.T) {
+	logger.Setup(logger.ProductionEnvironment)
+	ctx := context.Background()
+
+	require.NoError(t, logger.SetLevel("debug"))
+	require.True(t, logger.IsDebug(ctx))
+
+	require.Error(t, logger.SetLevel("loud"))
+	require.True(t, logger.IsDebug(ctx), "invalid levels keep the current level")
+
+	logger.Setup(logger.ProductionEnvironment)
+}
+
+func TestSetup_ConcurrentWithGet(t *testing.T) {
+	logger.Setup(logger.DevelopmentEnvironment)
+	ctx := context.Background()
+
+	// run with -race: concurrent Setup and Get must not race
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if i%2 == 0 {
+				logger.Setup(logger.DevelopmentEnvironment)
+			} else {
+				logger.Setup(logger.ProductionEnvironment)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			require.NotNil(t, logger.Get(ctx))
+			logger.Debug(ctx, "concurrent log")
+		}()
+	}
+	wg.Wait()
+}
+
+// failingSyncer is a zapcore.WriteSyncer whose Sync always fails.
+type failingSyncer struct{ err error }
+
+func (f failingSyncer) Write(p []byte) (int, error) { return len(p), nil }
+func (f failingSyncer) Sync() error                 { return f.err }
+
+func TestSync(t *testing.T) {
+	logger.Setup(logger.DevelopmentEnvironment)
+
+	// development logger writes to stderr, which may not support syncing; it must not panic
+	require.NotPanics(t, func() {
+		_ = logger.Sync(context.Background())
+	})
+
+	// the writer's error is returned as is
+	errSync := errors.New("sync failed")
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+		failingSyncer{err: errSync}, zap.InfoLevel)
+	ctx := logger.WithLogger(context.Background(), zap.New(core))
+	require.ErrorIs(t, logger.Sync(ctx), errSync)
+}
@@ -0,0 +1,30 @@
+package logger
+
+import (
+	"net/http"
+)
+
+// Redacted replaces the values of sensitive headers in logs.
+const Redacted = "***"
+
+// sensitiveHeaders lists the headers whose values are never logged.
+var sensitiveHeaders = []string{ //nolint: gochecknoglobals
+	"Api-Key",
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// RedactHeaders returns a copy of h safe for logging, with the values of
+// credentials and cookies replaced by Redacted. h itself is not modified.
+func RedactHeaders(h http.Header) http.Header {
+	redacted := h.Clone()
+	for _, name := range sensitiveHeaders {
+		if _, ok := redacted[name]; ok {
+			redacted[name] = []string{Redacted}
+		}
+	}
+
+	return redacted
+}
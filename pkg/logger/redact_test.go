@@ -0,0 +1,34 @@
+package logger_test
+
+import (
+	"net/http"
+	"scanner/pkg/logger"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Set("Api-Key", "secret-token")
+	h.Set("Authorization", "Bearer secret")
+	h.Set("Proxy-Authorization", "Basic secret")
+	h.Add("Cookie", "session=secret")
+	h.Add("Set-Cookie", "a=1")
+	h.Add("Set-Cookie", "b=2")
+	h.Set("Content-Type", "application/json")
+
+	redacted := logger.RedactHeaders(h)
+	for _, name := range []string{"Api-Key", "Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"} {
+		require.Equal(t, []string{logger.Redacted}, redacted.Values(name), name)
+	}
+	require.Equal(t, "application/json", redacted.Get("Content-Type"))
+
+	// the original headers are left untouched
+	require.Equal(t, "secret-token", h.Get("Api-Key"))
+	require.Len(t, h.Values("Set-Cookie"), 2)
+}
+
+func TestRedactHeaders_Nil(t *testing.T) {
+	require.Nil(t, logger.RedactHeaders(nil))
+}
@@ -3,6 +3,7 @@ package serrors
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 // Kind is a marker interface implemented by all semantic error kinds created
@@ -64,9 +65,10 @@ var (
 //   - If only err is set: "<err>"
 //   - If neither set: the kind's Error() string.
 type Error struct {
-	kind Kind  // semantic kind sentinel
-	err  error // wrapped error (optional)
-	msg  string
+	kind       Kind  // semantic kind sentinel
+	err        error // wrapped error (optional)
+	msg        string
+	retryAfter time.Duration // hint of when the operation may be retried (optional)
 }
 
 // With constructs a new semantic error with the given kind and an arbitrary
@@ -149,3 +151,39 @@ func (e *Error) Message() string { return e.msg }
 
 // Cause returns the wrapped cause (may be nil).
 func (e *Error) Cause() error { return e.err }
+
+// WithRetryAfter sets a hint of how long callers should wait before retrying
+// (e.g. until a rate limit resets) and returns the same error for chaining.
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	e.retryAfter = d
+
+	return e
+}
+
+// RetryAfter returns the retry hint set with WithRetryAfter, or zero.
+func (e *Error) RetryAfter() time.Duration { return e.retryAfter }
+
+// KindOf returns the semantic kind of err: the kind of the outermost Error in
+// its chain that has one, or a bare Kind sentinel in the chain. It returns nil
+// if err carries no kind.
+func KindOf(err error) Kind {
+	// errors.As stops at the outermost Error, so Errors without a kind are
+	// skipped explicitly
+	for chain := err; chain != nil; {
+		var sem *Error
+		if !errors.As(chain, &sem) {
+			break
+		}
+		if sem.Kind() != nil {
+			return sem.Kind()
+		}
+		chain = sem.Unwrap()
+	}
+
+	var k Kind
+	if errors.As(err, &k) {
+		return k
+	}
+
+	return nil
+}
@@ -2,8 +2,10 @@ package serrors_test
 
 import (
 	"errors"
+	"fmt"
 	"scanner/pkg/serrors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -77,3 +79,24 @@ func TestAccessors(t *testing.T) {
 	require.Equal(t, "no token", e.Message())
 	require.Equal(t, base, e.Cause())
 }
+
+func TestRetryAfter(t *testing.T) {
+	e := serrors.With(serrors.ErrRateLimited, "slow down")
+	require.Zero(t, e.RetryAfter())
+
+	require.Same(t, e, e.WithRetryAfter(30*time.Second))
+	require.Equal(t, 30*time.Second, e.RetryAfter())
+}
+
+func TestKindOf(t *testing.T) {
+	require.Equal(t, serrors.ErrConflict, serrors.KindOf(serrors.With(serrors.ErrConflict, "dupe")))
+	require.Equal(t, serrors.ErrNotFound, serrors.KindOf(fmt.Errorf("lookup: %w", serrors.KindOnly(serrors.ErrNotFound))))
+	require.Equal(t, serrors.ErrTimeout, serrors.KindOf(fmt.Errorf("wait: %w", serrors.ErrTimeout)))
+	// the outermost kind wins
+	inner := serrors.With(serrors.ErrRateLimited, "slow down")
+	require.Equal(t, serrors.ErrUnavailable, serrors.KindOf(serrors.Wrap(serrors.ErrUnavailable, inner, "upstream")))
+	// Errors without a kind are skipped
+	require.Equal(t, serrors.ErrRateLimited, serrors.KindOf(serrors.Wrap(nil, fmt.Errorf("submit: %w", inner), "scan")))
+	require.Nil(t, serrors.KindOf(errors.New("boom")))
+	require.Nil(t, serrors.KindOf(nil))
+}
@@ -0,0 +1,21 @@
+package storage
+
+import (
+	"scanner/pkg/domain"
+	"scanner/pkg/urlscanner"
+	"time"
+)
+
+// ScanAttempt is a single processing attempt of a scan by the worker.
+type ScanAttempt struct {
+	// ID is the storage-assigned attempt identifier, increasing over time.
+	ID int64
+	// ScanID is the scan the attempt was made for.
+	ScanID domain.ScanID
+	// Error is the error the attempt failed with; empty when it succeeded.
+	Error string
+	// RateLimit is the upstream rate-limit status reported by the attempt.
+	RateLimit urlscanner.RateLimitStatus
+	// CreatedAt is the time the attempt was recorded.
+	CreatedAt time.Time
+}
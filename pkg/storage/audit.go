@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"scanner/pkg/domain"
+	"time"
+)
+
+// AuditAction is an operation on a scan recorded in the audit log.
+type AuditAction string
+
+const (
+	// AuditActionCreate records a scan created by Enqueue.
+	AuditActionCreate AuditAction = "CREATE"
+	// AuditActionDelete records a scan deleted by its user.
+	AuditActionDelete AuditAction = "DELETE"
+)
+
+// Valid reports whether a is one of the known audit actions.
+func (a AuditAction) Valid() bool {
+	switch a {
+	case AuditActionCreate, AuditActionDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// AuditEntry is a single entry of the audit log.
+type AuditEntry struct {
+	// ID is the storage-assigned entry identifier, increasing over time.
+	ID int64
+	// UserID is the user who performed the action.
+	UserID domain.UserID
+	// Action is the recorded operation.
+	Action AuditAction
+	// ScanID is the scan the action was performed on.
+	ScanID domain.ScanID
+	// CreatedAt is the time the entry was recorded.
+	CreatedAt time.Time
+}
+
+// AuditFilter narrows the entries returned by AuditLog. Zero-valued fields
+// are ignored.
+type AuditFilter struct {
+	// UserID filters results to entries of the given user.
+	UserID domain.UserID
+	// ScanID filters results to entries of the given scan.
+	ScanID domain.ScanID
+	// Action filters results to entries of the given action.
+	Action AuditAction
+	// Cursor returns entries with IDs lower than the given one; it is the
+	// NextCursor of the previous page.
+	Cursor int64
+	// Limit is the maximum number of entries returned.
+	Limit uint
+}
+
+// AuditEntries groups a page of audit entries together with an optional
+// NextCursor used for pagination.
+type AuditEntries struct {
+	// Entries contains the current page of entries.
+	Entries []AuditEntry
+	// NextCursor is the entry ID to be used as the cursor for fetching the next
+	// page. It is nil when there is no next page.
+	NextCursor *int64
+}
@@ -10,4 +10,7 @@ var (
 	// ErrNotInTx is returned when a transaction-specific operation is attempted
 	// while not currently inside a transaction.
 	ErrNotInTx = errors.New("not in tx")
+	// ErrVersionMismatch is returned, wrapped in a conflict error, when an update
+	// expects a version other than the stored one.
+	ErrVersionMismatch = errors.New("version mismatch")
 )
@@ -13,6 +13,7 @@ import "context"
 type AllStorage interface {
 	ScanStorage
 	JobStorage
+	RateLimitStorage
 }
 
 // TxStorage describes a storage handle that operates within a database
@@ -2,10 +2,45 @@ package storage
 
 import (
 	"context"
+	"scanner/pkg/domain"
+	"time"
 
 	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
 )
 
+// Job is a summary of a queued background job, used for inspecting the queue.
+type Job struct {
+	// ID is the queue-assigned job identifier.
+	ID int64
+	// Kind is the kind of the job arguments.
+	Kind string
+	// URL is the url argument of the job, empty when the job has none.
+	URL string
+	// State is the current state of the job.
+	State rivertype.JobState
+	// Queue is the name of the queue the job was inserted into.
+	Queue string
+	// Attempt is the number of times the job has been worked so far.
+	Attempt int
+	// MaxAttempts is the maximum number of attempts before the job is discarded.
+	MaxAttempts int
+	// CreatedAt is the time the job was inserted.
+	CreatedAt time.Time
+	// ScheduledAt is the time the job is (or was) scheduled to run next.
+	ScheduledAt time.Time
+}
+
+// Jobs groups a page of jobs together with an optional NextCursor used for
+// pagination.
+type Jobs struct {
+	// Jobs contains the current page of jobs.
+	Jobs []Job
+	// NextCursor is the job ID to be used as the cursor for fetching the next
+	// page. It is nil when there is no next page.
+	NextCursor *int64
+}
+
 // JobStorage defines the minimal interface for enqueueing background jobs.
 // Implementations are responsible for persisting the job into the underlying
 // queue backend. The args parameter contains the job payload and opts can be
@@ -27,4 +62,16 @@ type JobStorage interface {
 	// AddJob enqueues a new job with the given arguments. It should be atomic
 	// with respect to any surrounding transaction when supported by the backend.
 	AddJob(ctx context.Context, args river.JobArgs, opts *river.InsertOpts) (bool, error)
+	// ListJobs returns a page of jobs with IDs lower than the optional cursor,
+	// newest first, limited by the given limit. If state is non-empty, results
+	// are filtered to jobs in the given state.
+	ListJobs(ctx context.Context, state rivertype.JobState, limit uint, cursor int64) (Jobs, error)
+	// ActiveJobByURL returns the newest job of the given kind whose url argument
+	// equals URL, whose owner argument equals owner, or is absent for the zero
+	// owner, and which has not finalized yet (i.e. it is pending, scheduled,
+	// available, running or waiting for a retry). Returns nil when none exists.
+	// Within a transaction, it also serializes concurrent transactions looking up
+	// the same job until the transaction ends, so that adding a job when none is
+	// active cannot be raced.
+	ActiveJobByURL(ctx context.Context, kind, URL string, owner domain.UserID) (*Job, error)
 }
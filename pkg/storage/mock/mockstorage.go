@@ -14,9 +14,11 @@ import (
 	reflect "reflect"
 	domain "scanner/pkg/domain"
 	storage "scanner/pkg/storage"
+	urlscanner "scanner/pkg/urlscanner"
 	time "time"
 
 	river "github.com/riverqueue/river"
+	rivertype "github.com/riverqueue/river/rivertype"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -44,6 +46,21 @@ func (m *MockAllStorage) EXPECT() *MockAllStorageMockRecorder {
 	return m.recorder
 }
 
+// ActiveJobByURL mocks base method.
+func (m *MockAllStorage) ActiveJobByURL(ctx context.Context, kind, URL string, owner domain.UserID) (*storage.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActiveJobByURL", ctx, kind, URL, owner)
+	ret0, _ := ret[0].(*storage.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ActiveJobByURL indicates an expected call of ActiveJobByURL.
+func (mr *MockAllStorageMockRecorder) ActiveJobByURL(ctx, kind, URL, owner any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActiveJobByURL", reflect.TypeOf((*MockAllStorage)(nil).ActiveJobByURL), ctx, kind, URL, owner)
+}
+
 // AddJob mocks base method.
 func (m *MockAllStorage) AddJob(ctx context.Context, args river.JobArgs, opts *river.InsertOpts) (bool, error) {
 	m.ctrl.T.Helper()
@@ -59,6 +76,51 @@ func (mr *MockAllStorageMockRecorder) AddJob(ctx, args, opts any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddJob", reflect.TypeOf((*MockAllStorage)(nil).AddJob), ctx, args, opts)
 }
 
+// AdminListScans mocks base method.
+func (m *MockAllStorage) AdminListScans(ctx context.Context, filter storage.AdminScanFilter) (storage.UserScans, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdminListScans", ctx, filter)
+	ret0, _ := ret[0].(storage.UserScans)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdminListScans indicates an expected call of AdminListScans.
+func (mr *MockAllStorageMockRecorder) AdminListScans(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminListScans", reflect.TypeOf((*MockAllStorage)(nil).AdminListScans), ctx, filter)
+}
+
+// AdminScanByID mocks base method.
+func (m *MockAllStorage) AdminScanByID(ctx context.Context, ID domain.ScanID) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdminScanByID", ctx, ID)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdminScanByID indicates an expected call of AdminScanByID.
+func (mr *MockAllStorageMockRecorder) AdminScanByID(ctx, ID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminScanByID", reflect.TypeOf((*MockAllStorage)(nil).AdminScanByID), ctx, ID)
+}
+
+// AuditLog mocks base method.
+func (m *MockAllStorage) AuditLog(ctx context.Context, filter storage.AuditFilter) (storage.AuditEntries, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuditLog", ctx, filter)
+	ret0, _ := ret[0].(storage.AuditEntries)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuditLog indicates an expected call of AuditLog.
+func (mr *MockAllStorageMockRecorder) AuditLog(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditLog", reflect.TypeOf((*MockAllStorage)(nil).AuditLog), ctx, filter)
+}
+
 // DeleteScan mocks base method.
 func (m *MockAllStorage) DeleteScan(ctx context.Context, userID domain.UserID, ID domain.ScanID) (*domain.Scan, error) {
 	m.ctrl.T.Helper()
@@ -75,33 +137,181 @@ func (mr *MockAllStorageMockRecorder) DeleteScan(ctx, userID, ID any) *gomock.Ca
 }
 
 // LastCompletedScanByURL mocks base method.
-func (m *MockAllStorage) LastCompletedScanByURL(ctx context.Context, URL string) (*domain.Scan, error) {
+func (m *MockAllStorage) LastCompletedScanByURL(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LastCompletedScanByURL", ctx, URL)
+	ret := m.ctrl.Call(m, "LastCompletedScanByURL", ctx, userID, URL)
 	ret0, _ := ret[0].(*domain.Scan)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // LastCompletedScanByURL indicates an expected call of LastCompletedScanByURL.
-func (mr *MockAllStorageMockRecorder) LastCompletedScanByURL(ctx, URL any) *gomock.Call {
+func (mr *MockAllStorageMockRecorder) LastCompletedScanByURL(ctx, userID, URL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastCompletedScanByURL", reflect.TypeOf((*MockAllStorage)(nil).LastCompletedScanByURL), ctx, userID, URL)
+}
+
+// LastCompletedScanByURLForUser mocks base method.
+func (m *MockAllStorage) LastCompletedScanByURLForUser(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastCompletedScanByURLForUser", ctx, userID, URL)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LastCompletedScanByURLForUser indicates an expected call of LastCompletedScanByURLForUser.
+func (mr *MockAllStorageMockRecorder) LastCompletedScanByURLForUser(ctx, userID, URL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastCompletedScanByURLForUser", reflect.TypeOf((*MockAllStorage)(nil).LastCompletedScanByURLForUser), ctx, userID, URL)
+}
+
+// LastScanByURL mocks base method.
+func (m *MockAllStorage) LastScanByURL(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastScanByURL", ctx, userID, URL)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LastScanByURL indicates an expected call of LastScanByURL.
+func (mr *MockAllStorageMockRecorder) LastScanByURL(ctx, userID, URL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastScanByURL", reflect.TypeOf((*MockAllStorage)(nil).LastScanByURL), ctx, userID, URL)
+}
+
+// LastScanByURLForUser mocks base method.
+func (m *MockAllStorage) LastScanByURLForUser(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastScanByURLForUser", ctx, userID, URL)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LastScanByURLForUser indicates an expected call of LastScanByURLForUser.
+func (mr *MockAllStorageMockRecorder) LastScanByURLForUser(ctx, userID, URL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastScanByURLForUser", reflect.TypeOf((*MockAllStorage)(nil).LastScanByURLForUser), ctx, userID, URL)
+}
+
+// LatestScanByURLForUser mocks base method.
+func (m *MockAllStorage) LatestScanByURLForUser(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LatestScanByURLForUser", ctx, userID, URL)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LatestScanByURLForUser indicates an expected call of LatestScanByURLForUser.
+func (mr *MockAllStorageMockRecorder) LatestScanByURLForUser(ctx, userID, URL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LatestScanByURLForUser", reflect.TypeOf((*MockAllStorage)(nil).LatestScanByURLForUser), ctx, userID, URL)
+}
+
+// ListJobs mocks base method.
+func (m *MockAllStorage) ListJobs(ctx context.Context, state rivertype.JobState, limit uint, cursor int64) (storage.Jobs, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJobs", ctx, state, limit, cursor)
+	ret0, _ := ret[0].(storage.Jobs)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobs indicates an expected call of ListJobs.
+func (mr *MockAllStorageMockRecorder) ListJobs(ctx, state, limit, cursor any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastCompletedScanByURL", reflect.TypeOf((*MockAllStorage)(nil).LastCompletedScanByURL), ctx, URL)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobs", reflect.TypeOf((*MockAllStorage)(nil).ListJobs), ctx, state, limit, cursor)
 }
 
 // PendingScanCountByURL mocks base method.
-func (m *MockAllStorage) PendingScanCountByURL(ctx context.Context, URL string) (int64, error) {
+func (m *MockAllStorage) PendingScanCountByURL(ctx context.Context, URL string, owner domain.UserID) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PendingScanCountByURL", ctx, URL)
+	ret := m.ctrl.Call(m, "PendingScanCountByURL", ctx, URL, owner)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // PendingScanCountByURL indicates an expected call of PendingScanCountByURL.
-func (mr *MockAllStorageMockRecorder) PendingScanCountByURL(ctx, URL any) *gomock.Call {
+func (mr *MockAllStorageMockRecorder) PendingScanCountByURL(ctx, URL, owner any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingScanCountByURL", reflect.TypeOf((*MockAllStorage)(nil).PendingScanCountByURL), ctx, URL, owner)
+}
+
+// PendingScanCountByUser mocks base method.
+func (m *MockAllStorage) PendingScanCountByUser(ctx context.Context, userID domain.UserID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PendingScanCountByUser", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PendingScanCountByUser indicates an expected call of PendingScanCountByUser.
+func (mr *MockAllStorageMockRecorder) PendingScanCountByUser(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingScanCountByUser", reflect.TypeOf((*MockAllStorage)(nil).PendingScanCountByUser), ctx, userID)
+}
+
+// RateLimitStatus mocks base method.
+func (m *MockAllStorage) RateLimitStatus(ctx context.Context, key string) (*urlscanner.RateLimitStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RateLimitStatus", ctx, key)
+	ret0, _ := ret[0].(*urlscanner.RateLimitStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RateLimitStatus indicates an expected call of RateLimitStatus.
+func (mr *MockAllStorageMockRecorder) RateLimitStatus(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RateLimitStatus", reflect.TypeOf((*MockAllStorage)(nil).RateLimitStatus), ctx, key)
+}
+
+// RecordAudit mocks base method.
+func (m *MockAllStorage) RecordAudit(ctx context.Context, userID domain.UserID, action storage.AuditAction, scanID domain.ScanID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAudit", ctx, userID, action, scanID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordAudit indicates an expected call of RecordAudit.
+func (mr *MockAllStorageMockRecorder) RecordAudit(ctx, userID, action, scanID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingScanCountByURL", reflect.TypeOf((*MockAllStorage)(nil).PendingScanCountByURL), ctx, URL)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAudit", reflect.TypeOf((*MockAllStorage)(nil).RecordAudit), ctx, userID, action, scanID)
+}
+
+// RecordScanAttempts mocks base method.
+func (m *MockAllStorage) RecordScanAttempts(ctx context.Context, URL string, owner domain.UserID, attempt storage.ScanAttempt) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordScanAttempts", ctx, URL, owner, attempt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordScanAttempts indicates an expected call of RecordScanAttempts.
+func (mr *MockAllStorageMockRecorder) RecordScanAttempts(ctx, URL, owner, attempt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordScanAttempts", reflect.TypeOf((*MockAllStorage)(nil).RecordScanAttempts), ctx, URL, owner, attempt)
+}
+
+// ScanAttemptsByID mocks base method.
+func (m *MockAllStorage) ScanAttemptsByID(ctx context.Context, ID domain.ScanID) ([]storage.ScanAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanAttemptsByID", ctx, ID)
+	ret0, _ := ret[0].([]storage.ScanAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScanAttemptsByID indicates an expected call of ScanAttemptsByID.
+func (mr *MockAllStorageMockRecorder) ScanAttemptsByID(ctx, ID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanAttemptsByID", reflect.TypeOf((*MockAllStorage)(nil).ScanAttemptsByID), ctx, ID)
 }
 
 // ScanByID mocks base method.
@@ -119,6 +329,110 @@ func (mr *MockAllStorageMockRecorder) ScanByID(ctx, userID, ID any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanByID", reflect.TypeOf((*MockAllStorage)(nil).ScanByID), ctx, userID, ID)
 }
 
+// ScanByIdempotencyKey mocks base method.
+func (m *MockAllStorage) ScanByIdempotencyKey(ctx context.Context, userID domain.UserID, key string) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanByIdempotencyKey", ctx, userID, key)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScanByIdempotencyKey indicates an expected call of ScanByIdempotencyKey.
+func (mr *MockAllStorageMockRecorder) ScanByIdempotencyKey(ctx, userID, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanByIdempotencyKey", reflect.TypeOf((*MockAllStorage)(nil).ScanByIdempotencyKey), ctx, userID, key)
+}
+
+// ScanExists mocks base method.
+func (m *MockAllStorage) ScanExists(ctx context.Context, ID domain.ScanID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanExists", ctx, ID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScanExists indicates an expected call of ScanExists.
+func (mr *MockAllStorageMockRecorder) ScanExists(ctx, ID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanExists", reflect.TypeOf((*MockAllStorage)(nil).ScanExists), ctx, ID)
+}
+
+// ScanStatusCounts mocks base method.
+func (m *MockAllStorage) ScanStatusCounts(ctx context.Context, userID domain.UserID) (map[domain.ScanStatus]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanStatusCounts", ctx, userID)
+	ret0, _ := ret[0].(map[domain.ScanStatus]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScanStatusCounts indicates an expected call of ScanStatusCounts.
+func (mr *MockAllStorageMockRecorder) ScanStatusCounts(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanStatusCounts", reflect.TypeOf((*MockAllStorage)(nil).ScanStatusCounts), ctx, userID)
+}
+
+// ScansByIDs mocks base method.
+func (m *MockAllStorage) ScansByIDs(ctx context.Context, userID domain.UserID, IDs []domain.ScanID) ([]domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScansByIDs", ctx, userID, IDs)
+	ret0, _ := ret[0].([]domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScansByIDs indicates an expected call of ScansByIDs.
+func (mr *MockAllStorageMockRecorder) ScansByIDs(ctx, userID, IDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScansByIDs", reflect.TypeOf((*MockAllStorage)(nil).ScansByIDs), ctx, userID, IDs)
+}
+
+// SearchScans mocks base method.
+func (m *MockAllStorage) SearchScans(ctx context.Context, userID domain.UserID, key domain.ScanSearchKey, value string, cursor time.Time, limit uint) (storage.UserScans, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchScans", ctx, userID, key, value, cursor, limit)
+	ret0, _ := ret[0].(storage.UserScans)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchScans indicates an expected call of SearchScans.
+func (mr *MockAllStorageMockRecorder) SearchScans(ctx, userID, key, value, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchScans", reflect.TypeOf((*MockAllStorage)(nil).SearchScans), ctx, userID, key, value, cursor, limit)
+}
+
+// StalePendingScans mocks base method.
+func (m *MockAllStorage) StalePendingScans(ctx context.Context, olderThan time.Time, after *domain.Scan, limit uint) ([]domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StalePendingScans", ctx, olderThan, after, limit)
+	ret0, _ := ret[0].([]domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StalePendingScans indicates an expected call of StalePendingScans.
+func (mr *MockAllStorageMockRecorder) StalePendingScans(ctx, olderThan, after, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StalePendingScans", reflect.TypeOf((*MockAllStorage)(nil).StalePendingScans), ctx, olderThan, after, limit)
+}
+
+// StoreRateLimitStatus mocks base method.
+func (m *MockAllStorage) StoreRateLimitStatus(ctx context.Context, key string, status urlscanner.RateLimitStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreRateLimitStatus", ctx, key, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreRateLimitStatus indicates an expected call of StoreRateLimitStatus.
+func (mr *MockAllStorageMockRecorder) StoreRateLimitStatus(ctx, key, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreRateLimitStatus", reflect.TypeOf((*MockAllStorage)(nil).StoreRateLimitStatus), ctx, key, status)
+}
+
 // StoreScans mocks base method.
 func (m *MockAllStorage) StoreScans(ctx context.Context, scans ...domain.Scan) ([]domain.Scan, error) {
 	m.ctrl.T.Helper()
@@ -140,17 +454,18 @@ func (mr *MockAllStorageMockRecorder) StoreScans(ctx any, scans ...any) *gomock.
 }
 
 // UpdatePendingScansByURL mocks base method.
-func (m *MockAllStorage) UpdatePendingScansByURL(ctx context.Context, URL string, updates storage.ScanUpdates) error {
+func (m *MockAllStorage) UpdatePendingScansByURL(ctx context.Context, URL string, owner domain.UserID, updates storage.ScanUpdates) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdatePendingScansByURL", ctx, URL, updates)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "UpdatePendingScansByURL", ctx, URL, owner, updates)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // UpdatePendingScansByURL indicates an expected call of UpdatePendingScansByURL.
-func (mr *MockAllStorageMockRecorder) UpdatePendingScansByURL(ctx, URL, updates any) *gomock.Call {
+func (mr *MockAllStorageMockRecorder) UpdatePendingScansByURL(ctx, URL, owner, updates any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePendingScansByURL", reflect.TypeOf((*MockAllStorage)(nil).UpdatePendingScansByURL), ctx, URL, updates)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePendingScansByURL", reflect.TypeOf((*MockAllStorage)(nil).UpdatePendingScansByURL), ctx, URL, owner, updates)
 }
 
 // UpdateScanByID mocks base method.
@@ -168,19 +483,50 @@ func (mr *MockAllStorageMockRecorder) UpdateScanByID(ctx, ID, updates any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScanByID", reflect.TypeOf((*MockAllStorage)(nil).UpdateScanByID), ctx, ID, updates)
 }
 
+// UpdateScanByIDForUser mocks base method.
+func (m *MockAllStorage) UpdateScanByIDForUser(ctx context.Context, userID domain.UserID, ID domain.ScanID, updates storage.ScanUpdates) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateScanByIDForUser", ctx, userID, ID, updates)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateScanByIDForUser indicates an expected call of UpdateScanByIDForUser.
+func (mr *MockAllStorageMockRecorder) UpdateScanByIDForUser(ctx, userID, ID, updates any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScanByIDForUser", reflect.TypeOf((*MockAllStorage)(nil).UpdateScanByIDForUser), ctx, userID, ID, updates)
+}
+
+// UpsertScan mocks base method.
+func (m *MockAllStorage) UpsertScan(ctx context.Context, scan domain.Scan) (*domain.Scan, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertScan", ctx, scan)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// UpsertScan indicates an expected call of UpsertScan.
+func (mr *MockAllStorageMockRecorder) UpsertScan(ctx, scan any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertScan", reflect.TypeOf((*MockAllStorage)(nil).UpsertScan), ctx, scan)
+}
+
 // UserScans mocks base method.
-func (m *MockAllStorage) UserScans(ctx context.Context, userID domain.UserID, status domain.ScanStatus, cursor time.Time, limit uint) (storage.UserScans, error) {
+func (m *MockAllStorage) UserScans(ctx context.Context, userID domain.UserID, status domain.ScanStatus, maliciousOnly bool, cursor time.Time, direction storage.PageDirection, limit uint) (storage.UserScans, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UserScans", ctx, userID, status, cursor, limit)
+	ret := m.ctrl.Call(m, "UserScans", ctx, userID, status, maliciousOnly, cursor, direction, limit)
 	ret0, _ := ret[0].(storage.UserScans)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UserScans indicates an expected call of UserScans.
-func (mr *MockAllStorageMockRecorder) UserScans(ctx, userID, status, cursor, limit any) *gomock.Call {
+func (mr *MockAllStorageMockRecorder) UserScans(ctx, userID, status, maliciousOnly, cursor, direction, limit any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserScans", reflect.TypeOf((*MockAllStorage)(nil).UserScans), ctx, userID, status, cursor, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserScans", reflect.TypeOf((*MockAllStorage)(nil).UserScans), ctx, userID, status, maliciousOnly, cursor, direction, limit)
 }
 
 // MockTxStorage is a mock of TxStorage interface.
@@ -207,6 +553,21 @@ func (m *MockTxStorage) EXPECT() *MockTxStorageMockRecorder {
 	return m.recorder
 }
 
+// ActiveJobByURL mocks base method.
+func (m *MockTxStorage) ActiveJobByURL(ctx context.Context, kind, URL string, owner domain.UserID) (*storage.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActiveJobByURL", ctx, kind, URL, owner)
+	ret0, _ := ret[0].(*storage.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ActiveJobByURL indicates an expected call of ActiveJobByURL.
+func (mr *MockTxStorageMockRecorder) ActiveJobByURL(ctx, kind, URL, owner any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActiveJobByURL", reflect.TypeOf((*MockTxStorage)(nil).ActiveJobByURL), ctx, kind, URL, owner)
+}
+
 // AddJob mocks base method.
 func (m *MockTxStorage) AddJob(ctx context.Context, args river.JobArgs, opts *river.InsertOpts) (bool, error) {
 	m.ctrl.T.Helper()
@@ -222,6 +583,51 @@ func (mr *MockTxStorageMockRecorder) AddJob(ctx, args, opts any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddJob", reflect.TypeOf((*MockTxStorage)(nil).AddJob), ctx, args, opts)
 }
 
+// AdminListScans mocks base method.
+func (m *MockTxStorage) AdminListScans(ctx context.Context, filter storage.AdminScanFilter) (storage.UserScans, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdminListScans", ctx, filter)
+	ret0, _ := ret[0].(storage.UserScans)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdminListScans indicates an expected call of AdminListScans.
+func (mr *MockTxStorageMockRecorder) AdminListScans(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminListScans", reflect.TypeOf((*MockTxStorage)(nil).AdminListScans), ctx, filter)
+}
+
+// AdminScanByID mocks base method.
+func (m *MockTxStorage) AdminScanByID(ctx context.Context, ID domain.ScanID) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdminScanByID", ctx, ID)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdminScanByID indicates an expected call of AdminScanByID.
+func (mr *MockTxStorageMockRecorder) AdminScanByID(ctx, ID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminScanByID", reflect.TypeOf((*MockTxStorage)(nil).AdminScanByID), ctx, ID)
+}
+
+// AuditLog mocks base method.
+func (m *MockTxStorage) AuditLog(ctx context.Context, filter storage.AuditFilter) (storage.AuditEntries, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuditLog", ctx, filter)
+	ret0, _ := ret[0].(storage.AuditEntries)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuditLog indicates an expected call of AuditLog.
+func (mr *MockTxStorageMockRecorder) AuditLog(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditLog", reflect.TypeOf((*MockTxStorage)(nil).AuditLog), ctx, filter)
+}
+
 // Commit mocks base method.
 func (m *MockTxStorage) Commit() error {
 	m.ctrl.T.Helper()
@@ -245,69 +651,321 @@ func (m *MockTxStorage) DeleteScan(ctx context.Context, userID domain.UserID, ID
 	return ret0, ret1
 }
 
-// DeleteScan indicates an expected call of DeleteScan.
-func (mr *MockTxStorageMockRecorder) DeleteScan(ctx, userID, ID any) *gomock.Call {
+// DeleteScan indicates an expected call of DeleteScan.
+func (mr *MockTxStorageMockRecorder) DeleteScan(ctx, userID, ID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteScan", reflect.TypeOf((*MockTxStorage)(nil).DeleteScan), ctx, userID, ID)
+}
+
+// LastCompletedScanByURL mocks base method.
+func (m *MockTxStorage) LastCompletedScanByURL(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastCompletedScanByURL", ctx, userID, URL)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LastCompletedScanByURL indicates an expected call of LastCompletedScanByURL.
+func (mr *MockTxStorageMockRecorder) LastCompletedScanByURL(ctx, userID, URL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastCompletedScanByURL", reflect.TypeOf((*MockTxStorage)(nil).LastCompletedScanByURL), ctx, userID, URL)
+}
+
+// LastCompletedScanByURLForUser mocks base method.
+func (m *MockTxStorage) LastCompletedScanByURLForUser(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastCompletedScanByURLForUser", ctx, userID, URL)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LastCompletedScanByURLForUser indicates an expected call of LastCompletedScanByURLForUser.
+func (mr *MockTxStorageMockRecorder) LastCompletedScanByURLForUser(ctx, userID, URL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastCompletedScanByURLForUser", reflect.TypeOf((*MockTxStorage)(nil).LastCompletedScanByURLForUser), ctx, userID, URL)
+}
+
+// LastScanByURL mocks base method.
+func (m *MockTxStorage) LastScanByURL(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastScanByURL", ctx, userID, URL)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LastScanByURL indicates an expected call of LastScanByURL.
+func (mr *MockTxStorageMockRecorder) LastScanByURL(ctx, userID, URL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastScanByURL", reflect.TypeOf((*MockTxStorage)(nil).LastScanByURL), ctx, userID, URL)
+}
+
+// LastScanByURLForUser mocks base method.
+func (m *MockTxStorage) LastScanByURLForUser(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastScanByURLForUser", ctx, userID, URL)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LastScanByURLForUser indicates an expected call of LastScanByURLForUser.
+func (mr *MockTxStorageMockRecorder) LastScanByURLForUser(ctx, userID, URL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastScanByURLForUser", reflect.TypeOf((*MockTxStorage)(nil).LastScanByURLForUser), ctx, userID, URL)
+}
+
+// LatestScanByURLForUser mocks base method.
+func (m *MockTxStorage) LatestScanByURLForUser(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LatestScanByURLForUser", ctx, userID, URL)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LatestScanByURLForUser indicates an expected call of LatestScanByURLForUser.
+func (mr *MockTxStorageMockRecorder) LatestScanByURLForUser(ctx, userID, URL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LatestScanByURLForUser", reflect.TypeOf((*MockTxStorage)(nil).LatestScanByURLForUser), ctx, userID, URL)
+}
+
+// ListJobs mocks base method.
+func (m *MockTxStorage) ListJobs(ctx context.Context, state rivertype.JobState, limit uint, cursor int64) (storage.Jobs, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJobs", ctx, state, limit, cursor)
+	ret0, _ := ret[0].(storage.Jobs)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobs indicates an expected call of ListJobs.
+func (mr *MockTxStorageMockRecorder) ListJobs(ctx, state, limit, cursor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobs", reflect.TypeOf((*MockTxStorage)(nil).ListJobs), ctx, state, limit, cursor)
+}
+
+// PendingScanCountByURL mocks base method.
+func (m *MockTxStorage) PendingScanCountByURL(ctx context.Context, URL string, owner domain.UserID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PendingScanCountByURL", ctx, URL, owner)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PendingScanCountByURL indicates an expected call of PendingScanCountByURL.
+func (mr *MockTxStorageMockRecorder) PendingScanCountByURL(ctx, URL, owner any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingScanCountByURL", reflect.TypeOf((*MockTxStorage)(nil).PendingScanCountByURL), ctx, URL, owner)
+}
+
+// PendingScanCountByUser mocks base method.
+func (m *MockTxStorage) PendingScanCountByUser(ctx context.Context, userID domain.UserID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PendingScanCountByUser", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PendingScanCountByUser indicates an expected call of PendingScanCountByUser.
+func (mr *MockTxStorageMockRecorder) PendingScanCountByUser(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingScanCountByUser", reflect.TypeOf((*MockTxStorage)(nil).PendingScanCountByUser), ctx, userID)
+}
+
+// RateLimitStatus mocks base method.
+func (m *MockTxStorage) RateLimitStatus(ctx context.Context, key string) (*urlscanner.RateLimitStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RateLimitStatus", ctx, key)
+	ret0, _ := ret[0].(*urlscanner.RateLimitStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RateLimitStatus indicates an expected call of RateLimitStatus.
+func (mr *MockTxStorageMockRecorder) RateLimitStatus(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RateLimitStatus", reflect.TypeOf((*MockTxStorage)(nil).RateLimitStatus), ctx, key)
+}
+
+// RecordAudit mocks base method.
+func (m *MockTxStorage) RecordAudit(ctx context.Context, userID domain.UserID, action storage.AuditAction, scanID domain.ScanID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAudit", ctx, userID, action, scanID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordAudit indicates an expected call of RecordAudit.
+func (mr *MockTxStorageMockRecorder) RecordAudit(ctx, userID, action, scanID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAudit", reflect.TypeOf((*MockTxStorage)(nil).RecordAudit), ctx, userID, action, scanID)
+}
+
+// RecordScanAttempts mocks base method.
+func (m *MockTxStorage) RecordScanAttempts(ctx context.Context, URL string, owner domain.UserID, attempt storage.ScanAttempt) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordScanAttempts", ctx, URL, owner, attempt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordScanAttempts indicates an expected call of RecordScanAttempts.
+func (mr *MockTxStorageMockRecorder) RecordScanAttempts(ctx, URL, owner, attempt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordScanAttempts", reflect.TypeOf((*MockTxStorage)(nil).RecordScanAttempts), ctx, URL, owner, attempt)
+}
+
+// Rollback mocks base method.
+func (m *MockTxStorage) Rollback() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rollback")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Rollback indicates an expected call of Rollback.
+func (mr *MockTxStorageMockRecorder) Rollback() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockTxStorage)(nil).Rollback))
+}
+
+// ScanAttemptsByID mocks base method.
+func (m *MockTxStorage) ScanAttemptsByID(ctx context.Context, ID domain.ScanID) ([]storage.ScanAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanAttemptsByID", ctx, ID)
+	ret0, _ := ret[0].([]storage.ScanAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScanAttemptsByID indicates an expected call of ScanAttemptsByID.
+func (mr *MockTxStorageMockRecorder) ScanAttemptsByID(ctx, ID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanAttemptsByID", reflect.TypeOf((*MockTxStorage)(nil).ScanAttemptsByID), ctx, ID)
+}
+
+// ScanByID mocks base method.
+func (m *MockTxStorage) ScanByID(ctx context.Context, userID domain.UserID, ID domain.ScanID) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanByID", ctx, userID, ID)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScanByID indicates an expected call of ScanByID.
+func (mr *MockTxStorageMockRecorder) ScanByID(ctx, userID, ID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanByID", reflect.TypeOf((*MockTxStorage)(nil).ScanByID), ctx, userID, ID)
+}
+
+// ScanByIdempotencyKey mocks base method.
+func (m *MockTxStorage) ScanByIdempotencyKey(ctx context.Context, userID domain.UserID, key string) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanByIdempotencyKey", ctx, userID, key)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScanByIdempotencyKey indicates an expected call of ScanByIdempotencyKey.
+func (mr *MockTxStorageMockRecorder) ScanByIdempotencyKey(ctx, userID, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanByIdempotencyKey", reflect.TypeOf((*MockTxStorage)(nil).ScanByIdempotencyKey), ctx, userID, key)
+}
+
+// ScanExists mocks base method.
+func (m *MockTxStorage) ScanExists(ctx context.Context, ID domain.ScanID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanExists", ctx, ID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScanExists indicates an expected call of ScanExists.
+func (mr *MockTxStorageMockRecorder) ScanExists(ctx, ID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanExists", reflect.TypeOf((*MockTxStorage)(nil).ScanExists), ctx, ID)
+}
+
+// ScanStatusCounts mocks base method.
+func (m *MockTxStorage) ScanStatusCounts(ctx context.Context, userID domain.UserID) (map[domain.ScanStatus]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanStatusCounts", ctx, userID)
+	ret0, _ := ret[0].(map[domain.ScanStatus]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScanStatusCounts indicates an expected call of ScanStatusCounts.
+func (mr *MockTxStorageMockRecorder) ScanStatusCounts(ctx, userID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteScan", reflect.TypeOf((*MockTxStorage)(nil).DeleteScan), ctx, userID, ID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanStatusCounts", reflect.TypeOf((*MockTxStorage)(nil).ScanStatusCounts), ctx, userID)
 }
 
-// LastCompletedScanByURL mocks base method.
-func (m *MockTxStorage) LastCompletedScanByURL(ctx context.Context, URL string) (*domain.Scan, error) {
+// ScansByIDs mocks base method.
+func (m *MockTxStorage) ScansByIDs(ctx context.Context, userID domain.UserID, IDs []domain.ScanID) ([]domain.Scan, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LastCompletedScanByURL", ctx, URL)
-	ret0, _ := ret[0].(*domain.Scan)
+	ret := m.ctrl.Call(m, "ScansByIDs", ctx, userID, IDs)
+	ret0, _ := ret[0].([]domain.Scan)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// LastCompletedScanByURL indicates an expected call of LastCompletedScanByURL.
-func (mr *MockTxStorageMockRecorder) LastCompletedScanByURL(ctx, URL any) *gomock.Call {
+// ScansByIDs indicates an expected call of ScansByIDs.
+func (mr *MockTxStorageMockRecorder) ScansByIDs(ctx, userID, IDs any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastCompletedScanByURL", reflect.TypeOf((*MockTxStorage)(nil).LastCompletedScanByURL), ctx, URL)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScansByIDs", reflect.TypeOf((*MockTxStorage)(nil).ScansByIDs), ctx, userID, IDs)
 }
 
-// PendingScanCountByURL mocks base method.
-func (m *MockTxStorage) PendingScanCountByURL(ctx context.Context, URL string) (int64, error) {
+// SearchScans mocks base method.
+func (m *MockTxStorage) SearchScans(ctx context.Context, userID domain.UserID, key domain.ScanSearchKey, value string, cursor time.Time, limit uint) (storage.UserScans, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PendingScanCountByURL", ctx, URL)
-	ret0, _ := ret[0].(int64)
+	ret := m.ctrl.Call(m, "SearchScans", ctx, userID, key, value, cursor, limit)
+	ret0, _ := ret[0].(storage.UserScans)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// PendingScanCountByURL indicates an expected call of PendingScanCountByURL.
-func (mr *MockTxStorageMockRecorder) PendingScanCountByURL(ctx, URL any) *gomock.Call {
+// SearchScans indicates an expected call of SearchScans.
+func (mr *MockTxStorageMockRecorder) SearchScans(ctx, userID, key, value, cursor, limit any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingScanCountByURL", reflect.TypeOf((*MockTxStorage)(nil).PendingScanCountByURL), ctx, URL)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchScans", reflect.TypeOf((*MockTxStorage)(nil).SearchScans), ctx, userID, key, value, cursor, limit)
 }
 
-// Rollback mocks base method.
-func (m *MockTxStorage) Rollback() error {
+// StalePendingScans mocks base method.
+func (m *MockTxStorage) StalePendingScans(ctx context.Context, olderThan time.Time, after *domain.Scan, limit uint) ([]domain.Scan, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Rollback")
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "StalePendingScans", ctx, olderThan, after, limit)
+	ret0, _ := ret[0].([]domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// Rollback indicates an expected call of Rollback.
-func (mr *MockTxStorageMockRecorder) Rollback() *gomock.Call {
+// StalePendingScans indicates an expected call of StalePendingScans.
+func (mr *MockTxStorageMockRecorder) StalePendingScans(ctx, olderThan, after, limit any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockTxStorage)(nil).Rollback))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StalePendingScans", reflect.TypeOf((*MockTxStorage)(nil).StalePendingScans), ctx, olderThan, after, limit)
 }
 
-// ScanByID mocks base method.
-func (m *MockTxStorage) ScanByID(ctx context.Context, userID domain.UserID, ID domain.ScanID) (*domain.Scan, error) {
+// StoreRateLimitStatus mocks base method.
+func (m *MockTxStorage) StoreRateLimitStatus(ctx context.Context, key string, status urlscanner.RateLimitStatus) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ScanByID", ctx, userID, ID)
-	ret0, _ := ret[0].(*domain.Scan)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "StoreRateLimitStatus", ctx, key, status)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// ScanByID indicates an expected call of ScanByID.
-func (mr *MockTxStorageMockRecorder) ScanByID(ctx, userID, ID any) *gomock.Call {
+// StoreRateLimitStatus indicates an expected call of StoreRateLimitStatus.
+func (mr *MockTxStorageMockRecorder) StoreRateLimitStatus(ctx, key, status any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanByID", reflect.TypeOf((*MockTxStorage)(nil).ScanByID), ctx, userID, ID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreRateLimitStatus", reflect.TypeOf((*MockTxStorage)(nil).StoreRateLimitStatus), ctx, key, status)
 }
 
 // StoreScans mocks base method.
@@ -331,17 +989,18 @@ func (mr *MockTxStorageMockRecorder) StoreScans(ctx any, scans ...any) *gomock.C
 }
 
 // UpdatePendingScansByURL mocks base method.
-func (m *MockTxStorage) UpdatePendingScansByURL(ctx context.Context, URL string, updates storage.ScanUpdates) error {
+func (m *MockTxStorage) UpdatePendingScansByURL(ctx context.Context, URL string, owner domain.UserID, updates storage.ScanUpdates) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdatePendingScansByURL", ctx, URL, updates)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "UpdatePendingScansByURL", ctx, URL, owner, updates)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // UpdatePendingScansByURL indicates an expected call of UpdatePendingScansByURL.
-func (mr *MockTxStorageMockRecorder) UpdatePendingScansByURL(ctx, URL, updates any) *gomock.Call {
+func (mr *MockTxStorageMockRecorder) UpdatePendingScansByURL(ctx, URL, owner, updates any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePendingScansByURL", reflect.TypeOf((*MockTxStorage)(nil).UpdatePendingScansByURL), ctx, URL, updates)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePendingScansByURL", reflect.TypeOf((*MockTxStorage)(nil).UpdatePendingScansByURL), ctx, URL, owner, updates)
 }
 
 // UpdateScanByID mocks base method.
@@ -359,19 +1018,50 @@ func (mr *MockTxStorageMockRecorder) UpdateScanByID(ctx, ID, updates any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScanByID", reflect.TypeOf((*MockTxStorage)(nil).UpdateScanByID), ctx, ID, updates)
 }
 
+// UpdateScanByIDForUser mocks base method.
+func (m *MockTxStorage) UpdateScanByIDForUser(ctx context.Context, userID domain.UserID, ID domain.ScanID, updates storage.ScanUpdates) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateScanByIDForUser", ctx, userID, ID, updates)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateScanByIDForUser indicates an expected call of UpdateScanByIDForUser.
+func (mr *MockTxStorageMockRecorder) UpdateScanByIDForUser(ctx, userID, ID, updates any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScanByIDForUser", reflect.TypeOf((*MockTxStorage)(nil).UpdateScanByIDForUser), ctx, userID, ID, updates)
+}
+
+// UpsertScan mocks base method.
+func (m *MockTxStorage) UpsertScan(ctx context.Context, scan domain.Scan) (*domain.Scan, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertScan", ctx, scan)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// UpsertScan indicates an expected call of UpsertScan.
+func (mr *MockTxStorageMockRecorder) UpsertScan(ctx, scan any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertScan", reflect.TypeOf((*MockTxStorage)(nil).UpsertScan), ctx, scan)
+}
+
 // UserScans mocks base method.
-func (m *MockTxStorage) UserScans(ctx context.Context, userID domain.UserID, status domain.ScanStatus, cursor time.Time, limit uint) (storage.UserScans, error) {
+func (m *MockTxStorage) UserScans(ctx context.Context, userID domain.UserID, status domain.ScanStatus, maliciousOnly bool, cursor time.Time, direction storage.PageDirection, limit uint) (storage.UserScans, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UserScans", ctx, userID, status, cursor, limit)
+	ret := m.ctrl.Call(m, "UserScans", ctx, userID, status, maliciousOnly, cursor, direction, limit)
 	ret0, _ := ret[0].(storage.UserScans)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UserScans indicates an expected call of UserScans.
-func (mr *MockTxStorageMockRecorder) UserScans(ctx, userID, status, cursor, limit any) *gomock.Call {
+func (mr *MockTxStorageMockRecorder) UserScans(ctx, userID, status, maliciousOnly, cursor, direction, limit any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserScans", reflect.TypeOf((*MockTxStorage)(nil).UserScans), ctx, userID, status, cursor, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserScans", reflect.TypeOf((*MockTxStorage)(nil).UserScans), ctx, userID, status, maliciousOnly, cursor, direction, limit)
 }
 
 // MockStorage is a mock of Storage interface.
@@ -398,6 +1088,21 @@ func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
 	return m.recorder
 }
 
+// ActiveJobByURL mocks base method.
+func (m *MockStorage) ActiveJobByURL(ctx context.Context, kind, URL string, owner domain.UserID) (*storage.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActiveJobByURL", ctx, kind, URL, owner)
+	ret0, _ := ret[0].(*storage.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ActiveJobByURL indicates an expected call of ActiveJobByURL.
+func (mr *MockStorageMockRecorder) ActiveJobByURL(ctx, kind, URL, owner any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActiveJobByURL", reflect.TypeOf((*MockStorage)(nil).ActiveJobByURL), ctx, kind, URL, owner)
+}
+
 // AddJob mocks base method.
 func (m *MockStorage) AddJob(ctx context.Context, args river.JobArgs, opts *river.InsertOpts) (bool, error) {
 	m.ctrl.T.Helper()
@@ -413,6 +1118,51 @@ func (mr *MockStorageMockRecorder) AddJob(ctx, args, opts any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddJob", reflect.TypeOf((*MockStorage)(nil).AddJob), ctx, args, opts)
 }
 
+// AdminListScans mocks base method.
+func (m *MockStorage) AdminListScans(ctx context.Context, filter storage.AdminScanFilter) (storage.UserScans, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdminListScans", ctx, filter)
+	ret0, _ := ret[0].(storage.UserScans)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdminListScans indicates an expected call of AdminListScans.
+func (mr *MockStorageMockRecorder) AdminListScans(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminListScans", reflect.TypeOf((*MockStorage)(nil).AdminListScans), ctx, filter)
+}
+
+// AdminScanByID mocks base method.
+func (m *MockStorage) AdminScanByID(ctx context.Context, ID domain.ScanID) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdminScanByID", ctx, ID)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdminScanByID indicates an expected call of AdminScanByID.
+func (mr *MockStorageMockRecorder) AdminScanByID(ctx, ID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminScanByID", reflect.TypeOf((*MockStorage)(nil).AdminScanByID), ctx, ID)
+}
+
+// AuditLog mocks base method.
+func (m *MockStorage) AuditLog(ctx context.Context, filter storage.AuditFilter) (storage.AuditEntries, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuditLog", ctx, filter)
+	ret0, _ := ret[0].(storage.AuditEntries)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuditLog indicates an expected call of AuditLog.
+func (mr *MockStorageMockRecorder) AuditLog(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditLog", reflect.TypeOf((*MockStorage)(nil).AuditLog), ctx, filter)
+}
+
 // Begin mocks base method.
 func (m *MockStorage) Begin(ctx context.Context) (storage.TxStorage, error) {
 	m.ctrl.T.Helper()
@@ -458,33 +1208,181 @@ func (mr *MockStorageMockRecorder) DeleteScan(ctx, userID, ID any) *gomock.Call
 }
 
 // LastCompletedScanByURL mocks base method.
-func (m *MockStorage) LastCompletedScanByURL(ctx context.Context, URL string) (*domain.Scan, error) {
+func (m *MockStorage) LastCompletedScanByURL(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LastCompletedScanByURL", ctx, URL)
+	ret := m.ctrl.Call(m, "LastCompletedScanByURL", ctx, userID, URL)
 	ret0, _ := ret[0].(*domain.Scan)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // LastCompletedScanByURL indicates an expected call of LastCompletedScanByURL.
-func (mr *MockStorageMockRecorder) LastCompletedScanByURL(ctx, URL any) *gomock.Call {
+func (mr *MockStorageMockRecorder) LastCompletedScanByURL(ctx, userID, URL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastCompletedScanByURL", reflect.TypeOf((*MockStorage)(nil).LastCompletedScanByURL), ctx, userID, URL)
+}
+
+// LastCompletedScanByURLForUser mocks base method.
+func (m *MockStorage) LastCompletedScanByURLForUser(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastCompletedScanByURLForUser", ctx, userID, URL)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LastCompletedScanByURLForUser indicates an expected call of LastCompletedScanByURLForUser.
+func (mr *MockStorageMockRecorder) LastCompletedScanByURLForUser(ctx, userID, URL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastCompletedScanByURLForUser", reflect.TypeOf((*MockStorage)(nil).LastCompletedScanByURLForUser), ctx, userID, URL)
+}
+
+// LastScanByURL mocks base method.
+func (m *MockStorage) LastScanByURL(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastScanByURL", ctx, userID, URL)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LastScanByURL indicates an expected call of LastScanByURL.
+func (mr *MockStorageMockRecorder) LastScanByURL(ctx, userID, URL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastScanByURL", reflect.TypeOf((*MockStorage)(nil).LastScanByURL), ctx, userID, URL)
+}
+
+// LastScanByURLForUser mocks base method.
+func (m *MockStorage) LastScanByURLForUser(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastScanByURLForUser", ctx, userID, URL)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LastScanByURLForUser indicates an expected call of LastScanByURLForUser.
+func (mr *MockStorageMockRecorder) LastScanByURLForUser(ctx, userID, URL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastScanByURLForUser", reflect.TypeOf((*MockStorage)(nil).LastScanByURLForUser), ctx, userID, URL)
+}
+
+// LatestScanByURLForUser mocks base method.
+func (m *MockStorage) LatestScanByURLForUser(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LatestScanByURLForUser", ctx, userID, URL)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LatestScanByURLForUser indicates an expected call of LatestScanByURLForUser.
+func (mr *MockStorageMockRecorder) LatestScanByURLForUser(ctx, userID, URL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LatestScanByURLForUser", reflect.TypeOf((*MockStorage)(nil).LatestScanByURLForUser), ctx, userID, URL)
+}
+
+// ListJobs mocks base method.
+func (m *MockStorage) ListJobs(ctx context.Context, state rivertype.JobState, limit uint, cursor int64) (storage.Jobs, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJobs", ctx, state, limit, cursor)
+	ret0, _ := ret[0].(storage.Jobs)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobs indicates an expected call of ListJobs.
+func (mr *MockStorageMockRecorder) ListJobs(ctx, state, limit, cursor any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastCompletedScanByURL", reflect.TypeOf((*MockStorage)(nil).LastCompletedScanByURL), ctx, URL)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobs", reflect.TypeOf((*MockStorage)(nil).ListJobs), ctx, state, limit, cursor)
 }
 
 // PendingScanCountByURL mocks base method.
-func (m *MockStorage) PendingScanCountByURL(ctx context.Context, URL string) (int64, error) {
+func (m *MockStorage) PendingScanCountByURL(ctx context.Context, URL string, owner domain.UserID) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PendingScanCountByURL", ctx, URL)
+	ret := m.ctrl.Call(m, "PendingScanCountByURL", ctx, URL, owner)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // PendingScanCountByURL indicates an expected call of PendingScanCountByURL.
-func (mr *MockStorageMockRecorder) PendingScanCountByURL(ctx, URL any) *gomock.Call {
+func (mr *MockStorageMockRecorder) PendingScanCountByURL(ctx, URL, owner any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingScanCountByURL", reflect.TypeOf((*MockStorage)(nil).PendingScanCountByURL), ctx, URL, owner)
+}
+
+// PendingScanCountByUser mocks base method.
+func (m *MockStorage) PendingScanCountByUser(ctx context.Context, userID domain.UserID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PendingScanCountByUser", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PendingScanCountByUser indicates an expected call of PendingScanCountByUser.
+func (mr *MockStorageMockRecorder) PendingScanCountByUser(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingScanCountByUser", reflect.TypeOf((*MockStorage)(nil).PendingScanCountByUser), ctx, userID)
+}
+
+// RateLimitStatus mocks base method.
+func (m *MockStorage) RateLimitStatus(ctx context.Context, key string) (*urlscanner.RateLimitStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RateLimitStatus", ctx, key)
+	ret0, _ := ret[0].(*urlscanner.RateLimitStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RateLimitStatus indicates an expected call of RateLimitStatus.
+func (mr *MockStorageMockRecorder) RateLimitStatus(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RateLimitStatus", reflect.TypeOf((*MockStorage)(nil).RateLimitStatus), ctx, key)
+}
+
+// RecordAudit mocks base method.
+func (m *MockStorage) RecordAudit(ctx context.Context, userID domain.UserID, action storage.AuditAction, scanID domain.ScanID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAudit", ctx, userID, action, scanID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordAudit indicates an expected call of RecordAudit.
+func (mr *MockStorageMockRecorder) RecordAudit(ctx, userID, action, scanID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAudit", reflect.TypeOf((*MockStorage)(nil).RecordAudit), ctx, userID, action, scanID)
+}
+
+// RecordScanAttempts mocks base method.
+func (m *MockStorage) RecordScanAttempts(ctx context.Context, URL string, owner domain.UserID, attempt storage.ScanAttempt) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordScanAttempts", ctx, URL, owner, attempt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordScanAttempts indicates an expected call of RecordScanAttempts.
+func (mr *MockStorageMockRecorder) RecordScanAttempts(ctx, URL, owner, attempt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordScanAttempts", reflect.TypeOf((*MockStorage)(nil).RecordScanAttempts), ctx, URL, owner, attempt)
+}
+
+// ScanAttemptsByID mocks base method.
+func (m *MockStorage) ScanAttemptsByID(ctx context.Context, ID domain.ScanID) ([]storage.ScanAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanAttemptsByID", ctx, ID)
+	ret0, _ := ret[0].([]storage.ScanAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScanAttemptsByID indicates an expected call of ScanAttemptsByID.
+func (mr *MockStorageMockRecorder) ScanAttemptsByID(ctx, ID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingScanCountByURL", reflect.TypeOf((*MockStorage)(nil).PendingScanCountByURL), ctx, URL)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanAttemptsByID", reflect.TypeOf((*MockStorage)(nil).ScanAttemptsByID), ctx, ID)
 }
 
 // ScanByID mocks base method.
@@ -502,6 +1400,110 @@ func (mr *MockStorageMockRecorder) ScanByID(ctx, userID, ID any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanByID", reflect.TypeOf((*MockStorage)(nil).ScanByID), ctx, userID, ID)
 }
 
+// ScanByIdempotencyKey mocks base method.
+func (m *MockStorage) ScanByIdempotencyKey(ctx context.Context, userID domain.UserID, key string) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanByIdempotencyKey", ctx, userID, key)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScanByIdempotencyKey indicates an expected call of ScanByIdempotencyKey.
+func (mr *MockStorageMockRecorder) ScanByIdempotencyKey(ctx, userID, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanByIdempotencyKey", reflect.TypeOf((*MockStorage)(nil).ScanByIdempotencyKey), ctx, userID, key)
+}
+
+// ScanExists mocks base method.
+func (m *MockStorage) ScanExists(ctx context.Context, ID domain.ScanID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanExists", ctx, ID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScanExists indicates an expected call of ScanExists.
+func (mr *MockStorageMockRecorder) ScanExists(ctx, ID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanExists", reflect.TypeOf((*MockStorage)(nil).ScanExists), ctx, ID)
+}
+
+// ScanStatusCounts mocks base method.
+func (m *MockStorage) ScanStatusCounts(ctx context.Context, userID domain.UserID) (map[domain.ScanStatus]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanStatusCounts", ctx, userID)
+	ret0, _ := ret[0].(map[domain.ScanStatus]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScanStatusCounts indicates an expected call of ScanStatusCounts.
+func (mr *MockStorageMockRecorder) ScanStatusCounts(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanStatusCounts", reflect.TypeOf((*MockStorage)(nil).ScanStatusCounts), ctx, userID)
+}
+
+// ScansByIDs mocks base method.
+func (m *MockStorage) ScansByIDs(ctx context.Context, userID domain.UserID, IDs []domain.ScanID) ([]domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScansByIDs", ctx, userID, IDs)
+	ret0, _ := ret[0].([]domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScansByIDs indicates an expected call of ScansByIDs.
+func (mr *MockStorageMockRecorder) ScansByIDs(ctx, userID, IDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScansByIDs", reflect.TypeOf((*MockStorage)(nil).ScansByIDs), ctx, userID, IDs)
+}
+
+// SearchScans mocks base method.
+func (m *MockStorage) SearchScans(ctx context.Context, userID domain.UserID, key domain.ScanSearchKey, value string, cursor time.Time, limit uint) (storage.UserScans, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchScans", ctx, userID, key, value, cursor, limit)
+	ret0, _ := ret[0].(storage.UserScans)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchScans indicates an expected call of SearchScans.
+func (mr *MockStorageMockRecorder) SearchScans(ctx, userID, key, value, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchScans", reflect.TypeOf((*MockStorage)(nil).SearchScans), ctx, userID, key, value, cursor, limit)
+}
+
+// StalePendingScans mocks base method.
+func (m *MockStorage) StalePendingScans(ctx context.Context, olderThan time.Time, after *domain.Scan, limit uint) ([]domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StalePendingScans", ctx, olderThan, after, limit)
+	ret0, _ := ret[0].([]domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StalePendingScans indicates an expected call of StalePendingScans.
+func (mr *MockStorageMockRecorder) StalePendingScans(ctx, olderThan, after, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StalePendingScans", reflect.TypeOf((*MockStorage)(nil).StalePendingScans), ctx, olderThan, after, limit)
+}
+
+// StoreRateLimitStatus mocks base method.
+func (m *MockStorage) StoreRateLimitStatus(ctx context.Context, key string, status urlscanner.RateLimitStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreRateLimitStatus", ctx, key, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreRateLimitStatus indicates an expected call of StoreRateLimitStatus.
+func (mr *MockStorageMockRecorder) StoreRateLimitStatus(ctx, key, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreRateLimitStatus", reflect.TypeOf((*MockStorage)(nil).StoreRateLimitStatus), ctx, key, status)
+}
+
 // StoreScans mocks base method.
 func (m *MockStorage) StoreScans(ctx context.Context, scans ...domain.Scan) ([]domain.Scan, error) {
 	m.ctrl.T.Helper()
@@ -523,17 +1525,18 @@ func (mr *MockStorageMockRecorder) StoreScans(ctx any, scans ...any) *gomock.Cal
 }
 
 // UpdatePendingScansByURL mocks base method.
-func (m *MockStorage) UpdatePendingScansByURL(ctx context.Context, URL string, updates storage.ScanUpdates) error {
+func (m *MockStorage) UpdatePendingScansByURL(ctx context.Context, URL string, owner domain.UserID, updates storage.ScanUpdates) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdatePendingScansByURL", ctx, URL, updates)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "UpdatePendingScansByURL", ctx, URL, owner, updates)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // UpdatePendingScansByURL indicates an expected call of UpdatePendingScansByURL.
-func (mr *MockStorageMockRecorder) UpdatePendingScansByURL(ctx, URL, updates any) *gomock.Call {
+func (mr *MockStorageMockRecorder) UpdatePendingScansByURL(ctx, URL, owner, updates any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePendingScansByURL", reflect.TypeOf((*MockStorage)(nil).UpdatePendingScansByURL), ctx, URL, updates)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePendingScansByURL", reflect.TypeOf((*MockStorage)(nil).UpdatePendingScansByURL), ctx, URL, owner, updates)
 }
 
 // UpdateScanByID mocks base method.
@@ -551,19 +1554,50 @@ func (mr *MockStorageMockRecorder) UpdateScanByID(ctx, ID, updates any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScanByID", reflect.TypeOf((*MockStorage)(nil).UpdateScanByID), ctx, ID, updates)
 }
 
+// UpdateScanByIDForUser mocks base method.
+func (m *MockStorage) UpdateScanByIDForUser(ctx context.Context, userID domain.UserID, ID domain.ScanID, updates storage.ScanUpdates) (*domain.Scan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateScanByIDForUser", ctx, userID, ID, updates)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateScanByIDForUser indicates an expected call of UpdateScanByIDForUser.
+func (mr *MockStorageMockRecorder) UpdateScanByIDForUser(ctx, userID, ID, updates any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScanByIDForUser", reflect.TypeOf((*MockStorage)(nil).UpdateScanByIDForUser), ctx, userID, ID, updates)
+}
+
+// UpsertScan mocks base method.
+func (m *MockStorage) UpsertScan(ctx context.Context, scan domain.Scan) (*domain.Scan, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertScan", ctx, scan)
+	ret0, _ := ret[0].(*domain.Scan)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// UpsertScan indicates an expected call of UpsertScan.
+func (mr *MockStorageMockRecorder) UpsertScan(ctx, scan any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertScan", reflect.TypeOf((*MockStorage)(nil).UpsertScan), ctx, scan)
+}
+
 // UserScans mocks base method.
-func (m *MockStorage) UserScans(ctx context.Context, userID domain.UserID, status domain.ScanStatus, cursor time.Time, limit uint) (storage.UserScans, error) {
+func (m *MockStorage) UserScans(ctx context.Context, userID domain.UserID, status domain.ScanStatus, maliciousOnly bool, cursor time.Time, direction storage.PageDirection, limit uint) (storage.UserScans, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UserScans", ctx, userID, status, cursor, limit)
+	ret := m.ctrl.Call(m, "UserScans", ctx, userID, status, maliciousOnly, cursor, direction, limit)
 	ret0, _ := ret[0].(storage.UserScans)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UserScans indicates an expected call of UserScans.
-func (mr *MockStorageMockRecorder) UserScans(ctx, userID, status, cursor, limit any) *gomock.Call {
+func (mr *MockStorageMockRecorder) UserScans(ctx, userID, status, maliciousOnly, cursor, direction, limit any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserScans", reflect.TypeOf((*MockStorage)(nil).UserScans), ctx, userID, status, cursor, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserScans", reflect.TypeOf((*MockStorage)(nil).UserScans), ctx, userID, status, maliciousOnly, cursor, direction, limit)
 }
 
 // WithTx mocks base method.
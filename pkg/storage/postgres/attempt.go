@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"scanner/pkg/domain"
+	"scanner/pkg/storage"
+	"scanner/pkg/urlscanner"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+)
+
+const (
+	scanAttemptsTable = "scan_attempts"
+)
+
+// RecordScanAttempts inserts a copy of attempt for every pending, non-deleted
+// scan of URL that the job of owner scans, see pendingScansOfJob, in a single
+// INSERT ... SELECT statement. It runs within the transaction of p, if any.
+func (p *PgSQL) RecordScanAttempts(
+	ctx context.Context,
+	URL string,
+	owner domain.UserID,
+	attempt storage.ScanAttempt,
+) (err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	row := newPgScanAttempt(attempt)
+	_, err = p.Builder.Insert(scanAttemptsTable).
+		Cols("scan_id", "error", "rate_limit_limit", "rate_limit_remaining", "rate_limit_reset_at").
+		FromQuery(p.Builder.From(scansTable).
+			// values of a select list are typed as text unless cast
+			Select(
+				goqu.I("id"),
+				goqu.Cast(goqu.V(row.Error), "TEXT"),
+				goqu.Cast(goqu.V(row.RateLimitLimit), "INTEGER"),
+				goqu.Cast(goqu.V(row.RateLimitRemaining), "INTEGER"),
+				goqu.Cast(goqu.V(row.RateLimitResetAt), "TIMESTAMP"),
+			).
+			Where(pendingScansOfJob(URL, owner)...)).
+		Executor().ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("could not store scan attempts into pg: %w", err)
+	}
+
+	return nil
+}
+
+// ScanAttemptsByID returns the attempts of a scan ordered by id ASC.
+func (p *PgSQL) ScanAttemptsByID(ctx context.Context, ID domain.ScanID) (_ []storage.ScanAttempt, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	var rows []PgScanAttempt
+	if err := p.Builder.From(scanAttemptsTable).
+		Where(goqu.I("scan_id").Eq(uuid.UUID(ID))).
+		Order(goqu.I("id").Asc()).
+		Executor().ScanStructsContext(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("could not fetch scan attempts from pg: %w", err)
+	}
+
+	attempts := make([]storage.ScanAttempt, 0, len(rows))
+	for _, row := range rows {
+		attempts = append(attempts, row.toStorage())
+	}
+
+	return attempts, nil
+}
+
+// newPgScanAttempt converts attempt into a scan_attempts row, storing an empty
+// error and a zero reset time as NULL.
+func newPgScanAttempt(attempt storage.ScanAttempt) PgScanAttempt {
+	return PgScanAttempt{
+		ScanID:             uuid.UUID(attempt.ScanID),
+		Error:              sql.NullString{String: attempt.Error, Valid: attempt.Error != ""},
+		RateLimitLimit:     attempt.RateLimit.Limit,
+		RateLimitRemaining: attempt.RateLimit.Remaining,
+		RateLimitResetAt: sql.NullTime{
+			Time:  attempt.RateLimit.ResetAt,
+			Valid: !attempt.RateLimit.ResetAt.IsZero(),
+		},
+	}
+}
+
+// toStorage converts a scan_attempts row into a storage.ScanAttempt.
+func (p *PgScanAttempt) toStorage() storage.ScanAttempt {
+	return storage.ScanAttempt{
+		ID:     p.ID,
+		ScanID: domain.ScanID(p.ScanID),
+		Error:  p.Error.String,
+		RateLimit: urlscanner.RateLimitStatus{
+			Limit:     p.RateLimitLimit,
+			Remaining: p.RateLimitRemaining,
+			ResetAt:   p.RateLimitResetAt.Time,
+		},
+		CreatedAt: p.CreatedAt,
+	}
+}
@@ -0,0 +1,78 @@
+package postgres_test
+
+import (
+	"context"
+	"scanner/pkg/domain"
+	"scanner/pkg/storage"
+	"scanner/pkg/urlscanner"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPgSQL_RecordScanAttempts(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	userID := domain.UserID(uuid.New())
+
+	s1 := domain.Scan{UserID: userID, URL: urlA, Status: domain.ScanStatusPending}
+	s2 := domain.Scan{UserID: userID, URL: urlA, Status: domain.ScanStatusPending}
+	s3 := domain.Scan{UserID: userID, URL: urlA, Status: domain.ScanStatusCompleted}
+	s4 := domain.Scan{UserID: userID, URL: urlB, Status: domain.ScanStatusPending}
+	ins, err := pgSQL.StoreScans(ctx, s1, s2, s3, s4)
+	require.NoError(t, err)
+	require.Len(t, ins, 4)
+
+	// first worker run fails and keeps the scans pending
+	errMsg := "provider down"
+	require.NoError(t, pgSQL.RecordScanAttempts(ctx, urlA, userID, storage.ScanAttempt{Error: errMsg}))
+	_, err = pgSQL.UpdatePendingScansByURL(ctx, urlA, userID, storage.ScanUpdates{
+		Status:      domain.ScanStatusFailed,
+		LastError:   &errMsg,
+		MaxAttempts: 3,
+	})
+	require.NoError(t, err)
+
+	// second worker run succeeds
+	resetAt := time.Now().UTC().Truncate(time.Second).Add(time.Minute)
+	rl := urlscanner.RateLimitStatus{Limit: 60, Remaining: 59, ResetAt: resetAt}
+	require.NoError(t, pgSQL.RecordScanAttempts(ctx, urlA, userID, storage.ScanAttempt{RateLimit: rl}))
+	_, err = pgSQL.UpdatePendingScansByURL(ctx, urlA, userID, storage.ScanUpdates{
+		Status: domain.ScanStatusCompleted,
+		Result: &domain.ScanResult{},
+	})
+	require.NoError(t, err)
+
+	// a run after the scans completed records nothing for them
+	require.NoError(t, pgSQL.RecordScanAttempts(ctx, urlA, userID, storage.ScanAttempt{Error: "late"}))
+
+	for _, sc := range ins[:2] {
+		attempts, err := pgSQL.ScanAttemptsByID(ctx, sc.ID)
+		require.NoError(t, err)
+		require.Len(t, attempts, 2)
+
+		require.Equal(t, sc.ID, attempts[0].ScanID)
+		require.Equal(t, errMsg, attempts[0].Error)
+		require.Zero(t, attempts[0].RateLimit)
+		require.False(t, attempts[0].CreatedAt.IsZero())
+
+		require.Less(t, attempts[0].ID, attempts[1].ID)
+		require.Empty(t, attempts[1].Error)
+		require.Equal(t, 60, attempts[1].RateLimit.Limit)
+		require.Equal(t, 59, attempts[1].RateLimit.Remaining)
+		require.True(t, resetAt.Equal(attempts[1].RateLimit.ResetAt))
+	}
+
+	// completed scans and scans of other URLs have no attempts
+	for _, sc := range ins[2:] {
+		attempts, err := pgSQL.ScanAttemptsByID(ctx, sc.ID)
+		require.NoError(t, err)
+		require.Empty(t, attempts)
+	}
+}
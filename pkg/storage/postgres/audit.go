@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"scanner/pkg/domain"
+	"scanner/pkg/storage"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+)
+
+const (
+	auditLogTable = "audit_log"
+)
+
+// RecordAudit inserts an audit log entry. It runs within the transaction of p,
+// if any.
+func (p *PgSQL) RecordAudit(ctx context.Context,
+	userID domain.UserID,
+	action storage.AuditAction,
+	scanID domain.ScanID) (err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	_, err = p.Builder.Insert(auditLogTable).
+		Rows(PgAuditEntry{
+			UserID: uuid.UUID(userID),
+			Action: string(action),
+			ScanID: uuid.UUID(scanID),
+		}).
+		Executor().ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("could not store audit entry into pg: %w", err)
+	}
+
+	return nil
+}
+
+// AuditLog returns a page of audit log entries matching filter, ordered by
+// id DESC.
+func (p *PgSQL) AuditLog(ctx context.Context, filter storage.AuditFilter) (_ storage.AuditEntries, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	var w []goqu.Expression
+	if !filter.UserID.IsZero() {
+		w = append(w, goqu.I("user_id").Eq(uuid.UUID(filter.UserID)))
+	}
+	if uuid.UUID(filter.ScanID) != uuid.Nil {
+		w = append(w, goqu.I("scan_id").Eq(uuid.UUID(filter.ScanID)))
+	}
+	if filter.Action != "" {
+		w = append(w, goqu.I("action").Eq(string(filter.Action)))
+	}
+	if filter.Cursor > 0 {
+		w = append(w, goqu.I("id").Lt(filter.Cursor))
+	}
+
+	// fetch one extra to determine if there is a next page
+	ds := p.Builder.From(auditLogTable).
+		Where(w...).
+		Order(goqu.I("id").Desc()).
+		Limit(filter.Limit + 1)
+
+	var rows []PgAuditEntry
+	if err := ds.Executor().ScanStructsContext(ctx, &rows); err != nil {
+		return storage.AuditEntries{}, fmt.Errorf("could not fetch audit entries from pg: %w", err)
+	}
+
+	var nextCursor *int64
+	if uint(len(rows)) > filter.Limit {
+		rows = rows[:filter.Limit]
+		nextCursor = &rows[len(rows)-1].ID
+	}
+
+	entries := make([]storage.AuditEntry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, row.toStorage())
+	}
+
+	return storage.AuditEntries{
+		Entries:    entries,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// toStorage converts an audit_log row into a storage.AuditEntry.
+func (p *PgAuditEntry) toStorage() storage.AuditEntry {
+	return storage.AuditEntry{
+		ID:        p.ID,
+		UserID:    domain.UserID(p.UserID),
+		Action:    storage.AuditAction(p.Action),
+		ScanID:    domain.ScanID(p.ScanID),
+		CreatedAt: p.CreatedAt,
+	}
+}
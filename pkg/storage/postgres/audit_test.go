@@ -0,0 +1,125 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"scanner/pkg/domain"
+	"scanner/pkg/storage"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPgSQL_RecordAudit_WithinTransaction(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	userID := domain.UserID(uuid.New())
+	filter := storage.AuditFilter{UserID: userID, Limit: 10}
+
+	// creating a scan writes its audit entry in the same transaction
+	var scanID domain.ScanID
+	err := pgSQL.WithTx(ctx, func(tx storage.AllStorage) error {
+		stored, err := tx.StoreScans(ctx, domain.Scan{UserID: userID, URL: urlA, Status: domain.ScanStatusPending})
+		require.NoError(t, err)
+		scanID = stored[0].ID
+		require.NoError(t, tx.RecordAudit(ctx, userID, storage.AuditActionCreate, scanID))
+
+		// visible within the transaction only
+		page, err := tx.AuditLog(ctx, filter)
+		require.NoError(t, err)
+		require.Len(t, page.Entries, 1)
+		page, err = pgSQL.AuditLog(ctx, filter)
+		require.NoError(t, err)
+		require.Empty(t, page.Entries)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	page, err := pgSQL.AuditLog(ctx, filter)
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 1)
+	require.Equal(t, userID, page.Entries[0].UserID)
+	require.Equal(t, storage.AuditActionCreate, page.Entries[0].Action)
+	require.Equal(t, scanID, page.Entries[0].ScanID)
+	require.False(t, page.Entries[0].CreatedAt.IsZero())
+
+	// a rolled back deletion leaves neither the deletion nor its audit entry
+	err = pgSQL.WithTx(ctx, func(tx storage.AllStorage) error {
+		deleted, err := tx.DeleteScan(ctx, userID, scanID)
+		require.NoError(t, err)
+		require.NotNil(t, deleted)
+		require.NoError(t, tx.RecordAudit(ctx, userID, storage.AuditActionDelete, scanID))
+
+		return errors.New("boom")
+	})
+	require.Error(t, err)
+	scan, err := pgSQL.ScanByID(ctx, userID, scanID)
+	require.NoError(t, err)
+	require.NotNil(t, scan)
+	page, err = pgSQL.AuditLog(ctx, filter)
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 1)
+
+	// a committed deletion is recorded after the creation
+	err = pgSQL.WithTx(ctx, func(tx storage.AllStorage) error {
+		deleted, err := tx.DeleteScan(ctx, userID, scanID)
+		require.NoError(t, err)
+		require.NotNil(t, deleted)
+
+		return tx.RecordAudit(ctx, userID, storage.AuditActionDelete, scanID) //nolint: wrapcheck
+	})
+	require.NoError(t, err)
+	page, err = pgSQL.AuditLog(ctx, filter)
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 2)
+	require.Equal(t, storage.AuditActionDelete, page.Entries[0].Action)
+	require.Equal(t, storage.AuditActionCreate, page.Entries[1].Action)
+	require.Greater(t, page.Entries[0].ID, page.Entries[1].ID)
+}
+
+func TestPgSQL_AuditLog(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	userA := domain.UserID(uuid.New())
+	userB := domain.UserID(uuid.New())
+	scan1 := domain.ScanID(uuid.New())
+	scan2 := domain.ScanID(uuid.New())
+	require.NoError(t, pgSQL.RecordAudit(ctx, userA, storage.AuditActionCreate, scan1))
+	require.NoError(t, pgSQL.RecordAudit(ctx, userA, storage.AuditActionDelete, scan1))
+	require.NoError(t, pgSQL.RecordAudit(ctx, userB, storage.AuditActionCreate, scan2))
+
+	// filters
+	page, err := pgSQL.AuditLog(ctx, storage.AuditFilter{UserID: userA, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 2)
+	page, err = pgSQL.AuditLog(ctx, storage.AuditFilter{ScanID: scan2, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 1)
+	require.Equal(t, userB, page.Entries[0].UserID)
+	page, err = pgSQL.AuditLog(ctx, storage.AuditFilter{Action: storage.AuditActionDelete, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 1)
+	require.Equal(t, scan1, page.Entries[0].ScanID)
+
+	// pagination, newest first
+	page, err = pgSQL.AuditLog(ctx, storage.AuditFilter{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 2)
+	require.Equal(t, scan2, page.Entries[0].ScanID)
+	require.NotNil(t, page.NextCursor)
+	page, err = pgSQL.AuditLog(ctx, storage.AuditFilter{Cursor: *page.NextCursor, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 1)
+	require.Equal(t, storage.AuditActionCreate, page.Entries[0].Action)
+	require.Nil(t, page.NextCursor)
+}
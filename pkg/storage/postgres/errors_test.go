@@ -0,0 +1,78 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"scanner/pkg/domain"
+	"scanner/pkg/serrors"
+	"scanner/pkg/storage/postgres"
+	"testing"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+// failingDB is a stubDB whose queries fail with err.
+type failingDB struct {
+	stubDB
+
+	err error
+}
+
+func (f *failingDB) QueryContext(_ context.Context, query string, _ ...any) (*sql.Rows, error) {
+	f.queries = append(f.queries, query)
+
+	return nil, f.err
+}
+
+func TestPgSQL_MapsConstraintViolations(t *testing.T) {
+	tests := []struct {
+		code string
+		kind serrors.Kind
+	}{
+		{"23505", serrors.ErrConflict},
+		{"23503", serrors.ErrBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			pgErr := &pgconn.PgError{Code: tt.code, ConstraintName: "some_constraint"}
+			pg := &postgres.PgSQL{Builder: goqu.Dialect("postgres").DB(&failingDB{err: pgErr})}
+
+			_, err := pg.ScanByID(context.Background(), domain.UserID(uuid.New()), domain.ScanID(uuid.New()))
+			require.ErrorIs(t, err, tt.kind)
+			require.ErrorIs(t, err, pgErr, "the postgres error stays the cause")
+			require.ErrorContains(t, err, "some_constraint")
+		})
+	}
+
+	// other postgres errors are returned as is
+	pgErr := &pgconn.PgError{Code: "42P01"}
+	pg := &postgres.PgSQL{Builder: goqu.Dialect("postgres").DB(&failingDB{err: pgErr})}
+	_, err := pg.ScanByID(context.Background(), domain.UserID(uuid.New()), domain.ScanID(uuid.New()))
+	require.ErrorIs(t, err, pgErr)
+	require.NotErrorIs(t, err, serrors.ErrConflict)
+	require.NotErrorIs(t, err, serrors.ErrBadRequest)
+}
+
+func TestPgSQL_StoreScans_DuplicateIsConflict(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	// scans_user_id_idempotency_key_idx allows a key once per user
+	scan := domain.Scan{
+		UserID:         domain.UserID(uuid.New()),
+		URL:            "https://dupe.test",
+		Status:         domain.ScanStatusPending,
+		IdempotencyKey: "key",
+	}
+	_, err := pgSQL.StoreScans(ctx, scan)
+	require.NoError(t, err)
+
+	_, err = pgSQL.StoreScans(ctx, scan)
+	require.ErrorIs(t, err, serrors.ErrConflict)
+}
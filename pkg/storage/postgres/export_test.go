@@ -0,0 +1,29 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/riverqueue/river"
+)
+
+// RiverClient exposes the River client shared by AddJob to tests.
+func RiverClient(p *PgSQL) *river.Client[*sql.Tx] {
+	return p.riverClient
+}
+
+// SetQueryTimeout sets the query timeout of p, bypassing New.
+func SetQueryTimeout(p *PgSQL, timeout time.Duration) {
+	p.queryTimeout = timeout
+}
+
+// Sleep runs pg_sleep for d on p, bounded by its query timeout.
+func Sleep(ctx context.Context, p *PgSQL, d time.Duration) (err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	_, err = p.DB.ExecContext(ctx, "SELECT pg_sleep($1)", d.Seconds())
+
+	return err
+}
@@ -3,49 +3,174 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"scanner/pkg/domain"
+	"scanner/pkg/storage"
 
+	"github.com/doug-martin/goqu/v9"
 	"github.com/riverqueue/river"
-	"github.com/riverqueue/river/riverdriver/riverdatabasesql"
+	"github.com/riverqueue/river/rivertype"
 )
 
-// AddJob enqueues a new River job using the underlying database handle.
+const (
+	riverJobTable = "river_job"
+)
+
+// AddJob enqueues a new River job using the River client shared by this
+// storage and the transactions it begins.
 //
 // Behavior:
 //   - If PgSQL is currently operating inside a transaction (DB is a *sql.Tx), the
 //     job is inserted using InsertTx so that it participates in the surrounding
 //     transaction and will only become visible upon a successful commit.
-//   - Otherwise, the job is inserted using a client bound to the *sql.DB, making
-//     the operation immediately visible once the insert succeeds.
+//   - Otherwise, the job is inserted through the *sql.DB the client is bound
+//     to, making the operation immediately visible once the insert succeeds.
 //
-// Any failure to create the River client or to insert the job is returned as an
-// error. The provided context controls cancellation and deadlines of the insert
-// operation.
-func (p *PgSQL) AddJob(ctx context.Context, args river.JobArgs, opts *river.InsertOpts) (bool, error) {
-	tx, ok := p.DB.(*sql.Tx)
-	if ok {
-		riverClient, err := river.NewClient[*sql.Tx](riverdatabasesql.New(nil), &river.Config{})
-		if err != nil {
-			return false, fmt.Errorf("could not create river queue client: %w", err)
-		}
+// Any failure to insert the job is returned as an error. The provided context
+// controls cancellation and deadlines of the insert operation.
+func (p *PgSQL) AddJob(ctx context.Context, args river.JobArgs, opts *river.InsertOpts) (_ bool, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	var job *rivertype.JobInsertResult
+	if tx, ok := p.DB.(*sql.Tx); ok {
+		job, err = p.riverClient.InsertTx(ctx, tx, args, opts)
+	} else {
+		job, err = p.riverClient.Insert(ctx, args, opts)
+	}
+	if err != nil {
+		return false, fmt.Errorf("could not insert job: %w", err)
+	}
+
+	return !job.UniqueSkippedAsDuplicate, nil
+}
+
+// ListJobs returns a page of jobs from River's job table, newest first. Jobs
+// are paginated by ID; the returned NextCursor is the ID of the last job in
+// the page.
+func (p *PgSQL) ListJobs(ctx context.Context,
+	state rivertype.JobState,
+	limit uint,
+	cursor int64) (_ storage.Jobs, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	var w []goqu.Expression
+	if state != "" {
+		w = append(w, goqu.I("state").Eq(string(state)))
+	}
+	if cursor > 0 {
+		w = append(w, goqu.I("id").Lt(cursor))
+	}
+
+	// fetch one extra to determine if there is a next page
+	ds := p.Builder.From(riverJobTable).
+		Where(w...).
+		Order(goqu.I("id").Desc()).
+		Limit(limit + 1)
+
+	var rows []PgJob
+	if err := ds.Executor().ScanStructsContext(ctx, &rows); err != nil {
+		return storage.Jobs{}, fmt.Errorf("could not fetch jobs from pg: %w", err)
+	}
 
-		job, err := riverClient.InsertTx(ctx, tx, args, opts)
+	var nextCursor *int64
+	if uint(len(rows)) > limit {
+		rows = rows[:limit]
+		nextCursor = &rows[len(rows)-1].ID
+	}
+
+	jobs := make([]storage.Job, 0, len(rows))
+	for _, row := range rows {
+		job, err := row.toStorage()
 		if err != nil {
-			return false, fmt.Errorf("could not insert job: %w", err)
+			return storage.Jobs{}, err
 		}
+		jobs = append(jobs, *job)
+	}
+
+	return storage.Jobs{
+		Jobs:       jobs,
+		NextCursor: nextCursor,
+	}, nil
+}
 
-		return !job.UniqueSkippedAsDuplicate, nil
+// ActiveJobByURL returns the newest non-finalized job of the given kind for URL
+// and owner. Jobs without an owner argument belong to the zero owner. In a
+// transaction, a transaction-scoped advisory lock keyed by kind, URL and owner
+// is taken first, so a concurrent transaction looking up the same job waits
+// until this one ends and then sees the job it added, if any.
+func (p *PgSQL) ActiveJobByURL(
+	ctx context.Context,
+	kind, URL string,
+	owner domain.UserID,
+) (_ *storage.Job, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	if _, ok := p.DB.(*sql.Tx); ok {
+		key := "job:" + kind + ":" + owner.String() + ":" + URL
+		if _, err := p.DB.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtextextended($1, 0))", key); err != nil {
+			return nil, fmt.Errorf("could not lock active job by url in pg: %w", err)
+		}
 	}
 
-	riverClient, err := river.NewClient(riverdatabasesql.New(p.DB.(*sql.DB)), &river.Config{})
+	var row PgJob
+	found, err := p.Builder.From(riverJobTable).
+		Where(
+			goqu.I("kind").Eq(kind),
+			goqu.L("args->>'url' = ?", URL),
+			jobOwnerCond(owner),
+			goqu.I("state").In(
+				string(rivertype.JobStateAvailable),
+				string(rivertype.JobStatePending),
+				string(rivertype.JobStateRetryable),
+				string(rivertype.JobStateRunning),
+				string(rivertype.JobStateScheduled),
+			),
+		).
+		Order(goqu.I("id").Desc()).
+		Executor().ScanStructContext(ctx, &row)
 	if err != nil {
-		return false, fmt.Errorf("could not create river queue client: %w", err)
+		return nil, fmt.Errorf("could not fetch active job by url from pg: %w", err)
+	}
+	if !found {
+		return nil, nil
 	}
 
-	job, err := riverClient.Insert(ctx, args, opts)
-	if err != nil {
-		return false, fmt.Errorf("could not insert job: %w", err)
+	return row.toStorage()
+}
+
+// jobOwnerCond matches the jobs whose owner argument is owner, or the jobs
+// without one for the zero owner.
+func jobOwnerCond(owner domain.UserID) goqu.Expression {
+	if owner.IsZero() {
+		return goqu.L("COALESCE(args->>'owner', '') = ''")
 	}
 
-	return !job.UniqueSkippedAsDuplicate, nil
+	return goqu.L("args->>'owner' = ?", owner.String())
+}
+
+// toStorage converts a river_job row into a storage.Job, extracting the url
+// argument when present.
+func (p *PgJob) toStorage() (*storage.Job, error) {
+	var args struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(p.Args, &args); err != nil {
+		return nil, fmt.Errorf("could not unmarshal job args: %w", err)
+	}
+
+	return &storage.Job{
+		ID:          p.ID,
+		Kind:        p.Kind,
+		URL:         args.URL,
+		State:       rivertype.JobState(p.State),
+		Queue:       p.Queue,
+		Attempt:     p.Attempt,
+		MaxAttempts: p.MaxAttempts,
+		CreatedAt:   p.CreatedAt,
+		ScheduledAt: p.ScheduledAt,
+	}, nil
 }
@@ -3,13 +3,22 @@ package postgres_test
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"scanner/internal/scanner"
+	"scanner/pkg/domain"
+	"scanner/pkg/storage"
 	"scanner/pkg/storage/postgres"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/riverqueue/river"
 	"github.com/riverqueue/river/riverdriver/riverdatabasesql"
 	"github.com/riverqueue/river/rivermigrate"
 	"github.com/riverqueue/river/rivertest"
+	"github.com/riverqueue/river/rivertype"
 	"github.com/stretchr/testify/require"
 )
 
@@ -17,12 +26,18 @@ type dummyJobArgs struct{}
 
 func (dummyJobArgs) Kind() string { return "dummy" }
 
-func migrateRiver(t *testing.T, storage *postgres.PgSQL) {
+// migrateRiver applies the river queue migrations that are not applied yet.
+func migrateRiver(t testing.TB, storage *postgres.PgSQL) {
 	t.Helper()
 	migrator, err := rivermigrate.New(riverdatabasesql.New(storage.DB.(*sql.DB)), nil)
 	require.NoError(t, err)
 	migrations := migrator.AllVersions()
 	latestVersion := migrations[len(migrations)-1].Version
+	existing, err := migrator.ExistingVersions(t.Context())
+	require.NoError(t, err)
+	if len(existing) > 0 && existing[len(existing)-1].Version == latestVersion {
+		return
+	}
 	_, err = migrator.Migrate(t.Context(), rivermigrate.DirectionUp, &rivermigrate.MigrateOpts{
 		TargetVersion: latestVersion,
 	})
@@ -69,3 +84,400 @@ func TestPgSQL_AddJob_OutsideTransaction_UsesDBPath(t *testing.T) {
 		nil,
 	)
 }
+
+func TestPgSQL_AddJob_ReusesRiverClient(t *testing.T) {
+	pg, cleanup := setupTestDB(t)
+	defer cleanup()
+	migrateRiver(t, pg)
+
+	ctx := context.Background()
+	client := postgres.RiverClient(pg)
+	require.NotNil(t, client)
+
+	for i := range 10 {
+		_, err := pg.AddJob(ctx, scanner.NewJobArgs(fmt.Sprintf("https://example.com/%d", i), nil, scanner.JobOptions{}), nil)
+		require.NoError(t, err)
+	}
+
+	// transactions share the client and insert through their own *sql.Tx
+	txStorage, err := pg.Begin(ctx)
+	require.NoError(t, err)
+	require.Same(t, client, postgres.RiverClient(txStorage.(*postgres.PgSQL)))
+	for i := range 10 {
+		_, err := txStorage.AddJob(ctx, scanner.NewJobArgs(fmt.Sprintf("https://example.org/%d", i), nil, scanner.JobOptions{}), nil)
+		require.NoError(t, err)
+	}
+	require.NoError(t, txStorage.Rollback())
+
+	require.Same(t, client, postgres.RiverClient(pg))
+	page, err := pg.ListJobs(ctx, "", 100, 0)
+	require.NoError(t, err)
+	require.Len(t, page.Jobs, 10, "jobs added in the rolled back transaction must not be visible")
+}
+
+func BenchmarkPgSQL_AddJob(b *testing.B) {
+	pg, cleanup := setupTestDB(b)
+	defer cleanup()
+	migrateRiver(b, pg)
+
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		_, err := pg.AddJob(ctx, scanner.NewJobArgs(fmt.Sprintf("https://example.com/%d", i), nil, scanner.JobOptions{}), nil)
+		require.NoError(b, err)
+	}
+}
+
+func TestPgSQL_AddJob_HonorsQueueAndPriority(t *testing.T) {
+	pg, cleanup := setupTestDB(t)
+	defer cleanup()
+	migrateRiver(t, pg)
+
+	ctx := context.Background()
+
+	_, err := pg.AddJob(ctx, dummyJobArgs{}, &river.InsertOpts{Queue: "priority", Priority: 1})
+	require.NoError(t, err)
+	rivertest.RequireInserted[*riverdatabasesql.Driver](
+		ctx,
+		t,
+		riverdatabasesql.New(pg.DB.(*sql.DB)),
+		&dummyJobArgs{},
+		&rivertest.RequireInsertedOpts{Queue: "priority", Priority: 1},
+	)
+}
+
+func TestPgSQL_AddJob_ScanJobCustomTimeout(t *testing.T) {
+	pg, cleanup := setupTestDB(t)
+	defer cleanup()
+	migrateRiver(t, pg)
+
+	ctx := context.Background()
+
+	args := scanner.NewJobArgs("https://example.com/", nil, scanner.JobOptions{
+		MaxAttempts: 3,
+		Queue:       "priority",
+		Priority:    1,
+		Timeout:     5 * time.Minute,
+	})
+	_, err := pg.AddJob(ctx, args, nil)
+	require.NoError(t, err)
+	job := rivertest.RequireInserted[*riverdatabasesql.Driver](
+		ctx,
+		t,
+		riverdatabasesql.New(pg.DB.(*sql.DB)),
+		&scanner.JobArgs{},
+		&rivertest.RequireInsertedOpts{MaxAttempts: 3, Queue: "priority", Priority: 1},
+	)
+	require.Equal(t, "https://example.com/", job.Args.URL)
+	require.Equal(t, 5*time.Minute, job.Args.Timeout)
+
+	// the timeout is not part of the unique key
+	inserted, err := pg.AddJob(ctx, scanner.NewJobArgs("https://example.com/", nil, scanner.JobOptions{MaxAttempts: 3}), nil)
+	require.NoError(t, err)
+	require.False(t, inserted)
+}
+
+func TestPgSQL_AddJob_ScanJobUniqueWithinPeriod(t *testing.T) {
+	pg, cleanup := setupTestDB(t)
+	defer cleanup()
+	migrateRiver(t, pg)
+
+	ctx := context.Background()
+	period := 2 * time.Second
+	args := scanner.NewJobArgs("https://example.com/", nil, scanner.JobOptions{
+		MaxAttempts:     3,
+		UniqueJobPeriod: period,
+	})
+
+	// wait for the start of a fresh period bucket so both inserts fall into the same one
+	time.Sleep(time.Until(time.Now().Truncate(period).Add(period)))
+
+	added, err := pg.AddJob(ctx, args, nil)
+	require.NoError(t, err)
+	require.True(t, added)
+	rivertest.RequireInserted[*riverdatabasesql.Driver](
+		ctx,
+		t,
+		riverdatabasesql.New(pg.DB.(*sql.DB)),
+		&scanner.JobArgs{},
+		&rivertest.RequireInsertedOpts{MaxAttempts: 3},
+	)
+
+	// same URL within the period is skipped as duplicate
+	added, err = pg.AddJob(ctx, args, nil)
+	require.NoError(t, err)
+	require.False(t, added)
+
+	// a different URL is not a duplicate
+	added, err = pg.AddJob(ctx, scanner.NewJobArgs("https://example.com/other", nil, scanner.JobOptions{
+		UniqueJobPeriod: period,
+	}), nil)
+	require.NoError(t, err)
+	require.True(t, added)
+
+	// once the period elapsed, the same URL is allowed again
+	time.Sleep(period)
+	added, err = pg.AddJob(ctx, args, nil)
+	require.NoError(t, err)
+	require.True(t, added)
+}
+
+func TestScanner_Enqueue_ConcurrentSameURLShareJob(t *testing.T) {
+	pg, cleanup := setupTestDB(t)
+	defer cleanup()
+	migrateRiver(t, pg)
+
+	ctx := context.Background()
+	s := scanner.New(pg, nil, scanner.Options{MaxAttempts: 3})
+	const URL = "https://example.com/"
+
+	// both enqueues run in their own transaction; whichever inserts the job
+	// second is blocked by river's unique index until the first one commits
+	var wg sync.WaitGroup
+	scans := make([]*domain.Scan, 2)
+	errs := make([]error, 2)
+	for i := range scans {
+		wg.Go(func() {
+			scans[i], errs[i] = s.Enqueue(ctx, domain.UserID(uuid.New()), URL, "", nil, "")
+		})
+	}
+	wg.Wait()
+
+	for i := range scans {
+		require.NoError(t, errs[i])
+		require.Equal(t, domain.ScanStatusPending, scans[i].Status)
+	}
+	require.NotEqual(t, scans[0].ID, scans[1].ID)
+
+	jobs, err := pg.ListJobs(ctx, "", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, jobs.Jobs, 1)
+
+	pending, err := pg.PendingScanCountByURL(ctx, URL, domain.UserID{})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), pending)
+}
+
+func TestScanner_Enqueue_DeletedResultScansAgain(t *testing.T) {
+	pg, cleanup := setupTestDB(t)
+	defer cleanup()
+	migrateRiver(t, pg)
+
+	ctx := context.Background()
+	s := scanner.New(pg, nil, scanner.Options{MaxAttempts: 3, ResultCacheTTL: time.Hour})
+	const URL = "https://example.com/"
+	user := domain.UserID(uuid.New())
+
+	// the first scan completes and its job finishes
+	first, err := s.Enqueue(ctx, user, URL, "", nil, "")
+	require.NoError(t, err)
+	_, err = pg.UpdatePendingScansByURL(ctx, URL, domain.UserID{}, storage.ScanUpdates{
+		Status: domain.ScanStatusCompleted,
+		Result: &domain.ScanResult{},
+	})
+	require.NoError(t, err)
+	_, err = pg.DB.ExecContext(ctx, "UPDATE river_job SET state = 'completed', finalized_at = now()")
+	require.NoError(t, err)
+
+	// the only completed scan within ResultCacheTTL is deleted, so the finished
+	// job has no result to reuse and the URL is scanned again
+	_, err = pg.DeleteScan(ctx, user, first.ID)
+	require.NoError(t, err)
+	second, err := s.Enqueue(ctx, user, URL, "", nil, "")
+	require.NoError(t, err)
+	require.Equal(t, domain.ScanStatusPending, second.Status)
+
+	job, err := pg.ActiveJobByURL(ctx, scanner.JobKind, URL, domain.UserID{})
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	jobs, err := pg.ListJobs(ctx, "", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, jobs.Jobs, 2)
+}
+
+func TestPgSQL_ListJobs(t *testing.T) {
+	pg, cleanup := setupTestDB(t)
+	defer cleanup()
+	migrateRiver(t, pg)
+
+	ctx := context.Background()
+
+	_, err := pg.AddJob(ctx, scanner.NewJobArgs("https://example.com/", nil, scanner.JobOptions{MaxAttempts: 3}), nil)
+	require.NoError(t, err)
+	_, err = pg.AddJob(ctx, dummyJobArgs{}, nil)
+	require.NoError(t, err)
+
+	page, err := pg.ListJobs(ctx, rivertype.JobStateAvailable, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, page.Jobs, 2)
+	require.Nil(t, page.NextCursor)
+	// newest first
+	require.Equal(t, "dummy", page.Jobs[0].Kind)
+	job := page.Jobs[1]
+	require.Equal(t, scanner.JobKind, job.Kind)
+	require.Equal(t, "https://example.com/", job.URL)
+	require.Equal(t, rivertype.JobStateAvailable, job.State)
+	require.Equal(t, 3, job.MaxAttempts)
+	require.Zero(t, job.Attempt)
+
+	// pagination
+	page, err = pg.ListJobs(ctx, "", 1, 0)
+	require.NoError(t, err)
+	require.Len(t, page.Jobs, 1)
+	require.NotNil(t, page.NextCursor)
+	page, err = pg.ListJobs(ctx, "", 1, *page.NextCursor)
+	require.NoError(t, err)
+	require.Len(t, page.Jobs, 1)
+	require.Equal(t, scanner.JobKind, page.Jobs[0].Kind)
+	require.Nil(t, page.NextCursor)
+
+	// state filter
+	page, err = pg.ListJobs(ctx, rivertype.JobStateCompleted, 10, 0)
+	require.NoError(t, err)
+	require.Empty(t, page.Jobs)
+}
+
+func TestPgSQL_ActiveJobByURL(t *testing.T) {
+	pg, cleanup := setupTestDB(t)
+	defer cleanup()
+	migrateRiver(t, pg)
+
+	ctx := context.Background()
+
+	_, err := pg.AddJob(ctx, scanner.NewJobArgs("https://example.com/", nil, scanner.JobOptions{MaxAttempts: 3}), nil)
+	require.NoError(t, err)
+
+	job, err := pg.ActiveJobByURL(ctx, scanner.JobKind, "https://example.com/", domain.UserID{})
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	require.Equal(t, "https://example.com/", job.URL)
+	require.Equal(t, rivertype.JobStateAvailable, job.State)
+	require.False(t, job.ScheduledAt.IsZero())
+
+	job, err = pg.ActiveJobByURL(ctx, scanner.JobKind, "https://example.org/", domain.UserID{})
+	require.NoError(t, err)
+	require.Nil(t, job)
+
+	job, err = pg.ActiveJobByURL(ctx, "dummy", "https://example.com/", domain.UserID{})
+	require.NoError(t, err)
+	require.Nil(t, job)
+
+	// jobs of private scans are found only for their owner
+	owner := domain.UserID(uuid.New())
+	args := scanner.NewJobArgs("https://example.net/", nil, scanner.JobOptions{MaxAttempts: 3})
+	args.Owner = owner.String()
+	_, err = pg.AddJob(ctx, args, nil)
+	require.NoError(t, err)
+
+	job, err = pg.ActiveJobByURL(ctx, scanner.JobKind, "https://example.net/", owner)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	job, err = pg.ActiveJobByURL(ctx, scanner.JobKind, "https://example.net/", domain.UserID{})
+	require.NoError(t, err)
+	require.Nil(t, job)
+	job, err = pg.ActiveJobByURL(ctx, scanner.JobKind, "https://example.net/", domain.UserID(uuid.New()))
+	require.NoError(t, err)
+	require.Nil(t, job)
+}
+
+func TestPgSQL_ActiveJobByURL_UsesIndex(t *testing.T) {
+	pg, cleanup := setupTestDB(t)
+	defer cleanup()
+	migrateRiver(t, pg)
+
+	ctx := context.Background()
+	for i := range 50 {
+		URL := fmt.Sprintf("https://example.com/%d", i)
+		_, err := pg.AddJob(ctx, scanner.NewJobArgs(URL, nil, scanner.JobOptions{MaxAttempts: 3}), nil)
+		require.NoError(t, err)
+	}
+
+	// the planner picks the partial expression index; sequential scans are
+	// disabled since the table is too small for the planner to prefer an index
+	// on its own
+	tx, err := pg.Begin(ctx)
+	require.NoError(t, err)
+	defer func() { _ = tx.Rollback() }()
+	db := tx.(*postgres.PgSQL).DB
+	_, err = db.ExecContext(ctx, "ANALYZE river_job")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "SET LOCAL enable_seqscan = off")
+	require.NoError(t, err)
+
+	rows, err := db.QueryContext(ctx, `EXPLAIN SELECT * FROM river_job WHERE kind = $1 AND args->>'url' = $2
+		AND state IN ('available', 'pending', 'retryable', 'running', 'scheduled')`,
+		scanner.JobKind, "https://example.com/7")
+	require.NoError(t, err)
+	defer rows.Close()
+	var plan strings.Builder
+	for rows.Next() {
+		var line string
+		require.NoError(t, rows.Scan(&line))
+		plan.WriteString(line + "\n")
+	}
+	require.NoError(t, rows.Err())
+	require.Contains(t, plan.String(), "river_job_active_url_idx")
+}
+
+func TestPgSQL_ActiveJobByURL_SerializesTransactions(t *testing.T) {
+	pg, cleanup := setupTestDB(t)
+	defer cleanup()
+	migrateRiver(t, pg)
+
+	ctx := context.Background()
+	URL := "https://serialized.example/"
+	args := scanner.NewJobArgs(URL, nil, scanner.JobOptions{MaxAttempts: 3, NotUnique: true})
+
+	// the first transaction finds no job and adds one, as Enqueue does
+	tx1, err := pg.Begin(ctx)
+	require.NoError(t, err)
+	job, err := tx1.ActiveJobByURL(ctx, scanner.JobKind, URL, domain.UserID{})
+	require.NoError(t, err)
+	require.Nil(t, job)
+	_, err = tx1.AddJob(ctx, args, nil)
+	require.NoError(t, err)
+
+	// a concurrent lookup of the same job waits for it
+	found := make(chan *storage.Job, 1)
+	go func() {
+		tx2, err := pg.Begin(ctx)
+		if err != nil {
+			close(found)
+
+			return
+		}
+		defer func() { _ = tx2.Rollback() }()
+		job, err := tx2.ActiveJobByURL(ctx, scanner.JobKind, URL, domain.UserID{})
+		if err != nil {
+			close(found)
+
+			return
+		}
+		found <- job
+	}()
+
+	// lookups of other URLs are not blocked
+	tx3, err := pg.Begin(ctx)
+	require.NoError(t, err)
+	job, err = tx3.ActiveJobByURL(ctx, scanner.JobKind, "https://other.example/", domain.UserID{})
+	require.NoError(t, err)
+	require.Nil(t, job)
+	require.NoError(t, tx3.Rollback())
+
+	select {
+	case <-found:
+		t.Fatal("concurrent lookup did not wait for the first transaction")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// and sees its job once it commits
+	require.NoError(t, tx1.Commit())
+	select {
+	case job, ok := <-found:
+		require.True(t, ok, "concurrent lookup failed")
+		require.NotNil(t, job)
+		require.Equal(t, URL, job.URL)
+	case <-time.After(5 * time.Second):
+		t.Fatal("concurrent lookup did not finish")
+	}
+}
@@ -0,0 +1,38 @@
+package postgres_test
+
+import (
+	"context"
+	"scanner/pkg/storage/postgres"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolCollector(t *testing.T) {
+	pg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// make sure the pool has served at least one acquire
+	require.NoError(t, pg.Pool.Ping(context.Background()))
+
+	reg := prometheus.NewPedanticRegistry()
+	require.NoError(t, reg.Register(postgres.NewPoolCollector(pg.Pool)))
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	values := make(map[string]float64, len(families))
+	for _, family := range families {
+		metric := family.GetMetric()[0]
+		if gauge := metric.GetGauge(); gauge != nil {
+			values[family.GetName()] = gauge.GetValue()
+		} else {
+			values[family.GetName()] = metric.GetCounter().GetValue()
+		}
+	}
+
+	require.Len(t, values, 9)
+	require.InDelta(t, 5, values["db_pool_max_conns"], 0)
+	require.Positive(t, values["db_pool_total_conns"])
+	require.Positive(t, values["db_pool_acquire_count_total"])
+}
@@ -0,0 +1,67 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/pressly/goose/v3"
+	"github.com/riverqueue/river/riverdriver/riverdatabasesql"
+	"github.com/riverqueue/river/rivermigrate"
+	"github.com/stretchr/testify/require"
+)
+
+// schemaSnapshot returns a description of every column and index of the
+// public schema, excluding goose's own version table.
+func schemaSnapshot(t *testing.T, db *sql.DB) []string {
+	t.Helper()
+	rows, err := db.QueryContext(context.Background(), `
+		SELECT table_name || '.' || column_name || ' ' || data_type || ' ' || is_nullable || ' ' || COALESCE(column_default, '')
+		FROM information_schema.columns
+		WHERE table_schema = 'public' AND table_name <> 'goose_db_version'
+		UNION ALL
+		SELECT indexdef FROM pg_indexes
+		WHERE schemaname = 'public' AND tablename <> 'goose_db_version'
+		ORDER BY 1`)
+	require.NoError(t, err)
+	defer func() { _ = rows.Close() }()
+
+	var snapshot []string
+	for rows.Next() {
+		var s string
+		require.NoError(t, rows.Scan(&s))
+		snapshot = append(snapshot, s)
+	}
+	require.NoError(t, rows.Err())
+
+	return snapshot
+}
+
+func TestMigrations_DownThenUp(t *testing.T) {
+	pg, cleanup := setupTestDB(t)
+	defer cleanup()
+	migrateRiver(t, pg)
+
+	ctx := context.Background()
+	db := pg.DB.(*sql.DB)
+	migrationsDir := filepath.Join("..", "..", "..", "migrations")
+	before := schemaSnapshot(t, db)
+
+	// app migrations are rolled back first, since they index river queue tables
+	require.NoError(t, goose.DownTo(db, migrationsDir, 0))
+	version, err := goose.GetDBVersion(db)
+	require.NoError(t, err)
+	require.Zero(t, version)
+
+	// river queue migrations
+	migrator, err := rivermigrate.New(riverdatabasesql.New(db), nil)
+	require.NoError(t, err)
+	_, err = migrator.Migrate(ctx, rivermigrate.DirectionDown, &rivermigrate.MigrateOpts{TargetVersion: -1})
+	require.NoError(t, err)
+	migrateRiver(t, pg)
+
+	require.NoError(t, goose.Up(db, migrationsDir))
+
+	require.Equal(t, before, schemaSnapshot(t, db))
+}
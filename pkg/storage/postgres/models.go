@@ -11,7 +11,7 @@ import (
 )
 
 type PgScan struct {
-	ID     uuid.UUID `db:"id"      goqu:"skipinsert"`
+	ID     uuid.UUID `db:"id"`
 	UserID uuid.UUID `db:"user_id"`
 
 	URL    string          `db:"url"`
@@ -21,17 +21,163 @@ type PgScan struct {
 	Attempts  uint           `db:"attempts"   goqu:"skipinsert"`
 	LastError sql.NullString `db:"last_error" goqu:"skipinsert"`
 
+	IdempotencyKey sql.NullString `db:"idempotency_key"`
+	Version        int64          `db:"version" goqu:"skipinsert"`
+	Shareable      bool           `db:"shareable"`
+
 	CreatedAt time.Time    `db:"created_at" goqu:"skipinsert"`
 	UpdatedAt sql.NullTime `db:"updated_at" goqu:"skipinsert"`
 	DeletedAt sql.NullTime `db:"deleted_at" goqu:"skipinsert"`
 }
 
+type PgKeyValue struct {
+	Key   string          `db:"key"`
+	Value json.RawMessage `db:"value"`
+
+	CreatedAt time.Time    `db:"created_at" goqu:"skipinsert"`
+	UpdatedAt sql.NullTime `db:"updated_at" goqu:"skipinsert"`
+}
+
+// PgAuditEntry is a row of the audit_log table.
+type PgAuditEntry struct {
+	ID     int64     `db:"id"      goqu:"skipinsert"`
+	UserID uuid.UUID `db:"user_id"`
+	Action string    `db:"action"`
+	ScanID uuid.UUID `db:"scan_id"`
+
+	CreatedAt time.Time `db:"created_at" goqu:"skipinsert"`
+}
+
+// PgScanAttempt is a row of the scan_attempts table.
+type PgScanAttempt struct {
+	ID                 int64          `db:"id"                   goqu:"skipinsert"`
+	ScanID             uuid.UUID      `db:"scan_id"`
+	Error              sql.NullString `db:"error"`
+	RateLimitLimit     int            `db:"rate_limit_limit"`
+	RateLimitRemaining int            `db:"rate_limit_remaining"`
+	RateLimitResetAt   sql.NullTime   `db:"rate_limit_reset_at"`
+
+	CreatedAt time.Time `db:"created_at" goqu:"skipinsert"`
+}
+
+// PgJob is the subset of river_job columns used to inspect queued jobs.
+type PgJob struct {
+	ID          int64           `db:"id"`
+	Kind        string          `db:"kind"`
+	Args        json.RawMessage `db:"args"`
+	State       string          `db:"state"`
+	Queue       string          `db:"queue"`
+	Attempt     int             `db:"attempt"`
+	MaxAttempts int             `db:"max_attempts"`
+	CreatedAt   time.Time       `db:"created_at"`
+	ScheduledAt time.Time       `db:"scheduled_at"`
+}
+
+// scanResultSchemaVersion is the version of the scan result JSON written by
+// this code. Bump it whenever domain.ScanResult changes in a way that rows of
+// older versions need handling for in unmarshalScanResult.
+//
+// Versions:
+//   - 1: results written before versioning, without a schemaVersion. They may
+//     lack the stats section, and the older ones also the verdict categories
+//     and brands.
+//   - 2: results tagged with their schemaVersion.
+const scanResultSchemaVersion = 2
+
+// pgScanResult is the JSON stored in the result column. The result fields stay
+// at the top level so that queries on them, e.g. result->'verdicts', keep
+// working across versions.
+type pgScanResult struct {
+	SchemaVersion int `json:"schemaVersion,omitempty"`
+	domain.ScanResult
+}
+
+// pgScanResultV1 is the layout of results of schema version 1. It is kept
+// apart from domain.ScanResult so that changes of the latter do not change how
+// version 1 rows are read.
+type pgScanResultV1 struct {
+	Page *struct {
+		URL     string `json:"url"`
+		Domain  string `json:"domain"`
+		IP      string `json:"ip"`
+		ASN     string `json:"asn"`
+		Country string `json:"country"`
+		Server  string `json:"server"`
+	} `json:"page,omitempty"`
+
+	Verdict *struct {
+		Malicious  bool     `json:"malicious"`
+		Score      int      `json:"score"`
+		Categories []string `json:"categories,omitempty"`
+		Brands     []string `json:"brands,omitempty"`
+	} `json:"verdicts,omitempty"`
+
+	Stats *struct {
+		Malicious int `json:"malicious"`
+	} `json:"stats,omitempty"`
+}
+
+// upgrade converts a version 1 result into the current domain.ScanResult.
+// Missing sections, such as stats, stay nil and are reported as unknown rather
+// than as zero.
+func (r pgScanResultV1) upgrade() domain.ScanResult {
+	return domain.ScanResult{
+		Page:    r.Page,
+		Verdict: r.Verdict,
+		Stats:   r.Stats,
+	}
+}
+
+// marshalScanResult encodes result tagged with the current schema version.
+func marshalScanResult(result domain.ScanResult) ([]byte, error) {
+	b, err := json.Marshal(pgScanResult{SchemaVersion: scanResultSchemaVersion, ScanResult: result})
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal scan result: %w", err)
+	}
+
+	return b, nil
+}
+
+// unmarshalScanResult decodes a stored result of any schema version into a
+// domain.ScanResult, upgrading results of older versions. Versions newer than
+// this code are decoded as far as their fields are known, so rows written
+// during a rolling deployment stay readable.
+func unmarshalScanResult(raw json.RawMessage) (domain.ScanResult, error) {
+	if len(raw) == 0 {
+		return domain.ScanResult{}, nil
+	}
+
+	var version struct {
+		SchemaVersion int `json:"schemaVersion"`
+	}
+	if err := json.Unmarshal(raw, &version); err != nil {
+		return domain.ScanResult{}, fmt.Errorf("could not unmarshal scan result version: %w", err)
+	}
+
+	switch version.SchemaVersion {
+	case 0, 1:
+		var stored pgScanResultV1
+		if err := json.Unmarshal(raw, &stored); err != nil {
+			return domain.ScanResult{}, fmt.Errorf("could not unmarshal v1 scan result: %w", err)
+		}
+
+		return stored.upgrade(), nil
+	default:
+		var stored pgScanResult
+		if err := json.Unmarshal(raw, &stored); err != nil {
+			return domain.ScanResult{}, fmt.Errorf("could not unmarshal scan result: %w", err)
+		}
+
+		return stored.ScanResult, nil
+	}
+}
+
 // TODO: use https://github.com/jmattheis/goverter for converting
 
 func (p *PgScan) ToDomain() (*domain.Scan, error) {
-	var result domain.ScanResult
-	if err := json.Unmarshal(p.Result, &result); err != nil {
-		return nil, fmt.Errorf("could not unmarshal scan result: %w", err)
+	result, err := unmarshalScanResult(p.Result)
+	if err != nil {
+		return nil, err
 	}
 
 	return &domain.Scan{
@@ -42,6 +188,11 @@ func (p *PgScan) ToDomain() (*domain.Scan, error) {
 		Result:    result,
 		Attempts:  p.Attempts,
 		LastError: p.LastError.String,
+
+		IdempotencyKey: p.IdempotencyKey.String,
+		Version:        p.Version,
+		Shareable:      p.Shareable,
+
 		CreatedAt: p.CreatedAt,
 		UpdatedAt: p.UpdatedAt.Time,
 		DeletedAt: p.DeletedAt.Time,
@@ -49,13 +200,20 @@ func (p *PgScan) ToDomain() (*domain.Scan, error) {
 }
 
 func (p *PgScan) FromDomain(scan domain.Scan) error {
-	result, err := json.Marshal(scan.Result)
+	result, err := marshalScanResult(scan.Result)
 	if err != nil {
-		return fmt.Errorf("could not marshal scan result: %w", err)
+		return err
+	}
+
+	// new scans get their ID here rather than from the column default so that
+	// inserted rows can be matched to their input
+	id := uuid.UUID(scan.ID)
+	if id == uuid.Nil {
+		id = uuid.New()
 	}
 
 	*p = PgScan{
-		ID:       uuid.UUID(scan.ID),
+		ID:       id,
 		UserID:   uuid.UUID(scan.UserID),
 		URL:      scan.URL,
 		Status:   string(scan.Status),
@@ -65,6 +223,12 @@ func (p *PgScan) FromDomain(scan domain.Scan) error {
 			String: scan.LastError,
 			Valid:  scan.LastError != "",
 		},
+		IdempotencyKey: sql.NullString{
+			String: scan.IdempotencyKey,
+			Valid:  scan.IdempotencyKey != "",
+		},
+		Version:   scan.Version,
+		Shareable: scan.Shareable,
 		CreatedAt: scan.CreatedAt,
 		UpdatedAt: sql.NullTime{
 			Time:  scan.UpdatedAt,
@@ -0,0 +1,108 @@
+package postgres_test
+
+import (
+	"encoding/json"
+	"scanner/pkg/domain"
+	"scanner/pkg/storage/postgres"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPgScan_ToDomain_OldFormatResult(t *testing.T) {
+	// results written before versioning carry no schemaVersion and no stats
+	row := postgres.PgScan{
+		Status: string(domain.ScanStatusCompleted),
+		Result: json.RawMessage(`{"page":{"url":"https://a.com","ip":"1.2.3.4"},"verdicts":{"malicious":true,"score":80}}`),
+	}
+
+	scan, err := row.ToDomain()
+	require.NoError(t, err)
+	require.NotNil(t, scan.Result.Page)
+	require.Equal(t, "https://a.com", scan.Result.Page.URL)
+	require.Equal(t, "1.2.3.4", scan.Result.Page.IP)
+	require.NotNil(t, scan.Result.Verdict)
+	require.True(t, scan.Result.Verdict.Malicious)
+	require.Equal(t, 80, scan.Result.Verdict.Score)
+	require.Nil(t, scan.Result.Stats, "missing stats are unknown rather than zero")
+}
+
+func TestPgScan_ToDomain_LegacyUntaggedResult(t *testing.T) {
+	// untagged results written after verdict categories and brands were added
+	// decode the same as the ones explicitly tagged with version 1
+	for _, raw := range []string{
+		`{"verdicts":{"malicious":true,"score":70,"categories":["phishing"],"brands":["acme"]}}`,
+		`{"schemaVersion":1,"verdicts":{"malicious":true,"score":70,"categories":["phishing"],"brands":["acme"]}}`,
+	} {
+		row := postgres.PgScan{Status: string(domain.ScanStatusCompleted), Result: json.RawMessage(raw)}
+		scan, err := row.ToDomain()
+		require.NoError(t, err, "result %q", raw)
+		require.Nil(t, scan.Result.Page, "result %q", raw)
+		require.NotNil(t, scan.Result.Verdict, "result %q", raw)
+		require.Equal(t, 70, scan.Result.Verdict.Score, "result %q", raw)
+		require.Equal(t, []string{"phishing"}, scan.Result.Verdict.Categories, "result %q", raw)
+		require.Equal(t, []string{"acme"}, scan.Result.Verdict.Brands, "result %q", raw)
+		require.Nil(t, scan.Result.Stats, "result %q", raw)
+	}
+}
+
+func TestPgScan_ToDomain_NewerVersionResult(t *testing.T) {
+	// results of versions newer than this code are decoded as far as known
+	row := postgres.PgScan{
+		Status: string(domain.ScanStatusCompleted),
+		Result: json.RawMessage(`{"schemaVersion":3,"stats":{"malicious":2},"screenshot":{"url":"https://s"}}`),
+	}
+
+	scan, err := row.ToDomain()
+	require.NoError(t, err)
+	require.NotNil(t, scan.Result.Stats)
+	require.Equal(t, 2, scan.Result.Stats.Malicious)
+}
+
+func TestPgScan_ToDomain_InvalidResult(t *testing.T) {
+	row := postgres.PgScan{Status: string(domain.ScanStatusCompleted), Result: json.RawMessage(`{"schemaVersion":"x"}`)}
+	_, err := row.ToDomain()
+	require.Error(t, err)
+}
+
+func TestPgScan_ToDomain_NewFormatResult(t *testing.T) {
+	row := postgres.PgScan{
+		Status: string(domain.ScanStatusCompleted),
+		Result: json.RawMessage(`{"schemaVersion":2,"page":{"url":"https://a.com"},` +
+			`"verdicts":{"malicious":false,"score":0},"stats":{"malicious":3}}`),
+	}
+
+	scan, err := row.ToDomain()
+	require.NoError(t, err)
+	require.Equal(t, "https://a.com", scan.Result.Page.URL)
+	require.False(t, scan.Result.Verdict.Malicious)
+	require.NotNil(t, scan.Result.Stats)
+	require.Equal(t, 3, scan.Result.Stats.Malicious)
+}
+
+func TestPgScan_ToDomain_EmptyResult(t *testing.T) {
+	for _, raw := range []string{"", "{}", "null"} {
+		row := postgres.PgScan{Status: string(domain.ScanStatusPending), Result: json.RawMessage(raw)}
+		scan, err := row.ToDomain()
+		require.NoError(t, err, "result %q", raw)
+		require.Equal(t, domain.ScanResult{}, scan.Result, "result %q", raw)
+	}
+}
+
+func TestPgScan_FromDomain_TagsResultSchemaVersion(t *testing.T) {
+	var result domain.ScanResult
+	require.NoError(t, json.Unmarshal([]byte(`{"stats":{"malicious":1}}`), &result))
+
+	var row postgres.PgScan
+	require.NoError(t, row.FromDomain(domain.Scan{URL: "https://a.com", Result: result}))
+
+	var stored map[string]any
+	require.NoError(t, json.Unmarshal(row.Result, &stored))
+	require.EqualValues(t, 2, stored["schemaVersion"])
+	require.Contains(t, stored, "stats")
+
+	// the tagged result round-trips
+	scan, err := row.ToDomain()
+	require.NoError(t, err)
+	require.Equal(t, result, scan.Result)
+}
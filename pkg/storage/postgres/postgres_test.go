@@ -75,7 +75,7 @@ func runMigrations(db *sql.DB, migrationsDir string) error {
 	return nil
 }
 
-func setupTestDB(t *testing.T) (*postgres.PgSQL, func()) {
+func setupTestDB(t testing.TB) (*postgres.PgSQL, func()) {
 	// TODO: setup a global test db and share between tests
 	t.Helper()
 	ctx := context.Background()
@@ -99,7 +99,8 @@ func setupTestDB(t *testing.T) (*postgres.PgSQL, func()) {
 	})
 	require.NoError(t, err)
 
-	// run migrations
+	// run migrations; river queue first, since app migrations index its tables
+	migrateRiver(t, pgSQL)
 	migrationsDir := filepath.Join("..", "..", "..", "migrations")
 	err = runMigrations(pgSQL.DB.(*sql.DB), migrationsDir)
 	require.NoError(t, err)
@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"scanner/pkg/urlscanner"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+)
+
+const (
+	keyValuesTable = "key_values"
+)
+
+// pgRateLimitStatus is the JSON representation of urlscanner.RateLimitStatus
+// stored in the value column of the key_values table.
+type pgRateLimitStatus struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"resetAt"`
+}
+
+// RateLimitStatus returns the rate-limit status stored under key, or nil when
+// no row exists for the key.
+func (p *PgSQL) RateLimitStatus(ctx context.Context, key string) (_ *urlscanner.RateLimitStatus, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	var row PgKeyValue
+	found, err := p.Builder.From(keyValuesTable).
+		Where(goqu.I("key").Eq(key)).
+		Executor().ScanStructContext(ctx, &row)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch rate limit status from pg: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+
+	var value pgRateLimitStatus
+	if err := json.Unmarshal(row.Value, &value); err != nil {
+		return nil, fmt.Errorf("could not unmarshal rate limit status: %w", err)
+	}
+
+	return &urlscanner.RateLimitStatus{
+		Limit:     value.Limit,
+		Remaining: value.Remaining,
+		ResetAt:   value.ResetAt,
+	}, nil
+}
+
+// StoreRateLimitStatus upserts the rate-limit status under key. An existing
+// value is replaced and its updated_at is refreshed.
+func (p *PgSQL) StoreRateLimitStatus(ctx context.Context, key string, status urlscanner.RateLimitStatus) (err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	value, err := json.Marshal(pgRateLimitStatus{
+		Limit:     status.Limit,
+		Remaining: status.Remaining,
+		ResetAt:   status.ResetAt,
+	})
+	if err != nil {
+		return fmt.Errorf("could not marshal rate limit status: %w", err)
+	}
+
+	_, err = p.Builder.Insert(keyValuesTable).
+		Rows(goqu.Record{"key": key, "value": value}).
+		OnConflict(goqu.DoUpdate("key", goqu.Record{
+			"value":      goqu.I("excluded.value"),
+			"updated_at": goqu.L("CURRENT_TIMESTAMP"),
+		})).
+		Executor().ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("could not store rate limit status into pg: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package postgres_test
+
+import (
+	"context"
+	"scanner/pkg/urlscanner"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPgSQL_RateLimitStatus(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	// missing key returns nil
+	got, err := pgSQL.RateLimitStatus(ctx, "missing")
+	require.NoError(t, err)
+	require.Nil(t, got)
+
+	// insert
+	first := urlscanner.RateLimitStatus{Limit: 100, Remaining: 40, ResetAt: time.Now().UTC().Truncate(time.Second)}
+	require.NoError(t, pgSQL.StoreRateLimitStatus(ctx, "rl", first))
+
+	got, err = pgSQL.RateLimitStatus(ctx, "rl")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Equal(t, first.Limit, got.Limit)
+	require.Equal(t, first.Remaining, got.Remaining)
+	require.True(t, first.ResetAt.Equal(got.ResetAt))
+
+	// upsert replaces the existing value
+	second := urlscanner.RateLimitStatus{Limit: 100, Remaining: 10, ResetAt: first.ResetAt.Add(time.Minute)}
+	require.NoError(t, pgSQL.StoreRateLimitStatus(ctx, "rl", second))
+
+	got, err = pgSQL.RateLimitStatus(ctx, "rl")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Equal(t, second.Remaining, got.Remaining)
+	require.True(t, second.ResetAt.Equal(got.ResetAt))
+}
@@ -0,0 +1,91 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"scanner/pkg/domain"
+	"scanner/pkg/storage"
+	"scanner/pkg/storage/postgres"
+	"testing"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+var errStubDB = errors.New("stub database")
+
+// stubDB is a goqu.SQLDatabase recording the queries it receives, along with
+// the arguments of the read ones, and failing every one of them.
+type stubDB struct {
+	queries []string
+	args    [][]any
+}
+
+func (s *stubDB) Begin() (*sql.Tx, error) { return nil, errStubDB }
+
+func (s *stubDB) BeginTx(context.Context, *sql.TxOptions) (*sql.Tx, error) { return nil, errStubDB }
+
+func (s *stubDB) ExecContext(_ context.Context, query string, _ ...any) (sql.Result, error) {
+	s.queries = append(s.queries, query)
+
+	return nil, errStubDB
+}
+
+func (s *stubDB) PrepareContext(_ context.Context, query string) (*sql.Stmt, error) {
+	s.queries = append(s.queries, query)
+
+	return nil, errStubDB
+}
+
+func (s *stubDB) QueryContext(_ context.Context, query string, args ...any) (*sql.Rows, error) {
+	s.queries = append(s.queries, query)
+	s.args = append(s.args, args)
+
+	return nil, errStubDB
+}
+
+func (s *stubDB) QueryRowContext(_ context.Context, query string, args ...any) *sql.Row {
+	s.queries = append(s.queries, query)
+	s.args = append(s.args, args)
+
+	return nil
+}
+
+func TestPgSQL_Reader(t *testing.T) {
+	primary := &stubDB{}
+	reader := &stubDB{}
+	pg := &postgres.PgSQL{Builder: goqu.Dialect("postgres").DB(primary)}
+
+	// without a replica reads use the primary
+	require.Equal(t, pg.Builder, pg.Reader())
+
+	pg.ReadBuilder = goqu.Dialect("postgres").DB(reader)
+	require.Equal(t, pg.ReadBuilder, pg.Reader())
+
+	ctx := context.Background()
+	userID := domain.UserID(uuid.New())
+
+	_, err := pg.ScanByID(ctx, userID, domain.ScanID(uuid.New()))
+	require.ErrorIs(t, err, errStubDB)
+	_, err = pg.UserScans(ctx, userID, "", false, time.Time{}, storage.PageNext, 10)
+	require.ErrorIs(t, err, errStubDB)
+	ids := []domain.ScanID{domain.ScanID(uuid.New()), domain.ScanID(uuid.New())}
+	_, err = pg.ScansByIDs(ctx, userID, ids)
+	require.ErrorIs(t, err, errStubDB)
+
+	require.Len(t, reader.queries, 3)
+	require.Empty(t, primary.queries, "reads must not hit the primary")
+	// the IDs are bound as parameters rather than spliced into the query
+	require.Contains(t, reader.queries[2], `id = ANY($1::UUID[])`)
+	require.Equal(t, fmt.Sprintf(`{"%s","%s"}`, uuid.UUID(ids[0]), uuid.UUID(ids[1])), reader.args[2][0])
+
+	// writes always go to the primary
+	_, err = pg.DeleteScan(ctx, userID, domain.ScanID(uuid.New()))
+	require.Error(t, err)
+	require.Len(t, primary.queries, 1)
+	require.Len(t, reader.queries, 3)
+}
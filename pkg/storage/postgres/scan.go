@@ -2,21 +2,30 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"scanner/pkg/domain"
+	"scanner/pkg/serrors"
 	"scanner/pkg/storage"
+	"slices"
 	"time"
 
 	"github.com/doug-martin/goqu/v9"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const (
 	scansTable = "scans"
 )
 
-func (p *PgSQL) StoreScans(ctx context.Context, scans ...domain.Scan) ([]domain.Scan, error) {
+// StoreScans inserts the scans in a single statement and returns the stored
+// scans in the order of the input.
+func (p *PgSQL) StoreScans(ctx context.Context, scans ...domain.Scan) (_ []domain.Scan, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
 	if len(scans) == 0 {
 		return nil, nil
 	}
@@ -34,13 +43,64 @@ func (p *PgSQL) StoreScans(ctx context.Context, scans ...domain.Scan) ([]domain.
 		return nil, fmt.Errorf("could not store scans into pg: %w", err)
 	}
 
-	return pgScansToDomain(result)
+	// RETURNING does not guarantee the order of the rows, so match them to
+	// the input by their ID
+	position := make(map[uuid.UUID]int, len(pgScans))
+	for i, scan := range pgScans {
+		position[scan.ID] = i
+	}
+	ordered := make([]PgScan, len(pgScans))
+	for _, row := range result {
+		ordered[position[row.ID]] = row
+	}
+
+	return pgScansToDomain(ordered)
+}
+
+// UpsertScan inserts scan with ON CONFLICT DO NOTHING so that concurrent
+// requests sharing an idempotency key cannot store duplicates. On conflict, the
+// scan holding the key is fetched and returned with created set to false.
+func (p *PgSQL) UpsertScan(ctx context.Context, scan domain.Scan) (_ *domain.Scan, created bool, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	var row PgScan
+	if err := row.FromDomain(scan); err != nil {
+		return nil, false, err
+	}
+
+	found, err := p.Builder.Insert(scansTable).
+		Rows(row).
+		OnConflict(goqu.DoNothing()).
+		Returning(&PgScan{}).
+		Executor().ScanStructContext(ctx, &row)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not upsert scan into pg: %w", err)
+	}
+	if found {
+		stored, err := row.ToDomain()
+
+		return stored, true, err
+	}
+
+	existing, err := p.scanByIdempotencyKey(ctx, scan.UserID, scan.IdempotencyKey)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing == nil {
+		// the conflicting scan was deleted before it could be read
+		return nil, false, serrors.With(serrors.ErrConflict, "scan with idempotency key %q was deleted concurrently",
+			scan.IdempotencyKey)
+	}
+
+	return existing, false, nil
 }
 
 func getScanUpdates(updates storage.ScanUpdates) (goqu.Record, error) {
 	rec := goqu.Record{
 		"updated_at": goqu.L("CURRENT_TIMESTAMP"),
 		"attempts":   goqu.L("attempts + 1"),
+		"version":    goqu.L("version + 1"),
 	}
 	// Status handling:
 	// - For Completed (or any non-Failed status), set directly.
@@ -54,12 +114,17 @@ func getScanUpdates(updates storage.ScanUpdates) (goqu.Record, error) {
 		}
 	}
 	if updates.Result != nil {
-		b, err := json.Marshal(updates.Result)
+		b, err := marshalScanResult(*updates.Result)
 		if err != nil {
-			return nil, fmt.Errorf("could not marshal result: %w", err)
+			return nil, err
 		}
 
-		rec["result"] = b
+		if updates.MergeResult {
+			// see migrations/00011_create_jsonb_deep_merge_function.sql
+			rec["result"] = goqu.L("jsonb_deep_merge(result, ?::jsonb)", string(b))
+		} else {
+			rec["result"] = b
+		}
 	}
 	if updates.LastError != nil {
 		if *updates.LastError == "" {
@@ -78,32 +143,51 @@ func getScanUpdates(updates storage.ScanUpdates) (goqu.Record, error) {
 // Special handling: when updates.Status is Failed and updates.MaxAttempts > 0,
 // status is only set to Failed if attempts after increment would exceed MaxAttempts;
 // otherwise status remains unchanged (i.e., stays Pending).
-func (p *PgSQL) UpdatePendingScansByURL(ctx context.Context, URL string, updates storage.ScanUpdates) error {
+// The number of updated rows is returned.
+func (p *PgSQL) UpdatePendingScansByURL(
+	ctx context.Context,
+	URL string,
+	owner domain.UserID,
+	updates storage.ScanUpdates,
+) (_ int64, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
 	updateRec, err := getScanUpdates(updates)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	_, err = p.Builder.Update(scansTable).
-		Set(updateRec).Where(
-		goqu.I("url").Eq(URL),
-		goqu.I("status").Eq(string(domain.ScanStatusPending)),
-		goqu.I("deleted_at").IsNull(),
-	).Executor().ExecContext(ctx)
+	res, err := p.Builder.Update(scansTable).
+		Set(updateRec).
+		Where(pendingScansOfJob(URL, owner)...).
+		Executor().ExecContext(ctx)
 	if err != nil {
-		return fmt.Errorf("could not update pending scans by url in pg: %w", err)
+		return 0, fmt.Errorf("could not update pending scans by url in pg: %w", err)
 	}
 
-	return nil
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("could not get updated pending scans count: %w", err)
+	}
+
+	return updated, nil
 }
 
 // DeleteScan performs a soft delete by setting deleted_at timestamp
-// for a given scan id and user, returning the deleted record.
-func (p *PgSQL) DeleteScan(ctx context.Context, userID domain.UserID, id domain.ScanID) (*domain.Scan, error) {
+// for a given scan id and user, returning the deleted record. Pending scans are
+// marked as canceled in the same statement, terminal statuses are kept.
+func (p *PgSQL) DeleteScan(ctx context.Context, userID domain.UserID, id domain.ScanID) (_ *domain.Scan, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
 	var row PgScan
 	found, err := p.Builder.Update(scansTable).
 		Set(goqu.Record{
 			"deleted_at": goqu.L("CURRENT_TIMESTAMP"),
+			"status": goqu.L("CASE WHEN status = ? THEN ? ELSE status END",
+				string(domain.ScanStatusPending), string(domain.ScanStatusCanceled)),
+			"version": goqu.L("version + 1"),
 		}).Where(
 		goqu.I("id").Eq(uuid.UUID(id)),
 		goqu.I("user_id").Eq(uuid.UUID(userID)),
@@ -121,11 +205,19 @@ func (p *PgSQL) DeleteScan(ctx context.Context, userID domain.UserID, id domain.
 
 // UserScans returns a list of scans for a user filtered by optional cursor and limited by limit.
 // Results are ordered by created_at DESC, id DESC. Returns next and previous cursors for pagination.
+// With storage.PagePrev, the scans created after the cursor are fetched in ascending order and
+// reversed, so that the page directly preceding the cursor is returned. It reads from the read
+// replica when one is configured.
 func (p *PgSQL) UserScans(ctx context.Context,
 	userID domain.UserID,
 	status domain.ScanStatus,
+	maliciousOnly bool,
 	cursor time.Time,
-	limit uint) (storage.UserScans, error) {
+	direction storage.PageDirection,
+	limit uint) (_ storage.UserScans, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
 	w := []goqu.Expression{
 		goqu.I("user_id").Eq(uuid.UUID(userID)),
 		goqu.I("deleted_at").IsNull(),
@@ -133,29 +225,131 @@ func (p *PgSQL) UserScans(ctx context.Context,
 	if status != "" {
 		w = append(w, goqu.I("status").Eq(string(status)))
 	}
+	if maliciousOnly {
+		w = append(w, goqu.L("result->'verdicts'->>'malicious' = 'true'"))
+	}
+
+	if direction == storage.PagePrev && !cursor.IsZero() {
+		w = append(w, goqu.I("created_at").Gt(cursor))
+		page, err := prevScanPage(ctx, p.Reader(), w, limit)
+		if err != nil {
+			return storage.UserScans{}, fmt.Errorf("could not fetch user scans from pg: %w", err)
+		}
+
+		return page, nil
+	}
+
+	if !cursor.IsZero() {
+		w = append(w, goqu.I("created_at").Lt(cursor))
+	}
+	page, err := scanPage(ctx, p.Reader(), w, limit)
+	if err != nil {
+		return storage.UserScans{}, fmt.Errorf("could not fetch user scans from pg: %w", err)
+	}
+	// scans created after the cursor come before this page
+	if !cursor.IsZero() && len(page.Scans) > 0 {
+		page.PrevCursor = &page.Scans[0].CreatedAt
+	}
+
+	return page, nil
+}
+
+// SearchScans returns a page of the user's scans whose result page has the
+// given value for key, using the GIN index on result through containment.
+// Pagination works the same as in UserScans. It reads from the read replica
+// when one is configured.
+func (p *PgSQL) SearchScans(ctx context.Context,
+	userID domain.UserID,
+	key domain.ScanSearchKey,
+	value string,
+	cursor time.Time,
+	limit uint) (_ storage.UserScans, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	if !key.Valid() {
+		return storage.UserScans{}, serrors.With(serrors.ErrBadRequest, "unknown search key %q", key)
+	}
+	contains, err := json.Marshal(map[string]map[string]string{"page": {string(key): value}})
+	if err != nil {
+		return storage.UserScans{}, fmt.Errorf("could not marshal search: %w", err)
+	}
+
+	w := []goqu.Expression{
+		goqu.I("user_id").Eq(uuid.UUID(userID)),
+		goqu.I("deleted_at").IsNull(),
+		goqu.L("result @> ?::jsonb", string(contains)),
+	}
 	if !cursor.IsZero() {
 		w = append(w, goqu.I("created_at").Lt(cursor))
 	}
 
+	page, err := scanPage(ctx, p.Reader(), w, limit)
+	if err != nil {
+		return storage.UserScans{}, fmt.Errorf("could not search scans in pg: %w", err)
+	}
+
+	return page, nil
+}
+
+// scanPage fetches up to limit scans matching w from b, newest first. When
+// more scans match, NextCursor is set to the creation time of the last one.
+func scanPage(ctx context.Context, b Builder, w []goqu.Expression, limit uint) (storage.UserScans, error) {
 	// fetch one extra to determine if there is a next page
-	fetch := limit + 1
-	ds := p.Builder.From(scansTable).
+	ds := b.From(scansTable).
 		Where(w...).
 		Order(goqu.I("created_at").Desc(), goqu.I("id").Desc()).
-		Limit(fetch)
+		Limit(limit + 1)
 
 	var rows []PgScan
 	if err := ds.Executor().ScanStructsContext(ctx, &rows); err != nil {
-		return storage.UserScans{}, fmt.Errorf("could not fetch user scans from pg: %w", err)
+		return storage.UserScans{}, err //nolint: wrapcheck
 	}
 
 	// if we fetched more than the limit, there is a next page
 	var nextCursor *time.Time
 	if uint(len(rows)) > limit {
-		trimmed := rows[:limit]
-		nextCursor = &trimmed[len(trimmed)-1].CreatedAt
-		rows = trimmed
+		rows = rows[:limit]
+		nextCursor = &rows[len(rows)-1].CreatedAt
+	}
+
+	domainRows, err := pgScansToDomain(rows)
+	if err != nil {
+		return storage.UserScans{}, err
+	}
+
+	return storage.UserScans{
+		Scans:      domainRows,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// prevScanPage fetches up to limit scans matching w from b that directly
+// follow the cursor in w, i.e. the oldest ones, and returns them newest first.
+// When more scans match, PrevCursor is set to the creation time of the newest
+// one. NextCursor is set to the creation time of the oldest one, as the scans
+// before the cursor follow this page.
+func prevScanPage(ctx context.Context, b Builder, w []goqu.Expression, limit uint) (storage.UserScans, error) {
+	// fetch one extra to determine if there is a previous page
+	ds := b.From(scansTable).
+		Where(w...).
+		Order(goqu.I("created_at").Asc(), goqu.I("id").Asc()).
+		Limit(limit + 1)
+
+	var rows []PgScan
+	if err := ds.Executor().ScanStructsContext(ctx, &rows); err != nil {
+		return storage.UserScans{}, err //nolint: wrapcheck
+	}
+
+	var prevCursor, nextCursor *time.Time
+	if uint(len(rows)) > limit {
+		rows = rows[:limit]
+		prevCursor = &rows[len(rows)-1].CreatedAt
+	}
+	if len(rows) > 0 {
+		nextCursor = &rows[0].CreatedAt
 	}
+	slices.Reverse(rows)
 
 	domainRows, err := pgScansToDomain(rows)
 	if err != nil {
@@ -165,13 +359,51 @@ func (p *PgSQL) UserScans(ctx context.Context,
 	return storage.UserScans{
 		Scans:      domainRows,
 		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
 	}, nil
 }
 
-// ScanByID returns a scan by its ID, excluding soft-deleted rows.
-func (p *PgSQL) ScanByID(ctx context.Context, userID domain.UserID, id domain.ScanID) (*domain.Scan, error) {
+// AdminListScans returns a page of scans across all users matching filter,
+// excluding soft-deleted rows. Pagination works the same as in UserScans.
+func (p *PgSQL) AdminListScans(ctx context.Context, filter storage.AdminScanFilter) (_ storage.UserScans, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	w := []goqu.Expression{
+		goqu.I("deleted_at").IsNull(),
+	}
+	if filter.Status != "" {
+		w = append(w, goqu.I("status").Eq(string(filter.Status)))
+	}
+	if filter.URL != "" {
+		w = append(w, goqu.I("url").Eq(filter.URL))
+	}
+	if !filter.CreatedAfter.IsZero() {
+		w = append(w, goqu.I("created_at").Gte(filter.CreatedAfter))
+	}
+	if !filter.CreatedBefore.IsZero() {
+		w = append(w, goqu.I("created_at").Lt(filter.CreatedBefore))
+	}
+	if !filter.Cursor.IsZero() {
+		w = append(w, goqu.I("created_at").Lt(filter.Cursor))
+	}
+
+	page, err := scanPage(ctx, p.Builder, w, filter.Limit)
+	if err != nil {
+		return storage.UserScans{}, fmt.Errorf("could not fetch scans from pg: %w", err)
+	}
+
+	return page, nil
+}
+
+// ScanByID returns a scan by its ID, excluding soft-deleted rows. It reads
+// from the read replica when one is configured.
+func (p *PgSQL) ScanByID(ctx context.Context, userID domain.UserID, id domain.ScanID) (_ *domain.Scan, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
 	var row PgScan
-	found, err := p.Builder.From(scansTable).
+	found, err := p.Reader().From(scansTable).
 		Where(
 			goqu.I("id").Eq(uuid.UUID(id)),
 			goqu.I("user_id").Eq(uuid.UUID(userID)),
@@ -188,28 +420,186 @@ func (p *PgSQL) ScanByID(ctx context.Context, userID domain.UserID, id domain.Sc
 	return row.ToDomain()
 }
 
+// AdminScanByID returns a scan by its ID for any user, excluding soft-deleted rows.
+func (p *PgSQL) AdminScanByID(ctx context.Context, id domain.ScanID) (_ *domain.Scan, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	var row PgScan
+	found, err := p.Builder.From(scansTable).
+		Where(
+			goqu.I("id").Eq(uuid.UUID(id)),
+			goqu.I("deleted_at").IsNull(),
+		).
+		Executor().ScanStructContext(ctx, &row)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch scan by id: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+
+	return row.ToDomain()
+}
+
 // UpdateScanByID updates a single scan by its ID and returns the updated record.
 // Only provided fields are updated; updated_at is set automatically. Soft-deleted rows are ignored.
+// With updates.ExpectedVersion set, only the matching version is updated and a
+// conflict error is returned on mismatch.
 func (p *PgSQL) UpdateScanByID(
 	ctx context.Context,
 	id domain.ScanID,
+	updates storage.ScanUpdates) (_ *domain.Scan, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	return p.updateScan(ctx, nil, id, updates)
+}
+
+// UpdateScanByIDForUser works like UpdateScanByID but only updates the scan
+// when it belongs to userID; scans of other users are treated as missing.
+func (p *PgSQL) UpdateScanByIDForUser(
+	ctx context.Context,
+	userID domain.UserID,
+	id domain.ScanID,
+	updates storage.ScanUpdates) (_ *domain.Scan, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	return p.updateScan(ctx, &userID, id, updates)
+}
+
+// updateScan implements UpdateScanByID, scoped to the scans of userID when it
+// is non-nil.
+func (p *PgSQL) updateScan(
+	ctx context.Context,
+	userID *domain.UserID,
+	id domain.ScanID,
 	updates storage.ScanUpdates) (*domain.Scan, error) {
 	updateRec, err := getScanUpdates(updates)
 	if err != nil {
 		return nil, err
 	}
 
-	var row PgScan
-	found, err := p.Builder.Update(scansTable).
-		Set(updateRec).Where(
+	w := []goqu.Expression{
 		goqu.I("id").Eq(uuid.UUID(id)),
 		goqu.I("deleted_at").IsNull(),
-	).
+	}
+	if userID != nil {
+		w = append(w, goqu.I("user_id").Eq(uuid.UUID(*userID)))
+	}
+	if updates.ExpectedVersion > 0 {
+		w = append(w, goqu.I("version").Eq(updates.ExpectedVersion))
+	}
+
+	var row PgScan
+	found, err := p.Builder.Update(scansTable).
+		Set(updateRec).Where(w...).
 		Returning(&PgScan{}).
 		Executor().ScanStructContext(ctx, &row)
 	if err != nil {
 		return nil, fmt.Errorf("could not update scan by id in pg: %w", err)
 	}
+	if !found {
+		if updates.ExpectedVersion > 0 {
+			// distinguish a missing scan from a concurrent modification
+			exists, err := p.scanExists(ctx, userID, id)
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				return nil, serrors.Wrap(serrors.ErrConflict, storage.ErrVersionMismatch, "scan was modified concurrently")
+			}
+		}
+
+		return nil, nil
+	}
+
+	return row.ToDomain()
+}
+
+// ScansByIDs returns the user's scans with the given IDs, excluding soft-deleted rows.
+// The IDs are sent as bound parameters and the scans are read from the read replica when
+// one is configured.
+func (p *PgSQL) ScansByIDs(ctx context.Context, userID domain.UserID, ids []domain.ScanID) (_ []domain.Scan, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	if len(ids) == 0 {
+		return []domain.Scan{}, nil
+	}
+
+	uuids := make([]string, 0, len(ids))
+	for _, id := range ids {
+		uuids = append(uuids, uuid.UUID(id).String())
+	}
+
+	var rows []PgScan
+	if err := p.Reader().From(scansTable).
+		Prepared(true).
+		Where(
+			// a single array parameter keeps the statement the same for any
+			// number of IDs
+			goqu.L("id = ANY(?::UUID[])", pq.Array(uuids)),
+			goqu.I("user_id").Eq(uuid.UUID(userID)),
+			goqu.I("deleted_at").IsNull(),
+		).
+		Executor().ScanStructsContext(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("could not fetch scans by ids from pg: %w", err)
+	}
+
+	return pgScansToDomain(rows)
+}
+
+// ScanExists reports whether a non-deleted scan with the given ID exists for any user.
+func (p *PgSQL) ScanExists(ctx context.Context, id domain.ScanID) (_ bool, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	return p.scanExists(ctx, nil, id)
+}
+
+// scanExists implements ScanExists, scoped to the scans of userID when it is
+// non-nil.
+func (p *PgSQL) scanExists(ctx context.Context, userID *domain.UserID, id domain.ScanID) (bool, error) {
+	w := []goqu.Expression{
+		goqu.I("id").Eq(uuid.UUID(id)),
+		goqu.I("deleted_at").IsNull(),
+	}
+	if userID != nil {
+		w = append(w, goqu.I("user_id").Eq(uuid.UUID(*userID)))
+	}
+
+	count, err := p.Builder.From(scansTable).Where(w...).CountContext(ctx)
+	if err != nil {
+		return false, fmt.Errorf("could not check scan existence in pg: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// ScanByIdempotencyKey returns the user's non-deleted scan created with the given idempotency key.
+func (p *PgSQL) ScanByIdempotencyKey(ctx context.Context, userID domain.UserID, key string) (_ *domain.Scan, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	return p.scanByIdempotencyKey(ctx, userID, key)
+}
+
+// scanByIdempotencyKey implements ScanByIdempotencyKey without bounding ctx,
+// for use by methods that already did.
+func (p *PgSQL) scanByIdempotencyKey(ctx context.Context, userID domain.UserID, key string) (*domain.Scan, error) {
+	var row PgScan
+	found, err := p.Builder.From(scansTable).
+		Where(
+			goqu.I("user_id").Eq(uuid.UUID(userID)),
+			goqu.I("idempotency_key").Eq(key),
+			goqu.I("deleted_at").IsNull(),
+		).
+		Executor().ScanStructContext(ctx, &row)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch scan by idempotency key: %w", err)
+	}
 	if !found {
 		return nil, nil
 	}
@@ -217,19 +607,23 @@ func (p *PgSQL) UpdateScanByID(
 	return row.ToDomain()
 }
 
-// LastCompletedScanByURL returns the latest completed scan for a URL across all users.
-func (p *PgSQL) LastCompletedScanByURL(ctx context.Context, URL string) (*domain.Scan, error) {
+// LatestScanByURLForUser returns the most recently created, non-deleted scan of a URL owned by the user.
+func (p *PgSQL) LatestScanByURLForUser(ctx context.Context, userID domain.UserID, URL string) (_ *domain.Scan, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
 	var row PgScan
 	found, err := p.Builder.From(scansTable).
 		Where(
+			goqu.I("user_id").Eq(uuid.UUID(userID)),
 			goqu.I("url").Eq(URL),
-			goqu.I("status").Eq(string(domain.ScanStatusCompleted)),
+			goqu.I("deleted_at").IsNull(),
 		).
 		Order(goqu.I("created_at").Desc(), goqu.I("id").Desc()).
 		Limit(1).
 		Executor().ScanStructContext(ctx, &row)
 	if err != nil {
-		return nil, fmt.Errorf("could not fetch last completed scan from pg: %w", err)
+		return nil, fmt.Errorf("could not fetch latest scan by url from pg: %w", err)
 	}
 	if !found {
 		return nil, nil
@@ -238,14 +632,112 @@ func (p *PgSQL) LastCompletedScanByURL(ctx context.Context, URL string) (*domain
 	return row.ToDomain()
 }
 
-// PendingScanCountByURL returns the number of pending, non-deleted scans for the URL across all users.
-func (p *PgSQL) PendingScanCountByURL(ctx context.Context, URL string) (int64, error) {
-	count, err := p.Builder.From(scansTable).
+// LastCompletedScanByURL returns the latest completed scan for a URL that is
+// either shareable or owned by userID, so private results are never reused
+// across users.
+func (p *PgSQL) LastCompletedScanByURL(ctx context.Context, userID domain.UserID, URL string) (_ *domain.Scan, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	return p.lastCompletedScanByURL(ctx, URL, goqu.Or(
+		goqu.I("shareable").IsTrue(),
+		goqu.I("user_id").Eq(uuid.UUID(userID)),
+	))
+}
+
+// LastScanByURL returns the latest processed scan for a URL in any terminal
+// status that is either shareable or owned by userID, like
+// LastCompletedScanByURL; see processedTerminalScan.
+func (p *PgSQL) LastScanByURL(ctx context.Context, userID domain.UserID, URL string) (_ *domain.Scan, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	return p.lastScanByURL(ctx, URL,
+		processedTerminalScan(),
+		goqu.Or(
+			goqu.I("shareable").IsTrue(),
+			goqu.I("user_id").Eq(uuid.UUID(userID)),
+		))
+}
+
+// LastCompletedScanByURLForUser returns the latest completed scan for a URL
+// owned by userID, ignoring scans of other users even when shareable.
+func (p *PgSQL) LastCompletedScanByURLForUser(
+	ctx context.Context,
+	userID domain.UserID,
+	URL string,
+) (_ *domain.Scan, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	return p.lastCompletedScanByURL(ctx, URL, goqu.I("user_id").Eq(uuid.UUID(userID)))
+}
+
+// LastScanByURLForUser returns the latest processed scan for a URL in any
+// terminal status owned by userID, like LastCompletedScanByURLForUser; see
+// processedTerminalScan.
+func (p *PgSQL) LastScanByURLForUser(
+	ctx context.Context,
+	userID domain.UserID,
+	URL string,
+) (_ *domain.Scan, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	return p.lastScanByURL(ctx, URL,
+		processedTerminalScan(),
+		goqu.I("user_id").Eq(uuid.UUID(userID)))
+}
+
+// processedTerminalScan matches the non-pending scans with at least one
+// recorded attempt. Scans finalized without being processed, e.g. failed by
+// reusing an earlier failure, are skipped, so that their later updated_at does
+// not stand in for the time the URL was actually scanned.
+func processedTerminalScan() goqu.Expression {
+	return goqu.And(
+		goqu.I("status").Neq(string(domain.ScanStatusPending)),
+		goqu.L("EXISTS (SELECT 1 FROM "+scanAttemptsTable+" WHERE scan_id = "+scansTable+".id)"),
+	)
+}
+
+// lastCompletedScanByURL returns the latest completed, non-deleted scan for a
+// URL that also matches owner, or nil when there is none.
+func (p *PgSQL) lastCompletedScanByURL(ctx context.Context, URL string, owner goqu.Expression) (*domain.Scan, error) {
+	return p.lastScanByURL(ctx, URL, goqu.I("status").Eq(string(domain.ScanStatusCompleted)), owner)
+}
+
+// lastScanByURL returns the latest non-deleted scan for a URL that matches
+// both status and owner, or nil when there is none.
+func (p *PgSQL) lastScanByURL(ctx context.Context, URL string, status, owner goqu.Expression) (*domain.Scan, error) {
+	var row PgScan
+	found, err := p.Builder.From(scansTable).
 		Where(
 			goqu.I("url").Eq(URL),
-			goqu.I("status").Eq(string(domain.ScanStatusPending)),
+			status,
 			goqu.I("deleted_at").IsNull(),
+			owner,
 		).
+		Order(goqu.I("created_at").Desc(), goqu.I("id").Desc()).
+		Limit(1).
+		Executor().ScanStructContext(ctx, &row)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch last scan by url from pg: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+
+	return row.ToDomain()
+}
+
+// PendingScanCountByURL returns the number of pending, non-deleted scans of
+// the URL that the job of owner scans; see pendingScansOfJob.
+func (p *PgSQL) PendingScanCountByURL(ctx context.Context, URL string, owner domain.UserID) (_ int64, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	count, err := p.Builder.From(scansTable).
+		Where(pendingScansOfJob(URL, owner)...).
 		CountContext(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("could not count pending scans by url in pg: %w", err)
@@ -253,3 +745,120 @@ func (p *PgSQL) PendingScanCountByURL(ctx context.Context, URL string) (int64, e
 
 	return count, nil
 }
+
+// pendingScansOfJob returns the conditions selecting the pending, non-deleted
+// scans of URL that a job of owner scans: the non-shareable scans of the owner
+// when set, the shareable scans of all users otherwise.
+func pendingScansOfJob(URL string, owner domain.UserID) []goqu.Expression {
+	conds := []goqu.Expression{
+		goqu.I("url").Eq(URL),
+		goqu.I("status").Eq(string(domain.ScanStatusPending)),
+		goqu.I("deleted_at").IsNull(),
+	}
+	if owner.IsZero() {
+		return append(conds, goqu.I("shareable").IsTrue())
+	}
+
+	return append(conds, goqu.I("user_id").Eq(uuid.UUID(owner)), goqu.I("shareable").IsFalse())
+}
+
+// PendingScanCountByUser counts the pending, non-deleted scans of a user. In a
+// transaction, a transaction-scoped advisory lock keyed by the user is taken
+// first, so a concurrent transaction counting for the same user waits until
+// this one ends and then sees its scans.
+func (p *PgSQL) PendingScanCountByUser(ctx context.Context, userID domain.UserID) (_ int64, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	if _, ok := p.DB.(*sql.Tx); ok {
+		if _, err := p.DB.ExecContext(ctx,
+			"SELECT pg_advisory_xact_lock(hashtextextended($1, 0))", uuid.UUID(userID).String()); err != nil {
+			return 0, fmt.Errorf("could not lock pending scans of user in pg: %w", err)
+		}
+	}
+
+	count, err := p.Builder.From(scansTable).
+		Where(
+			goqu.I("user_id").Eq(uuid.UUID(userID)),
+			goqu.I("status").Eq(string(domain.ScanStatusPending)),
+			goqu.I("deleted_at").IsNull(),
+		).
+		CountContext(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("could not count pending scans by user in pg: %w", err)
+	}
+
+	return count, nil
+}
+
+// StalePendingScans returns up to limit pending, non-deleted scans of all
+// users last updated before olderThan, oldest first, continuing after the
+// given scan when it is not nil.
+func (p *PgSQL) StalePendingScans(
+	ctx context.Context,
+	olderThan time.Time,
+	after *domain.Scan,
+	limit uint,
+) (_ []domain.Scan, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	// scans that were never updated have no updated_at and are as old as they
+	// were created
+	lastUpdate := goqu.COALESCE(goqu.I("updated_at"), goqu.I("created_at"))
+	w := []goqu.Expression{
+		goqu.I("status").Eq(string(domain.ScanStatusPending)),
+		lastUpdate.Lt(olderThan),
+		goqu.I("deleted_at").IsNull(),
+	}
+	if after != nil {
+		afterUpdate := after.UpdatedAt
+		if afterUpdate.IsZero() {
+			afterUpdate = after.CreatedAt
+		}
+		// scans last updated at the same time are ordered by their ID
+		w = append(w, goqu.Or(
+			lastUpdate.Gt(afterUpdate),
+			goqu.And(lastUpdate.Eq(afterUpdate), goqu.I("id").Gt(uuid.UUID(after.ID))),
+		))
+	}
+
+	var rows []PgScan
+	if err := p.Builder.From(scansTable).
+		Where(w...).
+		Order(lastUpdate.Asc(), goqu.I("id").Asc()).
+		Limit(limit).
+		Executor().ScanStructsContext(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("could not fetch stale pending scans from pg: %w", err)
+	}
+
+	return pgScansToDomain(rows)
+}
+
+// ScanStatusCounts counts the non-deleted scans of a user grouped by status.
+func (p *PgSQL) ScanStatusCounts(ctx context.Context, userID domain.UserID) (_ map[domain.ScanStatus]int64, err error) {
+	ctx, done := p.queryContext(ctx)
+	defer done(&err)
+
+	var rows []struct {
+		Status string `db:"status"`
+		Count  int64  `db:"count"`
+	}
+	if err := p.Reader().From(scansTable).
+		Select(goqu.I("status"), goqu.COUNT(goqu.Star()).As("count")).
+		Where(
+			goqu.I("user_id").Eq(uuid.UUID(userID)),
+			goqu.I("deleted_at").IsNull(),
+		).
+		GroupBy(goqu.I("status")).
+		ScanStructsContext(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("could not count scans by status in pg: %w", err)
+	}
+
+	counts := make(map[domain.ScanStatus]int64, len(rows))
+	for _, row := range rows {
+		counts[domain.ScanStatus(row.Status)] = row.Count
+	}
+
+	return counts, nil
+}
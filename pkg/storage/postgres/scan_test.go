@@ -2,8 +2,12 @@ package postgres_test
 
 import (
 	"context"
+	"fmt"
 	"scanner/pkg/domain"
+	"scanner/pkg/serrors"
 	"scanner/pkg/storage"
+	"scanner/pkg/storage/postgres"
+	"strings"
 	"testing"
 	"time"
 
@@ -95,10 +99,22 @@ func TestPgSQL_UpdatePendingScansByURL(t *testing.T) {
 		Result:    &domain.ScanResult{},
 		LastError: &empty, // clear last_error to NULL
 	}
-	require.NoError(t, pgSQL.UpdatePendingScansByURL(ctx, urlA, u))
+	updated, err := pgSQL.UpdatePendingScansByURL(ctx, urlA, userID, u)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, updated)
+
+	// repeating the update is a no-op since the scans are no longer pending
+	updated, err = pgSQL.UpdatePendingScansByURL(ctx, urlA, userID, u)
+	require.NoError(t, err)
+	require.Zero(t, updated)
+
+	// URLs without pending scans update nothing
+	updated, err = pgSQL.UpdatePendingScansByURL(ctx, "https://no.such/url", userID, u)
+	require.NoError(t, err)
+	require.Zero(t, updated)
 
 	// fetch all user scans and validate
-	page, err := pgSQL.UserScans(ctx, userID, "", time.Time{}, 50)
+	page, err := pgSQL.UserScans(ctx, userID, "", false, time.Time{}, storage.PageNext, 50)
 	require.NoError(t, err)
 
 	// build index by id
@@ -146,8 +162,9 @@ func TestPgSQL_UpdatePendingScansByURL_FailedWithMaxAttempts(t *testing.T) {
 
 	// perform 3 updates; first 2 should keep status pending, 3th should fail
 	for i := 1; i <= 3; i++ {
-		require.NoError(t, pgSQL.UpdatePendingScansByURL(ctx, urlA, updates))
-		page, err := pgSQL.UserScans(ctx, userID, "", time.Time{}, 10)
+		_, err := pgSQL.UpdatePendingScansByURL(ctx, urlA, userID, updates)
+		require.NoError(t, err)
+		page, err := pgSQL.UserScans(ctx, userID, "", false, time.Time{}, storage.PageNext, 10)
 		require.NoError(t, err)
 		require.Len(t, page.Scans, 1)
 		sc := page.Scans[0]
@@ -161,6 +178,57 @@ func TestPgSQL_UpdatePendingScansByURL_FailedWithMaxAttempts(t *testing.T) {
 	}
 }
 
+func TestPgSQL_UpdatePendingScansByURL_Visibility(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	// userA scans the URL privately, userB publicly, userC privately as well
+	userA := domain.UserID(uuid.New())
+	userB := domain.UserID(uuid.New())
+	userC := domain.UserID(uuid.New())
+	ins, err := pgSQL.StoreScans(ctx,
+		domain.Scan{UserID: userA, URL: urlA, Status: domain.ScanStatusPending},
+		domain.Scan{UserID: userB, URL: urlA, Status: domain.ScanStatusPending, Shareable: true},
+		domain.Scan{UserID: userC, URL: urlA, Status: domain.ScanStatusPending},
+	)
+	require.NoError(t, err)
+	require.Len(t, ins, 3)
+
+	status := func(user domain.UserID, id domain.ScanID) domain.ScanStatus {
+		t.Helper()
+		sc, err := pgSQL.ScanByID(ctx, user, id)
+		require.NoError(t, err)
+		require.NotNil(t, sc)
+
+		return sc.Status
+	}
+	completed := storage.ScanUpdates{Status: domain.ScanStatusCompleted, Result: &domain.ScanResult{}}
+
+	// the public job completes only the shareable scan
+	updated, err := pgSQL.UpdatePendingScansByURL(ctx, urlA, domain.UserID{}, completed)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, updated)
+	require.Equal(t, domain.ScanStatusPending, status(userA, ins[0].ID))
+	require.Equal(t, domain.ScanStatusCompleted, status(userB, ins[1].ID))
+	require.Equal(t, domain.ScanStatusPending, status(userC, ins[2].ID))
+
+	// the private job of userA completes only the scan of userA
+	updated, err = pgSQL.UpdatePendingScansByURL(ctx, urlA, userA, completed)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, updated)
+	require.Equal(t, domain.ScanStatusCompleted, status(userA, ins[0].ID))
+	require.Equal(t, domain.ScanStatusPending, status(userC, ins[2].ID))
+
+	// attempts follow the same scope
+	require.NoError(t, pgSQL.RecordScanAttempts(ctx, urlA, userB, storage.ScanAttempt{Error: "boom"}))
+	attempts, err := pgSQL.ScanAttemptsByID(ctx, ins[2].ID)
+	require.NoError(t, err)
+	require.Empty(t, attempts)
+}
+
 func TestPgSQL_DeleteScan(t *testing.T) {
 	t.Parallel()
 
@@ -185,7 +253,7 @@ func TestPgSQL_DeleteScan(t *testing.T) {
 	require.NoError(t, err)
 	require.Nil(t, got)
 	// listing should not include it
-	page, err := pgSQL.UserScans(ctx, userID, "", time.Time{}, 10)
+	page, err := pgSQL.UserScans(ctx, userID, "", false, time.Time{}, storage.PageNext, 10)
 	require.NoError(t, err)
 	for _, sc := range page.Scans {
 		require.NotEqual(t, id, sc.ID)
@@ -196,6 +264,41 @@ func TestPgSQL_DeleteScan(t *testing.T) {
 	require.Nil(t, deleted2)
 }
 
+func TestPgSQL_DeleteScan_CancelsPending(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	userID := domain.UserID(uuid.New())
+	stored, err := pgSQL.StoreScans(ctx,
+		domain.Scan{UserID: userID, URL: urlA, Status: domain.ScanStatusPending},
+		domain.Scan{UserID: userID, URL: urlA, Status: domain.ScanStatusCompleted},
+		domain.Scan{UserID: userID, URL: urlA, Status: domain.ScanStatusFailed},
+	)
+	require.NoError(t, err)
+
+	// pending scans are recorded as canceled
+	deleted, err := pgSQL.DeleteScan(ctx, userID, stored[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.ScanStatusCanceled, deleted.Status)
+	require.False(t, deleted.DeletedAt.IsZero())
+
+	// terminal scans keep their status
+	deleted, err = pgSQL.DeleteScan(ctx, userID, stored[1].ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.ScanStatusCompleted, deleted.Status)
+	deleted, err = pgSQL.DeleteScan(ctx, userID, stored[2].ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.ScanStatusFailed, deleted.Status)
+
+	// canceled scans are no longer pending for the URL
+	cnt, err := pgSQL.PendingScanCountByURL(ctx, urlA, userID)
+	require.NoError(t, err)
+	require.Zero(t, cnt)
+}
+
 func TestPgSQL_UserScans_Pagination(t *testing.T) {
 	t.Parallel()
 
@@ -223,24 +326,202 @@ func TestPgSQL_UserScans_Pagination(t *testing.T) {
 	}
 
 	// first page, limit 2
-	p1, err := pgSQL.UserScans(ctx, userID, "", time.Time{}, 2)
+	p1, err := pgSQL.UserScans(ctx, userID, "", false, time.Time{}, storage.PageNext, 2)
 	require.NoError(t, err)
 	require.Len(t, p1.Scans, 2)
 	require.NotNil(t, p1.NextCursor)
 	c1 := *p1.NextCursor
 
 	// second page
-	p2, err := pgSQL.UserScans(ctx, userID, "", c1, 2)
+	p2, err := pgSQL.UserScans(ctx, userID, "", false, c1, storage.PageNext, 2)
 	require.NoError(t, err)
 	require.Len(t, p2.Scans, 2)
 	require.NotNil(t, p2.NextCursor)
 	c2 := *p2.NextCursor
 
 	// third (last) page, should have 1 left and no next cursor
-	p3, err := pgSQL.UserScans(ctx, userID, "", c2, 2)
+	p3, err := pgSQL.UserScans(ctx, userID, "", false, c2, storage.PageNext, 2)
+	require.NoError(t, err)
+	require.Len(t, p3.Scans, 1)
+	require.Nil(t, p3.NextCursor)
+}
+
+func TestPgSQL_UserScans_PrevPage(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	userID := domain.UserID(uuid.New())
+	scans := make([]domain.Scan, 0, 5)
+	for range 5 {
+		scans = append(scans, domain.Scan{
+			UserID: userID,
+			URL:    "https://page.example/" + uuid.NewString(),
+			Status: domain.ScanStatusPending,
+		})
+	}
+	stored, err := pgSQL.StoreScans(ctx, scans...)
+	require.NoError(t, err)
+
+	// the last stored scan is the newest
+	now := time.Now().UTC()
+	for i, sc := range stored {
+		created := now.Add(-time.Duration(4-i) * time.Minute)
+		_, err := pgSQL.DB.ExecContext(ctx, "UPDATE scans SET created_at = $1 WHERE id = $2", created, uuid.UUID(sc.ID))
+		require.NoError(t, err)
+	}
+	ids := func(page storage.UserScans) []domain.ScanID {
+		res := make([]domain.ScanID, 0, len(page.Scans))
+		for _, sc := range page.Scans {
+			res = append(res, sc.ID)
+		}
+
+		return res
+	}
+
+	// forward through all pages
+	p1, err := pgSQL.UserScans(ctx, userID, "", false, time.Time{}, storage.PageNext, 2)
+	require.NoError(t, err)
+	require.Nil(t, p1.PrevCursor, "the first page has no previous page")
+	require.NotNil(t, p1.NextCursor)
+	p2, err := pgSQL.UserScans(ctx, userID, "", false, *p1.NextCursor, storage.PageNext, 2)
+	require.NoError(t, err)
+	require.NotNil(t, p2.PrevCursor)
+	require.NotNil(t, p2.NextCursor)
+	p3, err := pgSQL.UserScans(ctx, userID, "", false, *p2.NextCursor, storage.PageNext, 2)
 	require.NoError(t, err)
 	require.Len(t, p3.Scans, 1)
 	require.Nil(t, p3.NextCursor)
+	require.NotNil(t, p3.PrevCursor)
+
+	// and back again, landing on the same scans in the same order
+	back2, err := pgSQL.UserScans(ctx, userID, "", false, *p3.PrevCursor, storage.PagePrev, 2)
+	require.NoError(t, err)
+	require.Equal(t, ids(p2), ids(back2))
+	require.True(t, p2.NextCursor.Equal(*back2.NextCursor))
+	require.NotNil(t, back2.PrevCursor)
+	back1, err := pgSQL.UserScans(ctx, userID, "", false, *back2.PrevCursor, storage.PagePrev, 2)
+	require.NoError(t, err)
+	require.Equal(t, ids(p1), ids(back1))
+	require.True(t, p1.NextCursor.Equal(*back1.NextCursor))
+	require.Nil(t, back1.PrevCursor, "the first page has no previous page")
+}
+
+func TestPgSQL_UserScans_MaliciousOnly(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	userID := domain.UserID(uuid.New())
+	stored, err := pgSQL.StoreScans(ctx,
+		domain.Scan{UserID: userID, URL: "https://malicious.example", Status: domain.ScanStatusPending},
+		domain.Scan{UserID: userID, URL: "https://benign.example", Status: domain.ScanStatusPending},
+		domain.Scan{UserID: userID, URL: "https://pending.example", Status: domain.ScanStatusPending},
+	)
+	require.NoError(t, err)
+
+	complete := func(id domain.ScanID, malicious bool) {
+		_, err := pgSQL.UpdateScanByID(ctx, id, storage.ScanUpdates{
+			Status: domain.ScanStatusCompleted,
+			Result: &domain.ScanResult{Verdict: &struct {
+				Malicious  bool     `json:"malicious"`
+				Score      int      `json:"score"`
+				Categories []string `json:"categories,omitempty"`
+				Brands     []string `json:"brands,omitempty"`
+			}{Malicious: malicious, Score: 10}},
+		})
+		require.NoError(t, err)
+	}
+	complete(stored[0].ID, true)
+	complete(stored[1].ID, false)
+
+	// only the malicious scan matches
+	page, err := pgSQL.UserScans(ctx, userID, "", true, time.Time{}, storage.PageNext, 10)
+	require.NoError(t, err)
+	require.Len(t, page.Scans, 1)
+	require.Equal(t, stored[0].ID, page.Scans[0].ID)
+
+	// combined with a status filter
+	page, err = pgSQL.UserScans(ctx, userID, domain.ScanStatusCompleted, true, time.Time{}, storage.PageNext, 10)
+	require.NoError(t, err)
+	require.Len(t, page.Scans, 1)
+	page, err = pgSQL.UserScans(ctx, userID, domain.ScanStatusPending, true, time.Time{}, storage.PageNext, 10)
+	require.NoError(t, err)
+	require.Empty(t, page.Scans)
+
+	// without the filter every scan is returned
+	page, err = pgSQL.UserScans(ctx, userID, "", false, time.Time{}, storage.PageNext, 10)
+	require.NoError(t, err)
+	require.Len(t, page.Scans, 3)
+}
+
+func TestPgSQL_SearchScans(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	userID := domain.UserID(uuid.New())
+	otherUser := domain.UserID(uuid.New())
+	stored, err := pgSQL.StoreScans(ctx,
+		domain.Scan{UserID: userID, URL: "https://a.example", Status: domain.ScanStatusPending},
+		domain.Scan{UserID: userID, URL: "https://b.example", Status: domain.ScanStatusPending},
+		domain.Scan{UserID: userID, URL: "https://deleted.example", Status: domain.ScanStatusPending},
+		domain.Scan{UserID: otherUser, URL: "https://a.example", Status: domain.ScanStatusPending},
+	)
+	require.NoError(t, err)
+
+	type page = struct {
+		URL     string `json:"url"`
+		Domain  string `json:"domain"`
+		IP      string `json:"ip"`
+		ASN     string `json:"asn"`
+		Country string `json:"country"`
+		Server  string `json:"server"`
+	}
+	pageA := &page{Domain: "a.example", IP: "1.2.3.4", ASN: "AS15133", Country: "US", Server: "nginx"}
+	pageB := &page{Domain: "b.example", IP: "5.6.7.8", ASN: "AS13335", Country: "DE", Server: "cloudflare"}
+	for i, p := range []*page{pageA, pageB, pageA, pageA} {
+		_, err := pgSQL.UpdateScanByID(ctx, stored[i].ID, storage.ScanUpdates{
+			Status: domain.ScanStatusCompleted,
+			Result: &domain.ScanResult{Page: p},
+		})
+		require.NoError(t, err)
+	}
+	_, err = pgSQL.DeleteScan(ctx, userID, stored[2].ID)
+	require.NoError(t, err)
+
+	// every key finds the matching scan of the user only
+	for key, value := range map[domain.ScanSearchKey]string{
+		domain.ScanSearchKeyIP:      "1.2.3.4",
+		domain.ScanSearchKeyASN:     "AS15133",
+		domain.ScanSearchKeyServer:  "nginx",
+		domain.ScanSearchKeyDomain:  "a.example",
+		domain.ScanSearchKeyCountry: "US",
+	} {
+		res, err := pgSQL.SearchScans(ctx, userID, key, value, time.Time{}, 10)
+		require.NoError(t, err, key)
+		require.Len(t, res.Scans, 1, key)
+		require.Equal(t, stored[0].ID, res.Scans[0].ID, key)
+	}
+
+	// values must match exactly
+	res, err := pgSQL.SearchScans(ctx, userID, domain.ScanSearchKeyIP, "1.2.3", time.Time{}, 10)
+	require.NoError(t, err)
+	require.Empty(t, res.Scans)
+
+	// values are not matched across keys
+	res, err = pgSQL.SearchScans(ctx, userID, domain.ScanSearchKeyServer, "1.2.3.4", time.Time{}, 10)
+	require.NoError(t, err)
+	require.Empty(t, res.Scans)
+
+	_, err = pgSQL.SearchScans(ctx, userID, "url", "https://a.example", time.Time{}, 10)
+	require.ErrorIs(t, err, serrors.ErrBadRequest)
 }
 
 func TestPgSQL_ScanByID(t *testing.T) {
@@ -285,6 +566,36 @@ func TestPgSQL_ScanByID(t *testing.T) {
 	require.Nil(t, got3)
 }
 
+func TestPgSQL_ScanByID_PendingWithoutResult(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	userID := domain.UserID(uuid.New())
+	stored, err := pgSQL.StoreScans(ctx, domain.Scan{
+		UserID: userID,
+		URL:    "https://id.test/pending",
+		Status: domain.ScanStatusPending,
+	})
+	require.NoError(t, err)
+	id := stored[0].ID
+
+	// a fresh pending scan has no result yet
+	got, err := pgSQL.ScanByID(ctx, userID, id)
+	require.NoError(t, err)
+	require.Equal(t, domain.ScanStatusPending, got.Status)
+	require.Equal(t, domain.ScanResult{}, got.Result)
+
+	// a JSON null result reads as an empty one as well
+	_, err = pgSQL.DB.ExecContext(ctx, "UPDATE scans SET result = 'null'::jsonb WHERE id = $1", uuid.UUID(id))
+	require.NoError(t, err)
+	got, err = pgSQL.ScanByID(ctx, userID, id)
+	require.NoError(t, err)
+	require.Equal(t, domain.ScanResult{}, got.Result)
+}
+
 func TestPgSQL_UpdateScanByID(t *testing.T) {
 	t.Parallel()
 
@@ -324,113 +635,1197 @@ func TestPgSQL_UpdateScanByID(t *testing.T) {
 	require.Equal(t, "https://upd.example", updated.Result.Page.URL)
 }
 
-func TestPgSQL_UpdateScanByID_NotFound(t *testing.T) {
+func TestPgSQL_UpdateScans_MergeResult(t *testing.T) {
 	t.Parallel()
 
 	pgSQL, cleanup := setupTestDB(t)
 	t.Cleanup(cleanup)
 	ctx := context.Background()
 
-	// unknown id
-	unknown := domain.ScanID(uuid.New())
-	updated, err := pgSQL.UpdateScanByID(ctx, unknown, storage.ScanUpdates{Status: domain.ScanStatusFailed})
+	user := domain.UserID(uuid.New())
+	URL := "https://merge.example"
+	stored, err := pgSQL.StoreScans(ctx, domain.Scan{UserID: user, URL: URL, Status: domain.ScanStatusPending})
 	require.NoError(t, err)
-	require.Nil(t, updated)
+	id := stored[0].ID
 
-	// deleted id
-	user := domain.UserID(uuid.New())
-	ins, err := pgSQL.StoreScans(ctx, domain.Scan{
-		UserID: user,
-		URL:    "https://del.example",
-		Status: domain.ScanStatusPending,
-	})
+	verdict := &domain.ScanResult{Verdict: &struct {
+		Malicious  bool     `json:"malicious"`
+		Score      int      `json:"score"`
+		Categories []string `json:"categories,omitempty"`
+		Brands     []string `json:"brands,omitempty"`
+	}{Malicious: true, Score: 90}}
+	stats := &domain.ScanResult{Stats: &struct {
+		Malicious int `json:"malicious"`
+	}{Malicious: 3}}
+
+	// merge into an empty result
+	_, err = pgSQL.UpdateScanByID(ctx, id, storage.ScanUpdates{Result: verdict, MergeResult: true})
 	require.NoError(t, err)
-	_, err = pgSQL.DeleteScan(ctx, user, ins[0].ID)
+
+	// merging another section preserves the verdict
+	updated, err := pgSQL.UpdateScanByID(ctx, id, storage.ScanUpdates{Result: stats, MergeResult: true})
 	require.NoError(t, err)
-	updated2, err := pgSQL.UpdateScanByID(ctx, ins[0].ID, storage.ScanUpdates{Status: domain.ScanStatusCompleted})
+	require.NotNil(t, updated.Result.Verdict)
+	require.Equal(t, 90, updated.Result.Verdict.Score)
+	require.NotNil(t, updated.Result.Stats)
+	require.Equal(t, 3, updated.Result.Stats.Malicious)
+
+	// merging by URL behaves the same
+	page := &domain.ScanResult{Page: &struct {
+		URL     string `json:"url"`
+		Domain  string `json:"domain"`
+		IP      string `json:"ip"`
+		ASN     string `json:"asn"`
+		Country string `json:"country"`
+		Server  string `json:"server"`
+	}{URL: URL}}
+	_, err = pgSQL.UpdatePendingScansByURL(ctx, URL, user, storage.ScanUpdates{Result: page, MergeResult: true})
 	require.NoError(t, err)
-	require.Nil(t, updated2)
+	got, err := pgSQL.ScanByID(ctx, user, id)
+	require.NoError(t, err)
+	require.NotNil(t, got.Result.Page)
+	require.NotNil(t, got.Result.Verdict)
+	require.NotNil(t, got.Result.Stats)
+
+	// without MergeResult, the result is replaced
+	updated, err = pgSQL.UpdateScanByID(ctx, id, storage.ScanUpdates{Result: stats})
+	require.NoError(t, err)
+	require.Nil(t, updated.Result.Verdict)
+	require.Nil(t, updated.Result.Page)
+	require.NotNil(t, updated.Result.Stats)
 }
 
-func TestPgSQL_LastCompletedScanByURL(t *testing.T) {
+func TestPgSQL_UpdateScans_MergeResult_Nested(t *testing.T) {
 	t.Parallel()
 
 	pgSQL, cleanup := setupTestDB(t)
 	t.Cleanup(cleanup)
 	ctx := context.Background()
 
-	url := "https://latest.example"
-	userA := domain.UserID(uuid.New())
-	userB := domain.UserID(uuid.New())
+	user := domain.UserID(uuid.New())
+	URL := "https://merge-nested.example"
+	stored, err := pgSQL.StoreScans(ctx, domain.Scan{UserID: user, URL: URL, Status: domain.ScanStatusPending})
+	require.NoError(t, err)
+	id := stored[0].ID
 
-	// Insert various scans for the same URL across users
-	stored, err := pgSQL.StoreScans(ctx,
-		domain.Scan{UserID: userA, URL: url, Status: domain.ScanStatusCompleted},             // older
-		domain.Scan{UserID: userB, URL: url, Status: domain.ScanStatusCompleted},             // newer
-		domain.Scan{UserID: userA, URL: url, Status: domain.ScanStatusPending},               // ignore
-		domain.Scan{UserID: userB, URL: "https://other", Status: domain.ScanStatusCompleted}, // different URL
-	)
+	type verdict = struct {
+		Malicious  bool     `json:"malicious"`
+		Score      int      `json:"score"`
+		Categories []string `json:"categories,omitempty"`
+		Brands     []string `json:"brands,omitempty"`
+	}
+	_, err = pgSQL.UpdateScanByID(ctx, id, storage.ScanUpdates{
+		Result:      &domain.ScanResult{Verdict: &verdict{Malicious: true, Score: 90, Categories: []string{"phishing"}}},
+		MergeResult: true,
+	})
 	require.NoError(t, err)
-	require.Len(t, stored, 4)
 
-	// Set deterministic created_at: make second completed the newest
-	now := time.Now().UTC()
-	// order in stored equals inputs; set index 0 older, index 1 newer
-	_, err = pgSQL.DB.ExecContext(ctx,
-		"UPDATE scans SET created_at = $1 WHERE id = $2",
-		now.Add(-2*time.Minute),
-		uuid.UUID(stored[0].ID))
+	// a partial verdict keeps the stored fields it omits and overwrites the others
+	updated, err := pgSQL.UpdateScanByID(ctx, id, storage.ScanUpdates{
+		Result:      &domain.ScanResult{Verdict: &verdict{Malicious: true, Score: 95, Brands: []string{"acme"}}},
+		MergeResult: true,
+	})
 	require.NoError(t, err)
-	_, err = pgSQL.DB.ExecContext(ctx,
-		"UPDATE scans SET created_at = $1 WHERE id = $2",
-		now.Add(-1*time.Minute),
-		uuid.UUID(stored[1].ID))
+	require.NotNil(t, updated.Result.Verdict)
+	require.True(t, updated.Result.Verdict.Malicious)
+	require.Equal(t, 95, updated.Result.Verdict.Score)
+	require.Equal(t, []string{"phishing"}, updated.Result.Verdict.Categories)
+	require.Equal(t, []string{"acme"}, updated.Result.Verdict.Brands)
+
+	// arrays are replaced rather than merged
+	_, err = pgSQL.UpdatePendingScansByURL(ctx, URL, user, storage.ScanUpdates{
+		Result:      &domain.ScanResult{Verdict: &verdict{Malicious: true, Score: 95, Categories: []string{"malware"}}},
+		MergeResult: true,
+	})
 	require.NoError(t, err)
-
-	got, err := pgSQL.LastCompletedScanByURL(ctx, url)
+	got, err := pgSQL.ScanByID(ctx, user, id)
 	require.NoError(t, err)
-	require.NotNil(t, got)
-	require.Equal(t, stored[1].ID, got.ID)
+	require.Equal(t, []string{"malware"}, got.Result.Verdict.Categories)
+	require.Equal(t, []string{"acme"}, got.Result.Verdict.Brands)
 }
 
-func TestPgSQL_PendingScanCountByURL(t *testing.T) {
+func TestPgSQL_UpdateScanByID_ExpectedVersion(t *testing.T) {
 	t.Parallel()
 
 	pgSQL, cleanup := setupTestDB(t)
 	t.Cleanup(cleanup)
 	ctx := context.Background()
 
-	user1 := domain.UserID(uuid.New())
-	user2 := domain.UserID(uuid.New())
+	user := domain.UserID(uuid.New())
+	URL := "https://version.example"
+	stored, err := pgSQL.StoreScans(ctx, domain.Scan{UserID: user, URL: URL, Status: domain.ScanStatusPending})
+	require.NoError(t, err)
+	id := stored[0].ID
+	require.Equal(t, int64(1), stored[0].Version)
 
-	// Create scans
-	ins, err := pgSQL.StoreScans(ctx,
-		domain.Scan{UserID: user1, URL: urlA, Status: domain.ScanStatusPending},   // 0
-		domain.Scan{UserID: user1, URL: urlA, Status: domain.ScanStatusPending},   // 1
-		domain.Scan{UserID: user2, URL: urlA, Status: domain.ScanStatusPending},   // 2
-		domain.Scan{UserID: user1, URL: urlA, Status: domain.ScanStatusCompleted}, // 3 (not counted)
-		domain.Scan{UserID: user1, URL: urlB, Status: domain.ScanStatusPending},   // 4 (different URL)
-	)
+	// matching version updates and bumps the version
+	updated, err := pgSQL.UpdateScanByID(ctx, id, storage.ScanUpdates{
+		Status:          domain.ScanStatusPending,
+		ExpectedVersion: 1,
+	})
 	require.NoError(t, err)
-	require.Len(t, ins, 5)
+	require.Equal(t, int64(2), updated.Version)
 
-	// Soft-delete one pending for URL A
-	deleted, err := pgSQL.DeleteScan(ctx, user1, ins[1].ID)
+	// the worker path bumps the version as well
+	_, err = pgSQL.UpdatePendingScansByURL(ctx, URL, user, storage.ScanUpdates{Status: domain.ScanStatusPending})
 	require.NoError(t, err)
-	require.NotNil(t, deleted)
 
-	// Count for URL A should be 3 (two pending remaining for user1 and user2)
-	cnt, err := pgSQL.PendingScanCountByURL(ctx, urlA)
+	// a stale version conflicts and leaves the scan untouched
+	_, err = pgSQL.UpdateScanByID(ctx, id, storage.ScanUpdates{
+		Status:          domain.ScanStatusFailed,
+		ExpectedVersion: 2,
+	})
+	require.ErrorIs(t, err, serrors.ErrConflict)
+	require.ErrorIs(t, err, storage.ErrVersionMismatch)
+	got, err := pgSQL.ScanByID(ctx, user, id)
 	require.NoError(t, err)
-	require.EqualValues(t, 2+1-1, cnt) // total 2 user1 pending (one deleted) + 1 user2 pending
+	require.Equal(t, domain.ScanStatusPending, got.Status)
+	require.Equal(t, int64(3), got.Version)
 
-	// Count for URL B should be 1
-	cntB, err := pgSQL.PendingScanCountByURL(ctx, urlB)
+	// a missing scan is not a conflict
+	missing, err := pgSQL.UpdateScanByID(ctx, domain.ScanID(uuid.New()), storage.ScanUpdates{
+		Status:          domain.ScanStatusFailed,
+		ExpectedVersion: 1,
+	})
 	require.NoError(t, err)
-	require.EqualValues(t, 1, cntB)
+	require.Nil(t, missing)
+}
 
-	// Count for non-existing URL should be 0
-	cntC, err := pgSQL.PendingScanCountByURL(ctx, "https://no.such/url")
+func TestPgSQL_UpdateScanByID_NotFound(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	// unknown id
+	unknown := domain.ScanID(uuid.New())
+	updated, err := pgSQL.UpdateScanByID(ctx, unknown, storage.ScanUpdates{Status: domain.ScanStatusFailed})
 	require.NoError(t, err)
-	require.EqualValues(t, 0, cntC)
+	require.Nil(t, updated)
+
+	// deleted id
+	user := domain.UserID(uuid.New())
+	ins, err := pgSQL.StoreScans(ctx, domain.Scan{
+		UserID: user,
+		URL:    "https://del.example",
+		Status: domain.ScanStatusPending,
+	})
+	require.NoError(t, err)
+	_, err = pgSQL.DeleteScan(ctx, user, ins[0].ID)
+	require.NoError(t, err)
+	updated2, err := pgSQL.UpdateScanByID(ctx, ins[0].ID, storage.ScanUpdates{Status: domain.ScanStatusCompleted})
+	require.NoError(t, err)
+	require.Nil(t, updated2)
+}
+
+func TestPgSQL_UpdateScanByIDForUser(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	owner := domain.UserID(uuid.New())
+	other := domain.UserID(uuid.New())
+	ins, err := pgSQL.StoreScans(ctx, domain.Scan{
+		UserID: owner,
+		URL:    "https://owned.example",
+		Status: domain.ScanStatusPending,
+	})
+	require.NoError(t, err)
+	id := ins[0].ID
+
+	// another user's update does not touch the scan
+	updated, err := pgSQL.UpdateScanByIDForUser(ctx, other, id, storage.ScanUpdates{Status: domain.ScanStatusCompleted})
+	require.NoError(t, err)
+	require.Nil(t, updated)
+	got, err := pgSQL.ScanByID(ctx, owner, id)
+	require.NoError(t, err)
+	require.Equal(t, domain.ScanStatusPending, got.Status)
+	require.Equal(t, ins[0].Version, got.Version)
+
+	// a version mismatch of another user's scan is reported as missing, not as a conflict
+	updated, err = pgSQL.UpdateScanByIDForUser(ctx, other, id, storage.ScanUpdates{
+		Status:          domain.ScanStatusCompleted,
+		ExpectedVersion: ins[0].Version + 1,
+	})
+	require.NoError(t, err)
+	require.Nil(t, updated)
+
+	// the owner's update is applied
+	updated, err = pgSQL.UpdateScanByIDForUser(ctx, owner, id, storage.ScanUpdates{Status: domain.ScanStatusCompleted})
+	require.NoError(t, err)
+	require.NotNil(t, updated)
+	require.Equal(t, domain.ScanStatusCompleted, updated.Status)
+
+	// and a version mismatch of the owner's scan is still a conflict
+	_, err = pgSQL.UpdateScanByIDForUser(ctx, owner, id, storage.ScanUpdates{
+		Status:          domain.ScanStatusFailed,
+		ExpectedVersion: ins[0].Version,
+	})
+	require.ErrorIs(t, err, storage.ErrVersionMismatch)
+}
+
+func TestPgSQL_StoreScans_PreservesOrder(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	userID := domain.UserID(uuid.New())
+	scans := make([]domain.Scan, 200)
+	for i := range scans {
+		scans[i] = domain.Scan{
+			UserID: userID,
+			URL:    fmt.Sprintf("https://order.example/%d", i),
+			Status: domain.ScanStatusPending,
+		}
+	}
+
+	stored, err := pgSQL.StoreScans(ctx, scans...)
+	require.NoError(t, err)
+	require.Len(t, stored, len(scans))
+
+	ids := make(map[domain.ScanID]struct{}, len(stored))
+	for i, s := range stored {
+		require.Equal(t, scans[i].URL, s.URL)
+		require.NotEqual(t, domain.ScanID{}, s.ID)
+		ids[s.ID] = struct{}{}
+	}
+	require.Len(t, ids, len(scans))
+}
+
+func TestPgSQL_LastCompletedScanByURL(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	url := "https://latest.example"
+	userA := domain.UserID(uuid.New())
+	userB := domain.UserID(uuid.New())
+
+	// Insert various scans for the same URL across users
+	stored, err := pgSQL.StoreScans(ctx,
+		domain.Scan{UserID: userA, URL: url, Status: domain.ScanStatusCompleted, Shareable: true},             // older
+		domain.Scan{UserID: userB, URL: url, Status: domain.ScanStatusCompleted, Shareable: true},             // newer
+		domain.Scan{UserID: userA, URL: url, Status: domain.ScanStatusPending, Shareable: true},               // ignore
+		domain.Scan{UserID: userB, URL: "https://other", Status: domain.ScanStatusCompleted, Shareable: true}, // different URL
+	)
+	require.NoError(t, err)
+	require.Len(t, stored, 4)
+
+	// Set deterministic created_at: make second completed the newest
+	now := time.Now().UTC()
+	// order in stored equals inputs; set index 0 older, index 1 newer
+	_, err = pgSQL.DB.ExecContext(ctx,
+		"UPDATE scans SET created_at = $1 WHERE id = $2",
+		now.Add(-2*time.Minute),
+		uuid.UUID(stored[0].ID))
+	require.NoError(t, err)
+	_, err = pgSQL.DB.ExecContext(ctx,
+		"UPDATE scans SET created_at = $1 WHERE id = $2",
+		now.Add(-1*time.Minute),
+		uuid.UUID(stored[1].ID))
+	require.NoError(t, err)
+
+	got, err := pgSQL.LastCompletedScanByURL(ctx, userA, url)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Equal(t, stored[1].ID, got.ID)
+}
+
+func TestPgSQL_LastCompletedScanByURL_Shareable(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	url := "https://private.example"
+	userA := domain.UserID(uuid.New())
+	userB := domain.UserID(uuid.New())
+
+	stored, err := pgSQL.StoreScans(ctx,
+		domain.Scan{UserID: userA, URL: url, Status: domain.ScanStatusCompleted, Shareable: true}, // older, public
+		domain.Scan{UserID: userA, URL: url, Status: domain.ScanStatusCompleted},                  // newer, private
+	)
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+
+	now := time.Now().UTC()
+	_, err = pgSQL.DB.ExecContext(ctx,
+		"UPDATE scans SET created_at = $1 WHERE id = $2",
+		now.Add(-2*time.Minute),
+		uuid.UUID(stored[0].ID))
+	require.NoError(t, err)
+	_, err = pgSQL.DB.ExecContext(ctx,
+		"UPDATE scans SET created_at = $1 WHERE id = $2",
+		now.Add(-1*time.Minute),
+		uuid.UUID(stored[1].ID))
+	require.NoError(t, err)
+
+	// the owner reuses its private scan
+	got, err := pgSQL.LastCompletedScanByURL(ctx, userA, url)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Equal(t, stored[1].ID, got.ID)
+
+	// other users only see the public one
+	got, err = pgSQL.LastCompletedScanByURL(ctx, userB, url)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Equal(t, stored[0].ID, got.ID)
+}
+
+func TestPgSQL_LastScanByURL(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	url := "https://failing.example"
+	userA := domain.UserID(uuid.New())
+	userB := domain.UserID(uuid.New())
+
+	stored, err := pgSQL.StoreScans(ctx,
+		domain.Scan{UserID: userA, URL: url, Status: domain.ScanStatusCompleted, Shareable: true}, // oldest, public
+		domain.Scan{UserID: userA, URL: url, Status: domain.ScanStatusFailed},                     // private
+		domain.Scan{UserID: userB, URL: url, Status: domain.ScanStatusPending},                    // pending
+		domain.Scan{UserID: userA, URL: url, Status: domain.ScanStatusFailed},                     // newest, never processed
+	)
+	require.NoError(t, err)
+	require.Len(t, stored, 4)
+
+	now := time.Now().UTC()
+	for i, scan := range stored {
+		_, err = pgSQL.DB.ExecContext(ctx,
+			"UPDATE scans SET created_at = $1 WHERE id = $2",
+			now.Add(time.Duration(i-4)*time.Minute),
+			uuid.UUID(scan.ID))
+		require.NoError(t, err)
+	}
+	recordAttempts(t, pgSQL, stored[0].ID, stored[1].ID, stored[2].ID)
+
+	// pending scans and scans without attempts, e.g. failed by reusing an
+	// earlier failure, are skipped, so the owner sees its processed failed scan
+	got, err := pgSQL.LastScanByURL(ctx, userA, url)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Equal(t, stored[1].ID, got.ID)
+	require.Equal(t, domain.ScanStatusFailed, got.Status)
+
+	// other users only see the public one
+	got, err = pgSQL.LastScanByURL(ctx, userB, url)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Equal(t, stored[0].ID, got.ID)
+
+	// deleted scans are ignored
+	_, err = pgSQL.DeleteScan(ctx, userA, stored[0].ID)
+	require.NoError(t, err)
+	got, err = pgSQL.LastScanByURL(ctx, userB, url)
+	require.NoError(t, err)
+	require.Nil(t, got)
+}
+
+func TestPgSQL_LastScanByURLForUser(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	url := "https://own-failure.example"
+	userA := domain.UserID(uuid.New())
+	userB := domain.UserID(uuid.New())
+
+	stored, err := pgSQL.StoreScans(ctx,
+		domain.Scan{UserID: userA, URL: url, Status: domain.ScanStatusFailed},                     // oldest, own failure
+		domain.Scan{UserID: userB, URL: url, Status: domain.ScanStatusCompleted, Shareable: true}, // public
+		domain.Scan{UserID: userA, URL: url, Status: domain.ScanStatusPending},                    // pending
+		domain.Scan{UserID: userA, URL: url, Status: domain.ScanStatusFailed},                     // newest, never processed
+	)
+	require.NoError(t, err)
+	require.Len(t, stored, 4)
+
+	now := time.Now().UTC()
+	for i, scan := range stored {
+		_, err = pgSQL.DB.ExecContext(ctx,
+			"UPDATE scans SET created_at = $1 WHERE id = $2",
+			now.Add(time.Duration(i-4)*time.Minute),
+			uuid.UUID(scan.ID))
+		require.NoError(t, err)
+	}
+	recordAttempts(t, pgSQL, stored[0].ID, stored[1].ID, stored[2].ID)
+
+	// the newer shareable scan of another user hides the failure from LastScanByURL
+	got, err := pgSQL.LastScanByURL(ctx, userA, url)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Equal(t, stored[1].ID, got.ID)
+
+	// but not from the user's own scans, where pending scans and scans without
+	// attempts are skipped
+	got, err = pgSQL.LastScanByURLForUser(ctx, userA, url)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Equal(t, stored[0].ID, got.ID)
+	require.Equal(t, domain.ScanStatusFailed, got.Status)
+
+	// the user sees only its own scans
+	got, err = pgSQL.LastScanByURLForUser(ctx, userB, url)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Equal(t, stored[1].ID, got.ID)
+	got, err = pgSQL.LastScanByURLForUser(ctx, domain.UserID(uuid.New()), url)
+	require.NoError(t, err)
+	require.Nil(t, got)
+
+	// deleted scans are ignored
+	_, err = pgSQL.DeleteScan(ctx, userA, stored[0].ID)
+	require.NoError(t, err)
+	got, err = pgSQL.LastScanByURLForUser(ctx, userA, url)
+	require.NoError(t, err)
+	require.Nil(t, got)
+}
+
+// recordAttempts records an attempt without error for each of the scans.
+func recordAttempts(t *testing.T, pgSQL *postgres.PgSQL, ids ...domain.ScanID) {
+	t.Helper()
+	for _, id := range ids {
+		_, err := pgSQL.DB.ExecContext(context.Background(),
+			"INSERT INTO scan_attempts (scan_id) VALUES ($1)", uuid.UUID(id))
+		require.NoError(t, err)
+	}
+}
+
+func TestPgSQL_LastCompletedScanByURLForUser(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	url := "https://own.example"
+	userA := domain.UserID(uuid.New())
+	userB := domain.UserID(uuid.New())
+
+	stored, err := pgSQL.StoreScans(ctx,
+		domain.Scan{UserID: userA, URL: url, Status: domain.ScanStatusCompleted, Shareable: true}, // older
+		domain.Scan{UserID: userB, URL: url, Status: domain.ScanStatusCompleted, Shareable: true}, // newer, other user
+	)
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+
+	now := time.Now().UTC()
+	_, err = pgSQL.DB.ExecContext(ctx,
+		"UPDATE scans SET created_at = $1 WHERE id = $2",
+		now.Add(-2*time.Minute),
+		uuid.UUID(stored[0].ID))
+	require.NoError(t, err)
+	_, err = pgSQL.DB.ExecContext(ctx,
+		"UPDATE scans SET created_at = $1 WHERE id = $2",
+		now.Add(-1*time.Minute),
+		uuid.UUID(stored[1].ID))
+	require.NoError(t, err)
+
+	// the global lookup returns the newest shareable scan of any user
+	got, err := pgSQL.LastCompletedScanByURL(ctx, userA, url)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Equal(t, stored[1].ID, got.ID)
+
+	// the user-scoped lookup only returns the user's own scan
+	got, err = pgSQL.LastCompletedScanByURLForUser(ctx, userA, url)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Equal(t, stored[0].ID, got.ID)
+
+	got, err = pgSQL.LastCompletedScanByURLForUser(ctx, domain.UserID(uuid.New()), url)
+	require.NoError(t, err)
+	require.Nil(t, got)
+}
+
+func TestPgSQL_LastCompletedScanByURL_IgnoresDeleted(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	url := "https://deleted.example"
+	userID := domain.UserID(uuid.New())
+
+	stored, err := pgSQL.StoreScans(ctx,
+		domain.Scan{UserID: userID, URL: url, Status: domain.ScanStatusCompleted, Shareable: true}, // older
+		domain.Scan{UserID: userID, URL: url, Status: domain.ScanStatusCompleted, Shareable: true}, // newer, deleted
+	)
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+
+	now := time.Now().UTC()
+	_, err = pgSQL.DB.ExecContext(ctx,
+		"UPDATE scans SET created_at = $1 WHERE id = $2",
+		now.Add(-2*time.Minute),
+		uuid.UUID(stored[0].ID))
+	require.NoError(t, err)
+	_, err = pgSQL.DB.ExecContext(ctx,
+		"UPDATE scans SET created_at = $1 WHERE id = $2",
+		now.Add(-1*time.Minute),
+		uuid.UUID(stored[1].ID))
+	require.NoError(t, err)
+
+	deleted, err := pgSQL.DeleteScan(ctx, userID, stored[1].ID)
+	require.NoError(t, err)
+	require.NotNil(t, deleted)
+
+	got, err := pgSQL.LastCompletedScanByURL(ctx, userID, url)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Equal(t, stored[0].ID, got.ID)
+
+	// no result is reused once every completed scan is deleted
+	_, err = pgSQL.DeleteScan(ctx, userID, stored[0].ID)
+	require.NoError(t, err)
+	got, err = pgSQL.LastCompletedScanByURL(ctx, userID, url)
+	require.NoError(t, err)
+	require.Nil(t, got)
+}
+
+func TestPgSQL_PendingScanCountByUser(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	user1 := domain.UserID(uuid.New())
+	user2 := domain.UserID(uuid.New())
+
+	ins, err := pgSQL.StoreScans(ctx,
+		domain.Scan{UserID: user1, URL: urlA, Status: domain.ScanStatusPending},   // 0
+		domain.Scan{UserID: user1, URL: urlB, Status: domain.ScanStatusPending},   // 1
+		domain.Scan{UserID: user1, URL: urlA, Status: domain.ScanStatusPending},   // 2 (deleted)
+		domain.Scan{UserID: user1, URL: urlA, Status: domain.ScanStatusCompleted}, // 3 (not pending)
+		domain.Scan{UserID: user2, URL: urlA, Status: domain.ScanStatusPending},   // 4 (other user)
+	)
+	require.NoError(t, err)
+	deleted, err := pgSQL.DeleteScan(ctx, user1, ins[2].ID)
+	require.NoError(t, err)
+	require.NotNil(t, deleted)
+
+	cnt, err := pgSQL.PendingScanCountByUser(ctx, user1)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, cnt)
+
+	cnt, err = pgSQL.PendingScanCountByUser(ctx, user2)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, cnt)
+
+	cnt, err = pgSQL.PendingScanCountByUser(ctx, domain.UserID(uuid.New()))
+	require.NoError(t, err)
+	require.EqualValues(t, 0, cnt)
+}
+
+func TestPgSQL_PendingScanCountByUser_SerializesTransactions(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	userID := domain.UserID(uuid.New())
+
+	// the first transaction stores a scan and counts it, as Enqueue does
+	tx1, err := pgSQL.Begin(ctx)
+	require.NoError(t, err)
+	_, err = tx1.StoreScans(ctx, domain.Scan{UserID: userID, URL: urlA, Status: domain.ScanStatusPending})
+	require.NoError(t, err)
+	cnt, err := tx1.PendingScanCountByUser(ctx, userID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, cnt)
+
+	// a concurrent transaction of the same user waits for it
+	counted := make(chan int64, 1)
+	go func() {
+		tx2, err := pgSQL.Begin(ctx)
+		if err != nil {
+			close(counted)
+
+			return
+		}
+		defer func() { _ = tx2.Rollback() }()
+		if _, err := tx2.StoreScans(ctx,
+			domain.Scan{UserID: userID, URL: urlB, Status: domain.ScanStatusPending}); err != nil {
+			close(counted)
+
+			return
+		}
+		cnt, err := tx2.PendingScanCountByUser(ctx, userID)
+		if err != nil {
+			close(counted)
+
+			return
+		}
+		counted <- cnt
+	}()
+
+	// other users are not blocked
+	cnt, err = pgSQL.PendingScanCountByUser(ctx, domain.UserID(uuid.New()))
+	require.NoError(t, err)
+	require.EqualValues(t, 0, cnt)
+
+	select {
+	case <-counted:
+		t.Fatal("concurrent count did not wait for the first transaction")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// and sees its scan once it commits
+	require.NoError(t, tx1.Commit())
+	select {
+	case cnt, ok := <-counted:
+		require.True(t, ok, "concurrent count failed")
+		require.EqualValues(t, 2, cnt)
+	case <-time.After(5 * time.Second):
+		t.Fatal("concurrent count did not finish")
+	}
+}
+
+func TestPgSQL_PendingScanCountByURL(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	user1 := domain.UserID(uuid.New())
+	user2 := domain.UserID(uuid.New())
+
+	// Create scans
+	ins, err := pgSQL.StoreScans(ctx,
+		domain.Scan{UserID: user1, URL: urlA, Status: domain.ScanStatusPending},                  // 0
+		domain.Scan{UserID: user1, URL: urlA, Status: domain.ScanStatusPending},                  // 1
+		domain.Scan{UserID: user2, URL: urlA, Status: domain.ScanStatusPending},                  // 2
+		domain.Scan{UserID: user1, URL: urlA, Status: domain.ScanStatusCompleted},                // 3 (not counted)
+		domain.Scan{UserID: user1, URL: urlB, Status: domain.ScanStatusPending},                  // 4 (different URL)
+		domain.Scan{UserID: user1, URL: urlA, Status: domain.ScanStatusPending, Shareable: true}, // 5
+		domain.Scan{UserID: user2, URL: urlA, Status: domain.ScanStatusPending, Shareable: true}, // 6
+	)
+	require.NoError(t, err)
+	require.Len(t, ins, 7)
+
+	// Soft-delete one pending for URL A
+	deleted, err := pgSQL.DeleteScan(ctx, user1, ins[1].ID)
+	require.NoError(t, err)
+	require.NotNil(t, deleted)
+
+	// Owners count only their own non-shareable scans (one deleted for user1)
+	cnt, err := pgSQL.PendingScanCountByURL(ctx, urlA, user1)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, cnt)
+	cnt, err = pgSQL.PendingScanCountByURL(ctx, urlA, user2)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, cnt)
+
+	// The zero owner counts the shareable scans of all users
+	cnt, err = pgSQL.PendingScanCountByURL(ctx, urlA, domain.UserID{})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, cnt)
+
+	// Count for URL B should be 1
+	cntB, err := pgSQL.PendingScanCountByURL(ctx, urlB, user1)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, cntB)
+
+	// Count for non-existing URL should be 0
+	cntC, err := pgSQL.PendingScanCountByURL(ctx, "https://no.such/url", user1)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, cntC)
+}
+
+func TestPgSQL_PendingScanCountByURL_UsesPartialIndex(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	user := domain.UserID(uuid.New())
+	for range 50 {
+		_, err := pgSQL.StoreScans(ctx,
+			domain.Scan{UserID: user, URL: urlA, Status: domain.ScanStatusCompleted},
+			domain.Scan{UserID: user, URL: urlB, Status: domain.ScanStatusPending},
+		)
+		require.NoError(t, err)
+	}
+	_, err := pgSQL.StoreScans(ctx, domain.Scan{UserID: user, URL: urlA, Status: domain.ScanStatusPending})
+	require.NoError(t, err)
+
+	// counts remain correct with the index in place
+	cnt, err := pgSQL.PendingScanCountByURL(ctx, urlA, user)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, cnt)
+	cnt, err = pgSQL.PendingScanCountByURL(ctx, urlB, user)
+	require.NoError(t, err)
+	require.EqualValues(t, 50, cnt)
+
+	// the planner picks the partial index; sequential scans are disabled since
+	// the table is too small for the planner to prefer an index on its own
+	tx, err := pgSQL.Begin(ctx)
+	require.NoError(t, err)
+	defer func() { _ = tx.Rollback() }()
+	db := tx.(*postgres.PgSQL).DB
+	_, err = db.ExecContext(ctx, "ANALYZE scans")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "SET LOCAL enable_seqscan = off")
+	require.NoError(t, err)
+
+	rows, err := db.QueryContext(ctx,
+		"EXPLAIN SELECT COUNT(*) FROM scans WHERE url = $1 AND status = 'PENDING' AND deleted_at IS NULL", urlA)
+	require.NoError(t, err)
+	defer rows.Close()
+	var plan strings.Builder
+	for rows.Next() {
+		var line string
+		require.NoError(t, rows.Scan(&line))
+		plan.WriteString(line + "\n")
+	}
+	require.NoError(t, rows.Err())
+	require.Contains(t, plan.String(), "scans_pending_url_idx")
+}
+
+func TestPgSQL_StalePendingScans(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	user1 := domain.UserID(uuid.New())
+	user2 := domain.UserID(uuid.New())
+
+	stale, err := pgSQL.StoreScans(ctx,
+		domain.Scan{UserID: user1, URL: urlA, Status: domain.ScanStatusPending},   // 0
+		domain.Scan{UserID: user2, URL: urlB, Status: domain.ScanStatusPending},   // 1
+		domain.Scan{UserID: user1, URL: urlA, Status: domain.ScanStatusCompleted}, // 2 (not pending)
+		domain.Scan{UserID: user1, URL: urlB, Status: domain.ScanStatusPending},   // 3 (deleted below)
+	)
+	require.NoError(t, err)
+	deleted, err := pgSQL.DeleteScan(ctx, user1, stale[3].ID)
+	require.NoError(t, err)
+	require.NotNil(t, deleted)
+
+	// scans stored or updated after the cutoff are fresh; the stale scans were
+	// never updated, so their age is given by their creation
+	require.True(t, stale[1].UpdatedAt.IsZero())
+	time.Sleep(10 * time.Millisecond)
+	olderThan := stale[1].CreatedAt.Add(5 * time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	fresh, err := pgSQL.StoreScans(ctx, domain.Scan{UserID: user1, URL: urlA, Status: domain.ScanStatusPending})
+	require.NoError(t, err)
+
+	scans, err := pgSQL.StalePendingScans(ctx, olderThan, nil, 10)
+	require.NoError(t, err)
+	require.Len(t, scans, 2)
+	ids := []domain.ScanID{scans[0].ID, scans[1].ID}
+	require.ElementsMatch(t, []domain.ScanID{stale[0].ID, stale[1].ID}, ids)
+	require.NotContains(t, ids, fresh[0].ID)
+
+	// updating a stale scan makes it fresh again
+	msg := "retrying"
+	_, err = pgSQL.UpdateScanByID(ctx, stale[0].ID, storage.ScanUpdates{LastError: &msg})
+	require.NoError(t, err)
+	scans, err = pgSQL.StalePendingScans(ctx, olderThan, nil, 10)
+	require.NoError(t, err)
+	require.Len(t, scans, 1)
+	require.Equal(t, stale[1].ID, scans[0].ID)
+}
+
+func TestPgSQL_StalePendingScans_Paging(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	userID := domain.UserID(uuid.New())
+	stored := make([]domain.Scan, 0, 5)
+	for range 5 {
+		scans, err := pgSQL.StoreScans(ctx, domain.Scan{UserID: userID, URL: urlA, Status: domain.ScanStatusPending})
+		require.NoError(t, err)
+		stored = append(stored, scans...)
+	}
+	olderThan := time.Now().Add(time.Hour)
+
+	// the pages together return every stale scan once, oldest first
+	var paged []domain.ScanID
+	var after *domain.Scan
+	for {
+		scans, err := pgSQL.StalePendingScans(ctx, olderThan, after, 2)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(scans), 2)
+		for _, scan := range scans {
+			paged = append(paged, scan.ID)
+		}
+		if len(scans) < 2 {
+			break
+		}
+		after = &scans[len(scans)-1]
+	}
+	ids := make([]domain.ScanID, 0, len(stored))
+	for _, scan := range stored {
+		ids = append(ids, scan.ID)
+	}
+	require.Equal(t, ids, paged)
+}
+
+func TestPgSQL_ScanStatusCounts(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	user := domain.UserID(uuid.New())
+	other := domain.UserID(uuid.New())
+	url := "https://status-counts.example"
+
+	stored, err := pgSQL.StoreScans(ctx,
+		domain.Scan{UserID: user, URL: url, Status: domain.ScanStatusPending},
+		domain.Scan{UserID: user, URL: url, Status: domain.ScanStatusPending},
+		domain.Scan{UserID: user, URL: url, Status: domain.ScanStatusCompleted},
+		domain.Scan{UserID: user, URL: url, Status: domain.ScanStatusCompleted},
+		domain.Scan{UserID: user, URL: url, Status: domain.ScanStatusCompleted},
+		domain.Scan{UserID: user, URL: url, Status: domain.ScanStatusFailed},
+		domain.Scan{UserID: other, URL: url, Status: domain.ScanStatusCompleted},
+	)
+	require.NoError(t, err)
+
+	// soft-deleted scans are excluded; deleting a pending scan cancels it
+	_, err = pgSQL.DeleteScan(ctx, user, stored[0].ID)
+	require.NoError(t, err)
+	_, err = pgSQL.DeleteScan(ctx, user, stored[5].ID)
+	require.NoError(t, err)
+
+	counts, err := pgSQL.ScanStatusCounts(ctx, user)
+	require.NoError(t, err)
+	require.Equal(t, map[domain.ScanStatus]int64{
+		domain.ScanStatusPending:   1,
+		domain.ScanStatusCompleted: 3,
+	}, counts)
+
+	// users without scans get an empty map
+	counts, err = pgSQL.ScanStatusCounts(ctx, domain.UserID(uuid.New()))
+	require.NoError(t, err)
+	require.Empty(t, counts)
+}
+
+func TestPgSQL_LatestScanByURLForUser(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	url := "https://latest-user.example"
+	userA := domain.UserID(uuid.New())
+	userB := domain.UserID(uuid.New())
+
+	stored, err := pgSQL.StoreScans(ctx,
+		domain.Scan{UserID: userA, URL: url, Status: domain.ScanStatusCompleted},           // older
+		domain.Scan{UserID: userA, URL: url, Status: domain.ScanStatusPending},             // newer
+		domain.Scan{UserID: userB, URL: url, Status: domain.ScanStatusPending},             // other user, newest
+		domain.Scan{UserID: userA, URL: "https://other", Status: domain.ScanStatusPending}, // different URL
+	)
+	require.NoError(t, err)
+	require.Len(t, stored, 4)
+
+	now := time.Now().UTC()
+	for i, age := range []time.Duration{3 * time.Minute, 2 * time.Minute, time.Minute} {
+		_, err = pgSQL.DB.ExecContext(ctx,
+			"UPDATE scans SET created_at = $1 WHERE id = $2",
+			now.Add(-age),
+			uuid.UUID(stored[i].ID))
+		require.NoError(t, err)
+	}
+
+	// found: the newest scan of the user, not the other user's newer one
+	got, err := pgSQL.LatestScanByURLForUser(ctx, userA, url)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Equal(t, stored[1].ID, got.ID)
+
+	// soft-deleted scans are skipped
+	_, err = pgSQL.DeleteScan(ctx, userA, stored[1].ID)
+	require.NoError(t, err)
+	got, err = pgSQL.LatestScanByURLForUser(ctx, userA, url)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Equal(t, stored[0].ID, got.ID)
+
+	// not found
+	got, err = pgSQL.LatestScanByURLForUser(ctx, domain.UserID(uuid.New()), url)
+	require.NoError(t, err)
+	require.Nil(t, got)
+}
+
+func TestPgSQL_ScanByIdempotencyKey(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	userA := domain.UserID(uuid.New())
+	userB := domain.UserID(uuid.New())
+	url := "https://idempotent.example"
+
+	stored, err := pgSQL.StoreScans(ctx,
+		domain.Scan{UserID: userA, URL: url, Status: domain.ScanStatusPending, IdempotencyKey: "key-1"},
+		domain.Scan{UserID: userA, URL: url, Status: domain.ScanStatusPending, IdempotencyKey: "key-2"},
+		domain.Scan{UserID: userB, URL: url, Status: domain.ScanStatusPending, IdempotencyKey: "key-1"},
+		domain.Scan{UserID: userA, URL: url, Status: domain.ScanStatusPending},
+		domain.Scan{UserID: userA, URL: url, Status: domain.ScanStatusPending},
+	)
+	require.NoError(t, err)
+	require.Len(t, stored, 5)
+
+	// same key + user returns the same scan
+	got, err := pgSQL.ScanByIdempotencyKey(ctx, userA, "key-1")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Equal(t, stored[0].ID, got.ID)
+	require.Equal(t, "key-1", got.IdempotencyKey)
+
+	// different keys and users map to distinct scans
+	got, err = pgSQL.ScanByIdempotencyKey(ctx, userA, "key-2")
+	require.NoError(t, err)
+	require.Equal(t, stored[1].ID, got.ID)
+	got, err = pgSQL.ScanByIdempotencyKey(ctx, userB, "key-1")
+	require.NoError(t, err)
+	require.Equal(t, stored[2].ID, got.ID)
+
+	// unknown key
+	got, err = pgSQL.ScanByIdempotencyKey(ctx, userA, "missing")
+	require.NoError(t, err)
+	require.Nil(t, got)
+
+	// duplicate key for the same user violates the unique constraint
+	_, err = pgSQL.StoreScans(ctx, domain.Scan{UserID: userA, URL: url, Status: domain.ScanStatusPending, IdempotencyKey: "key-1"})
+	require.Error(t, err)
+
+	// once deleted, the key is released
+	_, err = pgSQL.DeleteScan(ctx, userA, stored[0].ID)
+	require.NoError(t, err)
+	got, err = pgSQL.ScanByIdempotencyKey(ctx, userA, "key-1")
+	require.NoError(t, err)
+	require.Nil(t, got)
+	_, err = pgSQL.StoreScans(ctx, domain.Scan{UserID: userA, URL: url, Status: domain.ScanStatusPending, IdempotencyKey: "key-1"})
+	require.NoError(t, err)
+}
+
+func TestPgSQL_UpsertScan(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	userA := domain.UserID(uuid.New())
+	userB := domain.UserID(uuid.New())
+	url := "https://upsert.example"
+
+	// first insert stores the scan
+	first, created, err := pgSQL.UpsertScan(ctx,
+		domain.Scan{UserID: userA, URL: url, Status: domain.ScanStatusPending, IdempotencyKey: "key-1"})
+	require.NoError(t, err)
+	require.True(t, created)
+	require.NotEqual(t, uuid.Nil, uuid.UUID(first.ID))
+	require.Equal(t, "key-1", first.IdempotencyKey)
+
+	// a conflicting insert returns the original scan without storing a new one
+	second, created, err := pgSQL.UpsertScan(ctx,
+		domain.Scan{UserID: userA, URL: "https://other.example", Status: domain.ScanStatusPending, IdempotencyKey: "key-1"})
+	require.NoError(t, err)
+	require.False(t, created)
+	require.Equal(t, first.ID, second.ID)
+	require.Equal(t, url, second.URL)
+
+	// the same key of another user does not conflict
+	other, created, err := pgSQL.UpsertScan(ctx,
+		domain.Scan{UserID: userB, URL: url, Status: domain.ScanStatusPending, IdempotencyKey: "key-1"})
+	require.NoError(t, err)
+	require.True(t, created)
+	require.NotEqual(t, first.ID, other.ID)
+
+	// scans without a key are always inserted
+	for range 2 {
+		_, created, err = pgSQL.UpsertScan(ctx, domain.Scan{UserID: userA, URL: url, Status: domain.ScanStatusPending})
+		require.NoError(t, err)
+		require.True(t, created)
+	}
+	scans, err := pgSQL.UserScans(ctx, userA, "", false, time.Time{}, storage.PageNext, 10)
+	require.NoError(t, err)
+	require.Len(t, scans.Scans, 3)
+}
+
+func TestPgSQL_ScanExists(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	owner := domain.UserID(uuid.New())
+	stored, err := pgSQL.StoreScans(ctx, domain.Scan{UserID: owner, URL: urlA, Status: domain.ScanStatusPending})
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	id := stored[0].ID
+
+	// exists regardless of the owner
+	exists, err := pgSQL.ScanExists(ctx, id)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	// unknown id
+	exists, err = pgSQL.ScanExists(ctx, domain.ScanID(uuid.New()))
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	// deleted scans do not exist
+	_, err = pgSQL.DeleteScan(ctx, owner, id)
+	require.NoError(t, err)
+	exists, err = pgSQL.ScanExists(ctx, id)
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestPgSQL_AdminListScans(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	user1 := domain.UserID(uuid.New())
+	user2 := domain.UserID(uuid.New())
+	_, err := pgSQL.StoreScans(ctx,
+		domain.Scan{UserID: user1, URL: urlA, Status: domain.ScanStatusPending},
+		domain.Scan{UserID: user2, URL: urlA, Status: domain.ScanStatusCompleted},
+		domain.Scan{UserID: user2, URL: urlB, Status: domain.ScanStatusPending},
+	)
+	require.NoError(t, err)
+	deleted, err := pgSQL.StoreScans(ctx, domain.Scan{UserID: user1, URL: urlB, Status: domain.ScanStatusPending})
+	require.NoError(t, err)
+	_, err = pgSQL.DeleteScan(ctx, user1, deleted[0].ID)
+	require.NoError(t, err)
+
+	// all users, deleted scans excluded
+	page, err := pgSQL.AdminListScans(ctx, storage.AdminScanFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Scans, 3)
+	require.Nil(t, page.NextCursor)
+
+	// status and URL filters
+	page, err = pgSQL.AdminListScans(ctx, storage.AdminScanFilter{Status: domain.ScanStatusPending, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Scans, 2)
+	page, err = pgSQL.AdminListScans(ctx, storage.AdminScanFilter{URL: urlA, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Scans, 2)
+
+	// time filters
+	page, err = pgSQL.AdminListScans(ctx, storage.AdminScanFilter{CreatedAfter: time.Now().Add(time.Hour), Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, page.Scans)
+	page, err = pgSQL.AdminListScans(ctx, storage.AdminScanFilter{CreatedBefore: time.Now().Add(time.Hour), Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Scans, 3)
+
+	// pagination
+	page, err = pgSQL.AdminListScans(ctx, storage.AdminScanFilter{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Scans, 2)
+	require.NotNil(t, page.NextCursor)
+}
+
+func TestPgSQL_AdminScanByID(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	owner := domain.UserID(uuid.New())
+	stored, err := pgSQL.StoreScans(ctx, domain.Scan{UserID: owner, URL: urlA, Status: domain.ScanStatusPending})
+	require.NoError(t, err)
+	id := stored[0].ID
+
+	got, err := pgSQL.AdminScanByID(ctx, id)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Equal(t, owner, got.UserID)
+
+	got, err = pgSQL.AdminScanByID(ctx, domain.ScanID(uuid.New()))
+	require.NoError(t, err)
+	require.Nil(t, got)
+
+	_, err = pgSQL.DeleteScan(ctx, owner, id)
+	require.NoError(t, err)
+	got, err = pgSQL.AdminScanByID(ctx, id)
+	require.NoError(t, err)
+	require.Nil(t, got)
+}
+
+func TestPgSQL_ScansByIDs(t *testing.T) {
+	t.Parallel()
+
+	pgSQL, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	user := domain.UserID(uuid.New())
+	other := domain.UserID(uuid.New())
+	mine, err := pgSQL.StoreScans(ctx,
+		domain.Scan{UserID: user, URL: urlA, Status: domain.ScanStatusPending},
+		domain.Scan{UserID: user, URL: urlB, Status: domain.ScanStatusCompleted},
+		domain.Scan{UserID: user, URL: urlB, Status: domain.ScanStatusPending},
+	)
+	require.NoError(t, err)
+	theirs, err := pgSQL.StoreScans(ctx, domain.Scan{UserID: other, URL: urlA, Status: domain.ScanStatusPending})
+	require.NoError(t, err)
+	_, err = pgSQL.DeleteScan(ctx, user, mine[2].ID)
+	require.NoError(t, err)
+
+	// mixed existing, non-existing, deleted and other users' ids
+	got, err := pgSQL.ScansByIDs(ctx, user, []domain.ScanID{
+		mine[0].ID,
+		mine[1].ID,
+		mine[2].ID,
+		theirs[0].ID,
+		domain.ScanID(uuid.New()),
+	})
+	require.NoError(t, err)
+	ids := make([]domain.ScanID, 0, len(got))
+	for _, sc := range got {
+		ids = append(ids, sc.ID)
+	}
+	require.ElementsMatch(t, []domain.ScanID{mine[0].ID, mine[1].ID}, ids)
+
+	// the owner still sees their scan
+	got, err = pgSQL.ScansByIDs(ctx, other, []domain.ScanID{theirs[0].ID, mine[0].ID})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	require.Equal(t, theirs[0].ID, got[0].ID)
+
+	// no ids
+	got, err = pgSQL.ScansByIDs(ctx, user, nil)
+	require.NoError(t, err)
+	require.Empty(t, got)
 }
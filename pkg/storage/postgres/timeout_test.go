@@ -0,0 +1,56 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"scanner/pkg/domain"
+	"scanner/pkg/serrors"
+	"scanner/pkg/storage/postgres"
+	"testing"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingDB is a stubDB whose queries block until their context is done.
+type blockingDB struct {
+	stubDB
+}
+
+func (b *blockingDB) QueryContext(ctx context.Context, query string, _ ...any) (*sql.Rows, error) {
+	b.queries = append(b.queries, query)
+	<-ctx.Done()
+
+	return nil, ctx.Err()
+}
+
+func TestPgSQL_QueryTimeout(t *testing.T) {
+	pg := &postgres.PgSQL{Builder: goqu.Dialect("postgres").DB(&blockingDB{})}
+	postgres.SetQueryTimeout(pg, 10*time.Millisecond)
+
+	start := time.Now()
+	_, err := pg.ScanByID(context.Background(), domain.UserID(uuid.New()), domain.ScanID(uuid.New()))
+	require.ErrorIs(t, err, serrors.ErrTimeout)
+	require.Less(t, time.Since(start), time.Second)
+
+	// without a timeout other errors are returned as is
+	postgres.SetQueryTimeout(pg, 0)
+	pg.Builder = goqu.Dialect("postgres").DB(&stubDB{})
+	_, err = pg.ScanByID(context.Background(), domain.UserID(uuid.New()), domain.ScanID(uuid.New()))
+	require.ErrorIs(t, err, errStubDB)
+	require.NotErrorIs(t, err, serrors.ErrTimeout)
+}
+
+func TestPgSQL_QueryTimeout_Postgres(t *testing.T) {
+	pg, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	postgres.SetQueryTimeout(pg, 100*time.Millisecond)
+
+	err := postgres.Sleep(context.Background(), pg, time.Second)
+	require.ErrorIs(t, err, serrors.ErrTimeout)
+
+	require.NoError(t, postgres.Sleep(context.Background(), pg, 10*time.Millisecond))
+}
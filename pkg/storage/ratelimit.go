@@ -0,0 +1,17 @@
+package storage
+
+import (
+	"context"
+	"scanner/pkg/urlscanner"
+)
+
+// RateLimitStorage persists the last known upstream rate-limit status so that
+// background workers can resume with an accurate budget after a restart instead
+// of probing the upstream API again.
+type RateLimitStorage interface {
+	// RateLimitStatus returns the stored rate-limit status for the given key.
+	// Returns nil when no status has been stored yet.
+	RateLimitStatus(ctx context.Context, key string) (*urlscanner.RateLimitStatus, error)
+	// StoreRateLimitStatus inserts or replaces the rate-limit status for the given key.
+	StoreRateLimitStatus(ctx context.Context, key string, status urlscanner.RateLimitStatus) error
+}
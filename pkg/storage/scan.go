@@ -13,6 +13,12 @@ type ScanUpdates struct {
 	Status domain.ScanStatus
 	// Result, when provided, replaces the stored scan result payload.
 	Result *domain.ScanResult
+	// MergeResult deep-merges Result into the stored payload instead of
+	// replacing it. Sections (page, verdicts, stats) absent from Result are
+	// preserved, and so are the fields of a section that Result omits, e.g. the
+	// verdict categories. Fields that are always encoded and arrays overwrite
+	// the stored ones.
+	MergeResult bool
 	// LastError, when provided, sets the last error text. An empty string value
 	// indicates the error should be cleared (set to NULL).
 	LastError *string
@@ -20,53 +26,185 @@ type ScanUpdates struct {
 	// is only updated to Failed if the current attempts after increment would
 	// exceed this threshold. A value <= 0 disables this guard.
 	MaxAttempts int
+	// ExpectedVersion, when > 0, makes UpdateScanByID fail with a conflict error
+	// unless the stored version equals it. UpdatePendingScansByURL ignores it.
+	ExpectedVersion int64
 }
 
-// UserScans groups a page of scans returned for a user together with an
-// optional NextCursor used for pagination.
+// UserScans groups a page of scans returned for a user together with the
+// optional NextCursor and PrevCursor used for pagination.
 type UserScans struct {
 	// Scans contains the current page of scan records.
 	Scans []domain.Scan
 	// NextCursor points to the timestamp to be used as the cursor for fetching
 	// the next page. It is nil when there is no next page.
 	NextCursor *time.Time
+	// PrevCursor points to the timestamp to be used as the cursor for fetching
+	// the previous page with PagePrev. It is nil when there is no previous page.
+	// Only UserScans sets it.
+	PrevCursor *time.Time
+}
+
+// PageDirection selects whether UserScans returns the scans created before or
+// after its cursor.
+type PageDirection string
+
+const (
+	// PageNext returns the page of older scans created before the cursor.
+	PageNext PageDirection = "next"
+	// PagePrev returns the page of newer scans created after the cursor.
+	PagePrev PageDirection = "prev"
+)
+
+// Valid reports whether d is a known page direction.
+func (d PageDirection) Valid() bool {
+	return d == PageNext || d == PagePrev
+}
+
+// AdminScanFilter narrows the scans returned by AdminListScans. Zero-valued
+// fields are ignored.
+type AdminScanFilter struct {
+	// Status filters results to scans with the given status.
+	Status domain.ScanStatus
+	// URL filters results to scans of the given (normalized) URL.
+	URL string
+	// CreatedAfter filters results to scans created at or after this time.
+	CreatedAfter time.Time
+	// CreatedBefore filters results to scans created before this time.
+	CreatedBefore time.Time
+	// Cursor returns scans created before the given time; it is the NextCursor
+	// of the previous page.
+	Cursor time.Time
+	// Limit is the maximum number of scans returned.
+	Limit uint
 }
 
 // ScanStorage defines CRUD and query operations related to scans. Implementations
 // should ensure idempotency and proper handling of soft-deletes where applicable.
 type ScanStorage interface {
 	// StoreScans inserts one or more scans and returns the stored rows as they
-	// exist in the database (including generated fields).
+	// exist in the database (including generated fields), in the order of the input.
 	StoreScans(ctx context.Context, scans ...domain.Scan) ([]domain.Scan, error)
-	// UpdatePendingScansByURL updates all pending scans for the given URL using
-	// the provided field set.
+	// UpsertScan inserts the scan unless its user already has a non-deleted scan
+	// with the same idempotency key, in which case that scan is returned instead.
+	// The returned bool reports whether the scan was inserted. Scans without an
+	// idempotency key are always inserted.
+	UpsertScan(ctx context.Context, scan domain.Scan) (*domain.Scan, bool, error)
+	// UpdatePendingScansByURL updates the pending scans of the given URL that the
+	// job of owner scans, using the provided field set. A non-zero owner selects
+	// the owner's non-shareable scans, the zero owner the shareable scans of all
+	// users, so that results are only shared as far as their visibility allows.
 	// Notes:
 	// - Attempts is incremented by 1 and updated_at is set automatically.
 	// - If Status is Failed and MaxAttempts > 0, status is only set to Failed
 	//   when the attempts after increment would exceed MaxAttempts; otherwise
 	//   status remains unchanged (i.e., stays Pending).
-	UpdatePendingScansByURL(ctx context.Context, URL string, updates ScanUpdates) error
-	// PendingScanCountByURL returns the total number of pending scans for the given URL
-	// across all users. Soft-deleted records are excluded from the count.
-	PendingScanCountByURL(ctx context.Context, URL string) (int64, error)
+	// It returns the number of updated scans.
+	UpdatePendingScansByURL(ctx context.Context, URL string, owner domain.UserID, updates ScanUpdates) (int64, error)
+	// PendingScanCountByURL returns the number of pending scans of the given URL
+	// that the job of owner scans, selected like in UpdatePendingScansByURL.
+	// Soft-deleted records are excluded from the count.
+	PendingScanCountByURL(ctx context.Context, URL string, owner domain.UserID) (int64, error)
+	// PendingScanCountByUser returns the number of pending scans of the given user.
+	// Soft-deleted records are excluded from the count. Within a transaction, it
+	// also serializes concurrent transactions counting the same user's scans until
+	// the transaction ends, so that a cap on pending scans cannot be raced.
+	PendingScanCountByUser(ctx context.Context, userID domain.UserID) (int64, error)
+	// StalePendingScans returns up to limit pending scans of all users that were
+	// last updated, or created if never updated, before olderThan, oldest first,
+	// excluding soft-deleted records. Pages continue after the scan after, the
+	// last one of the previous page, or start from the oldest scan when it is
+	// nil. It is used to find scans whose job was lost, e.g. when a worker died
+	// mid-scan.
+	StalePendingScans(ctx context.Context, olderThan time.Time, after *domain.Scan, limit uint) ([]domain.Scan, error)
+	// ScanStatusCounts returns the number of scans of the given user per status.
+	// Soft-deleted records are excluded, and statuses without scans are omitted.
+	ScanStatusCounts(ctx context.Context, userID domain.UserID) (map[domain.ScanStatus]int64, error)
 	// UpdateScanByID updates a single scan identified by its ID and returns the updated row.
 	// The update ignores soft-deleted rows and sets updated_at automatically. Only provided fields are changed.
+	// When ExpectedVersion is set and does not match, a conflict error wrapping ErrVersionMismatch is returned.
+	// It is not scoped to a user and must only be used by the worker and operator paths;
+	// user-initiated paths use UpdateScanByIDForUser.
 	UpdateScanByID(ctx context.Context, ID domain.ScanID, updates ScanUpdates) (*domain.Scan, error)
+	// UpdateScanByIDForUser works like UpdateScanByID but only updates the scan when it
+	// belongs to the given user; otherwise nil is returned as for a missing scan.
+	UpdateScanByIDForUser(ctx context.Context, userID domain.UserID, ID domain.ScanID, updates ScanUpdates) (*domain.Scan, error)
 	// DeleteScan performs a soft delete for the given scan ID and user ID and
-	// returns the deleted scan, or nil if it was not found.
+	// returns the deleted scan, or nil if it was not found. A pending scan is
+	// marked as canceled, while other statuses are kept for auditing.
 	DeleteScan(ctx context.Context, userID domain.UserID, ID domain.ScanID) (*domain.Scan, error)
 	// UserScans returns a page of scans for a user created before the optional
-	// cursor time, limited by the given limit. If status is non-empty, results are
-	// filtered to records with the given status.
+	// cursor time, or after it with PagePrev, limited by the given limit. Pages
+	// are ordered newest first in both directions. If status is non-empty,
+	// results are filtered to records with the given status. If maliciousOnly is
+	// set, results are filtered to records whose result has a malicious verdict.
 	UserScans(ctx context.Context,
 		userID domain.UserID,
 		status domain.ScanStatus,
+		maliciousOnly bool,
+		cursor time.Time,
+		direction PageDirection,
+		limit uint) (UserScans, error)
+	// SearchScans returns a page of scans for a user whose result page field
+	// named by key equals value, created before the optional cursor time and
+	// excluding soft-deleted records. Unknown keys are rejected.
+	SearchScans(ctx context.Context,
+		userID domain.UserID,
+		key domain.ScanSearchKey,
+		value string,
 		cursor time.Time,
 		limit uint) (UserScans, error)
+	// AdminListScans returns a page of scans across all users matching the given
+	// filter, excluding soft-deleted records. It is meant for operators only.
+	AdminListScans(ctx context.Context, filter AdminScanFilter) (UserScans, error)
+	// AdminScanByID fetches a scan by its ID regardless of the user owning it,
+	// excluding soft-deleted records. Returns nil when not found.
+	AdminScanByID(ctx context.Context, ID domain.ScanID) (*domain.Scan, error)
 	// ScanByID fetches a scan by its ID for the given user, excluding soft-deleted
 	// records. Returns nil when not found.
 	ScanByID(ctx context.Context, userID domain.UserID, ID domain.ScanID) (*domain.Scan, error)
-	// LastCompletedScanByURL returns the most recent completed scan for a given URL across all users.
-	// Returns nil when no completed scan exists for the URL.
-	LastCompletedScanByURL(ctx context.Context, URL string) (*domain.Scan, error)
+	// ScansByIDs fetches the user's scans with the given IDs in a single query,
+	// excluding soft-deleted records. IDs without a matching scan are skipped,
+	// and the order of the result is unspecified.
+	ScansByIDs(ctx context.Context, userID domain.UserID, IDs []domain.ScanID) ([]domain.Scan, error)
+	// ScanExists reports whether a non-deleted scan with the given ID exists, regardless of
+	// the user owning it.
+	ScanExists(ctx context.Context, ID domain.ScanID) (bool, error)
+	// ScanByIdempotencyKey fetches the user's scan created with the given idempotency key,
+	// excluding soft-deleted records. Returns nil when not found.
+	ScanByIdempotencyKey(ctx context.Context, userID domain.UserID, key string) (*domain.Scan, error)
+	// LatestScanByURLForUser returns the most recently created scan of the given URL owned by the user,
+	// excluding soft-deleted records. Returns nil when the user has no scan for the URL.
+	LatestScanByURLForUser(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error)
+	// LastCompletedScanByURL returns the most recent completed scan for a given URL whose result
+	// the given user may reuse: a shareable scan of any user, or one of the user's own scans,
+	// excluding soft-deleted records. Returns nil when no such scan exists for the URL.
+	LastCompletedScanByURL(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error)
+	// LastScanByURL returns the most recent scan for a given URL in any terminal status, e.g. a
+	// failed one, that the given user may see: a shareable scan of any user, or one of the user's
+	// own scans, excluding soft-deleted records. Only scans that were actually processed, i.e.
+	// that have recorded attempts, are considered, so scans finalized by copying an earlier
+	// outcome are skipped. Returns nil when no such scan exists for the URL.
+	LastScanByURL(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error)
+	// LastCompletedScanByURLForUser returns the most recent completed scan for a given URL owned by
+	// the user, excluding soft-deleted records. Returns nil when the user has no such scan.
+	LastCompletedScanByURLForUser(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error)
+	// LastScanByURLForUser returns the most recent scan for a given URL in any terminal status owned
+	// by the user, excluding soft-deleted records and, like LastScanByURL, scans without recorded
+	// attempts. Returns nil when the user has no such scan.
+	LastScanByURLForUser(ctx context.Context, userID domain.UserID, URL string) (*domain.Scan, error)
+	// RecordScanAttempts appends the given attempt to the attempts of every pending scan
+	// of the URL that the job of owner scans, selected like in UpdatePendingScansByURL,
+	// excluding soft-deleted records. ID, ScanID and CreatedAt of the attempt are
+	// ignored. It should be called before the attempt updates the pending scans.
+	RecordScanAttempts(ctx context.Context, URL string, owner domain.UserID, attempt ScanAttempt) error
+	// ScanAttemptsByID returns the recorded attempts of the scan, oldest first.
+	ScanAttemptsByID(ctx context.Context, ID domain.ScanID) ([]ScanAttempt, error)
+	// RecordAudit appends an entry for the action performed by the user on the scan
+	// to the audit log. It should be called within the transaction of the action so
+	// that both are committed or rolled back together.
+	RecordAudit(ctx context.Context, userID domain.UserID, action AuditAction, scanID domain.ScanID) error
+	// AuditLog returns a page of audit log entries matching the given filter, newest
+	// first. It is meant for operators only.
+	AuditLog(ctx context.Context, filter AuditFilter) (AuditEntries, error)
 }
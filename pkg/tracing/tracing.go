@@ -0,0 +1,96 @@
+// Package tracing sets up the OpenTelemetry tracer provider of the service and
+// provides helpers shared by instrumented packages.
+package tracing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"scanner/pkg/logger"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// URLHashKey is the attribute key of the hashed URL of a scan. URLs are hashed
+// since they may carry personal data or credentials.
+const URLHashKey = attribute.Key("url.hash")
+
+// URLHash returns the attribute holding the SHA-256 hex digest of URL.
+func URLHash(URL string) attribute.KeyValue {
+	sum := sha256.Sum256([]byte(URL))
+
+	return URLHashKey.String(hex.EncodeToString(sum[:]))
+}
+
+// End records err on span, if any, and ends it. It is meant to be deferred
+// with a pointer to the named error result of the traced function.
+func End(span trace.Span, err *error) {
+	if err != nil && *err != nil {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+	}
+	span.End()
+}
+
+// Options configures the tracer provider built by NewProvider.
+type Options struct {
+	// SampleRatio is the fraction of traces sampled, from 0 to 1. Child spans
+	// follow the sampling decision of their parent.
+	SampleRatio float64
+	// Exporter receives the finished spans. When nil, LogExporter is used.
+	Exporter sdktrace.SpanExporter
+}
+
+// NewProvider returns a tracer provider sampling and exporting spans as
+// configured by opts. It should be shut down to flush pending spans.
+func NewProvider(opts Options) *sdktrace.TracerProvider {
+	exporter := opts.Exporter
+	if exporter == nil {
+		exporter = LogExporter{}
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+		sdktrace.WithBatcher(exporter),
+	)
+}
+
+// LogExporter is a span exporter writing finished spans at debug level. Spans
+// are exported in batches from a background goroutine, so the logger of the
+// traced request is not available.
+type LogExporter struct {
+	// Logger receives the spans. When nil, the default logger is used.
+	Logger *zap.Logger
+}
+
+// ExportSpans implements sdktrace.SpanExporter.
+func (e LogExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if e.Logger != nil {
+		ctx = logger.WithLogger(ctx, e.Logger)
+	}
+
+	for _, span := range spans {
+		fields := []zap.Field{
+			zap.String("traceID", span.SpanContext().TraceID().String()),
+			zap.String("spanID", span.SpanContext().SpanID().String()),
+			zap.String("parentSpanID", span.Parent().SpanID().String()),
+			zap.Duration("duration", span.EndTime().Sub(span.StartTime())),
+			zap.String("status", span.Status().Code.String()),
+		}
+		for _, attr := range span.Attributes() {
+			fields = append(fields, zap.Any(string(attr.Key), attr.Value.AsInterface()))
+		}
+		logger.Debug(ctx, "span "+span.Name(), fields...)
+	}
+
+	return nil
+}
+
+// Shutdown implements sdktrace.SpanExporter.
+func (LogExporter) Shutdown(context.Context) error {
+	return nil
+}
@@ -0,0 +1,73 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"scanner/pkg/tracing"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestURLHash(t *testing.T) {
+	hash := tracing.URLHash("https://example.com/")
+	require.Equal(t, tracing.URLHashKey, hash.Key)
+	require.Len(t, hash.Value.AsString(), 64)
+	require.NotContains(t, hash.Value.AsString(), "example")
+	require.Equal(t, hash, tracing.URLHash("https://example.com/"))
+	require.NotEqual(t, hash, tracing.URLHash("https://example.org/"))
+}
+
+func TestEnd(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)).Tracer("test")
+
+	_, span := tracer.Start(context.Background(), "ok")
+	var err error
+	tracing.End(span, &err)
+
+	_, span = tracer.Start(context.Background(), "failed")
+	err = errors.New("boom")
+	tracing.End(span, &err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	require.Equal(t, codes.Unset, spans[0].Status.Code)
+	require.Empty(t, spans[0].Events)
+	require.Equal(t, codes.Error, spans[1].Status.Code)
+	require.Equal(t, "boom", spans[1].Status.Description)
+	require.Len(t, spans[1].Events, 1)
+}
+
+func TestNewProvider_LogsSpans(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	ctx := context.Background()
+
+	provider := tracing.NewProvider(tracing.Options{
+		SampleRatio: 1,
+		Exporter:    tracing.LogExporter{Logger: zap.New(core)},
+	})
+	_, span := provider.Tracer("test").Start(ctx, "work")
+	span.End()
+	require.NoError(t, provider.Shutdown(ctx))
+
+	entries := logs.FilterMessage("span work").All()
+	require.Len(t, entries, 1)
+	require.Equal(t, span.SpanContext().TraceID().String(), entries[0].ContextMap()["traceID"])
+}
+
+func TestNewProvider_SampleRatio(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewProvider(tracing.Options{SampleRatio: 0, Exporter: exporter})
+	_, span := provider.Tracer("test").Start(context.Background(), "work")
+	span.End()
+	require.NoError(t, provider.ForceFlush(context.Background()))
+
+	require.False(t, span.SpanContext().IsSampled())
+	require.Empty(t, exporter.GetSpans())
+}
@@ -4,8 +4,13 @@ package urlscanner
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"scanner/pkg/domain"
+	"strings"
 	"time"
+
+	"golang.org/x/text/language"
 )
 
 // RateLimitStatus describes the current API rate‑limit status returned by the
@@ -16,6 +21,83 @@ type RateLimitStatus struct {
 	ResetAt   time.Time // ResetAt is when the rate‑limit window resets.
 }
 
+// Visibility controls who can see a submitted scan at the provider.
+type Visibility string
+
+const (
+	// VisibilityPublic lists the scan publicly; its result may be shared across users.
+	VisibilityPublic Visibility = "public"
+	// VisibilityUnlisted hides the scan from public listings.
+	VisibilityUnlisted Visibility = "unlisted"
+	// VisibilityPrivate makes the scan visible to the submitter only.
+	VisibilityPrivate Visibility = "private"
+)
+
+// Valid reports whether v is one of the known visibilities.
+func (v Visibility) Valid() bool {
+	switch v {
+	case VisibilityPublic, VisibilityUnlisted, VisibilityPrivate:
+		return true
+	default:
+		return false
+	}
+}
+
+const (
+	// MaxTags is the maximum number of tags attached to a submission.
+	MaxTags = 10
+	// MaxTagLength is the maximum length of a single tag.
+	MaxTagLength = 64
+)
+
+// ValidCountry reports whether code is an ISO 3166-1 alpha-2 country code,
+// in any letter case.
+func ValidCountry(code string) bool {
+	if len(code) != 2 {
+		return false
+	}
+	region, err := language.ParseRegion(code)
+
+	return err == nil && region.IsCountry() && region.String() == strings.ToUpper(code)
+}
+
+// SubmitOptions holds optional parameters of a URL submission.
+type SubmitOptions struct {
+	// Tags are attached to the scan at the provider for later filtering.
+	Tags []string
+	// Country is the ISO 3166-1 alpha-2 code of the country to scan from.
+	// Empty lets the provider choose.
+	Country string
+	// Visibility overrides the client's default visibility of the scan. Empty
+	// uses the default.
+	Visibility Visibility
+}
+
+// Validate checks that the options respect MaxTags and MaxTagLength, that no
+// tag is empty and that Country and Visibility, when set, are valid.
+func (o SubmitOptions) Validate() error {
+	if o.Country != "" && !ValidCountry(o.Country) {
+		return fmt.Errorf("%q is not an ISO 3166-1 alpha-2 country code", o.Country)
+	}
+	if o.Visibility != "" && !o.Visibility.Valid() {
+		return fmt.Errorf("visibility must be %s, %s or %s, not %q",
+			VisibilityPublic, VisibilityUnlisted, VisibilityPrivate, o.Visibility)
+	}
+	if len(o.Tags) > MaxTags {
+		return fmt.Errorf("at most %d tags are allowed", MaxTags)
+	}
+	for _, tag := range o.Tags {
+		if tag == "" {
+			return errors.New("tags must not be empty")
+		}
+		if len(tag) > MaxTagLength {
+			return fmt.Errorf("tags must be at most %d characters long", MaxTagLength)
+		}
+	}
+
+	return nil
+}
+
 // SubmitRes represents the response of a successful URL submission.
 type SubmitRes struct {
 	ID string // ID is the scan job identifier returned by the provider.
@@ -26,9 +108,17 @@ type SubmitRes struct {
 //
 //go:generate mockgen -package mockurlscanner -source=interface.go -destination=mock/mockurlscanner.go *
 type Client interface {
-	// SubmitURL submits the target URL for scanning and returns a provider
-	// job ID plus the current rate‑limit status.
-	SubmitURL(ctx context.Context, URL string) (SubmitRes, RateLimitStatus, error)
+	// SubmitURL submits the target URL for scanning with the given options and
+	// returns a provider job ID plus the current rate‑limit status.
+	SubmitURL(ctx context.Context, URL string, opts SubmitOptions) (SubmitRes, RateLimitStatus, error)
+	// Status reports whether the result of a previously submitted job is
+	// ready, without fetching the result itself.
+	Status(ctx context.Context, scanID string) (bool, error)
 	// Result retrieves the result for a previously submitted job by its ID.
 	Result(ctx context.Context, scanID string) (*domain.ScanResult, error)
+	// Results retrieves the results of several previously submitted jobs at
+	// once, keyed by job ID. Jobs whose result is not available yet are
+	// omitted from the returned maps; jobs whose result could not be fetched
+	// are reported with their error in the second map.
+	Results(ctx context.Context, scanIDs []string) (map[string]*domain.ScanResult, map[string]error)
 }
@@ -57,10 +57,40 @@ func (mr *MockClientMockRecorder) Result(ctx, scanID any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Result", reflect.TypeOf((*MockClient)(nil).Result), ctx, scanID)
 }
 
+// Results mocks base method.
+func (m *MockClient) Results(ctx context.Context, scanIDs []string) (map[string]*domain.ScanResult, map[string]error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Results", ctx, scanIDs)
+	ret0, _ := ret[0].(map[string]*domain.ScanResult)
+	ret1, _ := ret[1].(map[string]error)
+	return ret0, ret1
+}
+
+// Results indicates an expected call of Results.
+func (mr *MockClientMockRecorder) Results(ctx, scanIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Results", reflect.TypeOf((*MockClient)(nil).Results), ctx, scanIDs)
+}
+
+// Status mocks base method.
+func (m *MockClient) Status(ctx context.Context, scanID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Status", ctx, scanID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Status indicates an expected call of Status.
+func (mr *MockClientMockRecorder) Status(ctx, scanID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockClient)(nil).Status), ctx, scanID)
+}
+
 // SubmitURL mocks base method.
-func (m *MockClient) SubmitURL(ctx context.Context, URL string) (urlscanner.SubmitRes, urlscanner.RateLimitStatus, error) {
+func (m *MockClient) SubmitURL(ctx context.Context, URL string, opts urlscanner.SubmitOptions) (urlscanner.SubmitRes, urlscanner.RateLimitStatus, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SubmitURL", ctx, URL)
+	ret := m.ctrl.Call(m, "SubmitURL", ctx, URL, opts)
 	ret0, _ := ret[0].(urlscanner.SubmitRes)
 	ret1, _ := ret[1].(urlscanner.RateLimitStatus)
 	ret2, _ := ret[2].(error)
@@ -68,7 +98,7 @@ func (m *MockClient) SubmitURL(ctx context.Context, URL string) (urlscanner.Subm
 }
 
 // SubmitURL indicates an expected call of SubmitURL.
-func (mr *MockClientMockRecorder) SubmitURL(ctx, URL any) *gomock.Call {
+func (mr *MockClientMockRecorder) SubmitURL(ctx, URL, opts any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitURL", reflect.TypeOf((*MockClient)(nil).SubmitURL), ctx, URL)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitURL", reflect.TypeOf((*MockClient)(nil).SubmitURL), ctx, URL, opts)
 }
@@ -5,9 +5,11 @@ package urlscanio
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"scanner/pkg/domain"
 	"scanner/pkg/serrors"
 	"scanner/pkg/urlscanner"
@@ -21,6 +23,27 @@ import (
 type Client struct {
 	httpClient *http.Client // httpClient performs HTTP requests to urlscan.io
 	token      string       // token is the API key for urlscan.io
+	visibility string       // visibility of submitted scans
+	userAgent  string       // userAgent is sent with every request
+}
+
+// UserAgent returns the User-Agent identifying the given version of the
+// application, url-scanner/<version>. It is sent when none is configured.
+func UserAgent(version string) string {
+	return "url-scanner/" + version
+}
+
+// newRequest creates a request to urlscan.io carrying the API key and the
+// User-Agent of the client.
+func (c *Client) newRequest(ctx context.Context, method, URL string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, URL, body)
+	if err != nil {
+		return nil, err //nolint: wrapcheck
+	}
+	req.Header.Set("Api-Key", c.token)
+	req.Header.Set("User-Agent", c.userAgent)
+
+	return req, nil
 }
 
 // ParseRateLimit extracts urlscan.io rate‑limit information from the HTTP
@@ -51,21 +74,55 @@ func ParseRateLimit(h http.Header) (urlscanner.RateLimitStatus, error) {
 	return urlscanner.RateLimitStatus{Limit: limit, Remaining: remaining, ResetAt: resetAt}, nil
 }
 
-// SubmitURL submits the provided URL to urlscan.io for scanning.
+// rateLimitedError returns the error of a 429 response with the given body,
+// hinting callers to retry once the rate limit of rl resets, when known.
+func rateLimitedError(body []byte, rl urlscanner.RateLimitStatus) error {
+	err := serrors.With(serrors.ErrRateLimited, "rate limited: %s", strings.TrimSpace(string(body)))
+	if !rl.ResetAt.IsZero() {
+		err = err.WithRetryAfter(time.Until(rl.ResetAt))
+	}
+
+	return err
+}
+
+// SubmitURL submits the provided URL to urlscan.io for scanning, attaching the
+// tags of opts to the scan and scanning from its country when set.
 // It returns the provider job identifier, the parsed rate‑limit status from
-// the response headers, and an error if the submission failed.
-func (c *Client) SubmitURL(ctx context.Context, URL string) (urlscanner.SubmitRes, urlscanner.RateLimitStatus, error) {
+// the response headers, and an error if the submission failed. Invalid
+// options are rejected with a bad-request error without sending a request.
+func (c *Client) SubmitURL(
+	ctx context.Context,
+	URL string,
+	opts urlscanner.SubmitOptions,
+) (urlscanner.SubmitRes, urlscanner.RateLimitStatus, error) {
+	if err := opts.Validate(); err != nil {
+		return urlscanner.SubmitRes{},
+			urlscanner.RateLimitStatus{},
+			serrors.Wrap(serrors.ErrBadRequest, err, "invalid submit options")
+	}
+
 	// https://docs.urlscan.io/apis/urlscan-openapi/scanning/submitscan
 	type submitReq struct {
-		URL        string `json:"url"`
-		Visibility string `json:"visibility,omitempty"`
+		URL        string   `json:"url"`
+		Visibility string   `json:"visibility,omitempty"`
+		Tags       []string `json:"tags,omitempty"`
+		Country    string   `json:"country,omitempty"`
 	}
-	bodyBytes, err := json.Marshal(submitReq{URL: URL, Visibility: "public"})
+	visibility := c.visibility
+	if opts.Visibility != "" {
+		visibility = string(opts.Visibility)
+	}
+	bodyBytes, err := json.Marshal(submitReq{
+		URL:        URL,
+		Visibility: visibility,
+		Tags:       opts.Tags,
+		Country:    strings.ToLower(opts.Country),
+	})
 	if err != nil {
 		return urlscanner.SubmitRes{}, urlscanner.RateLimitStatus{}, fmt.Errorf("could not marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx,
+	req, err := c.newRequest(ctx,
 		http.MethodPost,
 		"https://urlscan.io/api/v1/scan",
 		strings.NewReader(string(bodyBytes)))
@@ -73,7 +130,6 @@ func (c *Client) SubmitURL(ctx context.Context, URL string) (urlscanner.SubmitRe
 		return urlscanner.SubmitRes{}, urlscanner.RateLimitStatus{}, fmt.Errorf("could not create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Api-Key", c.token)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -92,9 +148,7 @@ func (c *Client) SubmitURL(ctx context.Context, URL string) (urlscanner.SubmitRe
 		return urlscanner.SubmitRes{}, rl, fmt.Errorf("could not read response body: %w", err)
 	}
 	if resp.StatusCode == http.StatusTooManyRequests {
-		return urlscanner.SubmitRes{},
-			rl,
-			serrors.With(serrors.ErrRateLimited, "rate limited: %s", strings.TrimSpace(string(b)))
+		return urlscanner.SubmitRes{}, rl, rateLimitedError(b, rl)
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return urlscanner.SubmitRes{}, rl, fmt.Errorf("submit failed: %s", strings.TrimSpace(string(b)))
@@ -111,16 +165,42 @@ func (c *Client) SubmitURL(ctx context.Context, URL string) (urlscanner.SubmitRe
 	return urlscanner.SubmitRes{ID: submitResp.UUID}, rl, nil
 }
 
+// Status reports whether the result of the given scan is available on
+// urlscan.io. It sends a HEAD request to the result API, which responds with
+// 404 until the scan finished, so the result body is never downloaded.
+func (c *Client) Status(ctx context.Context, scanID string) (bool, error) {
+	req, err := c.newRequest(ctx, http.MethodHead, "https://urlscan.io/api/v1/result/"+scanID, nil)
+	if err != nil {
+		return false, fmt.Errorf("could not create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("could not send request: %w", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return false, fmt.Errorf("get status failed: %s", resp.Status)
+	}
+
+	return true, nil
+}
+
 // Result fetches and decodes the scan result for the given scanID from
 // urlscan.io. It returns domain.ScanResult when available, ErrNotFound when the
 // scan is not yet available or does not exist, or another error on failure.
+// A 200 response with the 404 error envelope urlscan.io sends while the
+// result is still being processed is treated as not yet available.
 func (c *Client) Result(ctx context.Context, scanID string) (*domain.ScanResult, error) {
 	// https://docs.urlscan.io/apis/urlscan-openapi/scanning/resultapi
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://urlscan.io/api/v1/result/"+scanID, nil)
+	req, err := c.newRequest(ctx, http.MethodGet, "https://urlscan.io/api/v1/result/"+scanID, nil)
 	if err != nil {
 		return nil, fmt.Errorf("could not create request: %w", err)
 	}
-	req.Header.Set("Api-Key", c.token)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -143,7 +223,11 @@ func (c *Client) Result(ctx context.Context, scanID string) (*domain.ScanResult,
 
 	// successful
 	var rs struct {
-		Page struct {
+		// Status and Message are only set on error envelopes, which are also
+		// sent with 200 while the result is still being processed.
+		Status  int    `json:"status"`
+		Message string `json:"message"`
+		Page    struct {
 			URL     string `json:"url"`
 			Domain  string `json:"domain"`
 			IP      string `json:"ip"`
@@ -153,8 +237,12 @@ func (c *Client) Result(ctx context.Context, scanID string) (*domain.ScanResult,
 		} `json:"page"`
 		Verdicts struct {
 			Overall struct {
-				Malicious bool `json:"malicious"`
-				Score     int  `json:"score"`
+				Malicious  bool     `json:"malicious"`
+				Score      int      `json:"score"`
+				Categories []string `json:"categories"`
+				Brands     []struct {
+					Name string `json:"name"`
+				} `json:"brands"`
 			} `json:"overall"`
 		} `json:"verdicts"`
 		Stats struct {
@@ -164,6 +252,10 @@ func (c *Client) Result(ctx context.Context, scanID string) (*domain.ScanResult,
 	if err := json.Unmarshal(b, &rs); err != nil {
 		return nil, fmt.Errorf("could not decode response: %w", err)
 	}
+	// a result still being processed is retried like a 404
+	if rs.Status == http.StatusNotFound {
+		return nil, serrors.With(serrors.ErrNotFound, "result not ready: %s", rs.Message)
+	}
 	out := &domain.ScanResult{}
 	out.Page = &struct {
 		URL     string `json:"url"`
@@ -181,11 +273,17 @@ func (c *Client) Result(ctx context.Context, scanID string) (*domain.ScanResult,
 		Server:  rs.Page.Server,
 	}
 	out.Verdict = &struct {
-		Malicious bool `json:"malicious"`
-		Score     int  `json:"score"`
+		Malicious  bool     `json:"malicious"`
+		Score      int      `json:"score"`
+		Categories []string `json:"categories,omitempty"`
+		Brands     []string `json:"brands,omitempty"`
 	}{
-		Malicious: rs.Verdicts.Overall.Malicious,
-		Score:     rs.Verdicts.Overall.Score,
+		Malicious:  rs.Verdicts.Overall.Malicious,
+		Score:      rs.Verdicts.Overall.Score,
+		Categories: rs.Verdicts.Overall.Categories,
+	}
+	for _, brand := range rs.Verdicts.Overall.Brands {
+		out.Verdict.Brands = append(out.Verdict.Brands, brand.Name)
 	}
 	out.Stats = &struct {
 		Malicious int `json:"malicious"`
@@ -196,14 +294,117 @@ func (c *Client) Result(ctx context.Context, scanID string) (*domain.ScanResult,
 	return out, nil
 }
 
+// searchMaxSize is the maximum number of scans looked up by a single search request.
+const searchMaxSize = 100
+
+// Results fetches the results of the given scans. Finished scans are found
+// with a single search request per searchMaxSize scans, so only their results
+// are fetched instead of polling every scan. Scans that are not finished, or
+// are not indexed by the search yet, are omitted from both returned maps. A
+// scan whose result could not be fetched is reported in the errors map without
+// affecting the others; a failed search reports its error for every scan it
+// looked up.
+func (c *Client) Results(ctx context.Context, scanIDs []string) (map[string]*domain.ScanResult, map[string]error) {
+	results := make(map[string]*domain.ScanResult, len(scanIDs))
+	errs := make(map[string]error)
+	for start := 0; start < len(scanIDs); start += searchMaxSize {
+		batch := scanIDs[start:min(start+searchMaxSize, len(scanIDs))]
+		finished, err := c.finishedScans(ctx, batch)
+		if err != nil {
+			for _, scanID := range batch {
+				errs[scanID] = err
+			}
+
+			continue
+		}
+
+		for _, scanID := range finished {
+			res, err := c.Result(ctx, scanID)
+			if errors.Is(err, serrors.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				errs[scanID] = err
+
+				continue
+			}
+			results[scanID] = res
+		}
+	}
+
+	return results, errs
+}
+
+// finishedScans returns the IDs among scanIDs that the search API knows about,
+// i.e. whose scan has finished.
+func (c *Client) finishedScans(ctx context.Context, scanIDs []string) ([]string, error) {
+	// https://docs.urlscan.io/apis/urlscan-openapi/search/search
+	query := url.Values{}
+	query.Set("q", "_id:("+strings.Join(scanIDs, " OR ")+")")
+	query.Set("size", strconv.Itoa(len(scanIDs)))
+	req, err := c.newRequest(ctx, http.MethodGet, "https://urlscan.io/api/v1/search/?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not read response body: %w", err)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		// a malformed reset time only loses the retry hint
+		rl, _ := ParseRateLimit(resp.Header)
+
+		return nil, rateLimitedError(b, rl)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("search failed: %s", strings.TrimSpace(string(b)))
+	}
+
+	var rs struct {
+		Results []struct {
+			ID string `json:"_id"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(b, &rs); err != nil {
+		return nil, fmt.Errorf("could not decode response: %w", err)
+	}
+	finished := make([]string, 0, len(rs.Results))
+	for _, r := range rs.Results {
+		finished = append(finished, r.ID)
+	}
+
+	return finished, nil
+}
+
 // Ensure Client conforms to the urlscanner.Client interface at compile time.
 var _ urlscanner.Client = (*Client)(nil)
 
 // New constructs a Client that uses the provided http.Client and API token
-// to interact with the urlscan.io API.
-func New(httpClient *http.Client, token string) *Client {
+// to interact with the urlscan.io API. URLs are submitted with the given
+// visibility; an empty one submits them publicly. Requests are sent with the
+// given User-Agent, or the one of an unversioned build, UserAgent("dev"), when
+// empty.
+func New(httpClient *http.Client, token string, visibility urlscanner.Visibility, userAgent string) *Client {
+	if visibility == "" {
+		visibility = urlscanner.VisibilityPublic
+	}
+	if userAgent == "" {
+		userAgent = UserAgent("dev")
+	}
+
 	return &Client{
 		httpClient: httpClient,
 		token:      token,
+		visibility: string(visibility),
+		userAgent:  userAgent,
 	}
 }
@@ -2,8 +2,10 @@ package urlscanio_test
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
+	"scanner/pkg/urlscanner"
 	"scanner/pkg/urlscanner/urlscanio"
 	"strings"
 	"testing"
@@ -20,7 +22,7 @@ type rtFunc func(*http.Request) (*http.Response, error)
 func (f rtFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
 
 func newTestClient(fn rtFunc) *urlscanio.Client {
-	return urlscanio.New(&http.Client{Transport: fn}, "test-token")
+	return urlscanio.New(&http.Client{Transport: fn}, "test-token", "", "test-agent")
 }
 
 func Test_parseRateLimit_success(t *testing.T) {
@@ -55,6 +57,7 @@ func TestClient_SubmitURL_success(t *testing.T) {
 		require.Equal(t, "/api/v1/scan", r.URL.Path)
 		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
 		require.Equal(t, "test-token", r.Header.Get("Api-Key"))
+		require.Equal(t, "test-agent", r.Header.Get("User-Agent"))
 
 		h := http.Header{}
 		h.Set("X-Rate-Limit-Limit", "100")
@@ -68,7 +71,7 @@ func TestClient_SubmitURL_success(t *testing.T) {
 		}, nil
 	})
 
-	res, rl, err := c.SubmitURL(context.Background(), "https://example.com")
+	res, rl, err := c.SubmitURL(context.Background(), "https://example.com", urlscanner.SubmitOptions{})
 	require.NoError(t, err)
 	require.Equal(t, "abc-123", res.ID)
 	require.Equal(t, 100, rl.Limit)
@@ -76,6 +79,131 @@ func TestClient_SubmitURL_success(t *testing.T) {
 	require.True(t, rl.ResetAt.Equal(resetAt))
 }
 
+func TestClient_SubmitURL_visibility(t *testing.T) {
+	// a per-request visibility takes precedence over the client's default,
+	// which falls back to public
+	cases := []struct {
+		visibility urlscanner.Visibility
+		override   urlscanner.Visibility
+		want       string
+	}{
+		{visibility: "", want: "public"},
+		{visibility: urlscanner.VisibilityUnlisted, want: "unlisted"},
+		{visibility: urlscanner.VisibilityPrivate, want: "private"},
+		{visibility: "", override: urlscanner.VisibilityPrivate, want: "private"},
+		{visibility: urlscanner.VisibilityPrivate, override: urlscanner.VisibilityPublic, want: "public"},
+		{visibility: urlscanner.VisibilityPublic, override: urlscanner.VisibilityUnlisted, want: "unlisted"},
+	}
+	for _, tc := range cases {
+		c := urlscanio.New(&http.Client{Transport: rtFunc(func(r *http.Request) (*http.Response, error) {
+			var body struct {
+				Visibility string `json:"visibility"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, tc.want, body.Visibility)
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader(`{"uuid":"abc-123"}`)),
+			}, nil
+		})}, "test-token", tc.visibility, "")
+
+		_, _, err := c.SubmitURL(context.Background(), "https://example.com", urlscanner.SubmitOptions{
+			Visibility: tc.override,
+		})
+		require.NoError(t, err)
+	}
+
+	c := urlscanio.New(&http.Client{Transport: rtFunc(func(*http.Request) (*http.Response, error) {
+		t.Fatal("request sent with invalid visibility")
+
+		return nil, nil //nolint: nilnil
+	})}, "test-token", "", "")
+	_, _, err := c.SubmitURL(context.Background(), "https://example.com", urlscanner.SubmitOptions{
+		Visibility: "secret",
+	})
+	require.ErrorIs(t, err, serrors.ErrBadRequest)
+}
+
+func TestClient_SubmitURL_tags(t *testing.T) {
+	c := urlscanio.New(&http.Client{Transport: rtFunc(func(r *http.Request) (*http.Response, error) {
+		var body struct {
+			Tags []string `json:"tags"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, []string{"phishing", "campaign-42"}, body.Tags)
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`{"uuid":"abc-123"}`)),
+		}, nil
+	})}, "test-token", "", "")
+
+	_, _, err := c.SubmitURL(context.Background(), "https://example.com", urlscanner.SubmitOptions{
+		Tags: []string{"phishing", "campaign-42"},
+	})
+	require.NoError(t, err)
+}
+
+func TestClient_SubmitURL_invalidTags(t *testing.T) {
+	c := urlscanio.New(&http.Client{Transport: rtFunc(func(*http.Request) (*http.Response, error) {
+		t.Fatal("request sent with invalid tags")
+
+		return nil, nil //nolint: nilnil
+	})}, "test-token", "", "")
+
+	_, _, err := c.SubmitURL(context.Background(), "https://example.com", urlscanner.SubmitOptions{
+		Tags: []string{strings.Repeat("a", urlscanner.MaxTagLength+1)},
+	})
+	require.Error(t, err)
+}
+
+func TestClient_SubmitURL_country(t *testing.T) {
+	c := urlscanio.New(&http.Client{Transport: rtFunc(func(r *http.Request) (*http.Response, error) {
+		var body struct {
+			Country string `json:"country"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, "de", body.Country)
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`{"uuid":"abc-123"}`)),
+		}, nil
+	})}, "test-token", "", "")
+
+	_, _, err := c.SubmitURL(context.Background(), "https://example.com", urlscanner.SubmitOptions{Country: "DE"})
+	require.NoError(t, err)
+}
+
+func TestClient_SubmitURL_invalidCountry(t *testing.T) {
+	c := urlscanio.New(&http.Client{Transport: rtFunc(func(*http.Request) (*http.Response, error) {
+		t.Fatal("request sent with an invalid country")
+
+		return nil, nil //nolint: nilnil
+	})}, "test-token", "", "")
+
+	for _, country := range []string{"XX", "DEU", "276", "d"} {
+		_, _, err := c.SubmitURL(context.Background(), "https://example.com", urlscanner.SubmitOptions{Country: country})
+		require.ErrorIs(t, err, serrors.ErrBadRequest, country)
+	}
+}
+
+func TestClient_DefaultUserAgent(t *testing.T) {
+	c := urlscanio.New(&http.Client{Transport: rtFunc(func(r *http.Request) (*http.Response, error) {
+		require.Equal(t, "url-scanner/dev", r.Header.Get("User-Agent"))
+
+		return &http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody}, nil
+	})}, "test-token", "", "")
+
+	ready, err := c.Status(context.Background(), "scan-123")
+	require.NoError(t, err)
+	require.False(t, ready)
+}
+
 func TestClient_SubmitURL_rateLimited429(t *testing.T) {
 	resetAt := time.Now().Add(5 * time.Minute).UTC()
 	c := newTestClient(func(r *http.Request) (*http.Response, error) {
@@ -91,12 +219,16 @@ func TestClient_SubmitURL_rateLimited429(t *testing.T) {
 		}, nil
 	})
 
-	_, rl, err := c.SubmitURL(context.Background(), "https://example.com")
+	_, rl, err := c.SubmitURL(context.Background(), "https://example.com", urlscanner.SubmitOptions{})
 	require.Error(t, err)
 	require.ErrorIs(t, err, serrors.ErrRateLimited, "expected ErrRateLimited kind: %v", err)
 	require.Equal(t, 100, rl.Limit)
 	require.Equal(t, 0, rl.Remaining)
 	require.True(t, rl.ResetAt.Equal(resetAt))
+	// the error hints to retry once the rate limit resets
+	var sem *serrors.Error
+	require.ErrorAs(t, err, &sem)
+	require.InDelta(t, 5*time.Minute, sem.RetryAfter(), float64(time.Minute))
 }
 
 func TestClient_SubmitURL_non2xx(t *testing.T) {
@@ -114,7 +246,7 @@ func TestClient_SubmitURL_non2xx(t *testing.T) {
 		}, nil
 	})
 
-	_, rl, err := c.SubmitURL(context.Background(), "https://example.com")
+	_, rl, err := c.SubmitURL(context.Background(), "https://example.com", urlscanner.SubmitOptions{})
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "upstream bad")
 	require.Equal(t, 100, rl.Limit)
@@ -122,6 +254,44 @@ func TestClient_SubmitURL_non2xx(t *testing.T) {
 	require.True(t, rl.ResetAt.Equal(resetAt))
 }
 
+func TestClient_Status(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+		ready  bool
+	}{
+		{name: "ready", status: http.StatusOK, ready: true},
+		{name: "not ready", status: http.StatusNotFound, ready: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newTestClient(func(r *http.Request) (*http.Response, error) {
+				require.Equal(t, http.MethodHead, r.Method)
+				require.Equal(t, "/api/v1/result/scan-123", r.URL.Path)
+				require.Equal(t, "test-token", r.Header.Get("Api-Key"))
+				require.Equal(t, "test-agent", r.Header.Get("User-Agent"))
+
+				return &http.Response{StatusCode: tc.status, Body: http.NoBody}, nil
+			})
+
+			ready, err := c.Status(context.Background(), "scan-123")
+			require.NoError(t, err)
+			require.Equal(t, tc.ready, ready)
+		})
+	}
+}
+
+func TestClient_Status_non2xx(t *testing.T) {
+	c := newTestClient(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusBadGateway, Status: "502 Bad Gateway", Body: http.NoBody}, nil
+	})
+
+	ready, err := c.Status(context.Background(), "scan-500")
+	require.Error(t, err)
+	require.False(t, ready)
+	require.Contains(t, err.Error(), "502 Bad Gateway")
+}
+
 func TestClient_Result_success(t *testing.T) {
 	sent := struct {
 		Page struct {
@@ -134,8 +304,10 @@ func TestClient_Result_success(t *testing.T) {
 		} `json:"page"`
 		Verdicts struct {
 			Overall struct {
-				Malicious bool `json:"malicious"`
-				Score     int  `json:"score"`
+				Malicious  bool     `json:"malicious"`
+				Score      int      `json:"score"`
+				Categories []string `json:"categories,omitempty"`
+				Brands     []string `json:"brands,omitempty"`
 			} `json:"overall"`
 		} `json:"verdicts"`
 		Stats struct {
@@ -153,12 +325,13 @@ func TestClient_Result_success(t *testing.T) {
 	sent.Stats.Malicious = 7
 
 	//nolint: lll
-	body := `{"page":{"url":"` + sent.Page.URL + `","domain":"` + sent.Page.Domain + `","ip":"` + sent.Page.IP + `","asn":"` + sent.Page.ASN + `","country":"` + sent.Page.Country + `","server":"` + sent.Page.Server + `"},"verdicts":{"overall":{"malicious":true,"score":42}},"stats":{"malicious":7}}`
+	body := `{"page":{"url":"` + sent.Page.URL + `","domain":"` + sent.Page.Domain + `","ip":"` + sent.Page.IP + `","asn":"` + sent.Page.ASN + `","country":"` + sent.Page.Country + `","server":"` + sent.Page.Server + `"},"verdicts":{"overall":{"malicious":true,"score":42,"categories":["phishing","malware"],"brands":[{"name":"PayPal"},{"name":"Microsoft"}]}},"stats":{"malicious":7}}`
 
 	c := newTestClient(func(r *http.Request) (*http.Response, error) {
 		require.Equal(t, http.MethodGet, r.Method)
 		require.Equal(t, "/api/v1/result/scan-123", r.URL.Path)
 		require.Equal(t, "test-token", r.Header.Get("Api-Key"))
+		require.Equal(t, "test-agent", r.Header.Get("User-Agent"))
 
 		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
 	})
@@ -182,11 +355,15 @@ func TestClient_Result_success(t *testing.T) {
 		Server:  sent.Page.Server,
 	}, res.Page)
 	require.Equal(t, &struct {
-		Malicious bool `json:"malicious"`
-		Score     int  `json:"score"`
+		Malicious  bool     `json:"malicious"`
+		Score      int      `json:"score"`
+		Categories []string `json:"categories,omitempty"`
+		Brands     []string `json:"brands,omitempty"`
 	}{
-		Malicious: true,
-		Score:     42,
+		Malicious:  true,
+		Score:      42,
+		Categories: []string{"phishing", "malware"},
+		Brands:     []string{"PayPal", "Microsoft"},
 	}, res.Verdict)
 	require.Equal(t, &struct {
 		Malicious int `json:"malicious"`
@@ -204,6 +381,36 @@ func TestClient_Result_404(t *testing.T) {
 	require.ErrorIs(t, err, serrors.ErrNotFound)
 }
 
+func TestClient_Result_processing(t *testing.T) {
+	c := newTestClient(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"message":"Scan is not finished yet","status":404}`)),
+		}, nil
+	})
+
+	res, err := c.Result(context.Background(), "scan-123")
+	require.ErrorIs(t, err, serrors.ErrNotFound)
+	require.Nil(t, res)
+}
+
+func TestClient_Result_withoutPage(t *testing.T) {
+	// a finished result without page data, e.g. of a page that did not load,
+	// is final and not retried
+	c := newTestClient(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"task":{"uuid":"scan-123"},"verdicts":{"overall":{"malicious":false,"score":0}}}`)),
+		}, nil
+	})
+
+	res, err := c.Result(context.Background(), "scan-123")
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	require.Empty(t, res.Page.URL)
+	require.False(t, res.Verdict.Malicious)
+}
+
 func TestClient_Result_non2xx(t *testing.T) {
 	c := newTestClient(func(r *http.Request) (*http.Response, error) {
 		return &http.Response{StatusCode: http.StatusBadGateway, Body: io.NopCloser(strings.NewReader("bad upstream"))}, nil
@@ -214,3 +421,95 @@ func TestClient_Result_non2xx(t *testing.T) {
 	require.Nil(t, res)
 	require.Contains(t, err.Error(), "bad upstream")
 }
+
+func TestClient_Results_success(t *testing.T) {
+	c := newTestClient(func(r *http.Request) (*http.Response, error) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.Equal(t, "test-token", r.Header.Get("Api-Key"))
+		require.Equal(t, "test-agent", r.Header.Get("User-Agent"))
+
+		switch r.URL.Path {
+		case "/api/v1/search/":
+			require.Equal(t, "_id:(scan-1 OR scan-2 OR scan-3)", r.URL.Query().Get("q"))
+			require.Equal(t, "3", r.URL.Query().Get("size"))
+
+			// scan-2 has not finished yet, scan-3 is indexed but its result is not stored yet
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"results":[{"_id":"scan-1"},{"_id":"scan-3"}]}`)),
+			}, nil
+		case "/api/v1/result/scan-1":
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"page":{},"verdicts":{"overall":{"malicious":true,"score":42}}}`)),
+			}, nil
+		case "/api/v1/result/scan-3":
+			return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader("not found"))}, nil
+		default:
+			t.Fatalf("unexpected request to %s", r.URL.Path)
+
+			return nil, nil
+		}
+	})
+
+	res, errs := c.Results(context.Background(), []string{"scan-1", "scan-2", "scan-3"})
+	require.Empty(t, errs)
+	require.Len(t, res, 1)
+	require.NotNil(t, res["scan-1"])
+	require.True(t, res["scan-1"].Verdict.Malicious)
+	require.Equal(t, 42, res["scan-1"].Verdict.Score)
+	// categories and brands are absent from the verdict
+	require.Nil(t, res["scan-1"].Verdict.Categories)
+	require.Nil(t, res["scan-1"].Verdict.Brands)
+}
+
+func TestClient_Results_searchFailed(t *testing.T) {
+	c := newTestClient(func(r *http.Request) (*http.Response, error) {
+		h := http.Header{}
+		h.Set("X-Rate-Limit-Reset", time.Now().Add(time.Minute).UTC().Format(time.RFC3339Nano))
+
+		return &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header:     h,
+			Body:       io.NopCloser(strings.NewReader("slow down")),
+		}, nil
+	})
+
+	res, errs := c.Results(context.Background(), []string{"scan-1"})
+	require.Len(t, errs, 1)
+	require.ErrorIs(t, errs["scan-1"], serrors.ErrRateLimited)
+	var sem *serrors.Error
+	require.ErrorAs(t, errs["scan-1"], &sem)
+	require.Positive(t, sem.RetryAfter())
+	require.Empty(t, res)
+}
+
+func TestClient_Results_resultFailed(t *testing.T) {
+	c := newTestClient(func(r *http.Request) (*http.Response, error) {
+		switch r.URL.Path {
+		case "/api/v1/search/":
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"results":[{"_id":"scan-1"},{"_id":"scan-2"}]}`)),
+			}, nil
+		case "/api/v1/result/scan-1":
+			return &http.Response{StatusCode: http.StatusInternalServerError, Body: io.NopCloser(strings.NewReader("bad upstream"))}, nil
+		case "/api/v1/result/scan-2":
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"page":{},"verdicts":{"overall":{"malicious":false,"score":0}}}`)),
+			}, nil
+		default:
+			t.Fatalf("unexpected request to %s", r.URL.Path)
+
+			return nil, nil
+		}
+	})
+
+	// the failed scan does not drop the result of the other one
+	res, errs := c.Results(context.Background(), []string{"scan-1", "scan-2"})
+	require.Len(t, errs, 1)
+	require.ErrorContains(t, errs["scan-1"], "bad upstream")
+	require.Len(t, res, 1)
+	require.NotNil(t, res["scan-2"])
+}
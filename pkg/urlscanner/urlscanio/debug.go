@@ -0,0 +1,68 @@
+package urlscanio
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"scanner/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// debugBodyLimit is the maximum number of response body bytes logged by DebugTransport.
+const debugBodyLimit = 1024
+
+// DebugTransport is an http.RoundTripper logging the exchanges with urlscan.io
+// when the logger of the request context is at debug level. It logs the
+// request method, URL and headers, redacted with logger.RedactHeaders, and
+// the response status and body truncated to debugBodyLimit bytes.
+type DebugTransport struct {
+	// Base performs the requests. When nil, http.DefaultTransport is used.
+	Base http.RoundTripper
+}
+
+// NewDebugTransport returns a DebugTransport wrapping base.
+func NewDebugTransport(base http.RoundTripper) *DebugTransport {
+	return &DebugTransport{Base: base}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *DebugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	ctx := req.Context()
+	if !logger.IsDebug(ctx) {
+		return base.RoundTrip(req) //nolint: wrapcheck
+	}
+
+	fields := []zap.Field{
+		zap.String("method", req.Method),
+		zap.String("url", req.URL.String()),
+		zap.Any("headers", logger.RedactHeaders(req.Header)),
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		logger.Debug(ctx, "urlscan.io request failed", append(fields, zap.Error(err))...)
+
+		return nil, err //nolint: wrapcheck
+	}
+
+	// read the head of the body for logging and put it back in front of the rest
+	head, err := io.ReadAll(io.LimitReader(resp.Body, debugBodyLimit))
+	if err != nil {
+		logger.Debug(ctx, "could not read urlscan.io response body for logging", zap.Error(err))
+	}
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), resp.Body), resp.Body}
+
+	logger.Debug(ctx, "urlscan.io request",
+		append(fields, zap.Int("status_code", resp.StatusCode), zap.ByteString("body", head))...)
+
+	return resp, nil
+}
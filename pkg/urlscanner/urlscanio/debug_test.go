@@ -0,0 +1,64 @@
+package urlscanio_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"scanner/pkg/logger"
+	"scanner/pkg/urlscanner"
+	"scanner/pkg/urlscanner/urlscanio"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newDebugClient(level zap.AtomicLevel) (*urlscanio.Client, *observer.ObservedLogs, context.Context) {
+	core, logs := observer.New(level)
+	ctx := logger.WithLogger(context.Background(), zap.New(core))
+
+	transport := urlscanio.NewDebugTransport(rtFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"uuid":"abc-123"}` + strings.Repeat(" ", 2048))),
+		}, nil
+	}))
+
+	return urlscanio.New(&http.Client{Transport: transport}, "secret-token", "", ""), logs, ctx
+}
+
+func TestDebugTransport_RedactsAPIKey(t *testing.T) {
+	c, logs, ctx := newDebugClient(zap.NewAtomicLevelAt(zap.DebugLevel))
+
+	res, _, err := c.SubmitURL(ctx, "https://example.com", urlscanner.SubmitOptions{})
+	require.NoError(t, err)
+	// the logged head of the body is still passed on
+	require.Equal(t, "abc-123", res.ID)
+
+	entries := logs.FilterMessage("urlscan.io request").All()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	require.Equal(t, "https://urlscan.io/api/v1/scan", fields["url"])
+	require.EqualValues(t, http.StatusOK, fields["status_code"])
+	require.Len(t, fields["body"], 1024)
+
+	headers, ok := fields["headers"].(http.Header)
+	require.True(t, ok)
+	require.Equal(t, "***", headers.Get("Api-Key"))
+	for _, entry := range logs.All() {
+		for _, field := range entry.Context {
+			require.NotContains(t, field.String, "secret-token")
+		}
+		require.NotContains(t, entry.Message, "secret-token")
+	}
+}
+
+func TestDebugTransport_SilentAboveDebug(t *testing.T) {
+	c, logs, ctx := newDebugClient(zap.NewAtomicLevelAt(zap.InfoLevel))
+
+	_, _, err := c.SubmitURL(ctx, "https://example.com", urlscanner.SubmitOptions{})
+	require.NoError(t, err)
+	require.Zero(t, logs.Len())
+}
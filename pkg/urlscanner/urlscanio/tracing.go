@@ -0,0 +1,78 @@
+package urlscanio
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation name of the spans of TracingTransport.
+const tracerName = "scanner/pkg/urlscanner/urlscanio"
+
+// TracingTransport is an http.RoundTripper creating a client span for every
+// request to urlscan.io, as a child of the span of the request context. The
+// span is named after the method only, since paths contain scan IDs, and
+// records the path and response status as attributes. The trace context of the
+// span is injected into the request headers, e.g. as a W3C traceparent.
+type TracingTransport struct {
+	// Base performs the requests. When nil, http.DefaultTransport is used.
+	Base http.RoundTripper
+	// Provider creates the spans. When nil, the global provider of otel is used.
+	Provider trace.TracerProvider
+	// Propagator injects the trace context into the requests. When nil, the
+	// global propagator of otel is used.
+	Propagator propagation.TextMapPropagator
+}
+
+// NewTracingTransport returns a TracingTransport wrapping base.
+func NewTracingTransport(base http.RoundTripper) *TracingTransport {
+	return &TracingTransport{Base: base}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *TracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	provider := t.Provider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	propagator := t.Propagator
+	if propagator == nil {
+		propagator = otel.GetTextMapPropagator()
+	}
+
+	ctx, span := provider.Tracer(tracerName).Start(req.Context(), "urlscanio "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("server.address", req.URL.Host),
+			attribute.String("url.path", req.URL.Path),
+		))
+	defer span.End()
+
+	// round trippers must not modify the request, so headers are set on a clone
+	req = req.Clone(ctx)
+	propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err //nolint: wrapcheck
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,116 @@
+package urlscanio_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"scanner/pkg/urlscanner"
+	"scanner/pkg/urlscanner/urlscanio"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newTracedClient returns a client whose requests are answered by rt and
+// traced into the returned in-memory exporter, and a context carrying a parent
+// span.
+func newTracedClient(rt rtFunc) (*urlscanio.Client, *tracetest.InMemoryExporter, context.Context, trace.Span) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	transport := &urlscanio.TracingTransport{Base: rt, Provider: provider, Propagator: propagation.TraceContext{}}
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+
+	return urlscanio.New(&http.Client{Transport: transport}, "test-token", "", ""), exporter, ctx, parent
+}
+
+func TestTracingTransport_Success(t *testing.T) {
+	c, exporter, ctx, parent := newTracedClient(func(r *http.Request) (*http.Response, error) {
+		require.NotEmpty(t, r.Header.Get("Traceparent"))
+		require.True(t, trace.SpanContextFromContext(r.Context()).IsValid())
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"uuid":"abc-123"}`)),
+		}, nil
+	})
+
+	_, _, err := c.SubmitURL(ctx, "https://example.com", urlscanner.SubmitOptions{})
+	require.NoError(t, err)
+	parent.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	span := spans[0]
+	require.Equal(t, "urlscanio POST", span.Name)
+	require.Equal(t, trace.SpanKindClient, span.SpanKind)
+	require.Equal(t, parent.SpanContext().SpanID(), span.Parent.SpanID())
+	require.Contains(t, span.Attributes, attribute.String("http.request.method", http.MethodPost))
+	require.Contains(t, span.Attributes, attribute.String("url.path", "/api/v1/scan"))
+	require.Contains(t, span.Attributes, attribute.Int("http.response.status_code", http.StatusOK))
+	require.Equal(t, codes.Unset, span.Status.Code)
+}
+
+func TestTracingTransport_Errors(t *testing.T) {
+	c, exporter, ctx, _ := newTracedClient(func(r *http.Request) (*http.Response, error) {
+		if r.Method == http.MethodHead {
+			return nil, errors.New("connection reset")
+		}
+
+		return &http.Response{StatusCode: http.StatusBadGateway, Body: http.NoBody}, nil
+	})
+
+	_, err := c.Status(ctx, "abc-123")
+	require.Error(t, err)
+	_, err = c.Result(ctx, "abc-123")
+	require.Error(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	// transport errors are recorded
+	require.Equal(t, "urlscanio HEAD", spans[0].Name)
+	require.Equal(t, codes.Error, spans[0].Status.Code)
+	require.Len(t, spans[0].Events, 1)
+	// so are error responses
+	require.Equal(t, "urlscanio GET", spans[1].Name)
+	require.Equal(t, codes.Error, spans[1].Status.Code)
+	require.Contains(t, spans[1].Attributes, attribute.Int("http.response.status_code", http.StatusBadGateway))
+}
+
+func TestTracingTransport_PropagatesTraceContext(t *testing.T) {
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	const incomingSpanID = "00f067aa0ba902b7"
+
+	var outgoing trace.SpanContext
+	c, exporter, _, _ := newTracedClient(func(r *http.Request) (*http.Response, error) {
+		outgoing = trace.SpanContextFromContext(
+			propagation.TraceContext{}.Extract(context.Background(), propagation.HeaderCarrier(r.Header)))
+
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+
+	// the trace context of an incoming request, as extracted by the API
+	incoming := http.Header{}
+	incoming.Set("traceparent", "00-"+traceID+"-"+incomingSpanID+"-01")
+	ctx := propagation.TraceContext{}.Extract(context.Background(), propagation.HeaderCarrier(incoming))
+
+	_, err := c.Status(ctx, "abc-123")
+	require.NoError(t, err)
+
+	// the outgoing request continues the incoming trace, with the client span
+	// as its parent
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	require.Equal(t, incomingSpanID, spans[0].Parent.SpanID().String())
+	require.True(t, outgoing.IsValid())
+	require.Equal(t, traceID, outgoing.TraceID().String())
+	require.Equal(t, spans[0].SpanContext.SpanID(), outgoing.SpanID())
+	require.True(t, outgoing.IsSampled())
+}